@@ -17,22 +17,33 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+
 	"github.com/hashicorp/go-version"
 	"github.com/imdario/mergo"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
 	DefaultJobManagerReplicas  = 1
 	DefaultTaskManagerReplicas = 3
+	ReadOnlyUIProxyPort        = 8082
 )
 
 var v10, _ = version.NewVersion("1.10")
 
+// Reads the templates of spec.clusterRef, which are not applied if nil.
+var templateReader client.Reader
+
 // Sets default values for unspecified FlinkCluster properties.
 func _SetDefault(cluster *FlinkCluster) {
+	_SetClusterTemplate(cluster)
+
 	if cluster.Spec.BatchSchedulerName != nil {
 		cluster.Spec.BatchScheduler = &BatchSchedulerSpec{
 			Name: *cluster.Spec.BatchSchedulerName,
@@ -158,3 +169,68 @@ func _SetTaskManagerDefault(tmSpec *TaskManagerSpec, flinkVersion *version.Versi
 		tmSpec.ReadinessProbe = &readinessProbe
 	}
 }
+
+// Applies the template of spec.clusterRef to the cluster, unless it was applied before.
+// A cluster whose template cannot be applied is rejected by the validating webhook.
+func _SetClusterTemplate(cluster *FlinkCluster) {
+	if cluster.Spec.ClusterRef == nil || cluster.Annotations[AppliedTemplateAnnotation] != "" {
+		return
+	}
+	template, err := getClusterTemplate(templateReader, cluster)
+	if err != nil {
+		log.Info("Failed to get the cluster template", "name", cluster.Name, "reason", err.Error())
+		return
+	}
+	if err := _MergeClusterTemplate(&cluster.Spec, &template.Spec); err != nil {
+		log.Error(err, "Failed to apply the cluster template", "name", cluster.Name, "template", template.Name)
+		return
+	}
+	if cluster.Annotations == nil {
+		cluster.Annotations = map[string]string{}
+	}
+	cluster.Annotations[AppliedTemplateAnnotation] = fmt.Sprintf("%v@%v", template.Name, template.ResourceVersion)
+}
+
+// Gets the template referenced by spec.clusterRef of the cluster.
+func getClusterTemplate(reader client.Reader, cluster *FlinkCluster) (*FlinkCluster, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("cluster templates are not enabled")
+	}
+	var template = new(FlinkCluster)
+	var key = client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Spec.ClusterRef.Name}
+	if err := reader.Get(context.TODO(), key, template); err != nil {
+		return nil, err
+	}
+	if template.Annotations[TemplateAnnotation] != "true" {
+		return nil, fmt.Errorf("FlinkCluster %v is not annotated with %v: \"true\"", template.Name, TemplateAnnotation)
+	}
+	return template, nil
+}
+
+// Fills the unset fields of the spec with the ones of the template spec. The fields which
+// only apply to session clusters are not inherited.
+func _MergeClusterTemplate(spec *FlinkClusterSpec, template *FlinkClusterSpec) error {
+	var inherited = template.DeepCopy()
+	inherited.Job = nil
+	inherited.ClusterRef = nil
+	inherited.Jars = nil
+	inherited.IdlePolicy = nil
+	inherited.CanaryUpdate = nil
+	return mergo.Merge(spec, inherited, mergo.WithTransformers(quantityTransformer{}))
+}
+
+// Merges resource quantities as values, since merging their exported fields would leave
+// the amount of an unset quantity zero.
+type quantityTransformer struct{}
+
+func (quantityTransformer) Transformer(t reflect.Type) func(dst, src reflect.Value) error {
+	if t != reflect.TypeOf(resource.Quantity{}) {
+		return nil
+	}
+	return func(dst, src reflect.Value) error {
+		if quantity := dst.Interface().(resource.Quantity); dst.CanSet() && quantity.IsZero() {
+			dst.Set(src)
+		}
+		return nil
+	}
+}
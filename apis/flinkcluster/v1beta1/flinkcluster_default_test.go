@@ -27,6 +27,8 @@ import (
 	"gotest.tools/v3/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 // Tests default values are set as expected.
@@ -368,3 +370,67 @@ func TestSetNonDefault(t *testing.T) {
 		expectedCluster,
 		cmpopts.IgnoreUnexported(resource.Quantity{}))
 }
+
+// Tests the template of spec.clusterRef is applied to the unset fields.
+func TestSetClusterTemplate(t *testing.T) {
+	var templateReplicas = int32(5)
+	var jobReplicas = int32(2)
+	var template = &FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "template",
+			Namespace:       "default",
+			ResourceVersion: "7",
+			Annotations:     map[string]string{TemplateAnnotation: "true"},
+		},
+		Spec: FlinkClusterSpec{
+			FlinkVersion: "1.14",
+			Image:        ImageSpec{Name: "flink:1.14"},
+			TaskManager: &TaskManagerSpec{
+				Replicas:         &templateReplicas,
+				MemoryOffHeapMin: resource.MustParse("600M"),
+				Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+				}},
+			},
+			FlinkProperties: map[string]string{"taskmanager.numberOfTaskSlots": "2", "state.backend": "rocksdb"},
+			Jars:            []SessionJar{{Name: "job", URI: "gs://bucket/job.jar"}},
+		},
+	}
+	var scheme = runtime.NewScheme()
+	assert.NilError(t, AddToScheme(scheme))
+	templateReader = fake.NewClientBuilder().WithScheme(scheme).WithObjects(template).Build()
+	defer func() { templateReader = nil }()
+
+	var cluster = FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "default"},
+		Spec: FlinkClusterSpec{
+			ClusterRef: &corev1.LocalObjectReference{Name: "template"},
+			TaskManager: &TaskManagerSpec{
+				Replicas: &jobReplicas,
+				Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("1"),
+				}},
+			},
+			FlinkProperties: map[string]string{"taskmanager.numberOfTaskSlots": "4"},
+			Job:             &JobSpec{},
+		},
+	}
+	_SetClusterTemplate(&cluster)
+
+	assert.Equal(t, cluster.Annotations[AppliedTemplateAnnotation], "template@7")
+	assert.Equal(t, cluster.Spec.FlinkVersion, "1.14")
+	assert.Equal(t, cluster.Spec.Image.Name, "flink:1.14")
+	assert.Equal(t, *cluster.Spec.TaskManager.Replicas, jobReplicas)
+	assert.Equal(t, cluster.Spec.TaskManager.MemoryOffHeapMin.String(), "600M")
+	assert.Equal(t, cluster.Spec.TaskManager.Resources.Limits.Cpu().String(), "1")
+	assert.Equal(t, cluster.Spec.TaskManager.Resources.Limits.Memory().String(), "4Gi")
+	assert.DeepEqual(t, cluster.Spec.FlinkProperties,
+		map[string]string{"taskmanager.numberOfTaskSlots": "4", "state.backend": "rocksdb"})
+	assert.Assert(t, cluster.Spec.Jars == nil)
+
+	// The template is not applied again.
+	cluster.Spec.Image.Name = ""
+	_SetClusterTemplate(&cluster)
+	assert.Equal(t, cluster.Spec.Image.Name, "")
+}
@@ -31,14 +31,36 @@ func (cs ClusterState) String() string {
 }
 
 // ClusterState defines states for a cluster.
+//
+// A cluster goes from Queued and Creating to Running. A running cluster goes to
+// Degraded when only its TaskManagers are not ready, to Reconciling when other
+// components are not ready, and back to Running when they are. From any of them,
+// the cluster goes to Updating, Idle or Stopping as the spec, the idle policy and
+// the job require.
+// A running cluster goes to Suspending while its job is stopped with a savepoint
+// on a job-cancel control, then to Suspended once the job is stopped and the
+// cluster is kept by the cleanup policy. A suspended cluster stays Suspended until
+// it is updated.
+// An update which rolls the spec back to the previous revision, as the job of the
+// current revision failed the restore verification, is in RollingBack instead of
+// Updating.
+// Deleting is entered from any state once the cluster is being deleted, and is
+// never left.
 const (
+	ClusterStateQueued           ClusterState = "Queued"
 	ClusterStateCreating         ClusterState = "Creating"
 	ClusterStateRunning          ClusterState = "Running"
+	ClusterStateDegraded         ClusterState = "Degraded"
 	ClusterStateReconciling      ClusterState = "Reconciling"
 	ClusterStateUpdating         ClusterState = "Updating"
 	ClusterStateStopping         ClusterState = "Stopping"
 	ClusterStatePartiallyStopped ClusterState = "PartiallyStopped"
 	ClusterStateStopped          ClusterState = "Stopped"
+	ClusterStateIdle             ClusterState = "Idle"
+	ClusterStateDeleting         ClusterState = "Deleting"
+	ClusterStateSuspending       ClusterState = "Suspending"
+	ClusterStateSuspended        ClusterState = "Suspended"
+	ClusterStateRollingBack      ClusterState = "RollingBack"
 )
 
 type ComponentState string
@@ -87,11 +109,19 @@ const (
 
 // AccessScope defines the access scope of JobManager service.
 const (
-	AccessScopeCluster  = "Cluster"
-	AccessScopeVPC      = "VPC"
-	AccessScopeExternal = "External"
-	AccessScopeNodePort = "NodePort"
-	AccessScopeHeadless = "Headless"
+	AccessScopeCluster    = "Cluster"
+	AccessScopeVPC        = "VPC"
+	AccessScopeExternal   = "External"
+	AccessScopeNodePort   = "NodePort"
+	AccessScopeHeadless   = "Headless"
+	AccessScopeInternalLB = "InternalLB"
+)
+
+// LoadBalancerProvider defines the cloud provider of the JobManager service internal load balancer.
+const (
+	LoadBalancerProviderGCP   = "GCP"
+	LoadBalancerProviderAWS   = "AWS"
+	LoadBalancerProviderAzure = "Azure"
 )
 
 // JobRestartPolicy defines the restart policy when a job fails.
@@ -106,14 +136,297 @@ const (
 	JobRestartPolicyFromSavepointOnFailure JobRestartPolicy = "FromSavepointOnFailure"
 )
 
+// JobSuccessPolicyType defines which terminated jobs are regarded as succeeded.
+type JobSuccessPolicyType string
+
+const (
+	// JobSuccessPolicyFinished - the job succeeds when it finishes.
+	JobSuccessPolicyFinished JobSuccessPolicyType = "Finished"
+
+	// JobSuccessPolicyFinishedOrCancelled - the job also succeeds when it is
+	// cancelled other than by the operator, e.g. by the job itself.
+	JobSuccessPolicyFinishedOrCancelled JobSuccessPolicyType = "FinishedOrCancelled"
+
+	// JobSuccessPolicyAccumulator - the job succeeds when it finishes or is
+	// cancelled with the user accumulator matching the expected value.
+	JobSuccessPolicyAccumulator JobSuccessPolicyType = "Accumulator"
+)
+
+// RestoreVerificationState defines states of the verification of a job restored from a savepoint.
+type RestoreVerificationState string
+
+const (
+	RestoreVerificationStateInProgress RestoreVerificationState = "InProgress"
+	RestoreVerificationStateSucceeded  RestoreVerificationState = "Succeeded"
+	RestoreVerificationStateFailed     RestoreVerificationState = "Failed"
+)
+
+// RestoreVerificationFailureAction defines the action taken when a job restored from a
+// savepoint fails the verification.
+type RestoreVerificationFailureAction string
+
+const (
+	// RestoreVerificationFailureActionStop - the job is stopped and regarded as failed.
+	RestoreVerificationFailureActionStop RestoreVerificationFailureAction = "Stop"
+
+	// RestoreVerificationFailureActionRollback - the job is stopped, then the spec is rolled
+	// back to the previous revision, whose job is restored from the savepoint the failed job
+	// was started from.
+	RestoreVerificationFailureActionRollback RestoreVerificationFailureAction = "Rollback"
+)
+
+// UpdateStep defines the steps of the update of a cluster to its next revision.
+type UpdateStep string
+
+const (
+	// UpdateStepSavepoint - the job is stopped with a savepoint.
+	UpdateStepSavepoint UpdateStep = "Savepoint"
+	// UpdateStepTeardown - the components of the current revision are deleted.
+	UpdateStepTeardown UpdateStep = "Teardown"
+	// UpdateStepRecreate - the components of the next revision are created.
+	UpdateStepRecreate UpdateStep = "Recreate"
+	// UpdateStepUpdate - the components are updated in place, without recreation.
+	UpdateStepUpdate UpdateStep = "Update"
+)
+
+// UpdatePhase defines the phases the update of a cluster to its next revision goes through,
+// in order. The phases of the job are skipped if the job does not change.
+type UpdatePhase string
+
+const (
+	// UpdatePhaseSavepointTriggered - the savepoint to stop the job with is triggered.
+	UpdatePhaseSavepointTriggered UpdatePhase = "SavepointTriggered"
+	// UpdatePhaseJobStopped - the job of the current revision is stopped.
+	UpdatePhaseJobStopped UpdatePhase = "JobStopped"
+	// UpdatePhaseResourcesUpdated - the components are updated to the next revision.
+	UpdatePhaseResourcesUpdated UpdatePhase = "ResourcesUpdated"
+	// UpdatePhaseJobResubmitted - the job of the next revision is submitted.
+	UpdatePhaseJobResubmitted UpdatePhase = "JobResubmitted"
+)
+
+// Architecture is the CPU architecture of the nodes the pods of a cluster are scheduled on.
+type Architecture string
+
+const (
+	ArchitectureAMD64 Architecture = "amd64"
+	ArchitectureARM64 Architecture = "arm64"
+	// ArchitectureAny - the pods are scheduled on nodes of any architecture.
+	ArchitectureAny Architecture = "any"
+)
+
+// FlinkPlugin is a built-in plugin of the Flink image, named after its JAR file in the opt
+// directory of the image without the `flink-` prefix and the version.
+// +kubebuilder:validation:Enum=s3-fs-hadoop;s3-fs-presto;azure-fs-hadoop;gs-fs-hadoop;oss-fs-hadoop
+type FlinkPlugin string
+
+const (
+	FlinkPluginS3FsHadoop    FlinkPlugin = "s3-fs-hadoop"
+	FlinkPluginS3FsPresto    FlinkPlugin = "s3-fs-presto"
+	FlinkPluginAzureFsHadoop FlinkPlugin = "azure-fs-hadoop"
+	FlinkPluginGSFsHadoop    FlinkPlugin = "gs-fs-hadoop"
+	FlinkPluginOSSFsHadoop   FlinkPlugin = "oss-fs-hadoop"
+)
+
+// DeletionPolicy defines what happens to the components of a cluster when it is deleted.
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyRetain - the components are orphaned, the Flink cluster and its
+	// job keep running and must be deleted manually.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+
+	// DeletionPolicyDelete - the components are deleted, the PersistentVolumeClaims
+	// created from the volume claim templates are retained.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+
+	// DeletionPolicyDeletePVCsAlso - the components and the PersistentVolumeClaims
+	// are deleted.
+	DeletionPolicyDeletePVCsAlso DeletionPolicy = "DeletePVCsAlso"
+)
+
+// StorageRetentionPolicy defines what happens to the PersistentVolumeClaim of the
+// JobManager storage when the cluster is deleted.
+type StorageRetentionPolicy string
+
+const (
+	// StorageRetentionPolicyDelete - the claim is deleted with the cluster by its
+	// deletion policy.
+	StorageRetentionPolicyDelete StorageRetentionPolicy = "Delete"
+
+	// StorageRetentionPolicyRetain - the claim is not owned by the cluster and is kept
+	// after it is deleted.
+	StorageRetentionPolicyRetain StorageRetentionPolicy = "Retain"
+)
+
+// StartupDeadlineAction defines what happens to a cluster whose components are not ready
+// within spec.startupDeadlineSeconds.
+type StartupDeadlineAction string
+
+const (
+	// StartupDeadlineActionStopReconciling - the cluster is not reconciled until its spec
+	// is updated, its components are left as they are.
+	StartupDeadlineActionStopReconciling StartupDeadlineAction = "StopReconciling"
+
+	// StartupDeadlineActionCleanup - the job fails, so that its restart policy is not
+	// applied and the components are cleaned up by spec.job.cleanupPolicy.afterJobFails.
+	StartupDeadlineActionCleanup StartupDeadlineAction = "Cleanup"
+)
+
+// JobUpdateStopMode defines how the running job is stopped for an update.
+type JobUpdateStopMode string
+
+const (
+	// JobUpdateStopModeStopWithSavepoint - the job is stopped with a savepoint, which
+	// requires Flink 1.9 or later.
+	JobUpdateStopModeStopWithSavepoint JobUpdateStopMode = "StopWithSavepoint"
+
+	// JobUpdateStopModeCancelWithSavepoint - the job is cancelled with a savepoint, the
+	// deprecated API for Flink versions before 1.9.
+	JobUpdateStopModeCancelWithSavepoint JobUpdateStopMode = "CancelWithSavepoint"
+
+	// JobUpdateStopModeCancel - the job is cancelled without a savepoint.
+	JobUpdateStopModeCancel JobUpdateStopMode = "Cancel"
+)
+
+// SavepointTriggerMode defines how the operator triggers the savepoints of the job.
+type SavepointTriggerMode string
+
+const (
+	// SavepointTriggerModeAuto - savepoints are triggered through the Flink REST API, or
+	// through a Kubernetes Job when the operator cannot connect to the JobManager.
+	SavepointTriggerModeAuto SavepointTriggerMode = "Auto"
+
+	// SavepointTriggerModeREST - savepoints are triggered through the Flink REST API only.
+	SavepointTriggerModeREST SavepointTriggerMode = "REST"
+
+	// SavepointTriggerModeJob - savepoints are triggered by a Kubernetes Job running the Flink
+	// CLI in the namespace of the cluster.
+	SavepointTriggerModeJob SavepointTriggerMode = "Job"
+)
+
+// ClusterDependencyState defines when a FlinkCluster of spec.dependsOn is ready.
+type ClusterDependencyState string
+
+const (
+	// ClusterDependencyStateRunning - the dependency is ready while the cluster is Running or
+	// Degraded.
+	ClusterDependencyStateRunning ClusterDependencyState = "Running"
+
+	// ClusterDependencyStateSucceeded - the dependency is ready once its job succeeded.
+	ClusterDependencyStateSucceeded ClusterDependencyState = "Succeeded"
+)
+
+// CanaryUpdateState defines states of the canary update of the TaskManagers.
+type CanaryUpdateState string
+
+func (cs CanaryUpdateState) String() string {
+	return string(cs)
+}
+
+const (
+	// The canary TaskManagers are updated, and waited for to become ready and registered.
+	CanaryUpdateStateProgressing CanaryUpdateState = "Progressing"
+	// The canary TaskManagers are healthy, the others are updated after the soak period.
+	CanaryUpdateStateSoaking CanaryUpdateState = "Soaking"
+	// The other TaskManagers are updated.
+	CanaryUpdateStatePromoted CanaryUpdateState = "Promoted"
+	// The update is halted by the user, the canary TaskManagers return to the current revision.
+	CanaryUpdateStateAborted CanaryUpdateState = "Aborted"
+	// The update is halted as the canary TaskManagers were not healthy, they return to the current revision.
+	CanaryUpdateStateFailed CanaryUpdateState = "Failed"
+)
+
+// ConsumerLagAggregation defines how a consumer lag metric is aggregated over the subtasks
+// and the vertices of a job.
+type ConsumerLagAggregation string
+
+const (
+	ConsumerLagAggregationSum ConsumerLagAggregation = "Sum"
+	ConsumerLagAggregationMax ConsumerLagAggregation = "Max"
+)
+
+// The objectives of spec.job.slo, as reported in the violations of the job status.
+const (
+	JobSLOObjectiveMaxCheckpointAge = "maxCheckpointAgeSeconds"
+	JobSLOObjectiveMaxRestartRate   = "maxRestartRatePerHour"
+	JobSLOObjectiveMaxConsumerLag   = "maxConsumerLag"
+)
+
+// ClusterConditionSLOViolated is the type of the cluster condition which is true while
+// the running job violates spec.job.slo.
+const ClusterConditionSLOViolated = "SLOViolated"
+
+// ClusterConditionStartupDeadlineExceeded is the type of the cluster condition which tracks
+// spec.startupDeadlineSeconds while the cluster is created. It is true once the deadline
+// passed before the components were ready, with the reason why they were not ready.
+const ClusterConditionStartupDeadlineExceeded = "StartupDeadlineExceeded"
+
+// ClusterConditionPendingUpdate is the type of the cluster condition which is true while
+// the update of the cluster is deferred until the window of spec.updatePolicy.window opens.
+const ClusterConditionPendingUpdate = "PendingUpdate"
+
+// ClusterConditionTaskManagersNotRegistered is the type of the cluster condition which is
+// true while ready TaskManager pods are not registered to the JobManager past the deadline of
+// spec.taskManager.registrationWatchdog, with the pods.
+const ClusterConditionTaskManagersNotRegistered = "TaskManagersNotRegistered"
+
+// ClusterConditionStorageUnavailable is the type of the cluster condition which is true while
+// the probe of spec.job.storageProbe fails, with the location and the reason.
+const ClusterConditionStorageUnavailable = "StorageUnavailable"
+
+// ClusterConditionPaused is the type of the cluster condition which is true while the
+// reconciliation is paused by spec.paused or the paused annotation.
+const ClusterConditionPaused = "Paused"
+
+// ClusterConditionWaitingForDependencies is the type of the cluster condition which is true
+// while the cluster is not started because a FlinkCluster of spec.dependsOn is not ready.
+const ClusterConditionWaitingForDependencies = "WaitingForDependencies"
+
 // User requested control
 const (
 	// control annotation key
 	ControlAnnotation = "flinkclusters.flinkoperator.k8s.io/user-control"
+	// Comma separated names of the TaskManager pods to take thread dumps of with the
+	// thread-dump control, all TaskManagers if unset.
+	ThreadDumpTaskManagersAnnotation = "flinkclusters.flinkoperator.k8s.io/thread-dump-taskmanagers"
+	// Name of the JobManager or TaskManager pod to inject the ephemeral container of the
+	// debug control into, the JobManager pod if unset.
+	DebugPodAnnotation = "flinkclusters.flinkoperator.k8s.io/debug-pod"
+
+	// Set to "true" to confirm the deletion of a running job cluster when the validating
+	// webhook requires it.
+	ConfirmDeletionAnnotation = "flinkclusters.flinkoperator.k8s.io/confirm-deletion"
+
+	// Set to "true" on a session cluster to make it a template of the job clusters which
+	// reference it with spec.clusterRef. The operator creates no components for templates.
+	TemplateAnnotation = "flinkclusters.flinkoperator.k8s.io/template"
+	// Set by the mutating webhook to the name and resource version of the template applied
+	// to a cluster with spec.clusterRef. The template is applied again when it is removed.
+	AppliedTemplateAnnotation = "flinkclusters.flinkoperator.k8s.io/applied-template"
+
+	// Set to "true" to pause the reconciliation of the cluster as with spec.paused, e.g.
+	// during an incident, without changing the spec.
+	PausedAnnotation = "flinkclusters.flinkoperator.k8s.io/paused"
+
+	// Faults injected for end-to-end tests of the failure paths, honored only by operators
+	// built with the `faultinjection` build tag.
+	// Number of the next savepoints triggered through the Flink API to fail, decremented by
+	// the operator as they fail.
+	FaultFailSavepointsAnnotation = "flinkclusters.flinkoperator.k8s.io/fault-fail-savepoints"
+	// Delay of the responses of the Flink API of the cluster, e.g. `30s`.
+	FaultDelayFlinkAPIAnnotation = "flinkclusters.flinkoperator.k8s.io/fault-delay-flink-api"
+	// Comma separated components whose creation is dropped, e.g. `TaskManager,JobManagerService`.
+	FaultDropCreationsAnnotation = "flinkclusters.flinkoperator.k8s.io/fault-drop-creations"
 
 	// control name
-	ControlNameSavepoint = "savepoint"
-	ControlNameJobCancel = "job-cancel"
+	ControlNameSavepoint       = "savepoint"
+	ControlNameJobCancel       = "job-cancel"
+	ControlNameFlightRecording = "flight-recording"
+	ControlNameThreadDump      = "thread-dump"
+	ControlNameCanaryPromote   = "canary-promote"
+	ControlNameCanaryAbort     = "canary-abort"
+	ControlNameDebug           = "debug"
+	ControlNameWakeUp          = "wake-up"
 
 	// control state
 	ControlStateRequested  = "Requested"
@@ -135,6 +448,8 @@ const (
 	SavepointReasonJobCancel     SavepointReason = "job cancel"
 	SavepointReasonScheduled     SavepointReason = "scheduled"
 	SavepointReasonUpdate        SavepointReason = "update"
+	SavepointReasonStateDelta    SavepointReason = "state delta"
+	SavepointReasonNodeDrain     SavepointReason = "node drain"
 )
 
 // ImageSpec defines Flink image of JobManager and TaskManager containers.
@@ -150,7 +465,9 @@ type ImageSpec struct {
 	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
 	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty"`
 
-	// _(Optional)_ Secrets for image pull.
+	// _(Optional)_ Secrets for image pull, of all the pods of the cluster including the job
+	// submitter, whose init containers and sidecars are pulled with them as well. Default: the
+	// secrets of the `--default-image-pull-secrets` flag of the operator.
 	// [More info](https://kubernetes.io/docs/tasks/configure-pod-container/pull-image-private-registry/#create-a-pod-that-uses-your-secret)
 	PullSecrets []corev1.LocalObjectReference `json:"pullSecrets,omitempty"`
 }
@@ -171,6 +488,12 @@ type NamedPort struct {
 	// Protocol for port. One of `UDP, TCP, or SCTP`, default: `TCP`.
 	// +kubebuilder:validation:Enum=TCP;UDP;SCTP
 	Protocol string `json:"protocol,omitempty"`
+
+	// _(Optional)_ Application protocol of the port, e.g. `http`, `tcp` or `kubernetes.io/h2c`,
+	// for service meshes and Gateway implementations. Setting it adds the port, which must be
+	// named, to the service of the component with the protocol, while other extra ports are
+	// only exposed on the pods.
+	AppProtocol *string `json:"appProtocol,omitempty"`
 }
 
 // JobManagerPorts defines ports of JobManager.
@@ -198,6 +521,11 @@ type JobManagerPorts struct {
 	// +kubebuilder:validation:Maximum=65535
 	// +kubebuilder:default:=8081
 	UI *int32 `json:"ui,omitempty"`
+
+	// _(Optional)_ Application protocols of the ports in the JobManager service by port name,
+	// `rpc`, `blob`, `query` or `ui`, e.g. `ui: http` for service meshes to route and observe
+	// the REST API behind mTLS. The services have no application protocol by default.
+	AppProtocols map[string]string `json:"appProtocols,omitempty"`
 }
 
 // JobManagerIngressSpec defines ingress of JobManager
@@ -215,6 +543,77 @@ type JobManagerIngressSpec struct {
 
 	// _(Optional)_TLS secret name.
 	TLSSecretName *string `json:"tlsSecretName,omitempty"`
+
+	// _(Optional)_ Authentication in front of the Flink web UI. The UI allows
+	// anyone who can reach it to cancel jobs and upload jars.
+	Auth *JobManagerIngressAuthSpec `json:"auth,omitempty"`
+}
+
+// ExternalDNSSpec defines the DNS name of the JobManager published by external-dns.
+// [More info](https://github.com/kubernetes-sigs/external-dns)
+type ExternalDNSSpec struct {
+	// Hostname of the JobManager, set as the `external-dns.alpha.kubernetes.io/hostname`
+	// annotation. ex) {{$clusterName}}.flink.example.com
+	Hostname string `json:"hostname"`
+
+	// _(Optional)_ TTL of the DNS record in seconds, set as the
+	// `external-dns.alpha.kubernetes.io/ttl` annotation. Default: the external-dns default.
+	// +kubebuilder:validation:Minimum=1
+	TTL *int32 `json:"ttl,omitempty"`
+}
+
+// JobManagerIngressAuthSpec defines the authentication of the JobManager ingress.
+// At most one of `oauth2Proxy` and `externalAuth` can be set.
+type JobManagerIngressAuthSpec struct {
+	// _(Optional)_ Injects an oauth2-proxy sidecar into the JobManager pod and
+	// routes the ingress to it instead of the UI port.
+	OAuth2Proxy *OAuth2ProxySpec `json:"oauth2Proxy,omitempty"`
+
+	// _(Optional)_ Delegates the authentication to an external auth service
+	// through ingress-nginx annotations.
+	ExternalAuth *IngressExternalAuthSpec `json:"externalAuth,omitempty"`
+}
+
+// OAuth2ProxySpec defines the oauth2-proxy sidecar of the JobManager.
+// [More info](https://oauth2-proxy.github.io/oauth2-proxy/)
+type OAuth2ProxySpec struct {
+	// oauth2-proxy image, default: `quay.io/oauth2-proxy/oauth2-proxy:v7.4.0`.
+	// +kubebuilder:default:="quay.io/oauth2-proxy/oauth2-proxy:v7.4.0"
+	Image string `json:"image,omitempty"`
+
+	// OAuth provider, e.g. `google`, `github`, `oidc`.
+	Provider string `json:"provider"`
+
+	// Proxy port, default: `4180`.
+	// +kubebuilder:default:=4180
+	Port *int32 `json:"port,omitempty"`
+
+	// Secret key holding the OAuth client ID.
+	ClientIDSecretRef corev1.SecretKeySelector `json:"clientIDSecretRef"`
+
+	// Secret key holding the OAuth client secret.
+	ClientSecretSecretRef corev1.SecretKeySelector `json:"clientSecretSecretRef"`
+
+	// Secret key holding the seed for secure cookies, 16, 24 or 32 bytes.
+	CookieSecretSecretRef corev1.SecretKeySelector `json:"cookieSecretSecretRef"`
+
+	// _(Optional)_ Additional oauth2-proxy arguments, e.g. `--email-domain=example.com`.
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+
+	// _(Optional)_ Compute resources of the sidecar.
+	// [More info](https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/)
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// IngressExternalAuthSpec defines ingress-nginx external authentication.
+// [More info](https://kubernetes.github.io/ingress-nginx/user-guide/nginx-configuration/annotations/#external-authentication)
+type IngressExternalAuthSpec struct {
+	// URL of the auth service, set as `nginx.ingress.kubernetes.io/auth-url`.
+	URL string `json:"url"`
+
+	// _(Optional)_ URL to redirect unauthenticated requests to, set as
+	// `nginx.ingress.kubernetes.io/auth-signin`.
+	SignInURL *string `json:"signInURL,omitempty"`
 }
 
 // JobManagerSpec defines properties of JobManager.
@@ -231,11 +630,18 @@ type JobManagerSpec struct {
 	// `External`: accessible from the internet.
 	// `NodePort`: accessible through node port.
 	// `Headless`: pod IPs assumed to be routable and advertised directly with `clusterIP: None``.
+	// `InternalLB`: accessible through an internal load balancer of `loadBalancerProvider`.
 	// Currently `VPC, External` are only available for GKE.
 	// +kubebuilder:default:=Cluster
-	// +kubebuilder:validation:Enum=Cluster;VPC;External;NodePort;Headless
+	// +kubebuilder:validation:Enum=Cluster;VPC;External;NodePort;Headless;InternalLB
 	AccessScope string `json:"accessScope,omitempty"`
 
+	// _(Optional)_ Cloud provider of the internal load balancer, one of `GCP, AWS, Azure`.
+	// Required when `accessScope` is `InternalLB`, the provider specific annotations
+	// are added to the JobManager service.
+	// +kubebuilder:validation:Enum=GCP;AWS;Azure
+	LoadBalancerProvider string `json:"loadBalancerProvider,omitempty"`
+
 	// _(Optional)_ Define JobManager Service annotations for configuration.
 	ServiceAnnotations map[string]string `json:"ServiceAnnotations,omitempty"`
 
@@ -245,6 +651,15 @@ type JobManagerSpec struct {
 	// _(Optional)_ Provide external access to JobManager UI/API.
 	Ingress *JobManagerIngressSpec `json:"ingress,omitempty"`
 
+	// _(Optional)_ Publishes the JobManager under a DNS name with external-dns, through the
+	// ingress if `ingress` is set, otherwise through the JobManager service.
+	ExternalDNS *ExternalDNSSpec `json:"externalDNS,omitempty"`
+
+	// _(Optional)_ Makes the Flink web UI read-only, default: `true` if `accessScope` is `External`,
+	// `false` otherwise. Job submission and cancellation are disabled in the web UI, and the ingress
+	// is routed through a proxy sidecar which rejects mutating REST API requests.
+	ReadOnlyUI *bool `json:"readOnlyUI,omitempty"`
+
 	// Ports that JobManager listening on.
 	// +kubebuilder:default:={rpc:6123, blob:6124, query:6125, ui:8081}
 	Ports JobManagerPorts `json:"ports,omitempty"`
@@ -271,6 +686,11 @@ type JobManagerSpec struct {
 	// For Flink 1.10+. Percentage of memory process, as a safety margin to avoid OOM kill, default: `80`
 	MemoryProcessRatio *int32 `json:"memoryProcessRatio,omitempty"`
 
+	// _(Optional)_ JVM options of the JobManager, e.g. `-XX:+UseG1GC`, set as
+	// `env.java.opts.jobmanager`. Each option must be a single argument without whitespace.
+	// The options must not be set in `flinkProperties` as well.
+	JVMOptions []string `json:"jvmOptions,omitempty"`
+
 	// _(Optional)_ Volumes in the JobManager pod.
 	// [More info](https://kubernetes.io/docs/concepts/storage/volumes/)
 	Volumes []corev1.Volume `json:"volumes,omitempty"`
@@ -284,6 +704,11 @@ type JobManagerSpec struct {
 	// [More info](https://kubernetes.io/docs/concepts/storage/persistent-volumes/#persistentvolumeclaims)
 	VolumeClaimTemplates []corev1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
 
+	// _(Optional)_ Persists the web upload directory of the JobManager in a claim of
+	// `volumeClaimTemplates`, so that the JARs uploaded to a session cluster are not lost when
+	// the JobManager restarts. Not supported in application mode.
+	Storage *JobManagerStorageSpec `json:"storage,omitempty"`
+
 	// _(Optional)_ Init containers of the Job Manager pod.
 	// [More info](https://kubernetes.io/docs/concepts/workloads/pods/init-containers/)
 	InitContainers []corev1.Container `json:"initContainers,omitempty"`
@@ -313,6 +738,12 @@ type JobManagerSpec struct {
 	// [More info](https://kubernetes.io/docs/tasks/configure-pod-container/security-context/#set-the-security-context-for-a-pod)
 	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
 
+	// _(Optional)_ Seconds the JobManager pod is given to shut down gracefully, e.g. to finalize
+	// the checkpoints, before it is killed, default: 60.
+	// [More info](https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle/#pod-termination)
+	// +kubebuilder:validation:Minimum=0
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
 	// _(Optional)_ JobManager StatefulSet pod template labels.
 	// [More info](https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/)
 	PodLabels map[string]string `json:"podLabels,omitempty"`
@@ -332,6 +763,22 @@ type JobManagerSpec struct {
 	HostAliases []corev1.HostAlias `json:"hostAliases,omitempty"`
 }
 
+// JobManagerStorageSpec defines the persistent storage of the JobManager.
+type JobManagerStorageSpec struct {
+	// Name of the entry of `volumeClaimTemplates` to store the directory in. The claim is
+	// mounted at `/opt/flink-operator/jobmanager-storage`, and `web.upload.dir` of the JobManager
+	// is set to its `upload` directory.
+	VolumeClaimTemplate string `json:"volumeClaimTemplate"`
+
+	// _(Optional)_ What happens to the claim when the cluster is deleted, default: `Delete`.
+	// `Delete`: the claim is deleted with the cluster, as `deletionPolicy` decides.
+	// `Retain`: the claim is kept, and reused by the JobManager of a cluster created again
+	// with the same name.
+	// It cannot be updated.
+	// +kubebuilder:validation:Enum=Delete;Retain
+	RetentionPolicy *StorageRetentionPolicy `json:"retentionPolicy,omitempty"`
+}
+
 // TaskManagerPorts defines ports of TaskManager.
 type TaskManagerPorts struct {
 	// Data port, default: `6121`.
@@ -351,6 +798,10 @@ type TaskManagerPorts struct {
 	// +kubebuilder:validation:Maximum=65535
 	// +kubebuilder:default:=6125
 	Query *int32 `json:"query,omitempty"`
+
+	// _(Optional)_ Application protocols of the ports in the TaskManager service by port name,
+	// `data`, `rpc` or `query`, e.g. `rpc: tcp`.
+	AppProtocols map[string]string `json:"appProtocols,omitempty"`
 }
 
 // K8s workload API kind for TaskManager workers
@@ -390,6 +841,62 @@ type HorizontalPodAutoscalerSpec struct {
 	// in both Up and Down directions (scaleUp and scaleDown fields respectively).
 	// If not set, the default HPAScalingRules for scale up and scale down are used.
 	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty" protobuf:"bytes,5,opt,name=behavior"`
+
+	// _(Optional)_ Target of the Kafka consumer lag of `spec.monitoring.kafkaLag` per TaskManager,
+	// added to the metrics as the external metric `flink_operator_kafka_consumer_lag` of the
+	// cluster. The metrics of the operator must be served to the external metrics API, e.g. by
+	// the Prometheus adapter.
+	// +kubebuilder:validation:Minimum=1
+	TargetKafkaLagPerReplica *int64 `json:"targetKafkaLagPerReplica,omitempty"`
+}
+
+// SlotResources defines the resource profile of a TaskManager slot.
+type SlotResources struct {
+	// CPU cores of a slot.
+	CPUCores resource.Quantity `json:"cpuCores"`
+
+	// Task heap memory of a slot.
+	TaskHeapMemory resource.Quantity `json:"taskHeapMemory"`
+
+	// _(Optional)_ Task off-heap memory of a slot.
+	TaskOffHeapMemory resource.Quantity `json:"taskOffHeapMemory,omitempty"`
+
+	// _(Optional)_ Managed memory of a slot. If unspecified, Flink derives it from
+	// `taskmanager.memory.managed.fraction`.
+	ManagedMemory resource.Quantity `json:"managedMemory,omitempty"`
+}
+
+// TaskManagerRegistrationWatchdog defines how long the ready TaskManager pods may stay
+// unregistered to the JobManager, and what to do then.
+type TaskManagerRegistrationWatchdog struct {
+	// Seconds a TaskManager pod may be ready without being registered to the JobManager, from
+	// when both the pod and the JobManager are ready. Then the TaskManagersNotRegistered
+	// condition of the cluster is set, with the pods.
+	// +kubebuilder:validation:Minimum=1
+	DeadlineSeconds int32 `json:"deadlineSeconds"`
+
+	// _(Optional)_ Delete the pods which did not register within the deadline, so that they
+	// are recreated. Default: false.
+	RestartPods *bool `json:"restartPods,omitempty"`
+}
+
+// TaskManagerArtifact defines a file downloaded by the `extra-artifacts` init container of the
+// TaskManager pods, which fail to start if the download or the checksum verification fails.
+type TaskManagerArtifact struct {
+	// URI to download the file from, one of `http://`, `https://`, `gs://` or `s3://`.
+	// `gs://` and `s3://` objects are downloaded with the credentials of the TaskManager pods,
+	// as with `spec.jars`.
+	URI string `json:"uri"`
+
+	// Directory of the Flink home the file is placed in, `lib` or `plugins/<plugin>`, e.g.
+	// `plugins/s3-fs-presto`.
+	Directory string `json:"directory"`
+
+	// _(Optional)_ Name of the file in the directory. Default: the name of the file in the URI.
+	FileName *string `json:"fileName,omitempty"`
+
+	// _(Optional)_ Expected SHA-256 checksum of the file in hex, verified after the download.
+	SHA256 string `json:"sha256,omitempty"`
 }
 
 // TaskManagerSpec defines properties of TaskManager.
@@ -403,6 +910,23 @@ type TaskManagerSpec struct {
 	// +kubebuilder:validation:Minimum=1
 	Replicas *int32 `json:"replicas,omitempty"`
 
+	// _(Optional)_ The number of task slots of each TaskManager, set as
+	// `taskmanager.numberOfTaskSlots`. Cannot be used with `slotsPerCPU` or the Flink property.
+	// Default: `taskmanager.numberOfTaskSlots` of `flinkProperties` if set, otherwise half of the
+	// TaskManager CPU, at least 1.
+	// +kubebuilder:validation:Minimum=1
+	TaskSlots *int32 `json:"taskSlots,omitempty"`
+
+	// _(Optional)_ The number of task slots per CPU of the TaskManager `resources`, e.g. `1` or
+	// `0.5`, rounded down to the number of slots of each TaskManager, at least 1.
+	SlotsPerCPU *resource.Quantity `json:"slotsPerCPU,omitempty"`
+
+	// _(Optional)_ The TaskManagers are managed outside of the operator, e.g. by a separate
+	// autoscaler or on VMs, and register to the JobManager by its service. The operator does
+	// not create the TaskManager StatefulSet or Deployment, and reflects the number of
+	// TaskManagers registered to the JobManager in the status. default: `false`
+	External *bool `json:"external,omitempty"`
+
 	// Ports that TaskManager listening on.
 	// +kubebuilder:default:={data:6121, rpc:6122, query:6125}
 	Ports TaskManagerPorts `json:"ports,omitempty"`
@@ -430,6 +954,16 @@ type TaskManagerSpec struct {
 	// For Flink 1.10+. Percentage of process memory, as a safety margin to avoid OOM kill, default: `20`
 	MemoryProcessRatio *int32 `json:"memoryProcessRatio,omitempty"`
 
+	// _(Optional)_ JVM options of the TaskManagers, e.g. `-XX:+UseG1GC`, set as
+	// `env.java.opts.taskmanager`. Each option must be a single argument without whitespace.
+	// The options must not be set in `flinkProperties` as well.
+	JVMOptions []string `json:"jvmOptions,omitempty"`
+
+	// _(Optional)_ For Flink 1.14+. Resource profile of a task slot, which enables Flink fine-grained
+	// resource management. The TaskManager resources are derived from it and the number of task slots.
+	// [More info](https://nightlies.apache.org/flink/flink-docs-stable/docs/deployment/finegrained_resource/)
+	SlotResources *SlotResources `json:"slotResources,omitempty"`
+
 	// _(Optional)_ Volumes in the TaskManager pods.
 	// [More info](https://kubernetes.io/docs/concepts/storage/volumes/)
 	Volumes []corev1.Volume `json:"volumes,omitempty"`
@@ -451,6 +985,11 @@ type TaskManagerSpec struct {
 	// [More info](https://kubernetes.io/docs/concepts/workloads/pods/init-containers/)
 	InitContainers []corev1.Container `json:"initContainers,omitempty"`
 
+	// _(Optional)_ Files downloaded into the `lib` or `plugins` directories of the TaskManager
+	// pods before Flink starts, e.g. the JAR files of UDFs, metrics reporters or file systems,
+	// so that they do not need to be built into the image.
+	ExtraArtifacts []TaskManagerArtifact `json:"extraArtifacts,omitempty"`
+
 	// _(Optional)_ Defines the affinity of the Task Manager pod
 	// [More info](https://kubernetes.io/docs/concepts/configuration/assign-pod-node/#affinity-and-anti-affinity)
 	Affinity *corev1.Affinity `json:"affinity,omitempty"`
@@ -476,6 +1015,23 @@ type TaskManagerSpec struct {
 	// [More info](https://kubernetes.io/docs/tasks/configure-pod-container/security-context/#set-the-security-context-for-a-pod)
 	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
 
+	// _(Optional)_ Seconds the TaskManager pods are given to shut down gracefully, e.g. to
+	// deregister from the JobManager, before they are killed, default: 60.
+	// [More info](https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle/#pod-termination)
+	// +kubebuilder:validation:Minimum=0
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// _(Optional)_ Seconds to wait at most before the TaskManagers removed by a scale-down of the
+	// StatefulSet are deleted, until they run no tasks or the checkpoint of the job triggered by
+	// the scale-down completed, which the tasks running on them restart from. If not
+	// specified, they are deleted with the scale-down.
+	// +kubebuilder:validation:Minimum=0
+	DecommissionTimeoutSeconds *int32 `json:"decommissionTimeoutSeconds,omitempty"`
+
+	// _(Optional)_ Check that the ready TaskManager pods register to the JobManager, which they
+	// fail to do e.g. with a wrong `jobmanager.rpc.address` or a network policy between them.
+	RegistrationWatchdog *TaskManagerRegistrationWatchdog `json:"registrationWatchdog,omitempty"`
+
 	// _(Optional)_ TaskManager StatefulSet pod template labels.
 	// [More info](https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/)
 	PodLabels map[string]string `json:"podLabels,omitempty"`
@@ -509,23 +1065,377 @@ const (
 	CleanupActionDeleteCluster = "DeleteCluster"
 	// CleanupActionDeleteTaskManager - delete task manager, keep job manager.
 	CleanupActionDeleteTaskManager = "DeleteTaskManager"
+	// CleanupActionKeepJobManagerOnly - keep job manager with its service and configuration,
+	// delete the other components.
+	CleanupActionKeepJobManagerOnly = "KeepJobManagerOnly"
 )
 
 // CleanupPolicy defines the action to take after job finishes.
-// Use one of `KeepCluster, DeleteCluster, DeleteTaskManager` for the below fields.
+// Use one of `KeepCluster, DeleteCluster, DeleteTaskManager, KeepJobManagerOnly` for the below fields.
 type CleanupPolicy struct {
 	// Action to take after job succeeds, default: `DeleteCluster`.
 	// +kubebuilder:default=DeleteCluster
-	// +kubebuilder:validation:Enum=KeepCluster;DeleteCluster;DeleteTaskManager
+	// +kubebuilder:validation:Enum=KeepCluster;DeleteCluster;DeleteTaskManager;KeepJobManagerOnly
 	AfterJobSucceeds CleanupAction `json:"afterJobSucceeds,omitempty"`
 	// Action to take after job fails, default: `KeepCluster`.
 	// +kubebuilder:default=KeepCluster
-	// +kubebuilder:validation:Enum=KeepCluster;DeleteCluster;DeleteTaskManager
+	// +kubebuilder:validation:Enum=KeepCluster;DeleteCluster;DeleteTaskManager;KeepJobManagerOnly
 	AfterJobFails CleanupAction `json:"afterJobFails,omitempty"`
 	// Action to take after job is cancelled, default: `DeleteCluster`.
 	// +kubebuilder:default=DeleteCluster
-	// +kubebuilder:validation:Enum=KeepCluster;DeleteCluster;DeleteTaskManager
+	// +kubebuilder:validation:Enum=KeepCluster;DeleteCluster;DeleteTaskManager;KeepJobManagerOnly
 	AfterJobCancelled CleanupAction `json:"afterJobCancelled,omitempty"`
+
+	// _(Optional)_ Seconds after the job finished, without being restarted, after which the
+	// FlinkCluster itself is deleted, like `ttlSecondsAfterFinished` of Jobs. The actions
+	// above apply until then.
+	// +kubebuilder:validation:Minimum=0
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+
+	// _(Optional)_ Keep only the last finished FlinkClusters of the group of the cluster,
+	// e.g. the job clusters of the runs of a batch pipeline, and delete the older ones.
+	KeepLastFinished *KeepLastFinishedPolicy `json:"keepLastFinished,omitempty"`
+}
+
+// KeepLastFinishedPolicy limits the number of finished FlinkClusters kept in a group.
+type KeepLastFinishedPolicy struct {
+	// Label whose value groups the FlinkClusters in the namespace of the cluster, e.g.
+	// `app`. The policy is ignored if the cluster does not have the label.
+	GroupLabel string `json:"groupLabel"`
+
+	// Number of the finished FlinkClusters of the group to keep, the ones whose jobs
+	// completed last. The older ones are deleted when a job of the group finishes.
+	// +kubebuilder:validation:Minimum=1
+	Count int32 `json:"count"`
+}
+
+// JobSuccessPolicy defines the criteria for a terminated job to be regarded as succeeded.
+type JobSuccessPolicy struct {
+	// Type of the success criteria, one of `Finished, FinishedOrCancelled, Accumulator`, default: `Finished`.
+	// +kubebuilder:default=Finished
+	// +kubebuilder:validation:Enum=Finished;FinishedOrCancelled;Accumulator
+	Type JobSuccessPolicyType `json:"type,omitempty"`
+
+	// _(Optional)_ The user accumulator to match, required for `Accumulator` type.
+	// A finished job whose accumulator does not match is regarded as failed.
+	Accumulator *JobAccumulatorPredicate `json:"accumulator,omitempty"`
+}
+
+// JobAccumulatorPredicate matches a user accumulator of the job.
+type JobAccumulatorPredicate struct {
+	// Name of the user accumulator.
+	Name string `json:"name"`
+
+	// Expected value of the accumulator, compared with its string representation.
+	Value string `json:"value"`
+}
+
+// RestoreVerification defines the checks of a job started from a savepoint, which
+// catch savepoints whose state is incompatible with the job early.
+type RestoreVerification struct {
+	// Seconds after the job started within which a checkpoint must complete, default: 600.
+	// +kubebuilder:default:=600
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// The maximum number of restarts of the job until a checkpoint completes, default: 0.
+	// +kubebuilder:validation:Minimum=0
+	MaxRestarts int32 `json:"maxRestarts,omitempty"`
+
+	// _(Optional)_ The action taken when the job fails the verification: `Stop`, the job
+	// is stopped and regarded as failed, or `Rollback`, the spec is then also rolled back to
+	// the previous revision, whose job is restored from the savepoint the failed job was
+	// started from. A rollback is not rolled back again. default: `Stop`
+	// +kubebuilder:validation:Enum=Stop;Rollback
+	OnFailure RestoreVerificationFailureAction `json:"onFailure,omitempty"`
+}
+
+// SavepointOwnership defines the ownership metadata of the savepoints of a job and how it
+// is verified on restores.
+type SavepointOwnership struct {
+	// _(Optional)_ Labels identifying the pipeline of the job, e.g. `pipeline: clicks`,
+	// recorded in the metadata. A savepoint is compatible with the job if its labels are the
+	// same. If the job or the savepoint has no labels, it is compatible if it was taken of a
+	// job with the same entry class, as the job IDs change on every submission.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// _(Optional)_ Verify the metadata of the savepoint to restore the job from before the
+	// job is submitted. The submission is blocked while the savepoint is incompatible, has
+	// no metadata or its metadata cannot be read. Set `allowedSavepoint` to restore a
+	// savepoint without metadata, e.g. taken before the ownership was recorded. default: `true`
+	VerifyRestore *bool `json:"verifyRestore,omitempty"`
+
+	// _(Optional)_ Savepoint to restore the job from even if it is not compatible or has no
+	// metadata, e.g. to move the state of a pipeline to a job with another entry class once.
+	AllowedSavepoint string `json:"allowedSavepoint,omitempty"`
+
+	// _(Optional)_ Key of a Secret in the namespace of the cluster whose value is sent as the
+	// `Authorization` header of the requests writing and reading the metadata on Cloud Storage
+	// and HTTP locations, instead of the credentials of the operator.
+	AuthorizationSecret *corev1.SecretKeySelector `json:"authorizationSecret,omitempty"`
+}
+
+// StorageProbe defines how the savepoint storage of the job is probed.
+type StorageProbe struct {
+	// _(Optional)_ Key of a Secret in the namespace of the cluster whose value is sent as the
+	// `Authorization` header of the probe requests to `gs://`, `http://` and `https://`
+	// storage, e.g. `Bearer <token>` of the credentials the job accesses the storage with,
+	// instead of the credentials of the operator. Requests to S3 are always signed with the
+	// credentials of the operator.
+	AuthorizationSecret *corev1.SecretKeySelector `json:"authorizationSecret,omitempty"`
+}
+
+// JobPlanArchive defines where the plans of the job are stored.
+type JobPlanArchive struct {
+	// _(Optional)_ Object storage to upload the plans to instead of the ConfigMap, as
+	// `<uri>/<namespace>/<cluster>/<revision>/plan.json`.
+	Upload *DiagnosticsUpload `json:"upload,omitempty"`
+}
+
+// AdaptiveSavepointSpec defines when savepoints are taken by the changes of the state of a
+// job and by the drains of the nodes, instead of only periodically.
+type AdaptiveSavepointSpec struct {
+	// _(Optional)_ Take a savepoint once the checkpointed size of the checkpoints completed
+	// since the last savepoint exceeds it, e.g. `10Gi`. With incremental checkpoints it
+	// estimates the state changed since the savepoint, otherwise the full state size of each
+	// checkpoint is counted. The state size is counted with Flink before 1.15, which does
+	// not report the checkpointed size.
+	StateDeltaThreshold *resource.Quantity `json:"stateDeltaThreshold,omitempty"`
+
+	// _(Optional)_ Take a savepoint when a node running a JobManager or TaskManager pod is
+	// cordoned to be drained, or marked for deletion by the cluster autoscaler. default: `false`
+	BeforeNodeDrain *bool `json:"beforeNodeDrain,omitempty"`
+
+	// Minimum seconds between the last savepoint and a savepoint taken by this policy,
+	// default: 300.
+	// +kubebuilder:default:=300
+	// +kubebuilder:validation:Minimum=0
+	MinIntervalSeconds int32 `json:"minIntervalSeconds,omitempty"`
+}
+
+// CanaryUpdateSpec defines the canary update strategy of a session cluster: a part of the
+// TaskManagers is updated first, and the others are updated once the canaries stayed ready
+// and registered to the JobManager for the soak period.
+type CanaryUpdateSpec struct {
+	// Percentage of the TaskManagers which are updated first, at least one, default: 10.
+	// +kubebuilder:default:=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Percentage int32 `json:"percentage,omitempty"`
+
+	// Seconds for which the canary TaskManagers must stay ready and registered to the
+	// JobManager before the others are updated, default: 300.
+	// +kubebuilder:default:=300
+	// +kubebuilder:validation:Minimum=0
+	SoakSeconds int32 `json:"soakSeconds,omitempty"`
+
+	// Seconds after the update started within which the canary TaskManagers must become
+	// ready and registered, otherwise the update fails, default: 600.
+	// +kubebuilder:default:=600
+	// +kubebuilder:validation:Minimum=1
+	ProgressDeadlineSeconds int32 `json:"progressDeadlineSeconds,omitempty"`
+}
+
+// UpdatePolicy defines when the updates of the cluster are applied.
+type UpdatePolicy struct {
+	// _(Optional)_ The recurring window in which the cluster is updated. If unspecified,
+	// the cluster is updated as soon as its spec changes.
+	Window *UpdateWindow `json:"window,omitempty"`
+
+	// _(Optional)_ Seconds without further spec changes to wait for before an update
+	// starts, so that successive changes, e.g. several commits applied by GitOps, are
+	// applied in one update with a single savepoint and restart of the job. The next
+	// revisions replaced before their update started are removed from the revision history.
+	// +kubebuilder:validation:Minimum=1
+	DebounceSeconds *int32 `json:"debounceSeconds,omitempty"`
+
+	// _(Optional)_ Paths of the spec fields, separated by dots and relative to the spec,
+	// e.g. `job.resources` or `taskManager.podAnnotations`, whose changes do not trigger an
+	// update. A new revision of the spec is still recorded, but it becomes the current revision
+	// without the savepoint and the restart of the job, and the ignored changes are only
+	// applied with the next update. Useful when external controllers patch the cluster.
+	IgnoreFields []string `json:"ignoreFields,omitempty"`
+}
+
+// IdlePolicy defines the scale-to-zero of an idle session cluster. The jobs of the cluster are
+// observed through the Flink REST API, and the cluster is woken up with the `wake-up` user
+// control, e.g. before jobs are submitted to it.
+type IdlePolicy struct {
+	// Minutes without running jobs after which the TaskManagers are scaled to zero.
+	// +kubebuilder:validation:Minimum=1
+	IdleMinutes int32 `json:"idleMinutes"`
+
+	// _(Optional)_ Scale the JobManager to zero as well, in which case the REST API is not
+	// available until the cluster is woken up. Otherwise jobs submitted to the idle cluster
+	// wake it up as they wait for TaskManagers. Default: false.
+	ScaleJobManager *bool `json:"scaleJobManager,omitempty"`
+}
+
+// UpdateWindow defines a recurring window in which the disruptive updates of the cluster,
+// e.g. the restarts of its job, are allowed. The spec changes observed outside the window
+// are recorded as the next revision, and the update starts once the window opens. An update
+// which started in the window continues when the window closes.
+type UpdateWindow struct {
+	// Schedule of the openings of the window in the standard 5-field cron format
+	// `minute hour day-of-month month day-of-week`, e.g. `0 2 * * 6` for 2 AM on Saturdays.
+	Schedule string `json:"schedule"`
+
+	// Seconds the window stays open after each opening, at most 7 days.
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:validation:Maximum=604800
+	DurationSeconds int32 `json:"durationSeconds"`
+
+	// _(Optional)_ Time zone of the schedule, a name of the IANA time zone database,
+	// e.g. `Europe/Stockholm`, default: `spec.timezone` of the cluster, else UTC.
+	Timezone *string `json:"timezone,omitempty"`
+}
+
+// JobSLO defines the service level objectives of a running job. The operator evaluates
+// them on every reconciliation, and reports the violations with the `SLOViolated`
+// condition of the cluster and the `flink_operator_job_slo_violated` metric.
+type JobSLO struct {
+	// _(Optional)_ The maximum age in seconds of the latest completed checkpoint,
+	// or of the job if no checkpoint completed yet.
+	// +kubebuilder:validation:Minimum=1
+	MaxCheckpointAgeSeconds *int32 `json:"maxCheckpointAgeSeconds,omitempty"`
+
+	// _(Optional)_ The maximum number of restarts of the job by its restart strategy
+	// within the last hour.
+	// +kubebuilder:validation:Minimum=0
+	MaxRestartRatePerHour *int32 `json:"maxRestartRatePerHour,omitempty"`
+
+	// _(Optional)_ The maximum consumer lag of the job, read from a metric of its tasks.
+	MaxConsumerLag *ConsumerLagObjective `json:"maxConsumerLag,omitempty"`
+}
+
+// ConsumerLagObjective defines the maximum consumer lag of a job.
+type ConsumerLagObjective struct {
+	// Name of the task metric which measures the lag, e.g. `pendingRecords` of the Kafka
+	// sources. The metrics whose IDs equal the name or end with `.<name>` are selected,
+	// e.g. the operator metric `Source__orders.pendingRecords`.
+	Metric string `json:"metric"`
+
+	// How the metric is aggregated over the subtasks and the vertices of the job,
+	// `Sum` or `Max`, default: `Sum`.
+	// +kubebuilder:validation:Enum=Sum;Max
+	// +kubebuilder:default:=Sum
+	Aggregation ConsumerLagAggregation `json:"aggregation,omitempty"`
+
+	// The maximum value of the aggregated metric.
+	// +kubebuilder:validation:Minimum=0
+	Max int64 `json:"max"`
+}
+
+// JobReadinessGate defines a check which must pass before the job is submitted, e.g. that
+// the input data of the job is available. Exactly one of `httpGet`, `kafkaTopic` and
+// `configMapKey` must be set.
+type JobReadinessGate struct {
+	// Name of the gate, recorded in the job status while the gate blocks the submission.
+	Name string `json:"name"`
+
+	// _(Optional)_ HTTP GET request which must return a 2xx status code.
+	HTTPGet *HTTPReadinessGate `json:"httpGet,omitempty"`
+
+	// _(Optional)_ Kafka topic which must exist.
+	KafkaTopic *KafkaTopicReadinessGate `json:"kafkaTopic,omitempty"`
+
+	// _(Optional)_ Key of a ConfigMap in the namespace of the cluster which must exist,
+	// e.g. a data marker published by the upstream pipeline.
+	ConfigMapKey *ConfigMapKeyReadinessGate `json:"configMapKey,omitempty"`
+}
+
+// HTTPReadinessGate defines the HTTP GET request of a job readiness gate.
+type HTTPReadinessGate struct {
+	// The `http://` or `https://` URL to request. Its host must be a Service of the namespace
+	// of the cluster, e.g. `<service>.<namespace>.svc`, unless the operator allows it with
+	// `--readiness-gate-allowed-hosts`. Redirects are not followed.
+	URL string `json:"url"`
+
+	// _(Optional)_ Seconds after which the request times out, default: 3.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// KafkaTopicReadinessGate defines the Kafka topic of a job readiness gate.
+type KafkaTopicReadinessGate struct {
+	// Comma separated `host:port` addresses of the Kafka brokers.
+	BootstrapServers string `json:"bootstrapServers"`
+
+	// Name of the topic.
+	Topic string `json:"topic"`
+}
+
+// ConfigMapKeyReadinessGate defines the ConfigMap key of a job readiness gate.
+type ConfigMapKeyReadinessGate struct {
+	// Name of the ConfigMap.
+	Name string `json:"name"`
+
+	// The key of the ConfigMap.
+	Key string `json:"key"`
+
+	// _(Optional)_ The value the key must have. If unspecified, any value passes.
+	Value *string `json:"value,omitempty"`
+}
+
+// ArtifactCacheSpec defines the volume in which remote job artifacts are cached.
+// Exactly one of `persistentVolumeClaim` and `hostPath` must be set.
+// Artifacts are cached by their URI, so the URIs must be immutable.
+type ArtifactCacheSpec struct {
+	// _(Optional)_ PersistentVolumeClaim in which the artifacts are cached. It must support
+	// `ReadWriteMany` access to be shared by submitters running on different nodes.
+	PersistentVolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
+
+	// _(Optional)_ Directory on the node in which the artifacts are cached,
+	// shared by the submitters running on the same node.
+	HostPath *string `json:"hostPath,omitempty"`
+}
+
+// MavenRepositorySpec defines the Maven repository `mvn:` JAR files are fetched from.
+type MavenRepositorySpec struct {
+	// _(Optional)_ URL of the repository. Default: Maven Central, `https://repo1.maven.org/maven2`.
+	URL *string `json:"url,omitempty"`
+
+	// _(Optional)_ Secret in the namespace of the cluster with the `username` and `password`
+	// of the repository.
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// GitRepoSpec defines the Git repository the code of a job is checked out from.
+type GitRepoSpec struct {
+	// URL of the repository, e.g. `https://github.com/example/jobs.git` or
+	// `git@github.com:example/jobs.git` for SSH.
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// _(Optional)_ Branch, tag or commit to check out. Pin a tag or a commit for the restarts of
+	// the job to run the same code. Default: `HEAD`, the default branch.
+	Ref *string `json:"ref,omitempty"`
+
+	// _(Optional)_ Directory in the repository which is the working directory of the job
+	// submitter, in which relative `jarFile`, `pyFile`, `pyFiles` and `args` are resolved.
+	// Default: the root of the repository.
+	Path *string `json:"path,omitempty"`
+
+	// _(Optional)_ Secret in the namespace of the cluster with the credentials of the
+	// repository: `username` and `password` for HTTPS, `ssh-privatekey` and `known_hosts` for SSH.
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// _(Optional)_ Image of git-sync. Default: `registry.k8s.io/git-sync/git-sync:v4.2.4`.
+	Image *string `json:"image,omitempty"`
+}
+
+// JobArgSource defines a command-line arg of the job. Exactly one of Value, ConfigMapKeyRef
+// and SecretKeyRef must be set.
+type JobArgSource struct {
+	// _(Optional)_ Literal arg, e.g. the option whose value is the next arg.
+	Value *string `json:"value,omitempty"`
+
+	// _(Optional)_ Key of a ConfigMap in the namespace of the cluster.
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// _(Optional)_ Key of a Secret in the namespace of the cluster.
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
 
 // JobSpec defines properties of a Flink job.
@@ -537,10 +1447,34 @@ type JobSpec struct {
 	ClassPath []string `json:"classPath,omitempty"`
 
 	// _(Optional)_ JAR file of the job. It could be a local file or remote URI,
-	// depending on which protocols (e.g., `https://, gs://`) are supported by the Flink image.
+	// depending on which protocols (e.g., `https://, gs://`) are supported by the Flink image,
+	// or Maven coordinates `mvn:<groupId>:<artifactId>:<version>[:<classifier>]`, which an
+	// init container of the job submitter fetches from `mavenRepository` and verifies against
+	// its SHA-1 checksum. SNAPSHOT versions are not supported.
 	JarFile *string `json:"jarFile,omitempty"`
 
-	// _(Optional)_ Fully qualified Java class name of the job.
+	// _(Optional)_ Expected SHA-256 checksum in hex of the JAR file fetched by the init
+	// container of the job submitter, i.e. Maven coordinates or a remote JAR file with
+	// `artifactCache`. It is verified instead of the SHA-1 checksum of the Maven repository,
+	// also on the JAR file found in the cache.
+	JarFileSHA256 string `json:"jarFileSHA256,omitempty"`
+
+	// _(Optional)_ Maven repository of the `mvn:` JAR file.
+	MavenRepository *MavenRepositorySpec `json:"mavenRepository,omitempty"`
+
+	// _(Optional)_ Cache of the remote `http://` or `https://` JAR file. The job submitter
+	// downloads the JAR file into the cache once and the following submissions, e.g. on
+	// job restarts and updates, use the cached file.
+	ArtifactCache *ArtifactCacheSpec `json:"artifactCache,omitempty"`
+
+	// _(Optional)_ Git repository of the code of the job, e.g. Python files or SQL scripts.
+	// An init container of the job submitter checks it out with git-sync on each submission,
+	// into the working directory of the submitter. Not supported in application mode.
+	GitRepo *GitRepoSpec `json:"gitRepo,omitempty"`
+
+	// _(Optional)_ Fully qualified Java class name of the job. If unspecified, the job submitter
+	// resolves it from the `program-class` entry of the manifest of `jarFile`, or else from its
+	// `Main-Class` entry.
 	ClassName *string `json:"className,omitempty"`
 
 	// _(Optional)_ Python file of the job. It could be a local file or remote URI (e.g.,`https://`, `gs://`).
@@ -556,6 +1490,14 @@ type JobSpec struct {
 	// _(Optional)_ Command-line args of the job.
 	Args []string `json:"args,omitempty"`
 
+	// _(Optional)_ Command-line args of the job appended to `args` in order, given literally or
+	// by ConfigMap and Secret keys, so that args such as tokens are not stored in the cluster
+	// spec. Each key is a single arg, passed to the container through an environment variable.
+	// The args of Secret keys are masked in the log of the job submitter, but they are still in
+	// the command line of the processes, which anyone with access to the node or the pod can
+	// read.
+	ArgsFrom []JobArgSource `json:"argsFrom,omitempty"`
+
 	// _(Optional)_ FromSavepoint where to restore the job from
 	// Savepoint where to restore the job from (e.g., gs://my-savepoint/1234).
 	// If flink job must be restored from the latest available savepoint when Flink job updating, this field must be unspecified.
@@ -572,6 +1514,27 @@ type JobSpec struct {
 	// If this is set as false, maxStateAgeToRestoreSeconds must be provided to limit the savepoint age to restore.
 	TakeSavepointOnUpdate *bool `json:"takeSavepointOnUpdate,omitempty"`
 
+	// _(Optional)_ How the running job is stopped for an update: `StopWithSavepoint`,
+	// `CancelWithSavepoint` or `Cancel`. Defaults to `Cancel` if `takeSavepointOnUpdate` is
+	// false, to `CancelWithSavepoint` for Flink versions before 1.9, and to `StopWithSavepoint`
+	// otherwise. The savepoint modes require `takeSavepointOnUpdate` not to be false, and
+	// `Cancel` requires it to be false.
+	// +kubebuilder:validation:Enum=StopWithSavepoint;CancelWithSavepoint;Cancel
+	UpdateStopMode *JobUpdateStopMode `json:"updateStopMode,omitempty"`
+
+	// _(Optional)_ How savepoints are triggered: `REST`, through the Flink REST API of the
+	// JobManager, `Job`, by a Kubernetes Job running the Flink CLI of the image next to the
+	// cluster, for JobManagers the operator cannot reach, e.g. behind strict NetworkPolicies,
+	// or `Auto`, through the REST API and by a Job when the operator fails to connect to the
+	// JobManager. Default: `Auto`.
+	// +kubebuilder:validation:Enum=Auto;REST;Job
+	SavepointTriggerMode *SavepointTriggerMode `json:"savepointTriggerMode,omitempty"`
+
+	// _(Optional)_ For fine-grained resource management, converts all data exchanges of the job to
+	// blocking ones, so that a batch job can run region by region with fewer slots than needed to run
+	// all of its tasks at once. Only applies when `taskManager.slotResources` is set.
+	AllBlockingShuffle *bool `json:"allBlockingShuffle,omitempty"`
+
 	// _(Optional)_ Maximum age of the savepoint that allowed to restore state.
 	// This is applied to auto restart on failure, update from stopped state and update without taking savepoint.
 	// If nil, job can be restarted only when the latest savepoint is the final job state (created by "stop with savepoint")
@@ -579,14 +1542,37 @@ type JobSpec struct {
 	// +kubebuilder:validation:Minimum=0
 	MaxStateAgeToRestoreSeconds *int32 `json:"maxStateAgeToRestoreSeconds,omitempty"`
 
+	// _(Optional)_ Write the ownership metadata of each savepoint taken of the job next to
+	// it, as `<savepoint>.owner.json`, and verify the metadata of the savepoint to restore
+	// the job from before the job is submitted, so that the state of another pipeline is not
+	// restored by accident. Requires `savepointsDir` on `gs://`, `s3://`, `http://` or
+	// `https://` storage.
+	SavepointOwnership *SavepointOwnership `json:"savepointOwnership,omitempty"`
+
+	// _(Optional)_ Probe that `savepointsDir` is writable and `fromSavepoint` is readable
+	// before the job is first submitted and after each change of the spec, so that a missing
+	// bucket or permission fails fast with the `StorageUnavailable` condition instead of the
+	// job failing on its first checkpoint. The job is not submitted while the probe fails.
+	// The condition is unknown, without blocking the job, if a location could not be probed:
+	// it is not on `gs://`, `s3://`, `s3a://`, `s3p://`, `http://` or `https://` storage, or
+	// the operator has no credentials for it.
+	StorageProbe *StorageProbe `json:"storageProbe,omitempty"`
+
 	// _(Optional)_ Automatically take a savepoint to the `savepointsDir` every n seconds.
 	AutoSavepointSeconds *int32 `json:"autoSavepointSeconds,omitempty"`
 
+	// _(Optional)_ Automatically take a savepoint to the `savepointsDir` when the state of the
+	// job changed enough since the last savepoint, or before the nodes of the cluster are
+	// drained, in addition to `autoSavepointSeconds`.
+	AdaptiveSavepoint *AdaptiveSavepointSpec `json:"adaptiveSavepoint,omitempty"`
+
 	// _(Optional)_ Update this field to `jobStatus.savepointGeneration + 1` for a running job
 	// cluster to trigger a new savepoint to `savepointsDir` on demand.
 	SavepointGeneration int32 `json:"savepointGeneration,omitempty"`
 
 	// _(Optional)_ Job parallelism; if not set parallelism will be #replicas * #slots.
+	// It must not exceed the task slots of the TaskManagers, unless the adaptive scheduler
+	// or the reactive mode is used.
 	Parallelism *int32 `json:"parallelism,omitempty"`
 
 	// No logging output to STDOUT, default: `false`.
@@ -632,10 +1618,49 @@ type JobSpec struct {
 	// +kubebuilder:validation:Enum=Never;FromSavepointOnFailure
 	RestartPolicy *JobRestartPolicy `json:"restartPolicy,omitempty"`
 
+	// _(Optional)_ The maximum number of runs of the job to record in
+	// `status.components.job.runs`, the oldest runs are dropped first. 0 records no runs.
+	// Default: 10 if `restartPolicy` is `FromSavepointOnFailure`, otherwise 0.
+	// +kubebuilder:validation:Minimum=0
+	RunHistoryLimit *int32 `json:"runHistoryLimit,omitempty"`
+
 	// The action to take after job finishes.
 	// +kubebuilder:default:={afterJobSucceeds:DeleteCluster, afterJobFails:KeepCluster, afterJobCancelled:DeleteCluster}
 	CleanupPolicy *CleanupPolicy `json:"cleanupPolicy,omitempty"`
 
+	// _(Optional)_ The criteria for the terminated job to be regarded as succeeded,
+	// default: the job succeeds only when it finishes.
+	SuccessPolicy *JobSuccessPolicy `json:"successPolicy,omitempty"`
+
+	// _(Optional)_ Verifies the job started from a savepoint: it must complete a checkpoint
+	// within the timeout without restarting more than allowed. Otherwise the job is stopped
+	// without a savepoint and regarded as failed, and it is not restarted from the savepoint
+	// by `restartPolicy`, or the spec is rolled back by `onFailure`.
+	RestoreVerification *RestoreVerification `json:"restoreVerification,omitempty"`
+
+	// _(Optional)_ The service level objectives of the running job, e.g. the maximum age
+	// of its latest checkpoint.
+	SLO *JobSLO `json:"slo,omitempty"`
+
+	// _(Optional)_ Store the JSON plan of the job, its job graph, once each submitted job is
+	// running, e.g. for lineage tools or to diff the graphs of revisions offline. The plans
+	// are stored in the `<cluster>-job-plan` ConfigMap, one `<revision>.json` key for each
+	// revision of the revision history, unless they are uploaded to object storage.
+	PlanArchive *JobPlanArchive `json:"planArchive,omitempty"`
+
+	// _(Optional)_ Checks which must all pass before the job submitter is created, or the
+	// JobManager in `Application` mode, e.g. that the input data published by the upstream
+	// pipeline is available. The gates are checked in order on every submission of the job,
+	// including restarts and updates, and the first failing gate is recorded in the job status.
+	ReadinessGates []JobReadinessGate `json:"readinessGates,omitempty"`
+
+	// _(Optional)_ Seconds after which the finished job submitter and its pod are
+	// deleted by the operator. The job status is recorded before the deletion.
+	// If unspecified, the submitter is kept until the next job submission or
+	// until the cluster is deleted. Not applicable to `Application` mode.
+	// +kubebuilder:validation:Minimum=0
+	SubmitterTTLSecondsAfterFinished *int32 `json:"submitterTTLSecondsAfterFinished,omitempty"`
+
 	// Deprecated: _(Optional)_ Request the job to be cancelled. Only applies to running jobs. If
 	// `savePointsDir` is provided, a savepoint will be taken before stopping the
 	// job.
@@ -660,6 +1685,12 @@ type JobSpec struct {
 	// [More info](https://kubernetes.io/docs/tasks/configure-pod-container/security-context/#set-the-security-context-for-a-pod)
 	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
 
+	// _(Optional)_ Seconds the Job pod is given to shut down gracefully before it is killed.
+	// If unspecified, the Kubernetes default of 30 seconds is used.
+	// [More info](https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle/#pod-termination)
+	// +kubebuilder:validation:Minimum=0
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
 	// _(Optional)_ Adding entries to Job pod /etc/hosts with HostAliases
 	// [More info](https://kubernetes.io/docs/tasks/network/customize-hosts-file-for-pods/)
 	HostAliases []corev1.HostAlias `json:"hostAliases,omitempty"`
@@ -692,12 +1723,25 @@ type BatchSchedulerSpec struct {
 // FlinkClusterSpec defines the desired state of FlinkCluster
 type FlinkClusterSpec struct {
 	// The version of Flink to be managed. This version must match the version in the image.
+	// Required unless inherited from the template of `clusterRef`.
+	// +optional
 	FlinkVersion string `json:"flinkVersion"`
 
 	// Flink image for JobManager, TaskManager and job containers.
+	// Required unless inherited from the template of `clusterRef`.
+	// +optional
 	Image ImageSpec `json:"image"`
 
+	// _(Optional)_ The CPU architecture of the nodes the JobManager, TaskManager and job
+	// submitter pods are scheduled on, one of `amd64`, `arm64` or `any`. The pods require
+	// nodes with the `kubernetes.io/arch` label of the architecture, in addition to their
+	// affinity. If the webhook of the operator can read the image manifest from its registry,
+	// images which are not built for the architecture are rejected. Default: `any`.
+	// +kubebuilder:validation:Enum=amd64;arm64;any
+	Architecture *Architecture `json:"architecture,omitempty"`
+
 	// _(Optional)_ The service account assigned to JobManager, TaskManager and Job submitter Pods. If empty, the default service account in the namespace will be used.
+	// If empty and Kubernetes HA services are enabled, the operator creates a service account allowed to edit ConfigMaps.
 	ServiceAccountName *string `json:"serviceAccountName,omitempty"`
 
 	// Deprecated: BatchSchedulerName specifies the batch scheduler name for JobManager, TaskManager.
@@ -725,6 +1769,21 @@ type FlinkClusterSpec struct {
 	// otherwise, it is a long-running Session Cluster.
 	Job *JobSpec `json:"job,omitempty"`
 
+	// _(Optional)_ A session cluster in the same namespace, annotated with
+	// `flinkclusters.flinkoperator.k8s.io/template: "true"`, whose spec this job cluster
+	// inherits, e.g. the image, the JobManager, the TaskManager and the Flink properties. The
+	// fields unset in this spec are taken from the template when the cluster is created: maps
+	// are merged key by key and lists are taken as a whole. The CRD defaults of this spec,
+	// e.g. the replicas of the JobManager and TaskManager, are not inherited. Requires the
+	// webhook of the operator. Only applicable to job clusters.
+	ClusterRef *corev1.LocalObjectReference `json:"clusterRef,omitempty"`
+
+	// _(Optional)_ JAR files to upload to the JobManager of a session cluster, so that jobs
+	// can be submitted to it through the Flink REST API by the JAR IDs in `status.jars`.
+	// JAR files removed from the list are deleted from the JobManager.
+	// Not applicable to job clusters.
+	Jars []SessionJar `json:"jars,omitempty"`
+
 	// _(Optional)_ Environment variables shared by all JobManager, TaskManager and job
 	// containers.
 	// [More info](https://kubernetes.io/docs/tasks/inject-data-application/define-environment-variable-container/)
@@ -735,15 +1794,76 @@ type FlinkClusterSpec struct {
 	// [More info](https://kubernetes.io/docs/tasks/configure-pod-container/configure-pod-configmap/#configure-all-key-value-pairs-in-a-configmap-as-container-environment-variables)
 	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
 
+	// _(Optional)_ Time zone of the JobManager, TaskManager and job containers, a name of
+	// the IANA time zone database, e.g. `Europe/Stockholm`. Sets the `TZ` environment
+	// variable and the `user.timezone` JVM system property through `env.java.opts`.
+	// Default: the time zone of the image, usually UTC.
+	Timezone *string `json:"timezone,omitempty"`
+
+	// _(Optional)_ Egress proxy and trusted certificate authorities of the JobManager,
+	// TaskManager and job submitter pods, e.g. behind a TLS-intercepting corporate proxy, and
+	// the IP families of the JobManager and TaskManager services.
+	Networking *NetworkingSpec `json:"networking,omitempty"`
+
+	// _(Optional)_ Collection of JVM diagnostics of the JobManager and TaskManagers: heap
+	// dumps on OutOfMemoryError and flight recordings requested with the `flight-recording`
+	// user control. If unspecified, no diagnostics are collected.
+	Diagnostics *DiagnosticsSpec `json:"diagnostics,omitempty"`
+
+	// _(Optional)_ Export of the state of each revision for audit and restore: once the
+	// revision is running, the cluster with its status and the resources the operator
+	// derives from its spec are uploaded as a single multi-document YAML file. If
+	// unspecified, nothing is exported.
+	StateExport *StateExportSpec `json:"stateExport,omitempty"`
+
+	// _(Optional)_ Monitoring of the cluster with Prometheus.
+	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
+
 	// _(Optional)_ Flink properties which are appened to flink-conf.yaml.
 	FlinkProperties map[string]string `json:"flinkProperties,omitempty"`
 
+	// _(Optional)_ Sources of Flink properties resolved by the operator, whose values are
+	// kept out of the cluster spec and the Flink ConfigMap: the keys of Secrets and of the
+	// secrets of external secret stores are Flink property names. Later sources override
+	// earlier ones and all of them override `flinkProperties`. The resolved properties
+	// are stored in a Secret of the cluster and appended to flink-conf.yaml by an init
+	// container of the JobManager and TaskManager pods. The cluster is updated when they
+	// change, e.g. when a secret is rotated.
+	FlinkPropertiesFrom []FlinkPropertiesSource `json:"flinkPropertiesFrom,omitempty"`
+
 	// _(Optional)_ Config for Hadoop.
 	HadoopConfig *HadoopConfig `json:"hadoopConfig,omitempty"`
 
 	// _(Optional)_ Config for GCP.
 	GCPConfig *GCPConfig `json:"gcpConfig,omitempty"`
 
+	// _(Optional)_ Encryption at rest of the checkpoints and savepoints with a key of the
+	// object storage, e.g. for regulated data. The operator sets the encryption properties of
+	// the file system plugins and provides the credentials of the key to the JobManager and
+	// TaskManagers, which must not be set in `flinkProperties`.
+	StateEncryption *StateEncryptionSpec `json:"stateEncryption,omitempty"`
+
+	// _(Optional)_ Built-in plugins of the Flink image to enable, any of `s3-fs-hadoop`,
+	// `s3-fs-presto`, `azure-fs-hadoop`, `gs-fs-hadoop` and `oss-fs-hadoop`. An init container
+	// of the JobManager and TaskManager pods copies the JAR file of each plugin from the opt
+	// directory of the image into its own directory under plugins, like the
+	// `ENABLE_BUILT_IN_PLUGINS` environment variable of the official images but without the
+	// full version of Flink in the name of the file.
+	// [More info](https://nightlies.apache.org/flink/flink-docs-stable/docs/deployment/filesystems/plugins/)
+	FlinkPlugins []FlinkPlugin `json:"flinkPlugins,omitempty"`
+
+	// _(Optional)_ Keys of existing ConfigMaps or Secrets to project into the Flink conf
+	// directory (/opt/flink/conf) alongside the generated flink-conf.yaml, e.g. core-site.xml
+	// or krb5.conf. Projected files must not collide with the generated ones.
+	// [More info](https://kubernetes.io/docs/concepts/storage/projected-volumes/)
+	ExtraConfigMounts []ExtraConfigMount `json:"extraConfigMounts,omitempty"`
+
+	// _(Optional)_ Secret keys substituted for `${PLACEHOLDER}` references in the values of
+	// `flinkProperties`, e.g. in `properties.sasl.jaas.config` of a Kafka connector. An init
+	// container of the JobManager and TaskManager pods renders flink-conf.yaml with the keys,
+	// so that the credentials are not written to the cluster spec or the Flink ConfigMap.
+	SecretsInjection []SecretInjection `json:"secretsInjection,omitempty"`
+
 	// _(Optional)_ The logging configuration, which should have keys 'log4j-console.properties' and 'logback-console.xml'.
 	// These will end up in the 'flink-config-volume' ConfigMap, which gets mounted at /opt/flink/conf.
 	// If not provided, defaults that log to console only will be used.
@@ -752,12 +1872,407 @@ type FlinkClusterSpec struct {
 	// <br> - Other arbitrary keys are also allowed, and will become part of the ConfigMap.
 	LogConfig map[string]string `json:"logConfig,omitempty"`
 
-	// The maximum number of revision history to keep, default: 10.
+	// _(Optional)_ An existing ConfigMap which replaces the generated Flink configuration,
+	// for configurations managed by another system. Its files are copied to the Flink conf
+	// directory as they are, over the generated ones, by an init container of the JobManager
+	// and TaskManager pods; only the addressing properties of the JobManager and TaskManager,
+	// e.g. `jobmanager.rpc.address` and `rest.port`, are appended to its flink-conf.yaml. The
+	// properties derived from the other fields, e.g. the memory sizes and `jvmOptions`, are
+	// not set, and `flinkProperties` must be empty.
+	ConfigOverride *ConfigOverrideSpec `json:"configOverride,omitempty"`
+
+	// _(Optional)_ Shipping of the log files of the JobManager and TaskManagers, which do not
+	// reach `kubectl logs` unlike the console logs.
+	Logging *LoggingSpec `json:"logging,omitempty"`
+
+	// The maximum number of revision history to keep, default: 10. The oldest revisions are
+	// deleted first, except the current and the next revisions of an update in progress.
+	// +kubebuilder:validation:Minimum=0
 	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
 
 	// Recreate components when updating flinkcluster, default: true.
 	// +kubebuilder:default:=true
 	RecreateOnUpdate *bool `json:"recreateOnUpdate,omitempty"`
+
+	// _(Optional)_ Update the TaskManagers of the session cluster with a canary: a part of
+	// them is updated first and verified before the others. The components are updated in
+	// place regardless of `recreateOnUpdate`. Only applicable to session clusters whose
+	// TaskManagers are deployed as a StatefulSet.
+	CanaryUpdate *CanaryUpdateSpec `json:"canaryUpdate,omitempty"`
+
+	// _(Optional)_ When the updates of the cluster are applied, e.g. only in a maintenance
+	// window. If unspecified, the cluster is updated as soon as its spec changes.
+	UpdatePolicy *UpdatePolicy `json:"updatePolicy,omitempty"`
+
+	// _(Optional)_ What happens to the components of the cluster when it is deleted. One of
+	// `Retain`, which orphans them so that the Flink cluster keeps running, `Delete`, which
+	// deletes them but retains the PersistentVolumeClaims of the volume claim templates, or
+	// `DeletePVCsAlso`, which deletes the PersistentVolumeClaims too. Default: `DeletePVCsAlso`.
+	// +kubebuilder:validation:Enum=Retain;Delete;DeletePVCsAlso
+	DeletionPolicy *DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// _(Optional)_ The time in seconds the components of the cluster have to become ready
+	// after it starts to be created, e.g. while their pods cannot pull the image or are
+	// unschedulable. Once the deadline passes, the `StartupDeadlineExceeded` condition
+	// records the reason and `startupDeadlineAction` applies. The deadline restarts when the
+	// spec is updated. If unspecified, the cluster is created without a deadline.
+	// +kubebuilder:validation:Minimum=1
+	StartupDeadlineSeconds *int32 `json:"startupDeadlineSeconds,omitempty"`
+
+	// _(Optional)_ What happens when `startupDeadlineSeconds` passes, one of
+	// `StopReconciling`, which stops the reconciliation until the spec is updated, or
+	// `Cleanup`, which fails the job of a job cluster so that
+	// `job.cleanupPolicy.afterJobFails` applies. Default: `StopReconciling`.
+	// +kubebuilder:validation:Enum=StopReconciling;Cleanup
+	StartupDeadlineAction *StartupDeadlineAction `json:"startupDeadlineAction,omitempty"`
+
+	// _(Optional)_ Scale-to-zero of the session cluster while it runs no jobs. If unspecified,
+	// the cluster keeps running when idle. Only applicable to session clusters.
+	IdlePolicy *IdlePolicy `json:"idlePolicy,omitempty"`
+
+	// _(Optional)_ Update the cluster when the contents of the ConfigMaps and Secrets
+	// referenced by the spec change, as if the spec had been updated: `hadoopConfig`,
+	// `gcpConfig`, `extraConfigMounts`, `configOverride`, `secretsInjection`, `envFrom`,
+	// `networking.caBundle`, `job.argsFrom` and the ConfigMap and Secret volumes of the
+	// JobManager and TaskManager. Job clusters take a savepoint before the update as with spec
+	// updates.
+	// Default: false.
+	UpdateOnReferencedConfigChange *bool `json:"updateOnReferencedConfigChange,omitempty"`
+
+	// _(Optional)_ Adopt the existing components named after the cluster, e.g. deployed by
+	// another operator or by hand, by setting the cluster as their owner. The adopted
+	// components keep their spec until the next update of the cluster, so that their pods
+	// are not restarted. Otherwise the cluster fails to reconcile existing components it
+	// does not own. Components owned by another controller are never adopted. Default: false.
+	AdoptExisting *bool `json:"adoptExisting,omitempty"`
+
+	// _(Optional)_ Disable the creation of components which are managed externally, e.g. an
+	// Istio VirtualService instead of the ingress. If unspecified, all components are created.
+	Components *ComponentsSpec `json:"components,omitempty"`
+
+	// _(Optional)_ Pause the reconciliation of the cluster, e.g. to freeze it during an
+	// incident. The operator keeps observing the cluster and updating its status with the
+	// `Paused` condition, but it does not create, update or delete components, submit or
+	// cancel jobs, take savepoints or act on user controls until the cluster is resumed. The
+	// deletion of a paused cluster is not finalized either. Changing this field does not
+	// update the cluster. The annotation `flinkclusters.flinkoperator.k8s.io/paused: "true"`
+	// pauses the cluster too. Default: false.
+	Paused *bool `json:"paused,omitempty"`
+
+	// _(Optional)_ FlinkClusters which must be ready before the cluster is started, e.g. the
+	// job clusters of the previous steps of a pipeline. Until then, the cluster stays `Queued`
+	// with the `WaitingForDependencies` condition telling which dependency it waits for. The
+	// dependencies are not checked again once the cluster is started, and changing them does
+	// not update the cluster.
+	DependsOn []ClusterDependency `json:"dependsOn,omitempty"`
+}
+
+// ClusterDependency defines a FlinkCluster which must be ready before the cluster is started.
+type ClusterDependency struct {
+	// Name of the FlinkCluster in the namespace of the cluster.
+	Name string `json:"name"`
+
+	// _(Optional)_ When the FlinkCluster is ready: `Running`, while the cluster is `Running`
+	// or `Degraded`, or `Succeeded`, once its job succeeded, even if the FlinkCluster is
+	// deleted afterwards. Default: `Running`.
+	// +kubebuilder:validation:Enum=Running;Succeeded
+	State *ClusterDependencyState `json:"state,omitempty"`
+}
+
+// ComponentsSpec defines which components of the cluster the operator creates. A disabled
+// component is neither created nor updated, and an existing object of its name which is not
+// owned by the cluster is left alone. The JobManager service, the TaskManager service and the
+// ConfigMap are still referenced by the cluster with their names, e.g.
+// `<cluster>-jobmanager`, and need to be created externally with these names.
+type ComponentsSpec struct {
+	// Create the JobManager service, default: true. The operator calls the Flink REST API
+	// through the service.
+	CreateJmService *bool `json:"createJmService,omitempty"`
+
+	// Create the TaskManager service, default: true.
+	CreateTmService *bool `json:"createTmService,omitempty"`
+
+	// Create the ingress of `jobManager.ingress`, default: true.
+	CreateIngress *bool `json:"createIngress,omitempty"`
+
+	// Create the PodDisruptionBudget of `podDisruptionBudget`, default: true.
+	CreatePDB *bool `json:"createPDB,omitempty"`
+
+	// Create the ConfigMap of the Flink configuration and the log configuration mounted by
+	// the pods, default: true.
+	CreateConfigMap *bool `json:"createConfigMap,omitempty"`
+}
+
+// SessionJar defines a JAR file uploaded to the JobManager of a session cluster.
+type SessionJar struct {
+	// Name to reference the JAR file by, unique in the cluster.
+	Name string `json:"name"`
+
+	// URI to download the JAR file from, one of `http://`, `https://`, `gs://` or `s3://`.
+	// The file is downloaded by the `<cluster>-jar-uploader` Job, with the credentials of the
+	// service account of the cluster for `gs://` and `s3://` objects.
+	URI string `json:"uri"`
+
+	// _(Optional)_ Expected SHA-256 checksum of the JAR file in hex. If set, the upload is
+	// rejected on mismatch, and the file is not downloaded again while a JAR file with the
+	// same checksum is uploaded.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// NetworkingSpec defines the egress proxy and the trusted certificate authorities of the pods,
+// and the IP families of the services.
+type NetworkingSpec struct {
+	// _(Optional)_ HTTP proxy of the outbound connections of the pods.
+	Proxy *ProxySpec `json:"proxy,omitempty"`
+
+	// _(Optional)_ Certificate authorities trusted by the JVMs of the pods in addition to
+	// the ones of the image, e.g. the CA of a TLS-intercepting proxy.
+	CABundle *CABundleSpec `json:"caBundle,omitempty"`
+
+	// _(Optional)_ IP family policy of the JobManager and TaskManager services, one of
+	// `SingleStack, PreferDualStack, RequireDualStack`. Default: the default of the Kubernetes
+	// cluster, usually `SingleStack`.
+	// [More info](https://kubernetes.io/docs/concepts/services-networking/dual-stack/#services)
+	// +kubebuilder:validation:Enum=SingleStack;PreferDualStack;RequireDualStack
+	IPFamilyPolicy *corev1.IPFamilyPolicy `json:"ipFamilyPolicy,omitempty"`
+
+	// _(Optional)_ IP families of the JobManager and TaskManager services in order of
+	// preference, e.g. `[IPv6]` on IPv6-only clusters or `[IPv6, IPv4]` on dual-stack clusters.
+	// The first family cannot be changed once the services are created.
+	// +kubebuilder:validation:MaxItems=2
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
+
+	// _(Optional)_ DNS domain of the Kubernetes cluster, e.g. `k8s.example.com` on clusters
+	// whose domain is not `cluster.local`. If set, `jobmanager.rpc.address` and the JobManager
+	// address of the job submitter are the fully qualified name of the JobManager service in
+	// the domain, instead of the service name. Default: the `CLUSTER_DOMAIN` environment
+	// variable of the operator.
+	ClusterDomain *string `json:"clusterDomain,omitempty"`
+}
+
+// ProxySpec defines the HTTP proxy of the pods. The URLs are set as the `HTTP_PROXY`,
+// `HTTPS_PROXY` and `NO_PROXY` environment variables of all containers, in upper and lower
+// case, and as the `http.proxyHost`, `https.proxyHost` and `http.nonProxyHosts` JVM system
+// properties through `env.java.opts`. The JobManager service is never proxied.
+type ProxySpec struct {
+	// _(Optional)_ URL of the proxy of the HTTP requests, e.g. `http://proxy.example.com:3128`.
+	HTTPProxy *string `json:"httpProxy,omitempty"`
+
+	// _(Optional)_ URL of the proxy of the HTTPS requests.
+	HTTPSProxy *string `json:"httpsProxy,omitempty"`
+
+	// _(Optional)_ Comma separated hosts and domains which are not proxied, e.g.
+	// `localhost,.svc,.cluster.local`. A leading dot matches the subdomains of the domain.
+	NoProxy *string `json:"noProxy,omitempty"`
+}
+
+// CABundleSpec defines a ConfigMap key with PEM encoded certificates. An init container of
+// the pods imports them into a copy of the truststore of the image, which the JVMs use through
+// the `javax.net.ssl.trustStore` system property.
+type CABundleSpec struct {
+	// The name of the ConfigMap in the namespace of the cluster.
+	ConfigMapName string `json:"configMapName"`
+
+	// _(Optional)_ The key of the certificates in the ConfigMap. Default: `ca.crt`.
+	Key *string `json:"key,omitempty"`
+}
+
+// DiagnosticsSpec defines the collection of JVM diagnostics of the JobManager and TaskManagers.
+// The files are written to a volume mounted at `/opt/flink/diagnostics` and collected by
+// the diagnostics collector sidecar of the pods.
+type DiagnosticsSpec struct {
+	// _(Optional)_ Write a heap dump to the diagnostics volume when the JVM runs out of memory.
+	// Default: true.
+	HeapDumpOnOutOfMemory *bool `json:"heapDumpOnOutOfMemory,omitempty"`
+
+	// _(Optional)_ Exit the JVM when it runs out of memory, after the heap dump if any, so that
+	// the container is restarted rather than keep running after the error. Default: false.
+	ExitOnOutOfMemory *bool `json:"exitOnOutOfMemory,omitempty"`
+
+	// _(Optional)_ Duration of the flight recordings requested with the `flight-recording`
+	// user control. The recordings are started with `jcmd`, which must be in the image,
+	// e.g. an image based on a JDK rather than a JRE. Default: 60.
+	// +kubebuilder:validation:Minimum=1
+	FlightRecordingSeconds *int32 `json:"flightRecordingSeconds,omitempty"`
+
+	// _(Optional)_ Volume to write the diagnostics files to. Heap dumps can be as large as the
+	// heap. Default: an emptyDir volume, which survives container restarts but not pod deletions.
+	Volume *corev1.VolumeSource `json:"volume,omitempty"`
+
+	// _(Optional)_ Object storage to upload the heap dumps and the flight recordings to, which
+	// the diagnostics collector sidecar of the JobManager and TaskManager pods uploads with the
+	// credentials of their service account, and the thread dumps of the `thread-dump` user
+	// control, which the operator uploads with its credentials. If unspecified, the files are
+	// kept in the volume.
+	Upload *DiagnosticsUpload `json:"upload,omitempty"`
+}
+
+// DiagnosticsUpload defines the object storage to upload diagnostics files to.
+type DiagnosticsUpload struct {
+	// URI of the directory to upload the files to, one of `http://`, `https://`, `gs://` or
+	// `s3://`. The files are uploaded with HTTP PUT requests to
+	// `<uri>/<namespace>/<cluster>/<pod>/<file>`, `gs://` and `s3://` through the HTTPS
+	// endpoints of Cloud Storage and S3 with the credentials of the uploader, and removed from
+	// the volume once uploaded.
+	URI string `json:"uri"`
+
+	// _(Optional)_ Key of a Secret in the namespace of the cluster whose value is sent as the
+	// `Authorization` header of the uploads instead of the credentials of the uploader, e.g.
+	// `Bearer <token>`. Uploads to S3 are always signed with the credentials of the uploader.
+	AuthorizationSecret *corev1.SecretKeySelector `json:"authorizationSecret,omitempty"`
+}
+
+// StateExportSpec defines where the state of the revisions of a cluster is exported to.
+type StateExportSpec struct {
+	// Object storage to upload the state of each revision to, as
+	// `<uri>/<namespace>/<cluster>/<revision>/state.yaml`.
+	Upload DiagnosticsUpload `json:"upload"`
+
+	// _(Optional)_ Key of a Secret in the namespace of the cluster to sign the exported file
+	// with. The HMAC-SHA256 of the documents is written to the first line of the file as
+	// `# hmac-sha256: <hex>`. If unspecified, the file is not signed.
+	SigningKeySecret *corev1.SecretKeySelector `json:"signingKeySecret,omitempty"`
+}
+
+// LoggingSpec defines the shipping of the log files of the JobManager and TaskManagers.
+type LoggingSpec struct {
+	// _(Optional)_ Log forwarder, e.g. fluent-bit or vector, which runs as a sidecar of the
+	// JobManager and TaskManagers and ships the files of their log directory. If unspecified,
+	// only the console logs are available.
+	Sidecar *LogSidecarSpec `json:"sidecar,omitempty"`
+}
+
+// LogSidecarSpec defines the log forwarder sidecar. The log directory `/opt/flink/log` of the
+// Flink container is an emptyDir volume shared with the sidecar, mounted read-only at the same
+// path, or the volume the user mounts there. Flink writes `flink-*.log` there with a file appender in `logConfig`, and GC logs with
+// e.g. `-Xloggc:/opt/flink/log/gc.log` in `jvmOptions`.
+type LogSidecarSpec struct {
+	// Container of the log forwarder, which gets the environment variables `FLINK_CLUSTER`,
+	// `FLINK_COMPONENT`, `POD_NAME` and `POD_NAMESPACE` to tag the records with.
+	Template corev1.Container `json:"template"`
+
+	// _(Optional)_ Secret with the configuration of the log forwarder outputs, e.g. destinations
+	// and credentials, mounted at `/opt/flink-operator/log-forwarder` in the sidecar.
+	OutputSecret string `json:"outputSecret,omitempty"`
+}
+
+// MonitoringSpec defines the monitoring of the cluster with Prometheus.
+type MonitoringSpec struct {
+	// _(Optional)_ Expose the port of the Prometheus reporter of `reporters` or `flinkProperties`
+	// as the `metrics` port of the TaskManager containers and the TaskManager headless service,
+	// and annotate the TaskManager pods with `prometheus.io/scrape` and `prometheus.io/port`.
+	// The reporter with the first name is exposed if there are several. Default: false.
+	ExposeTaskManagerMetrics *bool `json:"exposeTaskManagerMetrics,omitempty"`
+
+	// _(Optional)_ Annotate the JobManager and TaskManager pods with `prometheus.io/scrape` and
+	// `prometheus.io/port` of the Prometheus reporter, for the scrape configs which discover the
+	// pods by annotations. The reporter port is declared as the `metrics` container port of
+	// the pods regardless. Default: false.
+	PrometheusAnnotations *bool `json:"prometheusAnnotations,omitempty"`
+
+	// _(Optional)_ Metrics reporters, which are translated into the `metrics.reporter.<name>.*`
+	// Flink properties. `flinkProperties` of the same reporter take precedence, e.g. to set
+	// options which are not typed here.
+	Reporters []MetricsReporter `json:"reporters,omitempty"`
+
+	// _(Optional)_ Kafka consumer group of the job whose lag is collected by the operator while
+	// the job runs, and served as the `flink_operator_kafka_consumer_lag` metric of the operator.
+	KafkaLag *KafkaLagSpec `json:"kafkaLag,omitempty"`
+
+	// _(Optional)_ Collect the state size, duration and alignment time of the latest completed
+	// checkpoint and savepoint of the running job, and serve them as the
+	// `flink_operator_checkpoint_*` metrics of the operator. Default: false.
+	CheckpointMetrics *bool `json:"checkpointMetrics,omitempty"`
+}
+
+// MetricsReporter defines a metrics reporter of the JobManager and TaskManagers. Exactly one
+// of prometheus, datadog, statsd and slf4j must be set. The JAR file of the reporter must be
+// in the plugins or lib directory of the image.
+type MetricsReporter struct {
+	// Name of the reporter in the Flink properties, `metrics.reporter.<name>.*`.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_-]+$`
+	Name string `json:"name"`
+
+	// _(Optional)_ Interval to report the metrics at, e.g. `60 SECONDS`, not for Prometheus
+	// reporters. Default: the Flink default, 10 seconds.
+	Interval *string `json:"interval,omitempty"`
+
+	// _(Optional)_ Prometheus reporter, whose metrics are scraped from each JobManager and
+	// TaskManager pod.
+	Prometheus *PrometheusReporter `json:"prometheus,omitempty"`
+
+	// _(Optional)_ Datadog HTTP reporter.
+	Datadog *DatadogReporter `json:"datadog,omitempty"`
+
+	// _(Optional)_ StatsD reporter.
+	StatsD *StatsDReporter `json:"statsd,omitempty"`
+
+	// _(Optional)_ SLF4J reporter, which writes the metrics to the logs.
+	Slf4j *Slf4jReporter `json:"slf4j,omitempty"`
+}
+
+// PrometheusReporter defines a Prometheus metrics reporter.
+type PrometheusReporter struct {
+	// _(Optional)_ Port to expose the metrics at. Default: 9249.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+}
+
+// DatadogReporter defines a Datadog HTTP metrics reporter.
+type DatadogReporter struct {
+	// Key of a Secret in the namespace of the cluster which holds the Datadog API key. It is
+	// passed to the JobManager and TaskManagers through an environment variable rather than
+	// the Flink ConfigMap, which requires Flink 1.11 or later.
+	APIKeySecret corev1.SecretKeySelector `json:"apiKeySecret"`
+
+	// _(Optional)_ Tags to add to all metrics, e.g. `env:prod`.
+	Tags []string `json:"tags,omitempty"`
+
+	// _(Optional)_ Datadog site to report to. Default: `US`.
+	// +kubebuilder:validation:Enum=US;EU
+	DataCenter *string `json:"dataCenter,omitempty"`
+}
+
+// StatsDReporter defines a StatsD metrics reporter.
+type StatsDReporter struct {
+	// Host of the StatsD server.
+	Host string `json:"host"`
+
+	// Port of the StatsD server.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+}
+
+// Slf4jReporter defines an SLF4J metrics reporter.
+type Slf4jReporter struct{}
+
+// KafkaLagSpec defines the Kafka consumer group whose lag is collected, the sum over its
+// partitions of the difference between the end offset and the committed offset.
+type KafkaLagSpec struct {
+	// Comma separated `host:port` addresses of the Kafka brokers.
+	BootstrapServers string `json:"bootstrapServers"`
+
+	// ID of the consumer group, e.g. `properties.group.id` of the Kafka sources.
+	Group string `json:"group"`
+
+	// _(Optional)_ Topics whose partitions are counted. Default: all the topics the group
+	// committed offsets for.
+	Topics []string `json:"topics,omitempty"`
+
+	// _(Optional)_ Connect to the brokers with TLS, verified with the system CAs or `ca.crt`
+	// of the Secret. Default: false.
+	TLS *bool `json:"tls,omitempty"`
+
+	// _(Optional)_ Secret in the namespace of the cluster with the `username` and `password`
+	// of the SASL authentication, and the optional `ca.crt` of the TLS connections.
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// _(Optional)_ The SASL mechanism of the authentication with the credentials of
+	// `secretRef`: `PLAIN`, `SCRAM-SHA-256` or `SCRAM-SHA-512`. Default: `PLAIN`.
+	// +kubebuilder:validation:Enum=PLAIN;SCRAM-SHA-256;SCRAM-SHA-512
+	SASLMechanism string `json:"saslMechanism,omitempty"`
 }
 
 // HadoopConfig defines configs for Hadoop.
@@ -773,6 +2288,67 @@ type HadoopConfig struct {
 	MountPath string `json:"mountPath,omitempty"`
 }
 
+// ExtraConfigMount defines a ConfigMap or Secret whose keys are projected into
+// the Flink conf directory. Exactly one of ConfigMap and Secret must be set.
+type ExtraConfigMount struct {
+	// _(Optional)_ ConfigMap to project. If items are unspecified, every key becomes a file.
+	ConfigMap *corev1.ConfigMapProjection `json:"configMap,omitempty"`
+
+	// _(Optional)_ Secret to project. If items are unspecified, every key becomes a file.
+	Secret *corev1.SecretProjection `json:"secret,omitempty"`
+}
+
+// ConfigOverrideSpec defines the ConfigMap of the Flink configuration which replaces the
+// generated one.
+type ConfigOverrideSpec struct {
+	// The name of the ConfigMap in the namespace of the cluster, whose keys are the files
+	// of the Flink conf directory, e.g. flink-conf.yaml and log4j-console.properties.
+	ConfigMapName string `json:"configMapName"`
+}
+
+// FlinkPropertiesSource defines a Secret or a secret of an external secret store whose
+// keys are Flink property names. Exactly one of SecretRef and External must be set.
+type FlinkPropertiesSource struct {
+	// _(Optional)_ Secret in the namespace of the cluster.
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// _(Optional)_ Secret of an external secret store, whose value is a JSON object.
+	External *ExternalSecretSource `json:"external,omitempty"`
+}
+
+// ExternalSecretProvider defines the external secret store of a secret.
+type ExternalSecretProvider string
+
+const (
+	ExternalSecretProviderVault             ExternalSecretProvider = "Vault"
+	ExternalSecretProviderAWSSecretsManager ExternalSecretProvider = "AWSSecretsManager"
+	ExternalSecretProviderGCPSecretManager  ExternalSecretProvider = "GCPSecretManager"
+)
+
+// ExternalSecretSource defines a secret of an external secret store, which the operator
+// gets with its own credentials. The store must be configured with the flags of the operator.
+type ExternalSecretSource struct {
+	// The secret store, one of `Vault`, `AWSSecretsManager` or `GCPSecretManager`.
+	// +kubebuilder:validation:Enum=Vault;AWSSecretsManager;GCPSecretManager
+	Provider ExternalSecretProvider `json:"provider"`
+
+	// The name of the secret: `<mount>/<path>` of a KV version 2 secret in Vault, the name
+	// or ARN of the secret in AWS Secrets Manager, `projects/<project>/secrets/<secret>`
+	// in GCP Secret Manager, optionally followed by `/versions/<version>`.
+	Name string `json:"name"`
+}
+
+// SecretInjection defines a Secret key substituted for a placeholder in the Flink properties.
+type SecretInjection struct {
+	// The name of the placeholder, referenced as `${PLACEHOLDER}` in the values of
+	// `flinkProperties`. Must be a valid environment variable name.
+	// +kubebuilder:validation:Pattern=^[A-Za-z_][A-Za-z0-9_]*$
+	Placeholder string `json:"placeholder"`
+
+	// The key of the Secret in the namespace of the cluster.
+	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
+}
+
 // GCPConfig defines configs for GCP.
 type GCPConfig struct {
 	// GCP service account.
@@ -792,6 +2368,46 @@ type GCPServiceAccount struct {
 	MountPath string `json:"mountPath,omitempty"`
 }
 
+// StateEncryptionSpec defines the encryption at rest of the checkpoints and savepoints.
+// Exactly one provider must be specified.
+type StateEncryptionSpec struct {
+	// _(Optional)_ Server-side encryption of the objects written to S3 with a key of AWS KMS,
+	// by the `s3-fs-hadoop` and `s3-fs-presto` plugins.
+	AWSKMS *AWSKMSEncryptionSpec `json:"awsKMS,omitempty"`
+
+	// _(Optional)_ Encryption of the objects written to Cloud Storage with a customer-supplied
+	// encryption key, by the `gs-fs-hadoop` plugin.
+	GCSCustomerKey *GCSCustomerKeySpec `json:"gcsCustomerKey,omitempty"`
+}
+
+// AWSKMSEncryptionSpec defines the SSE-KMS encryption of S3 objects.
+type AWSKMSEncryptionSpec struct {
+	// The ID, ARN or alias ARN of the KMS key.
+	KeyID string `json:"keyID"`
+
+	// _(Optional)_ The access key ID of the AWS credentials allowed to use the key, set as the
+	// `AWS_ACCESS_KEY_ID` environment variable of the Flink containers of the JobManager and
+	// TaskManagers, not of their init containers and sidecars. Must be set with
+	// `secretAccessKeySecretRef`. If unspecified, the default credentials of the pods are
+	// used, e.g. of IAM roles for service accounts.
+	AccessKeyIDSecretRef *corev1.SecretKeySelector `json:"accessKeyIDSecretRef,omitempty"`
+
+	// _(Optional)_ The secret access key of the AWS credentials, set as the
+	// `AWS_SECRET_ACCESS_KEY` environment variable. Must be set with `accessKeyIDSecretRef`.
+	SecretAccessKeySecretRef *corev1.SecretKeySelector `json:"secretAccessKeySecretRef,omitempty"`
+}
+
+// GCSCustomerKeySpec defines the encryption of Cloud Storage objects with a customer-supplied
+// encryption key, which is substituted into flink-conf.yaml like spec.secretsInjection.
+// [More info](https://cloud.google.com/storage/docs/encryption/customer-supplied-keys)
+type GCSCustomerKeySpec struct {
+	// The base64-encoded AES-256 key.
+	KeySecretRef corev1.SecretKeySelector `json:"keySecretRef"`
+
+	// The base64-encoded SHA256 hash of the key.
+	KeyHashSecretRef corev1.SecretKeySelector `json:"keyHashSecretRef"`
+}
+
 type ConfigMapStatus struct {
 	// The resource name of the component.
 	Name string `json:"name"`
@@ -814,6 +2430,19 @@ type JobManagerStatus struct {
 	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
 
 	Ready string `json:"ready"`
+
+	// (Optional) The reason why the component is not ready, e.g. ImagePullBackOff,
+	// Unschedulable or CrashLoopBackOff, extracted from the conditions and
+	// container statuses of its pods.
+	NotReadyReason string `json:"notReadyReason,omitempty"`
+
+	// (Optional) The number of restarts of the JobManager containers of the pods.
+	Restarts int32 `json:"restarts,omitempty"`
+
+	// (Optional) The last termination of a JobManager container which was restarted,
+	// e.g. because it was OOMKilled, so that crash loops are visible even while the
+	// pods are ready.
+	LastTermination *ContainerTerminationStatus `json:"lastTermination,omitempty"`
 }
 
 type TaskManagerStatus struct {
@@ -831,9 +2460,42 @@ type TaskManagerStatus struct {
 
 	Ready string `json:"ready"`
 
+	// (Optional) The reason why the component is not ready, e.g. ImagePullBackOff,
+	// Unschedulable or CrashLoopBackOff, extracted from the conditions and
+	// container statuses of its pods.
+	NotReadyReason string `json:"notReadyReason,omitempty"`
+
+	// (Optional) The number of restarts of the TaskManager containers of the pods.
+	Restarts int32 `json:"restarts,omitempty"`
+
+	// (Optional) The last termination of a TaskManager container which was restarted,
+	// e.g. because it was OOMKilled, so that crash loops are visible even while the
+	// pods are ready.
+	LastTermination *ContainerTerminationStatus `json:"lastTermination,omitempty"`
+
 	Selector string `json:"selector"`
 }
 
+// ContainerTerminationStatus is the last termination of the main container of a pod of the
+// JobManager or TaskManager, from its last termination state.
+type ContainerTerminationStatus struct {
+	// The name of the pod.
+	Pod string `json:"pod"`
+
+	// The reason of the termination, e.g. OOMKilled or Error.
+	Reason string `json:"reason"`
+
+	// The exit code of the container.
+	ExitCode int32 `json:"exitCode"`
+
+	// The time the container terminated.
+	FinishedAt string `json:"finishedAt,omitempty"`
+
+	// (Optional) The memory limit to try if the container was OOMKilled, 1.5 times the
+	// limit of the container rounded up to a MiB. The spec is not changed by the operator.
+	SuggestedMemory *resource.Quantity `json:"suggestedMemory,omitempty"`
+}
+
 // FlinkClusterComponentsStatus defines the observed status of the
 // components of a FlinkCluster.
 type FlinkClusterComponentsStatus struct {
@@ -882,6 +2544,10 @@ type JobStatus struct {
 	// The Name of the Flink job.
 	Name string `json:"name,omitempty"`
 
+	// The entry class of the job, `spec.job.className` or the class resolved by the job
+	// submitter from the manifest of the JAR file when it is not specified.
+	EntryClass string `json:"entryClass,omitempty"`
+
 	// The name of the Kubernetes job resource.
 	SubmitterName string `json:"submitterName,omitempty"`
 
@@ -896,6 +2562,11 @@ type JobStatus struct {
 	// spec.
 	FromSavepoint string `json:"fromSavepoint,omitempty"`
 
+	// (Optional) The savepoints dir selected for the region and zone of the JobManager node
+	// by the savepoint storage config of the operator, which the savepoints are written to
+	// instead of `spec.job.savepointsDir`.
+	SavepointsDir string `json:"savepointsDir,omitempty"`
+
 	// The generation of the savepoint in `savepointsDir` taken by the operator.
 	// The value starts from 0 when there is no savepoint and increases by 1 for
 	// each successful savepoint.
@@ -907,6 +2578,10 @@ type JobStatus struct {
 	// Last successful savepoint completed timestamp.
 	SavepointTime string `json:"savepointTime,omitempty"`
 
+	// The Flink version of the JobManager which took the savepoint in savepointLocation, empty
+	// if it is not known.
+	SavepointFlinkVersion string `json:"savepointFlinkVersion,omitempty"`
+
 	// The savepoint recorded in savepointLocation is the final state of the job.
 	FinalSavepoint bool `json:"finalSavepoint,omitempty"`
 
@@ -919,11 +2594,253 @@ type JobStatus struct {
 	// The number of restarts.
 	RestartCount int32 `json:"restartCount,omitempty"`
 
+	// (Optional) The latest runs of the job, oldest first, up to `spec.job.runHistoryLimit`.
+	// A run starts whenever the job is deployed, e.g. on restarts and updates.
+	Runs []JobRunStatus `json:"runs,omitempty"`
+
+	// (Optional) How the job was stopped for the last update, see `spec.job.updateStopMode`.
+	UpdateStopMode JobUpdateStopMode `json:"updateStopMode,omitempty"`
+
 	// Job completion time. Present when job is terminated regardless of its state.
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 
 	// Reasons for the job failure. Present if job state is Failure
 	FailureReasons []string `json:"failureReasons,omitempty"`
+
+	// (Optional) The verification of the job started from a savepoint,
+	// present if `restoreVerification` is specified.
+	RestoreVerification *RestoreVerificationStatus `json:"restoreVerification,omitempty"`
+
+	// (Optional) The evaluation of the service level objectives of the running job,
+	// present while the job is running if `slo` is specified.
+	SLO *JobSLOStatus `json:"slo,omitempty"`
+
+	// (Optional) The state changes and the node drains observed for `adaptiveSavepoint`,
+	// present if it is specified.
+	AdaptiveSavepoint *AdaptiveSavepointStatus `json:"adaptiveSavepoint,omitempty"`
+
+	// (Optional) Where the plan of the job is stored, present once it is stored if
+	// `planArchive` is specified.
+	Plan *JobPlanStatus `json:"plan,omitempty"`
+
+	// (Optional) The ownership metadata of the savepoints of the job and its verification,
+	// present if `savepointOwnership` is specified.
+	SavepointOwnership *SavepointOwnershipStatus `json:"savepointOwnership,omitempty"`
+
+	// (Optional) The snapshot of the back pressure and the busyness of the vertices of the
+	// running job, present if the operator is started with `--job-vertex-status-interval`.
+	Vertices []JobVertexStatus `json:"vertices,omitempty"`
+
+	// (Optional) The time of the snapshot of the vertices.
+	VerticesTime string `json:"verticesTime,omitempty"`
+
+	// (Optional) The reason why the job submitter pod, or the JobManager pod in
+	// application mode, is not running yet, e.g. ImagePullBackOff or Unschedulable.
+	NotReadyReason string `json:"notReadyReason,omitempty"`
+
+	// (Optional) The gate of `readinessGates` blocking the submission of the job,
+	// present while the job is pending.
+	BlockingReadinessGate *JobReadinessGateStatus `json:"blockingReadinessGate,omitempty"`
+}
+
+// JobVertexStatus is the snapshot of the back pressure and the busyness of a job vertex.
+type JobVertexStatus struct {
+	// The name of the vertex, truncated to 128 characters.
+	Name string `json:"name"`
+
+	// The parallelism of the vertex.
+	Parallelism int32 `json:"parallelism,omitempty"`
+
+	// The back pressure level of the vertex sampled by the JobManager: `ok`, `low` or `high`,
+	// absent if not sampled yet.
+	BackPressureLevel string `json:"backPressureLevel,omitempty"`
+
+	// The busy time of the busiest subtask of the vertex in percent, absent if not measured.
+	MaxBusyPercent *int32 `json:"maxBusyPercent,omitempty"`
+}
+
+// JobReadinessGateStatus is the status of the readiness gate blocking the submission of a job.
+type JobReadinessGateStatus struct {
+	// The name of the gate.
+	Name string `json:"name"`
+
+	// The reason why the gate does not pass.
+	Message string `json:"message"`
+}
+
+// JobRunStatus is the record of a run of a job.
+type JobRunStatus struct {
+	// The ID of the Flink job of the run, present once it is submitted.
+	ID string `json:"id,omitempty"`
+
+	// The name of the job submitter pod of the run.
+	SubmitterPod string `json:"submitterPod,omitempty"`
+
+	// The savepoint the run was restored from, empty if it started without state.
+	FromSavepoint string `json:"fromSavepoint,omitempty"`
+
+	// The time the job of the run started running.
+	StartTime string `json:"startTime,omitempty"`
+
+	// The time the run ended.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The terminal state of the run, empty while it is not over. `Updating` if the run was
+	// replaced by an update before it stopped.
+	State JobState `json:"state,omitempty"`
+}
+
+// RestoreVerificationStatus is the status of the verification of a job started from a savepoint.
+type RestoreVerificationStatus struct {
+	// The savepoint the job was started from.
+	Savepoint string `json:"savepoint"`
+
+	// The state of the verification.
+	State RestoreVerificationState `json:"state"`
+
+	// The reason why the verification failed.
+	Message string `json:"message,omitempty"`
+}
+
+// CanaryUpdateStatus is the status of the canary update of the TaskManagers.
+type CanaryUpdateStatus struct {
+	// The revision of the cluster the canary TaskManagers are updated to.
+	Revision string `json:"revision"`
+
+	// The state of the canary update.
+	State CanaryUpdateState `json:"state"`
+
+	// The number of canary TaskManagers.
+	Replicas int32 `json:"replicas"`
+
+	// The number of canary TaskManagers which are ready and registered to the JobManager.
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// The time when the canary update started.
+	StartTime string `json:"startTime,omitempty"`
+
+	// The time when all the canary TaskManagers became ready and registered, the soak period starts then.
+	SoakStartTime string `json:"soakStartTime,omitempty"`
+
+	// The time when the canary update was promoted, aborted or failed.
+	FinishTime string `json:"finishTime,omitempty"`
+
+	// The reason why the canary update was promoted early, aborted or failed.
+	Message string `json:"message,omitempty"`
+}
+
+// UpdateProgressStatus is the coarse progress of the update of a cluster to its next revision.
+type UpdateProgressStatus struct {
+	// The revision of the cluster being updated to.
+	Revision string `json:"revision"`
+
+	// The current step of the update, one of `Savepoint`, `Teardown`, `Recreate` or `Update`.
+	Step UpdateStep `json:"step"`
+
+	// The 1-based number of the current step.
+	Current int32 `json:"current"`
+
+	// The number of steps of the update, which depends on whether the job is restarted and
+	// the components are recreated.
+	Total int32 `json:"total"`
+
+	// The percentage of the steps completed.
+	Percentage int32 `json:"percentage"`
+}
+
+// IdleStatus is the status of the idle policy of a session cluster.
+type IdleStatus struct {
+	// The time since the cluster runs no jobs, or since it was woken up. Unset while the
+	// cluster runs jobs.
+	IdleSince string `json:"idleSince,omitempty"`
+
+	// Whether the cluster is scaled to zero.
+	ScaledToZero bool `json:"scaledToZero,omitempty"`
+}
+
+// TaskManagerDecommissionStatus is the status of the decommission of the TaskManagers removed
+// by a scale-down, see `spec.taskManager.decommissionTimeoutSeconds`.
+type TaskManagerDecommissionStatus struct {
+	// The number of replicas the TaskManagers are scaled down to.
+	Replicas int32 `json:"replicas"`
+
+	// The pods of the decommissioned TaskManagers.
+	Pods []string `json:"pods,omitempty"`
+
+	// The time the decommission started.
+	StartTime string `json:"startTime"`
+
+	// The ID of the trigger of the checkpoint of the job triggered when the decommission
+	// started, the decommission finishes once this checkpoint completed successfully. Empty if
+	// the checkpoint could not be triggered, e.g. before Flink 1.17.
+	CheckpointTriggerID string `json:"checkpointTriggerID,omitempty"`
+}
+
+// JobPlanStatus is the status of the stored plan of a job.
+type JobPlanStatus struct {
+	// The ID of the job whose plan is stored.
+	JobID string `json:"jobID"`
+
+	// The revision of the job.
+	Revision string `json:"revision"`
+
+	// The ConfigMap the plan is stored in, with the key `<revision>.json`, empty if the
+	// plan is uploaded.
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// The URL the plan is uploaded to, empty if the plan is stored in the ConfigMap.
+	URL string `json:"url,omitempty"`
+
+	// The time the plan was stored.
+	StoreTime string `json:"storeTime"`
+}
+
+// SavepointOwnershipStatus is the status of the ownership metadata of the savepoints of a
+// job.
+type SavepointOwnershipStatus struct {
+	// The last savepoint whose ownership metadata was written.
+	RecordedSavepoint string `json:"recordedSavepoint,omitempty"`
+
+	// (Optional) Why the job is not restored from the savepoint, present while the
+	// submission of the job is blocked by the verification of the savepoint.
+	RestoreBlockedReason string `json:"restoreBlockedReason,omitempty"`
+}
+
+// AdaptiveSavepointStatus is the status of the adaptive savepoint policy of a job.
+type AdaptiveSavepointStatus struct {
+	// The checkpointed size of the checkpoints completed since the last savepoint.
+	StateDeltaBytes int64 `json:"stateDeltaBytes,omitempty"`
+
+	// The ID of the last checkpoint counted in `stateDeltaBytes`.
+	LastCheckpointID int64 `json:"lastCheckpointID,omitempty"`
+
+	// The nodes running pods of the cluster which are being drained.
+	DrainingNodes []string `json:"drainingNodes,omitempty"`
+
+	// The time the last of `drainingNodes` was observed being drained. A savepoint is taken
+	// unless one completed since then.
+	DrainStartTime string `json:"drainStartTime,omitempty"`
+}
+
+// JobSLOStatus is the status of the service level objectives of a running job.
+type JobSLOStatus struct {
+	// The objectives violated by the job.
+	Violations []JobSLOViolation `json:"violations,omitempty"`
+
+	// The times of the restarts of the job within the last hour, counted for `maxRestartRatePerHour`.
+	RestartTimes []metav1.Time `json:"restartTimes,omitempty"`
+
+	// The `numRestarts` metric of the Flink job when it was last observed.
+	ObservedNumRestarts *int64 `json:"observedNumRestarts,omitempty"`
+}
+
+// JobSLOViolation defines a service level objective violated by a job.
+type JobSLOViolation struct {
+	// The violated objective, e.g. `maxCheckpointAgeSeconds`.
+	Objective string `json:"objective"`
+
+	// Human readable description of the violation.
+	Message string `json:"message"`
 }
 
 // SavepointStatus is the status of savepoint progress.
@@ -934,6 +2851,10 @@ type SavepointStatus struct {
 	// Savepoint trigger ID.
 	TriggerID string `json:"triggerID,omitempty"`
 
+	// The name of the Kubernetes Job which triggered the savepoint, when it is not triggered
+	// through the Flink REST API.
+	TriggerJob string `json:"triggerJob,omitempty"`
+
 	// Savepoint triggered time.
 	TriggerTime string `json:"triggerTime,omitempty"`
 
@@ -948,6 +2869,10 @@ type SavepointStatus struct {
 
 	// Savepoint message.
 	Message string `json:"message,omitempty"`
+
+	// The Flink version reported by the JobManager when the savepoint was triggered, empty if
+	// it could not be retrieved.
+	FlinkVersion string `json:"flinkVersion,omitempty"`
 }
 
 type RevisionStatus struct {
@@ -969,6 +2894,42 @@ type RevisionStatus struct {
 	// uses this field as a collision avoidance mechanism when it needs to create the name for the
 	// newest ControllerRevision.
 	CollisionCount *int32 `json:"collisionCount,omitempty"`
+
+	// The time when the update to nextRevision started in the window of
+	// `spec.updatePolicy.window`, present until the update finishes.
+	UpdateStartTime string `json:"updateStartTime,omitempty"`
+
+	// The time when nextRevision last changed, present while `spec.updatePolicy.debounceSeconds`
+	// is set and the update is triggered.
+	NextRevisionTime string `json:"nextRevisionTime,omitempty"`
+
+	// The last phase the update to nextRevision reached, present until the update finishes.
+	// The update resumes from this phase after the operator restarts.
+	UpdatePhase UpdatePhase `json:"updatePhase,omitempty"`
+
+	// The last failed update, e.g. whose job failed the restore verification, kept until
+	// another update fails.
+	UpdateFailure *UpdateFailureStatus `json:"updateFailure,omitempty"`
+}
+
+// UpdateFailureStatus is the status of an update of the cluster which failed.
+type UpdateFailureStatus struct {
+	// The revision of the cluster whose update failed.
+	Revision string `json:"revision"`
+
+	// The reason why the update failed.
+	Reason string `json:"reason"`
+
+	// The time when the update failed.
+	Time string `json:"time"`
+
+	// The revision the spec was rolled back to, present once the rollback of
+	// `restoreVerification.onFailure` is triggered.
+	RollbackRevision string `json:"rollbackRevision,omitempty"`
+
+	// Whether the failed update was the rollback of a previous failed update, which is not
+	// rolled back again.
+	Rollback bool `json:"rollback,omitempty"`
 }
 
 // JobManagerIngressStatus defines the status of a JobManager ingress.
@@ -981,6 +2942,9 @@ type JobManagerIngressStatus struct {
 
 	// The URLs of ingress.
 	URLs []string `json:"urls,omitempty"`
+
+	// (Optional) The reason why the ingress is not ready, e.g. LoadBalancerPending.
+	NotReadyReason string `json:"notReadyReason,omitempty"`
 }
 
 // JobManagerServiceStatus defines the observed state of FlinkCluster
@@ -994,8 +2958,11 @@ type JobManagerServiceStatus struct {
 	// (Optional) The node port, present when `accessScope` is `NodePort`.
 	NodePort int32 `json:"nodePort,omitempty"`
 
-	// (Optional) The load balancer ingress, present when `accessScope` is `VPC` or `External`
+	// (Optional) The load balancer ingress, present when `accessScope` is `VPC`, `External` or `InternalLB`
 	LoadBalancerIngress []corev1.LoadBalancerIngress `json:"loadBalancerIngress,omitempty"`
+
+	// (Optional) The reason why the service is not ready, e.g. LoadBalancerPending.
+	NotReadyReason string `json:"notReadyReason,omitempty"`
 }
 
 // FlinkClusterStatus defines the observed state of FlinkCluster
@@ -1004,6 +2971,7 @@ type FlinkClusterStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// The overall state of the Flink cluster.
+	// +kubebuilder:validation:Enum=Queued;Creating;Running;Degraded;Reconciling;Updating;Stopping;PartiallyStopped;Stopped;Idle;Deleting;Suspending;Suspended;RollingBack
 	State ClusterState `json:"state"`
 
 	// The status of the components.
@@ -1018,11 +2986,192 @@ type FlinkClusterStatus struct {
 	// The status of revision.
 	Revision RevisionStatus `json:"revision,omitempty"`
 
+	// The generation of the spec which the cluster is updated to. It equals
+	// `metadata.generation` once the update to the latest spec finished, including the spec
+	// changes which do not need an update. It lags behind while the update is in progress
+	// or deferred by `spec.updatePolicy`.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The progress of the update to the next revision, present while the update is in progress.
+	UpdateProgress *UpdateProgressStatus `json:"updateProgress,omitempty"`
+
+	// The status of the canary update of the TaskManagers, present once an update with
+	// `canaryUpdate` started. It is kept after the update finished.
+	CanaryUpdate *CanaryUpdateStatus `json:"canaryUpdate,omitempty"`
+
+	// The status of `spec.idlePolicy`, present while it is set.
+	Idle *IdleStatus `json:"idle,omitempty"`
+
+	// The decommission of the TaskManagers removed by a scale-down, present while it is in
+	// progress.
+	TaskManagerDecommission *TaskManagerDecommissionStatus `json:"taskManagerDecommission,omitempty"`
+
+	// Position of the cluster in the job cluster queue, set only while the cluster is Queued.
+	// 1 means the cluster starts next when a running job cluster frees its slot.
+	QueuePosition int32 `json:"queuePosition,omitempty"`
+
+	// The FlinkClusters of `spec.dependsOn` whose job was seen to succeed while the cluster
+	// waited for its dependencies. They stay ready when they are deleted afterwards, e.g.
+	// by their `cleanupPolicy.ttlSecondsAfterFinished`.
+	SucceededDependencies []string `json:"succeededDependencies,omitempty"`
+
+	// The error which stopped the reconciliation of the current generation of the cluster,
+	// e.g. a resource kind which is not served by the API server. The cluster is
+	// reconciled again when its spec is updated.
+	ReconcileError *ReconcileErrorStatus `json:"reconcileError,omitempty"`
+
+	// The JAR files of `spec.jars` uploaded to the JobManager of the session cluster.
+	Jars []SessionJarStatus `json:"jars,omitempty"`
+
+	// The last export of the state of the cluster by `spec.stateExport`.
+	StateExport *StateExportStatus `json:"stateExport,omitempty"`
+
+	// The endpoints to access the web UI, the REST API and the metrics of the cluster,
+	// present while the JobManager service exists.
+	Endpoints *FlinkClusterEndpoints `json:"endpoints,omitempty"`
+
+	// The CPU and memory of the pods of the components of the cluster as they are scaled,
+	// so that the footprint of the clusters can be summed up without listing their pods.
+	Resources *ClusterResourcesStatus `json:"resources,omitempty"`
+
+	// The conditions of the cluster, e.g. `SLOViolated` while the running job violates `spec.job.slo`.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// The version of the operator which last reconciled the cluster.
+	OperatorVersion string `json:"operatorVersion,omitempty"`
+
+	// The version of the layout of the status. The operator migrates the status written by
+	// older operators to its version, and does not reconcile the clusters whose status was
+	// migrated by a newer operator.
+	SchemaVersion int32 `json:"schemaVersion,omitempty"`
+
 	// Last update timestamp for this status.
 	LastUpdateTime string `json:"lastUpdateTime,omitempty"`
 }
 
+// ClusterResourcesStatus is the sum of the effective requests and limits of the pods of the
+// JobManager, TaskManager and job submitter, by the replicas of their StatefulSets,
+// Deployment and active Job pods.
+type ClusterResourcesStatus struct {
+	// The CPU and memory requested by the pods.
+	Requests corev1.ResourceList `json:"requests,omitempty"`
+
+	// The CPU and memory limits of the pods. The containers without a limit of a resource do
+	// not count towards it.
+	Limits corev1.ResourceList `json:"limits,omitempty"`
+
+	// The number of pods.
+	Pods int32 `json:"pods"`
+}
+
+// SessionJarStatus is the status of a JAR file uploaded to the JobManager of a session cluster.
+type SessionJarStatus struct {
+	// The name of the JAR file in `spec.jars`.
+	Name string `json:"name"`
+
+	// The URI the JAR file was downloaded from.
+	URI string `json:"uri"`
+
+	// SHA-256 checksum of the JAR file in hex.
+	SHA256 string `json:"sha256"`
+
+	// ID of the JAR file in the JobManager, e.g. to run it with `POST /jars/<id>/run`.
+	// JAR files with the same checksum share the ID.
+	ID string `json:"id"`
+}
+
+// StateExportStatus is the status of the export of the state of a revision.
+type StateExportStatus struct {
+	// The revision whose state is exported, empty until a state is exported.
+	Revision string `json:"revision,omitempty"`
+
+	// The URL the state is uploaded to.
+	URL string `json:"url,omitempty"`
+
+	// Whether the exported file is signed.
+	Signed bool `json:"signed,omitempty"`
+
+	// The time the state was exported.
+	ExportTime string `json:"exportTime,omitempty"`
+
+	// The failure of the export of the running revision, cleared once it is exported.
+	LastFailure *StateExportFailure `json:"lastFailure,omitempty"`
+}
+
+// StateExportFailure is the failure of the exports of the state of a revision, which are
+// retried with an exponential backoff.
+type StateExportFailure struct {
+	// The revision whose state failed to be exported.
+	Revision string `json:"revision"`
+
+	// The number of consecutive failures of the exports of the revision.
+	Attempts int32 `json:"attempts"`
+
+	// The time of the last failure.
+	FailureTime string `json:"failureTime"`
+
+	// The error of the last failure.
+	Message string `json:"message,omitempty"`
+}
+
+// FlinkClusterEndpoints is the endpoints to access the cluster.
+type FlinkClusterEndpoints struct {
+	// URL of the web UI through the JobManager service inside the Kubernetes cluster, e.g.
+	// `http://mycluster-jobmanager.default.svc.cluster.local:8081`. It is the URL of the
+	// oauth2-proxy or the read-only UI proxy when they are enabled.
+	UI string `json:"ui"`
+
+	// (Optional) URLs of the web UI through the JobManager ingress, present when the ingress is ready.
+	IngressUI []string `json:"ingressUI,omitempty"`
+
+	// (Optional) URLs of the web UI through the load balancer of the JobManager service,
+	// present when `accessScope` is `VPC`, `External` or `InternalLB` and the load balancer is assigned.
+	LoadBalancerUI []string `json:"loadBalancerUI,omitempty"`
+
+	// (Optional) URL of the web UI at the hostname of `spec.jobManager.externalDNS`, present
+	// once the hostname resolves.
+	ExternalDNSUI string `json:"externalDNSUI,omitempty"`
+
+	// URL of the REST API through the JobManager service inside the Kubernetes cluster.
+	REST string `json:"rest"`
+
+	// (Optional) The endpoints of the metrics of the JobManager and TaskManagers.
+	Metrics []MetricsEndpoint `json:"metrics,omitempty"`
+}
+
+// MetricsEndpoint is an endpoint of the metrics of the cluster.
+type MetricsEndpoint struct {
+	// `rest` for the metrics of the REST API, otherwise the name of a Prometheus reporter in
+	// `flinkProperties`, e.g. `prom` of `metrics.reporter.prom.factory.class`.
+	Name string `json:"name"`
+
+	// (Optional) URLs of the metrics, present for the REST API.
+	URLs []string `json:"urls,omitempty"`
+
+	// (Optional) The port the reporter exposes the metrics at in each JobManager and TaskManager
+	// pod, e.g. for the endpoint of a PodMonitor.
+	Port int32 `json:"port,omitempty"`
+
+	// (Optional) The label selector of the pods which expose the port.
+	Selector string `json:"selector,omitempty"`
+}
+
+// ReconcileErrorStatus is the status of an error which retries cannot fix.
+type ReconcileErrorStatus struct {
+	// The error message.
+	Message string `json:"message"`
+
+	// The generation of the cluster whose reconciliation failed.
+	ObservedGeneration int64 `json:"observedGeneration"`
+
+	// The time when the error occurred.
+	Time string `json:"time"`
+}
+
 // FlinkCluster is the Schema for the flinkclusters API
+// +genclient
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:shortName={fc,fcs}
 // +kubebuilder:subresource:status
@@ -2,6 +2,10 @@ package v1beta1
 
 import (
 	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,8 +17,32 @@ const (
 	haConfigType       = "high-availability"
 	haConfigStorageDir = "high-availability.storageDir"
 	haConfigClusterId  = "kubernetes.cluster-id"
+
+	metricsReporterPrefix         = "metrics.reporter."
+	defaultPrometheusReporterPort = 9249
+
+	// Name of the container port of the Prometheus reporter, and of the TaskManager service port
+	// of spec.monitoring.exposeTaskManagerMetrics.
+	MetricsPortName = "metrics"
+	// Deprecated: Use MetricsPortName.
+	TaskManagerMetricsPortName = MetricsPortName
+
+	// Prefix of the Maven coordinates of spec.job.jarFile.
+	MavenArtifactPrefix       = "mvn:"
+	defaultMavenRepositoryURL = "https://repo1.maven.org/maven2"
+
+	// Key of the certificates of spec.networking.caBundle in the ConfigMap by default.
+	DefaultCABundleKey = "ca.crt"
+
+	// Placeholders of the customer-supplied key of spec.stateEncryption.gcsCustomerKey in
+	// flink-conf.yaml, which spec.secretsInjection cannot use.
+	GCSEncryptionKeyPlaceholder     = "FLINK_OPERATOR_GCS_ENCRYPTION_KEY"
+	GCSEncryptionKeyHashPlaceholder = "FLINK_OPERATOR_GCS_ENCRYPTION_KEY_HASH"
 )
 
+// The pull reporter of flink-metrics-prometheus and its factory, not the PushGateway reporter.
+var prometheusReporterClassRegexp = regexp.MustCompile(`^org\.apache\.flink\.metrics\.prometheus\.PrometheusReporter(Factory)?$`)
+
 func (j *JobStatus) IsActive() bool {
 	return j != nil &&
 		(j.State == JobStateRunning || j.State == JobStateDeploying)
@@ -69,6 +97,10 @@ func (j *JobStatus) ShouldRestart(spec *JobSpec) bool {
 	if j == nil || !j.IsFailed() || spec == nil {
 		return false
 	}
+	// Restarting from the savepoint whose restore failed the verification would fail again.
+	if j.RestoreVerification != nil && j.RestoreVerification.State == RestoreVerificationStateFailed {
+		return false
+	}
 
 	restartEnabled := spec.RestartPolicy != nil && *spec.RestartPolicy == JobRestartPolicyFromSavepointOnFailure
 
@@ -117,10 +149,40 @@ func (s *SavepointStatus) IsFailed() bool {
 	return s != nil && (s.State == SavepointStateTriggerFailed || s.State == SavepointStateFailed)
 }
 
+func (s *CanaryUpdateStatus) IsActive() bool {
+	return s != nil &&
+		(s.State == CanaryUpdateStateProgressing || s.State == CanaryUpdateStateSoaking)
+}
+
 func (r *RevisionStatus) IsUpdateTriggered() bool {
 	return r.CurrentRevision != r.NextRevision
 }
 
+func (c *ComponentsSpec) ShouldCreateJmService() bool {
+	return c == nil || isEnabled(c.CreateJmService)
+}
+
+func (c *ComponentsSpec) ShouldCreateTmService() bool {
+	return c == nil || isEnabled(c.CreateTmService)
+}
+
+func (c *ComponentsSpec) ShouldCreateIngress() bool {
+	return c == nil || isEnabled(c.CreateIngress)
+}
+
+func (c *ComponentsSpec) ShouldCreatePDB() bool {
+	return c == nil || isEnabled(c.CreatePDB)
+}
+
+func (c *ComponentsSpec) ShouldCreateConfigMap() bool {
+	return c == nil || isEnabled(c.CreateConfigMap)
+}
+
+// The components are created unless disabled in spec.components.
+func isEnabled(toggle *bool) bool {
+	return toggle == nil || *toggle
+}
+
 func isBlank(s *string) bool {
 	return s == nil || strings.TrimSpace(*s) == ""
 }
@@ -129,10 +191,81 @@ func (jm *JobManagerSpec) GetResources() *corev1.ResourceList {
 	return util.UpperBoundedResourceList(jm.Resources)
 }
 
+// Checks whether the Flink web UI is read-only, which is the default when it is exposed to the internet.
+func (jm *JobManagerSpec) IsReadOnlyUI() bool {
+	if jm.ReadOnlyUI != nil {
+		return *jm.ReadOnlyUI
+	}
+	return jm.AccessScope == AccessScopeExternal
+}
+
+// GetRetentionPolicy returns the retention policy of the claim, `Delete` by default.
+func (s *JobManagerStorageSpec) GetRetentionPolicy() StorageRetentionPolicy {
+	if s.RetentionPolicy == nil {
+		return StorageRetentionPolicyDelete
+	}
+	return *s.RetentionPolicy
+}
+
 func (tm *TaskManagerSpec) GetResources() *corev1.ResourceList {
 	return util.UpperBoundedResourceList(tm.Resources)
 }
 
+// GetTaskSlots returns the number of task slots of a TaskManager, taskSlots, slotsPerCPU times
+// the TaskManager cpu, taskmanager.numberOfTaskSlots of the Flink properties or else half of
+// the TaskManager cpu, at least 1.
+func (fc *FlinkCluster) GetTaskSlots() (int32, error) {
+	var tm = fc.Spec.TaskManager
+	if tm.TaskSlots != nil {
+		return *tm.TaskSlots, nil
+	}
+	if tm.SlotsPerCPU != nil {
+		var cpu = tm.GetResources().Cpu()
+		var slots = int32(cpu.MilliValue() * tm.SlotsPerCPU.MilliValue() / 1000000)
+		if slots < 1 {
+			return 1, nil
+		}
+		return slots, nil
+	}
+	if ts, ok := fc.Spec.FlinkProperties["taskmanager.numberOfTaskSlots"]; ok {
+		parsed, err := strconv.ParseInt(ts, 10, 32)
+		if err != nil {
+			return 0, err
+		}
+		return int32(parsed), nil
+	}
+
+	resources := fc.Spec.TaskManager.GetResources()
+	slots := int32(resources.Cpu().Value()) / 2
+	if slots == 0 {
+		return 1, nil
+	}
+	return slots, nil
+}
+
+// GetFlinkProperties returns the properties of the file system plugins which encrypt the
+// checkpoints and savepoints, the ones of both S3 plugins for AWS KMS. The customer-supplied
+// key of Cloud Storage is referenced by its placeholders.
+func (s *StateEncryptionSpec) GetFlinkProperties() map[string]string {
+	switch {
+	case s.AWSKMS != nil:
+		return map[string]string{
+			"fs.s3a.server-side-encryption-algorithm": "SSE-KMS",
+			"fs.s3a.server-side-encryption.key":       s.AWSKMS.KeyID,
+			"s3.sse.enabled":                          "true",
+			"s3.sse.type":                             "KMS",
+			"s3.sse.kms-key-id":                       s.AWSKMS.KeyID,
+		}
+	case s.GCSCustomerKey != nil:
+		return map[string]string{
+			"fs.gs.encryption.algorithm": "AES256",
+			"fs.gs.encryption.key":       "${" + GCSEncryptionKeyPlaceholder + "}",
+			"fs.gs.encryption.key.hash":  "${" + GCSEncryptionKeyHashPlaceholder + "}",
+		}
+	}
+	return nil
+}
+
 func (fc *FlinkCluster) IsHighAvailabilityEnabled() bool {
 	if fc.Spec.FlinkProperties == nil {
 		return false
@@ -152,9 +285,179 @@ func (fc *FlinkCluster) IsHighAvailabilityEnabled() bool {
 	return true
 }
 
+// IsKubernetesHighAvailabilityEnabled returns true if HA is enabled with Kubernetes HA services,
+// which store the HA metadata in ConfigMaps.
+func (fc *FlinkCluster) IsKubernetesHighAvailabilityEnabled() bool {
+	if !fc.IsHighAvailabilityEnabled() {
+		return false
+	}
+	switch strings.ToLower(fc.Spec.FlinkProperties[haConfigType]) {
+	case "kubernetes", "org.apache.flink.kubernetes.highavailability.kuberneteshaservicesfactory":
+		return true
+	}
+	return false
+}
+
 func (fc *FlinkCluster) GetHAConfigMapName() string {
 	if !fc.IsHighAvailabilityEnabled() {
 		return ""
 	}
 	return fmt.Sprintf("%s-cluster-config-map", fc.Spec.FlinkProperties[haConfigClusterId])
 }
+
+// GetPrometheusReporterPorts returns the ports of the Prometheus reporters of
+// spec.monitoring.reporters or configured with `metrics.reporter.<name>.factory.class` or
+// `metrics.reporter.<name>.class` by their names. The reporters listen on the first port of
+// `metrics.reporter.<name>.port`, 9249 by default.
+func (fc *FlinkCluster) GetPrometheusReporterPorts() map[string]int32 {
+	var ports map[string]int32
+	if fc.Spec.Monitoring != nil {
+		for _, reporter := range fc.Spec.Monitoring.Reporters {
+			if reporter.Prometheus == nil {
+				continue
+			}
+			if ports == nil {
+				ports = map[string]int32{}
+			}
+			ports[reporter.Name] = defaultPrometheusReporterPort
+			if reporter.Prometheus.Port != nil {
+				ports[reporter.Name] = *reporter.Prometheus.Port
+			}
+		}
+	}
+	for key, value := range fc.Spec.FlinkProperties {
+		var name = strings.TrimPrefix(key, metricsReporterPrefix)
+		if name == key || !prometheusReporterClassRegexp.MatchString(value) {
+			continue
+		}
+		if strings.HasSuffix(name, ".factory.class") {
+			name = strings.TrimSuffix(name, ".factory.class")
+		} else {
+			name = strings.TrimSuffix(name, ".class")
+		}
+		if name == "" || strings.Contains(name, ".") {
+			continue
+		}
+		if ports == nil {
+			ports = map[string]int32{}
+		}
+		if _, ok := ports[name]; !ok {
+			ports[name] = defaultPrometheusReporterPort
+		}
+	}
+	// The port of the Flink properties takes precedence over the one of the typed reporter.
+	for name := range ports {
+		var portRange = strings.SplitN(fc.Spec.FlinkProperties[metricsReporterPrefix+name+".port"], "-", 2)
+		if p, err := strconv.ParseInt(strings.TrimSpace(portRange[0]), 10, 32); err == nil {
+			ports[name] = int32(p)
+		}
+	}
+	return ports
+}
+
+// IsTaskManagerMetricsExposed returns true if spec.monitoring.exposeTaskManagerMetrics is enabled.
+func (fc *FlinkCluster) IsTaskManagerMetricsExposed() bool {
+	var monitoring = fc.Spec.Monitoring
+	return monitoring != nil && monitoring.ExposeTaskManagerMetrics != nil && *monitoring.ExposeTaskManagerMetrics
+}
+
+// HasPrometheusAnnotations returns true if spec.monitoring.prometheusAnnotations is enabled.
+func (fc *FlinkCluster) HasPrometheusAnnotations() bool {
+	var monitoring = fc.Spec.Monitoring
+	return monitoring != nil && monitoring.PrometheusAnnotations != nil && *monitoring.PrometheusAnnotations
+}
+
+// HasCheckpointMetrics returns true if spec.monitoring.checkpointMetrics is enabled.
+func (fc *FlinkCluster) HasCheckpointMetrics() bool {
+	var monitoring = fc.Spec.Monitoring
+	return monitoring != nil && monitoring.CheckpointMetrics != nil && *monitoring.CheckpointMetrics
+}
+
+// IsMavenArtifact returns true if the JAR file is given by its Maven coordinates.
+func IsMavenArtifact(jarFile string) bool {
+	return strings.HasPrefix(jarFile, MavenArtifactPrefix)
+}
+
+// GetMavenArtifactURL resolves the Maven coordinates
+// `mvn:<groupId>:<artifactId>:<version>[:<classifier>]` of a JAR file to its URL in the
+// repository, Maven Central if unspecified.
+func GetMavenArtifactURL(repository *MavenRepositorySpec, coordinates string) (string, error) {
+	var parts = strings.Split(strings.TrimPrefix(coordinates, MavenArtifactPrefix), ":")
+	if len(parts) < 3 || len(parts) > 4 {
+		return "", fmt.Errorf("invalid Maven coordinates %q, expected %v<groupId>:<artifactId>:<version>[:<classifier>]",
+			coordinates, MavenArtifactPrefix)
+	}
+	for _, part := range parts {
+		if len(part) == 0 || strings.ContainsAny(part, "/\\") || part == "." || part == ".." {
+			return "", fmt.Errorf("invalid Maven coordinates %q", coordinates)
+		}
+	}
+	var groupID, artifactID, version = parts[0], parts[1], parts[2]
+	if strings.HasSuffix(version, "-SNAPSHOT") {
+		return "", fmt.Errorf("invalid Maven coordinates %q, SNAPSHOT versions are not supported", coordinates)
+	}
+	var fileName = artifactID + "-" + version
+	if len(parts) == 4 {
+		fileName += "-" + parts[3]
+	}
+
+	var repositoryURL = defaultMavenRepositoryURL
+	if repository != nil && repository.URL != nil {
+		repositoryURL = *repository.URL
+	}
+	return strings.Join([]string{
+		strings.TrimSuffix(repositoryURL, "/"),
+		strings.ReplaceAll(groupID, ".", "/"),
+		artifactID,
+		version,
+		fileName + ".jar",
+	}, "/"), nil
+}
+
+// GetKey returns the key of the certificates in the ConfigMap.
+func (c *CABundleSpec) GetKey() string {
+	if c.Key != nil {
+		return *c.Key
+	}
+	return DefaultCABundleKey
+}
+
+// GetFileName returns the name of the file in its directory, the name of the file in the URI
+// if unspecified.
+func (a *TaskManagerArtifact) GetFileName() string {
+	if a.FileName != nil {
+		return *a.FileName
+	}
+	if u, err := url.Parse(a.URI); err == nil {
+		if name := path.Base(u.Path); name != "/" && name != "." {
+			return name
+		}
+	}
+	return ""
+}
+
+// ParseProxyURL returns the host and port of a proxy URL of spec.networking.proxy. The port
+// defaults to the one of the scheme.
+func ParseProxyURL(proxyURL string) (string, string, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", fmt.Errorf("the scheme must be http or https")
+	}
+	if u.Hostname() == "" {
+		return "", "", fmt.Errorf("the host is missing")
+	}
+	if u.User != nil {
+		return "", "", fmt.Errorf("credentials are not supported")
+	}
+	var port = u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return u.Hostname(), port, nil
+}
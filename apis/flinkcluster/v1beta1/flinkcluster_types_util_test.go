@@ -22,6 +22,8 @@ import (
 
 	"github.com/spotify/flink-on-k8s-operator/internal/util"
 	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -154,4 +156,100 @@ func TestShouldRestartJob(t *testing.T) {
 	}
 	restart = jobStatus.ShouldRestart(&jobSpec)
 	assert.Equal(t, restart, false)
+
+	// Not restart after the restore verification failed
+	jobCompletionTime = savepointTime.Add(time.Second * 60)
+	jobSpec = JobSpec{
+		RestartPolicy:               &restartOnFailure,
+		MaxStateAgeToRestoreSeconds: &maxStateAgeToRestoreSeconds,
+	}
+	jobStatus = JobStatus{
+		State:             JobStateFailed,
+		SavepointLocation: "gs://my-bucket/savepoint-123",
+		SavepointTime:     tc.ToString(savepointTime),
+		CompletionTime:    &metav1.Time{Time: jobCompletionTime},
+		RestoreVerification: &RestoreVerificationStatus{
+			Savepoint: "gs://my-bucket/savepoint-123",
+			State:     RestoreVerificationStateFailed,
+		},
+	}
+	restart = jobStatus.ShouldRestart(&jobSpec)
+	assert.Equal(t, restart, false)
+}
+
+func TestIsReadOnlyUI(t *testing.T) {
+	var readOnly, writable = true, false
+	assert.Assert(t, !(&JobManagerSpec{AccessScope: AccessScopeCluster}).IsReadOnlyUI())
+	assert.Assert(t, (&JobManagerSpec{AccessScope: AccessScopeExternal}).IsReadOnlyUI())
+	assert.Assert(t, (&JobManagerSpec{AccessScope: AccessScopeVPC, ReadOnlyUI: &readOnly}).IsReadOnlyUI())
+	assert.Assert(t, !(&JobManagerSpec{AccessScope: AccessScopeExternal, ReadOnlyUI: &writable}).IsReadOnlyUI())
+}
+
+func TestGetTaskSlots(t *testing.T) {
+	var cluster = FlinkCluster{Spec: FlinkClusterSpec{TaskManager: &TaskManagerSpec{
+		Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")}},
+	}}}
+	var getTaskSlots = func() int32 {
+		slots, err := cluster.GetTaskSlots()
+		assert.NilError(t, err)
+		return slots
+	}
+	assert.Equal(t, getTaskSlots(), int32(1))
+
+	cluster.Spec.FlinkProperties = map[string]string{"taskmanager.numberOfTaskSlots": "4"}
+	assert.Equal(t, getTaskSlots(), int32(4))
+
+	var slotsPerCPU = resource.MustParse("1.5")
+	cluster.Spec.FlinkProperties = nil
+	cluster.Spec.TaskManager.SlotsPerCPU = &slotsPerCPU
+	assert.Equal(t, getTaskSlots(), int32(4))
+	slotsPerCPU = resource.MustParse("0.25")
+	assert.Equal(t, getTaskSlots(), int32(1))
+
+	var taskSlots int32 = 6
+	cluster.Spec.TaskManager.SlotsPerCPU = nil
+	cluster.Spec.TaskManager.TaskSlots = &taskSlots
+	assert.Equal(t, getTaskSlots(), int32(6))
+}
+
+func TestGetPrometheusReporterPorts(t *testing.T) {
+	var cluster = FlinkCluster{Spec: FlinkClusterSpec{FlinkProperties: map[string]string{
+		"metrics.reporter.prom.factory.class": "org.apache.flink.metrics.prometheus.PrometheusReporterFactory",
+		"metrics.reporter.prom.port":          "9250-9260",
+		"metrics.reporter.legacy.class":       "org.apache.flink.metrics.prometheus.PrometheusReporter",
+		"metrics.reporter.push.factory.class": "org.apache.flink.metrics.prometheus.PrometheusPushGatewayReporterFactory",
+		"metrics.reporter.jmx.factory.class":  "org.apache.flink.metrics.jmx.JMXReporterFactory",
+		"taskmanager.numberOfTaskSlots":       "1",
+	}}}
+	assert.DeepEqual(t, cluster.GetPrometheusReporterPorts(), map[string]int32{"legacy": 9249, "prom": 9250})
+
+	var port int32 = 9300
+	cluster.Spec.Monitoring = &MonitoringSpec{Reporters: []MetricsReporter{
+		{Name: "typed", Prometheus: &PrometheusReporter{Port: &port}},
+		{Name: "prom", Prometheus: &PrometheusReporter{}},
+		{Name: "logs", Slf4j: &Slf4jReporter{}},
+	}}
+	assert.DeepEqual(t, cluster.GetPrometheusReporterPorts(), map[string]int32{"legacy": 9249, "prom": 9250, "typed": 9300})
+	cluster.Spec.Monitoring = nil
+
+	cluster.Spec.FlinkProperties = nil
+	assert.Assert(t, cluster.GetPrometheusReporterPorts() == nil)
+}
+
+func TestGetMavenArtifactURL(t *testing.T) {
+	url, err := GetMavenArtifactURL(nil, "mvn:com.acme:pipeline:1.4.2")
+	assert.NilError(t, err)
+	assert.Equal(t, url, "https://repo1.maven.org/maven2/com/acme/pipeline/1.4.2/pipeline-1.4.2.jar")
+
+	var repositoryURL = "https://maven.acme.com/releases/"
+	url, err = GetMavenArtifactURL(&MavenRepositorySpec{URL: &repositoryURL}, "mvn:com.acme:pipeline:1.4.2:all")
+	assert.NilError(t, err)
+	assert.Equal(t, url, "https://maven.acme.com/releases/com/acme/pipeline/1.4.2/pipeline-1.4.2-all.jar")
+
+	_, err = GetMavenArtifactURL(nil, "mvn:com.acme:pipeline")
+	assert.ErrorContains(t, err, "invalid Maven coordinates")
+	_, err = GetMavenArtifactURL(nil, "mvn:com.acme:pipeline:../1.4.2")
+	assert.ErrorContains(t, err, "invalid Maven coordinates")
+	_, err = GetMavenArtifactURL(nil, "mvn:com.acme:pipeline:1.5.0-SNAPSHOT")
+	assert.ErrorContains(t, err, "SNAPSHOT versions are not supported")
 }
@@ -17,14 +17,25 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"path"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	// Embeds the time zone database to validate spec.timezone regardless of the operator image.
+	_ "time/tzdata"
 
 	"github.com/hashicorp/go-version"
+	"github.com/spotify/flink-on-k8s-operator/internal/registry"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 
@@ -32,22 +43,75 @@ import (
 	"k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	InvalidControlAnnMsg           = "invalid value for annotation key: %v, value: %v, available values: savepoint, job-cancel"
+	InvalidControlAnnMsg           = "invalid value for annotation key: %v, value: %v, available values: savepoint, job-cancel, flight-recording, thread-dump, canary-promote, canary-abort, debug, wake-up"
 	InvalidJobStateForJobCancelMsg = "job-cancel is not allowed because job is not started yet or already terminated, annotation: %v"
 	InvalidJobStateForSavepointMsg = "savepoint is not allowed because job is not started yet or already stopped, annotation: %v"
 	InvalidSavepointDirMsg         = "savepoint is not allowed without spec.job.savepointsDir, annotation: %v"
 	SessionClusterWarnMsg          = "%v is not allowed for session cluster, annotation: %v"
+	InvalidDiagnosticsMsg          = "flight-recording is not allowed without spec.diagnostics, annotation: %v"
+	InvalidCanaryUpdateStateMsg    = "%v is not allowed because no canary update is in progress, annotation: %v"
+	InvalidIdlePolicyMsg           = "wake-up is not allowed without spec.idlePolicy, annotation: %v"
 	ControlChangeWarnMsg           = "change is not allowed for control in progress, annotation: %v"
 	dns1035ErrorMsg                = "cluster name %s is invalid: a DNS-1035 name must consist of lower case alphanumeric characters or '-', start with an alphabetic character, and end with an alphanumeric character (e.g. 'my-name', or 'abc-123', regex used for validation is '[a-z]([-a-z0-9]*[a-z0-9])?'"
 	maxClusterNameLength           = 49 // 63 - 14 (max suffix length)
+	maxUpdateWindowDurationSeconds = 7 * 24 * 60 * 60
+	// The time the image architecture check waits for the registry, well within the
+	// timeout of the webhook.
+	imageArchitectureCheckTimeout = 5 * time.Second
 )
 
+// ResourceQuotaCheckMode defines how the aggregate resource request of a new
+// cluster is checked against the ResourceQuotas of its namespace.
+type ResourceQuotaCheckMode string
+
+const (
+	ResourceQuotaCheckDisabled ResourceQuotaCheckMode = ""
+	ResourceQuotaCheckWarn     ResourceQuotaCheckMode = "Warn"
+	ResourceQuotaCheckReject   ResourceQuotaCheckMode = "Reject"
+)
+
+const (
+	flinkFeatureFineGrainedResourceManagement = "fine-grained resource management"
+	flinkFeatureThreadDump                    = "thread-dump"
+	flinkFeatureMetricsReporterSecrets        = "passing secrets to metrics reporters"
+	flinkFeatureStopWithSavepoint             = "stop-with-savepoint"
+)
+
+// Minimum Flink versions of the features which depend on the Flink version.
+var flinkFeatureVersions = map[string]*version.Version{
+	flinkFeatureFineGrainedResourceManagement: version.Must(version.NewVersion("1.14")),
+	// The thread dump of the JobManager is served since Flink 1.13.
+	flinkFeatureThreadDump: version.Must(version.NewVersion("1.13")),
+	// The secrets are passed as dynamic properties, which the JobManager accepts since Flink 1.11.
+	flinkFeatureMetricsReporterSecrets: version.Must(version.NewVersion("1.11")),
+	// The jobs are stopped with a savepoint by the stop endpoint since Flink 1.9.
+	flinkFeatureStopWithSavepoint: version.Must(version.NewVersion("1.9")),
+}
+
 // Validator validates CUD requests for the CR.
-type Validator struct{}
+// +kubebuilder:object:generate=false
+type Validator struct {
+	// Reads the ResourceQuotas of the cluster namespace, required unless the
+	// quota check is disabled.
+	quotaReader    client.Reader
+	quotaCheckMode ResourceQuotaCheckMode
+	// Whether the deletion of running job clusters requires ConfirmDeletionAnnotation.
+	deletionConfirmationRequired bool
+	// Reads the image pull secrets of the cluster namespace for the image architecture
+	// check, which is disabled if nil.
+	imageReader        client.Reader
+	imageRegistry      *registry.Client
+	defaultPullSecrets []string
+	// The IP families of the services of the Kubernetes cluster, which are not checked
+	// if empty.
+	clusterIPFamilies []corev1.IPFamily
+}
 
 // ValidateCreate validates create request.
 func (v *Validator) ValidateCreate(cluster *FlinkCluster) error {
@@ -56,6 +120,16 @@ func (v *Validator) ValidateCreate(cluster *FlinkCluster) error {
 	if err != nil {
 		return err
 	}
+	err = v.validateClusterRef(cluster)
+	if err != nil {
+		return err
+	}
+	if len(cluster.Spec.FlinkVersion) == 0 {
+		return fmt.Errorf("spec.flinkVersion is unspecified")
+	}
+	if len(cluster.Spec.Image.Name) == 0 {
+		return fmt.Errorf("spec.image.name is unspecified")
+	}
 
 	var flinkVersion *version.Version
 	if len(cluster.Spec.FlinkVersion) != 0 {
@@ -69,18 +143,133 @@ func (v *Validator) ValidateCreate(cluster *FlinkCluster) error {
 	if err != nil {
 		return err
 	}
+	err = v.validateFlinkPlugins(&cluster.Spec)
+	if err != nil {
+		return err
+	}
+	err = v.validateExtraConfigMounts(cluster.Spec.ExtraConfigMounts, cluster.Spec.LogConfig)
+	if err != nil {
+		return err
+	}
+	err = v.validateConfigOverride(&cluster.Spec)
+	if err != nil {
+		return err
+	}
+	err = v.validateSecretsInjection(cluster.Spec.SecretsInjection, cluster.Spec.FlinkProperties, cluster.Spec.ConfigOverride)
+	if err != nil {
+		return err
+	}
+	err = v.validateFlinkPropertiesFrom(cluster.Spec.FlinkPropertiesFrom)
+	if err != nil {
+		return err
+	}
+	err = v.validateStateEncryption(&cluster.Spec)
+	if err != nil {
+		return err
+	}
+	err = v.validateTimezone(cluster.Spec.Timezone)
+	if err != nil {
+		return err
+	}
+	err = v.validateNetworking(cluster.Spec.Networking)
+	if err != nil {
+		return err
+	}
+	err = v.validateJVMOptions(&cluster.Spec)
+	if err != nil {
+		return err
+	}
+	err = v.validateJars(cluster.Spec.Jars, cluster.Spec.Job)
+	if err != nil {
+		return err
+	}
+	err = v.validateDiagnostics(&cluster.Spec)
+	if err != nil {
+		return err
+	}
+	err = v.validateStateExport(cluster.Spec.StateExport)
+	if err != nil {
+		return err
+	}
+	err = v.validateLogging(cluster.Spec.Logging)
+	if err != nil {
+		return err
+	}
+	err = v.validateMonitoring(flinkVersion, cluster)
+	if err != nil {
+		return err
+	}
+	err = v.validateCanaryUpdate(&cluster.Spec)
+	if err != nil {
+		return err
+	}
+	err = v.validateUpdatePolicy(cluster.Spec.UpdatePolicy)
+	if err != nil {
+		return err
+	}
+	if limit := cluster.Spec.RevisionHistoryLimit; limit != nil && *limit < 0 {
+		return fmt.Errorf("spec.revisionHistoryLimit must be >= 0")
+	}
+	if cluster.Spec.DeletionPolicy != nil {
+		err = v.validateDeletionPolicy(*cluster.Spec.DeletionPolicy)
+		if err != nil {
+			return err
+		}
+	}
+	if cluster.Spec.Architecture != nil {
+		err = v.validateArchitecture(*cluster.Spec.Architecture)
+		if err != nil {
+			return err
+		}
+	}
+	err = v.validateIdlePolicy(&cluster.Spec)
+	if err != nil {
+		return err
+	}
+	err = v.validateStartupDeadline(&cluster.Spec)
+	if err != nil {
+		return err
+	}
+	err = v.validateDependsOn(cluster)
+	if err != nil {
+		return err
+	}
 	err = v.validateJobManager(flinkVersion, cluster.Spec.JobManager)
 	if err != nil {
 		return err
 	}
+	err = v.validateJobManagerStorage(&cluster.Spec)
+	if err != nil {
+		return err
+	}
 	err = v.validateTaskManager(flinkVersion, cluster.Spec.TaskManager)
 	if err != nil {
 		return err
 	}
+	err = v.validateSlotResources(cluster)
+	if err != nil {
+		return err
+	}
 	err = v.validateJob(cluster.Spec.Job)
 	if err != nil {
 		return err
 	}
+	err = v.validateUpdateStopMode(flinkVersion, cluster.Spec.Job)
+	if err != nil {
+		return err
+	}
+	err = v.validateSavepointTriggerMode(cluster.Spec.Job)
+	if err != nil {
+		return err
+	}
+	err = v.validateTaskSlots(&cluster.Spec)
+	if err != nil {
+		return err
+	}
+	err = v.validateJobParallelism(cluster)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -110,6 +299,11 @@ func (v *Validator) ValidateUpdate(old *FlinkCluster, new *FlinkCluster) error {
 		return nil
 	}
 
+	err = v.validateJobManagerUpdate(old, new)
+	if err != nil {
+		return err
+	}
+
 	err = v.validateTaskManagerUpdate(old, new)
 	if err != nil {
 		return err
@@ -128,6 +322,23 @@ func (v *Validator) ValidateUpdate(old *FlinkCluster, new *FlinkCluster) error {
 	return nil
 }
 
+// ValidateDelete validates delete request. When the confirmation is required, running job
+// clusters, degraded and suspending ones included, are only deleted with
+// ConfirmDeletionAnnotation set to "true", so that stateful jobs are not deleted by mistake.
+func (v *Validator) ValidateDelete(cluster *FlinkCluster) error {
+	var state = cluster.Status.State
+	if !v.deletionConfirmationRequired || cluster.Spec.Job == nil ||
+		(state != ClusterStateRunning && state != ClusterStateDegraded && state != ClusterStateSuspending) {
+		return nil
+	}
+	if cluster.Annotations[ConfirmDeletionAnnotation] == "true" {
+		return nil
+	}
+	return fmt.Errorf(
+		"deletion of the running job cluster %v is not allowed without confirmation, set the annotation %v to \"true\" first",
+		cluster.Name, ConfirmDeletionAnnotation)
+}
+
 func (v *Validator) checkControlAnnotations(old *FlinkCluster, new *FlinkCluster) error {
 	oldUserControl := old.Annotations[ControlAnnotation]
 	newUserControl, ok := new.Annotations[ControlAnnotation]
@@ -152,6 +363,27 @@ func (v *Validator) checkControlAnnotations(old *FlinkCluster, new *FlinkCluster
 			} else if job == nil || job.IsStopped() {
 				return fmt.Errorf(InvalidJobStateForSavepointMsg, ControlAnnotation)
 			}
+		case ControlNameFlightRecording:
+			if old.Spec.Diagnostics == nil {
+				return fmt.Errorf(InvalidDiagnosticsMsg, ControlAnnotation)
+			}
+		case ControlNameThreadDump:
+			flinkVersion, _ := version.NewVersion(old.Spec.FlinkVersion)
+			if err := v.checkFlinkFeature(flinkVersion, flinkFeatureThreadDump); err != nil {
+				return fmt.Errorf("%v, annotation: %v", err, ControlAnnotation)
+			}
+		case ControlNameDebug:
+			if pod := new.Annotations[DebugPodAnnotation]; pod != "" && len(validation.NameIsDNSSubdomain(pod, false)) > 0 {
+				return fmt.Errorf("invalid pod name %v, annotation: %v", pod, DebugPodAnnotation)
+			}
+		case ControlNameCanaryPromote, ControlNameCanaryAbort:
+			if !old.Status.CanaryUpdate.IsActive() {
+				return fmt.Errorf(InvalidCanaryUpdateStateMsg, newUserControl, ControlAnnotation)
+			}
+		case ControlNameWakeUp:
+			if old.Spec.IdlePolicy == nil {
+				return fmt.Errorf(InvalidIdlePolicyMsg, ControlAnnotation)
+			}
 		default:
 			return fmt.Errorf(InvalidControlAnnMsg, ControlAnnotation, newUserControl)
 		}
@@ -222,6 +454,21 @@ func (v *Validator) checkSavepointGeneration(
 		"you cannot update savepointGeneration with others at the same time")
 }
 
+// The retention policy of the JobManager storage is set in the owner references of its claim
+// when it is created, so it cannot be changed later. Claims without storage are deleted.
+func (v *Validator) validateJobManagerUpdate(old *FlinkCluster, new *FlinkCluster) error {
+	var getRetentionPolicy = func(jmSpec *JobManagerSpec) StorageRetentionPolicy {
+		if jmSpec == nil || jmSpec.Storage == nil {
+			return StorageRetentionPolicyDelete
+		}
+		return jmSpec.Storage.GetRetentionPolicy()
+	}
+	if getRetentionPolicy(old.Spec.JobManager) != getRetentionPolicy(new.Spec.JobManager) {
+		return fmt.Errorf("updating spec.jobManager.storage.retentionPolicy is not allowed")
+	}
+	return nil
+}
+
 func (v *Validator) validateTaskManagerUpdate(old *FlinkCluster, new *FlinkCluster) error {
 	//  When flink-operator updated, old flinkCluster does not have TaskManager.DeploymentType, may cause update failed.
 	oldDeploymentType := old.Spec.TaskManager.DeploymentType
@@ -304,6 +551,39 @@ func (v *Validator) validateMeta(meta *metav1.ObjectMeta) error {
 	return nil
 }
 
+// Validates spec.clusterRef of the cluster and the spec of templates. The templates are
+// applied by the mutating webhook, so the cluster is rejected if its template was not.
+func (v *Validator) validateClusterRef(cluster *FlinkCluster) error {
+	var ref = cluster.Spec.ClusterRef
+	if cluster.Annotations[TemplateAnnotation] == "true" {
+		if cluster.Spec.Job != nil {
+			return fmt.Errorf("spec.job cannot be set in a template, templates must be session clusters")
+		}
+		if ref != nil {
+			return fmt.Errorf("spec.clusterRef cannot be set in a template")
+		}
+	}
+	if ref == nil {
+		return nil
+	}
+	if cluster.Spec.Job == nil {
+		return fmt.Errorf("spec.clusterRef is only applicable to job clusters")
+	}
+	if len(ref.Name) == 0 {
+		return fmt.Errorf("spec.clusterRef.name is unspecified")
+	}
+	if ref.Name == cluster.Name {
+		return fmt.Errorf("spec.clusterRef cannot reference the cluster itself")
+	}
+	if cluster.Annotations[AppliedTemplateAnnotation] != "" {
+		return nil
+	}
+	if _, err := getClusterTemplate(templateReader, cluster); err != nil {
+		return fmt.Errorf("spec.clusterRef: %v", err)
+	}
+	return fmt.Errorf("spec.clusterRef: the template %v was not applied", ref.Name)
+}
+
 func (v *Validator) validateGCPConfig(gcpConfig *GCPConfig) error {
 	if gcpConfig == nil {
 		return nil
@@ -326,161 +606,1248 @@ func (v *Validator) validateGCPConfig(gcpConfig *GCPConfig) error {
 	return nil
 }
 
-func (v *Validator) validateJobManager(flinkVersion *version.Version, jmSpec *JobManagerSpec) error {
-	var err error
-	if jmSpec == nil {
-		return nil
+func (v *Validator) validateFlinkPlugins(clusterSpec *FlinkClusterSpec) error {
+	var fp = field.NewPath("spec.flinkPlugins")
+	var plugins = map[string]bool{}
+	for i, plugin := range clusterSpec.FlinkPlugins {
+		switch plugin {
+		case FlinkPluginS3FsHadoop, FlinkPluginS3FsPresto, FlinkPluginAzureFsHadoop, FlinkPluginGSFsHadoop, FlinkPluginOSSFsHadoop:
+		default:
+			return fmt.Errorf("invalid %v %q, must be one of s3-fs-hadoop, s3-fs-presto, azure-fs-hadoop, gs-fs-hadoop or oss-fs-hadoop",
+				fp.Index(i), plugin)
+		}
+		if plugins[string(plugin)] {
+			return fmt.Errorf("%v: duplicate plugin %v", fp.Index(i), plugin)
+		}
+		plugins[string(plugin)] = true
+	}
+	// The directory of an enabled plugin is replaced, files cannot be added to it.
+	if tmSpec := clusterSpec.TaskManager; tmSpec != nil {
+		for i, artifact := range tmSpec.ExtraArtifacts {
+			var plugin = strings.TrimPrefix(artifact.Directory, "plugins/")
+			if artifact.Directory != plugin && plugins[plugin] {
+				return fmt.Errorf("%v cannot be the directory of %v %v",
+					field.NewPath("spec.taskManager.extraArtifacts").Index(i).Child("directory"), fp, plugin)
+			}
+		}
 	}
+	return nil
+}
 
-	fp := field.NewPath("spec.jobManager")
-	if errors := validation.ValidateAnnotations(jmSpec.PodAnnotations, fp.Child("podAnnotations")); len(errors) > 0 {
-		return fmt.Errorf(errors.ToAggregate().Error())
-	}
-	if errors := v1validation.ValidateLabels(jmSpec.PodLabels, fp.Child("podLabels")); len(errors) > 0 {
-		return fmt.Errorf(errors.ToAggregate().Error())
+func (v *Validator) validateExtraConfigMounts(mounts []ExtraConfigMount, logConfig map[string]string) error {
+	// Files generated by the operator in the Flink conf directory.
+	var paths = map[string]bool{
+		"flink-conf.yaml":          true,
+		"submit-job.sh":            true,
+		"log4j-console.properties": true,
+		"logback-console.xml":      true,
+		"ui-proxy.conf":            true,
+	}
+	for k := range logConfig {
+		paths[k] = true
+	}
+
+	fp := field.NewPath("spec.extraConfigMounts")
+	for i, mount := range mounts {
+		var name string
+		var items []corev1.KeyToPath
+		switch {
+		case mount.ConfigMap != nil && mount.Secret != nil:
+			return fmt.Errorf("%v: only one of configMap or secret can be specified", fp.Index(i))
+		case mount.ConfigMap != nil:
+			name, items = mount.ConfigMap.Name, mount.ConfigMap.Items
+		case mount.Secret != nil:
+			name, items = mount.Secret.Name, mount.Secret.Items
+		default:
+			return fmt.Errorf("%v: one of configMap or secret must be specified", fp.Index(i))
+		}
+		if len(name) == 0 {
+			return fmt.Errorf("%v: name is unspecified", fp.Index(i))
+		}
+		for _, item := range items {
+			var path = strings.TrimPrefix(item.Path, "./")
+			if len(path) == 0 {
+				return fmt.Errorf("%v: path is unspecified for key %v", fp.Index(i), item.Key)
+			}
+			if paths[path] {
+				return fmt.Errorf("%v: path %v conflicts with another file in the Flink conf directory", fp.Index(i), path)
+			}
+			paths[path] = true
+		}
 	}
+	return nil
+}
 
-	// Ports.
-	var ports = []NamedPort{
-		{Name: "rpc", ContainerPort: *jmSpec.Ports.RPC},
-		{Name: "blob", ContainerPort: *jmSpec.Ports.Blob},
-		{Name: "query", ContainerPort: *jmSpec.Ports.Query},
-		{Name: "ui", ContainerPort: *jmSpec.Ports.UI},
+func (v *Validator) validateConfigOverride(clusterSpec *FlinkClusterSpec) error {
+	var override = clusterSpec.ConfigOverride
+	if override == nil {
+		return nil
 	}
-	ports = append(ports, jmSpec.ExtraPorts...)
-	err = v.checkDupPorts(ports, "jobmanager")
-	if err != nil {
-		return err
+	fp := field.NewPath("spec.configOverride")
+	if len(override.ConfigMapName) == 0 {
+		return fmt.Errorf("%v is required", fp.Child("configMapName"))
 	}
-
-	if err := v.validateResourceRequirements(jmSpec.Resources, "jobmanager"); err != nil {
-		return err
+	if len(clusterSpec.FlinkProperties) > 0 {
+		return fmt.Errorf("spec.flinkProperties cannot be set with %v, set the properties in its ConfigMap", fp)
 	}
+	return nil
+}
 
-	if flinkVersion == nil || flinkVersion.LessThan(v10) {
-		if jmSpec.MemoryProcessRatio != nil {
-			return fmt.Errorf("MemoryProcessRatio config cannot be used with flinkVersion < 1.11', use " +
-				"memoryOffHeapRatio instead")
+// The placeholders of spec.secretsInjection, which are environment variable names.
+var secretPlaceholderRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validates the placeholders and the Secret keys of spec.secretsInjection. Placeholders which
+// are not referenced in flinkProperties are rejected, as they are likely misspelled, unless
+// the configuration is replaced by spec.configOverride, whose references cannot be checked.
+func (v *Validator) validateSecretsInjection(injections []SecretInjection, flinkProperties map[string]string, override *ConfigOverrideSpec) error {
+	var seen = map[string]bool{}
+	fp := field.NewPath("spec.secretsInjection")
+	for i, injection := range injections {
+		var placeholder = injection.Placeholder
+		if !secretPlaceholderRegexp.MatchString(placeholder) {
+			return fmt.Errorf("%v: invalid placeholder %q, must be a valid environment variable name", fp.Index(i).Child("placeholder"), placeholder)
 		}
-
-		// MemoryOffHeapRatio
-		err = v.validateRatio(jmSpec.MemoryOffHeapRatio, "jobmanager", "memoryOffHeapRatio")
-		if err != nil {
-			return err
+		if seen[placeholder] {
+			return fmt.Errorf("%v: duplicate placeholder %v", fp.Index(i).Child("placeholder"), placeholder)
 		}
-
-		// MemoryOffHeapMin
-		err = v.validateMemoryOffHeapMin(&jmSpec.MemoryOffHeapMin, jmSpec.GetResources().Memory(), "jobmanager")
-		if err != nil {
-			return err
+		if placeholder == GCSEncryptionKeyPlaceholder || placeholder == GCSEncryptionKeyHashPlaceholder {
+			return fmt.Errorf("%v: placeholder %v is reserved for spec.stateEncryption", fp.Index(i).Child("placeholder"), placeholder)
 		}
-	} else {
-		if jmSpec.MemoryOffHeapRatio != nil || !jmSpec.MemoryOffHeapMin.IsZero() {
-			return fmt.Errorf("MemoryOffHeapRatio or MemoryOffHeapMin config cannot be used with flinkVersion >= 1.11'; " +
-				"use memoryProcessRatio istead")
+		seen[placeholder] = true
+		if injection.SecretKeyRef.Name == "" || injection.SecretKeyRef.Key == "" {
+			return fmt.Errorf("%v: name and key are required", fp.Index(i).Child("secretKeyRef"))
 		}
-		// MemoryProcessRatio
-		err = v.validateRatio(jmSpec.MemoryProcessRatio, "jobmanager", "memoryProcessRatio")
-		if err != nil {
-			return err
+
+		var referenced = override != nil
+		for _, value := range flinkProperties {
+			if strings.Contains(value, "${"+placeholder+"}") {
+				referenced = true
+				break
+			}
+		}
+		if !referenced {
+			return fmt.Errorf("%v: placeholder ${%v} is not referenced in spec.flinkProperties", fp.Index(i).Child("placeholder"), placeholder)
 		}
 	}
+	return nil
+}
 
+func (v *Validator) validateFlinkPropertiesFrom(sources []FlinkPropertiesSource) error {
+	fp := field.NewPath("spec.flinkPropertiesFrom")
+	for i, source := range sources {
+		switch {
+		case source.SecretRef != nil && source.External != nil:
+			return fmt.Errorf("%v: only one of secretRef or external can be specified", fp.Index(i))
+		case source.SecretRef != nil:
+			if source.SecretRef.Name == "" {
+				return fmt.Errorf("%v: name is required", fp.Index(i).Child("secretRef"))
+			}
+		case source.External != nil:
+			var external = source.External
+			switch external.Provider {
+			case ExternalSecretProviderVault, ExternalSecretProviderAWSSecretsManager:
+			case ExternalSecretProviderGCPSecretManager:
+				if !strings.HasPrefix(external.Name, "projects/") || !strings.Contains(external.Name, "/secrets/") {
+					return fmt.Errorf("%v: invalid name %q, must be projects/<project>/secrets/<secret>", fp.Index(i).Child("external"), external.Name)
+				}
+			default:
+				return fmt.Errorf("%v: invalid provider %q", fp.Index(i).Child("external"), external.Provider)
+			}
+			if external.Name == "" {
+				return fmt.Errorf("%v: name is required", fp.Index(i).Child("external"))
+			}
+		default:
+			return fmt.Errorf("%v: one of secretRef or external must be specified", fp.Index(i))
+		}
+	}
 	return nil
 }
 
-func (v *Validator) validateTaskManager(flinkVersion *version.Version, tmSpec *TaskManagerSpec) error {
-	if tmSpec == nil {
+// Validates that exactly one provider of spec.stateEncryption is specified with all of its
+// required fields, and that the properties it sets are not set in spec.flinkProperties.
+func (v *Validator) validateStateEncryption(clusterSpec *FlinkClusterSpec) error {
+	var encryption = clusterSpec.StateEncryption
+	if encryption == nil {
 		return nil
 	}
-
-	fp := field.NewPath("spec.taskManager")
-	if errors := validation.ValidateAnnotations(tmSpec.PodAnnotations, fp.Child("podAnnotations")); len(errors) > 0 {
-		return fmt.Errorf(errors.ToAggregate().Error())
+	fp := field.NewPath("spec.stateEncryption")
+	if clusterSpec.ConfigOverride != nil {
+		return fmt.Errorf("%v cannot be used with spec.configOverride", fp)
 	}
-	if errors := v1validation.ValidateLabels(tmSpec.PodLabels, fp.Child("podLabels")); len(errors) > 0 {
-		return fmt.Errorf(errors.ToAggregate().Error())
+	var validateSecretRef = func(path *field.Path, ref *corev1.SecretKeySelector) error {
+		if ref.Name == "" || ref.Key == "" {
+			return fmt.Errorf("%v: name and key are required", path)
+		}
+		return nil
+	}
+	switch {
+	case encryption.AWSKMS != nil && encryption.GCSCustomerKey != nil:
+		return fmt.Errorf("%v: only one of awsKMS or gcsCustomerKey can be specified", fp)
+	case encryption.AWSKMS != nil:
+		var kms = encryption.AWSKMS
+		var kmsPath = fp.Child("awsKMS")
+		if kms.KeyID == "" {
+			return fmt.Errorf("%v is unspecified", kmsPath.Child("keyID"))
+		}
+		if (kms.AccessKeyIDSecretRef == nil) != (kms.SecretAccessKeySecretRef == nil) {
+			return fmt.Errorf("%v and %v must be specified together",
+				kmsPath.Child("accessKeyIDSecretRef"), kmsPath.Child("secretAccessKeySecretRef"))
+		}
+		if kms.AccessKeyIDSecretRef != nil {
+			if err := validateSecretRef(kmsPath.Child("accessKeyIDSecretRef"), kms.AccessKeyIDSecretRef); err != nil {
+				return err
+			}
+			if err := validateSecretRef(kmsPath.Child("secretAccessKeySecretRef"), kms.SecretAccessKeySecretRef); err != nil {
+				return err
+			}
+		}
+	case encryption.GCSCustomerKey != nil:
+		var customerKey = encryption.GCSCustomerKey
+		var keyPath = fp.Child("gcsCustomerKey")
+		if err := validateSecretRef(keyPath.Child("keySecretRef"), &customerKey.KeySecretRef); err != nil {
+			return err
+		}
+		if err := validateSecretRef(keyPath.Child("keyHashSecretRef"), &customerKey.KeyHashSecretRef); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%v: one of awsKMS or gcsCustomerKey must be specified", fp)
 	}
 
-	// Ports.
-	var err error
-	var ports = []NamedPort{
-		{Name: "rpc", ContainerPort: *tmSpec.Ports.RPC},
-		{Name: "data", ContainerPort: *tmSpec.Ports.Data},
-		{Name: "query", ContainerPort: *tmSpec.Ports.Query},
+	var keys []string
+	for key := range encryption.GetFlinkProperties() {
+		keys = append(keys, key)
 	}
-	ports = append(ports, tmSpec.ExtraPorts...)
-	err = v.checkDupPorts(ports, "taskmanager")
-	if err != nil {
-		return err
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := clusterSpec.FlinkProperties[key]; ok {
+			return fmt.Errorf("%v cannot be used with %v", fp, field.NewPath("spec", "flinkProperties").Key(key))
+		}
 	}
+	return nil
+}
 
-	if err := v.validateResourceRequirements(tmSpec.Resources, "taskmanager"); err != nil {
-		return err
+func (v *Validator) validateTimezone(timezone *string) error {
+	if timezone == nil {
+		return nil
+	}
+	// Local is the time zone of the operator, not a name of the time zone database.
+	if *timezone == "" || *timezone == "Local" {
+		return fmt.Errorf("invalid spec.timezone %q", *timezone)
 	}
+	if _, err := time.LoadLocation(*timezone); err != nil {
+		return fmt.Errorf("invalid spec.timezone %q: %v", *timezone, err)
+	}
+	return nil
+}
 
-	if flinkVersion == nil || flinkVersion.LessThan(v10) {
-		if tmSpec.MemoryProcessRatio != nil {
-			return fmt.Errorf("MemoryProcessRatio config cannot be used with flinkVersion < 1.11', use " +
-				"memoryOffHeapRatio instead")
+func (v *Validator) validateNetworking(networking *NetworkingSpec) error {
+	if networking == nil {
+		return nil
+	}
+	fp := field.NewPath("spec.networking")
+	if proxy := networking.Proxy; proxy != nil {
+		if proxy.HTTPProxy == nil && proxy.HTTPSProxy == nil {
+			return fmt.Errorf("%v: one of httpProxy or httpsProxy must be specified", fp.Child("proxy"))
 		}
-
+		if proxy.HTTPProxy != nil {
+			if _, _, err := ParseProxyURL(*proxy.HTTPProxy); err != nil {
+				return fmt.Errorf("invalid %v %q: %v", fp.Child("proxy", "httpProxy"), *proxy.HTTPProxy, err)
+			}
+		}
+		if proxy.HTTPSProxy != nil {
+			if _, _, err := ParseProxyURL(*proxy.HTTPSProxy); err != nil {
+				return fmt.Errorf("invalid %v %q: %v", fp.Child("proxy", "httpsProxy"), *proxy.HTTPSProxy, err)
+			}
+		}
+	}
+	if caBundle := networking.CABundle; caBundle != nil {
+		if caBundle.ConfigMapName == "" {
+			return fmt.Errorf("%v is required", fp.Child("caBundle", "configMapName"))
+		}
+		if caBundle.Key != nil && *caBundle.Key == "" {
+			return fmt.Errorf("%v must not be empty", fp.Child("caBundle", "key"))
+		}
+	}
+	if clusterDomain := networking.ClusterDomain; clusterDomain != nil {
+		if errs := utilvalidation.IsDNS1123Subdomain(*clusterDomain); len(errs) > 0 {
+			return fmt.Errorf("invalid %v %q: %v", fp.Child("clusterDomain"), *clusterDomain, strings.Join(errs, ", "))
+		}
+	}
+	return v.validateIPFamilies(networking, fp)
+}
+
+// validateIPFamilies validates the IP families of the services, and checks them against the
+// IP families of the Kubernetes cluster if they are known, as a single-stack cluster rejects
+// the services of the other family or which require dual-stack.
+func (v *Validator) validateIPFamilies(networking *NetworkingSpec, fp *field.Path) error {
+	var families = map[corev1.IPFamily]bool{}
+	for i, family := range networking.IPFamilies {
+		var familyPath = fp.Child("ipFamilies").Index(i)
+		switch {
+		case family != corev1.IPv4Protocol && family != corev1.IPv6Protocol:
+			return fmt.Errorf("invalid %v %q, must be %v or %v", familyPath, family, corev1.IPv4Protocol, corev1.IPv6Protocol)
+		case families[family]:
+			return fmt.Errorf("%v %v is duplicated", familyPath, family)
+		case len(v.clusterIPFamilies) > 0 && !containsIPFamily(v.clusterIPFamilies, family):
+			return fmt.Errorf("%v %v is not supported by the Kubernetes cluster, which supports %v",
+				familyPath, family, joinIPFamilies(v.clusterIPFamilies))
+		}
+		families[family] = true
+	}
+
+	var policy = networking.IPFamilyPolicy
+	if policy == nil {
+		return nil
+	}
+	switch {
+	case *policy == corev1.IPFamilyPolicySingleStack && len(networking.IPFamilies) > 1:
+		return fmt.Errorf("%v cannot be used with %v %v", fp.Child("ipFamilies"), fp.Child("ipFamilyPolicy"), *policy)
+	case *policy == corev1.IPFamilyPolicyRequireDualStack && len(v.clusterIPFamilies) == 1:
+		return fmt.Errorf("%v %v is not supported by the Kubernetes cluster, which supports %v",
+			fp.Child("ipFamilyPolicy"), *policy, joinIPFamilies(v.clusterIPFamilies))
+	}
+	return nil
+}
+
+func containsIPFamily(families []corev1.IPFamily, family corev1.IPFamily) bool {
+	for _, f := range families {
+		if f == family {
+			return true
+		}
+	}
+	return false
+}
+
+func joinIPFamilies(families []corev1.IPFamily) string {
+	var names []string
+	for _, family := range families {
+		names = append(names, string(family))
+	}
+	return strings.Join(names, ", ")
+}
+
+var ignoredFieldPathRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*(\.[A-Za-z][A-Za-z0-9]*)*$`)
+
+func (v *Validator) validateUpdatePolicy(policy *UpdatePolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.DebounceSeconds != nil && *policy.DebounceSeconds < 1 {
+		return fmt.Errorf("spec.updatePolicy.debounceSeconds must be >= 1")
+	}
+	for i, path := range policy.IgnoreFields {
+		if !ignoredFieldPathRegexp.MatchString(path) {
+			return fmt.Errorf("invalid %v %q, expected the path of a spec field separated by dots, e.g. job.resources",
+				field.NewPath("spec.updatePolicy.ignoreFields").Index(i), path)
+		}
+	}
+	if policy.Window == nil {
+		return nil
+	}
+
+	fp := field.NewPath("spec.updatePolicy.window")
+	var window = policy.Window
+	if _, err := util.ParseCronSchedule(window.Schedule); err != nil {
+		return fmt.Errorf("invalid %v %q: %v", fp.Child("schedule"), window.Schedule, err)
+	}
+	if window.DurationSeconds < 60 || window.DurationSeconds > maxUpdateWindowDurationSeconds {
+		return fmt.Errorf("%v must be between 60 and %v", fp.Child("durationSeconds"), maxUpdateWindowDurationSeconds)
+	}
+	if tz := window.Timezone; tz != nil {
+		if _, err := time.LoadLocation(*tz); err != nil || *tz == "" || *tz == "Local" {
+			return fmt.Errorf("invalid %v %q", fp.Child("timezone"), *tz)
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateJVMOptions(clusterSpec *FlinkClusterSpec) error {
+	var properties = clusterSpec.FlinkProperties
+	var propertiesPath = field.NewPath("spec", "flinkProperties")
+	// Options shared by all JVMs, which the component options would conflict with.
+	var sharedOptions = map[string]string{}
+	for _, key := range []string{"env.java.opts", "env.java.opts.all"} {
+		for _, option := range strings.Fields(properties[key]) {
+			sharedOptions[getJVMOptionName(option)] = key
+		}
+	}
+
+	var validate = func(jvmOptions []string, fp *field.Path, key string) error {
+		if len(jvmOptions) == 0 {
+			return nil
+		}
+		if _, ok := properties[key]; ok {
+			return fmt.Errorf("%v cannot be used with %v", fp, propertiesPath.Key(key))
+		}
+		var names = map[string]bool{}
+		for i, option := range jvmOptions {
+			if len(option) == 0 || len(strings.Fields(option)) != 1 {
+				return fmt.Errorf("%v: must be a single option without whitespace, got %q", fp.Index(i), option)
+			}
+			var name = getJVMOptionName(option)
+			if names[name] {
+				return fmt.Errorf("%v: duplicate option %v", fp.Index(i), name)
+			}
+			names[name] = true
+			if sharedKey, ok := sharedOptions[name]; ok {
+				return fmt.Errorf("%v: option %v conflicts with %v", fp.Index(i), name, propertiesPath.Key(sharedKey))
+			}
+		}
+		return nil
+	}
+
+	if clusterSpec.JobManager != nil {
+		if err := validate(clusterSpec.JobManager.JVMOptions,
+			field.NewPath("spec", "jobManager", "jvmOptions"), "env.java.opts.jobmanager"); err != nil {
+			return err
+		}
+	}
+	if clusterSpec.TaskManager != nil {
+		if err := validate(clusterSpec.TaskManager.JVMOptions,
+			field.NewPath("spec", "taskManager", "jvmOptions"), "env.java.opts.taskmanager"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getJVMOptionName returns the name of a JVM option to detect options which override each
+// other, e.g. `-XX:MaxGCPauseMillis` for `-XX:MaxGCPauseMillis=200`, and `-XX:UseG1GC` for both
+// `-XX:+UseG1GC` and `-XX:-UseG1GC`.
+func getJVMOptionName(option string) string {
+	switch {
+	case strings.HasPrefix(option, "-XX:"):
+		var name = strings.TrimLeft(strings.TrimPrefix(option, "-XX:"), "+-")
+		return "-XX:" + strings.SplitN(name, "=", 2)[0]
+	case strings.HasPrefix(option, "-D"):
+		return strings.SplitN(option, "=", 2)[0]
+	}
+	for _, prefix := range []string{"-Xmx", "-Xms", "-Xmn", "-Xss"} {
+		if strings.HasPrefix(option, prefix) {
+			return prefix
+		}
+	}
+	return option
+}
+
+func (v *Validator) validateJars(jars []SessionJar, jobSpec *JobSpec) error {
+	if len(jars) == 0 {
+		return nil
+	}
+
+	fp := field.NewPath("spec.jars")
+	if jobSpec != nil {
+		return fmt.Errorf("%v cannot be used with spec.job", fp)
+	}
+	var names = map[string]bool{}
+	for i, jar := range jars {
+		if len(jar.Name) == 0 {
+			return fmt.Errorf("%v: name is unspecified", fp.Index(i))
+		}
+		if names[jar.Name] {
+			return fmt.Errorf("%v: duplicate name %v", fp.Index(i), jar.Name)
+		}
+		names[jar.Name] = true
+		u, err := url.Parse(jar.URI)
+		if err != nil {
+			return fmt.Errorf("%v: invalid uri: %v", fp.Index(i), err)
+		}
+		switch u.Scheme {
+		case "http", "https", "gs", "s3":
+		default:
+			return fmt.Errorf("%v: unsupported uri scheme %q, must be one of http, https, gs or s3", fp.Index(i), u.Scheme)
+		}
+		if len(jar.SHA256) != 0 {
+			if _, err := hex.DecodeString(jar.SHA256); err != nil || len(jar.SHA256) != sha256.Size*2 {
+				return fmt.Errorf("%v: sha256 must be %v hex digits", fp.Index(i), sha256.Size*2)
+			}
+		}
+	}
+	return nil
+}
+
+var artifactDirectoryRegexp = regexp.MustCompile(`^(lib|plugins/[A-Za-z0-9][A-Za-z0-9._-]*)$`)
+
+func (v *Validator) validateTaskManagerArtifacts(tmSpec *TaskManagerSpec, fp *field.Path) error {
+	if len(tmSpec.ExtraArtifacts) == 0 {
+		return nil
+	}
+	if tmSpec.External != nil && *tmSpec.External {
+		return fmt.Errorf("%v cannot be used with %v", fp.Child("extraArtifacts"), fp.Child("external"))
+	}
+	var files = map[string]bool{}
+	for i, artifact := range tmSpec.ExtraArtifacts {
+		var artifactPath = fp.Child("extraArtifacts").Index(i)
+		u, err := url.Parse(artifact.URI)
+		if err != nil {
+			return fmt.Errorf("%v: invalid uri: %v", artifactPath, err)
+		}
+		switch u.Scheme {
+		case "http", "https", "gs", "s3":
+		default:
+			return fmt.Errorf("%v: unsupported uri scheme %q, must be one of http, https, gs or s3", artifactPath, u.Scheme)
+		}
+		if !artifactDirectoryRegexp.MatchString(artifact.Directory) {
+			return fmt.Errorf("invalid %v %q, must be lib or plugins/<plugin>", artifactPath.Child("directory"), artifact.Directory)
+		}
+		var fileName = artifact.GetFileName()
+		if fileName == "" || fileName == "." || fileName == ".." || strings.Contains(fileName, "/") {
+			return fmt.Errorf("invalid %v %q", artifactPath.Child("fileName"), fileName)
+		}
+		var file = path.Join(artifact.Directory, fileName)
+		if files[file] {
+			return fmt.Errorf("%v: duplicate file %v", artifactPath, file)
+		}
+		files[file] = true
+		if len(artifact.SHA256) != 0 {
+			if _, err := hex.DecodeString(artifact.SHA256); err != nil || len(artifact.SHA256) != sha256.Size*2 {
+				return fmt.Errorf("%v must be %v hex digits", artifactPath.Child("sha256"), sha256.Size*2)
+			}
+		}
+	}
+	return nil
+}
+
+// JVM options set by spec.diagnostics.heapDumpOnOutOfMemory.
+var heapDumpJVMOptions = []string{"-XX:HeapDumpOnOutOfMemoryError", "-XX:HeapDumpPath", "-XX:ExitOnOutOfMemoryError"}
+
+func (v *Validator) validateCanaryUpdate(clusterSpec *FlinkClusterSpec) error {
+	var canary = clusterSpec.CanaryUpdate
+	if canary == nil {
+		return nil
+	}
+
+	fp := field.NewPath("spec.canaryUpdate")
+	if clusterSpec.Job != nil {
+		return fmt.Errorf("%v is only applicable to session clusters", fp)
+	}
+	if tm := clusterSpec.TaskManager; tm != nil {
+		if tm.External != nil && *tm.External {
+			return fmt.Errorf("%v is not applicable to externally managed TaskManagers", fp)
+		}
+		if tm.DeploymentType == DeploymentTypeDeployment {
+			return fmt.Errorf("%v requires spec.taskManager.deploymentType: StatefulSet", fp)
+		}
+	}
+	if canary.Percentage < 1 || canary.Percentage > 100 {
+		return fmt.Errorf("%v must be between 1 and 100", fp.Child("percentage"))
+	}
+	if canary.SoakSeconds < 0 {
+		return fmt.Errorf("%v must be >= 0", fp.Child("soakSeconds"))
+	}
+	if canary.ProgressDeadlineSeconds < 1 {
+		return fmt.Errorf("%v must be >= 1", fp.Child("progressDeadlineSeconds"))
+	}
+	return nil
+}
+
+func (v *Validator) validateDiagnostics(clusterSpec *FlinkClusterSpec) error {
+	var diagnostics = clusterSpec.Diagnostics
+	if diagnostics == nil {
+		return nil
+	}
+
+	fp := field.NewPath("spec.diagnostics")
+	if diagnostics.FlightRecordingSeconds != nil && *diagnostics.FlightRecordingSeconds < 1 {
+		return fmt.Errorf("%v must be greater than 0", fp.Child("flightRecordingSeconds"))
+	}
+	if diagnostics.HeapDumpOnOutOfMemory == nil || *diagnostics.HeapDumpOnOutOfMemory {
+		var options = map[string]*field.Path{}
+		for _, key := range []string{"env.java.opts", "env.java.opts.all", "env.java.opts.jobmanager", "env.java.opts.taskmanager"} {
+			for _, option := range strings.Fields(clusterSpec.FlinkProperties[key]) {
+				options[getJVMOptionName(option)] = field.NewPath("spec", "flinkProperties").Key(key)
+			}
+		}
+		if clusterSpec.JobManager != nil {
+			for _, option := range clusterSpec.JobManager.JVMOptions {
+				options[getJVMOptionName(option)] = field.NewPath("spec", "jobManager", "jvmOptions")
+			}
+		}
+		if clusterSpec.TaskManager != nil {
+			for _, option := range clusterSpec.TaskManager.JVMOptions {
+				options[getJVMOptionName(option)] = field.NewPath("spec", "taskManager", "jvmOptions")
+			}
+		}
+		for _, name := range heapDumpJVMOptions {
+			if path, ok := options[name]; ok {
+				return fmt.Errorf("%v: option %v conflicts with %v", path, name, fp.Child("heapDumpOnOutOfMemory"))
+			}
+		}
+	}
+	if upload := diagnostics.Upload; upload != nil {
+		return v.validateUpload(upload, fp.Child("upload"))
+	}
+	return nil
+}
+
+// Validates the object storage of the uploads of diagnostics files and job plans.
+func (v *Validator) validateUpload(upload *DiagnosticsUpload, fp *field.Path) error {
+	u, err := url.Parse(upload.URI)
+	if err != nil {
+		return fmt.Errorf("%v: invalid uri: %v", fp, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "gs", "s3":
+	default:
+		return fmt.Errorf("%v: unsupported uri scheme %q, must be one of http, https, gs or s3", fp, u.Scheme)
+	}
+	if len(u.Host) == 0 {
+		return fmt.Errorf("%v: uri %v has no host or bucket", fp, upload.URI)
+	}
+	if secret := upload.AuthorizationSecret; secret != nil && (len(secret.Name) == 0 || len(secret.Key) == 0) {
+		return fmt.Errorf("%v: name and key are required", fp.Child("authorizationSecret"))
+	}
+	return nil
+}
+
+func (v *Validator) validateStateExport(export *StateExportSpec) error {
+	if export == nil {
+		return nil
+	}
+	fp := field.NewPath("spec.stateExport")
+	if err := v.validateUpload(&export.Upload, fp.Child("upload")); err != nil {
+		return err
+	}
+	if secret := export.SigningKeySecret; secret != nil && (len(secret.Name) == 0 || len(secret.Key) == 0) {
+		return fmt.Errorf("%v: name and key are required", fp.Child("signingKeySecret"))
+	}
+	return nil
+}
+
+func (v *Validator) validateLogging(logging *LoggingSpec) error {
+	if logging == nil || logging.Sidecar == nil {
+		return nil
+	}
+
+	var sidecar = logging.Sidecar
+	fp := field.NewPath("spec.logging.sidecar")
+	if len(sidecar.Template.Image) == 0 {
+		return fmt.Errorf("%v is required", fp.Child("template", "image"))
+	}
+	if name := sidecar.Template.Name; len(validation.NameIsDNS1035Label(name, false)) > 0 {
+		return fmt.Errorf("%v: invalid container name %q", fp.Child("template", "name"), name)
+	}
+	if secret := sidecar.OutputSecret; len(secret) > 0 && len(validation.NameIsDNSSubdomain(secret, false)) > 0 {
+		return fmt.Errorf("%v: invalid secret name %q", fp.Child("outputSecret"), secret)
+	}
+	return nil
+}
+
+// Names of the metrics reporters of spec.monitoring.reporters, which are part of Flink property keys.
+var metricsReporterNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func (v *Validator) validateMonitoring(flinkVersion *version.Version, cluster *FlinkCluster) error {
+	var monitoring = cluster.Spec.Monitoring
+	if tm := cluster.Spec.TaskManager; tm != nil && tm.HorizontalPodAutoscaler != nil &&
+		tm.HorizontalPodAutoscaler.TargetKafkaLagPerReplica != nil && (monitoring == nil || monitoring.KafkaLag == nil) {
+		return fmt.Errorf("spec.taskManager.horizontalPodAutoscaler.targetKafkaLagPerReplica requires spec.monitoring.kafkaLag")
+	}
+	if monitoring == nil {
+		return nil
+	}
+
+	fp := field.NewPath("spec.monitoring")
+	var names = map[string]bool{}
+	for i, reporter := range monitoring.Reporters {
+		var rp = fp.Child("reporters").Index(i)
+		if !metricsReporterNameRegexp.MatchString(reporter.Name) {
+			return fmt.Errorf("%v is invalid, must consist of alphanumeric characters, '-' or '_'", rp.Child("name"))
+		}
+		if names[reporter.Name] {
+			return fmt.Errorf("%v: duplicate reporter name %v", rp.Child("name"), reporter.Name)
+		}
+		names[reporter.Name] = true
+		var types []string
+		if reporter.Prometheus != nil {
+			types = append(types, "prometheus")
+		}
+		if reporter.Datadog != nil {
+			types = append(types, "datadog")
+		}
+		if reporter.StatsD != nil {
+			types = append(types, "statsd")
+		}
+		if reporter.Slf4j != nil {
+			types = append(types, "slf4j")
+		}
+		if len(types) != 1 {
+			return fmt.Errorf("%v: exactly one of prometheus, datadog, statsd and slf4j must be set", rp)
+		}
+		if reporter.Prometheus != nil && reporter.Interval != nil {
+			return fmt.Errorf("%v is not supported by Prometheus reporters, which are scraped", rp.Child("interval"))
+		}
+		if datadog := reporter.Datadog; datadog != nil {
+			if datadog.APIKeySecret.Name == "" || datadog.APIKeySecret.Key == "" {
+				return fmt.Errorf("%v: name and key are required", rp.Child("datadog", "apiKeySecret"))
+			}
+			if err := v.checkFlinkFeature(flinkVersion, flinkFeatureMetricsReporterSecrets); err != nil {
+				return fmt.Errorf("%v: %v", rp.Child("datadog", "apiKeySecret"), err)
+			}
+		}
+		if statsD := reporter.StatsD; statsD != nil {
+			if statsD.Host == "" {
+				return fmt.Errorf("%v is required", rp.Child("statsd", "host"))
+			}
+			if statsD.Port < 1 || statsD.Port > 65535 {
+				return fmt.Errorf("%v must be between 1 and 65535", rp.Child("statsd", "port"))
+			}
+		}
+	}
+
+	if kafkaLag := monitoring.KafkaLag; kafkaLag != nil {
+		var kp = fp.Child("kafkaLag")
+		if cluster.Spec.Job == nil {
+			return fmt.Errorf("%v is only allowed for job clusters", kp)
+		}
+		if len(strings.TrimSpace(kafkaLag.BootstrapServers)) == 0 {
+			return fmt.Errorf("%v is required", kp.Child("bootstrapServers"))
+		}
+		if len(kafkaLag.Group) == 0 {
+			return fmt.Errorf("%v is required", kp.Child("group"))
+		}
+		if kafkaLag.SecretRef != nil && len(kafkaLag.SecretRef.Name) == 0 {
+			return fmt.Errorf("%v is required", kp.Child("secretRef", "name"))
+		}
+		switch kafkaLag.SASLMechanism {
+		case "", "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512":
+		default:
+			return fmt.Errorf("%v: unsupported mechanism %v, must be one of PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512",
+				kp.Child("saslMechanism"), kafkaLag.SASLMechanism)
+		}
+		if kafkaLag.SASLMechanism != "" && kafkaLag.SecretRef == nil {
+			return fmt.Errorf("%v requires %v", kp.Child("saslMechanism"), kp.Child("secretRef"))
+		}
+	}
+
+	if cluster.HasPrometheusAnnotations() && len(cluster.GetPrometheusReporterPorts()) == 0 {
+		return fmt.Errorf("%v requires a Prometheus reporter in spec.monitoring.reporters or spec.flinkProperties, e.g. metrics.reporter.prom.factory.class: org.apache.flink.metrics.prometheus.PrometheusReporterFactory",
+			fp.Child("prometheusAnnotations"))
+	}
+	if !cluster.IsTaskManagerMetricsExposed() {
+		return nil
+	}
+	if len(cluster.GetPrometheusReporterPorts()) == 0 {
+		return fmt.Errorf("%v requires a Prometheus reporter in spec.monitoring.reporters or spec.flinkProperties, e.g. metrics.reporter.prom.factory.class: org.apache.flink.metrics.prometheus.PrometheusReporterFactory",
+			fp.Child("exposeTaskManagerMetrics"))
+	}
+	if cluster.Spec.TaskManager != nil {
+		for i, port := range cluster.Spec.TaskManager.ExtraPorts {
+			if port.Name == MetricsPortName {
+				return fmt.Errorf("%v: port name %v is reserved by %v",
+					field.NewPath("spec", "taskManager", "extraPorts").Index(i), port.Name, fp.Child("exposeTaskManagerMetrics"))
+			}
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateJobManager(flinkVersion *version.Version, jmSpec *JobManagerSpec) error {
+	var err error
+	if jmSpec == nil {
+		return nil
+	}
+
+	fp := field.NewPath("spec.jobManager")
+	if errors := validation.ValidateAnnotations(jmSpec.PodAnnotations, fp.Child("podAnnotations")); len(errors) > 0 {
+		return fmt.Errorf(errors.ToAggregate().Error())
+	}
+	if errors := v1validation.ValidateLabels(jmSpec.PodLabels, fp.Child("podLabels")); len(errors) > 0 {
+		return fmt.Errorf(errors.ToAggregate().Error())
+	}
+
+	if jmSpec.AccessScope == AccessScopeInternalLB && jmSpec.LoadBalancerProvider == "" {
+		return fmt.Errorf("%v is required when accessScope is %v", fp.Child("loadBalancerProvider"), AccessScopeInternalLB)
+	}
+
+	// Ports.
+	var ports = []NamedPort{
+		{Name: "rpc", ContainerPort: *jmSpec.Ports.RPC},
+		{Name: "blob", ContainerPort: *jmSpec.Ports.Blob},
+		{Name: "query", ContainerPort: *jmSpec.Ports.Query},
+		{Name: "ui", ContainerPort: *jmSpec.Ports.UI},
+	}
+	ports = append(ports, jmSpec.ExtraPorts...)
+	if jmSpec.Ingress != nil && jmSpec.Ingress.Auth != nil && jmSpec.Ingress.Auth.OAuth2Proxy != nil &&
+		jmSpec.Ingress.Auth.OAuth2Proxy.Port != nil {
+		ports = append(ports, NamedPort{Name: "oauth2-proxy", ContainerPort: *jmSpec.Ingress.Auth.OAuth2Proxy.Port})
+	}
+	if jmSpec.IsReadOnlyUI() {
+		ports = append(ports, NamedPort{Name: "ui-proxy", ContainerPort: ReadOnlyUIProxyPort})
+	}
+	err = v.checkDupPorts(ports, "jobmanager")
+	if err != nil {
+		return err
+	}
+	err = v.validateAppProtocols(jmSpec.Ports.AppProtocols, jmSpec.ExtraPorts, fp, "rpc", "blob", "query", "ui")
+	if err != nil {
+		return err
+	}
+
+	if jmSpec.Ingress != nil {
+		if err := v.validateIngressAuth(jmSpec.Ingress.Auth); err != nil {
+			return err
+		}
+	}
+	if err := v.validateExternalDNS(jmSpec, fp); err != nil {
+		return err
+	}
+
+	if err := v.validateResourceRequirements(jmSpec.Resources, "jobmanager"); err != nil {
+		return err
+	}
+
+	if flinkVersion == nil || flinkVersion.LessThan(v10) {
+		if jmSpec.MemoryProcessRatio != nil {
+			return fmt.Errorf("MemoryProcessRatio config cannot be used with flinkVersion < 1.11', use " +
+				"memoryOffHeapRatio instead")
+		}
+
+		// MemoryOffHeapRatio
+		err = v.validateRatio(jmSpec.MemoryOffHeapRatio, "jobmanager", "memoryOffHeapRatio")
+		if err != nil {
+			return err
+		}
+
+		// MemoryOffHeapMin
+		err = v.validateMemoryOffHeapMin(&jmSpec.MemoryOffHeapMin, jmSpec.GetResources().Memory(), "jobmanager")
+		if err != nil {
+			return err
+		}
+	} else {
+		if jmSpec.MemoryOffHeapRatio != nil || !jmSpec.MemoryOffHeapMin.IsZero() {
+			return fmt.Errorf("MemoryOffHeapRatio or MemoryOffHeapMin config cannot be used with flinkVersion >= 1.11'; " +
+				"use memoryProcessRatio istead")
+		}
+		// MemoryProcessRatio
+		err = v.validateRatio(jmSpec.MemoryProcessRatio, "jobmanager", "memoryProcessRatio")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *Validator) validateJobManagerStorage(clusterSpec *FlinkClusterSpec) error {
+	if clusterSpec.JobManager == nil || clusterSpec.JobManager.Storage == nil {
+		return nil
+	}
+	var storage = clusterSpec.JobManager.Storage
+	var fp = field.NewPath("spec", "jobManager", "storage")
+	if clusterSpec.Job != nil && clusterSpec.Job.Mode != nil && *clusterSpec.Job.Mode == JobModeApplication {
+		return fmt.Errorf("%v is not supported in application mode", fp)
+	}
+	var found bool
+	for _, pvc := range clusterSpec.JobManager.VolumeClaimTemplates {
+		found = found || pvc.Name == storage.VolumeClaimTemplate
+	}
+	if !found {
+		return fmt.Errorf("%v: %q is not an entry of spec.jobManager.volumeClaimTemplates",
+			fp.Child("volumeClaimTemplate"), storage.VolumeClaimTemplate)
+	}
+	switch storage.GetRetentionPolicy() {
+	case StorageRetentionPolicyDelete, StorageRetentionPolicyRetain:
+	default:
+		return fmt.Errorf("invalid %v: %v", fp.Child("retentionPolicy"), *storage.RetentionPolicy)
+	}
+	if _, ok := clusterSpec.FlinkProperties["web.upload.dir"]; ok {
+		return fmt.Errorf("%v cannot be used with %v", fp, field.NewPath("spec", "flinkProperties").Key("web.upload.dir"))
+	}
+	return nil
+}
+
+func (v *Validator) validateTaskManager(flinkVersion *version.Version, tmSpec *TaskManagerSpec) error {
+	if tmSpec == nil {
+		return nil
+	}
+
+	fp := field.NewPath("spec.taskManager")
+	if errors := validation.ValidateAnnotations(tmSpec.PodAnnotations, fp.Child("podAnnotations")); len(errors) > 0 {
+		return fmt.Errorf(errors.ToAggregate().Error())
+	}
+	if errors := v1validation.ValidateLabels(tmSpec.PodLabels, fp.Child("podLabels")); len(errors) > 0 {
+		return fmt.Errorf(errors.ToAggregate().Error())
+	}
+
+	// Ports.
+	var err error
+	var ports = []NamedPort{
+		{Name: "rpc", ContainerPort: *tmSpec.Ports.RPC},
+		{Name: "data", ContainerPort: *tmSpec.Ports.Data},
+		{Name: "query", ContainerPort: *tmSpec.Ports.Query},
+	}
+	ports = append(ports, tmSpec.ExtraPorts...)
+	err = v.checkDupPorts(ports, "taskmanager")
+	if err != nil {
+		return err
+	}
+	err = v.validateAppProtocols(tmSpec.Ports.AppProtocols, tmSpec.ExtraPorts, fp, "data", "rpc", "query")
+	if err != nil {
+		return err
+	}
+
+	if err := v.validateResourceRequirements(tmSpec.Resources, "taskmanager"); err != nil {
+		return err
+	}
+
+	if err := v.validateTaskManagerArtifacts(tmSpec, fp); err != nil {
+		return err
+	}
+
+	if flinkVersion == nil || flinkVersion.LessThan(v10) {
+		if tmSpec.MemoryProcessRatio != nil {
+			return fmt.Errorf("MemoryProcessRatio config cannot be used with flinkVersion < 1.11', use " +
+				"memoryOffHeapRatio instead")
+		}
+
 		// MemoryOffHeapRatio
 		err = v.validateRatio(tmSpec.MemoryOffHeapRatio, "taskmanager", "memoryOffHeapRatio")
 		if err != nil {
 			return err
 		}
 
-		// MemoryOffHeapMin
-		err = v.validateMemoryOffHeapMin(&tmSpec.MemoryOffHeapMin, tmSpec.GetResources().Memory(), "taskmanager")
-		if err != nil {
-			return err
+		// MemoryOffHeapMin
+		err = v.validateMemoryOffHeapMin(&tmSpec.MemoryOffHeapMin, tmSpec.GetResources().Memory(), "taskmanager")
+		if err != nil {
+			return err
+		}
+	} else {
+		if tmSpec.MemoryOffHeapRatio != nil || !tmSpec.MemoryOffHeapMin.IsZero() {
+			return fmt.Errorf("MemoryOffHeapRatio or MemoryOffHeapMin config cannot be used with flinkVersion >= 1.11'; " +
+				"use memoryProcessRatio istead")
+		}
+		// MemoryProcessRatio
+		err = v.validateRatio(tmSpec.MemoryProcessRatio, "taskmanager", "memoryProcessRatio")
+		if err != nil {
+			return err
+		}
+	}
+
+	if tmSpec.External != nil && *tmSpec.External && tmSpec.HorizontalPodAutoscaler != nil {
+		return fmt.Errorf("%v cannot be used with %v", fp.Child("horizontalPodAutoscaler"), fp.Child("external"))
+	}
+
+	if watchdog := tmSpec.RegistrationWatchdog; watchdog != nil {
+		if tmSpec.External != nil && *tmSpec.External {
+			return fmt.Errorf("%v cannot be used with %v", fp.Child("registrationWatchdog"), fp.Child("external"))
+		}
+		if watchdog.DeadlineSeconds < 1 {
+			return fmt.Errorf("%v must be >= 1", fp.Child("registrationWatchdog", "deadlineSeconds"))
+		}
+	}
+
+	if tmSpec.SlotResources != nil {
+		if err := v.checkFlinkFeature(flinkVersion, flinkFeatureFineGrainedResourceManagement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Checks the feature is supported by the Flink version.
+func (v *Validator) checkFlinkFeature(flinkVersion *version.Version, feature string) error {
+	var minVersion = flinkFeatureVersions[feature]
+	if flinkVersion == nil || flinkVersion.LessThan(minVersion) {
+		return fmt.Errorf("%v requires flinkVersion >= %v", feature, minVersion)
+	}
+	return nil
+}
+
+// Validates that a task slot fits in the TaskManager resources.
+// validateSlotResources checks that the slots of a taskmanager, of the given resources each,
+// fit in the resources of the taskmanager.
+func (v *Validator) validateSlotResources(cluster *FlinkCluster) error {
+	var tmSpec = cluster.Spec.TaskManager
+	if tmSpec == nil || tmSpec.SlotResources == nil {
+		return nil
+	}
+	var slot = tmSpec.SlotResources
+	fp := field.NewPath("spec.taskManager.slotResources")
+	if slot.CPUCores.Sign() <= 0 {
+		return fmt.Errorf("%v must be > 0", fp.Child("cpuCores"))
+	}
+	if slot.TaskHeapMemory.Sign() <= 0 {
+		return fmt.Errorf("%v must be > 0", fp.Child("taskHeapMemory"))
+	}
+	if slot.TaskOffHeapMemory.Sign() < 0 || slot.ManagedMemory.Sign() < 0 {
+		return fmt.Errorf("%v: memory must be >= 0", fp)
+	}
+
+	slots, err := cluster.GetTaskSlots()
+	if err != nil {
+		return fmt.Errorf("invalid taskmanager slots: %v", err)
+	}
+	var resources = tmSpec.GetResources()
+	var slotsCPU = resource.NewMilliQuantity(slot.CPUCores.MilliValue()*int64(slots), resource.DecimalSI)
+	if cpu := resources.Cpu(); !cpu.IsZero() && slotsCPU.Cmp(*cpu) > 0 {
+		return fmt.Errorf("%v %v of %v slots exceeds the taskmanager cpu %v",
+			fp.Child("cpuCores"), slot.CPUCores.String(), slots, cpu.String())
+	}
+	var slotMemory = slot.TaskHeapMemory.DeepCopy()
+	slotMemory.Add(slot.TaskOffHeapMemory)
+	slotMemory.Add(slot.ManagedMemory)
+	if memory := resources.Memory(); !memory.IsZero() && tmSpec.MemoryProcessRatio != nil {
+		var processMemory = memory.Value() * int64(*tmSpec.MemoryProcessRatio) / 100
+		if slotMemory.Value()*int64(slots) >= processMemory {
+			return fmt.Errorf("%v: memory of %v slots of %v exceeds the taskmanager process memory %v",
+				fp, slots, slotMemory.String(), resource.NewQuantity(processMemory, resource.BinarySI).String())
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateJob(jobSpec *JobSpec) error {
+	if jobSpec == nil {
+		return nil
+	}
+
+	fp := field.NewPath("spec.job")
+	if errors := validation.ValidateAnnotations(jobSpec.PodAnnotations, fp.Child("podAnnotations")); len(errors) > 0 {
+		return fmt.Errorf(errors.ToAggregate().Error())
+	}
+	if errors := v1validation.ValidateLabels(jobSpec.PodLabels, fp.Child("podLabels")); len(errors) > 0 {
+		return fmt.Errorf(errors.ToAggregate().Error())
+	}
+
+	applicationMode := jobSpec.Mode != nil && *jobSpec.Mode == JobModeApplication
+	if !applicationMode && jobSpec.JarFile == nil && jobSpec.PyFile == nil && jobSpec.PyModule == nil {
+		return fmt.Errorf("job jarFile or pythonFile or pythonModule is unspecified")
+	}
+
+	if jobSpec.Parallelism != nil && *jobSpec.Parallelism < 1 {
+		return fmt.Errorf("job parallelism must be >= 1")
+	}
+
+	for i, source := range jobSpec.ArgsFrom {
+		if err := v.validateJobArgSource(source, fp.Child("argsFrom").Index(i)); err != nil {
+			return err
+		}
+	}
+
+	if jobSpec.JarFile != nil && IsMavenArtifact(*jobSpec.JarFile) {
+		if applicationMode {
+			return fmt.Errorf("%v: Maven coordinates are not supported in application mode", fp.Child("jarFile"))
+		}
+		if _, err := GetMavenArtifactURL(jobSpec.MavenRepository, *jobSpec.JarFile); err != nil {
+			return fmt.Errorf("%v: %v", fp.Child("jarFile"), err)
+		}
+	}
+	if len(jobSpec.JarFileSHA256) != 0 {
+		var jarFile = ""
+		if jobSpec.JarFile != nil {
+			jarFile = *jobSpec.JarFile
+		}
+		var fetched = IsMavenArtifact(jarFile) || (jobSpec.ArtifactCache != nil &&
+			(strings.HasPrefix(jarFile, "http://") || strings.HasPrefix(jarFile, "https://")))
+		if !fetched {
+			return fmt.Errorf("%v: requires Maven coordinates or a remote jarFile with artifactCache", fp.Child("jarFileSHA256"))
+		}
+		if _, err := hex.DecodeString(jobSpec.JarFileSHA256); err != nil || len(jobSpec.JarFileSHA256) != sha256.Size*2 {
+			return fmt.Errorf("%v must be %v hex digits", fp.Child("jarFileSHA256"), sha256.Size*2)
+		}
+	}
+	if repository := jobSpec.MavenRepository; repository != nil {
+		if repository.URL != nil && !(strings.HasPrefix(*repository.URL, "http://") || strings.HasPrefix(*repository.URL, "https://")) {
+			return fmt.Errorf("%v: must be an http:// or https:// URL", fp.Child("mavenRepository", "url"))
+		}
+		if repository.SecretRef != nil && len(repository.SecretRef.Name) == 0 {
+			return fmt.Errorf("%v is unspecified", fp.Child("mavenRepository", "secretRef", "name"))
+		}
+	}
+
+	if cache := jobSpec.ArtifactCache; cache != nil {
+		switch {
+		case cache.PersistentVolumeClaim != nil && cache.HostPath != nil:
+			return fmt.Errorf("%v: only one of persistentVolumeClaim or hostPath can be specified", fp.Child("artifactCache"))
+		case cache.PersistentVolumeClaim != nil:
+			if len(cache.PersistentVolumeClaim.ClaimName) == 0 {
+				return fmt.Errorf("%v: claimName is unspecified", fp.Child("artifactCache", "persistentVolumeClaim"))
+			}
+		case cache.HostPath != nil:
+			if !strings.HasPrefix(*cache.HostPath, "/") {
+				return fmt.Errorf("%v: must be an absolute path", fp.Child("artifactCache", "hostPath"))
+			}
+		default:
+			return fmt.Errorf("%v: one of persistentVolumeClaim or hostPath must be specified", fp.Child("artifactCache"))
+		}
+	}
+
+	if gitRepo := jobSpec.GitRepo; gitRepo != nil {
+		switch {
+		case applicationMode:
+			return fmt.Errorf("%v: not supported in application mode", fp.Child("gitRepo"))
+		case len(gitRepo.URL) == 0:
+			return fmt.Errorf("%v is unspecified", fp.Child("gitRepo", "url"))
+		case gitRepo.Path != nil && (path.IsAbs(*gitRepo.Path) || strings.HasPrefix(path.Clean(*gitRepo.Path), "..")):
+			return fmt.Errorf("%v: must be a relative path within the repository", fp.Child("gitRepo", "path"))
+		case gitRepo.SecretRef != nil && len(gitRepo.SecretRef.Name) == 0:
+			return fmt.Errorf("%v is unspecified", fp.Child("gitRepo", "secretRef", "name"))
+		}
+	}
+
+	switch *jobSpec.RestartPolicy {
+	case JobRestartPolicyNever:
+	case JobRestartPolicyFromSavepointOnFailure:
+		if jobSpec.MaxStateAgeToRestoreSeconds == nil {
+			return fmt.Errorf("maxStateAgeToRestoreSeconds must be specified when restartPolicy is set as FromSavepointOnFailure")
+		}
+	default:
+		return fmt.Errorf("invalid job restartPolicy: %v", *jobSpec.RestartPolicy)
+	}
+	if limit := jobSpec.RunHistoryLimit; limit != nil && *limit < 0 {
+		return fmt.Errorf("%v must be >= 0", fp.Child("runHistoryLimit"))
+	}
+
+	if policy := jobSpec.SuccessPolicy; policy != nil {
+		switch policy.Type {
+		case JobSuccessPolicyAccumulator:
+			if policy.Accumulator == nil || len(policy.Accumulator.Name) == 0 {
+				return fmt.Errorf("%v is required when type is Accumulator", fp.Child("successPolicy", "accumulator", "name"))
+			}
+		case "", JobSuccessPolicyFinished, JobSuccessPolicyFinishedOrCancelled:
+			if policy.Accumulator != nil {
+				return fmt.Errorf("%v is only applicable when type is Accumulator", fp.Child("successPolicy", "accumulator"))
+			}
+		default:
+			return fmt.Errorf("invalid %v: %v", fp.Child("successPolicy", "type"), policy.Type)
+		}
+	}
+
+	if slo := jobSpec.SLO; slo != nil {
+		sp := fp.Child("slo")
+		if slo.MaxCheckpointAgeSeconds == nil && slo.MaxRestartRatePerHour == nil && slo.MaxConsumerLag == nil {
+			return fmt.Errorf("%v: at least one of maxCheckpointAgeSeconds, maxRestartRatePerHour and maxConsumerLag must be set", sp)
 		}
-	} else {
-		if tmSpec.MemoryOffHeapRatio != nil || !tmSpec.MemoryOffHeapMin.IsZero() {
-			return fmt.Errorf("MemoryOffHeapRatio or MemoryOffHeapMin config cannot be used with flinkVersion >= 1.11'; " +
-				"use memoryProcessRatio istead")
+		if slo.MaxCheckpointAgeSeconds != nil && *slo.MaxCheckpointAgeSeconds < 1 {
+			return fmt.Errorf("%v must be >= 1", sp.Child("maxCheckpointAgeSeconds"))
 		}
-		// MemoryProcessRatio
-		err = v.validateRatio(tmSpec.MemoryProcessRatio, "taskmanager", "memoryProcessRatio")
-		if err != nil {
-			return err
+		if slo.MaxRestartRatePerHour != nil && *slo.MaxRestartRatePerHour < 0 {
+			return fmt.Errorf("%v must be >= 0", sp.Child("maxRestartRatePerHour"))
+		}
+		if lag := slo.MaxConsumerLag; lag != nil {
+			if len(lag.Metric) == 0 {
+				return fmt.Errorf("%v is required", sp.Child("maxConsumerLag", "metric"))
+			}
+			if lag.Max < 0 {
+				return fmt.Errorf("%v must be >= 0", sp.Child("maxConsumerLag", "max"))
+			}
+			switch lag.Aggregation {
+			case "", ConsumerLagAggregationSum, ConsumerLagAggregationMax:
+			default:
+				return fmt.Errorf("invalid %v: %v", sp.Child("maxConsumerLag", "aggregation"), lag.Aggregation)
+			}
 		}
 	}
 
-	return nil
-}
-
-func (v *Validator) validateJob(jobSpec *JobSpec) error {
-	if jobSpec == nil {
-		return nil
+	if policy := jobSpec.AdaptiveSavepoint; policy != nil {
+		ap := fp.Child("adaptiveSavepoint")
+		if policy.StateDeltaThreshold == nil && (policy.BeforeNodeDrain == nil || !*policy.BeforeNodeDrain) {
+			return fmt.Errorf("%v: at least one of stateDeltaThreshold and beforeNodeDrain must be set", ap)
+		}
+		if policy.StateDeltaThreshold != nil && policy.StateDeltaThreshold.Sign() <= 0 {
+			return fmt.Errorf("%v must be > 0", ap.Child("stateDeltaThreshold"))
+		}
+		if policy.MinIntervalSeconds < 0 {
+			return fmt.Errorf("%v must be >= 0", ap.Child("minIntervalSeconds"))
+		}
+		if jobSpec.SavepointsDir == nil || *jobSpec.SavepointsDir == "" {
+			return fmt.Errorf("%v is required when %v is set", fp.Child("savepointsDir"), ap)
+		}
 	}
 
-	fp := field.NewPath("spec.job")
-	if errors := validation.ValidateAnnotations(jobSpec.PodAnnotations, fp.Child("podAnnotations")); len(errors) > 0 {
-		return fmt.Errorf(errors.ToAggregate().Error())
+	if policy := jobSpec.CleanupPolicy; policy != nil {
+		cp := fp.Child("cleanupPolicy")
+		if policy.TTLSecondsAfterFinished != nil && *policy.TTLSecondsAfterFinished < 0 {
+			return fmt.Errorf("%v must be >= 0", cp.Child("ttlSecondsAfterFinished"))
+		}
+		if keep := policy.KeepLastFinished; keep != nil {
+			if len(keep.GroupLabel) == 0 {
+				return fmt.Errorf("%v is required", cp.Child("keepLastFinished", "groupLabel"))
+			}
+			if keep.Count < 1 {
+				return fmt.Errorf("%v must be >= 1", cp.Child("keepLastFinished", "count"))
+			}
+		}
 	}
-	if errors := v1validation.ValidateLabels(jobSpec.PodLabels, fp.Child("podLabels")); len(errors) > 0 {
-		return fmt.Errorf(errors.ToAggregate().Error())
+
+	if ownership := jobSpec.SavepointOwnership; ownership != nil {
+		op := fp.Child("savepointOwnership")
+		if jobSpec.SavepointsDir == nil || *jobSpec.SavepointsDir == "" {
+			return fmt.Errorf("%v is required when %v is set", fp.Child("savepointsDir"), op)
+		}
+		u, err := url.Parse(*jobSpec.SavepointsDir)
+		if err != nil || len(u.Host) == 0 {
+			return fmt.Errorf("%v: invalid %v: %v", op, fp.Child("savepointsDir"), *jobSpec.SavepointsDir)
+		}
+		switch u.Scheme {
+		case "http", "https", "gs", "s3", "s3a", "s3p":
+		default:
+			return fmt.Errorf("%v: unsupported scheme %q of %v, must be one of http, https, gs, s3, s3a or s3p", op, u.Scheme, fp.Child("savepointsDir"))
+		}
+		if secret := ownership.AuthorizationSecret; secret != nil && (len(secret.Name) == 0 || len(secret.Key) == 0) {
+			return fmt.Errorf("%v: name and key are required", op.Child("authorizationSecret"))
+		}
 	}
 
-	applicationMode := jobSpec.Mode != nil && *jobSpec.Mode == JobModeApplication
-	if !applicationMode && jobSpec.JarFile == nil && jobSpec.PyFile == nil && jobSpec.PyModule == nil {
-		return fmt.Errorf("job jarFile or pythonFile or pythonModule is unspecified")
+	if probe := jobSpec.StorageProbe; probe != nil {
+		pp := fp.Child("storageProbe")
+		if isBlank(jobSpec.SavepointsDir) && isBlank(jobSpec.FromSavepoint) {
+			return fmt.Errorf("%v: %v or %v is required", pp, fp.Child("savepointsDir"), fp.Child("fromSavepoint"))
+		}
+		if secret := probe.AuthorizationSecret; secret != nil && (len(secret.Name) == 0 || len(secret.Key) == 0) {
+			return fmt.Errorf("%v: name and key are required", pp.Child("authorizationSecret"))
+		}
 	}
 
-	if jobSpec.Parallelism != nil && *jobSpec.Parallelism < 1 {
-		return fmt.Errorf("job parallelism must be >= 1")
+	if archive := jobSpec.PlanArchive; archive != nil && archive.Upload != nil {
+		if err := v.validateUpload(archive.Upload, fp.Child("planArchive", "upload")); err != nil {
+			return err
+		}
 	}
 
-	switch *jobSpec.RestartPolicy {
-	case JobRestartPolicyNever:
-	case JobRestartPolicyFromSavepointOnFailure:
-		if jobSpec.MaxStateAgeToRestoreSeconds == nil {
-			return fmt.Errorf("maxStateAgeToRestoreSeconds must be specified when restartPolicy is set as FromSavepointOnFailure")
+	var gateNames = map[string]bool{}
+	for i, gate := range jobSpec.ReadinessGates {
+		gp := fp.Child("readinessGates").Index(i)
+		if len(gate.Name) == 0 {
+			return fmt.Errorf("%v is required", gp.Child("name"))
+		}
+		if gateNames[gate.Name] {
+			return fmt.Errorf("duplicate %v: %v", gp.Child("name"), gate.Name)
+		}
+		gateNames[gate.Name] = true
+		var checks = 0
+		if gate.HTTPGet != nil {
+			checks++
+			if u, err := url.Parse(gate.HTTPGet.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("%v must be a http:// or https:// URL: %v", gp.Child("httpGet", "url"), gate.HTTPGet.URL)
+			}
+			if t := gate.HTTPGet.TimeoutSeconds; t != nil && (*t < 1 || *t > 10) {
+				return fmt.Errorf("%v must be between 1 and 10", gp.Child("httpGet", "timeoutSeconds"))
+			}
+		}
+		if gate.KafkaTopic != nil {
+			checks++
+			if len(gate.KafkaTopic.BootstrapServers) == 0 {
+				return fmt.Errorf("%v is required", gp.Child("kafkaTopic", "bootstrapServers"))
+			}
+			if len(gate.KafkaTopic.Topic) == 0 {
+				return fmt.Errorf("%v is required", gp.Child("kafkaTopic", "topic"))
+			}
+		}
+		if gate.ConfigMapKey != nil {
+			checks++
+			if len(gate.ConfigMapKey.Name) == 0 {
+				return fmt.Errorf("%v is required", gp.Child("configMapKey", "name"))
+			}
+			if len(gate.ConfigMapKey.Key) == 0 {
+				return fmt.Errorf("%v is required", gp.Child("configMapKey", "key"))
+			}
+		}
+		if checks != 1 {
+			return fmt.Errorf("%v: exactly one of httpGet, kafkaTopic and configMapKey must be set", gp)
 		}
-	default:
-		return fmt.Errorf("invalid job restartPolicy: %v", *jobSpec.RestartPolicy)
 	}
 
 	if jobSpec.TakeSavepointOnUpdate != nil && !*jobSpec.TakeSavepointOnUpdate &&
@@ -496,6 +1863,163 @@ func (v *Validator) validateJob(jobSpec *JobSpec) error {
 	return nil
 }
 
+// Validates that spec.job.updateStopMode agrees with takeSavepointOnUpdate and is supported
+// by the Flink version.
+// validateJobArgSource checks an entry of spec.job.argsFrom. Optional references are
+// rejected, as Kubernetes keeps the $(VAR) placeholder of a missing key in the arguments.
+func (v *Validator) validateJobArgSource(source JobArgSource, fp *field.Path) error {
+	var count = 0
+	for _, set := range []bool{source.Value != nil, source.ConfigMapKeyRef != nil, source.SecretKeyRef != nil} {
+		if set {
+			count++
+		}
+	}
+	if count != 1 {
+		return fmt.Errorf("%v: exactly one of value, configMapKeyRef or secretKeyRef must be specified", fp)
+	}
+	if ref := source.ConfigMapKeyRef; ref != nil {
+		if len(ref.Name) == 0 || len(ref.Key) == 0 {
+			return fmt.Errorf("%v: name and key are required", fp.Child("configMapKeyRef"))
+		}
+		if ref.Optional != nil && *ref.Optional {
+			return fmt.Errorf("%v: optional references are not supported", fp.Child("configMapKeyRef"))
+		}
+	}
+	if ref := source.SecretKeyRef; ref != nil {
+		if len(ref.Name) == 0 || len(ref.Key) == 0 {
+			return fmt.Errorf("%v: name and key are required", fp.Child("secretKeyRef"))
+		}
+		if ref.Optional != nil && *ref.Optional {
+			return fmt.Errorf("%v: optional references are not supported", fp.Child("secretKeyRef"))
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateUpdateStopMode(flinkVersion *version.Version, jobSpec *JobSpec) error {
+	if jobSpec == nil || jobSpec.UpdateStopMode == nil {
+		return nil
+	}
+	var takeSavepointOnUpdate = jobSpec.TakeSavepointOnUpdate == nil || *jobSpec.TakeSavepointOnUpdate
+	switch mode := *jobSpec.UpdateStopMode; mode {
+	case JobUpdateStopModeStopWithSavepoint:
+		if err := v.checkFlinkFeature(flinkVersion, flinkFeatureStopWithSavepoint); err != nil {
+			return fmt.Errorf("spec.job.updateStopMode %v: %v", mode, err)
+		}
+		fallthrough
+	case JobUpdateStopModeCancelWithSavepoint:
+		if !takeSavepointOnUpdate {
+			return fmt.Errorf("spec.job.updateStopMode %v cannot be used when takeSavepointOnUpdate is false", mode)
+		}
+	case JobUpdateStopModeCancel:
+		if takeSavepointOnUpdate {
+			return fmt.Errorf("spec.job.updateStopMode %v requires takeSavepointOnUpdate to be false", mode)
+		}
+	default:
+		return fmt.Errorf("invalid spec.job.updateStopMode %v", mode)
+	}
+	return nil
+}
+
+func (v *Validator) validateSavepointTriggerMode(jobSpec *JobSpec) error {
+	if jobSpec == nil || jobSpec.SavepointTriggerMode == nil {
+		return nil
+	}
+	switch mode := *jobSpec.SavepointTriggerMode; mode {
+	case SavepointTriggerModeAuto, SavepointTriggerModeREST, SavepointTriggerModeJob:
+		return nil
+	default:
+		return fmt.Errorf("invalid spec.job.savepointTriggerMode %v, must be %v, %v or %v",
+			mode, SavepointTriggerModeAuto, SavepointTriggerModeREST, SavepointTriggerModeJob)
+	}
+}
+
+// Validates that the number of task slots is set in one place only, by taskSlots, slotsPerCPU
+// or the Flink property.
+func (v *Validator) validateTaskSlots(clusterSpec *FlinkClusterSpec) error {
+	var tmSpec = clusterSpec.TaskManager
+	if tmSpec == nil {
+		return nil
+	}
+	var fp = field.NewPath("spec", "taskManager")
+	var propertyPath = field.NewPath("spec", "flinkProperties").Key("taskmanager.numberOfTaskSlots")
+	var _, propertySet = clusterSpec.FlinkProperties["taskmanager.numberOfTaskSlots"]
+	switch {
+	case tmSpec.TaskSlots != nil && tmSpec.SlotsPerCPU != nil:
+		return fmt.Errorf("%v cannot be used with %v", fp.Child("taskSlots"), fp.Child("slotsPerCPU"))
+	case tmSpec.TaskSlots != nil:
+		if *tmSpec.TaskSlots < 1 {
+			return fmt.Errorf("%v must be >= 1", fp.Child("taskSlots"))
+		}
+		if propertySet {
+			return fmt.Errorf("%v cannot be used with %v", fp.Child("taskSlots"), propertyPath)
+		}
+	case tmSpec.SlotsPerCPU != nil:
+		if tmSpec.SlotsPerCPU.Sign() <= 0 {
+			return fmt.Errorf("%v must be > 0", fp.Child("slotsPerCPU"))
+		}
+		if propertySet {
+			return fmt.Errorf("%v cannot be used with %v", fp.Child("slotsPerCPU"), propertyPath)
+		}
+		if tmSpec.GetResources().Cpu().IsZero() {
+			return fmt.Errorf("%v requires the cpu of %v", fp.Child("slotsPerCPU"), fp.Child("resources"))
+		}
+	}
+	return nil
+}
+
+// Validates that spec.job.parallelism does not exceed the task slots of the cluster, as the
+// job would otherwise fail to be scheduled with NoResourceAvailableException. The check is
+// skipped for external TaskManagers and in reactive mode, where the parallelism is adapted to
+// the slots. The adaptive scheduler runs the job with the slots available, so it is only
+// logged.
+func (v *Validator) validateJobParallelism(cluster *FlinkCluster) error {
+	var jobSpec = cluster.Spec.Job
+	var tmSpec = cluster.Spec.TaskManager
+	if jobSpec == nil || jobSpec.Parallelism == nil || tmSpec == nil || tmSpec.Replicas == nil ||
+		(tmSpec.External != nil && *tmSpec.External) ||
+		cluster.Spec.FlinkProperties["scheduler-mode"] == "reactive" {
+		return nil
+	}
+
+	slots, err := cluster.GetTaskSlots()
+	if err != nil || slots < 1 {
+		return fmt.Errorf("invalid spec.flinkProperties taskmanager.numberOfTaskSlots %q, must be an integer >= 1",
+			cluster.Spec.FlinkProperties["taskmanager.numberOfTaskSlots"])
+	}
+	var replicas = *tmSpec.Replicas
+	var replicasField = "spec.taskManager.replicas"
+	if hpa := tmSpec.HorizontalPodAutoscaler; hpa != nil {
+		replicas = hpa.MaxReplicas
+		replicasField = "spec.taskManager.horizontalPodAutoscaler.maxReplicas"
+	}
+	var parallelism = *jobSpec.Parallelism
+	if parallelism <= replicas*slots {
+		return nil
+	}
+
+	var recommendations = []string{
+		fmt.Sprintf("%v to at least %v", replicasField, (parallelism+slots-1)/slots),
+	}
+	if replicas > 0 {
+		var slotsField = "spec.taskManager.taskSlots"
+		if _, ok := cluster.Spec.FlinkProperties["taskmanager.numberOfTaskSlots"]; ok {
+			slotsField = "taskmanager.numberOfTaskSlots"
+		}
+		recommendations = append(recommendations, fmt.Sprintf(
+			"%v to at least %v", slotsField, (parallelism+replicas-1)/replicas))
+	}
+	err = fmt.Errorf("spec.job.parallelism %v exceeds the %v task slots of the cluster (%v TaskManagers x %v slots), "+
+		"set %v or spec.job.parallelism to at most %v",
+		parallelism, replicas*slots, replicas, slots, strings.Join(recommendations, ", "), replicas*slots)
+	if cluster.Spec.FlinkProperties["jobmanager.scheduler"] == "adaptive" {
+		log.Info("Job parallelism exceeds the task slots", "name", cluster.Name, "namespace", cluster.Namespace,
+			"reason", err.Error())
+		return nil
+	}
+	return err
+}
+
 func (v *Validator) validateResourceRequirements(rr corev1.ResourceRequirements, component string) error {
 	memoryNotSet := true
 	cpuNotSet := true
@@ -525,6 +2049,68 @@ func (v *Validator) validateResourceRequirements(rr corev1.ResourceRequirements,
 	return nil
 }
 
+// The cluster name placeholder of the host formats, replaced by the name of the cluster.
+var clusterNamePlaceholderRegexp = regexp.MustCompile(`{{\s*[$]clusterName\s*}}`)
+
+func (v *Validator) validateExternalDNS(jmSpec *JobManagerSpec, fp *field.Path) error {
+	var externalDNS = jmSpec.ExternalDNS
+	if externalDNS == nil {
+		return nil
+	}
+	var dnsPath = fp.Child("externalDNS")
+	if externalDNS.Hostname == "" {
+		return fmt.Errorf("%v is unspecified", dnsPath.Child("hostname"))
+	}
+	var hostname = clusterNamePlaceholderRegexp.ReplaceAllString(externalDNS.Hostname, "cluster")
+	if errs := utilvalidation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+		return fmt.Errorf("invalid %v %q: %v", dnsPath.Child("hostname"), externalDNS.Hostname, strings.Join(errs, ", "))
+	}
+	if externalDNS.TTL != nil && *externalDNS.TTL < 1 {
+		return fmt.Errorf("%v must be >= 1", dnsPath.Child("ttl"))
+	}
+	// external-dns publishes ClusterIP services only with --publish-internal-services.
+	if jmSpec.Ingress == nil && jmSpec.AccessScope == AccessScopeCluster {
+		return fmt.Errorf("%v requires %v or an accessScope other than %v",
+			dnsPath, fp.Child("ingress"), AccessScopeCluster)
+	}
+	return nil
+}
+
+// validateIngressAuth checks that at most one of oauth2-proxy and external authentication
+// protects the ingress, and that the one set is complete.
+func (v *Validator) validateIngressAuth(auth *JobManagerIngressAuthSpec) error {
+	if auth == nil {
+		return nil
+	}
+
+	fp := field.NewPath("spec.jobManager.ingress.auth")
+	if auth.OAuth2Proxy != nil && auth.ExternalAuth != nil {
+		return fmt.Errorf("%v: only one of oauth2Proxy or externalAuth can be specified", fp)
+	}
+	if proxy := auth.OAuth2Proxy; proxy != nil {
+		if len(proxy.Provider) == 0 {
+			return fmt.Errorf("%v: provider is unspecified", fp.Child("oauth2Proxy"))
+		}
+		var secretRefs = []struct {
+			name string
+			ref  corev1.SecretKeySelector
+		}{
+			{"clientIDSecretRef", proxy.ClientIDSecretRef},
+			{"clientSecretSecretRef", proxy.ClientSecretSecretRef},
+			{"cookieSecretSecretRef", proxy.CookieSecretSecretRef},
+		}
+		for _, secretRef := range secretRefs {
+			if len(secretRef.ref.Name) == 0 || len(secretRef.ref.Key) == 0 {
+				return fmt.Errorf("%v: name and key must be specified", fp.Child("oauth2Proxy", secretRef.name))
+			}
+		}
+	}
+	if auth.ExternalAuth != nil && len(auth.ExternalAuth.URL) == 0 {
+		return fmt.Errorf("%v: url is unspecified", fp.Child("externalAuth"))
+	}
+	return nil
+}
+
 // Check duplicate name and number in NamedPort array.
 func (v *Validator) checkDupPorts(ports []NamedPort, component string) error {
 	if len(ports) == 0 {
@@ -547,12 +2133,42 @@ func (v *Validator) checkDupPorts(ports []NamedPort, component string) error {
 	return nil
 }
 
+// validateAppProtocols checks the application protocols of the ports and extra ports in the
+// service of the component.
+func (v *Validator) validateAppProtocols(appProtocols map[string]string, extraPorts []NamedPort, fp *field.Path, portNames ...string) error {
+	for name, appProtocol := range appProtocols {
+		var known = false
+		for _, portName := range portNames {
+			known = known || name == portName
+		}
+		if !known {
+			return fmt.Errorf("invalid %v %v, must be one of %v", fp.Child("ports", "appProtocols"), name, strings.Join(portNames, ", "))
+		}
+		if appProtocol == "" {
+			return fmt.Errorf("%v is empty", fp.Child("ports", "appProtocols").Key(name))
+		}
+	}
+	for i, port := range extraPorts {
+		if port.AppProtocol == nil {
+			continue
+		}
+		if port.Name == "" {
+			return fmt.Errorf("%v is required when appProtocol is set", fp.Child("extraPorts").Index(i).Child("name"))
+		}
+		if *port.AppProtocol == "" {
+			return fmt.Errorf("%v is empty", fp.Child("extraPorts").Index(i).Child("appProtocol"))
+		}
+	}
+	return nil
+}
+
 func (v *Validator) validateCleanupAction(
 	property string, value CleanupAction) error {
 	switch value {
 	case CleanupActionDeleteCluster:
 	case CleanupActionDeleteTaskManager:
 	case CleanupActionKeepCluster:
+	case CleanupActionKeepJobManagerOnly:
 	default:
 		return fmt.Errorf(
 			"invalid %v: %v",
@@ -561,6 +2177,82 @@ func (v *Validator) validateCleanupAction(
 	return nil
 }
 
+func (v *Validator) validateDeletionPolicy(value DeletionPolicy) error {
+	switch value {
+	case DeletionPolicyRetain:
+	case DeletionPolicyDelete:
+	case DeletionPolicyDeletePVCsAlso:
+	default:
+		return fmt.Errorf("invalid spec.deletionPolicy: %v", value)
+	}
+	return nil
+}
+
+func (v *Validator) validateArchitecture(value Architecture) error {
+	switch value {
+	case ArchitectureAMD64:
+	case ArchitectureARM64:
+	case ArchitectureAny:
+	default:
+		return fmt.Errorf("invalid spec.architecture: %v", value)
+	}
+	return nil
+}
+
+func (v *Validator) validateIdlePolicy(clusterSpec *FlinkClusterSpec) error {
+	var policy = clusterSpec.IdlePolicy
+	if policy == nil {
+		return nil
+	}
+	if clusterSpec.Job != nil {
+		return fmt.Errorf("spec.idlePolicy is only allowed for session clusters")
+	}
+	if policy.IdleMinutes < 1 {
+		return fmt.Errorf("spec.idlePolicy.idleMinutes must be >= 1")
+	}
+	return nil
+}
+
+func (v *Validator) validateStartupDeadline(clusterSpec *FlinkClusterSpec) error {
+	if seconds := clusterSpec.StartupDeadlineSeconds; seconds != nil && *seconds < 1 {
+		return fmt.Errorf("spec.startupDeadlineSeconds must be >= 1")
+	}
+	var action = clusterSpec.StartupDeadlineAction
+	if action == nil {
+		return nil
+	}
+	switch *action {
+	case StartupDeadlineActionStopReconciling:
+	case StartupDeadlineActionCleanup:
+		if clusterSpec.Job == nil {
+			return fmt.Errorf("spec.startupDeadlineAction %v is only allowed for job clusters", *action)
+		}
+	default:
+		return fmt.Errorf("invalid spec.startupDeadlineAction: %v", *action)
+	}
+	return nil
+}
+
+func (v *Validator) validateDependsOn(cluster *FlinkCluster) error {
+	var names = make(map[string]bool)
+	for i, dependency := range cluster.Spec.DependsOn {
+		switch {
+		case dependency.Name == "":
+			return fmt.Errorf("spec.dependsOn[%d].name is unspecified", i)
+		case dependency.Name == cluster.Name:
+			return fmt.Errorf("spec.dependsOn[%d].name must not be the cluster itself", i)
+		case names[dependency.Name]:
+			return fmt.Errorf("duplicate spec.dependsOn[%d].name %v", i, dependency.Name)
+		}
+		names[dependency.Name] = true
+		if state := dependency.State; state != nil &&
+			*state != ClusterDependencyStateRunning && *state != ClusterDependencyStateSucceeded {
+			return fmt.Errorf("invalid spec.dependsOn[%d].state: %v", i, *state)
+		}
+	}
+	return nil
+}
+
 func (v *Validator) validateJobMode(property string, value JobMode) error {
 	switch value {
 	case JobModeBlocking:
@@ -591,3 +2283,203 @@ func (v *Validator) validateMemoryOffHeapMin(
 	}
 	return nil
 }
+
+// ValidateResourceQuota checks that the pods of a new cluster fit in the
+// remaining capacity of the ResourceQuotas of its namespace, so that a cluster
+// which cannot possibly be scheduled is not left with pending pods.
+func (v *Validator) ValidateResourceQuota(cluster *FlinkCluster) error {
+	if v.quotaCheckMode == ResourceQuotaCheckDisabled || v.quotaReader == nil {
+		return nil
+	}
+
+	var quotas = new(corev1.ResourceQuotaList)
+	if err := v.quotaReader.List(context.TODO(), quotas, client.InNamespace(cluster.Namespace)); err != nil {
+		// Do not block the request when quotas cannot be read.
+		log.Error(err, "Failed to list resource quotas", "namespace", cluster.Namespace)
+		return nil
+	}
+
+	var required = getRequiredQuotaResources(cluster)
+	var violations []string
+	for _, quota := range quotas.Items {
+		for name, hard := range quota.Status.Hard {
+			need, ok := required[name]
+			if !ok {
+				continue
+			}
+			var remaining = hard.DeepCopy()
+			if used, ok := quota.Status.Used[name]; ok {
+				remaining.Sub(used)
+			}
+			if need.Cmp(remaining) > 0 {
+				violations = append(violations, fmt.Sprintf(
+					"%v: requested %v, remaining %v of %v", name, need.String(), remaining.String(), quota.Name))
+			}
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	sort.Strings(violations)
+	var err = fmt.Errorf("the cluster does not fit in the namespace resource quota, %v", strings.Join(violations, "; "))
+	if v.quotaCheckMode == ResourceQuotaCheckReject {
+		return err
+	}
+	log.Info("Resource quota check failed", "name", cluster.Name, "namespace", cluster.Namespace, "reason", err.Error())
+	return nil
+}
+
+// ValidateImageArchitecture checks that the image of a cluster is built for
+// spec.architecture, so that a cluster whose pods cannot run on the nodes is rejected
+// before they end up in ImagePullBackOff. The check is skipped when the manifest of the
+// image cannot be read, e.g. without credentials of its registry.
+func (v *Validator) ValidateImageArchitecture(cluster *FlinkCluster) error {
+	var architecture = cluster.Spec.Architecture
+	if v.imageReader == nil || architecture == nil || *architecture == ArchitectureAny {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), imageArchitectureCheckTimeout)
+	defer cancel()
+	var image = cluster.Spec.Image.Name
+	architectures, err := v.imageRegistry.GetArchitectures(ctx, image, v.getRegistryCredentials(ctx, cluster))
+	if err != nil {
+		// Do not block the request when the manifest cannot be read.
+		log.Info("Skipped image architecture check", "name", cluster.Name, "image", image, "reason", err.Error())
+		return nil
+	}
+	for _, arch := range architectures {
+		if arch == string(*architecture) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%v: image %v is not built for the %v architecture, only for %v",
+		field.NewPath("spec.image.name"), image, *architecture, strings.Join(architectures, ", "))
+}
+
+// Gets the registry credentials of the image pull secrets of a cluster, the secrets listed
+// first taking precedence. Secrets which cannot be read are skipped.
+func (v *Validator) getRegistryCredentials(ctx context.Context, cluster *FlinkCluster) map[string]registry.Credentials {
+	var names []string
+	for _, secret := range cluster.Spec.Image.PullSecrets {
+		names = append(names, secret.Name)
+	}
+	if len(names) == 0 {
+		names = v.defaultPullSecrets
+	}
+
+	var credentials = map[string]registry.Credentials{}
+	for _, name := range names {
+		var secret = new(corev1.Secret)
+		var key = client.ObjectKey{Namespace: cluster.Namespace, Name: name}
+		if err := v.imageReader.Get(ctx, key, secret); err != nil {
+			log.Error(err, "Failed to get image pull secret", "namespace", cluster.Namespace, "name", name)
+			continue
+		}
+		var data, ok = secret.Data[corev1.DockerConfigJsonKey]
+		if !ok {
+			data = secret.Data[corev1.DockerConfigKey]
+		}
+		secretCredentials, err := registry.ParseDockerConfig(data)
+		if err != nil {
+			log.Error(err, "Invalid image pull secret", "namespace", cluster.Namespace, "name", name)
+			continue
+		}
+		for host, creds := range secretCredentials {
+			if _, ok := credentials[host]; !ok {
+				credentials[host] = creds
+			}
+		}
+	}
+	return credentials
+}
+
+// Gets the aggregate requests and limits of the JobManager, TaskManager and
+// job submitter pods of a cluster, keyed by the resource names used in quotas.
+func getRequiredQuotaResources(cluster *FlinkCluster) corev1.ResourceList {
+	var total = corev1.ResourceList{}
+	var add = func(replicas int32, pod corev1.ResourceRequirements) {
+		if replicas <= 0 {
+			return
+		}
+		var n = int64(replicas)
+		var quantities = map[corev1.ResourceName]*resource.Quantity{
+			corev1.ResourceCPU:            pod.Requests.Cpu(),
+			corev1.ResourceRequestsCPU:    pod.Requests.Cpu(),
+			corev1.ResourceMemory:         pod.Requests.Memory(),
+			corev1.ResourceRequestsMemory: pod.Requests.Memory(),
+			corev1.ResourceLimitsCPU:      pod.Limits.Cpu(),
+			corev1.ResourceLimitsMemory:   pod.Limits.Memory(),
+		}
+		for name, q := range quantities {
+			var sum = total[name]
+			sum.Add(*resource.NewMilliQuantity(q.MilliValue()*n, q.Format))
+			total[name] = sum
+		}
+		var pods = total[corev1.ResourcePods]
+		pods.Add(*resource.NewQuantity(n, resource.DecimalSI))
+		total[corev1.ResourcePods] = pods
+	}
+
+	var spec = cluster.Spec
+	var applicationMode = spec.Job != nil && spec.Job.Mode != nil && *spec.Job.Mode == JobModeApplication
+	if jm := spec.JobManager; jm != nil {
+		var replicas int32 = 1
+		if jm.Replicas != nil {
+			replicas = *jm.Replicas
+		}
+		var main = corev1.Container{Resources: jm.Resources}
+		add(replicas, GetPodResourceRequirements(append([]corev1.Container{main}, jm.Sidecars...), jm.InitContainers))
+	}
+	if tm := spec.TaskManager; tm != nil && tm.Replicas != nil {
+		var main = corev1.Container{Resources: tm.Resources}
+		add(*tm.Replicas, GetPodResourceRequirements(append([]corev1.Container{main}, tm.Sidecars...), tm.InitContainers))
+	}
+	if job := spec.Job; job != nil && !applicationMode {
+		var main = corev1.Container{Resources: job.Resources}
+		add(1, GetPodResourceRequirements([]corev1.Container{main}, job.InitContainers))
+	}
+	return total
+}
+
+// GetPodResourceRequirements gets the effective resource requirements of a pod,
+// which is the larger of the sum of its containers and any of its init containers.
+// Requests default to limits as the API server does.
+func GetPodResourceRequirements(containers []corev1.Container, initContainers []corev1.Container) corev1.ResourceRequirements {
+	var effective = func(c corev1.Container) corev1.ResourceRequirements {
+		var r = corev1.ResourceRequirements{Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if q, ok := c.Resources.Limits[name]; ok {
+				r.Limits[name] = q.DeepCopy()
+				r.Requests[name] = q.DeepCopy()
+			}
+			if q, ok := c.Resources.Requests[name]; ok {
+				r.Requests[name] = q.DeepCopy()
+			}
+		}
+		return r
+	}
+	var pod = corev1.ResourceRequirements{Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+	for _, c := range containers {
+		var r = effective(c)
+		for _, list := range []struct{ pod, container corev1.ResourceList }{{pod.Requests, r.Requests}, {pod.Limits, r.Limits}} {
+			for name, q := range list.container {
+				var sum = list.pod[name]
+				sum.Add(q)
+				list.pod[name] = sum
+			}
+		}
+	}
+	for _, c := range initContainers {
+		var r = effective(c)
+		for _, list := range []struct{ pod, container corev1.ResourceList }{{pod.Requests, r.Requests}, {pod.Limits, r.Limits}} {
+			for name, q := range list.container {
+				if q.Cmp(list.pod[name]) > 0 {
+					list.pod[name] = q
+				}
+			}
+		}
+	}
+	return pod
+}
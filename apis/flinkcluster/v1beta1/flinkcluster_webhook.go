@@ -18,8 +18,14 @@ limitations under the License.
 package v1beta1
 
 import (
+	"reflect"
+	"strings"
+
+	"github.com/spotify/flink-on-k8s-operator/internal/registry"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -63,16 +69,68 @@ func (cluster *FlinkCluster) Default() {
 This marker is responsible for generating a validating webhook manifest.
 */
 
-// +kubebuilder:webhook:path=/validate-flinkoperator-k8s-io-v1beta1-flinkcluster,admissionReviewVersions=v1,sideEffects=None,mutating=false,failurePolicy=fail,groups=flinkoperator.k8s.io,resources=flinkclusters,verbs=create;update,versions=v1beta1,name=vflinkcluster.flinkoperator.k8s.io
+// +kubebuilder:webhook:path=/validate-flinkoperator-k8s-io-v1beta1-flinkcluster,admissionReviewVersions=v1,sideEffects=None,mutating=false,failurePolicy=fail,groups=flinkoperator.k8s.io,resources=flinkclusters,verbs=create;update;delete,versions=v1beta1,name=vflinkcluster.flinkoperator.k8s.io
 
 var _ webhook.Validator = &FlinkCluster{}
 var validator = Validator{}
 
+// EnableResourceQuotaCheck makes the webhook check new clusters against the
+// ResourceQuotas of their namespace, read with the given reader.
+func EnableResourceQuotaCheck(reader client.Reader, mode ResourceQuotaCheckMode) {
+	validator.quotaReader = reader
+	validator.quotaCheckMode = mode
+}
+
+// EnableImageArchitectureCheck makes the webhook check that the images of clusters
+// with spec.architecture are built for the architecture, with the credentials of their
+// image pull secrets read with the given reader. The default pull secrets are used for
+// the clusters which do not set spec.image.pullSecrets.
+func EnableImageArchitectureCheck(reader client.Reader, defaultPullSecrets []string) {
+	validator.imageReader = reader
+	validator.imageRegistry = registry.NewClient()
+	validator.defaultPullSecrets = nil
+	for _, name := range defaultPullSecrets {
+		if name = strings.TrimSpace(name); name != "" {
+			validator.defaultPullSecrets = append(validator.defaultPullSecrets, name)
+		}
+	}
+}
+
+// EnableIPFamilyCheck makes the webhook check spec.networking.ipFamilies and ipFamilyPolicy
+// against the IP families of the services of the Kubernetes cluster, e.g. `IPv4` and `IPv6`
+// for dual-stack clusters.
+func EnableIPFamilyCheck(families []string) {
+	validator.clusterIPFamilies = nil
+	for _, family := range families {
+		if family = strings.TrimSpace(family); family != "" {
+			validator.clusterIPFamilies = append(validator.clusterIPFamilies, corev1.IPFamily(family))
+		}
+	}
+}
+
+// EnableClusterTemplates makes the webhook apply the templates of spec.clusterRef to
+// clusters, read with the given reader.
+func EnableClusterTemplates(reader client.Reader) {
+	templateReader = reader
+}
+
+// RequireDeletionConfirmation makes the webhook reject the deletion of running
+// job clusters without ConfirmDeletionAnnotation.
+func RequireDeletionConfirmation() {
+	validator.deletionConfirmationRequired = true
+}
+
 // ValidateCreate implements webhook.Validator so a webhook will be registered
 // for the type.
 func (cluster *FlinkCluster) ValidateCreate() error {
 	log.Info("Validate create", "name", cluster.Name)
-	return validator.ValidateCreate(cluster)
+	if err := validator.ValidateCreate(cluster); err != nil {
+		return err
+	}
+	if err := validator.ValidateResourceQuota(cluster); err != nil {
+		return err
+	}
+	return validator.ValidateImageArchitecture(cluster)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered
@@ -80,16 +138,21 @@ func (cluster *FlinkCluster) ValidateCreate() error {
 func (cluster *FlinkCluster) ValidateUpdate(old runtime.Object) error {
 	log.Info("Validate update", "name", cluster.Name)
 	var oldCluster = old.(*FlinkCluster)
-	return validator.ValidateUpdate(oldCluster, cluster)
+	if err := validator.ValidateUpdate(oldCluster, cluster); err != nil {
+		return err
+	}
+	if cluster.Spec.Image.Name == oldCluster.Spec.Image.Name &&
+		reflect.DeepEqual(cluster.Spec.Architecture, oldCluster.Spec.Architecture) {
+		return nil
+	}
+	return validator.ValidateImageArchitecture(cluster)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered
 // for the type.
 func (cluster *FlinkCluster) ValidateDelete() error {
-	log.Info("validate delete", "name", cluster.Name)
-
-	// TODO
-	return nil
+	log.Info("Validate delete", "name", cluster.Name)
+	return validator.ValidateDelete(cluster)
 }
 
 // +kubebuilder:docs-gen:collapse=Validate object name
@@ -0,0 +1,106 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Labels of the FlinkClusters generated by a FlinkClusterSet.
+const (
+	ClusterSetNameLabel      = "flinkoperator.k8s.io/cluster-set-name"
+	ClusterSetNamespaceLabel = "flinkoperator.k8s.io/cluster-set-namespace"
+)
+
+// FlinkClusterSetGenerator generates the parameter sets for the FlinkClusters.
+// Exactly one of `list` and `namespaces` must be set.
+type FlinkClusterSetGenerator struct {
+	// _(Optional)_ Parameter sets given as a list, a FlinkCluster is generated for each element.
+	List []map[string]string `json:"list,omitempty"`
+
+	// _(Optional)_ Generates a parameter set for each namespace matching the selector.
+	// The name of the namespace is given as the `namespace` parameter.
+	Namespaces *FlinkClusterSetNamespaceGenerator `json:"namespaces,omitempty"`
+}
+
+// FlinkClusterSetNamespaceGenerator selects the namespaces to generate the FlinkClusters for.
+type FlinkClusterSetNamespaceGenerator struct {
+	// Label selector of the namespaces.
+	Selector metav1.LabelSelector `json:"selector"`
+}
+
+// FlinkClusterTemplateMeta defines the metadata of the generated FlinkCluster.
+type FlinkClusterTemplateMeta struct {
+	// Name of the FlinkCluster, must be unique for each parameter set.
+	Name string `json:"name"`
+
+	// _(Optional)_ Namespace of the FlinkCluster, default: the namespace of the FlinkClusterSet.
+	Namespace string `json:"namespace,omitempty"`
+
+	// _(Optional)_ Labels of the FlinkCluster.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// _(Optional)_ Annotations of the FlinkCluster.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// FlinkClusterTemplate defines the FlinkCluster generated for each parameter set.
+// Parameters are referenced as `{{name}}` in string fields.
+type FlinkClusterTemplate struct {
+	// Metadata of the FlinkCluster.
+	Metadata FlinkClusterTemplateMeta `json:"metadata"`
+
+	// Spec of the FlinkCluster.
+	Spec FlinkClusterSpec `json:"spec"`
+}
+
+// FlinkClusterSetSpec defines the desired state of FlinkClusterSet.
+type FlinkClusterSetSpec struct {
+	// Generators of the parameter sets, a FlinkCluster is generated for each parameter set.
+	// +kubebuilder:validation:MinItems=1
+	Generators []FlinkClusterSetGenerator `json:"generators"`
+
+	// Template of the FlinkClusters.
+	Template FlinkClusterTemplate `json:"template"`
+}
+
+// FlinkClusterSetStatus defines the observed state of FlinkClusterSet.
+type FlinkClusterSetStatus struct {
+	// The generation of the spec the FlinkClusters were generated from.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The generated FlinkClusters, as `<namespace>/<name>`.
+	Clusters []string `json:"clusters,omitempty"`
+
+	// The error which occurred while generating the FlinkClusters.
+	Error string `json:"error,omitempty"`
+
+	// Last update timestamp for this status.
+	LastUpdateTime string `json:"lastUpdateTime,omitempty"`
+}
+
+// FlinkClusterSet is the Schema for the flinkclustersets API
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName={fcset}
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="error",type=string,JSONPath=`.status.error`
+// +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
+type FlinkClusterSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FlinkClusterSetSpec   `json:"spec"`
+	Status FlinkClusterSetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FlinkClusterSetList contains a list of FlinkClusterSet
+type FlinkClusterSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FlinkClusterSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FlinkClusterSet{}, &FlinkClusterSetList{})
+}
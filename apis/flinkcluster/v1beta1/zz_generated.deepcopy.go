@@ -25,927 +25,3137 @@ import (
 	"k8s.io/api/autoscaling/v2"
 	"k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BatchSchedulerSpec) DeepCopyInto(out *BatchSchedulerSpec) {
+func (in *AWSKMSEncryptionSpec) DeepCopyInto(out *AWSKMSEncryptionSpec) {
 	*out = *in
+	if in.AccessKeyIDSecretRef != nil {
+		in, out := &in.AccessKeyIDSecretRef, &out.AccessKeyIDSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretAccessKeySecretRef != nil {
+		in, out := &in.SecretAccessKeySecretRef, &out.SecretAccessKeySecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchSchedulerSpec.
-func (in *BatchSchedulerSpec) DeepCopy() *BatchSchedulerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSKMSEncryptionSpec.
+func (in *AWSKMSEncryptionSpec) DeepCopy() *AWSKMSEncryptionSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(BatchSchedulerSpec)
+	out := new(AWSKMSEncryptionSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CleanupPolicy) DeepCopyInto(out *CleanupPolicy) {
+func (in *AdaptiveSavepointSpec) DeepCopyInto(out *AdaptiveSavepointSpec) {
 	*out = *in
+	if in.StateDeltaThreshold != nil {
+		in, out := &in.StateDeltaThreshold, &out.StateDeltaThreshold
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.BeforeNodeDrain != nil {
+		in, out := &in.BeforeNodeDrain, &out.BeforeNodeDrain
+		*out = new(bool)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupPolicy.
-func (in *CleanupPolicy) DeepCopy() *CleanupPolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdaptiveSavepointSpec.
+func (in *AdaptiveSavepointSpec) DeepCopy() *AdaptiveSavepointSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(CleanupPolicy)
+	out := new(AdaptiveSavepointSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ConfigMapStatus) DeepCopyInto(out *ConfigMapStatus) {
+func (in *AdaptiveSavepointStatus) DeepCopyInto(out *AdaptiveSavepointStatus) {
 	*out = *in
+	if in.DrainingNodes != nil {
+		in, out := &in.DrainingNodes, &out.DrainingNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapStatus.
-func (in *ConfigMapStatus) DeepCopy() *ConfigMapStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdaptiveSavepointStatus.
+func (in *AdaptiveSavepointStatus) DeepCopy() *AdaptiveSavepointStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ConfigMapStatus)
+	out := new(AdaptiveSavepointStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FlinkCluster) DeepCopyInto(out *FlinkCluster) {
+func (in *ArtifactCacheSpec) DeepCopyInto(out *ArtifactCacheSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(v1.PersistentVolumeClaimVolumeSource)
+		**out = **in
+	}
+	if in.HostPath != nil {
+		in, out := &in.HostPath, &out.HostPath
+		*out = new(string)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkCluster.
-func (in *FlinkCluster) DeepCopy() *FlinkCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactCacheSpec.
+func (in *ArtifactCacheSpec) DeepCopy() *ArtifactCacheSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(FlinkCluster)
+	out := new(ArtifactCacheSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *FlinkCluster) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchSchedulerSpec) DeepCopyInto(out *BatchSchedulerSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchSchedulerSpec.
+func (in *BatchSchedulerSpec) DeepCopy() *BatchSchedulerSpec {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(BatchSchedulerSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FlinkClusterComponentsStatus) DeepCopyInto(out *FlinkClusterComponentsStatus) {
+func (in *CABundleSpec) DeepCopyInto(out *CABundleSpec) {
 	*out = *in
-	if in.ConfigMap != nil {
-		in, out := &in.ConfigMap, &out.ConfigMap
-		*out = new(ConfigMapStatus)
-		**out = **in
-	}
-	if in.JobManager != nil {
-		in, out := &in.JobManager, &out.JobManager
-		*out = new(JobManagerStatus)
-		**out = **in
-	}
-	in.JobManagerService.DeepCopyInto(&out.JobManagerService)
-	if in.JobManagerIngress != nil {
-		in, out := &in.JobManagerIngress, &out.JobManagerIngress
-		*out = new(JobManagerIngressStatus)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.TaskManager != nil {
-		in, out := &in.TaskManager, &out.TaskManager
-		*out = new(TaskManagerStatus)
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(string)
 		**out = **in
 	}
-	if in.Job != nil {
-		in, out := &in.Job, &out.Job
-		*out = new(JobStatus)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterComponentsStatus.
-func (in *FlinkClusterComponentsStatus) DeepCopy() *FlinkClusterComponentsStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CABundleSpec.
+func (in *CABundleSpec) DeepCopy() *CABundleSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(FlinkClusterComponentsStatus)
+	out := new(CABundleSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FlinkClusterControlStatus) DeepCopyInto(out *FlinkClusterControlStatus) {
+func (in *CanaryUpdateSpec) DeepCopyInto(out *CanaryUpdateSpec) {
 	*out = *in
-	if in.Details != nil {
-		in, out := &in.Details, &out.Details
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterControlStatus.
-func (in *FlinkClusterControlStatus) DeepCopy() *FlinkClusterControlStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryUpdateSpec.
+func (in *CanaryUpdateSpec) DeepCopy() *CanaryUpdateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(FlinkClusterControlStatus)
+	out := new(CanaryUpdateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FlinkClusterList) DeepCopyInto(out *FlinkClusterList) {
+func (in *CanaryUpdateStatus) DeepCopyInto(out *CanaryUpdateStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]FlinkCluster, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterList.
-func (in *FlinkClusterList) DeepCopy() *FlinkClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryUpdateStatus.
+func (in *CanaryUpdateStatus) DeepCopy() *CanaryUpdateStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(FlinkClusterList)
+	out := new(CanaryUpdateStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *FlinkClusterList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FlinkClusterSpec) DeepCopyInto(out *FlinkClusterSpec) {
+func (in *CleanupPolicy) DeepCopyInto(out *CleanupPolicy) {
 	*out = *in
-	in.Image.DeepCopyInto(&out.Image)
-	if in.ServiceAccountName != nil {
-		in, out := &in.ServiceAccountName, &out.ServiceAccountName
-		*out = new(string)
-		**out = **in
-	}
-	if in.BatchSchedulerName != nil {
-		in, out := &in.BatchSchedulerName, &out.BatchSchedulerName
-		*out = new(string)
-		**out = **in
-	}
-	if in.BatchScheduler != nil {
-		in, out := &in.BatchScheduler, &out.BatchScheduler
-		*out = new(BatchSchedulerSpec)
-		**out = **in
-	}
-	if in.PodDisruptionBudget != nil {
-		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
-		*out = new(policyv1.PodDisruptionBudgetSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.JobManager != nil {
-		in, out := &in.JobManager, &out.JobManager
-		*out = new(JobManagerSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.TaskManager != nil {
-		in, out := &in.TaskManager, &out.TaskManager
-		*out = new(TaskManagerSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Job != nil {
-		in, out := &in.Job, &out.Job
-		*out = new(JobSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.EnvVars != nil {
-		in, out := &in.EnvVars, &out.EnvVars
-		*out = make([]v1.EnvVar, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.EnvFrom != nil {
-		in, out := &in.EnvFrom, &out.EnvFrom
-		*out = make([]v1.EnvFromSource, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.FlinkProperties != nil {
-		in, out := &in.FlinkProperties, &out.FlinkProperties
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.HadoopConfig != nil {
-		in, out := &in.HadoopConfig, &out.HadoopConfig
-		*out = new(HadoopConfig)
-		**out = **in
-	}
-	if in.GCPConfig != nil {
-		in, out := &in.GCPConfig, &out.GCPConfig
-		*out = new(GCPConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.LogConfig != nil {
-		in, out := &in.LogConfig, &out.LogConfig
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.RevisionHistoryLimit != nil {
-		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
 		*out = new(int32)
 		**out = **in
 	}
-	if in.RecreateOnUpdate != nil {
-		in, out := &in.RecreateOnUpdate, &out.RecreateOnUpdate
-		*out = new(bool)
+	if in.KeepLastFinished != nil {
+		in, out := &in.KeepLastFinished, &out.KeepLastFinished
+		*out = new(KeepLastFinishedPolicy)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterSpec.
-func (in *FlinkClusterSpec) DeepCopy() *FlinkClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupPolicy.
+func (in *CleanupPolicy) DeepCopy() *CleanupPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(FlinkClusterSpec)
+	out := new(CleanupPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FlinkClusterStatus) DeepCopyInto(out *FlinkClusterStatus) {
+func (in *ClusterDependency) DeepCopyInto(out *ClusterDependency) {
 	*out = *in
-	in.Components.DeepCopyInto(&out.Components)
-	if in.Control != nil {
-		in, out := &in.Control, &out.Control
-		*out = new(FlinkClusterControlStatus)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Savepoint != nil {
-		in, out := &in.Savepoint, &out.Savepoint
-		*out = new(SavepointStatus)
+	if in.State != nil {
+		in, out := &in.State, &out.State
+		*out = new(ClusterDependencyState)
 		**out = **in
 	}
-	in.Revision.DeepCopyInto(&out.Revision)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterStatus.
-func (in *FlinkClusterStatus) DeepCopy() *FlinkClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDependency.
+func (in *ClusterDependency) DeepCopy() *ClusterDependency {
 	if in == nil {
 		return nil
 	}
-	out := new(FlinkClusterStatus)
+	out := new(ClusterDependency)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GCPConfig) DeepCopyInto(out *GCPConfig) {
+func (in *ClusterResourcesStatus) DeepCopyInto(out *ClusterResourcesStatus) {
 	*out = *in
-	if in.ServiceAccount != nil {
-		in, out := &in.ServiceAccount, &out.ServiceAccount
-		*out = new(GCPServiceAccount)
-		**out = **in
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCPConfig.
-func (in *GCPConfig) DeepCopy() *GCPConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterResourcesStatus.
+func (in *ClusterResourcesStatus) DeepCopy() *ClusterResourcesStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GCPConfig)
+	out := new(ClusterResourcesStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GCPServiceAccount) DeepCopyInto(out *GCPServiceAccount) {
+func (in *ComponentsSpec) DeepCopyInto(out *ComponentsSpec) {
 	*out = *in
+	if in.CreateJmService != nil {
+		in, out := &in.CreateJmService, &out.CreateJmService
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CreateTmService != nil {
+		in, out := &in.CreateTmService, &out.CreateTmService
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CreateIngress != nil {
+		in, out := &in.CreateIngress, &out.CreateIngress
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CreatePDB != nil {
+		in, out := &in.CreatePDB, &out.CreatePDB
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CreateConfigMap != nil {
+		in, out := &in.CreateConfigMap, &out.CreateConfigMap
+		*out = new(bool)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCPServiceAccount.
-func (in *GCPServiceAccount) DeepCopy() *GCPServiceAccount {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentsSpec.
+func (in *ComponentsSpec) DeepCopy() *ComponentsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GCPServiceAccount)
+	out := new(ComponentsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HadoopConfig) DeepCopyInto(out *HadoopConfig) {
+func (in *ConfigMapKeyReadinessGate) DeepCopyInto(out *ConfigMapKeyReadinessGate) {
 	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(string)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HadoopConfig.
-func (in *HadoopConfig) DeepCopy() *HadoopConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapKeyReadinessGate.
+func (in *ConfigMapKeyReadinessGate) DeepCopy() *ConfigMapKeyReadinessGate {
 	if in == nil {
 		return nil
 	}
-	out := new(HadoopConfig)
+	out := new(ConfigMapKeyReadinessGate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HorizontalPodAutoscalerSpec) DeepCopyInto(out *HorizontalPodAutoscalerSpec) {
+func (in *ConfigMapStatus) DeepCopyInto(out *ConfigMapStatus) {
 	*out = *in
-	if in.MinReplicas != nil {
-		in, out := &in.MinReplicas, &out.MinReplicas
-		*out = new(int32)
-		**out = **in
-	}
-	if in.Metrics != nil {
-		in, out := &in.Metrics, &out.Metrics
-		*out = make([]v2.MetricSpec, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Behavior != nil {
-		in, out := &in.Behavior, &out.Behavior
-		*out = new(v2.HorizontalPodAutoscalerBehavior)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HorizontalPodAutoscalerSpec.
-func (in *HorizontalPodAutoscalerSpec) DeepCopy() *HorizontalPodAutoscalerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapStatus.
+func (in *ConfigMapStatus) DeepCopy() *ConfigMapStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(HorizontalPodAutoscalerSpec)
+	out := new(ConfigMapStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ImageSpec) DeepCopyInto(out *ImageSpec) {
+func (in *ConfigOverrideSpec) DeepCopyInto(out *ConfigOverrideSpec) {
 	*out = *in
-	if in.PullSecrets != nil {
-		in, out := &in.PullSecrets, &out.PullSecrets
-		*out = make([]v1.LocalObjectReference, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSpec.
-func (in *ImageSpec) DeepCopy() *ImageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigOverrideSpec.
+func (in *ConfigOverrideSpec) DeepCopy() *ConfigOverrideSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ImageSpec)
+	out := new(ConfigOverrideSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobManagerIngressSpec) DeepCopyInto(out *JobManagerIngressSpec) {
+func (in *ConsumerLagObjective) DeepCopyInto(out *ConsumerLagObjective) {
 	*out = *in
-	if in.HostFormat != nil {
-		in, out := &in.HostFormat, &out.HostFormat
-		*out = new(string)
-		**out = **in
-	}
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.UseTLS != nil {
-		in, out := &in.UseTLS, &out.UseTLS
-		*out = new(bool)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumerLagObjective.
+func (in *ConsumerLagObjective) DeepCopy() *ConsumerLagObjective {
+	if in == nil {
+		return nil
 	}
-	if in.TLSSecretName != nil {
-		in, out := &in.TLSSecretName, &out.TLSSecretName
-		*out = new(string)
-		**out = **in
+	out := new(ConsumerLagObjective)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerTerminationStatus) DeepCopyInto(out *ContainerTerminationStatus) {
+	*out = *in
+	if in.SuggestedMemory != nil {
+		in, out := &in.SuggestedMemory, &out.SuggestedMemory
+		x := (*in).DeepCopy()
+		*out = &x
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerIngressSpec.
-func (in *JobManagerIngressSpec) DeepCopy() *JobManagerIngressSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerTerminationStatus.
+func (in *ContainerTerminationStatus) DeepCopy() *ContainerTerminationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(JobManagerIngressSpec)
+	out := new(ContainerTerminationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobManagerIngressStatus) DeepCopyInto(out *JobManagerIngressStatus) {
+func (in *DatadogReporter) DeepCopyInto(out *DatadogReporter) {
 	*out = *in
-	if in.URLs != nil {
-		in, out := &in.URLs, &out.URLs
+	in.APIKeySecret.DeepCopyInto(&out.APIKeySecret)
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.DataCenter != nil {
+		in, out := &in.DataCenter, &out.DataCenter
+		*out = new(string)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerIngressStatus.
-func (in *JobManagerIngressStatus) DeepCopy() *JobManagerIngressStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatadogReporter.
+func (in *DatadogReporter) DeepCopy() *DatadogReporter {
 	if in == nil {
 		return nil
 	}
-	out := new(JobManagerIngressStatus)
+	out := new(DatadogReporter)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobManagerPorts) DeepCopyInto(out *JobManagerPorts) {
+func (in *DiagnosticsSpec) DeepCopyInto(out *DiagnosticsSpec) {
 	*out = *in
-	if in.RPC != nil {
-		in, out := &in.RPC, &out.RPC
-		*out = new(int32)
+	if in.HeapDumpOnOutOfMemory != nil {
+		in, out := &in.HeapDumpOnOutOfMemory, &out.HeapDumpOnOutOfMemory
+		*out = new(bool)
 		**out = **in
 	}
-	if in.Blob != nil {
-		in, out := &in.Blob, &out.Blob
-		*out = new(int32)
+	if in.ExitOnOutOfMemory != nil {
+		in, out := &in.ExitOnOutOfMemory, &out.ExitOnOutOfMemory
+		*out = new(bool)
 		**out = **in
 	}
-	if in.Query != nil {
-		in, out := &in.Query, &out.Query
+	if in.FlightRecordingSeconds != nil {
+		in, out := &in.FlightRecordingSeconds, &out.FlightRecordingSeconds
 		*out = new(int32)
 		**out = **in
 	}
-	if in.UI != nil {
-		in, out := &in.UI, &out.UI
-		*out = new(int32)
-		**out = **in
+	if in.Volume != nil {
+		in, out := &in.Volume, &out.Volume
+		*out = new(v1.VolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Upload != nil {
+		in, out := &in.Upload, &out.Upload
+		*out = new(DiagnosticsUpload)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerPorts.
-func (in *JobManagerPorts) DeepCopy() *JobManagerPorts {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiagnosticsSpec.
+func (in *DiagnosticsSpec) DeepCopy() *DiagnosticsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(JobManagerPorts)
+	out := new(DiagnosticsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobManagerServiceStatus) DeepCopyInto(out *JobManagerServiceStatus) {
+func (in *DiagnosticsUpload) DeepCopyInto(out *DiagnosticsUpload) {
 	*out = *in
-	if in.LoadBalancerIngress != nil {
-		in, out := &in.LoadBalancerIngress, &out.LoadBalancerIngress
-		*out = make([]v1.LoadBalancerIngress, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.AuthorizationSecret != nil {
+		in, out := &in.AuthorizationSecret, &out.AuthorizationSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerServiceStatus.
-func (in *JobManagerServiceStatus) DeepCopy() *JobManagerServiceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiagnosticsUpload.
+func (in *DiagnosticsUpload) DeepCopy() *DiagnosticsUpload {
 	if in == nil {
 		return nil
 	}
-	out := new(JobManagerServiceStatus)
+	out := new(DiagnosticsUpload)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobManagerSpec) DeepCopyInto(out *JobManagerSpec) {
+func (in *ExternalDNSSpec) DeepCopyInto(out *ExternalDNSSpec) {
 	*out = *in
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
-		**out = **in
-	}
-	if in.ServiceAnnotations != nil {
-		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.ServiceLabels != nil {
-		in, out := &in.ServiceLabels, &out.ServiceLabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Ingress != nil {
-		in, out := &in.Ingress, &out.Ingress
-		*out = new(JobManagerIngressSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	in.Ports.DeepCopyInto(&out.Ports)
-	if in.ExtraPorts != nil {
-		in, out := &in.ExtraPorts, &out.ExtraPorts
-		*out = make([]NamedPort, len(*in))
-		copy(*out, *in)
-	}
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.MemoryOffHeapRatio != nil {
-		in, out := &in.MemoryOffHeapRatio, &out.MemoryOffHeapRatio
-		*out = new(int32)
-		**out = **in
-	}
-	out.MemoryOffHeapMin = in.MemoryOffHeapMin.DeepCopy()
-	if in.MemoryProcessRatio != nil {
-		in, out := &in.MemoryProcessRatio, &out.MemoryProcessRatio
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
 		*out = new(int32)
 		**out = **in
 	}
-	if in.Volumes != nil {
-		in, out := &in.Volumes, &out.Volumes
-		*out = make([]v1.Volume, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.VolumeMounts != nil {
-		in, out := &in.VolumeMounts, &out.VolumeMounts
-		*out = make([]v1.VolumeMount, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.VolumeClaimTemplates != nil {
-		in, out := &in.VolumeClaimTemplates, &out.VolumeClaimTemplates
-		*out = make([]v1.PersistentVolumeClaim, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.InitContainers != nil {
-		in, out := &in.InitContainers, &out.InitContainers
-		*out = make([]v1.Container, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Sidecars != nil {
-		in, out := &in.Sidecars, &out.Sidecars
-		*out = make([]v1.Container, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.PodAnnotations != nil {
-		in, out := &in.PodAnnotations, &out.PodAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.SecurityContext != nil {
-		in, out := &in.SecurityContext, &out.SecurityContext
-		*out = new(v1.PodSecurityContext)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.PodLabels != nil {
-		in, out := &in.PodLabels, &out.PodLabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.LivenessProbe != nil {
-		in, out := &in.LivenessProbe, &out.LivenessProbe
-		*out = new(v1.Probe)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ReadinessProbe != nil {
-		in, out := &in.ReadinessProbe, &out.ReadinessProbe
-		*out = new(v1.Probe)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.HostAliases != nil {
-		in, out := &in.HostAliases, &out.HostAliases
-		*out = make([]v1.HostAlias, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerSpec.
-func (in *JobManagerSpec) DeepCopy() *JobManagerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalDNSSpec.
+func (in *ExternalDNSSpec) DeepCopy() *ExternalDNSSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(JobManagerSpec)
+	out := new(ExternalDNSSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobManagerStatus) DeepCopyInto(out *JobManagerStatus) {
+func (in *ExternalSecretSource) DeepCopyInto(out *ExternalSecretSource) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerStatus.
-func (in *JobManagerStatus) DeepCopy() *JobManagerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecretSource.
+func (in *ExternalSecretSource) DeepCopy() *ExternalSecretSource {
 	if in == nil {
 		return nil
 	}
-	out := new(JobManagerStatus)
+	out := new(ExternalSecretSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobSpec) DeepCopyInto(out *JobSpec) {
+func (in *ExtraConfigMount) DeepCopyInto(out *ExtraConfigMount) {
 	*out = *in
-	if in.ClassPath != nil {
-		in, out := &in.ClassPath, &out.ClassPath
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(v1.ConfigMapProjection)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.JarFile != nil {
-		in, out := &in.JarFile, &out.JarFile
-		*out = new(string)
-		**out = **in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(v1.SecretProjection)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtraConfigMount.
+func (in *ExtraConfigMount) DeepCopy() *ExtraConfigMount {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtraConfigMount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkCluster) DeepCopyInto(out *FlinkCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkCluster.
+func (in *FlinkCluster) DeepCopy() *FlinkCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FlinkCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkClusterComponentsStatus) DeepCopyInto(out *FlinkClusterComponentsStatus) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(ConfigMapStatus)
+		**out = **in
+	}
+	if in.JobManager != nil {
+		in, out := &in.JobManager, &out.JobManager
+		*out = new(JobManagerStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	in.JobManagerService.DeepCopyInto(&out.JobManagerService)
+	if in.JobManagerIngress != nil {
+		in, out := &in.JobManagerIngress, &out.JobManagerIngress
+		*out = new(JobManagerIngressStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TaskManager != nil {
+		in, out := &in.TaskManager, &out.TaskManager
+		*out = new(TaskManagerStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(JobStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterComponentsStatus.
+func (in *FlinkClusterComponentsStatus) DeepCopy() *FlinkClusterComponentsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkClusterComponentsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkClusterControlStatus) DeepCopyInto(out *FlinkClusterControlStatus) {
+	*out = *in
+	if in.Details != nil {
+		in, out := &in.Details, &out.Details
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterControlStatus.
+func (in *FlinkClusterControlStatus) DeepCopy() *FlinkClusterControlStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkClusterControlStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkClusterEndpoints) DeepCopyInto(out *FlinkClusterEndpoints) {
+	*out = *in
+	if in.IngressUI != nil {
+		in, out := &in.IngressUI, &out.IngressUI
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LoadBalancerUI != nil {
+		in, out := &in.LoadBalancerUI, &out.LoadBalancerUI
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]MetricsEndpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterEndpoints.
+func (in *FlinkClusterEndpoints) DeepCopy() *FlinkClusterEndpoints {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkClusterEndpoints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkClusterList) DeepCopyInto(out *FlinkClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FlinkCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterList.
+func (in *FlinkClusterList) DeepCopy() *FlinkClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FlinkClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkClusterSet) DeepCopyInto(out *FlinkClusterSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterSet.
+func (in *FlinkClusterSet) DeepCopy() *FlinkClusterSet {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkClusterSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FlinkClusterSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkClusterSetGenerator) DeepCopyInto(out *FlinkClusterSetGenerator) {
+	*out = *in
+	if in.List != nil {
+		in, out := &in.List, &out.List
+		*out = make([]map[string]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+		}
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = new(FlinkClusterSetNamespaceGenerator)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterSetGenerator.
+func (in *FlinkClusterSetGenerator) DeepCopy() *FlinkClusterSetGenerator {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkClusterSetGenerator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkClusterSetList) DeepCopyInto(out *FlinkClusterSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FlinkClusterSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterSetList.
+func (in *FlinkClusterSetList) DeepCopy() *FlinkClusterSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkClusterSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FlinkClusterSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkClusterSetNamespaceGenerator) DeepCopyInto(out *FlinkClusterSetNamespaceGenerator) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterSetNamespaceGenerator.
+func (in *FlinkClusterSetNamespaceGenerator) DeepCopy() *FlinkClusterSetNamespaceGenerator {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkClusterSetNamespaceGenerator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkClusterSetSpec) DeepCopyInto(out *FlinkClusterSetSpec) {
+	*out = *in
+	if in.Generators != nil {
+		in, out := &in.Generators, &out.Generators
+		*out = make([]FlinkClusterSetGenerator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterSetSpec.
+func (in *FlinkClusterSetSpec) DeepCopy() *FlinkClusterSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkClusterSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkClusterSetStatus) DeepCopyInto(out *FlinkClusterSetStatus) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterSetStatus.
+func (in *FlinkClusterSetStatus) DeepCopy() *FlinkClusterSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkClusterSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkClusterSpec) DeepCopyInto(out *FlinkClusterSpec) {
+	*out = *in
+	in.Image.DeepCopyInto(&out.Image)
+	if in.Architecture != nil {
+		in, out := &in.Architecture, &out.Architecture
+		*out = new(Architecture)
+		**out = **in
+	}
+	if in.ServiceAccountName != nil {
+		in, out := &in.ServiceAccountName, &out.ServiceAccountName
+		*out = new(string)
+		**out = **in
+	}
+	if in.BatchSchedulerName != nil {
+		in, out := &in.BatchSchedulerName, &out.BatchSchedulerName
+		*out = new(string)
+		**out = **in
+	}
+	if in.BatchScheduler != nil {
+		in, out := &in.BatchScheduler, &out.BatchScheduler
+		*out = new(BatchSchedulerSpec)
+		**out = **in
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(policyv1.PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JobManager != nil {
+		in, out := &in.JobManager, &out.JobManager
+		*out = new(JobManagerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TaskManager != nil {
+		in, out := &in.TaskManager, &out.TaskManager
+		*out = new(TaskManagerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(JobSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterRef != nil {
+		in, out := &in.ClusterRef, &out.ClusterRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Jars != nil {
+		in, out := &in.Jars, &out.Jars
+		*out = make([]SessionJar, len(*in))
+		copy(*out, *in)
+	}
+	if in.EnvVars != nil {
+		in, out := &in.EnvVars, &out.EnvVars
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Timezone != nil {
+		in, out := &in.Timezone, &out.Timezone
+		*out = new(string)
+		**out = **in
+	}
+	if in.Networking != nil {
+		in, out := &in.Networking, &out.Networking
+		*out = new(NetworkingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Diagnostics != nil {
+		in, out := &in.Diagnostics, &out.Diagnostics
+		*out = new(DiagnosticsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StateExport != nil {
+		in, out := &in.StateExport, &out.StateExport
+		*out = new(StateExportSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(MonitoringSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FlinkProperties != nil {
+		in, out := &in.FlinkProperties, &out.FlinkProperties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.FlinkPropertiesFrom != nil {
+		in, out := &in.FlinkPropertiesFrom, &out.FlinkPropertiesFrom
+		*out = make([]FlinkPropertiesSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HadoopConfig != nil {
+		in, out := &in.HadoopConfig, &out.HadoopConfig
+		*out = new(HadoopConfig)
+		**out = **in
+	}
+	if in.GCPConfig != nil {
+		in, out := &in.GCPConfig, &out.GCPConfig
+		*out = new(GCPConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StateEncryption != nil {
+		in, out := &in.StateEncryption, &out.StateEncryption
+		*out = new(StateEncryptionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FlinkPlugins != nil {
+		in, out := &in.FlinkPlugins, &out.FlinkPlugins
+		*out = make([]FlinkPlugin, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExtraConfigMounts != nil {
+		in, out := &in.ExtraConfigMounts, &out.ExtraConfigMounts
+		*out = make([]ExtraConfigMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecretsInjection != nil {
+		in, out := &in.SecretsInjection, &out.SecretsInjection
+		*out = make([]SecretInjection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LogConfig != nil {
+		in, out := &in.LogConfig, &out.LogConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ConfigOverride != nil {
+		in, out := &in.ConfigOverride, &out.ConfigOverride
+		*out = new(ConfigOverrideSpec)
+		**out = **in
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(LoggingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RecreateOnUpdate != nil {
+		in, out := &in.RecreateOnUpdate, &out.RecreateOnUpdate
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CanaryUpdate != nil {
+		in, out := &in.CanaryUpdate, &out.CanaryUpdate
+		*out = new(CanaryUpdateSpec)
+		**out = **in
+	}
+	if in.UpdatePolicy != nil {
+		in, out := &in.UpdatePolicy, &out.UpdatePolicy
+		*out = new(UpdatePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeletionPolicy != nil {
+		in, out := &in.DeletionPolicy, &out.DeletionPolicy
+		*out = new(DeletionPolicy)
+		**out = **in
+	}
+	if in.StartupDeadlineSeconds != nil {
+		in, out := &in.StartupDeadlineSeconds, &out.StartupDeadlineSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StartupDeadlineAction != nil {
+		in, out := &in.StartupDeadlineAction, &out.StartupDeadlineAction
+		*out = new(StartupDeadlineAction)
+		**out = **in
+	}
+	if in.IdlePolicy != nil {
+		in, out := &in.IdlePolicy, &out.IdlePolicy
+		*out = new(IdlePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpdateOnReferencedConfigChange != nil {
+		in, out := &in.UpdateOnReferencedConfigChange, &out.UpdateOnReferencedConfigChange
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AdoptExisting != nil {
+		in, out := &in.AdoptExisting, &out.AdoptExisting
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = new(ComponentsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]ClusterDependency, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterSpec.
+func (in *FlinkClusterSpec) DeepCopy() *FlinkClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkClusterStatus) DeepCopyInto(out *FlinkClusterStatus) {
+	*out = *in
+	in.Components.DeepCopyInto(&out.Components)
+	if in.Control != nil {
+		in, out := &in.Control, &out.Control
+		*out = new(FlinkClusterControlStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Savepoint != nil {
+		in, out := &in.Savepoint, &out.Savepoint
+		*out = new(SavepointStatus)
+		**out = **in
+	}
+	in.Revision.DeepCopyInto(&out.Revision)
+	if in.UpdateProgress != nil {
+		in, out := &in.UpdateProgress, &out.UpdateProgress
+		*out = new(UpdateProgressStatus)
+		**out = **in
+	}
+	if in.CanaryUpdate != nil {
+		in, out := &in.CanaryUpdate, &out.CanaryUpdate
+		*out = new(CanaryUpdateStatus)
+		**out = **in
+	}
+	if in.Idle != nil {
+		in, out := &in.Idle, &out.Idle
+		*out = new(IdleStatus)
+		**out = **in
+	}
+	if in.TaskManagerDecommission != nil {
+		in, out := &in.TaskManagerDecommission, &out.TaskManagerDecommission
+		*out = new(TaskManagerDecommissionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SucceededDependencies != nil {
+		in, out := &in.SucceededDependencies, &out.SucceededDependencies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReconcileError != nil {
+		in, out := &in.ReconcileError, &out.ReconcileError
+		*out = new(ReconcileErrorStatus)
+		**out = **in
+	}
+	if in.Jars != nil {
+		in, out := &in.Jars, &out.Jars
+		*out = make([]SessionJarStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.StateExport != nil {
+		in, out := &in.StateExport, &out.StateExport
+		*out = new(StateExportStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = new(FlinkClusterEndpoints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(ClusterResourcesStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterStatus.
+func (in *FlinkClusterStatus) DeepCopy() *FlinkClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkClusterTemplate) DeepCopyInto(out *FlinkClusterTemplate) {
+	*out = *in
+	in.Metadata.DeepCopyInto(&out.Metadata)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterTemplate.
+func (in *FlinkClusterTemplate) DeepCopy() *FlinkClusterTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkClusterTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkClusterTemplateMeta) DeepCopyInto(out *FlinkClusterTemplateMeta) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkClusterTemplateMeta.
+func (in *FlinkClusterTemplateMeta) DeepCopy() *FlinkClusterTemplateMeta {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkClusterTemplateMeta)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlinkPropertiesSource) DeepCopyInto(out *FlinkPropertiesSource) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(ExternalSecretSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlinkPropertiesSource.
+func (in *FlinkPropertiesSource) DeepCopy() *FlinkPropertiesSource {
+	if in == nil {
+		return nil
+	}
+	out := new(FlinkPropertiesSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GCPConfig) DeepCopyInto(out *GCPConfig) {
+	*out = *in
+	if in.ServiceAccount != nil {
+		in, out := &in.ServiceAccount, &out.ServiceAccount
+		*out = new(GCPServiceAccount)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCPConfig.
+func (in *GCPConfig) DeepCopy() *GCPConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GCPConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GCPServiceAccount) DeepCopyInto(out *GCPServiceAccount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCPServiceAccount.
+func (in *GCPServiceAccount) DeepCopy() *GCPServiceAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(GCPServiceAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GCSCustomerKeySpec) DeepCopyInto(out *GCSCustomerKeySpec) {
+	*out = *in
+	in.KeySecretRef.DeepCopyInto(&out.KeySecretRef)
+	in.KeyHashSecretRef.DeepCopyInto(&out.KeyHashSecretRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCSCustomerKeySpec.
+func (in *GCSCustomerKeySpec) DeepCopy() *GCSCustomerKeySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GCSCustomerKeySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitRepoSpec) DeepCopyInto(out *GitRepoSpec) {
+	*out = *in
+	if in.Ref != nil {
+		in, out := &in.Ref, &out.Ref
+		*out = new(string)
+		**out = **in
+	}
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepoSpec.
+func (in *GitRepoSpec) DeepCopy() *GitRepoSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitRepoSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPReadinessGate) DeepCopyInto(out *HTTPReadinessGate) {
+	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPReadinessGate.
+func (in *HTTPReadinessGate) DeepCopy() *HTTPReadinessGate {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPReadinessGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HadoopConfig) DeepCopyInto(out *HadoopConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HadoopConfig.
+func (in *HadoopConfig) DeepCopy() *HadoopConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HadoopConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HorizontalPodAutoscalerSpec) DeepCopyInto(out *HorizontalPodAutoscalerSpec) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]v2.MetricSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(v2.HorizontalPodAutoscalerBehavior)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TargetKafkaLagPerReplica != nil {
+		in, out := &in.TargetKafkaLagPerReplica, &out.TargetKafkaLagPerReplica
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HorizontalPodAutoscalerSpec.
+func (in *HorizontalPodAutoscalerSpec) DeepCopy() *HorizontalPodAutoscalerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HorizontalPodAutoscalerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdlePolicy) DeepCopyInto(out *IdlePolicy) {
+	*out = *in
+	if in.ScaleJobManager != nil {
+		in, out := &in.ScaleJobManager, &out.ScaleJobManager
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdlePolicy.
+func (in *IdlePolicy) DeepCopy() *IdlePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(IdlePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdleStatus) DeepCopyInto(out *IdleStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdleStatus.
+func (in *IdleStatus) DeepCopy() *IdleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IdleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageSpec) DeepCopyInto(out *ImageSpec) {
+	*out = *in
+	if in.PullSecrets != nil {
+		in, out := &in.PullSecrets, &out.PullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSpec.
+func (in *ImageSpec) DeepCopy() *ImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressExternalAuthSpec) DeepCopyInto(out *IngressExternalAuthSpec) {
+	*out = *in
+	if in.SignInURL != nil {
+		in, out := &in.SignInURL, &out.SignInURL
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressExternalAuthSpec.
+func (in *IngressExternalAuthSpec) DeepCopy() *IngressExternalAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressExternalAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobAccumulatorPredicate) DeepCopyInto(out *JobAccumulatorPredicate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobAccumulatorPredicate.
+func (in *JobAccumulatorPredicate) DeepCopy() *JobAccumulatorPredicate {
+	if in == nil {
+		return nil
+	}
+	out := new(JobAccumulatorPredicate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobArgSource) DeepCopyInto(out *JobArgSource) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(string)
+		**out = **in
+	}
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobArgSource.
+func (in *JobArgSource) DeepCopy() *JobArgSource {
+	if in == nil {
+		return nil
+	}
+	out := new(JobArgSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobManagerIngressAuthSpec) DeepCopyInto(out *JobManagerIngressAuthSpec) {
+	*out = *in
+	if in.OAuth2Proxy != nil {
+		in, out := &in.OAuth2Proxy, &out.OAuth2Proxy
+		*out = new(OAuth2ProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalAuth != nil {
+		in, out := &in.ExternalAuth, &out.ExternalAuth
+		*out = new(IngressExternalAuthSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerIngressAuthSpec.
+func (in *JobManagerIngressAuthSpec) DeepCopy() *JobManagerIngressAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JobManagerIngressAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobManagerIngressSpec) DeepCopyInto(out *JobManagerIngressSpec) {
+	*out = *in
+	if in.HostFormat != nil {
+		in, out := &in.HostFormat, &out.HostFormat
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.UseTLS != nil {
+		in, out := &in.UseTLS, &out.UseTLS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TLSSecretName != nil {
+		in, out := &in.TLSSecretName, &out.TLSSecretName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(JobManagerIngressAuthSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerIngressSpec.
+func (in *JobManagerIngressSpec) DeepCopy() *JobManagerIngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JobManagerIngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobManagerIngressStatus) DeepCopyInto(out *JobManagerIngressStatus) {
+	*out = *in
+	if in.URLs != nil {
+		in, out := &in.URLs, &out.URLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerIngressStatus.
+func (in *JobManagerIngressStatus) DeepCopy() *JobManagerIngressStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JobManagerIngressStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobManagerPorts) DeepCopyInto(out *JobManagerPorts) {
+	*out = *in
+	if in.RPC != nil {
+		in, out := &in.RPC, &out.RPC
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Blob != nil {
+		in, out := &in.Blob, &out.Blob
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Query != nil {
+		in, out := &in.Query, &out.Query
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UI != nil {
+		in, out := &in.UI, &out.UI
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AppProtocols != nil {
+		in, out := &in.AppProtocols, &out.AppProtocols
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerPorts.
+func (in *JobManagerPorts) DeepCopy() *JobManagerPorts {
+	if in == nil {
+		return nil
+	}
+	out := new(JobManagerPorts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobManagerServiceStatus) DeepCopyInto(out *JobManagerServiceStatus) {
+	*out = *in
+	if in.LoadBalancerIngress != nil {
+		in, out := &in.LoadBalancerIngress, &out.LoadBalancerIngress
+		*out = make([]v1.LoadBalancerIngress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerServiceStatus.
+func (in *JobManagerServiceStatus) DeepCopy() *JobManagerServiceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JobManagerServiceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobManagerSpec) DeepCopyInto(out *JobManagerSpec) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ServiceLabels != nil {
+		in, out := &in.ServiceLabels, &out.ServiceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(JobManagerIngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalDNS != nil {
+		in, out := &in.ExternalDNS, &out.ExternalDNS
+		*out = new(ExternalDNSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadOnlyUI != nil {
+		in, out := &in.ReadOnlyUI, &out.ReadOnlyUI
+		*out = new(bool)
+		**out = **in
+	}
+	in.Ports.DeepCopyInto(&out.Ports)
+	if in.ExtraPorts != nil {
+		in, out := &in.ExtraPorts, &out.ExtraPorts
+		*out = make([]NamedPort, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.MemoryOffHeapRatio != nil {
+		in, out := &in.MemoryOffHeapRatio, &out.MemoryOffHeapRatio
+		*out = new(int32)
+		**out = **in
+	}
+	out.MemoryOffHeapMin = in.MemoryOffHeapMin.DeepCopy()
+	if in.MemoryProcessRatio != nil {
+		in, out := &in.MemoryProcessRatio, &out.MemoryProcessRatio
+		*out = new(int32)
+		**out = **in
+	}
+	if in.JVMOptions != nil {
+		in, out := &in.JVMOptions, &out.JVMOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeClaimTemplates != nil {
+		in, out := &in.VolumeClaimTemplates, &out.VolumeClaimTemplates
+		*out = make([]v1.PersistentVolumeClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(JobManagerStorageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(v1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PodLabels != nil {
+		in, out := &in.PodLabels, &out.PodLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostAliases != nil {
+		in, out := &in.HostAliases, &out.HostAliases
+		*out = make([]v1.HostAlias, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerSpec.
+func (in *JobManagerSpec) DeepCopy() *JobManagerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JobManagerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobManagerStatus) DeepCopyInto(out *JobManagerStatus) {
+	*out = *in
+	if in.LastTermination != nil {
+		in, out := &in.LastTermination, &out.LastTermination
+		*out = new(ContainerTerminationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerStatus.
+func (in *JobManagerStatus) DeepCopy() *JobManagerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JobManagerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobManagerStorageSpec) DeepCopyInto(out *JobManagerStorageSpec) {
+	*out = *in
+	if in.RetentionPolicy != nil {
+		in, out := &in.RetentionPolicy, &out.RetentionPolicy
+		*out = new(StorageRetentionPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobManagerStorageSpec.
+func (in *JobManagerStorageSpec) DeepCopy() *JobManagerStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JobManagerStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobPlanArchive) DeepCopyInto(out *JobPlanArchive) {
+	*out = *in
+	if in.Upload != nil {
+		in, out := &in.Upload, &out.Upload
+		*out = new(DiagnosticsUpload)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobPlanArchive.
+func (in *JobPlanArchive) DeepCopy() *JobPlanArchive {
+	if in == nil {
+		return nil
+	}
+	out := new(JobPlanArchive)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobPlanStatus) DeepCopyInto(out *JobPlanStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobPlanStatus.
+func (in *JobPlanStatus) DeepCopy() *JobPlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JobPlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobReadinessGate) DeepCopyInto(out *JobReadinessGate) {
+	*out = *in
+	if in.HTTPGet != nil {
+		in, out := &in.HTTPGet, &out.HTTPGet
+		*out = new(HTTPReadinessGate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KafkaTopic != nil {
+		in, out := &in.KafkaTopic, &out.KafkaTopic
+		*out = new(KafkaTopicReadinessGate)
+		**out = **in
+	}
+	if in.ConfigMapKey != nil {
+		in, out := &in.ConfigMapKey, &out.ConfigMapKey
+		*out = new(ConfigMapKeyReadinessGate)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobReadinessGate.
+func (in *JobReadinessGate) DeepCopy() *JobReadinessGate {
+	if in == nil {
+		return nil
+	}
+	out := new(JobReadinessGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobReadinessGateStatus) DeepCopyInto(out *JobReadinessGateStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobReadinessGateStatus.
+func (in *JobReadinessGateStatus) DeepCopy() *JobReadinessGateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JobReadinessGateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobRunStatus) DeepCopyInto(out *JobRunStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobRunStatus.
+func (in *JobRunStatus) DeepCopy() *JobRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JobRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobSLO) DeepCopyInto(out *JobSLO) {
+	*out = *in
+	if in.MaxCheckpointAgeSeconds != nil {
+		in, out := &in.MaxCheckpointAgeSeconds, &out.MaxCheckpointAgeSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxRestartRatePerHour != nil {
+		in, out := &in.MaxRestartRatePerHour, &out.MaxRestartRatePerHour
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxConsumerLag != nil {
+		in, out := &in.MaxConsumerLag, &out.MaxConsumerLag
+		*out = new(ConsumerLagObjective)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobSLO.
+func (in *JobSLO) DeepCopy() *JobSLO {
+	if in == nil {
+		return nil
+	}
+	out := new(JobSLO)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobSLOStatus) DeepCopyInto(out *JobSLOStatus) {
+	*out = *in
+	if in.Violations != nil {
+		in, out := &in.Violations, &out.Violations
+		*out = make([]JobSLOViolation, len(*in))
+		copy(*out, *in)
+	}
+	if in.RestartTimes != nil {
+		in, out := &in.RestartTimes, &out.RestartTimes
+		*out = make([]metav1.Time, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ObservedNumRestarts != nil {
+		in, out := &in.ObservedNumRestarts, &out.ObservedNumRestarts
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobSLOStatus.
+func (in *JobSLOStatus) DeepCopy() *JobSLOStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JobSLOStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobSLOViolation) DeepCopyInto(out *JobSLOViolation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobSLOViolation.
+func (in *JobSLOViolation) DeepCopy() *JobSLOViolation {
+	if in == nil {
+		return nil
+	}
+	out := new(JobSLOViolation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobSpec) DeepCopyInto(out *JobSpec) {
+	*out = *in
+	if in.ClassPath != nil {
+		in, out := &in.ClassPath, &out.ClassPath
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.JarFile != nil {
+		in, out := &in.JarFile, &out.JarFile
+		*out = new(string)
+		**out = **in
+	}
+	if in.MavenRepository != nil {
+		in, out := &in.MavenRepository, &out.MavenRepository
+		*out = new(MavenRepositorySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ArtifactCache != nil {
+		in, out := &in.ArtifactCache, &out.ArtifactCache
+		*out = new(ArtifactCacheSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitRepo != nil {
+		in, out := &in.GitRepo, &out.GitRepo
+		*out = new(GitRepoSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClassName != nil {
+		in, out := &in.ClassName, &out.ClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.PyFile != nil {
+		in, out := &in.PyFile, &out.PyFile
+		*out = new(string)
+		**out = **in
+	}
+	if in.PyFiles != nil {
+		in, out := &in.PyFiles, &out.PyFiles
+		*out = new(string)
+		**out = **in
+	}
+	if in.PyModule != nil {
+		in, out := &in.PyModule, &out.PyModule
+		*out = new(string)
+		**out = **in
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ArgsFrom != nil {
+		in, out := &in.ArgsFrom, &out.ArgsFrom
+		*out = make([]JobArgSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FromSavepoint != nil {
+		in, out := &in.FromSavepoint, &out.FromSavepoint
+		*out = new(string)
+		**out = **in
+	}
+	if in.AllowNonRestoredState != nil {
+		in, out := &in.AllowNonRestoredState, &out.AllowNonRestoredState
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SavepointsDir != nil {
+		in, out := &in.SavepointsDir, &out.SavepointsDir
+		*out = new(string)
+		**out = **in
+	}
+	if in.TakeSavepointOnUpdate != nil {
+		in, out := &in.TakeSavepointOnUpdate, &out.TakeSavepointOnUpdate
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UpdateStopMode != nil {
+		in, out := &in.UpdateStopMode, &out.UpdateStopMode
+		*out = new(JobUpdateStopMode)
+		**out = **in
+	}
+	if in.SavepointTriggerMode != nil {
+		in, out := &in.SavepointTriggerMode, &out.SavepointTriggerMode
+		*out = new(SavepointTriggerMode)
+		**out = **in
+	}
+	if in.AllBlockingShuffle != nil {
+		in, out := &in.AllBlockingShuffle, &out.AllBlockingShuffle
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxStateAgeToRestoreSeconds != nil {
+		in, out := &in.MaxStateAgeToRestoreSeconds, &out.MaxStateAgeToRestoreSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SavepointOwnership != nil {
+		in, out := &in.SavepointOwnership, &out.SavepointOwnership
+		*out = new(SavepointOwnership)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageProbe != nil {
+		in, out := &in.StorageProbe, &out.StorageProbe
+		*out = new(StorageProbe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutoSavepointSeconds != nil {
+		in, out := &in.AutoSavepointSeconds, &out.AutoSavepointSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AdaptiveSavepoint != nil {
+		in, out := &in.AdaptiveSavepoint, &out.AdaptiveSavepoint
+		*out = new(AdaptiveSavepointSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Parallelism != nil {
+		in, out := &in.Parallelism, &out.Parallelism
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NoLoggingToStdout != nil {
+		in, out := &in.NoLoggingToStdout, &out.NoLoggingToStdout
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RestartPolicy != nil {
+		in, out := &in.RestartPolicy, &out.RestartPolicy
+		*out = new(JobRestartPolicy)
+		**out = **in
+	}
+	if in.RunHistoryLimit != nil {
+		in, out := &in.RunHistoryLimit, &out.RunHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CleanupPolicy != nil {
+		in, out := &in.CleanupPolicy, &out.CleanupPolicy
+		*out = new(CleanupPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SuccessPolicy != nil {
+		in, out := &in.SuccessPolicy, &out.SuccessPolicy
+		*out = new(JobSuccessPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RestoreVerification != nil {
+		in, out := &in.RestoreVerification, &out.RestoreVerification
+		*out = new(RestoreVerification)
+		**out = **in
+	}
+	if in.SLO != nil {
+		in, out := &in.SLO, &out.SLO
+		*out = new(JobSLO)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PlanArchive != nil {
+		in, out := &in.PlanArchive, &out.PlanArchive
+		*out = new(JobPlanArchive)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessGates != nil {
+		in, out := &in.ReadinessGates, &out.ReadinessGates
+		*out = make([]JobReadinessGate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SubmitterTTLSecondsAfterFinished != nil {
+		in, out := &in.SubmitterTTLSecondsAfterFinished, &out.SubmitterTTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CancelRequested != nil {
+		in, out := &in.CancelRequested, &out.CancelRequested
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodLabels != nil {
+		in, out := &in.PodLabels, &out.PodLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(v1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.HostAliases != nil {
+		in, out := &in.HostAliases, &out.HostAliases
+		*out = make([]v1.HostAlias, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(JobMode)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobSpec.
+func (in *JobSpec) DeepCopy() *JobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobStatus) DeepCopyInto(out *JobStatus) {
+	*out = *in
+	if in.Runs != nil {
+		in, out := &in.Runs, &out.Runs
+		*out = make([]JobRunStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FailureReasons != nil {
+		in, out := &in.FailureReasons, &out.FailureReasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RestoreVerification != nil {
+		in, out := &in.RestoreVerification, &out.RestoreVerification
+		*out = new(RestoreVerificationStatus)
+		**out = **in
+	}
+	if in.SLO != nil {
+		in, out := &in.SLO, &out.SLO
+		*out = new(JobSLOStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdaptiveSavepoint != nil {
+		in, out := &in.AdaptiveSavepoint, &out.AdaptiveSavepoint
+		*out = new(AdaptiveSavepointStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = new(JobPlanStatus)
+		**out = **in
+	}
+	if in.SavepointOwnership != nil {
+		in, out := &in.SavepointOwnership, &out.SavepointOwnership
+		*out = new(SavepointOwnershipStatus)
+		**out = **in
+	}
+	if in.Vertices != nil {
+		in, out := &in.Vertices, &out.Vertices
+		*out = make([]JobVertexStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BlockingReadinessGate != nil {
+		in, out := &in.BlockingReadinessGate, &out.BlockingReadinessGate
+		*out = new(JobReadinessGateStatus)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobStatus.
+func (in *JobStatus) DeepCopy() *JobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobSuccessPolicy) DeepCopyInto(out *JobSuccessPolicy) {
+	*out = *in
+	if in.Accumulator != nil {
+		in, out := &in.Accumulator, &out.Accumulator
+		*out = new(JobAccumulatorPredicate)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobSuccessPolicy.
+func (in *JobSuccessPolicy) DeepCopy() *JobSuccessPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(JobSuccessPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaLagSpec) DeepCopyInto(out *KafkaLagSpec) {
+	*out = *in
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaLagSpec.
+func (in *KafkaLagSpec) DeepCopy() *KafkaLagSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaLagSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaTopicReadinessGate) DeepCopyInto(out *KafkaTopicReadinessGate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopicReadinessGate.
+func (in *KafkaTopicReadinessGate) DeepCopy() *KafkaTopicReadinessGate {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaTopicReadinessGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobVertexStatus) DeepCopyInto(out *JobVertexStatus) {
+	*out = *in
+	if in.MaxBusyPercent != nil {
+		in, out := &in.MaxBusyPercent, &out.MaxBusyPercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobVertexStatus.
+func (in *JobVertexStatus) DeepCopy() *JobVertexStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JobVertexStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeepLastFinishedPolicy) DeepCopyInto(out *KeepLastFinishedPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeepLastFinishedPolicy.
+func (in *KeepLastFinishedPolicy) DeepCopy() *KeepLastFinishedPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(KeepLastFinishedPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogSidecarSpec) DeepCopyInto(out *LogSidecarSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogSidecarSpec.
+func (in *LogSidecarSpec) DeepCopy() *LogSidecarSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogSidecarSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingSpec) DeepCopyInto(out *LoggingSpec) {
+	*out = *in
+	if in.Sidecar != nil {
+		in, out := &in.Sidecar, &out.Sidecar
+		*out = new(LogSidecarSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingSpec.
+func (in *LoggingSpec) DeepCopy() *LoggingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MavenRepositorySpec) DeepCopyInto(out *MavenRepositorySpec) {
+	*out = *in
+	if in.URL != nil {
+		in, out := &in.URL, &out.URL
+		*out = new(string)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MavenRepositorySpec.
+func (in *MavenRepositorySpec) DeepCopy() *MavenRepositorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MavenRepositorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsEndpoint) DeepCopyInto(out *MetricsEndpoint) {
+	*out = *in
+	if in.URLs != nil {
+		in, out := &in.URLs, &out.URLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsEndpoint.
+func (in *MetricsEndpoint) DeepCopy() *MetricsEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsReporter) DeepCopyInto(out *MetricsReporter) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(string)
+		**out = **in
+	}
+	if in.Prometheus != nil {
+		in, out := &in.Prometheus, &out.Prometheus
+		*out = new(PrometheusReporter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Datadog != nil {
+		in, out := &in.Datadog, &out.Datadog
+		*out = new(DatadogReporter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StatsD != nil {
+		in, out := &in.StatsD, &out.StatsD
+		*out = new(StatsDReporter)
+		**out = **in
 	}
-	if in.ClassName != nil {
-		in, out := &in.ClassName, &out.ClassName
-		*out = new(string)
+	if in.Slf4j != nil {
+		in, out := &in.Slf4j, &out.Slf4j
+		*out = new(Slf4jReporter)
 		**out = **in
 	}
-	if in.PyFile != nil {
-		in, out := &in.PyFile, &out.PyFile
-		*out = new(string)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsReporter.
+func (in *MetricsReporter) DeepCopy() *MetricsReporter {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsReporter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+	*out = *in
+	if in.ExposeTaskManagerMetrics != nil {
+		in, out := &in.ExposeTaskManagerMetrics, &out.ExposeTaskManagerMetrics
+		*out = new(bool)
 		**out = **in
 	}
-	if in.PyFiles != nil {
-		in, out := &in.PyFiles, &out.PyFiles
-		*out = new(string)
+	if in.PrometheusAnnotations != nil {
+		in, out := &in.PrometheusAnnotations, &out.PrometheusAnnotations
+		*out = new(bool)
 		**out = **in
 	}
-	if in.PyModule != nil {
-		in, out := &in.PyModule, &out.PyModule
-		*out = new(string)
+	if in.Reporters != nil {
+		in, out := &in.Reporters, &out.Reporters
+		*out = make([]MetricsReporter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.KafkaLag != nil {
+		in, out := &in.KafkaLag, &out.KafkaLag
+		*out = new(KafkaLagSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CheckpointMetrics != nil {
+		in, out := &in.CheckpointMetrics, &out.CheckpointMetrics
+		*out = new(bool)
 		**out = **in
 	}
-	if in.Args != nil {
-		in, out := &in.Args, &out.Args
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
+func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+	if in == nil {
+		return nil
 	}
-	if in.FromSavepoint != nil {
-		in, out := &in.FromSavepoint, &out.FromSavepoint
+	out := new(MonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamedPort) DeepCopyInto(out *NamedPort) {
+	*out = *in
+	if in.AppProtocol != nil {
+		in, out := &in.AppProtocol, &out.AppProtocol
 		*out = new(string)
 		**out = **in
 	}
-	if in.AllowNonRestoredState != nil {
-		in, out := &in.AllowNonRestoredState, &out.AllowNonRestoredState
-		*out = new(bool)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamedPort.
+func (in *NamedPort) DeepCopy() *NamedPort {
+	if in == nil {
+		return nil
+	}
+	out := new(NamedPort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkingSpec) DeepCopyInto(out *NetworkingSpec) {
+	*out = *in
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(CABundleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IPFamilyPolicy != nil {
+		in, out := &in.IPFamilyPolicy, &out.IPFamilyPolicy
+		*out = new(v1.IPFamilyPolicy)
 		**out = **in
 	}
-	if in.SavepointsDir != nil {
-		in, out := &in.SavepointsDir, &out.SavepointsDir
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]v1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterDomain != nil {
+		in, out := &in.ClusterDomain, &out.ClusterDomain
 		*out = new(string)
 		**out = **in
 	}
-	if in.TakeSavepointOnUpdate != nil {
-		in, out := &in.TakeSavepointOnUpdate, &out.TakeSavepointOnUpdate
-		*out = new(bool)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkingSpec.
+func (in *NetworkingSpec) DeepCopy() *NetworkingSpec {
+	if in == nil {
+		return nil
 	}
-	if in.MaxStateAgeToRestoreSeconds != nil {
-		in, out := &in.MaxStateAgeToRestoreSeconds, &out.MaxStateAgeToRestoreSeconds
+	out := new(NetworkingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2ProxySpec) DeepCopyInto(out *OAuth2ProxySpec) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
 		*out = new(int32)
 		**out = **in
 	}
-	if in.AutoSavepointSeconds != nil {
-		in, out := &in.AutoSavepointSeconds, &out.AutoSavepointSeconds
+	in.ClientIDSecretRef.DeepCopyInto(&out.ClientIDSecretRef)
+	in.ClientSecretSecretRef.DeepCopyInto(&out.ClientSecretSecretRef)
+	in.CookieSecretSecretRef.DeepCopyInto(&out.CookieSecretSecretRef)
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2ProxySpec.
+func (in *OAuth2ProxySpec) DeepCopy() *OAuth2ProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2ProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusReporter) DeepCopyInto(out *PrometheusReporter) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
 		*out = new(int32)
 		**out = **in
 	}
-	if in.Parallelism != nil {
-		in, out := &in.Parallelism, &out.Parallelism
-		*out = new(int32)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusReporter.
+func (in *PrometheusReporter) DeepCopy() *PrometheusReporter {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusReporter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxySpec) DeepCopyInto(out *ProxySpec) {
+	*out = *in
+	if in.HTTPProxy != nil {
+		in, out := &in.HTTPProxy, &out.HTTPProxy
+		*out = new(string)
 		**out = **in
 	}
-	if in.NoLoggingToStdout != nil {
-		in, out := &in.NoLoggingToStdout, &out.NoLoggingToStdout
-		*out = new(bool)
+	if in.HTTPSProxy != nil {
+		in, out := &in.HTTPSProxy, &out.HTTPSProxy
+		*out = new(string)
 		**out = **in
 	}
-	if in.Volumes != nil {
-		in, out := &in.Volumes, &out.Volumes
-		*out = make([]v1.Volume, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.NoProxy != nil {
+		in, out := &in.NoProxy, &out.NoProxy
+		*out = new(string)
+		**out = **in
 	}
-	if in.VolumeMounts != nil {
-		in, out := &in.VolumeMounts, &out.VolumeMounts
-		*out = make([]v1.VolumeMount, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxySpec.
+func (in *ProxySpec) DeepCopy() *ProxySpec {
+	if in == nil {
+		return nil
 	}
-	if in.InitContainers != nil {
-		in, out := &in.InitContainers, &out.InitContainers
-		*out = make([]v1.Container, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	out := new(ProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReconcileErrorStatus) DeepCopyInto(out *ReconcileErrorStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconcileErrorStatus.
+func (in *ReconcileErrorStatus) DeepCopy() *ReconcileErrorStatus {
+	if in == nil {
+		return nil
 	}
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
-		(*in).DeepCopyInto(*out)
+	out := new(ReconcileErrorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreVerification) DeepCopyInto(out *RestoreVerification) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerification.
+func (in *RestoreVerification) DeepCopy() *RestoreVerification {
+	if in == nil {
+		return nil
 	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
+	out := new(RestoreVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreVerificationStatus) DeepCopyInto(out *RestoreVerificationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerificationStatus.
+func (in *RestoreVerificationStatus) DeepCopy() *RestoreVerificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreVerificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevisionStatus) DeepCopyInto(out *RevisionStatus) {
+	*out = *in
+	if in.CollisionCount != nil {
+		in, out := &in.CollisionCount, &out.CollisionCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UpdateFailure != nil {
+		in, out := &in.UpdateFailure, &out.UpdateFailure
+		*out = new(UpdateFailureStatus)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevisionStatus.
+func (in *RevisionStatus) DeepCopy() *RevisionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RevisionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SavepointOwnership) DeepCopyInto(out *SavepointOwnership) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.VerifyRestore != nil {
+		in, out := &in.VerifyRestore, &out.VerifyRestore
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AuthorizationSecret != nil {
+		in, out := &in.AuthorizationSecret, &out.AuthorizationSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavepointOwnership.
+func (in *SavepointOwnership) DeepCopy() *SavepointOwnership {
+	if in == nil {
+		return nil
+	}
+	out := new(SavepointOwnership)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SavepointOwnershipStatus) DeepCopyInto(out *SavepointOwnershipStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavepointOwnershipStatus.
+func (in *SavepointOwnershipStatus) DeepCopy() *SavepointOwnershipStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SavepointOwnershipStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SavepointStatus) DeepCopyInto(out *SavepointStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavepointStatus.
+func (in *SavepointStatus) DeepCopy() *SavepointStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SavepointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretInjection) DeepCopyInto(out *SecretInjection) {
+	*out = *in
+	in.SecretKeyRef.DeepCopyInto(&out.SecretKeyRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretInjection.
+func (in *SecretInjection) DeepCopy() *SecretInjection {
+	if in == nil {
+		return nil
 	}
-	if in.RestartPolicy != nil {
-		in, out := &in.RestartPolicy, &out.RestartPolicy
-		*out = new(JobRestartPolicy)
-		**out = **in
+	out := new(SecretInjection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionJar) DeepCopyInto(out *SessionJar) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionJar.
+func (in *SessionJar) DeepCopy() *SessionJar {
+	if in == nil {
+		return nil
 	}
-	if in.CleanupPolicy != nil {
-		in, out := &in.CleanupPolicy, &out.CleanupPolicy
-		*out = new(CleanupPolicy)
-		**out = **in
+	out := new(SessionJar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionJarStatus) DeepCopyInto(out *SessionJarStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionJarStatus.
+func (in *SessionJarStatus) DeepCopy() *SessionJarStatus {
+	if in == nil {
+		return nil
 	}
-	if in.CancelRequested != nil {
-		in, out := &in.CancelRequested, &out.CancelRequested
-		*out = new(bool)
-		**out = **in
+	out := new(SessionJarStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Slf4jReporter) DeepCopyInto(out *Slf4jReporter) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Slf4jReporter.
+func (in *Slf4jReporter) DeepCopy() *Slf4jReporter {
+	if in == nil {
+		return nil
 	}
-	if in.PodAnnotations != nil {
-		in, out := &in.PodAnnotations, &out.PodAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	out := new(Slf4jReporter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlotResources) DeepCopyInto(out *SlotResources) {
+	*out = *in
+	out.CPUCores = in.CPUCores.DeepCopy()
+	out.TaskHeapMemory = in.TaskHeapMemory.DeepCopy()
+	out.TaskOffHeapMemory = in.TaskOffHeapMemory.DeepCopy()
+	out.ManagedMemory = in.ManagedMemory.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlotResources.
+func (in *SlotResources) DeepCopy() *SlotResources {
+	if in == nil {
+		return nil
 	}
-	if in.PodLabels != nil {
-		in, out := &in.PodLabels, &out.PodLabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	out := new(SlotResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateEncryptionSpec) DeepCopyInto(out *StateEncryptionSpec) {
+	*out = *in
+	if in.AWSKMS != nil {
+		in, out := &in.AWSKMS, &out.AWSKMS
+		*out = new(AWSKMSEncryptionSpec)
+		(*in).DeepCopyInto(*out)
 	}
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.SecurityContext != nil {
-		in, out := &in.SecurityContext, &out.SecurityContext
-		*out = new(v1.PodSecurityContext)
+	if in.GCSCustomerKey != nil {
+		in, out := &in.GCSCustomerKey, &out.GCSCustomerKey
+		*out = new(GCSCustomerKeySpec)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.HostAliases != nil {
-		in, out := &in.HostAliases, &out.HostAliases
-		*out = make([]v1.HostAlias, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateEncryptionSpec.
+func (in *StateEncryptionSpec) DeepCopy() *StateEncryptionSpec {
+	if in == nil {
+		return nil
 	}
-	if in.Mode != nil {
-		in, out := &in.Mode, &out.Mode
-		*out = new(JobMode)
-		**out = **in
+	out := new(StateEncryptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateExportSpec) DeepCopyInto(out *StateExportSpec) {
+	*out = *in
+	in.Upload.DeepCopyInto(&out.Upload)
+	if in.SigningKeySecret != nil {
+		in, out := &in.SigningKeySecret, &out.SigningKeySecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobSpec.
-func (in *JobSpec) DeepCopy() *JobSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateExportSpec.
+func (in *StateExportSpec) DeepCopy() *StateExportSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(JobSpec)
+	out := new(StateExportSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JobStatus) DeepCopyInto(out *JobStatus) {
+func (in *StateExportFailure) DeepCopyInto(out *StateExportFailure) {
 	*out = *in
-	if in.CompletionTime != nil {
-		in, out := &in.CompletionTime, &out.CompletionTime
-		*out = (*in).DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateExportFailure.
+func (in *StateExportFailure) DeepCopy() *StateExportFailure {
+	if in == nil {
+		return nil
 	}
-	if in.FailureReasons != nil {
-		in, out := &in.FailureReasons, &out.FailureReasons
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out := new(StateExportFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateExportStatus) DeepCopyInto(out *StateExportStatus) {
+	*out = *in
+	if in.LastFailure != nil {
+		in, out := &in.LastFailure, &out.LastFailure
+		*out = new(StateExportFailure)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobStatus.
-func (in *JobStatus) DeepCopy() *JobStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateExportStatus.
+func (in *StateExportStatus) DeepCopy() *StateExportStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(JobStatus)
+	out := new(StateExportStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NamedPort) DeepCopyInto(out *NamedPort) {
+func (in *StatsDReporter) DeepCopyInto(out *StatsDReporter) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamedPort.
-func (in *NamedPort) DeepCopy() *NamedPort {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatsDReporter.
+func (in *StatsDReporter) DeepCopy() *StatsDReporter {
 	if in == nil {
 		return nil
 	}
-	out := new(NamedPort)
+	out := new(StatsDReporter)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RevisionStatus) DeepCopyInto(out *RevisionStatus) {
+func (in *StorageProbe) DeepCopyInto(out *StorageProbe) {
 	*out = *in
-	if in.CollisionCount != nil {
-		in, out := &in.CollisionCount, &out.CollisionCount
-		*out = new(int32)
+	if in.AuthorizationSecret != nil {
+		in, out := &in.AuthorizationSecret, &out.AuthorizationSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageProbe.
+func (in *StorageProbe) DeepCopy() *StorageProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskManagerArtifact) DeepCopyInto(out *TaskManagerArtifact) {
+	*out = *in
+	if in.FileName != nil {
+		in, out := &in.FileName, &out.FileName
+		*out = new(string)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevisionStatus.
-func (in *RevisionStatus) DeepCopy() *RevisionStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskManagerArtifact.
+func (in *TaskManagerArtifact) DeepCopy() *TaskManagerArtifact {
 	if in == nil {
 		return nil
 	}
-	out := new(RevisionStatus)
+	out := new(TaskManagerArtifact)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SavepointStatus) DeepCopyInto(out *SavepointStatus) {
+func (in *TaskManagerDecommissionStatus) DeepCopyInto(out *TaskManagerDecommissionStatus) {
 	*out = *in
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavepointStatus.
-func (in *SavepointStatus) DeepCopy() *SavepointStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskManagerDecommissionStatus.
+func (in *TaskManagerDecommissionStatus) DeepCopy() *TaskManagerDecommissionStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SavepointStatus)
+	out := new(TaskManagerDecommissionStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -968,6 +3178,13 @@ func (in *TaskManagerPorts) DeepCopyInto(out *TaskManagerPorts) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.AppProtocols != nil {
+		in, out := &in.AppProtocols, &out.AppProtocols
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskManagerPorts.
@@ -980,6 +3197,26 @@ func (in *TaskManagerPorts) DeepCopy() *TaskManagerPorts {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskManagerRegistrationWatchdog) DeepCopyInto(out *TaskManagerRegistrationWatchdog) {
+	*out = *in
+	if in.RestartPods != nil {
+		in, out := &in.RestartPods, &out.RestartPods
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskManagerRegistrationWatchdog.
+func (in *TaskManagerRegistrationWatchdog) DeepCopy() *TaskManagerRegistrationWatchdog {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskManagerRegistrationWatchdog)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskManagerSpec) DeepCopyInto(out *TaskManagerSpec) {
 	*out = *in
@@ -988,11 +3225,28 @@ func (in *TaskManagerSpec) DeepCopyInto(out *TaskManagerSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.TaskSlots != nil {
+		in, out := &in.TaskSlots, &out.TaskSlots
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SlotsPerCPU != nil {
+		in, out := &in.SlotsPerCPU, &out.SlotsPerCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(bool)
+		**out = **in
+	}
 	in.Ports.DeepCopyInto(&out.Ports)
 	if in.ExtraPorts != nil {
 		in, out := &in.ExtraPorts, &out.ExtraPorts
 		*out = make([]NamedPort, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
 	if in.MemoryOffHeapRatio != nil {
@@ -1006,6 +3260,16 @@ func (in *TaskManagerSpec) DeepCopyInto(out *TaskManagerSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.JVMOptions != nil {
+		in, out := &in.JVMOptions, &out.JVMOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SlotResources != nil {
+		in, out := &in.SlotResources, &out.SlotResources
+		*out = new(SlotResources)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Volumes != nil {
 		in, out := &in.Volumes, &out.Volumes
 		*out = make([]v1.Volume, len(*in))
@@ -1034,6 +3298,13 @@ func (in *TaskManagerSpec) DeepCopyInto(out *TaskManagerSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ExtraArtifacts != nil {
+		in, out := &in.ExtraArtifacts, &out.ExtraArtifacts
+		*out = make([]TaskManagerArtifact, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
 		*out = new(v1.Affinity)
@@ -1072,6 +3343,21 @@ func (in *TaskManagerSpec) DeepCopyInto(out *TaskManagerSpec) {
 		*out = new(v1.PodSecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DecommissionTimeoutSeconds != nil {
+		in, out := &in.DecommissionTimeoutSeconds, &out.DecommissionTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RegistrationWatchdog != nil {
+		in, out := &in.RegistrationWatchdog, &out.RegistrationWatchdog
+		*out = new(TaskManagerRegistrationWatchdog)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PodLabels != nil {
 		in, out := &in.PodLabels, &out.PodLabels
 		*out = make(map[string]string, len(*in))
@@ -1116,6 +3402,11 @@ func (in *TaskManagerSpec) DeepCopy() *TaskManagerSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskManagerStatus) DeepCopyInto(out *TaskManagerStatus) {
 	*out = *in
+	if in.LastTermination != nil {
+		in, out := &in.LastTermination, &out.LastTermination
+		*out = new(ContainerTerminationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskManagerStatus.
@@ -1129,16 +3420,81 @@ func (in *TaskManagerStatus) DeepCopy() *TaskManagerStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Validator) DeepCopyInto(out *Validator) {
+func (in *UpdateFailureStatus) DeepCopyInto(out *UpdateFailureStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateFailureStatus.
+func (in *UpdateFailureStatus) DeepCopy() *UpdateFailureStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateFailureStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdatePolicy) DeepCopyInto(out *UpdatePolicy) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(UpdateWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DebounceSeconds != nil {
+		in, out := &in.DebounceSeconds, &out.DebounceSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IgnoreFields != nil {
+		in, out := &in.IgnoreFields, &out.IgnoreFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdatePolicy.
+func (in *UpdatePolicy) DeepCopy() *UpdatePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdatePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateProgressStatus) DeepCopyInto(out *UpdateProgressStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateProgressStatus.
+func (in *UpdateProgressStatus) DeepCopy() *UpdateProgressStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateProgressStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateWindow) DeepCopyInto(out *UpdateWindow) {
 	*out = *in
+	if in.Timezone != nil {
+		in, out := &in.Timezone, &out.Timezone
+		*out = new(string)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Validator.
-func (in *Validator) DeepCopy() *Validator {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateWindow.
+func (in *UpdateWindow) DeepCopy() *UpdateWindow {
 	if in == nil {
 		return nil
 	}
-	out := new(Validator)
+	out := new(UpdateWindow)
 	in.DeepCopyInto(out)
 	return out
 }
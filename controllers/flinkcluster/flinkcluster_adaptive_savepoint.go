@@ -0,0 +1,175 @@
+package flinkcluster
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The taint the cluster autoscaler puts on the nodes it is about to delete.
+const clusterAutoscalerDeletionTaint = "ToBeDeletedByClusterAutoscaler"
+
+func isStateDeltaTracked(jobSpec *v1beta1.JobSpec) bool {
+	return jobSpec != nil && jobSpec.AdaptiveSavepoint != nil && jobSpec.AdaptiveSavepoint.StateDeltaThreshold != nil
+}
+
+func isNodeDrainTracked(jobSpec *v1beta1.JobSpec) bool {
+	return jobSpec != nil && jobSpec.AdaptiveSavepoint != nil &&
+		jobSpec.AdaptiveSavepoint.BeforeNodeDrain != nil && *jobSpec.AdaptiveSavepoint.BeforeNodeDrain
+}
+
+// isNodeDraining returns true if the node is cordoned, e.g. by `kubectl drain`, or is about
+// to be deleted by the cluster autoscaler.
+func isNodeDraining(node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return true
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == clusterAutoscalerDeletionTaint {
+			return true
+		}
+	}
+	return false
+}
+
+// Observes the nodes running the JobManager and TaskManager pods which are being drained, if
+// spec.job.adaptiveSavepoint.beforeNodeDrain is enabled.
+func (observer *ClusterStateObserver) observeDrainingNodes(ctx context.Context, observed *ObservedClusterState) {
+	var log = logr.FromContextOrDiscard(ctx)
+	if !isNodeDrainTracked(observed.cluster.Spec.Job) {
+		return
+	}
+
+	var nodeNames = map[string]bool{}
+	for _, pod := range append(append([]corev1.Pod(nil), observed.jmPods...), observed.tmPods...) {
+		if pod.Spec.NodeName != "" {
+			nodeNames[pod.Spec.NodeName] = true
+		}
+	}
+	observed.drainingNodes = nil
+	for name := range nodeNames {
+		node, err := observer.k8sClientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			log.Info("Failed to get node", "node", name, "error", err)
+			continue
+		}
+		if isNodeDraining(node) {
+			observed.drainingNodes = append(observed.drainingNodes, name)
+		}
+	}
+	sort.Strings(observed.drainingNodes)
+}
+
+// deriveAdaptiveSavepointStatus adds the checkpointed size of the checkpoints completed since
+// the last counted one to the state delta, which is reset when a savepoint completes, and
+// records the time a node of the cluster was first observed being drained. The checkpointed
+// size is the incremental size of the checkpoint since Flink 1.15, the state size reported
+// before is counted as is.
+func deriveAdaptiveSavepointStatus(
+	jobSpec *v1beta1.JobSpec,
+	recorded *v1beta1.AdaptiveSavepointStatus,
+	checkpoints *flink.JobCheckpoints,
+	drainingNodes []string,
+	savepointCompleted bool,
+	now time.Time) *v1beta1.AdaptiveSavepointStatus {
+	if jobSpec == nil || jobSpec.AdaptiveSavepoint == nil {
+		return nil
+	}
+	var status = recorded.DeepCopy()
+	if status == nil {
+		status = new(v1beta1.AdaptiveSavepointStatus)
+	}
+
+	if savepointCompleted {
+		status.StateDeltaBytes = 0
+	}
+	if isStateDeltaTracked(jobSpec) && checkpoints != nil {
+		// The checkpoint IDs start over when the job is submitted without a savepoint.
+		if latest := checkpoints.Latest.Completed; latest != nil && latest.ID < status.LastCheckpointID {
+			status.LastCheckpointID = 0
+		}
+		var lastID = status.LastCheckpointID
+		for _, checkpoint := range checkpoints.History {
+			if checkpoint.Status != "COMPLETED" || checkpoint.IsSavepoint || checkpoint.ID <= status.LastCheckpointID {
+				continue
+			}
+			// An unchanged state has a checkpointed size of 0, which is counted as such. Flink
+			// before 1.15 reports only the state size.
+			var size = checkpoint.StateSize
+			if checkpoint.CheckpointedSize != nil {
+				size = *checkpoint.CheckpointedSize
+			}
+			status.StateDeltaBytes += size
+			if checkpoint.ID > lastID {
+				lastID = checkpoint.ID
+			}
+		}
+		status.LastCheckpointID = lastID
+	}
+
+	if isNodeDrainTracked(jobSpec) {
+		var known = map[string]bool{}
+		for _, name := range status.DrainingNodes {
+			known[name] = true
+		}
+		for _, name := range drainingNodes {
+			if !known[name] {
+				status.DrainStartTime = now.Format(time.RFC3339)
+			}
+		}
+		status.DrainingNodes = append([]string(nil), drainingNodes...)
+		if len(drainingNodes) == 0 {
+			status.DrainStartTime = ""
+		}
+	} else {
+		status.DrainingNodes = nil
+		status.DrainStartTime = ""
+	}
+	return status
+}
+
+// getAdaptiveSavepointReason returns the reason to take a savepoint for
+// spec.job.adaptiveSavepoint, empty if none is due. The savepoint is taken before the
+// drained nodes are deleted, or once the state delta exceeds the threshold, but not within
+// minIntervalSeconds after the last savepoint.
+func getAdaptiveSavepointReason(
+	jobSpec *v1beta1.JobSpec,
+	job *v1beta1.JobStatus,
+	savepoint *v1beta1.SavepointStatus,
+	now time.Time) v1beta1.SavepointReason {
+	var policy = jobSpec.AdaptiveSavepoint
+	var status = job.AdaptiveSavepoint
+	if policy == nil || status == nil {
+		return ""
+	}
+
+	// When the previous try failed, wait for the retry interval.
+	if savepoint.IsFailed() && (savepoint.TriggerReason == v1beta1.SavepointReasonStateDelta ||
+		savepoint.TriggerReason == v1beta1.SavepointReasonNodeDrain) &&
+		!hasTimeElapsed(savepoint.UpdateTime, now, SavepointRetryIntervalSeconds) {
+		return ""
+	}
+	var lastTime = job.SavepointTime
+	if lastTime == "" {
+		lastTime = job.StartTime
+	}
+	if lastTime != "" && !hasTimeElapsed(lastTime, now, int(policy.MinIntervalSeconds)) {
+		return ""
+	}
+
+	if isNodeDrainTracked(jobSpec) && status.DrainStartTime != "" &&
+		(job.SavepointTime == "" || util.GetTime(job.SavepointTime).Before(util.GetTime(status.DrainStartTime))) {
+		return v1beta1.SavepointReasonNodeDrain
+	}
+	if isStateDeltaTracked(jobSpec) && status.StateDeltaBytes >= policy.StateDeltaThreshold.Value() {
+		return v1beta1.SavepointReasonStateDelta
+	}
+	return ""
+}
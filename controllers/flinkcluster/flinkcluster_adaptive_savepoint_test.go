@@ -0,0 +1,131 @@
+package flinkcluster
+
+import (
+	"testing"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestIsNodeDraining(t *testing.T) {
+	var node = &corev1.Node{}
+	assert.Assert(t, !isNodeDraining(node))
+
+	node.Spec.Unschedulable = true
+	assert.Assert(t, isNodeDraining(node))
+
+	node = &corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+		{Key: clusterAutoscalerDeletionTaint, Effect: corev1.TaintEffectNoSchedule},
+	}}}
+	assert.Assert(t, isNodeDraining(node))
+}
+
+func TestDeriveAdaptiveSavepointStatus(t *testing.T) {
+	var now = time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	var threshold = resource.MustParse("1Gi")
+	var beforeNodeDrain = true
+	var jobSpec = &v1beta1.JobSpec{AdaptiveSavepoint: &v1beta1.AdaptiveSavepointSpec{
+		StateDeltaThreshold: &threshold,
+		BeforeNodeDrain:     &beforeNodeDrain,
+	}}
+	var sizes = []int64{300, 200, 400, 700, 0}
+	var checkpoints = &flink.JobCheckpoints{
+		Latest: flink.LatestCheckpoints{Completed: &flink.CheckpointStatistics{ID: 12}},
+		History: []flink.CheckpointStatistics{
+			{ID: 12, Status: "COMPLETED", CheckpointedSize: &sizes[0], StateSize: 5000},
+			{ID: 11, Status: "FAILED", CheckpointedSize: &sizes[1]},
+			{ID: 10, Status: "COMPLETED", IsSavepoint: true, StateSize: 5000},
+			{ID: 9, Status: "COMPLETED", StateSize: 100},
+			{ID: 8, Status: "COMPLETED", CheckpointedSize: &sizes[2]},
+		},
+	}
+	var recorded = &v1beta1.AdaptiveSavepointStatus{StateDeltaBytes: 1000, LastCheckpointID: 8}
+
+	var status = deriveAdaptiveSavepointStatus(jobSpec, recorded, checkpoints, nil, false, now)
+	assert.DeepEqual(t, status, &v1beta1.AdaptiveSavepointStatus{StateDeltaBytes: 1400, LastCheckpointID: 12})
+
+	// The state delta is reset when a savepoint completes, the checkpoints are counted once.
+	status = deriveAdaptiveSavepointStatus(jobSpec, status, checkpoints, nil, true, now)
+	assert.DeepEqual(t, status, &v1beta1.AdaptiveSavepointStatus{StateDeltaBytes: 0, LastCheckpointID: 12})
+
+	// The checkpoint IDs start over with a new job.
+	var restarted = &flink.JobCheckpoints{
+		Latest:  flink.LatestCheckpoints{Completed: &flink.CheckpointStatistics{ID: 1}},
+		History: []flink.CheckpointStatistics{{ID: 1, Status: "COMPLETED", CheckpointedSize: &sizes[3]}},
+	}
+	status = deriveAdaptiveSavepointStatus(jobSpec, status, restarted, nil, false, now)
+	assert.DeepEqual(t, status, &v1beta1.AdaptiveSavepointStatus{StateDeltaBytes: 700, LastCheckpointID: 1})
+
+	// The incremental checkpoints of an unchanged state add nothing to the delta.
+	var unchanged = &flink.JobCheckpoints{
+		Latest: flink.LatestCheckpoints{Completed: &flink.CheckpointStatistics{ID: 2}},
+		History: []flink.CheckpointStatistics{
+			{ID: 2, Status: "COMPLETED", CheckpointedSize: &sizes[4], StateSize: 5000},
+			{ID: 1, Status: "COMPLETED", CheckpointedSize: &sizes[3]},
+		},
+	}
+	status = deriveAdaptiveSavepointStatus(jobSpec, status, unchanged, nil, false, now)
+	assert.DeepEqual(t, status, &v1beta1.AdaptiveSavepointStatus{StateDeltaBytes: 700, LastCheckpointID: 2})
+
+	// The drain start time is updated only when another node is drained.
+	status = deriveAdaptiveSavepointStatus(jobSpec, status, nil, []string{"node-1"}, false, now)
+	assert.DeepEqual(t, status.DrainingNodes, []string{"node-1"})
+	assert.Equal(t, status.DrainStartTime, "2026-10-15T10:00:00Z")
+	status = deriveAdaptiveSavepointStatus(jobSpec, status, nil, []string{"node-1"}, false, now.Add(time.Minute))
+	assert.Equal(t, status.DrainStartTime, "2026-10-15T10:00:00Z")
+	status = deriveAdaptiveSavepointStatus(jobSpec, status, nil, []string{"node-1", "node-2"}, false, now.Add(time.Minute))
+	assert.Equal(t, status.DrainStartTime, "2026-10-15T10:01:00Z")
+	status = deriveAdaptiveSavepointStatus(jobSpec, status, nil, nil, false, now.Add(time.Minute))
+	assert.Assert(t, status.DrainingNodes == nil)
+	assert.Equal(t, status.DrainStartTime, "")
+
+	assert.Assert(t, deriveAdaptiveSavepointStatus(&v1beta1.JobSpec{}, status, checkpoints, nil, false, now) == nil)
+}
+
+func TestGetAdaptiveSavepointReason(t *testing.T) {
+	var now = time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	var threshold = resource.MustParse("1Ki")
+	var beforeNodeDrain = true
+	var jobSpec = &v1beta1.JobSpec{AdaptiveSavepoint: &v1beta1.AdaptiveSavepointSpec{
+		StateDeltaThreshold: &threshold,
+		BeforeNodeDrain:     &beforeNodeDrain,
+		MinIntervalSeconds:  300,
+	}}
+	var job = &v1beta1.JobStatus{
+		StartTime:         "2026-10-15T08:00:00Z",
+		SavepointTime:     "2026-10-15T09:50:00Z",
+		AdaptiveSavepoint: &v1beta1.AdaptiveSavepointStatus{StateDeltaBytes: 512},
+	}
+	var savepoint = &v1beta1.SavepointStatus{}
+
+	assert.Equal(t, getAdaptiveSavepointReason(jobSpec, job, savepoint, now), v1beta1.SavepointReason(""))
+
+	job.AdaptiveSavepoint.StateDeltaBytes = 1024
+	assert.Equal(t, getAdaptiveSavepointReason(jobSpec, job, savepoint, now), v1beta1.SavepointReasonStateDelta)
+
+	// Not within the minimum interval after the last savepoint.
+	job.SavepointTime = "2026-10-15T09:58:00Z"
+	assert.Equal(t, getAdaptiveSavepointReason(jobSpec, job, savepoint, now), v1beta1.SavepointReason(""))
+
+	// A node was drained after the last savepoint.
+	job.SavepointTime = "2026-10-15T09:50:00Z"
+	job.AdaptiveSavepoint = &v1beta1.AdaptiveSavepointStatus{DrainingNodes: []string{"node-1"}, DrainStartTime: "2026-10-15T09:55:00Z"}
+	assert.Equal(t, getAdaptiveSavepointReason(jobSpec, job, savepoint, now), v1beta1.SavepointReasonNodeDrain)
+	job.AdaptiveSavepoint.DrainStartTime = "2026-10-15T09:45:00Z"
+	assert.Equal(t, getAdaptiveSavepointReason(jobSpec, job, savepoint, now), v1beta1.SavepointReason(""))
+
+	// The failed savepoint is retried after the retry interval.
+	job.AdaptiveSavepoint.DrainStartTime = "2026-10-15T09:55:00Z"
+	savepoint = &v1beta1.SavepointStatus{
+		State:         v1beta1.SavepointStateFailed,
+		TriggerReason: v1beta1.SavepointReasonNodeDrain,
+		UpdateTime:    "2026-10-15T09:59:55Z",
+	}
+	assert.Equal(t, getAdaptiveSavepointReason(jobSpec, job, savepoint, now), v1beta1.SavepointReason(""))
+	savepoint.UpdateTime = "2026-10-15T09:55:00Z"
+	assert.Equal(t, getAdaptiveSavepointReason(jobSpec, job, savepoint, now), v1beta1.SavepointReasonNodeDrain)
+}
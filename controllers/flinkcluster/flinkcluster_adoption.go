@@ -0,0 +1,119 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func isAdoptExistingEnabled(cluster *v1beta1.FlinkCluster) bool {
+	return cluster.Spec.AdoptExisting != nil && *cluster.Spec.AdoptExisting
+}
+
+// getUnownedComponents returns the observed components which are not owned by the cluster,
+// e.g. deployed by another operator or by hand before the cluster was created. The HA
+// ConfigMap is created by Flink without owner and is owned in reconcileHAConfigMap, and the
+// components disabled in spec.components are managed externally.
+func getUnownedComponents(observed *ObservedClusterState) []client.Object {
+	var created = observed.cluster.Spec.Components
+	var external = map[client.Object]bool{client.Object(observed.haConfigMap): true}
+	if !created.ShouldCreateJmService() {
+		external[observed.jmService] = true
+		external[observed.jmUIService] = true
+	}
+	if !created.ShouldCreateTmService() {
+		external[observed.tmService] = true
+	}
+	if !created.ShouldCreateIngress() {
+		external[observed.jmIngress] = true
+	}
+	if !created.ShouldCreatePDB() {
+		external[observed.podDisruptionBudget] = true
+	}
+	if !created.ShouldCreateConfigMap() {
+		external[observed.configMap] = true
+	}
+
+	var components []client.Object
+	for _, obj := range getObservedComponents(observed) {
+		if !external[obj] && !isOwnedBy(obj, observed.cluster) {
+			components = append(components, obj)
+		}
+	}
+	return components
+}
+
+// Adopts the existing components which are not owned by the cluster if spec.adoptExisting
+// is enabled, otherwise the cluster is not reconciled so that they are not taken over
+// unintentionally. Only the owner reference is added, the spec of the adopted components
+// is updated with the next update of the cluster. The components being deleted, e.g. those
+// of a deleted cluster of the same name which the garbage collector has not removed yet,
+// are retried until they are gone.
+func (reconciler *ClusterReconciler) reconcileAdoption(ctx context.Context) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = reconciler.observed.cluster
+	var components = getUnownedComponents(&reconciler.observed)
+	if len(components) == 0 {
+		return nil
+	}
+
+	for _, obj := range components {
+		if isComponentBeingDeleted(obj, cluster) {
+			return fmt.Errorf("%v %v is being deleted, waiting until it is gone", getComponentKind(obj), obj.GetName())
+		}
+	}
+	for _, obj := range components {
+		if owner := metav1.GetControllerOf(obj); owner != nil {
+			return newUserConfigError(fmt.Errorf("%v %v exists and is controlled by %v %v, it cannot be adopted",
+				getComponentKind(obj), obj.GetName(), owner.Kind, owner.Name))
+		}
+	}
+	if !isAdoptExistingEnabled(cluster) {
+		var names []string
+		for _, obj := range components {
+			names = append(names, fmt.Sprintf("%v %v", getComponentKind(obj), obj.GetName()))
+		}
+		return newUserConfigError(fmt.Errorf(
+			"components not owned by the cluster already exist: %v, set spec.adoptExisting to adopt them",
+			strings.Join(names, ", ")))
+	}
+
+	for _, obj := range components {
+		var log = log.WithValues("component", getComponentKind(obj), "name", obj.GetName())
+		obj.SetOwnerReferences(append(obj.GetOwnerReferences(), render.ToOwnerReference(cluster)))
+		if err := reconciler.k8sClient.Update(ctx, obj); err != nil {
+			log.Error(err, "Failed to adopt")
+			return err
+		}
+		log.Info("Adopted")
+		reconciler.recorder.Event(
+			cluster,
+			corev1.EventTypeNormal,
+			"Adopted",
+			fmt.Sprintf("Adopted the existing %v %v", getComponentKind(obj), obj.GetName()))
+	}
+	return nil
+}
+
+// isComponentBeingDeleted returns true if the component is being deleted, or if it is
+// controlled by a previous cluster of the same name, whose components the garbage collector
+// deletes.
+func isComponentBeingDeleted(obj client.Object, cluster *v1beta1.FlinkCluster) bool {
+	if obj.GetDeletionTimestamp() != nil {
+		return true
+	}
+	var owner = metav1.GetControllerOf(obj)
+	return owner != nil && owner.Kind == "FlinkCluster" && owner.Name == cluster.Name && owner.UID != cluster.UID
+}
+
+func getComponentKind(obj client.Object) string {
+	return reflect.TypeOf(obj).Elem().Name()
+}
@@ -0,0 +1,97 @@
+package flinkcluster
+
+import (
+	"context"
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileAdoption(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "flinkoperator.k8s.io/v1beta1", Kind: "FlinkCluster"},
+		ObjectMeta: metav1.ObjectMeta{Name: "mycluster", Namespace: "default", UID: "cluster-uid"},
+	}
+	var jmStatefulSet = &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "mycluster-jobmanager", Namespace: "default"},
+	}
+	var configMap = &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "mycluster-configmap",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{render.ToOwnerReference(cluster)},
+		},
+	}
+	var haConfigMap = &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "mycluster-cluster-config-map", Namespace: "default"},
+	}
+	var scheme = runtime.NewScheme()
+	assert.NilError(t, appsv1.AddToScheme(scheme))
+	assert.NilError(t, corev1.AddToScheme(scheme))
+	var k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(jmStatefulSet, configMap, haConfigMap).Build()
+	var reconciler = &ClusterReconciler{
+		k8sClient: k8sClient,
+		recorder:  record.NewFakeRecorder(10),
+	}
+	var observe = func() {
+		var observedStatefulSet = new(appsv1.StatefulSet)
+		assert.NilError(t, k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(jmStatefulSet), observedStatefulSet))
+		reconciler.observed = ObservedClusterState{
+			cluster:       cluster,
+			jmStatefulSet: observedStatefulSet,
+			configMap:     configMap,
+			haConfigMap:   haConfigMap,
+		}
+	}
+
+	// The existing components are not adopted unless spec.adoptExisting is enabled.
+	observe()
+	assert.DeepEqual(t, getUnownedComponents(&reconciler.observed), []client.Object{reconciler.observed.jmStatefulSet})
+	var err = reconciler.reconcileAdoption(context.TODO())
+	assert.Error(t, err, "components not owned by the cluster already exist: StatefulSet mycluster-jobmanager, "+
+		"set spec.adoptExisting to adopt them")
+	assert.Equal(t, getErrorType(err), ErrorTypeUserConfig)
+
+	var adopt = true
+	cluster.Spec.AdoptExisting = &adopt
+	observe()
+	assert.NilError(t, reconciler.reconcileAdoption(context.TODO()))
+	var adopted = new(appsv1.StatefulSet)
+	assert.NilError(t, k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(jmStatefulSet), adopted))
+	assert.DeepEqual(t, adopted.OwnerReferences, []metav1.OwnerReference{render.ToOwnerReference(cluster)})
+	observe()
+	assert.Equal(t, len(getUnownedComponents(&reconciler.observed)), 0)
+
+	// The components controlled by another owner are not adopted.
+	var otherCluster = cluster.DeepCopy()
+	otherCluster.Name, otherCluster.UID = "othercluster", "other-uid"
+	reconciler.observed.jmStatefulSet.OwnerReferences = []metav1.OwnerReference{render.ToOwnerReference(otherCluster)}
+	err = reconciler.reconcileAdoption(context.TODO())
+	assert.Error(t, err, "StatefulSet mycluster-jobmanager exists and is controlled by FlinkCluster othercluster, "+
+		"it cannot be adopted")
+	assert.Equal(t, getErrorType(err), ErrorTypeUserConfig)
+
+	// The components of a deleted cluster of the same name are retried until the garbage
+	// collector deletes them.
+	var deletedCluster = cluster.DeepCopy()
+	deletedCluster.UID = "deleted-uid"
+	reconciler.observed.jmStatefulSet.OwnerReferences = []metav1.OwnerReference{render.ToOwnerReference(deletedCluster)}
+	err = reconciler.reconcileAdoption(context.TODO())
+	assert.Error(t, err, "StatefulSet mycluster-jobmanager is being deleted, waiting until it is gone")
+	assert.Equal(t, getErrorType(err), ErrorTypeTransientK8s)
+
+	var now = metav1.Now()
+	reconciler.observed.jmStatefulSet.OwnerReferences = nil
+	reconciler.observed.jmStatefulSet.DeletionTimestamp = &now
+	err = reconciler.reconcileAdoption(context.TODO())
+	assert.Equal(t, getErrorType(err), ErrorTypeTransientK8s)
+}
@@ -0,0 +1,231 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// The decisions of the operator recorded in the audit ConfigMap of a cluster.
+const (
+	auditDecisionUpdate                    = "Update"
+	auditDecisionJobSubmit                 = "JobSubmit"
+	auditDecisionJobRestart                = "JobRestart"
+	auditDecisionJobNotRestarted           = "JobNotRestarted"
+	auditDecisionRestoreVerificationFailed = "RestoreVerificationFailed"
+	auditDecisionRollback                  = "Rollback"
+	auditDecisionCleanup                   = "Cleanup"
+)
+
+const (
+	// The key of the audit ConfigMap with the YAML list of the records.
+	auditRecordsKey = "records"
+	// The number of records kept in the audit ConfigMap, the oldest records are dropped.
+	auditMaxRecords = 100
+)
+
+// auditRecord is a decision the operator made for a cluster and the reason for it.
+type auditRecord struct {
+	Time     metav1.Time `json:"time"`
+	Decision string      `json:"decision"`
+	Message  string      `json:"message"`
+	// The revision of the cluster the decision was made in.
+	Revision string `json:"revision,omitempty"`
+	JobID    string `json:"jobID,omitempty"`
+}
+
+func getAuditConfigMapName(clusterName string) string {
+	return clusterName + "-audit"
+}
+
+func newAuditRecord(status *v1beta1.FlinkClusterStatus, now time.Time, decision string, message string) auditRecord {
+	var record = auditRecord{
+		Time:     metav1.NewTime(now),
+		Decision: decision,
+		Message:  message,
+		Revision: status.Revision.NextRevision,
+	}
+	if status.Components.Job != nil {
+		record.JobID = status.Components.Job.ID
+	}
+	return record
+}
+
+// Gets the audit records of the decisions made in the transition of the cluster status: the
+// updates triggered, the restarts of failed jobs and the cleanups after the jobs finished.
+func getAuditRecords(
+	observed *ObservedClusterState,
+	oldStatus v1beta1.FlinkClusterStatus,
+	newStatus v1beta1.FlinkClusterStatus,
+	now time.Time) []auditRecord {
+	var cluster = observed.cluster
+	var records []auditRecord
+
+	if !oldStatus.Revision.IsUpdateTriggered() && newStatus.Revision.IsUpdateTriggered() {
+		var message = fmt.Sprintf("Updating from revision %v to %v", newStatus.Revision.CurrentRevision, newStatus.Revision.NextRevision)
+		if changes := getRevisionChanges(observed); len(changes) > 0 {
+			message = fmt.Sprintf("%v, changed: %v", message, strings.Join(changes, ", "))
+		}
+		if isJobUpdate(observed.revisions, cluster) && newStatus.Components.Job.IsActive() {
+			message = fmt.Sprintf("%v. The job is stopped with update stop mode %v and submitted again",
+				message, getUpdateStopMode(cluster))
+		}
+		records = append(records, newAuditRecord(&newStatus, now, auditDecisionUpdate, message))
+	}
+
+	var jobSpec = cluster.Spec.Job
+	var oldJob, newJob = oldStatus.Components.Job, newStatus.Components.Job
+	if jobSpec == nil || !newJob.IsStopped() || (oldJob.IsStopped() && oldJob.State == newJob.State) {
+		return records
+	}
+
+	if newJob.IsFailed() && jobSpec.RestartPolicy != nil &&
+		*jobSpec.RestartPolicy == v1beta1.JobRestartPolicyFromSavepointOnFailure {
+		if newJob.ShouldRestart(jobSpec) {
+			records = append(records, newAuditRecord(&newStatus, now, auditDecisionJobRestart, fmt.Sprintf(
+				"The job ended in state %v, restarting it from savepoint %v taken at %v as spec.job.restartPolicy is %v",
+				newJob.State, newJob.SavepointLocation, newJob.SavepointTime, *jobSpec.RestartPolicy)))
+		} else {
+			records = append(records, newAuditRecord(&newStatus, now, auditDecisionJobNotRestarted, fmt.Sprintf(
+				"The job ended in state %v and is not restarted although spec.job.restartPolicy is %v: %v",
+				newJob.State, *jobSpec.RestartPolicy, getNoRestartReason(jobSpec, newJob))))
+		}
+	}
+
+	if !newStatus.Revision.IsUpdateTriggered() {
+		var policy, action = render.GetCleanupAction(jobSpec, newJob.State)
+		var deleted string
+		switch action {
+		case v1beta1.CleanupActionDeleteCluster:
+			deleted = "the cluster components"
+		case v1beta1.CleanupActionDeleteTaskManager:
+			deleted = "the TaskManagers"
+		case v1beta1.CleanupActionKeepJobManagerOnly:
+			deleted = "the cluster components except the JobManager"
+		}
+		if deleted != "" {
+			records = append(records, newAuditRecord(&newStatus, now, auditDecisionCleanup, fmt.Sprintf(
+				"The job ended in state %v, deleting %v as spec.job.cleanupPolicy.%v is %v",
+				newJob.State, deleted, policy, action)))
+		}
+	}
+	return records
+}
+
+// Gets the changed fields of the spec between the last two revisions.
+func getRevisionChanges(observed *ObservedClusterState) []string {
+	var revisions = observed.revisions
+	if len(revisions) < 2 {
+		return nil
+	}
+	var changes []string
+	for key := range revisionDiff(revisions[len(revisions)-2], revisions[len(revisions)-1]) {
+		if key == render.ReferencedConfigHashKey {
+			changes = append(changes, "referenced ConfigMaps and Secrets")
+		} else {
+			changes = append(changes, "spec."+key)
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// Explains why the failed job is not restarted, see JobStatus.ShouldRestart.
+func getNoRestartReason(jobSpec *v1beta1.JobSpec, job *v1beta1.JobStatus) string {
+	switch {
+	case job.RestoreVerification != nil && job.RestoreVerification.State == v1beta1.RestoreVerificationStateFailed:
+		return fmt.Sprintf("its restore from savepoint %v failed the verification", job.RestoreVerification.Savepoint)
+	case job.SavepointLocation == "":
+		return "no savepoint was taken"
+	case jobSpec.MaxStateAgeToRestoreSeconds == nil:
+		return fmt.Sprintf("savepoint %v is not the final state of the job and spec.job.maxStateAgeToRestoreSeconds is not set",
+			job.SavepointLocation)
+	}
+	return fmt.Sprintf("savepoint %v taken at %v is older than spec.job.maxStateAgeToRestoreSeconds (%v) at the failure",
+		job.SavepointLocation, job.SavepointTime, *jobSpec.MaxStateAgeToRestoreSeconds)
+}
+
+// Appends the records to the audit ConfigMap of the cluster, which is created with the first
+// records. A record repeating the last record is not appended again, so that the same
+// decision made in consecutive reconciliations is recorded once.
+func appendAuditRecords(ctx context.Context, k8sClient client.Client, cluster *v1beta1.FlinkCluster, records []auditRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	var key = types.NamespacedName{Namespace: cluster.Namespace, Name: getAuditConfigMapName(cluster.Name)}
+	var isRetriable = func(err error) bool {
+		return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+	}
+	return retry.OnError(retry.DefaultRetry, isRetriable, func() error {
+		var configMap = new(corev1.ConfigMap)
+		var err = k8sClient.Get(ctx, key, configMap)
+		if errors.IsNotFound(err) {
+			configMap, err = newAuditConfigMap(cluster, mergeAuditRecords(nil, records))
+			if err != nil {
+				return err
+			}
+			return k8sClient.Create(ctx, configMap)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Records which cannot be parsed, e.g. edited by hand, are dropped.
+		var existing []auditRecord
+		yaml.Unmarshal([]byte(configMap.Data[auditRecordsKey]), &existing)
+		data, err := yaml.Marshal(mergeAuditRecords(existing, records))
+		if err != nil {
+			return err
+		}
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[auditRecordsKey] = string(data)
+		return k8sClient.Update(ctx, configMap)
+	})
+}
+
+// Appends the records which do not repeat their previous record, keeping the last
+// auditMaxRecords records.
+func mergeAuditRecords(existing []auditRecord, records []auditRecord) []auditRecord {
+	var merged = existing
+	for _, record := range records {
+		if n := len(merged); n > 0 && merged[n-1].Decision == record.Decision &&
+			merged[n-1].Message == record.Message && merged[n-1].Revision == record.Revision {
+			continue
+		}
+		merged = append(merged, record)
+	}
+	if len(merged) > auditMaxRecords {
+		merged = merged[len(merged)-auditMaxRecords:]
+	}
+	return merged
+}
+
+func newAuditConfigMap(cluster *v1beta1.FlinkCluster, records []auditRecord) (*corev1.ConfigMap, error) {
+	data, err := yaml.Marshal(records)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       cluster.Namespace,
+			Name:            getAuditConfigMapName(cluster.Name),
+			Labels:          render.ClusterLabels(cluster),
+			OwnerReferences: []metav1.OwnerReference{render.ToOwnerReference(cluster)},
+		},
+		Data: map[string]string{auditRecordsKey: string(data)},
+	}, nil
+}
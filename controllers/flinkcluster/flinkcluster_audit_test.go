@@ -0,0 +1,141 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGetAuditRecords(t *testing.T) {
+	var now = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	var restartPolicy = v1beta1.JobRestartPolicyFromSavepointOnFailure
+	var maxStateAge int32 = 600
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			FlinkVersion: "1.16",
+			Job: &v1beta1.JobSpec{
+				RestartPolicy:               &restartPolicy,
+				MaxStateAgeToRestoreSeconds: &maxStateAge,
+				CleanupPolicy: &v1beta1.CleanupPolicy{
+					AfterJobSucceeds:  v1beta1.CleanupActionDeleteCluster,
+					AfterJobFails:     v1beta1.CleanupActionKeepCluster,
+					AfterJobCancelled: v1beta1.CleanupActionDeleteTaskManager,
+				},
+			},
+		},
+	}
+	var observed = &ObservedClusterState{
+		cluster: cluster,
+		revisions: []*appsv1.ControllerRevision{
+			{Revision: 1, Data: runtime.RawExtension{Raw: []byte(`{"spec":{"job":{"jarFile":"v1.jar"},"referencedConfigHash":"a"}}`)}},
+			{Revision: 2, Data: runtime.RawExtension{Raw: []byte(`{"spec":{"job":{"jarFile":"v2.jar"},"referencedConfigHash":"b"}}`)}},
+		},
+	}
+	var status = func(revision string, job v1beta1.JobStatus) v1beta1.FlinkClusterStatus {
+		return v1beta1.FlinkClusterStatus{
+			Components: v1beta1.FlinkClusterComponentsStatus{Job: &job},
+			Revision:   v1beta1.RevisionStatus{CurrentRevision: "cluster-1", NextRevision: revision},
+		}
+	}
+	var running = v1beta1.JobStatus{ID: "1234", State: v1beta1.JobStateRunning}
+
+	// Update triggered.
+	var records = getAuditRecords(observed, status("cluster-1", running), status("cluster-2", running), now)
+	assert.DeepEqual(t, records, []auditRecord{{
+		Time:     metav1.NewTime(now),
+		Decision: auditDecisionUpdate,
+		Message: "Updating from revision cluster-1 to cluster-2, changed: referenced ConfigMaps and Secrets, spec.job. " +
+			"The job is stopped with update stop mode StopWithSavepoint and submitted again",
+		Revision: "cluster-2",
+		JobID:    "1234",
+	}})
+
+	// Failed with a recent savepoint.
+	var failed = v1beta1.JobStatus{
+		ID:                "1234",
+		State:             v1beta1.JobStateFailed,
+		CompletionTime:    &metav1.Time{Time: now},
+		SavepointLocation: "gs://savepoints/savepoint-1",
+		SavepointTime:     now.Add(-time.Minute).Format(time.RFC3339),
+	}
+	records = getAuditRecords(observed, status("cluster-1", running), status("cluster-1", failed), now)
+	assert.Equal(t, len(records), 1)
+	assert.Equal(t, records[0].Decision, auditDecisionJobRestart)
+	assert.Equal(t, records[0].Message, fmt.Sprintf("The job ended in state Failed, restarting it from savepoint gs://savepoints/savepoint-1 "+
+		"taken at %v as spec.job.restartPolicy is FromSavepointOnFailure", failed.SavepointTime))
+
+	// The failure is recorded once.
+	records = getAuditRecords(observed, status("cluster-1", failed), status("cluster-1", failed), now)
+	assert.Equal(t, len(records), 0)
+
+	// Failed with an outdated savepoint.
+	failed.SavepointTime = now.Add(-time.Hour).Format(time.RFC3339)
+	records = getAuditRecords(observed, status("cluster-1", running), status("cluster-1", failed), now)
+	assert.Equal(t, len(records), 1)
+	assert.Equal(t, records[0].Decision, auditDecisionJobNotRestarted)
+	assert.Equal(t, records[0].Message, fmt.Sprintf("The job ended in state Failed and is not restarted although spec.job.restartPolicy "+
+		"is FromSavepointOnFailure: savepoint gs://savepoints/savepoint-1 taken at %v is older than "+
+		"spec.job.maxStateAgeToRestoreSeconds (600) at the failure", failed.SavepointTime))
+
+	// Cleanup.
+	var succeeded = v1beta1.JobStatus{ID: "1234", State: v1beta1.JobStateSucceeded}
+	records = getAuditRecords(observed, status("cluster-1", running), status("cluster-1", succeeded), now)
+	assert.Equal(t, len(records), 1)
+	assert.Equal(t, records[0].Decision, auditDecisionCleanup)
+	assert.Equal(t, records[0].Message, "The job ended in state Succeeded, deleting the cluster components as spec.job.cleanupPolicy.afterJobSucceeds is DeleteCluster")
+
+	var cancelled = v1beta1.JobStatus{ID: "1234", State: v1beta1.JobStateCancelled}
+	records = getAuditRecords(observed, status("cluster-1", running), status("cluster-1", cancelled), now)
+	assert.Equal(t, len(records), 1)
+	assert.Equal(t, records[0].Message, "The job ended in state Cancelled, deleting the TaskManagers as spec.job.cleanupPolicy.afterJobCancelled is DeleteTaskManager")
+}
+
+func TestAppendAuditRecords(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{ObjectMeta: metav1.ObjectMeta{Name: "mycluster", Namespace: "default"}}
+	var scheme = runtime.NewScheme()
+	assert.NilError(t, corev1.AddToScheme(scheme))
+	var k8sClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+	var now = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	var getRecords = func() []auditRecord {
+		var configMap = new(corev1.ConfigMap)
+		var key = types.NamespacedName{Namespace: "default", Name: "mycluster-audit"}
+		assert.NilError(t, k8sClient.Get(context.TODO(), key, configMap))
+		var records []auditRecord
+		assert.NilError(t, yaml.Unmarshal([]byte(configMap.Data["records"]), &records))
+		return records
+	}
+
+	// The ConfigMap is created with the first records.
+	var first = auditRecord{Time: metav1.NewTime(now), Decision: auditDecisionJobSubmit, Message: "Submitted the job without a savepoint"}
+	assert.NilError(t, appendAuditRecords(context.TODO(), k8sClient, cluster, []auditRecord{first}))
+	assert.DeepEqual(t, getRecords(), []auditRecord{first})
+
+	// The repeated decision is not appended.
+	var repeated = first
+	repeated.Time = metav1.NewTime(now.Add(time.Minute))
+	assert.NilError(t, appendAuditRecords(context.TODO(), k8sClient, cluster, []auditRecord{repeated}))
+	assert.DeepEqual(t, getRecords(), []auditRecord{first})
+
+	// The oldest records are dropped.
+	var records []auditRecord
+	for i := 0; i < auditMaxRecords; i++ {
+		records = append(records, auditRecord{
+			Time:     metav1.NewTime(now.Add(time.Duration(i) * time.Hour)),
+			Decision: auditDecisionUpdate,
+			Message:  fmt.Sprintf("Updating to revision %v", i),
+		})
+	}
+	assert.NilError(t, appendAuditRecords(context.TODO(), k8sClient, cluster, records))
+	assert.DeepEqual(t, getRecords(), records)
+}
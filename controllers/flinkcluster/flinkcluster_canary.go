@@ -0,0 +1,155 @@
+package flinkcluster
+
+import (
+	"fmt"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Defaults of spec.canaryUpdate, applied when the fields are not set.
+const (
+	defaultCanaryUpdatePercentage              = 10
+	defaultCanaryUpdateProgressDeadlineSeconds = 600
+)
+
+// getCanaryUpdateReplicas returns the number of canary TaskManagers of the percentage of
+// spec.canaryUpdate, rounded up so that there is at least one TaskManager to verify.
+func getCanaryUpdateReplicas(spec *v1beta1.CanaryUpdateSpec, replicas int32) int32 {
+	var percentage = spec.Percentage
+	if percentage <= 0 {
+		percentage = defaultCanaryUpdatePercentage
+	}
+	var canaries = (replicas*percentage + 99) / 100
+	if canaries > replicas {
+		canaries = replicas
+	}
+	return canaries
+}
+
+// getStatefulSetPartition returns the partition of the rolling update of the StatefulSet, 0 if unset.
+func getStatefulSetPartition(statefulSet *appsv1.StatefulSet) int32 {
+	var rollingUpdate = statefulSet.Spec.UpdateStrategy.RollingUpdate
+	if rollingUpdate == nil || rollingUpdate.Partition == nil {
+		return 0
+	}
+	return *rollingUpdate.Partition
+}
+
+// getReadyCanaryTaskManagers counts the TaskManager pods of the update revision of the
+// StatefulSet which are ready and registered to the JobManager. It returns false if they
+// cannot be counted yet, e.g. while the StatefulSet is not updated or the JobManager is
+// not reachable.
+func getReadyCanaryTaskManagers(observed *ObservedClusterState) (int32, bool) {
+	var statefulSet = observed.tmStatefulSet
+	if statefulSet == nil || observed.registeredTaskManagers == nil ||
+		statefulSet.Status.ObservedGeneration < statefulSet.Generation ||
+		statefulSet.Status.UpdateRevision == "" ||
+		!isComponentUpdated(statefulSet, observed.cluster) {
+		return 0, false
+	}
+
+	var registered = map[string]bool{}
+	for _, tm := range observed.registeredTaskManagers.TaskManagers {
+		if name := getTaskManagerPodName(tm, observed.tmPods); name != "" {
+			registered[name] = true
+		}
+	}
+	var ready int32
+	for _, pod := range observed.tmPods {
+		if pod.DeletionTimestamp != nil ||
+			pod.Labels[appsv1.ControllerRevisionHashLabelKey] != statefulSet.Status.UpdateRevision ||
+			!registered[pod.Name] {
+			continue
+		}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+				ready++
+				break
+			}
+		}
+	}
+	return ready, true
+}
+
+// deriveCanaryUpdateStatus derives the status of the canary update of spec.canaryUpdate.
+// A canary update starts for every new revision of the cluster. The canary TaskManagers
+// must become ready and registered within the progress deadline and stay so for the soak
+// period, then the update is promoted to the other TaskManagers. The update can also be
+// promoted or aborted early with the canary-promote and canary-abort user controls.
+func deriveCanaryUpdateStatus(
+	observed *ObservedClusterState,
+	revision *v1beta1.RevisionStatus,
+	now time.Time) *v1beta1.CanaryUpdateStatus {
+	var cluster = observed.cluster
+	var spec = cluster.Spec.CanaryUpdate
+	if spec == nil {
+		return nil
+	}
+	var recorded = cluster.Status.CanaryUpdate
+	if !revision.IsUpdateTriggered() || isUpdateDeferred(cluster, revision, now) {
+		return recorded.DeepCopy()
+	}
+
+	var tc = &util.TimeConverter{}
+	if recorded == nil || recorded.Revision != revision.NextRevision {
+		var replicas int32
+		if r := cluster.Spec.TaskManager.Replicas; r != nil {
+			replicas = *r
+		}
+		return &v1beta1.CanaryUpdateStatus{
+			Revision:  revision.NextRevision,
+			State:     v1beta1.CanaryUpdateStateProgressing,
+			Replicas:  getCanaryUpdateReplicas(spec, replicas),
+			StartTime: tc.ToString(now),
+		}
+	}
+
+	var s = recorded.DeepCopy()
+	if !s.IsActive() {
+		return s
+	}
+
+	// The canaries are verified only while they can be counted.
+	var ready, counted = getReadyCanaryTaskManagers(observed)
+	if counted {
+		s.ReadyReplicas = ready
+	}
+	var control = cluster.Status.Control
+	switch {
+	case control != nil && control.State == v1beta1.ControlStateRequested && control.Name == v1beta1.ControlNameCanaryAbort:
+		s.State = v1beta1.CanaryUpdateStateAborted
+		s.Message = "Aborted by the user."
+	case control != nil && control.State == v1beta1.ControlStateRequested && control.Name == v1beta1.ControlNameCanaryPromote:
+		s.State = v1beta1.CanaryUpdateStatePromoted
+		s.Message = "Promoted by the user."
+	case s.State == v1beta1.CanaryUpdateStateProgressing:
+		var deadline = spec.ProgressDeadlineSeconds
+		if deadline <= 0 {
+			deadline = defaultCanaryUpdateProgressDeadlineSeconds
+		}
+		if counted && s.ReadyReplicas >= s.Replicas {
+			s.State = v1beta1.CanaryUpdateStateSoaking
+			s.SoakStartTime = tc.ToString(now)
+		} else if util.HasTimeElapsed(s.StartTime, now, int(deadline)) {
+			s.State = v1beta1.CanaryUpdateStateFailed
+			s.Message = fmt.Sprintf("%v of %v canary TaskManagers became ready and registered within %vs",
+				s.ReadyReplicas, s.Replicas, deadline)
+		}
+	case s.State == v1beta1.CanaryUpdateStateSoaking && counted:
+		if s.ReadyReplicas < s.Replicas {
+			s.State = v1beta1.CanaryUpdateStateFailed
+			s.Message = fmt.Sprintf("%v of %v canary TaskManagers stayed ready and registered during the soak period",
+				s.ReadyReplicas, s.Replicas)
+		} else if util.HasTimeElapsed(s.SoakStartTime, now, int(spec.SoakSeconds)) {
+			s.State = v1beta1.CanaryUpdateStatePromoted
+		}
+	}
+	if !s.IsActive() {
+		s.FinishTime = tc.ToString(now)
+	}
+	return s
+}
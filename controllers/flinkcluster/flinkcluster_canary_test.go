@@ -0,0 +1,223 @@
+package flinkcluster
+
+import (
+	"testing"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetCanaryUpdateReplicas(t *testing.T) {
+	assert.Equal(t, getCanaryUpdateReplicas(&v1beta1.CanaryUpdateSpec{Percentage: 10}, 25), int32(3))
+	assert.Equal(t, getCanaryUpdateReplicas(&v1beta1.CanaryUpdateSpec{Percentage: 50}, 4), int32(2))
+	assert.Equal(t, getCanaryUpdateReplicas(&v1beta1.CanaryUpdateSpec{Percentage: 100}, 3), int32(3))
+	assert.Equal(t, getCanaryUpdateReplicas(&v1beta1.CanaryUpdateSpec{}, 3), int32(1))
+	assert.Equal(t, getCanaryUpdateReplicas(&v1beta1.CanaryUpdateSpec{}, 0), int32(0))
+}
+
+func getCanaryUpdateCluster() *v1beta1.FlinkCluster {
+	var replicas int32 = 4
+	return &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			TaskManager: &v1beta1.TaskManagerSpec{
+				DeploymentType: v1beta1.DeploymentTypeStatefulSet,
+				Replicas:       &replicas,
+			},
+			CanaryUpdate: &v1beta1.CanaryUpdateSpec{Percentage: 25, SoakSeconds: 300, ProgressDeadlineSeconds: 600},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-aa5e3a87z-3"},
+		},
+	}
+}
+
+func newCanaryTaskManagerPod(name, revision string, ready bool) corev1.Pod {
+	var status = corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{appsv1.ControllerRevisionHashLabelKey: revision},
+		},
+		Status: corev1.PodStatus{
+			PodIP:      "10.12.0." + name[len(name)-1:],
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+func getCanaryUpdateObservedState() *ObservedClusterState {
+	return &ObservedClusterState{
+		cluster: getCanaryUpdateCluster(),
+		tmStatefulSet: &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Generation: 2,
+				Labels:     map[string]string{render.RevisionNameLabel: "cluster-aa5e3a87z"},
+			},
+			Status: appsv1.StatefulSetStatus{ObservedGeneration: 2, UpdateRevision: "cluster-tm-6b8d5"},
+		},
+		tmPods: []corev1.Pod{
+			newCanaryTaskManagerPod("cluster-taskmanager-0", "cluster-tm-5c7f9", true),
+			newCanaryTaskManagerPod("cluster-taskmanager-1", "cluster-tm-5c7f9", true),
+			newCanaryTaskManagerPod("cluster-taskmanager-2", "cluster-tm-6b8d5", true),
+			newCanaryTaskManagerPod("cluster-taskmanager-3", "cluster-tm-6b8d5", false),
+		},
+		registeredTaskManagers: &flink.TaskManagersOverview{TaskManagers: []flink.TaskManager{
+			{ID: "10.12.0.0:6122-8bd5e1", Path: "akka.tcp://flink@10.12.0.0:6122/user/rpc/taskmanager_0"},
+			{ID: "10.12.0.1:6122-6c2d1a", Path: "akka.tcp://flink@10.12.0.1:6122/user/rpc/taskmanager_0"},
+			{ID: "10.12.0.2:6122-0f3e4b", Path: "akka.tcp://flink@10.12.0.2:6122/user/rpc/taskmanager_0"},
+		}},
+	}
+}
+
+func TestGetReadyCanaryTaskManagers(t *testing.T) {
+	var observed = getCanaryUpdateObservedState()
+	var ready, counted = getReadyCanaryTaskManagers(observed)
+	assert.Assert(t, counted)
+	assert.Equal(t, ready, int32(1))
+
+	// The pods of the update revision must be ready and registered.
+	observed.tmPods[3].Status.Conditions[0].Status = corev1.ConditionTrue
+	ready, _ = getReadyCanaryTaskManagers(observed)
+	assert.Equal(t, ready, int32(1))
+	observed.registeredTaskManagers.TaskManagers = append(observed.registeredTaskManagers.TaskManagers,
+		flink.TaskManager{ID: "10.12.0.3:6122-9a7b2c", Path: "akka.tcp://flink@10.12.0.3:6122/user/rpc/taskmanager_0"})
+	ready, _ = getReadyCanaryTaskManagers(observed)
+	assert.Equal(t, ready, int32(2))
+
+	observed.tmStatefulSet.Generation = 3
+	_, counted = getReadyCanaryTaskManagers(observed)
+	assert.Assert(t, !counted)
+
+	observed.tmStatefulSet.Generation = 2
+	observed.registeredTaskManagers = nil
+	_, counted = getReadyCanaryTaskManagers(observed)
+	assert.Assert(t, !counted)
+}
+
+func TestDeriveCanaryUpdateStatus(t *testing.T) {
+	var tc = &util.TimeConverter{}
+	var now = time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	var observed = getCanaryUpdateObservedState()
+	var cluster = observed.cluster
+	var revision = cluster.Status.Revision
+
+	// The canary update starts with the next revision.
+	var status = deriveCanaryUpdateStatus(observed, &revision, now)
+	assert.DeepEqual(t, status, &v1beta1.CanaryUpdateStatus{
+		Revision:  "cluster-aa5e3a87z-3",
+		State:     v1beta1.CanaryUpdateStateProgressing,
+		Replicas:  1,
+		StartTime: tc.ToString(now),
+	})
+
+	// The canary pod is ready and registered, the soak period starts.
+	cluster.Status.CanaryUpdate = status
+	var soakStart = now.Add(time.Minute)
+	status = deriveCanaryUpdateStatus(observed, &revision, soakStart)
+	assert.Equal(t, status.State, v1beta1.CanaryUpdateStateSoaking)
+	assert.Equal(t, status.ReadyReplicas, int32(1))
+	assert.Equal(t, status.SoakStartTime, tc.ToString(soakStart))
+
+	cluster.Status.CanaryUpdate = status
+	status = deriveCanaryUpdateStatus(observed, &revision, soakStart.Add(time.Minute))
+	assert.Equal(t, status.State, v1beta1.CanaryUpdateStateSoaking)
+
+	// The update is promoted after the soak period.
+	var promoteTime = soakStart.Add(301 * time.Second)
+	status = deriveCanaryUpdateStatus(observed, &revision, promoteTime)
+	assert.Equal(t, status.State, v1beta1.CanaryUpdateStatePromoted)
+	assert.Equal(t, status.FinishTime, tc.ToString(promoteTime))
+
+	// The update fails if the canary becomes unready while soaking.
+	observed.tmPods[2].Status.Conditions[0].Status = corev1.ConditionFalse
+	status = deriveCanaryUpdateStatus(observed, &revision, soakStart.Add(time.Minute))
+	assert.Equal(t, status.State, v1beta1.CanaryUpdateStateFailed)
+	assert.Equal(t, status.Message, "0 of 1 canary TaskManagers stayed ready and registered during the soak period")
+
+	// The update fails if the canary does not become ready within the progress deadline.
+	cluster.Status.CanaryUpdate.State = v1beta1.CanaryUpdateStateProgressing
+	status = deriveCanaryUpdateStatus(observed, &revision, now.Add(5*time.Minute))
+	assert.Equal(t, status.State, v1beta1.CanaryUpdateStateProgressing)
+	status = deriveCanaryUpdateStatus(observed, &revision, now.Add(11*time.Minute))
+	assert.Equal(t, status.State, v1beta1.CanaryUpdateStateFailed)
+	assert.Equal(t, status.Message, "0 of 1 canary TaskManagers became ready and registered within 600s")
+
+	// The update is aborted by the user control.
+	cluster.Status.Control = &v1beta1.FlinkClusterControlStatus{
+		Name:  v1beta1.ControlNameCanaryAbort,
+		State: v1beta1.ControlStateRequested,
+	}
+	status = deriveCanaryUpdateStatus(observed, &revision, now.Add(time.Minute))
+	assert.Equal(t, status.State, v1beta1.CanaryUpdateStateAborted)
+	assert.Equal(t, status.Message, "Aborted by the user.")
+
+	// The halted update is kept until the next revision.
+	cluster.Status.CanaryUpdate = status
+	cluster.Status.Control = nil
+	status = deriveCanaryUpdateStatus(observed, &revision, now.Add(time.Hour))
+	assert.DeepEqual(t, status, cluster.Status.CanaryUpdate)
+
+	revision.NextRevision = "cluster-7f5c9d87b-4"
+	status = deriveCanaryUpdateStatus(observed, &revision, now.Add(time.Hour))
+	assert.Equal(t, status.Revision, "cluster-7f5c9d87b-4")
+	assert.Equal(t, status.State, v1beta1.CanaryUpdateStateProgressing)
+
+	cluster.Spec.CanaryUpdate = nil
+	assert.Assert(t, deriveCanaryUpdateStatus(observed, &revision, now) == nil)
+}
+
+func TestDeriveCanaryUpdateControlStatus(t *testing.T) {
+	var cluster = getCanaryUpdateCluster()
+	cluster.Annotations = map[string]string{v1beta1.ControlAnnotation: v1beta1.ControlNameCanaryPromote}
+
+	var control = deriveControlStatus(cluster, nil, nil, nil)
+	assert.Equal(t, control.Name, v1beta1.ControlNameCanaryPromote)
+	assert.Equal(t, control.State, v1beta1.ControlStateRequested)
+
+	cluster.Status.Control = control
+	cluster.Status.CanaryUpdate = &v1beta1.CanaryUpdateStatus{State: v1beta1.CanaryUpdateStateSoaking}
+	control = deriveControlStatus(cluster, nil, nil, cluster.Status.Control)
+	assert.Equal(t, control.State, v1beta1.ControlStateSucceeded)
+
+	cluster.Status.CanaryUpdate.State = v1beta1.CanaryUpdateStateFailed
+	control = deriveControlStatus(cluster, nil, nil, cluster.Status.Control)
+	assert.Equal(t, control.State, v1beta1.ControlStateFailed)
+	assert.Equal(t, control.Message, "No canary update in progress.")
+}
+
+func TestCanaryUpdateRejected(t *testing.T) {
+	var observed = getCanaryUpdateObservedState()
+	var cluster = observed.cluster
+	cluster.Status.CanaryUpdate = &v1beta1.CanaryUpdateStatus{
+		Revision: "cluster-aa5e3a87z-3",
+		State:    v1beta1.CanaryUpdateStateSoaking,
+		Replicas: 1,
+	}
+	assert.Equal(t, getUpdateState(observed), UpdateStateInProgress)
+
+	// The update is halted once the canary update is aborted or failed.
+	for _, state := range []v1beta1.CanaryUpdateState{v1beta1.CanaryUpdateStateAborted, v1beta1.CanaryUpdateStateFailed} {
+		cluster.Status.CanaryUpdate.State = state
+		assert.Equal(t, getUpdateState(observed), UpdateStateNoUpdate)
+	}
+
+	// The TaskManagers are rendered from the spec decoded from the current revision.
+	var current = cluster.DeepCopy()
+	current.Spec.Image.Name = "flink:1.14.4"
+	var currentRevision, err = render.NewRevision(current, "", 2, nil)
+	assert.NilError(t, err)
+	var spec = getRevisionSpec(currentRevision)
+	assert.Assert(t, spec != nil)
+	assert.Equal(t, spec.Image.Name, "flink:1.14.4")
+	assert.Assert(t, getRevisionSpec(nil) == nil)
+}
@@ -0,0 +1,163 @@
+package flinkcluster
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	checkpointTypeCheckpoint = "checkpoint"
+	checkpointTypeSavepoint  = "savepoint"
+	// The trigger reason of the savepoints which were not triggered by the operator.
+	savepointReasonUnknown = "unknown"
+)
+
+var checkpointMetricLabels = []string{"namespace", "cluster", "type", "trigger_reason"}
+
+// The statistics of the latest completed checkpoint and savepoint of the job of
+// spec.monitoring.checkpointMetrics, served on the metrics endpoint of the operator so that
+// capacity planning dashboards do not need to scrape the metrics of each job.
+var (
+	checkpointStateSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "flink_operator_checkpoint_state_size_bytes",
+		Help: "The state size of the latest completed checkpoint or savepoint of the job of the FlinkCluster.",
+	}, checkpointMetricLabels)
+	checkpointDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "flink_operator_checkpoint_duration_seconds",
+		Help: "The time from the trigger to the completion of the latest checkpoint or savepoint of the job of the FlinkCluster.",
+	}, checkpointMetricLabels)
+	checkpointAlignmentTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "flink_operator_checkpoint_alignment_seconds",
+		Help: "The longest barrier alignment of the subtasks in the latest checkpoint or savepoint of the job of the FlinkCluster.",
+	}, checkpointMetricLabels)
+)
+
+func init() {
+	metrics.Registry.MustRegister(checkpointStateSize, checkpointDuration, checkpointAlignmentTime)
+}
+
+type checkpointMetricKey struct {
+	cluster        types.NamespacedName
+	checkpointType string
+}
+
+// The latest checkpoint exported per cluster and type, whose alignment time is fetched only
+// once as it takes a request per vertex of the job.
+var exportedCheckpoints = struct {
+	sync.Mutex
+	checkpoints map[checkpointMetricKey]exportedCheckpoint
+}{checkpoints: map[checkpointMetricKey]exportedCheckpoint{}}
+
+type exportedCheckpoint struct {
+	id int64
+	// Nil if it could not be observed.
+	alignmentSeconds *float64
+}
+
+// getLatestCheckpoints returns the latest completed checkpoint and savepoint of the job, nil
+// if there is none.
+func getLatestCheckpoints(checkpoints *flink.JobCheckpoints) map[string]*flink.CheckpointStatistics {
+	var latest = map[string]*flink.CheckpointStatistics{
+		checkpointTypeSavepoint: checkpoints.Latest.Savepoint,
+	}
+	if completed := checkpoints.Latest.Completed; completed != nil && !completed.IsSavepoint {
+		latest[checkpointTypeCheckpoint] = completed
+		return latest
+	}
+	for i := range checkpoints.History {
+		var checkpoint = &checkpoints.History[i]
+		if checkpoint.Status == "COMPLETED" && !checkpoint.IsSavepoint {
+			latest[checkpointTypeCheckpoint] = checkpoint
+			break
+		}
+	}
+	return latest
+}
+
+// isCheckpointExported returns true if the checkpoint is the latest exported of its type.
+func isCheckpointExported(name types.NamespacedName, checkpointType string, id int64) bool {
+	exportedCheckpoints.Lock()
+	defer exportedCheckpoints.Unlock()
+	var exported, ok = exportedCheckpoints.checkpoints[checkpointMetricKey{name, checkpointType}]
+	return ok && exported.id == id
+}
+
+// getSavepointTriggerReason returns the reason the operator triggered the savepoint for.
+func getSavepointTriggerReason(cluster *v1beta1.FlinkCluster, savepoint *flink.CheckpointStatistics) string {
+	var recorded = cluster.Status.Savepoint
+	var job = cluster.Status.Components.Job
+	if recorded != nil && recorded.State == v1beta1.SavepointStateSucceeded && recorded.TriggerReason != "" &&
+		job != nil && job.SavepointLocation != "" && job.SavepointLocation == savepoint.ExternalPath {
+		return string(recorded.TriggerReason)
+	}
+	return savepointReasonUnknown
+}
+
+// recordCheckpointMetrics exports the statistics of the latest checkpoint and savepoint of the
+// job observed in this reconciliation, with the alignment times fetched in it by checkpoint
+// ID. The metrics are kept while the checkpoints are not observed, e.g. while the job is not
+// running, and removed when spec.monitoring.checkpointMetrics is disabled or the cluster is
+// deleted.
+func recordCheckpointMetrics(
+	name types.NamespacedName,
+	cluster *v1beta1.FlinkCluster,
+	checkpoints *flink.JobCheckpoints,
+	alignmentSeconds map[int64]float64,
+	err error) {
+	if cluster == nil || !cluster.HasCheckpointMetrics() {
+		if cluster != nil || err == nil {
+			deleteCheckpointMetrics(name)
+		}
+		return
+	}
+	if checkpoints == nil {
+		return
+	}
+
+	exportedCheckpoints.Lock()
+	defer exportedCheckpoints.Unlock()
+	for checkpointType, checkpoint := range getLatestCheckpoints(checkpoints) {
+		if checkpoint == nil {
+			continue
+		}
+		var key = checkpointMetricKey{name, checkpointType}
+		var exported = exportedCheckpoint{id: checkpoint.ID}
+		if alignment, ok := alignmentSeconds[checkpoint.ID]; ok {
+			exported.alignmentSeconds = &alignment
+		} else if previous, ok := exportedCheckpoints.checkpoints[key]; ok && previous.id == checkpoint.ID {
+			exported.alignmentSeconds = previous.alignmentSeconds
+		}
+		exportedCheckpoints.checkpoints[key] = exported
+
+		var labels = prometheus.Labels{"namespace": name.Namespace, "cluster": name.Name, "type": checkpointType}
+		// Removes the metrics of the previous trigger reason, if any.
+		for _, gauge := range []*prometheus.GaugeVec{checkpointStateSize, checkpointDuration, checkpointAlignmentTime} {
+			gauge.DeletePartialMatch(labels)
+		}
+		labels["trigger_reason"] = ""
+		if checkpointType == checkpointTypeSavepoint {
+			labels["trigger_reason"] = getSavepointTriggerReason(cluster, checkpoint)
+		}
+		checkpointStateSize.With(labels).Set(float64(checkpoint.StateSize))
+		checkpointDuration.With(labels).Set(float64(checkpoint.EndToEndDuration) / 1000)
+		if exported.alignmentSeconds != nil {
+			checkpointAlignmentTime.With(labels).Set(*exported.alignmentSeconds)
+		}
+	}
+}
+
+func deleteCheckpointMetrics(name types.NamespacedName) {
+	var labels = prometheus.Labels{"namespace": name.Namespace, "cluster": name.Name}
+	for _, gauge := range []*prometheus.GaugeVec{checkpointStateSize, checkpointDuration, checkpointAlignmentTime} {
+		gauge.DeletePartialMatch(labels)
+	}
+	exportedCheckpoints.Lock()
+	defer exportedCheckpoints.Unlock()
+	delete(exportedCheckpoints.checkpoints, checkpointMetricKey{name, checkpointTypeCheckpoint})
+	delete(exportedCheckpoints.checkpoints, checkpointMetricKey{name, checkpointTypeSavepoint})
+}
@@ -0,0 +1,79 @@
+package flinkcluster
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestGetLatestCheckpoints(t *testing.T) {
+	var savepoint = flink.CheckpointStatistics{ID: 12, Status: "COMPLETED", IsSavepoint: true}
+	var checkpoints = &flink.JobCheckpoints{
+		Latest: flink.LatestCheckpoints{Completed: &savepoint, Savepoint: &savepoint},
+		History: []flink.CheckpointStatistics{
+			savepoint,
+			{ID: 11, Status: "FAILED"},
+			{ID: 10, Status: "COMPLETED"},
+		},
+	}
+	var latest = getLatestCheckpoints(checkpoints)
+	assert.Equal(t, latest[checkpointTypeCheckpoint].ID, int64(10))
+	assert.Equal(t, latest[checkpointTypeSavepoint].ID, int64(12))
+
+	latest = getLatestCheckpoints(&flink.JobCheckpoints{})
+	assert.Assert(t, latest[checkpointTypeCheckpoint] == nil)
+	assert.Assert(t, latest[checkpointTypeSavepoint] == nil)
+}
+
+func TestRecordCheckpointMetrics(t *testing.T) {
+	var enabled = true
+	var name = types.NamespacedName{Namespace: "default", Name: "orders"}
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"},
+		Spec:       v1beta1.FlinkClusterSpec{Monitoring: &v1beta1.MonitoringSpec{CheckpointMetrics: &enabled}},
+		Status: v1beta1.FlinkClusterStatus{
+			Components: v1beta1.FlinkClusterComponentsStatus{
+				Job: &v1beta1.JobStatus{SavepointLocation: "gs://my-bucket/savepoints/savepoint-1"},
+			},
+			Savepoint: &v1beta1.SavepointStatus{State: v1beta1.SavepointStateSucceeded, TriggerReason: v1beta1.SavepointReasonScheduled},
+		},
+	}
+	var checkpoints = &flink.JobCheckpoints{Latest: flink.LatestCheckpoints{
+		Completed: &flink.CheckpointStatistics{ID: 10, StateSize: 2048, EndToEndDuration: 1500},
+		Savepoint: &flink.CheckpointStatistics{ID: 9, IsSavepoint: true, StateSize: 4096, EndToEndDuration: 30000,
+			ExternalPath: "gs://my-bucket/savepoints/savepoint-1"},
+	}}
+	defer recordCheckpointMetrics(name, nil, nil, nil, nil)
+
+	recordCheckpointMetrics(name, cluster, checkpoints, map[int64]float64{10: 0.25}, nil)
+	assert.Equal(t, testutil.ToFloat64(checkpointStateSize.WithLabelValues("default", "orders", "checkpoint", "")), float64(2048))
+	assert.Equal(t, testutil.ToFloat64(checkpointDuration.WithLabelValues("default", "orders", "savepoint", "scheduled")), float64(30))
+	assert.Equal(t, testutil.ToFloat64(checkpointAlignmentTime.WithLabelValues("default", "orders", "checkpoint", "")), 0.25)
+	// The alignment of the savepoint could not be observed.
+	assert.Equal(t, testutil.CollectAndCount(checkpointAlignmentTime), 1)
+
+	// The alignment time is not fetched again for the exported checkpoint.
+	assert.Assert(t, isCheckpointExported(name, checkpointTypeCheckpoint, 10))
+	recordCheckpointMetrics(name, cluster, checkpoints, nil, nil)
+	assert.Equal(t, testutil.ToFloat64(checkpointAlignmentTime.WithLabelValues("default", "orders", "checkpoint", "")), 0.25)
+
+	// The savepoints which were not triggered by the operator have an unknown trigger reason.
+	checkpoints.Latest.Savepoint = &flink.CheckpointStatistics{ID: 11, IsSavepoint: true, ExternalPath: "gs://my-bucket/manual"}
+	recordCheckpointMetrics(name, cluster, checkpoints, nil, nil)
+	assert.Equal(t, testutil.CollectAndCount(checkpointStateSize), 2)
+	assert.Equal(t, testutil.ToFloat64(checkpointStateSize.WithLabelValues("default", "orders", "savepoint", "unknown")), float64(0))
+
+	// The metrics are kept while the checkpoints are not observed.
+	recordCheckpointMetrics(name, cluster, nil, nil, nil)
+	assert.Equal(t, testutil.CollectAndCount(checkpointStateSize), 2)
+
+	cluster.Spec.Monitoring = nil
+	recordCheckpointMetrics(name, cluster, checkpoints, nil, nil)
+	assert.Equal(t, testutil.CollectAndCount(checkpointStateSize), 0)
+	assert.Assert(t, !isCheckpointExported(name, checkpointTypeCheckpoint, 10))
+}
@@ -0,0 +1,116 @@
+package flinkcluster
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// isClusterFinished returns true if the job of the job cluster completed and is neither
+// restarted nor updated.
+func isClusterFinished(cluster *v1beta1.FlinkCluster) bool {
+	var jobSpec = cluster.Spec.Job
+	var job = cluster.Status.Components.Job
+	return jobSpec != nil && job.IsTerminated(jobSpec) && job.CompletionTime != nil &&
+		!cluster.Status.Revision.IsUpdateTriggered()
+}
+
+// getClusterExpiry returns when the finished cluster is deleted by
+// spec.job.cleanupPolicy.ttlSecondsAfterFinished, zero if it is not.
+func getClusterExpiry(cluster *v1beta1.FlinkCluster) time.Time {
+	if !isClusterFinished(cluster) || cluster.Spec.Job.CleanupPolicy == nil ||
+		cluster.Spec.Job.CleanupPolicy.TTLSecondsAfterFinished == nil {
+		return time.Time{}
+	}
+	var ttl = time.Duration(*cluster.Spec.Job.CleanupPolicy.TTLSecondsAfterFinished) * time.Second
+	return cluster.Status.Components.Job.CompletionTime.Add(ttl)
+}
+
+// getExcessFinishedClusters returns the finished clusters of the group of the cluster by
+// spec.job.cleanupPolicy.keepLastFinished which are older than the ones to keep.
+func getExcessFinishedClusters(cluster *v1beta1.FlinkCluster, group []v1beta1.FlinkCluster) []*v1beta1.FlinkCluster {
+	if cluster.Spec.Job == nil || cluster.Spec.Job.CleanupPolicy == nil {
+		return nil
+	}
+	var keep = cluster.Spec.Job.CleanupPolicy.KeepLastFinished
+	if keep == nil {
+		return nil
+	}
+	var value, ok = cluster.Labels[keep.GroupLabel]
+	if !ok {
+		return nil
+	}
+
+	var finished []*v1beta1.FlinkCluster
+	for i := range group {
+		var member = &group[i]
+		if member.Namespace == cluster.Namespace && member.Labels[keep.GroupLabel] == value &&
+			member.DeletionTimestamp == nil && isClusterFinished(member) {
+			finished = append(finished, member)
+		}
+	}
+	if len(finished) <= int(keep.Count) {
+		return nil
+	}
+	sort.SliceStable(finished, func(i, j int) bool {
+		var ti = finished[i].Status.Components.Job.CompletionTime
+		var tj = finished[j].Status.Components.Job.CompletionTime
+		if !ti.Equal(tj) {
+			return tj.Before(ti)
+		}
+		return finished[i].Name < finished[j].Name
+	})
+	return finished[keep.Count:]
+}
+
+// Deletes the finished clusters by spec.job.cleanupPolicy: the cluster once its TTL
+// expired, and the older finished clusters of its group. Returns true if the cluster itself
+// is deleted.
+func (reconciler *ClusterReconciler) reconcileFinishedClusters(ctx context.Context) (bool, error) {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = reconciler.observed.cluster
+	if !isClusterFinished(cluster) {
+		return false, nil
+	}
+
+	if expiry := getClusterExpiry(cluster); !expiry.IsZero() && !time.Now().Before(expiry) {
+		log.Info("Deleting the finished cluster as its TTL expired",
+			"ttlSecondsAfterFinished", *cluster.Spec.Job.CleanupPolicy.TTLSecondsAfterFinished)
+		reconciler.recorder.Eventf(cluster, corev1.EventTypeNormal, "ClusterExpired",
+			"Deleting the cluster %v seconds after its job finished as spec.job.cleanupPolicy.ttlSecondsAfterFinished",
+			*cluster.Spec.Job.CleanupPolicy.TTLSecondsAfterFinished)
+		return true, client.IgnoreNotFound(reconciler.k8sClient.Delete(ctx, cluster))
+	}
+
+	if cluster.Spec.Job.CleanupPolicy == nil {
+		return false, nil
+	}
+	var keep = cluster.Spec.Job.CleanupPolicy.KeepLastFinished
+	if keep == nil || cluster.Labels[keep.GroupLabel] == "" {
+		return false, nil
+	}
+	var group v1beta1.FlinkClusterList
+	var err = reconciler.k8sClient.List(ctx, &group, client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{keep.GroupLabel: cluster.Labels[keep.GroupLabel]})
+	if err != nil {
+		return false, err
+	}
+	var deleted bool
+	for _, excess := range getExcessFinishedClusters(cluster, group.Items) {
+		log.Info("Deleting the finished cluster of the group beyond keepLastFinished",
+			"cluster", excess.Name, "group", keep.GroupLabel+"="+cluster.Labels[keep.GroupLabel], "count", keep.Count)
+		reconciler.recorder.Eventf(excess, corev1.EventTypeNormal, "ClusterExpired",
+			"Deleting the cluster as it is not one of the last %v finished clusters with label %v=%v",
+			keep.Count, keep.GroupLabel, cluster.Labels[keep.GroupLabel])
+		if err := reconciler.k8sClient.Delete(ctx, excess); client.IgnoreNotFound(err) != nil {
+			return deleted, err
+		}
+		deleted = deleted || excess.UID == cluster.UID
+	}
+	return deleted, nil
+}
@@ -0,0 +1,77 @@
+package flinkcluster
+
+import (
+	"testing"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newFinishedCluster(name, app string, completionTime time.Time, policy *v1beta1.CleanupPolicy) v1beta1.FlinkCluster {
+	var restartPolicy = v1beta1.JobRestartPolicyNever
+	var completion = metav1.NewTime(completionTime)
+	return v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": app}},
+		Spec:       v1beta1.FlinkClusterSpec{Job: &v1beta1.JobSpec{RestartPolicy: &restartPolicy, CleanupPolicy: policy}},
+		Status: v1beta1.FlinkClusterStatus{Components: v1beta1.FlinkClusterComponentsStatus{
+			Job: &v1beta1.JobStatus{State: v1beta1.JobStateSucceeded, CompletionTime: &completion},
+		}},
+	}
+}
+
+func TestShouldCleanupKeepJobManagerOnly(t *testing.T) {
+	var cluster = newFinishedCluster("batch-1", "batch", time.Now(), &v1beta1.CleanupPolicy{
+		AfterJobSucceeds: v1beta1.CleanupActionKeepJobManagerOnly,
+	})
+	for _, component := range []string{"JobManager", "JobManagerService", "ConfigMap", "Job"} {
+		assert.Assert(t, !render.ShouldCleanup(&cluster, component), component)
+	}
+	for _, component := range []string{"TaskManager", "TaskManagerService", "JobManagerIngress", "PodDisruptionBudget"} {
+		assert.Assert(t, render.ShouldCleanup(&cluster, component), component)
+	}
+}
+
+func TestGetClusterExpiry(t *testing.T) {
+	var completionTime = time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	var ttl = int32(3600)
+	var cluster = newFinishedCluster("batch-1", "batch", completionTime, &v1beta1.CleanupPolicy{TTLSecondsAfterFinished: &ttl})
+	assert.Equal(t, getClusterExpiry(&cluster), completionTime.Add(time.Hour))
+
+	// The job restarted by the restart policy is not finished.
+	cluster.Status.Components.Job.State = v1beta1.JobStateFailed
+	var restartPolicy = v1beta1.JobRestartPolicyFromSavepointOnFailure
+	cluster.Spec.Job.RestartPolicy = &restartPolicy
+	cluster.Status.Components.Job.FinalSavepoint = true
+	assert.Assert(t, getClusterExpiry(&cluster).IsZero())
+
+	cluster = newFinishedCluster("batch-1", "batch", completionTime, &v1beta1.CleanupPolicy{})
+	assert.Assert(t, getClusterExpiry(&cluster).IsZero())
+}
+
+func TestGetExcessFinishedClusters(t *testing.T) {
+	var now = time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	var policy = &v1beta1.CleanupPolicy{KeepLastFinished: &v1beta1.KeepLastFinishedPolicy{GroupLabel: "app", Count: 2}}
+	var running = newFinishedCluster("batch-5", "batch", now, policy)
+	running.Status.Components.Job = &v1beta1.JobStatus{State: v1beta1.JobStateRunning}
+	var group = []v1beta1.FlinkCluster{
+		newFinishedCluster("batch-1", "batch", now.Add(-4*time.Hour), policy),
+		newFinishedCluster("batch-3", "batch", now.Add(-2*time.Hour), policy),
+		newFinishedCluster("batch-2", "batch", now.Add(-3*time.Hour), policy),
+		newFinishedCluster("batch-4", "batch", now.Add(-time.Hour), policy),
+		newFinishedCluster("stream-1", "stream", now.Add(-5*time.Hour), policy),
+		running,
+	}
+
+	var excess = getExcessFinishedClusters(&group[3], group)
+	var names []string
+	for _, cluster := range excess {
+		names = append(names, cluster.Name)
+	}
+	assert.DeepEqual(t, names, []string{"batch-2", "batch-1"})
+
+	group[3].Labels = nil
+	assert.Assert(t, getExcessFinishedClusters(&group[3], group) == nil)
+}
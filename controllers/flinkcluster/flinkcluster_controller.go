@@ -22,59 +22,121 @@ import (
 
 	"github.com/spotify/flink-on-k8s-operator/internal/controllers/history"
 	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"github.com/spotify/flink-on-k8s-operator/internal/kafka"
 
 	"github.com/go-logr/logr"
 	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
-	"github.com/spotify/flink-on-k8s-operator/internal/model"
+	"github.com/spotify/flink-on-k8s-operator/internal/notification"
+	"github.com/spotify/flink-on-k8s-operator/internal/secrets"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/model"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
-// controllerKind contains the schema.GroupVersionKind for this controller type.
-var controllerKind = v1beta1.GroupVersion.WithKind("FlinkCluster")
-
 // FlinkClusterReconciler reconciles a FlinkCluster object
 type FlinkClusterReconciler struct {
-	Client        client.Client
-	Clientset     *kubernetes.Clientset
+	Client    client.Client
+	Clientset *kubernetes.Clientset
+	// The configuration of the clientset, to run commands in the pods.
+	RestConfig    *rest.Config
 	EventRecorder record.EventRecorder
+	// The maximum number of job clusters running simultaneously per namespace
+	// and queue label, excess clusters are queued. 0 means no limit.
+	MaxRunningJobClusters int
+	// Records the outcome of the reconciliations for the diagnostics endpoint.
+	Diagnostics *Diagnostics
+	// Collects the lag of the consumer groups of spec.monitoring.kafkaLag in the background.
+	KafkaLagCollector *kafka.LagCollector
+	// Notifies webhooks of state transitions, nil if not configured.
+	Notifier *notification.Notifier
+	// The image of the ephemeral containers of the debug user control.
+	DebugContainerImage string
+	// The image of the operator, which the JAR uploader Jobs and the diagnostics collectors run.
+	OperatorImage string
+	// The image of the read-only web UI proxies and of the init containers rendering
+	// flink-conf.yaml, render.DefaultUIProxyImage if empty.
+	UIProxyImage string
+	// The scheduling constraints merged under those of the spec into the pods of the
+	// clusters, none if empty.
+	SchedulingDefaults render.SchedulingDefaults
+	// The image pull secrets of the pods of the clusters which do not set
+	// spec.image.pullSecrets.
+	ImagePullSecrets []corev1.LocalObjectReference
+	// The rules which select the savepoint storage of the jobs by the topology of the node of
+	// their JobManager, over spec.job.savepointsDir. None if empty.
+	SavepointStorageRules []SavepointStorageRule
+	// Limits the rate of the Flink API calls to each cluster, nil if not configured.
+	FlinkAPIRateLimiter *flink.RateLimiter
+	// The interval of the snapshots of the job vertices in the job status, 0 disables them.
+	JobVertexStatusInterval time.Duration
+	// Resolves the secrets of external secret stores in spec.flinkPropertiesFrom, nil if
+	// no stores are configured.
+	SecretResolver *secrets.Resolver
+	// The version of the operator, recorded in the status of the clusters it reconciles.
+	OperatorVersion string
+	// The hosts outside of the namespace of the clusters which their HTTP readiness gates may
+	// request, host names or `*.<domain>` patterns.
+	ReadinessGateAllowedHosts []string
 }
 
-func NewReconciler(mgr manager.Manager) (*FlinkClusterReconciler, error) {
+func NewReconciler(mgr manager.Manager, maxRunningJobClusters int) (*FlinkClusterReconciler, error) {
 	cs, err := kubernetes.NewForConfig(mgr.GetConfig())
 	if err != nil {
 		return nil, err
 	}
 
 	return &FlinkClusterReconciler{
-		Client:        mgr.GetClient(),
-		Clientset:     cs,
-		EventRecorder: mgr.GetEventRecorderFor("FlinkOperator"),
+		Client:                mgr.GetClient(),
+		Clientset:             cs,
+		RestConfig:            mgr.GetConfig(),
+		EventRecorder:         mgr.GetEventRecorderFor("FlinkOperator"),
+		MaxRunningJobClusters: maxRunningJobClusters,
+		Diagnostics:           NewDiagnostics(),
+		KafkaLagCollector:     kafka.NewLagCollector(kafkaLagRefreshInterval, kafkaLagMaxAge, kafkaLagTimeout),
 	}, nil
 }
 
 // +kubebuilder:rbac:groups=flinkoperator.k8s.io,resources=flinkclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=flinkoperator.k8s.io,resources=flinkclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=flinkoperator.k8s.io,resources=flinkclusters/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=statefulsets/status,verbs=get
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;delete
 // +kubebuilder:rbac:groups=core,resources=pods/status,verbs=get
+// +kubebuilder:rbac:groups=core,resources=pods/exec,verbs=create
+// +kubebuilder:rbac:groups=core,resources=pods/ephemeralcontainers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services/status,verbs=get
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=events/status,verbs=get
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=jobs/status,verbs=get
 // +kubebuilder:rbac:groups=networking,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
@@ -86,22 +148,53 @@ func (r *FlinkClusterReconciler) Reconcile(ctx context.Context,
 	log := logr.FromContextOrDiscard(ctx)
 
 	var handler = FlinkClusterHandler{
-		k8sClient:     r.Client,
-		k8sClientset:  r.Clientset,
-		flinkClient:   flink.NewDefaultClient(log),
-		request:       request,
-		eventRecorder: r.EventRecorder,
-		observed:      ObservedClusterState{},
+		k8sClient:               r.Client,
+		k8sClientset:            r.Clientset,
+		restConfig:              r.RestConfig,
+		flinkClient:             flink.NewClusterClient(log, request.NamespacedName, r.FlinkAPIRateLimiter),
+		request:                 request,
+		eventRecorder:           r.EventRecorder,
+		observed:                ObservedClusterState{},
+		maxRunningJobClusters:   r.MaxRunningJobClusters,
+		notifier:                r.Notifier,
+		debugContainerImage:     r.DebugContainerImage,
+		operatorImage:           r.OperatorImage,
+		uiProxyImage:            r.UIProxyImage,
+		schedulingDefaults:      r.SchedulingDefaults,
+		imagePullSecrets:        r.ImagePullSecrets,
+		savepointStorageRules:   r.SavepointStorageRules,
+		jobVertexStatusInterval: r.JobVertexStatusInterval,
+		secretResolver:          r.SecretResolver,
+		operatorVersion:         r.OperatorVersion,
+		readinessGateHosts:      r.ReadinessGateAllowedHosts,
+		kafkaLagCollector:       r.KafkaLagCollector,
 	}
 
-	return handler.reconcile(logr.NewContext(ctx, log), request)
+	result, err := handler.reconcile(logr.NewContext(ctx, log), request)
+	r.Diagnostics.record(request.NamespacedName, &handler.observed, err, time.Now())
+	recordJobSLOMetrics(request.NamespacedName, handler.observed.cluster, err)
+	recordKafkaLagMetric(request.NamespacedName, handler.observed.cluster, handler.observed.kafkaLag)
+	recordCheckpointMetrics(request.NamespacedName, handler.observed.cluster,
+		handler.observed.flinkJob.checkpoints, handler.observed.flinkJob.checkpointAlignments, err)
+	if handler.observed.cluster == nil && err == nil {
+		r.FlinkAPIRateLimiter.Forget(request.NamespacedName)
+		r.KafkaLagCollector.Forget(request.NamespacedName.String())
+	}
+	return handler.handleError(ctx, result, err)
 }
 
 // SetupWithManager registers this reconciler with the controller manager and
-// starts watching FlinkCluster, Deployment and Service resources.
+// starts watching FlinkCluster resources and the components they own, and the metadata of the
+// ConfigMaps and Secrets referenced by the clusters. Only the deletions of the owned
+// ConfigMaps are watched, as Flink updates the HA ConfigMaps on every lease renewal.
 func (reconciler *FlinkClusterReconciler) SetupWithManager(
 	mgr ctrl.Manager,
 	maxConcurrentReconciles int) error {
+	err := mgr.GetFieldIndexer().IndexField(
+		context.Background(), &v1beta1.FlinkCluster{}, referencedConfigIndex, indexReferencedConfigs)
+	if err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(ctrlcontroller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}).
 		For(&v1beta1.FlinkCluster{}).
@@ -109,19 +202,74 @@ func (reconciler *FlinkClusterReconciler) SetupWithManager(
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.Service{}).
 		Owns(&batchv1.Job{}).
+		Owns(&networkingv1.Ingress{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			&handler.EnqueueRequestForOwner{OwnerType: &v1beta1.FlinkCluster{}, IsController: true},
+			builder.OnlyMetadata, builder.WithPredicates(predicate.Funcs{
+				CreateFunc:  func(event.CreateEvent) bool { return false },
+				UpdateFunc:  func(event.UpdateEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+			})).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(reconciler.mapReferencedConfig("ConfigMap")),
+			builder.OnlyMetadata).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(reconciler.mapReferencedConfig("Secret")),
+			builder.OnlyMetadata).
 		Complete(reconciler)
 }
 
+// Indexes the clusters by the ConfigMaps and Secrets whose changes update them.
+func indexReferencedConfigs(obj client.Object) []string {
+	return getReferencedConfigKeys(obj.(*v1beta1.FlinkCluster))
+}
+
+// Enqueues the clusters which reference the ConfigMap or Secret of the kind. Only the
+// metadata of the objects is watched, so that Secrets are not cached by the operator.
+func (reconciler *FlinkClusterReconciler) mapReferencedConfig(kind string) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		var clusters = new(v1beta1.FlinkClusterList)
+		var key = configReference{kind: kind, name: obj.GetName()}.key()
+		err := reconciler.Client.List(context.Background(), clusters,
+			client.InNamespace(obj.GetNamespace()), client.MatchingFields{referencedConfigIndex: key})
+		if err != nil {
+			return nil
+		}
+		var requests []reconcile.Request
+		for i := range clusters.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: clusters.Items[i].Name, Namespace: clusters.Items[i].Namespace},
+			})
+		}
+		return requests
+	}
+}
+
 // FlinkClusterHandler holds the context and state for a
 // reconcile request.
 type FlinkClusterHandler struct {
-	k8sClient     client.Client
-	k8sClientset  *kubernetes.Clientset
-	flinkClient   *flink.Client
-	request       ctrl.Request
-	eventRecorder record.EventRecorder
-	observed      ObservedClusterState
-	desired       model.DesiredClusterState
+	k8sClient               client.Client
+	k8sClientset            *kubernetes.Clientset
+	restConfig              *rest.Config
+	flinkClient             *flink.Client
+	request                 ctrl.Request
+	eventRecorder           record.EventRecorder
+	observed                ObservedClusterState
+	desired                 model.DesiredClusterState
+	maxRunningJobClusters   int
+	notifier                *notification.Notifier
+	debugContainerImage     string
+	operatorImage           string
+	uiProxyImage            string
+	schedulingDefaults      render.SchedulingDefaults
+	imagePullSecrets        []corev1.LocalObjectReference
+	savepointStorageRules   []SavepointStorageRule
+	jobVertexStatusInterval time.Duration
+	secretResolver          *secrets.Resolver
+	operatorVersion         string
+	readinessGateHosts      []string
+	kafkaLagCollector       *kafka.LagCollector
 }
 
 func (handler *FlinkClusterHandler) reconcile(ctx context.Context,
@@ -141,12 +289,18 @@ func (handler *FlinkClusterHandler) reconcile(ctx context.Context,
 	log.Info("---------- 1. Observe the current state ----------")
 
 	var observer = ClusterStateObserver{
-		k8sClient:    k8sClient,
-		k8sClientset: handler.k8sClientset,
-		flinkClient:  flinkClient,
-		request:      request,
-		recorder:     handler.eventRecorder,
-		history:      history,
+		k8sClient:                 k8sClient,
+		k8sClientset:              handler.k8sClientset,
+		flinkClient:               flinkClient,
+		request:                   request,
+		recorder:                  handler.eventRecorder,
+		history:                   history,
+		maxRunningJobClusters:     handler.maxRunningJobClusters,
+		jobVertexStatusInterval:   handler.jobVertexStatusInterval,
+		secretResolver:            handler.secretResolver,
+		readinessGateAllowedHosts: handler.readinessGateHosts,
+		kafkaLagCollector:         handler.kafkaLagCollector,
+		savepointStorageRules:     handler.savepointStorageRules,
 	}
 	err = observer.observe(ctx, observed)
 	if err != nil {
@@ -154,6 +308,26 @@ func (handler *FlinkClusterHandler) reconcile(ctx context.Context,
 		return ctrl.Result{}, err
 	}
 
+	if isClusterTemplate(observed.cluster) {
+		log.Info("The cluster is a template of job clusters, no action to take")
+		return ctrl.Result{}, nil
+	}
+
+	if isStatusSchemaNewer(observed.cluster) {
+		log.Info("The status of the cluster was migrated by a newer operator, no action to take",
+			"operatorVersion", observed.cluster.Status.OperatorVersion,
+			"schemaVersion", observed.cluster.Status.SchemaVersion)
+		return ctrl.Result{}, nil
+	}
+	migrated, err := handler.reconcileStatusMigration(ctx)
+	if err != nil {
+		log.Error(err, "Failed to migrate the cluster status")
+		return ctrl.Result{}, err
+	}
+	if migrated {
+		return ctrl.Result{Requeue: true, RequeueAfter: 5 * time.Second}, nil
+	}
+
 	// Sync history and observe revision status
 	err = observer.syncRevisionStatus(observed)
 	if err != nil {
@@ -164,9 +338,12 @@ func (handler *FlinkClusterHandler) reconcile(ctx context.Context,
 	log.Info("---------- 2. Update cluster status ----------")
 
 	var updater = ClusterStatusUpdater{
-		k8sClient: k8sClient,
-		recorder:  handler.eventRecorder,
-		observed:  handler.observed,
+		k8sClient:               k8sClient,
+		recorder:                handler.eventRecorder,
+		notifier:                handler.notifier,
+		observed:                handler.observed,
+		jobVertexStatusInterval: handler.jobVertexStatusInterval,
+		savepointStorageRules:   handler.savepointStorageRules,
 	}
 	statusChanged, err = updater.updateStatusIfChanged(ctx)
 	if err != nil {
@@ -183,9 +360,27 @@ func (handler *FlinkClusterHandler) reconcile(ctx context.Context,
 		}, nil
 	}
 
+	if isReconcileStopped(observed.cluster) {
+		log.Info("Reconciliation is stopped by a permanent error until the cluster is updated",
+			"error", observed.cluster.Status.ReconcileError.Message)
+		return ctrl.Result{}, nil
+	}
+	if isPaused(observed.cluster) {
+		log.Info("Reconciliation is paused, no action to take", "requeueAfter", JobCheckInterval)
+		return requeueResult, nil
+	}
+	if err := getStartupDeadlineError(observed.cluster); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	log.Info("---------- 3. Compute the desired state ----------")
 
-	*desired = *getDesiredClusterState(observed)
+	*desired = *getDesiredClusterState(observed, render.Options{
+		OperatorImage:      handler.operatorImage,
+		UIProxyImage:       handler.uiProxyImage,
+		SchedulingDefaults: handler.schedulingDefaults,
+		ImagePullSecrets:   handler.imagePullSecrets,
+	})
 	if desired.ConfigMap != nil {
 		log = log.WithValues("ConfigMap", *desired.ConfigMap)
 	} else {
@@ -211,6 +406,11 @@ func (handler *FlinkClusterHandler) reconcile(ctx context.Context,
 	} else {
 		log = log.WithValues("JobManager service", "nil")
 	}
+	if desired.JmUIService != nil {
+		log = log.WithValues("JobManager UI service", *desired.JmUIService)
+	} else {
+		log = log.WithValues("JobManager UI service", "nil")
+	}
 	if desired.JmIngress != nil {
 		log = log.WithValues("JobManager ingress", *desired.JmIngress)
 	} else {
@@ -239,11 +439,17 @@ func (handler *FlinkClusterHandler) reconcile(ctx context.Context,
 	log.Info("---------- 4. Take actions ----------")
 
 	var reconciler = ClusterReconciler{
-		k8sClient:   k8sClient,
-		flinkClient: flinkClient,
-		observed:    handler.observed,
-		desired:     handler.desired,
-		recorder:    handler.eventRecorder,
+		k8sClient:    k8sClient,
+		k8sClientset: handler.k8sClientset,
+		restConfig:   handler.restConfig,
+		flinkClient:  flinkClient,
+		observed:     handler.observed,
+		desired:      handler.desired,
+		recorder:     handler.eventRecorder,
+
+		debugContainerImage: handler.debugContainerImage,
+		operatorImage:       handler.operatorImage,
+		imagePullSecrets:    handler.imagePullSecrets,
 	}
 	result, err := reconciler.reconcile(ctx)
 	if err != nil {
@@ -255,3 +461,40 @@ func (handler *FlinkClusterHandler) reconcile(ctx context.Context,
 
 	return result, err
 }
+
+// handleError decides how the request is retried by the type of the reconciliation error.
+func (handler *FlinkClusterHandler) handleError(
+	ctx context.Context, result ctrl.Result, err error) (ctrl.Result, error) {
+	if err == nil {
+		return result, nil
+	}
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = handler.observed.cluster
+	var errorType = getErrorType(err)
+	switch {
+	case errorType == ErrorTypeFlinkRESTUnavailable:
+		log.Info("Flink REST API is unavailable, will retry", "after", flinkRESTRetryInterval, "error", err.Error())
+		return ctrl.Result{RequeueAfter: flinkRESTRetryInterval}, nil
+	case cluster == nil:
+		return result, err
+	case errorType == ErrorTypeUserConfig:
+		handler.eventRecorder.Event(cluster, corev1.EventTypeWarning, "InvalidConfig", err.Error())
+		return ctrl.Result{}, nil
+	case errorType == ErrorTypePermanent:
+		handler.eventRecorder.Event(cluster, corev1.EventTypeWarning, "ReconcileStopped", err.Error())
+		return ctrl.Result{}, handler.recordReconcileError(ctx, err)
+	}
+	return result, err
+}
+
+// recordReconcileError records the permanent error in the status, which stops the
+// reconciliation of the current generation of the cluster.
+func (handler *FlinkClusterHandler) recordReconcileError(ctx context.Context, err error) error {
+	var cluster = handler.observed.cluster.DeepCopy()
+	cluster.Status.ReconcileError = &v1beta1.ReconcileErrorStatus{
+		Message:            err.Error(),
+		ObservedGeneration: cluster.Generation,
+	}
+	util.SetTimestamp(&cluster.Status.ReconcileError.Time)
+	return handler.k8sClient.Status().Update(ctx, cluster)
+}
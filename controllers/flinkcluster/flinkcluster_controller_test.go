@@ -6,6 +6,8 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render/rendertest"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	policyv1 "k8s.io/api/policy/v1"
@@ -24,7 +26,7 @@ var _ = Describe("FlinkCluster Controller", Ordered, func() {
 	)
 
 	var getDummyFlinkClusterWithJob = func() *v1beta1.FlinkCluster {
-		fc := getDummyFlinkCluster()
+		fc := rendertest.NewFlinkCluster()
 		var blocking = v1beta1.JobModeBlocking
 		fc.Spec.Job.Mode = &blocking
 		fc.Spec.PodDisruptionBudget = &policyv1.PodDisruptionBudgetSpec{
@@ -44,7 +46,7 @@ var _ = Describe("FlinkCluster Controller", Ordered, func() {
 
 	It("Should create the JobManager Statefulset", func() {
 		dummyFlinkCluster := getDummyFlinkClusterWithJob()
-		expectedJobManagerName := getJobManagerName(dummyFlinkCluster.Name)
+		expectedJobManagerName := render.JobManagerName(dummyFlinkCluster.Name)
 		jobManagerLookupKey := types.NamespacedName{
 			Name:      expectedJobManagerName,
 			Namespace: dummyFlinkCluster.Namespace,
@@ -59,7 +61,7 @@ var _ = Describe("FlinkCluster Controller", Ordered, func() {
 
 	It("Should create the TaskManager Statefulset", func() {
 		dummyFlinkCluster := getDummyFlinkClusterWithJob()
-		expectedTaskManagerName := getTaskManagerName(dummyFlinkCluster.Name)
+		expectedTaskManagerName := render.TaskManagerName(dummyFlinkCluster.Name)
 		taskManagerLookupKey := types.NamespacedName{
 			Name:      expectedTaskManagerName,
 			Namespace: dummyFlinkCluster.Namespace,
@@ -89,7 +91,7 @@ var _ = Describe("FlinkCluster Controller", Ordered, func() {
 
 	It("Should create the PodDisruptionBudget", func() {
 		dummyFlinkCluster := getDummyFlinkClusterWithJob()
-		expectedPdbName := getPodDisruptionBudgetName(dummyFlinkCluster.Name)
+		expectedPdbName := render.PodDisruptionBudgetName(dummyFlinkCluster.Name)
 		pdbLookupKey := types.NamespacedName{
 			Name:      expectedPdbName,
 			Namespace: dummyFlinkCluster.Namespace,
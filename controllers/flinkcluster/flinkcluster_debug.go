@@ -0,0 +1,62 @@
+package flinkcluster
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultDebugContainerImage is the image of the ephemeral containers of the debug user control,
+// unless the operator is configured with another one.
+const DefaultDebugContainerImage = "busybox:1.36"
+
+// Keys of the details of the debug user control.
+const (
+	debugPodKey       = "pod"
+	debugContainerKey = "container"
+)
+
+// getDebugTargetPod returns the JobManager or TaskManager pod of the cluster named by the
+// debug-pod annotation, the JobManager pod if the name is empty.
+func getDebugTargetPod(observed *ObservedClusterState, name string) (*corev1.Pod, error) {
+	var pod *corev1.Pod
+	if name == "" {
+		if len(observed.jmPods) == 0 {
+			return nil, fmt.Errorf("no JobManager pod to debug")
+		}
+		pod = &observed.jmPods[0]
+	} else {
+		var pods = append(append([]corev1.Pod{}, observed.jmPods...), observed.tmPods...)
+		for i := range pods {
+			if pods[i].Name == name {
+				pod = &pods[i]
+				break
+			}
+		}
+	}
+	if pod == nil {
+		return nil, fmt.Errorf("pod %v is not a JobManager or TaskManager pod of the cluster", name)
+	}
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+		return nil, fmt.Errorf("pod %v is not running", pod.Name)
+	}
+	return pod, nil
+}
+
+// newDebugContainer returns the ephemeral container of the debug user control, which shares
+// the process namespace of the main container of the pod so that the Flink processes can be
+// inspected, and keeps stdin open so that it can be attached to.
+func newDebugContainer(pod *corev1.Pod, image string, now time.Time) corev1.EphemeralContainer {
+	return corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:            "debugger-" + strings.ToLower(now.UTC().Format(diagnosticsTimeFormat)),
+			Image:           image,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Stdin:           true,
+			TTY:             true,
+		},
+		TargetContainerName: pod.Spec.Containers[0].Name,
+	}
+}
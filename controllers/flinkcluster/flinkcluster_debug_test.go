@@ -0,0 +1,62 @@
+package flinkcluster
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newDebugPod(name, container string, phase corev1.PodPhase) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: container}}},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestGetDebugTargetPod(t *testing.T) {
+	var observed = &ObservedClusterState{
+		jmPods: []corev1.Pod{newDebugPod("cluster-jobmanager-0", "jobmanager", corev1.PodRunning)},
+		tmPods: []corev1.Pod{
+			newDebugPod("cluster-taskmanager-0", "taskmanager", corev1.PodRunning),
+			newDebugPod("cluster-taskmanager-1", "taskmanager", corev1.PodPending),
+		},
+	}
+
+	pod, err := getDebugTargetPod(observed, "")
+	assert.NilError(t, err)
+	assert.Equal(t, pod.Name, "cluster-jobmanager-0")
+
+	pod, err = getDebugTargetPod(observed, "cluster-taskmanager-0")
+	assert.NilError(t, err)
+	assert.Equal(t, pod.Name, "cluster-taskmanager-0")
+
+	_, err = getDebugTargetPod(observed, "cluster-taskmanager-1")
+	assert.Error(t, err, "pod cluster-taskmanager-1 is not running")
+
+	_, err = getDebugTargetPod(observed, "other-pod")
+	assert.Error(t, err, "pod other-pod is not a JobManager or TaskManager pod of the cluster")
+
+	observed.jmPods = nil
+	_, err = getDebugTargetPod(observed, "")
+	assert.Error(t, err, "no JobManager pod to debug")
+}
+
+func TestNewDebugContainer(t *testing.T) {
+	var pod = newDebugPod("cluster-taskmanager-0", "taskmanager", corev1.PodRunning)
+	var now = time.Date(2022, 5, 1, 12, 30, 0, 0, time.UTC)
+	var container = newDebugContainer(&pod, "busybox:1.36", now)
+	assert.DeepEqual(t, container, corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:            "debugger-20220501t123000z",
+			Image:           "busybox:1.36",
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Stdin:           true,
+			TTY:             true,
+		},
+		TargetContainerName: "taskmanager",
+	})
+}
@@ -0,0 +1,180 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// shouldDecommissionTaskManagers returns true if the TaskManager StatefulSet is to be scaled
+// down by the update in progress and spec.taskManager.decommissionTimeoutSeconds is set. The
+// scale to zero of spec.idlePolicy and the recreation of the StatefulSet remove all the
+// TaskManagers, they are not decommissioned.
+func shouldDecommissionTaskManagers(observed *ObservedClusterState, desired *appsv1.StatefulSet) bool {
+	var cluster = observed.cluster
+	var statefulSet = observed.tmStatefulSet
+	if cluster.Spec.TaskManager == nil || cluster.Spec.TaskManager.DecommissionTimeoutSeconds == nil ||
+		desired == nil || statefulSet == nil || desired.Spec.Replicas == nil || statefulSet.Spec.Replicas == nil {
+		return false
+	}
+	return *desired.Spec.Replicas > 0 && *desired.Spec.Replicas < *statefulSet.Spec.Replicas &&
+		shouldUpdateCluster(observed) && !isComponentUpdated(statefulSet, cluster) && !shouldRecreateOnUpdate(observed)
+}
+
+// getDecommissionedTaskManagerPods returns the names of the pods the StatefulSet removes when
+// it is scaled down, the ones with the highest ordinals.
+func getDecommissionedTaskManagerPods(statefulSet *appsv1.StatefulSet, replicas int32) []string {
+	var pods []string
+	for ordinal := replicas; ordinal < *statefulSet.Spec.Replicas; ordinal++ {
+		pods = append(pods, fmt.Sprintf("%v-%v", statefulSet.Name, ordinal))
+	}
+	return pods
+}
+
+// deriveTaskManagerDecommissionStatus keeps the recorded decommission until the StatefulSet is
+// scaled down to its replicas, or until the scale-down is reverted.
+func deriveTaskManagerDecommissionStatus(
+	observed *ObservedClusterState,
+	recorded *v1beta1.TaskManagerDecommissionStatus) *v1beta1.TaskManagerDecommissionStatus {
+	var taskManager = observed.cluster.Spec.TaskManager
+	var statefulSet = observed.tmStatefulSet
+	if recorded == nil || taskManager == nil || taskManager.Replicas == nil ||
+		*taskManager.Replicas != recorded.Replicas || statefulSet == nil || statefulSet.Spec.Replicas == nil ||
+		*statefulSet.Spec.Replicas <= recorded.Replicas {
+		return nil
+	}
+	return recorded.DeepCopy()
+}
+
+// isTaskManagerDecommissionDone returns true and the reason if the decommissioned TaskManagers
+// can be removed: the timeout elapsed, none of them runs tasks, or the checkpoint triggered
+// when the decommission started completed successfully. Flink cannot block the slots of the
+// TaskManagers, so their tasks keep running until they are removed and then restart from the
+// triggered checkpoint; the periodic checkpoints of the job do not finish the decommission.
+// The TaskManagers which are not registered to the JobManager run no tasks. taskManagers and
+// checkpoint are nil if they are not observed.
+func isTaskManagerDecommissionDone(
+	decommission *v1beta1.TaskManagerDecommissionStatus,
+	timeoutSeconds int32,
+	taskManagers *flink.TaskManagersOverview,
+	pods []corev1.Pod,
+	checkpoint *flink.CheckpointTriggerStatus,
+	now time.Time) (bool, string) {
+	if util.HasTimeElapsed(decommission.StartTime, now, int(timeoutSeconds)) {
+		return true, fmt.Sprintf("the timeout of %vs elapsed", timeoutSeconds)
+	}
+
+	if checkpoint != nil && checkpoint.IsSuccessful() {
+		return true, fmt.Sprintf("checkpoint %v triggered by the decommission completed", checkpoint.CheckpointID)
+	}
+
+	if taskManagers == nil {
+		return false, ""
+	}
+	var decommissioned = map[string]bool{}
+	for _, name := range decommission.Pods {
+		decommissioned[name] = true
+	}
+	for _, tm := range taskManagers.TaskManagers {
+		if decommissioned[getTaskManagerPodName(tm, pods)] && tm.FreeSlots < tm.SlotsNumber {
+			return false, ""
+		}
+	}
+	return true, "the TaskManagers run no tasks"
+}
+
+// Holds the scale-down of the TaskManager StatefulSet until the TaskManagers it removes are
+// decommissioned, see isTaskManagerDecommissionDone. Flink cannot block the slots of a
+// TaskManager, a checkpoint is triggered so that the tasks running on them restart from a
+// recent state. Returns true while the scale-down is held.
+func (reconciler *ClusterReconciler) reconcileTaskManagerDecommission(ctx context.Context) (bool, error) {
+	var log = logr.FromContextOrDiscard(ctx)
+	var observed = &reconciler.observed
+	var cluster = observed.cluster
+	var desired = reconciler.desired.TmStatefulSet
+	if !shouldDecommissionTaskManagers(observed, desired) {
+		return false, nil
+	}
+
+	var apiBaseURL = getFlinkAPIBaseURL(cluster)
+	var jobID string
+	if job := observed.flinkJob.status; job != nil && getFlinkJobDeploymentState(job.State) == v1beta1.JobStateRunning {
+		jobID = job.Id
+	}
+	var decommission = cluster.Status.TaskManagerDecommission
+	if decommission == nil || decommission.Replicas != *desired.Spec.Replicas {
+		decommission = &v1beta1.TaskManagerDecommissionStatus{
+			Replicas: *desired.Spec.Replicas,
+			Pods:     getDecommissionedTaskManagerPods(observed.tmStatefulSet, *desired.Spec.Replicas),
+		}
+		util.SetTimestamp(&decommission.StartTime)
+		if jobID != "" {
+			// The checkpoints are triggered by the REST API since Flink 1.17, the decommission
+			// waits for the TaskManagers to run no tasks or for the timeout otherwise.
+			triggerID, err := reconciler.flinkClient.TriggerCheckpoint(apiBaseURL, jobID)
+			if err != nil {
+				log.Info("Failed to trigger checkpoint", "error", err)
+			}
+			decommission.CheckpointTriggerID = triggerID
+		}
+		if err := reconciler.updateTaskManagerDecommissionStatus(ctx, decommission); err != nil {
+			return true, err
+		}
+		log.Info("Decommissioning TaskManagers", "pods", decommission.Pods)
+		reconciler.recorder.Event(
+			cluster,
+			corev1.EventTypeNormal,
+			"DecommissioningTaskManagers",
+			fmt.Sprintf("Decommissioning TaskManagers %v before scaling down to %v replicas",
+				strings.Join(decommission.Pods, ", "), decommission.Replicas))
+		return true, nil
+	}
+
+	var checkpoint *flink.CheckpointTriggerStatus
+	if jobID != "" && decommission.CheckpointTriggerID != "" {
+		var err error
+		checkpoint, err = reconciler.flinkClient.GetCheckpointTriggerStatus(apiBaseURL, jobID, decommission.CheckpointTriggerID)
+		if err != nil {
+			log.Info("Failed to get the status of the triggered checkpoint", "error", err)
+		} else if checkpoint.Completed && !checkpoint.IsSuccessful() {
+			log.Info("The triggered checkpoint failed", "cause", checkpoint.FailureCause.ExceptionClass)
+		}
+	}
+	taskManagers, err := reconciler.flinkClient.GetTaskManagers(apiBaseURL)
+	if err != nil {
+		log.Info("Failed to get Flink TaskManagers", "error", err)
+	}
+	var done, reason = isTaskManagerDecommissionDone(decommission, *cluster.Spec.TaskManager.DecommissionTimeoutSeconds,
+		taskManagers, observed.tmPods, checkpoint, observed.observeTime)
+	if !done {
+		log.Info("Waiting for the TaskManagers to be decommissioned", "pods", decommission.Pods)
+		return true, nil
+	}
+	log.Info("Decommissioned TaskManagers", "pods", decommission.Pods, "reason", reason)
+	reconciler.recorder.Event(
+		cluster,
+		corev1.EventTypeNormal,
+		"DecommissionedTaskManagers",
+		fmt.Sprintf("Decommissioned TaskManagers %v as %v", strings.Join(decommission.Pods, ", "), reason))
+	return false, nil
+}
+
+func (reconciler *ClusterReconciler) updateTaskManagerDecommissionStatus(
+	ctx context.Context, decommission *v1beta1.TaskManagerDecommissionStatus) error {
+	var clusterClone = reconciler.observed.cluster.DeepCopy()
+	clusterClone.Status.TaskManagerDecommission = decommission
+	util.SetTimestamp(&clusterClone.Status.LastUpdateTime)
+	var err = reconciler.k8sClient.Status().Update(ctx, clusterClone)
+	if err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "Failed to update TaskManager decommission status")
+	}
+	return err
+}
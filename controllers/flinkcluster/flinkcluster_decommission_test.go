@@ -0,0 +1,93 @@
+package flinkcluster
+
+import (
+	"testing"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetDecommissionedTaskManagerPods(t *testing.T) {
+	var replicas int32 = 4
+	var statefulSet = &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "mycluster-taskmanager"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+	}
+	assert.DeepEqual(t, getDecommissionedTaskManagerPods(statefulSet, 2),
+		[]string{"mycluster-taskmanager-2", "mycluster-taskmanager-3"})
+}
+
+func TestDeriveTaskManagerDecommissionStatus(t *testing.T) {
+	var specReplicas, observedReplicas int32 = 2, 4
+	var observed = &ObservedClusterState{
+		cluster: &v1beta1.FlinkCluster{
+			Spec: v1beta1.FlinkClusterSpec{TaskManager: &v1beta1.TaskManagerSpec{Replicas: &specReplicas}},
+		},
+		tmStatefulSet: &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Replicas: &observedReplicas}},
+	}
+	var recorded = &v1beta1.TaskManagerDecommissionStatus{
+		Replicas: 2,
+		Pods:     []string{"mycluster-taskmanager-2", "mycluster-taskmanager-3"},
+	}
+	assert.DeepEqual(t, deriveTaskManagerDecommissionStatus(observed, recorded), recorded)
+
+	// The decommission finished once the StatefulSet is scaled down.
+	observedReplicas = 2
+	assert.Assert(t, deriveTaskManagerDecommissionStatus(observed, recorded) == nil)
+
+	// The scale-down is reverted.
+	observedReplicas, specReplicas = 4, 4
+	assert.Assert(t, deriveTaskManagerDecommissionStatus(observed, recorded) == nil)
+}
+
+func TestIsTaskManagerDecommissionDone(t *testing.T) {
+	var now = time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	var decommission = &v1beta1.TaskManagerDecommissionStatus{
+		Replicas:            1,
+		Pods:                []string{"mycluster-taskmanager-1"},
+		StartTime:           now.Add(-time.Minute).Format(time.RFC3339),
+		CheckpointTriggerID: "a1b2c3",
+	}
+	var pods = []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "mycluster-taskmanager-0"}, Status: corev1.PodStatus{PodIP: "10.12.0.5"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "mycluster-taskmanager-1"}, Status: corev1.PodStatus{PodIP: "10.12.0.6"}},
+	}
+	var taskManagers = &flink.TaskManagersOverview{TaskManagers: []flink.TaskManager{
+		{ID: "tm-0", Path: "akka.tcp://flink@10.12.0.5:6122/user/rpc/taskmanager_0", SlotsNumber: 2, FreeSlots: 0},
+		{ID: "tm-1", Path: "akka.tcp://flink@10.12.0.6:6122/user/rpc/taskmanager_0", SlotsNumber: 2, FreeSlots: 1},
+	}}
+	var checkpoint = &flink.CheckpointTriggerStatus{}
+
+	done, _ := isTaskManagerDecommissionDone(decommission, 300, taskManagers, pods, checkpoint, now)
+	assert.Assert(t, !done)
+	done, _ = isTaskManagerDecommissionDone(decommission, 300, nil, pods, nil, now)
+	assert.Assert(t, !done)
+
+	done, reason := isTaskManagerDecommissionDone(decommission, 30, taskManagers, pods, checkpoint, now)
+	assert.Assert(t, done)
+	assert.Equal(t, reason, "the timeout of 30s elapsed")
+
+	// A failed checkpoint does not finish the decommission.
+	checkpoint = &flink.CheckpointTriggerStatus{
+		Completed:    true,
+		FailureCause: flink.SavepointFailureCause{ExceptionClass: "org.apache.flink.runtime.checkpoint.CheckpointException"},
+	}
+	done, _ = isTaskManagerDecommissionDone(decommission, 300, taskManagers, pods, checkpoint, now)
+	assert.Assert(t, !done)
+
+	checkpoint = &flink.CheckpointTriggerStatus{Completed: true, CheckpointID: 8}
+	done, reason = isTaskManagerDecommissionDone(decommission, 300, taskManagers, pods, checkpoint, now)
+	assert.Assert(t, done)
+	assert.Equal(t, reason, "checkpoint 8 triggered by the decommission completed")
+
+	// The other TaskManagers may run tasks.
+	taskManagers.TaskManagers[1].FreeSlots = 2
+	done, reason = isTaskManagerDecommissionDone(decommission, 300, taskManagers, pods, nil, now)
+	assert.Assert(t, done)
+	assert.Equal(t, reason, "the TaskManagers run no tasks")
+}
@@ -0,0 +1,179 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Finalizer to apply spec.deletionPolicy before the components are garbage collected
+// through their owner references.
+const deletionPolicyFinalizer = "flinkoperator.k8s.io/deletion-policy"
+
+func getDeletionPolicy(cluster *v1beta1.FlinkCluster) v1beta1.DeletionPolicy {
+	if cluster.Spec.DeletionPolicy == nil {
+		return v1beta1.DeletionPolicyDeletePVCsAlso
+	}
+	return *cluster.Spec.DeletionPolicy
+}
+
+// needsDeletionPolicyFinalizer returns true if the deletion of the cluster differs from the
+// garbage collection of its components and their PersistentVolumeClaims.
+func needsDeletionPolicyFinalizer(cluster *v1beta1.FlinkCluster) bool {
+	return getDeletionPolicy(cluster) != v1beta1.DeletionPolicyDeletePVCsAlso
+}
+
+// getOrphanedComponents returns the observed components owned by the cluster, which are
+// orphaned on deletion with the Retain policy. The PersistentVolumeClaims are owned by the
+// StatefulSets and retained with them.
+func getOrphanedComponents(observed *ObservedClusterState) []client.Object {
+	var components []client.Object
+	for _, obj := range getObservedComponents(observed) {
+		if isOwnedBy(obj, observed.cluster) {
+			components = append(components, obj)
+		}
+	}
+	return components
+}
+
+// getObservedComponents returns the observed components of the cluster, regardless of
+// their owner.
+func getObservedComponents(observed *ObservedClusterState) []client.Object {
+	var components []client.Object
+	var add = func(obj client.Object, ok bool) {
+		if ok {
+			components = append(components, obj)
+		}
+	}
+	add(observed.configMap, observed.configMap != nil)
+	add(observed.haConfigMap, observed.haConfigMap != nil)
+	add(observed.serviceAccount, observed.serviceAccount != nil)
+	add(observed.role, observed.role != nil)
+	add(observed.roleBinding, observed.roleBinding != nil)
+	add(observed.jmStatefulSet, observed.jmStatefulSet != nil)
+	add(observed.jmService, observed.jmService != nil)
+	add(observed.jmUIService, observed.jmUIService != nil)
+	add(observed.jmIngress, observed.jmIngress != nil)
+	add(observed.tmStatefulSet, observed.tmStatefulSet != nil)
+	add(observed.tmDeployment, observed.tmDeployment != nil)
+	add(observed.tmService, observed.tmService != nil)
+	add(observed.podDisruptionBudget, observed.podDisruptionBudget != nil)
+	add(observed.horizontalPodAutoscaler, observed.horizontalPodAutoscaler != nil)
+	add(observed.flinkJobSubmitter.job, observed.flinkJobSubmitter.job != nil)
+	return components
+}
+
+func isOwnedBy(obj client.Object, cluster *v1beta1.FlinkCluster) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == cluster.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// Adds the finalizer of spec.deletionPolicy to the cluster if the policy needs it, or
+// removes it otherwise.
+func (reconciler *ClusterReconciler) reconcileDeletionPolicyFinalizer(ctx context.Context) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = reconciler.observed.cluster
+	var needed = needsDeletionPolicyFinalizer(cluster)
+	if needed == controllerutil.ContainsFinalizer(cluster, deletionPolicyFinalizer) {
+		return nil
+	}
+
+	var updated = cluster.DeepCopy()
+	if needed {
+		controllerutil.AddFinalizer(updated, deletionPolicyFinalizer)
+	} else {
+		controllerutil.RemoveFinalizer(updated, deletionPolicyFinalizer)
+	}
+	if err := reconciler.k8sClient.Patch(ctx, updated, client.MergeFrom(cluster)); err != nil {
+		log.Error(err, "Failed to update the finalizer of the deletion policy")
+		return err
+	}
+	log.Info("Updated the finalizer of the deletion policy", "deletionPolicy", getDeletionPolicy(cluster))
+	return nil
+}
+
+// Applies spec.deletionPolicy to the components of the deleted cluster, then removes the
+// finalizer so that the remaining components are garbage collected with the cluster.
+func (reconciler *ClusterReconciler) finalizeCluster(ctx context.Context) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = reconciler.observed.cluster
+	if !controllerutil.ContainsFinalizer(cluster, deletionPolicyFinalizer) {
+		log.Info("The cluster is being deleted, no action to take")
+		return nil
+	}
+
+	var policy = getDeletionPolicy(cluster)
+	switch policy {
+	case v1beta1.DeletionPolicyRetain:
+		for _, obj := range getOrphanedComponents(&reconciler.observed) {
+			if err := reconciler.removeOwnerReferences(ctx, obj, cluster.UID); err != nil {
+				return err
+			}
+		}
+		reconciler.recorder.Event(
+			cluster,
+			corev1.EventTypeNormal,
+			"Orphaned",
+			"Orphaned the components of the deleted cluster by the Retain deletion policy")
+	case v1beta1.DeletionPolicyDelete:
+		var retained int
+		if pvcs := reconciler.observed.persistentVolumeClaims; pvcs != nil {
+			for i := range pvcs.Items {
+				if err := reconciler.removeOwnerReferences(ctx, &pvcs.Items[i], ""); err != nil {
+					return err
+				}
+				retained++
+			}
+		}
+		reconciler.recorder.Event(
+			cluster,
+			corev1.EventTypeNormal,
+			"RetainedPVCs",
+			fmt.Sprintf("Retained %v PersistentVolumeClaims of the deleted cluster by the Delete deletion policy", retained))
+	}
+
+	var updated = cluster.DeepCopy()
+	controllerutil.RemoveFinalizer(updated, deletionPolicyFinalizer)
+	if err := reconciler.k8sClient.Patch(ctx, updated, client.MergeFrom(cluster)); client.IgnoreNotFound(err) != nil {
+		log.Error(err, "Failed to remove the finalizer of the deletion policy")
+		return err
+	}
+	log.Info("Applied the deletion policy", "deletionPolicy", policy)
+	return nil
+}
+
+// Removes the owner references of the object to the owner, all of them if the owner is
+// empty, so that the object is not garbage collected with it.
+func (reconciler *ClusterReconciler) removeOwnerReferences(
+	ctx context.Context, obj client.Object, owner types.UID) error {
+	var log = logr.FromContextOrDiscard(ctx).WithValues("object", client.ObjectKeyFromObject(obj))
+	var refs []metav1.OwnerReference
+	for _, ref := range obj.GetOwnerReferences() {
+		if owner != "" && ref.UID != owner {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == len(obj.GetOwnerReferences()) {
+		return nil
+	}
+
+	var updated = obj.DeepCopyObject().(client.Object)
+	updated.SetOwnerReferences(refs)
+	if err := reconciler.k8sClient.Update(ctx, updated); client.IgnoreNotFound(err) != nil {
+		log.Error(err, "Failed to remove owner references")
+		return err
+	}
+	log.Info("Removed owner references")
+	return nil
+}
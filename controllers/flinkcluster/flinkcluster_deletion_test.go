@@ -0,0 +1,104 @@
+package flinkcluster
+
+import (
+	"context"
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestNeedsDeletionPolicyFinalizer(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{}
+	assert.Equal(t, getDeletionPolicy(cluster), v1beta1.DeletionPolicyDeletePVCsAlso)
+	assert.Assert(t, !needsDeletionPolicyFinalizer(cluster))
+
+	var policy = v1beta1.DeletionPolicyDelete
+	cluster.Spec.DeletionPolicy = &policy
+	assert.Assert(t, needsDeletionPolicyFinalizer(cluster))
+	policy = v1beta1.DeletionPolicyRetain
+	assert.Assert(t, needsDeletionPolicyFinalizer(cluster))
+}
+
+func TestGetOrphanedComponents(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{ObjectMeta: metav1.ObjectMeta{Name: "mycluster", UID: "cluster-uid"}}
+	var ownedBy = func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, OwnerReferences: []metav1.OwnerReference{render.ToOwnerReference(cluster)}}
+	}
+	var observed = &ObservedClusterState{
+		cluster:       cluster,
+		configMap:     &corev1.ConfigMap{ObjectMeta: ownedBy("mycluster-configmap")},
+		haConfigMap:   &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "mycluster-cluster-config-map"}},
+		jmStatefulSet: &appsv1.StatefulSet{ObjectMeta: ownedBy("mycluster-jobmanager")},
+		tmStatefulSet: &appsv1.StatefulSet{ObjectMeta: ownedBy("mycluster-taskmanager")},
+	}
+
+	var components = getOrphanedComponents(observed)
+	assert.Equal(t, len(components), 3)
+	assert.Equal(t, components[0].GetName(), "mycluster-configmap")
+	assert.Equal(t, components[1].GetName(), "mycluster-jobmanager")
+	assert.Equal(t, components[2].GetName(), "mycluster-taskmanager")
+}
+
+func TestFinalizeCluster(t *testing.T) {
+	var policy = v1beta1.DeletionPolicyDelete
+	var now = metav1.Now()
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "mycluster",
+			Namespace:         "default",
+			UID:               "cluster-uid",
+			DeletionTimestamp: &now,
+			Finalizers:        []string{deletionPolicyFinalizer},
+		},
+		Spec: v1beta1.FlinkClusterSpec{DeletionPolicy: &policy},
+	}
+	var pvc = &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pvc-mycluster-taskmanager-0",
+			Namespace: "default",
+			Labels:    map[string]string{"cluster": "mycluster", "component": "taskmanager"},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "StatefulSet",
+				Name:       "mycluster-taskmanager",
+				UID:        "statefulset-uid",
+			}},
+		},
+	}
+	var scheme = runtime.NewScheme()
+	assert.NilError(t, v1beta1.AddToScheme(scheme))
+	assert.NilError(t, corev1.AddToScheme(scheme))
+	var k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, pvc).Build()
+	var recorder = record.NewFakeRecorder(1)
+	var reconciler = ClusterReconciler{
+		k8sClient: k8sClient,
+		recorder:  recorder,
+		observed: ObservedClusterState{
+			cluster:                cluster,
+			persistentVolumeClaims: &corev1.PersistentVolumeClaimList{Items: []corev1.PersistentVolumeClaim{*pvc}},
+		},
+	}
+
+	assert.NilError(t, reconciler.finalizeCluster(context.TODO()))
+	assert.Equal(t, <-recorder.Events,
+		"Normal RetainedPVCs Retained 1 PersistentVolumeClaims of the deleted cluster by the Delete deletion policy")
+
+	var retained = new(corev1.PersistentVolumeClaim)
+	assert.NilError(t, k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(pvc), retained))
+	assert.Equal(t, len(retained.OwnerReferences), 0)
+
+	var finalized = new(v1beta1.FlinkCluster)
+	if err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cluster), finalized); err == nil {
+		assert.Assert(t, !controllerutil.ContainsFinalizer(finalized, deletionPolicyFinalizer))
+	}
+}
@@ -0,0 +1,151 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The states in which a FlinkCluster of spec.dependsOn with the Running state is ready.
+var runningDependencyStates = []v1beta1.ClusterState{
+	v1beta1.ClusterStateRunning,
+	v1beta1.ClusterStateDegraded,
+}
+
+// Reasons of the WaitingForDependencies condition.
+const (
+	dependenciesReasonNotReady = "DependencyNotReady"
+	dependenciesReasonReady    = "DependenciesReady"
+)
+
+func getClusterDependencyState(dependency *v1beta1.ClusterDependency) v1beta1.ClusterDependencyState {
+	if dependency.State == nil {
+		return v1beta1.ClusterDependencyStateRunning
+	}
+	return *dependency.State
+}
+
+// shouldCheckDependencies returns true if spec.dependsOn is set and the cluster has not been
+// started yet.
+func shouldCheckDependencies(cluster *v1beta1.FlinkCluster) bool {
+	var state = cluster.Status.State
+	return len(cluster.Spec.DependsOn) > 0 && cluster.DeletionTimestamp == nil &&
+		(state == "" || state == v1beta1.ClusterStateQueued)
+}
+
+// isWaitingForDependencies returns true if the cluster is not started because a dependency
+// was not ready when it was last checked.
+func isWaitingForDependencies(cluster *v1beta1.FlinkCluster) bool {
+	return meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.ClusterConditionWaitingForDependencies)
+}
+
+// checkDependency returns why the dependency is not ready, empty if it is ready. The
+// FlinkCluster of the dependency is nil if it does not exist.
+func checkDependency(dependency *v1beta1.ClusterDependency, dependencyCluster *v1beta1.FlinkCluster) string {
+	var state = getClusterDependencyState(dependency)
+	if dependencyCluster == nil {
+		return fmt.Sprintf("Waiting for FlinkCluster %v to be %v, it is not found.", dependency.Name, state)
+	}
+	switch state {
+	case v1beta1.ClusterDependencyStateSucceeded:
+		var job = dependencyCluster.Status.Components.Job
+		switch {
+		case dependencyCluster.Spec.Job == nil:
+			return fmt.Sprintf("Waiting for the job of FlinkCluster %v to succeed, it is a session cluster.", dependency.Name)
+		case job == nil:
+			return fmt.Sprintf("Waiting for the job of FlinkCluster %v to succeed, it is not submitted.", dependency.Name)
+		case job.State != v1beta1.JobStateSucceeded:
+			return fmt.Sprintf("Waiting for the job of FlinkCluster %v to succeed, it is %v.", dependency.Name, job.State)
+		}
+	default:
+		var current = dependencyCluster.Status.State
+		for _, state := range runningDependencyStates {
+			if current == state {
+				return ""
+			}
+		}
+		if current == "" {
+			current = "not started"
+		}
+		return fmt.Sprintf("Waiting for FlinkCluster %v to be Running or Degraded, it is %v.", dependency.Name, current)
+	}
+	return ""
+}
+
+// getDependenciesNotReadyReason returns why the first dependency of the cluster which is not
+// ready is not, empty if all of them are ready, and the dependencies whose job succeeded
+// including those recorded in status.succeededDependencies. A recorded dependency is ready
+// without its FlinkCluster, so that it can be deleted once its job succeeded.
+func getDependenciesNotReadyReason(ctx context.Context, k8sClient client.Client, cluster *v1beta1.FlinkCluster) (string, []string, error) {
+	var notReadyReason string
+	var succeeded = append([]string(nil), cluster.Status.SucceededDependencies...)
+	for i := range cluster.Spec.DependsOn {
+		var dependency = &cluster.Spec.DependsOn[i]
+		var state = getClusterDependencyState(dependency)
+		if state == v1beta1.ClusterDependencyStateSucceeded && containsDependency(succeeded, dependency.Name) {
+			continue
+		}
+		var dependencyCluster = new(v1beta1.FlinkCluster)
+		var key = types.NamespacedName{Namespace: cluster.Namespace, Name: dependency.Name}
+		if err := k8sClient.Get(ctx, key, dependencyCluster); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return "", nil, err
+			}
+			dependencyCluster = nil
+		}
+		// All the dependencies are checked, so that those which succeed while the cluster
+		// waits for another one are recorded.
+		var reason = checkDependency(dependency, dependencyCluster)
+		switch {
+		case reason != "":
+			if notReadyReason == "" {
+				notReadyReason = reason
+			}
+		case state == v1beta1.ClusterDependencyStateSucceeded:
+			succeeded = append(succeeded, dependency.Name)
+		}
+	}
+	return notReadyReason, succeeded, nil
+}
+
+func containsDependency(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// setWaitingForDependenciesCondition records what the cluster waits for before it is started
+// with the WaitingForDependencies condition. The condition is kept once the cluster is
+// started, and removed when spec.dependsOn is not set.
+func setWaitingForDependenciesCondition(conditions *[]metav1.Condition, cluster *v1beta1.FlinkCluster, notReadyReason *string) {
+	if len(cluster.Spec.DependsOn) == 0 {
+		meta.RemoveStatusCondition(conditions, v1beta1.ClusterConditionWaitingForDependencies)
+		return
+	}
+	if notReadyReason == nil {
+		return
+	}
+
+	var condition = metav1.Condition{
+		Type:               v1beta1.ClusterConditionWaitingForDependencies,
+		ObservedGeneration: cluster.Generation,
+	}
+	if *notReadyReason == "" {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = dependenciesReasonReady
+		condition.Message = "The dependencies of the cluster are ready."
+	} else {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = dependenciesReasonNotReady
+		condition.Message = *notReadyReason
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
@@ -0,0 +1,125 @@
+package flinkcluster
+
+import (
+	"context"
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckDependency(t *testing.T) {
+	var succeeded = v1beta1.ClusterDependencyStateSucceeded
+	var running = &v1beta1.ClusterDependency{Name: "ingest"}
+	var jobSucceeded = &v1beta1.ClusterDependency{Name: "ingest", State: &succeeded}
+	var ingest = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingest", Namespace: "default"},
+		Spec:       v1beta1.FlinkClusterSpec{Job: &v1beta1.JobSpec{}},
+	}
+
+	assert.Equal(t, checkDependency(running, nil), "Waiting for FlinkCluster ingest to be Running, it is not found.")
+	assert.Equal(t, checkDependency(running, ingest), "Waiting for FlinkCluster ingest to be Running or Degraded, it is not started.")
+	assert.Equal(t, checkDependency(jobSucceeded, ingest),
+		"Waiting for the job of FlinkCluster ingest to succeed, it is not submitted.")
+
+	ingest.Status.State = v1beta1.ClusterStateRunning
+	ingest.Status.Components.Job = &v1beta1.JobStatus{State: v1beta1.JobStateRunning}
+	assert.Equal(t, checkDependency(running, ingest), "")
+	assert.Equal(t, checkDependency(jobSucceeded, ingest),
+		"Waiting for the job of FlinkCluster ingest to succeed, it is Running.")
+
+	ingest.Status.State = v1beta1.ClusterStateDegraded
+	assert.Equal(t, checkDependency(running, ingest), "")
+
+	ingest.Status.State = v1beta1.ClusterStateStopped
+	ingest.Status.Components.Job.State = v1beta1.JobStateSucceeded
+	assert.Equal(t, checkDependency(running, ingest), "Waiting for FlinkCluster ingest to be Running or Degraded, it is Stopped.")
+	assert.Equal(t, checkDependency(jobSucceeded, ingest), "")
+
+	ingest.Spec.Job = nil
+	assert.Equal(t, checkDependency(jobSucceeded, ingest),
+		"Waiting for the job of FlinkCluster ingest to succeed, it is a session cluster.")
+}
+
+func TestGetDependenciesNotReadyReason(t *testing.T) {
+	var ingest = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingest", Namespace: "default"},
+		Status:     v1beta1.FlinkClusterStatus{State: v1beta1.ClusterStateRunning},
+	}
+	var scheme = runtime.NewScheme()
+	assert.NilError(t, v1beta1.AddToScheme(scheme))
+	var k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(ingest).Build()
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "default"},
+		Spec: v1beta1.FlinkClusterSpec{
+			Job:       &v1beta1.JobSpec{},
+			DependsOn: []v1beta1.ClusterDependency{{Name: "ingest"}, {Name: "enrich"}},
+		},
+	}
+	assert.Assert(t, shouldCheckDependencies(cluster))
+
+	reason, succeeded, err := getDependenciesNotReadyReason(context.TODO(), k8sClient, cluster)
+	assert.NilError(t, err)
+	assert.Equal(t, reason, "Waiting for FlinkCluster enrich to be Running, it is not found.")
+	assert.Assert(t, succeeded == nil)
+
+	cluster.Spec.DependsOn = cluster.Spec.DependsOn[:1]
+	reason, _, err = getDependenciesNotReadyReason(context.TODO(), k8sClient, cluster)
+	assert.NilError(t, err)
+	assert.Equal(t, reason, "")
+
+	// A succeeded dependency is recorded while the cluster waits for another one, and stays
+	// ready once it is deleted.
+	var succeededState = v1beta1.ClusterDependencyStateSucceeded
+	ingest.Spec.Job = &v1beta1.JobSpec{}
+	ingest.Status.Components.Job = &v1beta1.JobStatus{State: v1beta1.JobStateSucceeded}
+	assert.NilError(t, k8sClient.Update(context.TODO(), ingest))
+	cluster.Spec.DependsOn = []v1beta1.ClusterDependency{{Name: "enrich"}, {Name: "ingest", State: &succeededState}}
+	reason, succeeded, err = getDependenciesNotReadyReason(context.TODO(), k8sClient, cluster)
+	assert.NilError(t, err)
+	assert.Equal(t, reason, "Waiting for FlinkCluster enrich to be Running, it is not found.")
+	assert.DeepEqual(t, succeeded, []string{"ingest"})
+
+	cluster.Status.SucceededDependencies = succeeded
+	assert.NilError(t, k8sClient.Delete(context.TODO(), ingest))
+	cluster.Spec.DependsOn = cluster.Spec.DependsOn[1:]
+	reason, succeeded, err = getDependenciesNotReadyReason(context.TODO(), k8sClient, cluster)
+	assert.NilError(t, err)
+	assert.Equal(t, reason, "")
+	assert.DeepEqual(t, succeeded, []string{"ingest"})
+
+	// The dependencies are not checked again once the cluster is started.
+	cluster.Status.State = v1beta1.ClusterStateCreating
+	assert.Assert(t, !shouldCheckDependencies(cluster))
+}
+
+func TestSetWaitingForDependenciesCondition(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "report", Generation: 1},
+		Spec:       v1beta1.FlinkClusterSpec{DependsOn: []v1beta1.ClusterDependency{{Name: "ingest"}}},
+	}
+	var conditions []metav1.Condition
+	var reason = "Waiting for FlinkCluster ingest to be Running, it is Creating."
+
+	setWaitingForDependenciesCondition(&conditions, cluster, &reason)
+	assert.Assert(t, meta.IsStatusConditionTrue(conditions, v1beta1.ClusterConditionWaitingForDependencies))
+	var condition = meta.FindStatusCondition(conditions, v1beta1.ClusterConditionWaitingForDependencies)
+	assert.Equal(t, condition.Reason, "DependencyNotReady")
+	assert.Equal(t, condition.Message, reason)
+
+	reason = ""
+	setWaitingForDependenciesCondition(&conditions, cluster, &reason)
+	assert.Assert(t, meta.IsStatusConditionFalse(conditions, v1beta1.ClusterConditionWaitingForDependencies))
+
+	// The condition is kept while the dependencies are not checked.
+	setWaitingForDependenciesCondition(&conditions, cluster, nil)
+	assert.Assert(t, meta.IsStatusConditionFalse(conditions, v1beta1.ClusterConditionWaitingForDependencies))
+
+	cluster.Spec.DependsOn = nil
+	setWaitingForDependenciesCondition(&conditions, cluster, nil)
+	assert.Assert(t, meta.FindStatusCondition(conditions, v1beta1.ClusterConditionWaitingForDependencies) == nil)
+}
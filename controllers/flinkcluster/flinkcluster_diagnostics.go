@@ -0,0 +1,202 @@
+package flinkcluster
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Diagnostics records the outcome of the reconciliations of each cluster and
+// serves them as a JSON report, e.g. for SRE dashboards.
+type Diagnostics struct {
+	// Path of the serving certificate of the webhook server, empty if webhooks are disabled.
+	WebhookCertPath string
+
+	mu       sync.Mutex
+	clusters map[types.NamespacedName]*clusterRecord
+}
+
+type clusterRecord struct {
+	state             string
+	errorStreak       int
+	lastError         string
+	lastErrorTime     time.Time
+	lastReconcileTime time.Time
+	flinkAPIReachable *bool
+	queue             string
+	queuePosition     int32
+}
+
+// DiagnosticsReport is the report served by the diagnostics endpoint.
+type DiagnosticsReport struct {
+	// Number of clusters whose last reconciliation failed.
+	ClustersWithErrors int `json:"clustersWithErrors"`
+
+	// Number of clusters whose Flink REST API was unreachable in the last reconciliation.
+	FlinkAPIUnreachable int `json:"flinkAPIUnreachable"`
+
+	// Number of queued job clusters per queue, as `<namespace>/<queue>`.
+	QueueDepths map[string]int `json:"queueDepths,omitempty"`
+
+	// Serving certificate of the webhook server.
+	WebhookCertificate *CertificateDiagnostics `json:"webhookCertificate,omitempty"`
+
+	// Clusters, sorted by namespace and name.
+	Clusters []ClusterDiagnostics `json:"clusters"`
+}
+
+// ClusterDiagnostics is the diagnostics of a cluster.
+type ClusterDiagnostics struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	State     string `json:"state,omitempty"`
+
+	// Number of consecutive failed reconciliations.
+	ErrorStreak   int    `json:"errorStreak"`
+	LastError     string `json:"lastError,omitempty"`
+	LastErrorTime string `json:"lastErrorTime,omitempty"`
+
+	LastReconcileTime string `json:"lastReconcileTime"`
+
+	// Whether the Flink REST API of the JobManager was reachable, unset if it was not requested.
+	FlinkAPIReachable *bool `json:"flinkAPIReachable,omitempty"`
+
+	// Position of the job cluster in its queue, 0 if not queued.
+	QueuePosition int32 `json:"queuePosition,omitempty"`
+}
+
+// CertificateDiagnostics is the diagnostics of a certificate.
+type CertificateDiagnostics struct {
+	Path     string `json:"path"`
+	NotAfter string `json:"notAfter,omitempty"`
+	// Seconds until the certificate expires, negative if expired.
+	ExpiresInSeconds int64  `json:"expiresInSeconds,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+func NewDiagnostics() *Diagnostics {
+	return &Diagnostics{clusters: map[types.NamespacedName]*clusterRecord{}}
+}
+
+// Records the outcome of a reconciliation, the record is removed when the cluster is deleted.
+func (d *Diagnostics) record(name types.NamespacedName, observed *ObservedClusterState, err error, now time.Time) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if observed.cluster == nil && err == nil {
+		delete(d.clusters, name)
+		return
+	}
+
+	var record = d.clusters[name]
+	if record == nil {
+		record = &clusterRecord{}
+		d.clusters[name] = record
+	}
+	record.lastReconcileTime = now
+	if err != nil {
+		record.errorStreak++
+		record.lastError = err.Error()
+		record.lastErrorTime = now
+	} else {
+		record.errorStreak = 0
+	}
+	if observed.cluster == nil {
+		return
+	}
+	record.state = string(observed.cluster.Status.State)
+	record.flinkAPIReachable = observed.flinkJob.apiReachable
+	record.queue = observed.cluster.Labels[QueueLabel]
+	record.queuePosition = observed.queuePosition
+}
+
+// Creates the report of the recorded clusters.
+func (d *Diagnostics) report(now time.Time) *DiagnosticsReport {
+	var report = &DiagnosticsReport{Clusters: []ClusterDiagnostics{}}
+	d.mu.Lock()
+	for name, record := range d.clusters {
+		var cluster = ClusterDiagnostics{
+			Namespace:         name.Namespace,
+			Name:              name.Name,
+			State:             record.state,
+			ErrorStreak:       record.errorStreak,
+			LastError:         record.lastError,
+			LastReconcileTime: record.lastReconcileTime.Format(time.RFC3339),
+			FlinkAPIReachable: record.flinkAPIReachable,
+			QueuePosition:     record.queuePosition,
+		}
+		if !record.lastErrorTime.IsZero() {
+			cluster.LastErrorTime = record.lastErrorTime.Format(time.RFC3339)
+		}
+		if record.errorStreak > 0 {
+			report.ClustersWithErrors++
+		}
+		if record.flinkAPIReachable != nil && !*record.flinkAPIReachable {
+			report.FlinkAPIUnreachable++
+		}
+		if record.queuePosition > 0 {
+			if report.QueueDepths == nil {
+				report.QueueDepths = map[string]int{}
+			}
+			report.QueueDepths[name.Namespace+"/"+record.queue]++
+		}
+		report.Clusters = append(report.Clusters, cluster)
+	}
+	d.mu.Unlock()
+
+	sort.Slice(report.Clusters, func(i, j int) bool {
+		var a, b = report.Clusters[i], report.Clusters[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	if d.WebhookCertPath != "" {
+		report.WebhookCertificate = getCertificateDiagnostics(d.WebhookCertPath, now)
+	}
+	return report
+}
+
+// ServeHTTP serves the diagnostics report as JSON.
+func (d *Diagnostics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	data, err := json.MarshalIndent(d.report(time.Now()), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func getCertificateDiagnostics(path string, now time.Time) *CertificateDiagnostics {
+	var diagnostics = &CertificateDiagnostics{Path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		diagnostics.Error = err.Error()
+		return diagnostics
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		diagnostics.Error = "no PEM data found"
+		return diagnostics
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		diagnostics.Error = fmt.Sprintf("failed to parse certificate: %v", err)
+		return diagnostics
+	}
+	diagnostics.NotAfter = cert.NotAfter.Format(time.RFC3339)
+	diagnostics.ExpiresInSeconds = int64(cert.NotAfter.Sub(now).Seconds())
+	return diagnostics
+}
@@ -0,0 +1,66 @@
+package flinkcluster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestDiagnostics(t *testing.T) {
+	var now = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	var diagnostics = NewDiagnostics()
+	var newObserved = func(name string, queuePosition int32, apiReachable *bool) *ObservedClusterState {
+		return &ObservedClusterState{
+			cluster: &v1beta1.FlinkCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{QueueLabel: "batch"}},
+				Status:     v1beta1.FlinkClusterStatus{State: v1beta1.ClusterStateRunning},
+			},
+			queuePosition: queuePosition,
+			flinkJob:      FlinkJob{apiReachable: apiReachable},
+		}
+	}
+	var reachable, unreachable = true, false
+	var failing = types.NamespacedName{Namespace: "default", Name: "failing"}
+	var queued = types.NamespacedName{Namespace: "default", Name: "queued"}
+	var healthy = types.NamespacedName{Namespace: "default", Name: "healthy"}
+
+	diagnostics.record(failing, newObserved("failing", 0, &unreachable), fmt.Errorf("conflict"), now)
+	diagnostics.record(failing, newObserved("failing", 0, &unreachable), fmt.Errorf("timeout"), now)
+	diagnostics.record(queued, newObserved("queued", 1, nil), nil, now)
+	diagnostics.record(healthy, newObserved("healthy", 0, &reachable), fmt.Errorf("conflict"), now)
+	diagnostics.record(healthy, newObserved("healthy", 0, &reachable), nil, now)
+
+	var report = diagnostics.report(now)
+	assert.Equal(t, report.ClustersWithErrors, 1)
+	assert.Equal(t, report.FlinkAPIUnreachable, 1)
+	assert.DeepEqual(t, report.QueueDepths, map[string]int{"default/batch": 1})
+	assert.Assert(t, report.WebhookCertificate == nil)
+	assert.Equal(t, len(report.Clusters), 3)
+	assert.DeepEqual(t, report.Clusters[0], ClusterDiagnostics{
+		Namespace:         "default",
+		Name:              "failing",
+		State:             string(v1beta1.ClusterStateRunning),
+		ErrorStreak:       2,
+		LastError:         "timeout",
+		LastErrorTime:     "2022-06-01T12:00:00Z",
+		LastReconcileTime: "2022-06-01T12:00:00Z",
+		FlinkAPIReachable: &unreachable,
+	})
+	assert.Equal(t, report.Clusters[1].Name, "healthy")
+	assert.Equal(t, report.Clusters[1].ErrorStreak, 0)
+	assert.Equal(t, report.Clusters[1].LastError, "conflict")
+
+	// Deleted clusters are removed.
+	diagnostics.record(failing, &ObservedClusterState{}, nil, now)
+	assert.Equal(t, len(diagnostics.report(now).Clusters), 2)
+
+	diagnostics.WebhookCertPath = "/non-existent/tls.crt"
+	var certificate = diagnostics.report(now).WebhookCertificate
+	assert.Equal(t, certificate.Path, "/non-existent/tls.crt")
+	assert.Assert(t, certificate.Error != "")
+}
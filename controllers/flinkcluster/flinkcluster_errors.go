@@ -0,0 +1,92 @@
+package flinkcluster
+
+import (
+	"errors"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// ErrorType classifies the errors of a reconciliation to decide how the request is retried.
+type ErrorType string
+
+const (
+	// Errors of the Kubernetes API which may succeed on retry, e.g. conflicts and timeouts.
+	// The request is requeued with the rate limited backoff of the controller.
+	ErrorTypeTransientK8s ErrorType = "TransientK8s"
+	// The Flink REST API of the JobManager is unavailable, e.g. while it is starting.
+	// The request is requeued after flinkRESTRetryInterval.
+	ErrorTypeFlinkRESTUnavailable ErrorType = "FlinkRESTUnavailable"
+	// The spec cannot be applied, e.g. the API server rejects the resources rendered from it.
+	// A warning event is recorded and the request is not requeued, the cluster is
+	// reconciled again when it or one of its resources changes.
+	ErrorTypeUserConfig ErrorType = "UserConfigError"
+	// Errors which retries cannot fix, e.g. a resource kind which is not served by the API server.
+	// The error is recorded in the status and the components are not reconciled until the
+	// spec of the cluster is updated.
+	ErrorTypePermanent ErrorType = "Permanent"
+)
+
+const flinkRESTRetryInterval = 10 * time.Second
+
+// ReconcileError is an error of a reconciliation with its type.
+type ReconcileError struct {
+	Type ErrorType
+	Err  error
+}
+
+func (e *ReconcileError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ReconcileError) Unwrap() error {
+	return e.Err
+}
+
+func newReconcileError(errorType ErrorType, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ReconcileError{Type: errorType, Err: err}
+}
+
+// newFlinkRESTUnavailableError wraps an error of a Flink REST API request, nil if err is nil.
+func newFlinkRESTUnavailableError(err error) error {
+	return newReconcileError(ErrorTypeFlinkRESTUnavailable, err)
+}
+
+// newUserConfigError wraps an error caused by the spec of the cluster, nil if err is nil.
+func newUserConfigError(err error) error {
+	return newReconcileError(ErrorTypeUserConfig, err)
+}
+
+// newPermanentError wraps an error which retries cannot fix, nil if err is nil.
+func newPermanentError(err error) error {
+	return newReconcileError(ErrorTypePermanent, err)
+}
+
+// getErrorType returns the type of the error. Untyped errors are classified by their
+// Kubernetes API status, and are regarded as transient by default.
+func getErrorType(err error) ErrorType {
+	var reconcileErr *ReconcileError
+	switch {
+	case errors.As(err, &reconcileErr):
+		return reconcileErr.Type
+	case k8serrors.IsInvalid(err), k8serrors.IsBadRequest(err), k8serrors.IsRequestEntityTooLargeError(err):
+		return ErrorTypeUserConfig
+	case meta.IsNoMatchError(err), k8serrors.IsMethodNotSupported(err),
+		k8serrors.IsNotAcceptable(err), k8serrors.IsUnsupportedMediaType(err):
+		return ErrorTypePermanent
+	default:
+		return ErrorTypeTransientK8s
+	}
+}
+
+// isReconcileStopped returns true if a permanent error was recorded for the current generation of the cluster.
+// Deleted clusters are still reconciled to apply spec.deletionPolicy.
+func isReconcileStopped(cluster *v1beta1.FlinkCluster) bool {
+	return cluster != nil && cluster.DeletionTimestamp == nil && cluster.Status.ReconcileError != nil &&
+		cluster.Status.ReconcileError.ObservedGeneration == cluster.Generation
+}
@@ -0,0 +1,83 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestGetErrorType(t *testing.T) {
+	var resource = schema.GroupResource{Group: "apps", Resource: "statefulsets"}
+	var kind = schema.GroupKind{Group: "apps", Kind: "StatefulSet"}
+
+	assert.Equal(t, getErrorType(fmt.Errorf("unknown")), ErrorTypeTransientK8s)
+	assert.Equal(t, getErrorType(k8serrors.NewConflict(resource, "mycluster-jobmanager", fmt.Errorf("conflict"))),
+		ErrorTypeTransientK8s)
+	assert.Equal(t, getErrorType(k8serrors.NewTimeoutError("timeout", 1)), ErrorTypeTransientK8s)
+	assert.Equal(t, getErrorType(k8serrors.NewInvalid(kind, "mycluster-jobmanager", field.ErrorList{
+		field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0"),
+	})), ErrorTypeUserConfig)
+	assert.Equal(t, getErrorType(&meta.NoKindMatchError{GroupKind: kind}), ErrorTypePermanent)
+
+	var err = newFlinkRESTUnavailableError(fmt.Errorf("connection refused"))
+	assert.Equal(t, getErrorType(err), ErrorTypeFlinkRESTUnavailable)
+	assert.Equal(t, getErrorType(fmt.Errorf("failed to stop job: %w", err)), ErrorTypeFlinkRESTUnavailable)
+	assert.Error(t, err, "connection refused")
+	assert.Equal(t, getErrorType(newUserConfigError(fmt.Errorf("unknown scheduler"))), ErrorTypeUserConfig)
+	assert.Equal(t, getErrorType(newPermanentError(fmt.Errorf("unsupported"))), ErrorTypePermanent)
+	assert.NilError(t, newFlinkRESTUnavailableError(nil))
+}
+
+func TestIsReconcileStopped(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+	assert.Assert(t, !isReconcileStopped(nil))
+	assert.Assert(t, !isReconcileStopped(cluster))
+
+	cluster.Status.ReconcileError = &v1beta1.ReconcileErrorStatus{Message: "unsupported", ObservedGeneration: 2}
+	assert.Assert(t, isReconcileStopped(cluster))
+
+	// The spec is updated.
+	cluster.Generation = 3
+	assert.Assert(t, !isReconcileStopped(cluster))
+
+	// The cluster is deleted.
+	cluster.Generation = 2
+	cluster.DeletionTimestamp = &metav1.Time{}
+	assert.Assert(t, !isReconcileStopped(cluster))
+}
+
+func TestHandleError(t *testing.T) {
+	var recorder = record.NewFakeRecorder(1)
+	var handler = FlinkClusterHandler{
+		eventRecorder: recorder,
+		observed:      ObservedClusterState{cluster: &v1beta1.FlinkCluster{}},
+	}
+	var requeue = requeueResult
+
+	result, err := handler.handleError(context.TODO(), requeue, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, result, requeue)
+
+	result, err = handler.handleError(context.TODO(), requeue, fmt.Errorf("conflict"))
+	assert.Error(t, err, "conflict")
+	assert.Equal(t, result, requeue)
+
+	result, err = handler.handleError(context.TODO(), requeue, newFlinkRESTUnavailableError(fmt.Errorf("connection refused")))
+	assert.NilError(t, err)
+	assert.Equal(t, result, ctrl.Result{RequeueAfter: flinkRESTRetryInterval})
+
+	result, err = handler.handleError(context.TODO(), requeue, newUserConfigError(fmt.Errorf("unknown scheduler")))
+	assert.NilError(t, err)
+	assert.Equal(t, result, ctrl.Result{})
+	assert.Equal(t, <-recorder.Events, "Warning InvalidConfig unknown scheduler")
+}
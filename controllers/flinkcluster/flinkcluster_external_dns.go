@@ -0,0 +1,53 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+)
+
+const (
+	// The timeout of the lookups of the external DNS hostname.
+	externalDNSLookupTimeout = 2 * time.Second
+)
+
+// Resolves the external DNS hostnames, replaced in tests.
+var lookupHost = net.DefaultResolver.LookupHost
+
+// getExternalDNSUI returns the URL of the web UI at the external DNS hostname, through the
+// ingress if it is set, otherwise through the port of the service.
+func getExternalDNSUI(cluster *v1beta1.FlinkCluster) string {
+	var hostname = render.ExternalDNSHostname(cluster)
+	if ingress := cluster.Spec.JobManager.Ingress; ingress != nil {
+		if ingress.UseTLS != nil && *ingress.UseTLS {
+			return "https://" + hostname
+		}
+		return "http://" + hostname
+	}
+	var _, uiPort = render.JobManagerUIPort(cluster)
+	return fmt.Sprintf("http://%s:%d", hostname, uiPort)
+}
+
+// Observes whether the external DNS hostname of the JobManager resolves, i.e. external-dns
+// has programmed the record, if spec.jobManager.externalDNS is set.
+func (observer *ClusterStateObserver) observeExternalDNS(ctx context.Context, observed *ObservedClusterState) {
+	var log = logr.FromContextOrDiscard(ctx)
+	observed.externalDNSResolved = false
+	var hostname = render.ExternalDNSHostname(observed.cluster)
+	if hostname == "" || observed.jmService == nil {
+		return
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, externalDNSLookupTimeout)
+	defer cancel()
+	if _, err := lookupHost(lookupCtx, hostname); err != nil {
+		log.Info("External DNS hostname does not resolve yet", "hostname", hostname, "error", err)
+		return
+	}
+	observed.externalDNSResolved = true
+}
@@ -0,0 +1,50 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetExternalDNSUI(t *testing.T) {
+	var cluster = getObservedClusterState().cluster
+	cluster.Spec.JobManager.ExternalDNS = &v1beta1.ExternalDNSSpec{Hostname: "{{$clusterName}}.flink.example.com"}
+	assert.Equal(t, getExternalDNSUI(cluster), "https://fjc.flink.example.com")
+
+	var useTLS = false
+	cluster.Spec.JobManager.Ingress.UseTLS = &useTLS
+	assert.Equal(t, getExternalDNSUI(cluster), "http://fjc.flink.example.com")
+
+	cluster.Spec.JobManager.Ingress = nil
+	assert.Equal(t, getExternalDNSUI(cluster), "http://fjc.flink.example.com:8081")
+}
+
+func TestObserveExternalDNS(t *testing.T) {
+	defer func(lookup func(context.Context, string) ([]string, error)) { lookupHost = lookup }(lookupHost)
+	var resolved = map[string][]string{}
+	lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		if addrs, ok := resolved[host]; ok {
+			return addrs, nil
+		}
+		return nil, fmt.Errorf("lookup %v: no such host", host)
+	}
+	var observer = &ClusterStateObserver{}
+	var observed = getObservedClusterState()
+	observed.jmService = &corev1.Service{}
+	observed.cluster.Spec.JobManager.ExternalDNS = &v1beta1.ExternalDNSSpec{Hostname: "{{$clusterName}}.flink.example.com"}
+
+	observer.observeExternalDNS(context.TODO(), observed)
+	assert.Assert(t, !observed.externalDNSResolved)
+
+	resolved["fjc.flink.example.com"] = []string{"203.0.113.10"}
+	observer.observeExternalDNS(context.TODO(), observed)
+	assert.Assert(t, observed.externalDNSResolved)
+
+	observed.cluster.Spec.JobManager.ExternalDNS = nil
+	observer.observeExternalDNS(context.TODO(), observed)
+	assert.Assert(t, !observed.externalDNSResolved)
+}
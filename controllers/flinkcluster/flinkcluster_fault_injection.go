@@ -0,0 +1,86 @@
+//go:build faultinjection
+
+package flinkcluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The faults of the annotations of the clusters are injected into the reconciliations and
+// the calls of the Flink API, so that the integration tests of the platforms built on the
+// operator can exercise its failure paths deterministically. They are compiled in with the
+// faultinjection build tag only.
+
+// Delays the responses of the Flink API of the cluster by its fault injection annotation.
+func injectFlinkAPIFaults(ctx context.Context, flinkClient *flink.Client, cluster *v1beta1.FlinkCluster) {
+	var log = logr.FromContextOrDiscard(ctx)
+	var delay time.Duration
+	if value, ok := cluster.Annotations[v1beta1.FaultDelayFlinkAPIAnnotation]; ok {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			log.Info("Ignored invalid fault injection annotation", "annotation", v1beta1.FaultDelayFlinkAPIAnnotation, "error", err)
+		} else {
+			delay = parsed
+			log.Info("Injecting Flink API response delay", "delay", delay)
+		}
+	}
+	flinkClient.SetResponseDelay(delay)
+}
+
+// Fails the savepoint if the fault injection annotation requests it, and decrements the
+// number of the savepoints to fail. The savepoint is not failed if the annotation cannot be
+// updated, so that no more savepoints fail than requested.
+func (reconciler *ClusterReconciler) injectSavepointFault(ctx context.Context) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = reconciler.observed.cluster
+	var value, ok = cluster.Annotations[v1beta1.FaultFailSavepointsAnnotation]
+	if !ok {
+		return nil
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil || count <= 0 {
+		log.Info("Ignored invalid fault injection annotation", "annotation", v1beta1.FaultFailSavepointsAnnotation, "value", value)
+		return nil
+	}
+
+	var remaining interface{} = strconv.Itoa(count - 1)
+	if count == 1 {
+		remaining = nil
+	}
+	var annotationPatch = objectForPatch{
+		Metadata: objectMetaForPatch{
+			Annotations: map[string]interface{}{v1beta1.FaultFailSavepointsAnnotation: remaining},
+		},
+	}
+	patchBytes, err := json.Marshal(&annotationPatch)
+	if err == nil {
+		err = reconciler.k8sClient.Patch(ctx, cluster, client.RawPatch(types.MergePatchType, patchBytes))
+	}
+	if err != nil {
+		log.Info("Failed to update fault injection annotation, savepoint not failed", "error", err)
+		return nil
+	}
+	log.Info("Injecting savepoint failure", "remaining", count-1)
+	return fmt.Errorf("savepoint failure injected by the %v annotation", v1beta1.FaultFailSavepointsAnnotation)
+}
+
+// Checks whether the creation of the component is dropped by the fault injection annotation.
+func dropCreation(cluster *v1beta1.FlinkCluster, component string) bool {
+	for _, dropped := range strings.Split(cluster.Annotations[v1beta1.FaultDropCreationsAnnotation], ",") {
+		if strings.TrimSpace(dropped) == component {
+			return true
+		}
+	}
+	return false
+}
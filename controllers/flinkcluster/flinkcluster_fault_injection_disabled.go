@@ -0,0 +1,24 @@
+//go:build !faultinjection
+
+package flinkcluster
+
+import (
+	"context"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+)
+
+// No faults are injected unless built with the faultinjection build tag, see
+// flinkcluster_fault_injection.go.
+
+func injectFlinkAPIFaults(ctx context.Context, flinkClient *flink.Client, cluster *v1beta1.FlinkCluster) {
+}
+
+func (reconciler *ClusterReconciler) injectSavepointFault(ctx context.Context) error {
+	return nil
+}
+
+func dropCreation(cluster *v1beta1.FlinkCluster, component string) bool {
+	return false
+}
@@ -0,0 +1,68 @@
+//go:build faultinjection
+
+package flinkcluster
+
+import (
+	"context"
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestInjectSavepointFault(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "mycluster",
+			Namespace:   "default",
+			Annotations: map[string]string{v1beta1.FaultFailSavepointsAnnotation: "2"},
+		},
+	}
+	var scheme = runtime.NewScheme()
+	assert.NilError(t, v1beta1.AddToScheme(scheme))
+	var k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	var reconciler = &ClusterReconciler{k8sClient: k8sClient, observed: ObservedClusterState{cluster: cluster}}
+
+	// The requested number of savepoints fail, then the annotation is removed.
+	assert.ErrorContains(t, reconciler.injectSavepointFault(context.TODO()), "savepoint failure injected")
+	var updated = new(v1beta1.FlinkCluster)
+	assert.NilError(t, k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cluster), updated))
+	assert.Equal(t, updated.Annotations[v1beta1.FaultFailSavepointsAnnotation], "1")
+
+	reconciler.observed.cluster = updated
+	assert.ErrorContains(t, reconciler.injectSavepointFault(context.TODO()), "savepoint failure injected")
+	assert.NilError(t, k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cluster), updated))
+	_, ok := updated.Annotations[v1beta1.FaultFailSavepointsAnnotation]
+	assert.Assert(t, !ok)
+
+	reconciler.observed.cluster = updated
+	assert.NilError(t, reconciler.injectSavepointFault(context.TODO()))
+
+	// Invalid counts are ignored.
+	updated.Annotations = map[string]string{v1beta1.FaultFailSavepointsAnnotation: "all"}
+	assert.NilError(t, reconciler.injectSavepointFault(context.TODO()))
+}
+
+func TestDropCreation(t *testing.T) {
+	var observed = getObservedClusterState()
+	var k8sClient = fake.NewClientBuilder().Build()
+	var reconciler = &ClusterReconciler{k8sClient: k8sClient, observed: *observed}
+	var service = &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "fjc-jobmanager", Namespace: "default"}}
+
+	observed.cluster.Annotations = map[string]string{v1beta1.FaultDropCreationsAnnotation: "TaskManager, JobManagerService"}
+	assert.Assert(t, dropCreation(observed.cluster, "TaskManager"))
+	assert.Assert(t, !dropCreation(observed.cluster, "JobManager"))
+
+	assert.NilError(t, reconciler.createComponent(context.TODO(), service, "JobManagerService"))
+	var created = new(corev1.Service)
+	assert.Assert(t, k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(service), created) != nil)
+
+	observed.cluster.Annotations = nil
+	assert.NilError(t, reconciler.createComponent(context.TODO(), service, "JobManagerService"))
+	assert.NilError(t, k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(service), created))
+}
@@ -0,0 +1,59 @@
+package flinkcluster
+
+import (
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+)
+
+// isScaledForIdlePolicy returns true if the observed replicas of a component are to be scaled
+// to or from zero for spec.idlePolicy. The replicas are not compared otherwise, they may be
+// managed by the HorizontalPodAutoscaler.
+func isScaledForIdlePolicy(desired *int32, observed *int32) bool {
+	var isZero = func(replicas *int32) bool { return replicas != nil && *replicas == 0 }
+	return isZero(desired) != isZero(observed)
+}
+
+// hasActiveJobs returns true if a job of the session cluster is not terminated, including
+// the jobs waiting for TaskManagers.
+func hasActiveJobs(jobs *flink.JobsOverview) bool {
+	for _, job := range jobs.Jobs {
+		if getFlinkJobDeploymentState(job.State) == v1beta1.JobStateRunning {
+			return true
+		}
+	}
+	return false
+}
+
+// deriveIdleStatus derives the status of spec.idlePolicy. The cluster is idle since its jobs
+// are observed terminated, or since it was woken up with the `wake-up` user control, and it
+// is scaled to zero once it has been idle for spec.idlePolicy.idleMinutes. The idle time is
+// kept while the jobs are not observed, e.g. while the JobManager is scaled to zero.
+func deriveIdleStatus(observed *ObservedClusterState, now time.Time) *v1beta1.IdleStatus {
+	var cluster = observed.cluster
+	if !render.IsIdlePolicyEnabled(cluster) {
+		return nil
+	}
+
+	var tc = &util.TimeConverter{}
+	var recorded = cluster.Status.Idle
+	var jobs = observed.sessionJobs
+	switch {
+	case cluster.Annotations[v1beta1.ControlAnnotation] == v1beta1.ControlNameWakeUp:
+		return &v1beta1.IdleStatus{IdleSince: tc.ToString(now)}
+	case jobs != nil && hasActiveJobs(jobs):
+		return &v1beta1.IdleStatus{}
+	case recorded == nil || (recorded.IdleSince == "" && jobs != nil):
+		return &v1beta1.IdleStatus{IdleSince: tc.ToString(now)}
+	}
+
+	var s = recorded.DeepCopy()
+	var idleSeconds = int(cluster.Spec.IdlePolicy.IdleMinutes) * 60
+	if !s.ScaledToZero && s.IdleSince != "" && util.HasTimeElapsed(s.IdleSince, now, idleSeconds) {
+		s.ScaledToZero = true
+	}
+	return s
+}
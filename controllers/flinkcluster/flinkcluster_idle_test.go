@@ -0,0 +1,92 @@
+package flinkcluster
+
+import (
+	"testing"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getIdleCluster() *v1beta1.FlinkCluster {
+	var replicas int32 = 4
+	var jmReplicas int32 = 1
+	return &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			JobManager:  &v1beta1.JobManagerSpec{Replicas: &jmReplicas},
+			TaskManager: &v1beta1.TaskManagerSpec{Replicas: &replicas},
+			IdlePolicy:  &v1beta1.IdlePolicy{IdleMinutes: 30},
+		},
+	}
+}
+
+func TestIsScaledForIdlePolicy(t *testing.T) {
+	var zero, one, four int32 = 0, 1, 4
+	assert.Assert(t, isScaledForIdlePolicy(&zero, &four))
+	assert.Assert(t, isScaledForIdlePolicy(&one, &zero))
+	assert.Assert(t, !isScaledForIdlePolicy(&one, &four))
+	assert.Assert(t, !isScaledForIdlePolicy(&zero, &zero))
+}
+
+func TestDeriveIdleStatus(t *testing.T) {
+	var tc = &util.TimeConverter{}
+	var now = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	var observed = &ObservedClusterState{cluster: getIdleCluster()}
+	var running = &flink.JobsOverview{Jobs: []flink.Job{{Id: "a", State: "FINISHED"}, {Id: "b", State: "RUNNING"}}}
+	var finished = &flink.JobsOverview{Jobs: []flink.Job{{Id: "a", State: "FINISHED"}}}
+
+	// The idle time starts when the cluster is created.
+	var s = deriveIdleStatus(observed, now)
+	assert.DeepEqual(t, s, &v1beta1.IdleStatus{IdleSince: tc.ToString(now)})
+
+	// Running jobs keep the cluster active.
+	observed.sessionJobs = running
+	s = deriveIdleStatus(observed, now)
+	assert.DeepEqual(t, s, &v1beta1.IdleStatus{})
+
+	// The idle time starts when the jobs are observed terminated.
+	observed.cluster.Status.Idle = s
+	observed.sessionJobs = finished
+	s = deriveIdleStatus(observed, now)
+	assert.DeepEqual(t, s, &v1beta1.IdleStatus{IdleSince: tc.ToString(now)})
+
+	observed.cluster.Status.Idle = s
+	s = deriveIdleStatus(observed, now.Add(30*time.Minute))
+	assert.Assert(t, !s.ScaledToZero)
+	s = deriveIdleStatus(observed, now.Add(31*time.Minute))
+	assert.Assert(t, s.ScaledToZero)
+	assert.Equal(t, s.IdleSince, tc.ToString(now))
+
+	// The cluster stays scaled to zero while the jobs are not observed.
+	observed.cluster.Status.Idle = s
+	observed.sessionJobs = nil
+	s = deriveIdleStatus(observed, now.Add(time.Hour))
+	assert.Assert(t, s.ScaledToZero)
+
+	// The wake-up control restarts the idle time.
+	observed.cluster.ObjectMeta = metav1.ObjectMeta{
+		Annotations: map[string]string{v1beta1.ControlAnnotation: v1beta1.ControlNameWakeUp},
+	}
+	s = deriveIdleStatus(observed, now.Add(time.Hour))
+	assert.DeepEqual(t, s, &v1beta1.IdleStatus{IdleSince: tc.ToString(now.Add(time.Hour))})
+
+	// Session clusters without the idle policy have no idle status.
+	observed.cluster.Spec.IdlePolicy = nil
+	assert.Assert(t, deriveIdleStatus(observed, now) == nil)
+}
+
+func TestDeriveWakeUpControlStatus(t *testing.T) {
+	var cluster = getIdleCluster()
+	cluster.Annotations = map[string]string{v1beta1.ControlAnnotation: v1beta1.ControlNameWakeUp}
+
+	var control = deriveControlStatus(cluster, nil, nil, nil)
+	assert.Equal(t, control.Name, v1beta1.ControlNameWakeUp)
+	assert.Equal(t, control.State, v1beta1.ControlStateRequested)
+
+	cluster.Status.Control = control
+	control = deriveControlStatus(cluster, nil, nil, cluster.Status.Control)
+	assert.Equal(t, control.State, v1beta1.ControlStateSucceeded)
+}
@@ -0,0 +1,197 @@
+package flinkcluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/transfer"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// The annotation of the JAR uploader Job with the JSON of the JAR files it uploads.
+	jarUploaderRequestAnnotation = "flinkoperator.k8s.io/jar-upload-request"
+	// The annotation of the failed JAR uploader Job once its failure is reported.
+	jarUploaderReportedAnnotation = "flinkoperator.k8s.io/failure-reported"
+	// The maximum number of JAR files uploaded by a JAR uploader Job, so that its results fit
+	// into the termination message of its container.
+	maxJarsPerUploader = 16
+	// The time after which a failed JAR uploader Job is retried.
+	jarUploaderRetryInterval = 5 * time.Minute
+	// The time after which a JAR uploader Job is stopped.
+	jarUploaderDeadline = 30 * time.Minute
+)
+
+// getJarFileName returns the file name to upload a JAR file of spec.jars as.
+// The JobManager accepts only file names with the .jar extension.
+func getJarFileName(jar v1beta1.SessionJar) string {
+	var fileName string
+	if u, err := url.Parse(jar.URI); err == nil {
+		fileName = path.Base(u.Path)
+	}
+	if !strings.HasSuffix(fileName, ".jar") {
+		fileName = jar.Name + ".jar"
+	}
+	return fileName
+}
+
+// findUploadedJar returns the first of the JAR files which matches and is still uploaded to
+// the JobManager, nil if none.
+func findUploadedJar(
+	jars []v1beta1.SessionJarStatus,
+	uploaded map[string]bool,
+	match func(v1beta1.SessionJarStatus) bool) *v1beta1.SessionJarStatus {
+	for i := range jars {
+		if uploaded[jars[i].ID] && match(jars[i]) {
+			return &jars[i]
+		}
+	}
+	return nil
+}
+
+// Gets the name of the Kubernetes Job which uploads the JAR files of spec.jars.
+func getJarUploaderJobName(clusterName string) string {
+	return clusterName + "-jar-uploader"
+}
+
+// newJarUploaderJob returns the Kubernetes Job which runs the `upload-jars` command of the
+// operator image to download the JAR files with the credentials of the service account of
+// the cluster and upload them to the JobManager, unless a JAR file with the same checksum is
+// among the uploaded ones, the IDs of the JAR files in the JobManager by their checksums. The
+// image is pulled with the given image pull secrets unless the cluster sets
+// spec.image.pullSecrets.
+func newJarUploaderJob(
+	cluster *v1beta1.FlinkCluster,
+	image string,
+	imagePullSecrets []corev1.LocalObjectReference,
+	jars []v1beta1.SessionJar,
+	uploaded map[string]string) *batchv1.Job {
+	var request = getJarUploadRequest(jars)
+	var uploadedJSON, _ = json.Marshal(uploaded)
+	var labels = render.ComponentLabels(cluster, "jar-uploader")
+	var backoffLimit int32 = 0
+	var deadline = int64(jarUploaderDeadline.Seconds())
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       cluster.Namespace,
+			Name:            getJarUploaderJobName(cluster.Name),
+			OwnerReferences: []metav1.OwnerReference{render.ToOwnerReference(cluster)},
+			Labels:          labels,
+			Annotations:     map[string]string{jarUploaderRequestAnnotation: request},
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "main",
+						Image: image,
+						Args: []string{
+							"upload-jars",
+							"--jobmanager", getFlinkAPIBaseURL(cluster),
+							"--jars", request,
+							"--uploaded", string(uploadedJSON),
+						},
+						Env:     cluster.Spec.EnvVars,
+						EnvFrom: cluster.Spec.EnvFrom,
+					}},
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ImagePullSecrets:   render.ImagePullSecrets(cluster, imagePullSecrets),
+					ServiceAccountName: render.ServiceAccountName(cluster),
+				},
+			},
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &deadline,
+		},
+	}
+}
+
+// getJarUploadRequest returns the JSON of the JAR files to upload.
+func getJarUploadRequest(jars []v1beta1.SessionJar) string {
+	var request = make([]transfer.Jar, len(jars))
+	for i, jar := range jars {
+		request[i] = transfer.Jar{Name: jar.Name, URI: jar.URI, SHA256: jar.SHA256, FileName: getJarFileName(jar)}
+	}
+	var data, _ = json.Marshal(request)
+	return string(data)
+}
+
+// getJarUploaderResult returns the status of the JAR files uploaded by the Kubernetes Job,
+// or why it failed. Both are empty while the Job is running.
+func getJarUploaderResult(job *batchv1.Job, pod *corev1.Pod) ([]v1beta1.SessionJarStatus, string) {
+	if job.Status.Succeeded == 0 && job.Status.Failed == 0 {
+		return nil, ""
+	}
+	var output string
+	if pod != nil && len(pod.Status.ContainerStatuses) > 0 {
+		if terminated := pod.Status.ContainerStatuses[0].State.Terminated; terminated != nil {
+			output = strings.TrimSpace(terminated.Message)
+		}
+	}
+	if job.Status.Succeeded == 0 {
+		if output == "" {
+			output = "no output"
+		}
+		return nil, fmt.Sprintf("JAR uploader job %v failed: %v", job.Name, output)
+	}
+
+	var request []transfer.Jar
+	var results []transfer.UploadedJar
+	if err := json.Unmarshal([]byte(job.Annotations[jarUploaderRequestAnnotation]), &request); err != nil {
+		return nil, fmt.Sprintf("JAR uploader job %v has an invalid request: %v", job.Name, err)
+	}
+	if err := json.Unmarshal([]byte(output), &results); err != nil {
+		return nil, fmt.Sprintf("JAR uploader job %v has an invalid result: %v", job.Name, err)
+	}
+	var uris = map[string]string{}
+	for _, jar := range request {
+		uris[jar.Name] = jar.URI
+	}
+	var jars = []v1beta1.SessionJarStatus{}
+	for _, result := range results {
+		jars = append(jars, v1beta1.SessionJarStatus{Name: result.Name, URI: uris[result.Name], SHA256: result.SHA256, ID: result.ID})
+	}
+	return jars, ""
+}
+
+// getJarUploaderRetryTime returns when the failed JAR uploader Job is retried.
+func getJarUploaderRetryTime(job *batchv1.Job) time.Time {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Add(jarUploaderRetryInterval)
+		}
+	}
+	return job.CreationTimestamp.Add(jarUploaderRetryInterval)
+}
+
+func (observer *ClusterStateObserver) observeJarUploaderJob(
+	ctx context.Context,
+	observed *ObservedClusterState) error {
+	var jobName = getJarUploaderJobName(observed.cluster.Name)
+	var job = new(batchv1.Job)
+	observed.jarUploaderJob = nil
+	observed.jarUploaderPod = nil
+	if err := observer.observeObject(ctx, jobName, job); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	observed.jarUploaderJob = job
+
+	var pod = new(corev1.Pod)
+	if err := observer.observeJobSubmitterPod(ctx, jobName, pod); err != nil {
+		return err
+	}
+	if pod.Name != "" {
+		observed.jarUploaderPod = pod
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package flinkcluster
+
+import (
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetJarFileName(t *testing.T) {
+	assert.Equal(t, getJarFileName(v1beta1.SessionJar{Name: "wordcount", URI: "gs://my-bucket/jobs/wordcount-1.0.jar"}),
+		"wordcount-1.0.jar")
+	assert.Equal(t, getJarFileName(v1beta1.SessionJar{Name: "wordcount", URI: "https://example.com/download?id=1"}),
+		"wordcount.jar")
+}
+
+func TestJarUploaderJob(t *testing.T) {
+	var uiPort int32 = 8081
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "session", Namespace: "default"},
+		Spec: v1beta1.FlinkClusterSpec{
+			JobManager: &v1beta1.JobManagerSpec{Ports: v1beta1.JobManagerPorts{UI: &uiPort}},
+			EnvVars:    []corev1.EnvVar{{Name: "AWS_REGION", Value: "eu-west-1"}},
+		},
+	}
+	var jars = []v1beta1.SessionJar{{Name: "wordcount", URI: "s3://my-bucket/jobs/wordcount-1.0.jar", SHA256: "aa"}}
+	var job = newJarUploaderJob(cluster, "ghcr.io/spotify/flink-operator:v1", nil, jars, map[string]string{"bb": "2_topspeed.jar"})
+	assert.Equal(t, job.Name, "session-jar-uploader")
+	var container = job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, container.Image, "ghcr.io/spotify/flink-operator:v1")
+	var request = `[{"name":"wordcount","uri":"s3://my-bucket/jobs/wordcount-1.0.jar","sha256":"aa","fileName":"wordcount-1.0.jar"}]`
+	assert.DeepEqual(t, container.Args, []string{
+		"upload-jars",
+		"--jobmanager", "http://session-jobmanager.default.svc.cluster.local:8081",
+		"--jars", request,
+		"--uploaded", `{"bb":"2_topspeed.jar"}`,
+	})
+	assert.DeepEqual(t, container.Env, cluster.Spec.EnvVars)
+	assert.Equal(t, job.Annotations[jarUploaderRequestAnnotation], request)
+
+	// Running.
+	jarStatus, failure := getJarUploaderResult(job, nil)
+	assert.Assert(t, jarStatus == nil)
+	assert.Equal(t, failure, "")
+
+	var pod = &corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			Message: `[{"name":"wordcount","sha256":"aa","id":"3_wordcount-1.0.jar"}]`,
+		}},
+	}}}}
+	job.Status.Succeeded = 1
+	jarStatus, failure = getJarUploaderResult(job, pod)
+	assert.Equal(t, failure, "")
+	assert.DeepEqual(t, jarStatus, []v1beta1.SessionJarStatus{
+		{Name: "wordcount", URI: "s3://my-bucket/jobs/wordcount-1.0.jar", SHA256: "aa", ID: "3_wordcount-1.0.jar"},
+	})
+
+	job.Status.Succeeded = 0
+	job.Status.Failed = 1
+	pod.Status.ContainerStatuses[0].State.Terminated.Message = "failed to download JAR file wordcount: GET s3://my-bucket/jobs/wordcount-1.0.jar: access denied: 403 Forbidden"
+	_, failure = getJarUploaderResult(job, pod)
+	assert.Equal(t, failure, "JAR uploader job session-jar-uploader failed: "+
+		"failed to download JAR file wordcount: GET s3://my-bucket/jobs/wordcount-1.0.jar: access denied: 403 Forbidden")
+}
+
+func TestGetUploadedJarStatus(t *testing.T) {
+	var known = []v1beta1.SessionJarStatus{
+		{Name: "wordcount", URI: "gs://my-bucket/wordcount.jar", SHA256: "aa", ID: "1_wordcount.jar"},
+	}
+	var uploaded = map[string]bool{"1_wordcount.jar": true}
+	var jar = v1beta1.SessionJar{Name: "wordcount", URI: "gs://my-bucket/wordcount.jar"}
+	assert.DeepEqual(t, getUploadedJarStatus(jar, known, uploaded), &known[0])
+
+	// Another JAR file with the same checksum shares the upload.
+	jar = v1beta1.SessionJar{Name: "copy", URI: "gs://my-bucket/copy.jar", SHA256: "AA"}
+	assert.DeepEqual(t, getUploadedJarStatus(jar, known, uploaded),
+		&v1beta1.SessionJarStatus{Name: "copy", URI: "gs://my-bucket/copy.jar", SHA256: "aa", ID: "1_wordcount.jar"})
+
+	jar = v1beta1.SessionJar{Name: "wordcount", URI: "gs://my-bucket/wordcount-2.jar"}
+	assert.Assert(t, getUploadedJarStatus(jar, known, uploaded) == nil)
+	jar = v1beta1.SessionJar{Name: "wordcount", URI: "gs://my-bucket/wordcount.jar"}
+	assert.Assert(t, getUploadedJarStatus(jar, known, map[string]bool{}) == nil)
+}
+
+func TestFindUploadedJar(t *testing.T) {
+	var jars = []v1beta1.SessionJarStatus{
+		{Name: "wordcount", SHA256: "aa", ID: "1_wordcount.jar"},
+		{Name: "topspeed", SHA256: "bb", ID: "2_topspeed.jar"},
+	}
+	var sha = func(checksum string) func(v1beta1.SessionJarStatus) bool {
+		return func(j v1beta1.SessionJarStatus) bool { return j.SHA256 == checksum }
+	}
+	var uploaded = map[string]bool{"2_topspeed.jar": true}
+
+	assert.Assert(t, findUploadedJar(jars, uploaded, sha("aa")) == nil)
+	assert.DeepEqual(t, findUploadedJar(jars, uploaded, sha("bb")), &jars[1])
+	assert.Assert(t, findUploadedJar(nil, uploaded, sha("bb")) == nil)
+}
@@ -0,0 +1,175 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const jobPlanFileName = "plan.json"
+
+// The maximum size of the plans stored in the ConfigMap of the plans, below the 1 MiB limit of
+// ConfigMaps to leave room for the metadata.
+const jobPlanConfigMapMaxBytes = 1000 * 1024
+
+func getJobPlanConfigMapName(clusterName string) string {
+	return clusterName + "-job-plan"
+}
+
+func getJobPlanConfigMapKey(revision string) string {
+	return revision + ".json"
+}
+
+// shouldStoreJobPlan returns true if spec.job.planArchive is set and the plan of the running
+// job is not stored yet.
+func shouldStoreJobPlan(jobSpec *v1beta1.JobSpec, job *v1beta1.JobStatus, revision *v1beta1.RevisionStatus) bool {
+	return jobSpec != nil && jobSpec.PlanArchive != nil && job != nil &&
+		job.State == v1beta1.JobStateRunning && job.ID != "" && revision.CurrentRevision != "" &&
+		(job.Plan == nil || job.Plan.JobID != job.ID)
+}
+
+// newJobPlanConfigMap returns the ConfigMap of the plans with the plan of the revision. The
+// plans of the revisions no longer in the revision history are removed.
+func newJobPlanConfigMap(
+	cluster *v1beta1.FlinkCluster,
+	recorded *corev1.ConfigMap,
+	revision string,
+	plan []byte,
+	revisions []*appsv1.ControllerRevision) (*corev1.ConfigMap, error) {
+	var kept = map[string]bool{}
+	for _, r := range revisions {
+		kept[getJobPlanConfigMapKey(r.Name)] = true
+	}
+	var data = map[string]string{}
+	if recorded != nil {
+		for key, value := range recorded.Data {
+			if kept[key] {
+				data[key] = value
+			}
+		}
+	}
+	data[getJobPlanConfigMapKey(revision)] = string(plan)
+
+	var size int
+	for _, value := range data {
+		size += len(value)
+	}
+	if size > jobPlanConfigMapMaxBytes {
+		return nil, fmt.Errorf("plans of %v revisions exceed the size limit of ConfigMaps, set spec.job.planArchive.upload to upload them", len(data))
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       cluster.Namespace,
+			Name:            getJobPlanConfigMapName(cluster.Name),
+			Labels:          render.ClusterLabels(cluster),
+			OwnerReferences: []metav1.OwnerReference{render.ToOwnerReference(cluster)},
+		},
+		Data: data,
+	}, nil
+}
+
+// Stores the plan of the running job for spec.job.planArchive, once for each job. A failure
+// is retried in the next reconciliation while the job is running.
+func (reconciler *ClusterReconciler) reconcileJobPlan(ctx context.Context) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = reconciler.observed.cluster
+	var job = cluster.Status.Components.Job
+	if !shouldStoreJobPlan(cluster.Spec.Job, job, &cluster.Status.Revision) {
+		return nil
+	}
+
+	var revision = getCurrentRevisionName(&cluster.Status.Revision)
+	var status = &v1beta1.JobPlanStatus{JobID: job.ID, Revision: revision}
+	plan, err := reconciler.flinkClient.GetJobPlan(getFlinkAPIBaseURL(cluster), job.ID)
+	if err == nil {
+		if upload := cluster.Spec.Job.PlanArchive.Upload; upload != nil {
+			status.URL, err = reconciler.uploadJobPlan(ctx, upload, revision, plan.Plan)
+		} else {
+			status.ConfigMapName, err = reconciler.storeJobPlan(ctx, revision, plan.Plan)
+		}
+	}
+	if err != nil {
+		log.Info("Failed to store job plan, will retry", "jobID", job.ID, "error", err)
+		reconciler.recorder.Eventf(cluster, corev1.EventTypeWarning, "JobPlanStoreFailed",
+			"Failed to store the plan of job %v: %v", job.ID, err)
+		return nil
+	}
+	util.SetTimestamp(&status.StoreTime)
+	reconciler.recorder.Eventf(cluster, corev1.EventTypeNormal, "JobPlanStored",
+		"Stored the plan of job %v of revision %v", job.ID, revision)
+	return reconciler.updateJobPlanStatus(ctx, status)
+}
+
+// Stores the plan in the ConfigMap of the plans, returns the name of the ConfigMap.
+func (reconciler *ClusterReconciler) storeJobPlan(ctx context.Context, revision string, plan []byte) (string, error) {
+	var observed = &reconciler.observed
+	var cluster = observed.cluster
+	var name = getJobPlanConfigMapName(cluster.Name)
+	var recorded = new(corev1.ConfigMap)
+	var err = reconciler.k8sClient.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: name}, recorded)
+	if errors.IsNotFound(err) {
+		recorded = nil
+	} else if err != nil {
+		return "", err
+	}
+
+	configMap, err := newJobPlanConfigMap(cluster, recorded, revision, plan, observed.revisions)
+	if err != nil {
+		return "", err
+	}
+	if recorded == nil {
+		err = reconciler.k8sClient.Create(ctx, configMap)
+	} else {
+		configMap.ResourceVersion = recorded.ResourceVersion
+		err = reconciler.k8sClient.Update(ctx, configMap)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to store job plan in ConfigMap %v: %v", name, err)
+	}
+	var keys []string
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	logr.FromContextOrDiscard(ctx).Info("Stored job plan", "configMap", name, "keys", keys)
+	return name, nil
+}
+
+// Uploads the plan to `<uri>/<namespace>/<cluster>/<revision>/plan.json`, returns its location.
+func (reconciler *ClusterReconciler) uploadJobPlan(
+	ctx context.Context, upload *v1beta1.DiagnosticsUpload, revision string, plan []byte) (string, error) {
+	var cluster = reconciler.observed.cluster
+	location, err := getDiagnosticsUploadLocation(upload.URI, cluster.Namespace, cluster.Name, revision, jobPlanFileName)
+	if err != nil {
+		return "", err
+	}
+	authorization, err := reconciler.getUploadAuthorization(ctx, upload)
+	if err != nil {
+		return "", err
+	}
+	return location, uploadDiagnosticsFile(ctx, location, authorization, plan)
+}
+
+func (reconciler *ClusterReconciler) updateJobPlanStatus(ctx context.Context, plan *v1beta1.JobPlanStatus) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var clusterClone = reconciler.observed.cluster.DeepCopy()
+	clusterClone.Status.Components.Job.Plan = plan
+	util.SetTimestamp(&clusterClone.Status.LastUpdateTime)
+	var err = reconciler.k8sClient.Status().Update(ctx, clusterClone)
+	if err != nil {
+		log.Error(err, "Failed to update job plan status", "error", err)
+	} else {
+		log.Info("Succeeded to update job plan status.", "plan", plan)
+	}
+	return err
+}
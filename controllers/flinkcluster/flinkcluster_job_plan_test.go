@@ -0,0 +1,62 @@
+package flinkcluster
+
+import (
+	"strings"
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestShouldStoreJobPlan(t *testing.T) {
+	var jobSpec = &v1beta1.JobSpec{PlanArchive: &v1beta1.JobPlanArchive{}}
+	var revision = &v1beta1.RevisionStatus{CurrentRevision: "mycluster-85dc8f749-1"}
+	var job = &v1beta1.JobStatus{ID: "8f5a5b7e0a7f4c1bd3c6e5b4a3928170", State: v1beta1.JobStateRunning}
+	assert.Assert(t, shouldStoreJobPlan(jobSpec, job, revision))
+	assert.Assert(t, !shouldStoreJobPlan(&v1beta1.JobSpec{}, job, revision))
+
+	// The plan is stored once for each job.
+	job.Plan = &v1beta1.JobPlanStatus{JobID: job.ID}
+	assert.Assert(t, !shouldStoreJobPlan(jobSpec, job, revision))
+	job.Plan.JobID = "0c4b2e1f3a5d6c7b8a9f0e1d2c3b4a59"
+	assert.Assert(t, shouldStoreJobPlan(jobSpec, job, revision))
+
+	job.State = v1beta1.JobStateDeploying
+	assert.Assert(t, !shouldStoreJobPlan(jobSpec, job, revision))
+}
+
+func TestNewJobPlanConfigMap(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "mycluster", Namespace: "default"},
+	}
+	var revisions = []*appsv1.ControllerRevision{
+		{ObjectMeta: metav1.ObjectMeta{Name: "mycluster-6c9d8b7f5"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "mycluster-85dc8f749"}},
+	}
+	var recorded = &corev1.ConfigMap{Data: map[string]string{
+		"mycluster-5b8c7d6e4.json": `{"jid":"a"}`,
+		"mycluster-6c9d8b7f5.json": `{"jid":"b"}`,
+	}}
+
+	// The plans of the revisions pruned from the revision history are removed.
+	var configMap, err = newJobPlanConfigMap(cluster, recorded, "mycluster-85dc8f749", []byte(`{"jid":"c"}`), revisions)
+	assert.NilError(t, err)
+	assert.Equal(t, configMap.Name, "mycluster-job-plan")
+	assert.DeepEqual(t, configMap.Data, map[string]string{
+		"mycluster-6c9d8b7f5.json": `{"jid":"b"}`,
+		"mycluster-85dc8f749.json": `{"jid":"c"}`,
+	})
+	assert.DeepEqual(t, configMap.OwnerReferences, []metav1.OwnerReference{render.ToOwnerReference(cluster)})
+
+	configMap, err = newJobPlanConfigMap(cluster, nil, "mycluster-85dc8f749", []byte(`{"jid":"c"}`), nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, configMap.Data, map[string]string{"mycluster-85dc8f749.json": `{"jid":"c"}`})
+
+	var plan = []byte(strings.Repeat("x", jobPlanConfigMapMaxBytes+1))
+	_, err = newJobPlanConfigMap(cluster, recorded, "mycluster-85dc8f749", plan, revisions)
+	assert.Error(t, err, "plans of 2 revisions exceed the size limit of ConfigMaps, set spec.job.planArchive.upload to upload them")
+}
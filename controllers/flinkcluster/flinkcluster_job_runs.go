@@ -0,0 +1,71 @@
+package flinkcluster
+
+import (
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The number of runs recorded for restartable jobs when spec.job.runHistoryLimit is not set.
+const defaultRunHistoryLimit = 10
+
+func getRunHistoryLimit(jobSpec *v1beta1.JobSpec) int {
+	switch {
+	case jobSpec.RunHistoryLimit != nil:
+		return int(*jobSpec.RunHistoryLimit)
+	case jobSpec.RestartPolicy != nil && *jobSpec.RestartPolicy == v1beta1.JobRestartPolicyFromSavepointOnFailure:
+		return defaultRunHistoryLimit
+	}
+	return 0
+}
+
+// deriveJobRuns records the runs of the job from the transition of its state from the old
+// state to the state of the new job status. A run starts when the job is deployed, and it is
+// over once the job stops, or when the job is pending again before it stopped, e.g. for an
+// update.
+func deriveJobRuns(jobSpec *v1beta1.JobSpec, oldState v1beta1.JobState, newJob *v1beta1.JobStatus, submitterPod *corev1.Pod) {
+	var limit = getRunHistoryLimit(jobSpec)
+	if limit == 0 {
+		newJob.Runs = nil
+		return
+	}
+
+	if newJob.State == v1beta1.JobStateDeploying && oldState != v1beta1.JobStateDeploying {
+		newJob.Runs = append(newJob.Runs, v1beta1.JobRunStatus{FromSavepoint: newJob.FromSavepoint})
+	}
+	if len(newJob.Runs) > limit {
+		newJob.Runs = append([]v1beta1.JobRunStatus(nil), newJob.Runs[len(newJob.Runs)-limit:]...)
+	}
+	if len(newJob.Runs) == 0 {
+		return
+	}
+
+	var run = &newJob.Runs[len(newJob.Runs)-1]
+	// The lost job is recovered by the JobManager, the run goes on.
+	if run.State == v1beta1.JobStateLost && newJob.State == v1beta1.JobStateRunning {
+		run.State = ""
+		run.CompletionTime = nil
+	}
+	if run.State != "" {
+		return
+	}
+	if newJob.ID != "" {
+		run.ID = newJob.ID
+	}
+	if submitterPod != nil {
+		run.SubmitterPod = submitterPod.Name
+	}
+	switch {
+	case newJob.State == v1beta1.JobStateRunning:
+		if run.StartTime == "" {
+			run.StartTime = newJob.StartTime
+		}
+	case newJob.IsStopped():
+		run.State = newJob.State
+		run.CompletionTime = newJob.CompletionTime.DeepCopy()
+	case newJob.IsPending():
+		var now = metav1.Now()
+		run.State = newJob.State
+		run.CompletionTime = &now
+	}
+}
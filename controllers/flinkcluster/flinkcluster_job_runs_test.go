@@ -0,0 +1,88 @@
+package flinkcluster
+
+import (
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetRunHistoryLimit(t *testing.T) {
+	var never, onFailure = v1beta1.JobRestartPolicyNever, v1beta1.JobRestartPolicyFromSavepointOnFailure
+	var limit int32 = 3
+	assert.Equal(t, getRunHistoryLimit(&v1beta1.JobSpec{}), 0)
+	assert.Equal(t, getRunHistoryLimit(&v1beta1.JobSpec{RestartPolicy: &never}), 0)
+	assert.Equal(t, getRunHistoryLimit(&v1beta1.JobSpec{RestartPolicy: &onFailure}), 10)
+	assert.Equal(t, getRunHistoryLimit(&v1beta1.JobSpec{RestartPolicy: &never, RunHistoryLimit: &limit}), 3)
+}
+
+func TestDeriveJobRuns(t *testing.T) {
+	var limit int32 = 2
+	var jobSpec = &v1beta1.JobSpec{RunHistoryLimit: &limit}
+	var pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "fjc-job-submitter-abcde"}}
+	var job = &v1beta1.JobStatus{State: v1beta1.JobStatePending}
+	var transition = func(state v1beta1.JobState, submitterPod *corev1.Pod) {
+		var oldState = job.State
+		job.State = state
+		deriveJobRuns(jobSpec, oldState, job, submitterPod)
+	}
+
+	transition(v1beta1.JobStatePending, nil)
+	assert.Equal(t, len(job.Runs), 0)
+
+	// The first run fails.
+	job.FromSavepoint = "gs://my-bucket/savepoints/savepoint-1"
+	transition(v1beta1.JobStateDeploying, pod)
+	job.ID = "ec74209eb4e3db8ae72db00bd7a830aa"
+	job.StartTime = "2022-05-01T10:00:00Z"
+	transition(v1beta1.JobStateRunning, pod)
+	var completionTime = metav1.Now()
+	job.CompletionTime = &completionTime
+	transition(v1beta1.JobStateFailed, nil)
+	assert.DeepEqual(t, job.Runs, []v1beta1.JobRunStatus{{
+		ID:             "ec74209eb4e3db8ae72db00bd7a830aa",
+		SubmitterPod:   "fjc-job-submitter-abcde",
+		FromSavepoint:  "gs://my-bucket/savepoints/savepoint-1",
+		StartTime:      "2022-05-01T10:00:00Z",
+		CompletionTime: &completionTime,
+		State:          v1beta1.JobStateFailed,
+	}})
+
+	// The run after the restart is lost and recovered, then replaced by an update.
+	transition(v1beta1.JobStateRestarting, nil)
+	job.ID, job.StartTime, job.CompletionTime = "", "", nil
+	job.FromSavepoint = "gs://my-bucket/savepoints/savepoint-2"
+	transition(v1beta1.JobStateDeploying, pod)
+	job.ID = "fc74209eb4e3db8ae72db00bd7a830aa"
+	job.StartTime = "2022-05-01T11:00:00Z"
+	transition(v1beta1.JobStateRunning, nil)
+	job.CompletionTime = &completionTime
+	transition(v1beta1.JobStateLost, nil)
+	assert.Equal(t, job.Runs[1].State, v1beta1.JobStateLost)
+	job.CompletionTime = nil
+	transition(v1beta1.JobStateRunning, nil)
+	assert.Equal(t, job.Runs[1].State, v1beta1.JobState(""))
+	assert.Assert(t, job.Runs[1].CompletionTime == nil)
+	transition(v1beta1.JobStateUpdating, nil)
+	assert.Equal(t, len(job.Runs), 2)
+	assert.Equal(t, job.Runs[1].State, v1beta1.JobStateUpdating)
+	assert.Assert(t, job.Runs[1].CompletionTime != nil)
+	assert.Equal(t, job.Runs[1].StartTime, "2022-05-01T11:00:00Z")
+
+	// The oldest runs are dropped beyond the limit.
+	job.ID, job.StartTime = "", ""
+	transition(v1beta1.JobStateDeploying, pod)
+	assert.Equal(t, len(job.Runs), 2)
+	assert.Equal(t, job.Runs[0].ID, "fc74209eb4e3db8ae72db00bd7a830aa")
+	assert.DeepEqual(t, job.Runs[1], v1beta1.JobRunStatus{
+		SubmitterPod:  "fjc-job-submitter-abcde",
+		FromSavepoint: "gs://my-bucket/savepoints/savepoint-2",
+	})
+
+	// The runs are removed when the history is disabled.
+	limit = 0
+	transition(v1beta1.JobStateRunning, nil)
+	assert.Assert(t, job.Runs == nil)
+}
@@ -0,0 +1,227 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"github.com/spotify/flink-on-k8s-operator/internal/objectstore"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultFlightRecordingSeconds = 60
+	// Time for the JVMs to write the recordings after their duration elapsed.
+	flightRecordingGracePeriodSeconds = 10
+	// The timeout of the uploads of the operator, e.g. of the thread dumps. The heap dumps and
+	// the flight recordings are uploaded by the diagnostics collectors of the pods.
+	diagnosticsUploadTimeout = 30 * time.Second
+	diagnosticsTimeFormat    = "20060102T150405Z"
+)
+
+// Keys of the details of the flight-recording and thread-dump user controls.
+const (
+	flightRecordingFileKey       = "fileName"
+	flightRecordingPodsKey       = "pods"
+	flightRecordingFinishTimeKey = "finishTime"
+	threadDumpConfigMapKey       = "configMap"
+	// Set by the reconciler when the files are collected, the updater finishes the control then.
+	controlCollectTimeKey = "collectTime"
+)
+
+// The maximum size of the thread dumps stored in a ConfigMap, below the 1 MiB limit of
+// ConfigMaps to leave room for the metadata.
+const threadDumpConfigMapMaxBytes = 1000 * 1024
+
+var invalidConfigMapKeyChars = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+// Client of the object storage to upload the files of the operator to, with the credentials
+// of the operator.
+var diagnosticsStore = objectstore.NewClient(diagnosticsUploadTimeout)
+
+// getFlightRecordingCommand returns the command which starts a flight recording of the Flink
+// JVM of the container, selected by its main class.
+func getFlightRecordingCommand(fileName string, seconds int32) []string {
+	return []string{"jcmd", "org.apache.flink", "JFR.start", "name=flink-operator",
+		fmt.Sprintf("duration=%vs", seconds), "filename=" + path.Join(render.DiagnosticsPath, fileName)}
+}
+
+func getFlightRecordingSeconds(diagnostics *v1beta1.DiagnosticsSpec) int32 {
+	if diagnostics.FlightRecordingSeconds != nil {
+		return *diagnostics.FlightRecordingSeconds
+	}
+	return defaultFlightRecordingSeconds
+}
+
+// isMainContainerRunning returns true if the main container of the pod is running, so that
+// commands can run in it.
+func isMainContainerRunning(pod *corev1.Pod) bool {
+	if len(pod.Spec.Containers) == 0 {
+		return false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == pod.Spec.Containers[0].Name {
+			return status.State.Running != nil
+		}
+	}
+	return false
+}
+
+// getDiagnosticsUploadLocation returns the location of the object storage to upload a
+// diagnostics file of the pod or the revision to, `<uri>/<namespace>/<cluster>/<name>/<file>`.
+func getDiagnosticsUploadLocation(uri, namespace, clusterName, name, fileName string) (string, error) {
+	if !objectstore.IsSupported(uri) {
+		return "", fmt.Errorf("unsupported upload URI %v", uri)
+	}
+	return strings.TrimSuffix(uri, "/") + "/" + path.Join(namespace, clusterName, name, fileName), nil
+}
+
+// uploadDiagnosticsFile uploads a file of the operator to the object storage, with the
+// authorization if it is not empty and the credentials of the operator otherwise.
+func uploadDiagnosticsFile(ctx context.Context, location, authorization string, data []byte) error {
+	return diagnosticsStore.Put(ctx, location, authorization, data)
+}
+
+// getAuthorization returns the value of the Authorization header of the requests to the
+// object storage from the Secret key, empty if it is not set.
+func getAuthorization(
+	ctx context.Context, k8sClient client.Client, namespace string, ref *corev1.SecretKeySelector) (string, error) {
+	if ref == nil {
+		return "", nil
+	}
+	var secret corev1.Secret
+	var err = k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, &secret)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(secret.Data[ref.Key])), nil
+}
+
+func isFlightRecordingInProgress(cluster *v1beta1.FlinkCluster) bool {
+	var control = cluster.Status.Control
+	return control != nil && control.Name == v1beta1.ControlNameFlightRecording &&
+		control.State == v1beta1.ControlStateInProgress
+}
+
+// getThreadDumpText returns the thread dump in the format of jstack.
+func getThreadDumpText(threadDump *flink.ThreadDump) string {
+	var b strings.Builder
+	for _, info := range threadDump.ThreadInfos {
+		b.WriteString(info.StringifiedThreadInfo)
+		if !strings.HasSuffix(info.StringifiedThreadInfo, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// getTaskManagerPodName returns the name of the pod of a registered TaskManager, empty if
+// none of the pods runs it.
+func getTaskManagerPodName(tm flink.TaskManager, pods []corev1.Pod) string {
+	// The path is the RPC address of the TaskManager, e.g. akka.tcp://flink@10.12.0.5:6122/user/rpc/taskmanager_0,
+	// with IPv6 addresses in brackets, e.g. akka.tcp://flink@[fd00::5]:6122/user/rpc/taskmanager_0.
+	var host = tm.Path
+	if i := strings.Index(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	if strings.HasPrefix(host, "[") {
+		if i := strings.Index(host, "]"); i >= 0 {
+			host = host[1:i]
+		}
+	} else if i := strings.IndexAny(host, ":/"); i >= 0 {
+		host = host[:i]
+	}
+	if host == "" {
+		return ""
+	}
+	for _, pod := range pods {
+		if hasPodIP(&pod, host) || host == pod.Name || strings.HasPrefix(host, pod.Name+".") {
+			return pod.Name
+		}
+	}
+	return ""
+}
+
+// hasPodIP returns true if the IP is one of the addresses of the pod, which has an address of
+// each family on dual-stack clusters.
+func hasPodIP(pod *corev1.Pod, ip string) bool {
+	if ip == pod.Status.PodIP {
+		return true
+	}
+	for _, podIP := range pod.Status.PodIPs {
+		if ip == podIP.IP {
+			return true
+		}
+	}
+	return false
+}
+
+// getThreadDumpTaskManagers returns the IDs of the TaskManagers to take thread dumps of by the
+// names of their pods, the ones of the comma separated pod names if selected is not empty.
+// TaskManagers without a pod of the cluster, e.g. externally managed ones, are named by their IDs.
+func getThreadDumpTaskManagers(taskManagers []flink.TaskManager, pods []corev1.Pod, selected string) (map[string]string, error) {
+	var ids = map[string]string{}
+	for _, tm := range taskManagers {
+		var name = getTaskManagerPodName(tm, pods)
+		if name == "" {
+			name = invalidConfigMapKeyChars.ReplaceAllString(tm.ID, "-")
+		}
+		ids[name] = tm.ID
+	}
+	if selected == "" {
+		return ids, nil
+	}
+
+	var selectedIDs = map[string]string{}
+	var missing []string
+	for _, name := range strings.Split(selected, ",") {
+		name = strings.TrimSpace(name)
+		if id, ok := ids[name]; ok {
+			selectedIDs[name] = id
+		} else if name != "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return selectedIDs, fmt.Errorf("TaskManagers %v not registered to the JobManager", strings.Join(missing, ", "))
+	}
+	return selectedIDs, nil
+}
+
+func getThreadDumpConfigMapName(clusterName string) string {
+	return clusterName + "-thread-dump"
+}
+
+// newThreadDumpConfigMap returns the ConfigMap of the thread dumps, one `<pod>.txt` key for each JVM.
+// It is replaced by the ConfigMap of the next thread-dump control.
+func newThreadDumpConfigMap(cluster *v1beta1.FlinkCluster, threadDumps map[string]string) (*corev1.ConfigMap, error) {
+	var data = map[string]string{}
+	var size int
+	for name, threadDump := range threadDumps {
+		data[name+".txt"] = threadDump
+		size += len(threadDump)
+	}
+	if size > threadDumpConfigMapMaxBytes {
+		return nil, fmt.Errorf("thread dumps of %v JVMs exceed the size limit of ConfigMaps, set spec.diagnostics.upload to upload them", len(threadDumps))
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       cluster.Namespace,
+			Name:            getThreadDumpConfigMapName(cluster.Name),
+			Labels:          render.ClusterLabels(cluster),
+			OwnerReferences: []metav1.OwnerReference{render.ToOwnerReference(cluster)},
+		},
+		Data: data,
+	}, nil
+}
@@ -0,0 +1,142 @@
+package flinkcluster
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetDiagnosticsUploadLocation(t *testing.T) {
+	var location, err = getDiagnosticsUploadLocation("gs://my-bucket/diagnostics/", "default", "mycluster",
+		"mycluster-taskmanager-0", "threaddump-20261014T101500Z.txt")
+	assert.NilError(t, err)
+	assert.Equal(t, location, "gs://my-bucket/diagnostics/default/mycluster/mycluster-taskmanager-0/threaddump-20261014T101500Z.txt")
+
+	location, err = getDiagnosticsUploadLocation("https://example.com", "default", "mycluster", "r1", "plan.json")
+	assert.NilError(t, err)
+	assert.Equal(t, location, "https://example.com/default/mycluster/r1/plan.json")
+
+	_, err = getDiagnosticsUploadLocation("hdfs:///diagnostics", "default", "mycluster", "r1", "plan.json")
+	assert.Error(t, err, "unsupported upload URI hdfs:///diagnostics")
+}
+
+func TestIsMainContainerRunning(t *testing.T) {
+	var pod = &corev1.Pod{
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "taskmanager"}, {Name: "sidecar"}}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "sidecar", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			{Name: "taskmanager", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}},
+		}},
+	}
+	assert.Assert(t, !isMainContainerRunning(pod))
+
+	pod.Status.ContainerStatuses[1].State = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	assert.Assert(t, isMainContainerRunning(pod))
+}
+
+func TestUploadDiagnosticsFile(t *testing.T) {
+	var received []byte
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		received, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	var content = "\"main\" Id=1 WAITING"
+	var err = uploadDiagnosticsFile(context.Background(), server.URL+"/threaddump.txt", "Bearer token", []byte(content))
+	assert.NilError(t, err)
+	assert.Equal(t, string(received), content)
+
+	err = uploadDiagnosticsFile(context.Background(), server.URL+"/threaddump.txt", "", []byte(content))
+	assert.ErrorContains(t, err, "access denied")
+}
+
+func TestGetThreadDumpText(t *testing.T) {
+	var threadDump = &flink.ThreadDump{ThreadInfos: []flink.ThreadInfo{
+		{ThreadName: "main", StringifiedThreadInfo: "\"main\" Id=1 WAITING\n\tat java.lang.Object.wait(Native Method)\n"},
+		{ThreadName: "flink-akka.actor.default-dispatcher-2", StringifiedThreadInfo: "\"flink-akka.actor.default-dispatcher-2\" Id=20 RUNNABLE"},
+	}}
+	assert.Equal(t, getThreadDumpText(threadDump),
+		"\"main\" Id=1 WAITING\n\tat java.lang.Object.wait(Native Method)\n"+
+			"\"flink-akka.actor.default-dispatcher-2\" Id=20 RUNNABLE\n")
+}
+
+func TestGetThreadDumpTaskManagers(t *testing.T) {
+	var taskManagers = []flink.TaskManager{
+		{ID: "10.12.0.5:6122-a1b2c3", Path: "akka.tcp://flink@10.12.0.5:6122/user/rpc/taskmanager_0"},
+		{ID: "10.12.0.6:6122-d4e5f6", Path: "akka.tcp://flink@10.12.0.6:6122/user/rpc/taskmanager_0"},
+		{ID: "10.12.9.9:6122-0a0b0c", Path: "akka.tcp://flink@10.12.9.9:6122/user/rpc/taskmanager_0"},
+	}
+	var pods = []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "mycluster-taskmanager-0"}, Status: corev1.PodStatus{PodIP: "10.12.0.5"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "mycluster-taskmanager-1"}, Status: corev1.PodStatus{PodIP: "10.12.0.6"}},
+	}
+
+	ids, err := getThreadDumpTaskManagers(taskManagers, pods, "")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ids, map[string]string{
+		"mycluster-taskmanager-0": "10.12.0.5:6122-a1b2c3",
+		"mycluster-taskmanager-1": "10.12.0.6:6122-d4e5f6",
+		"10.12.9.9-6122-0a0b0c":   "10.12.9.9:6122-0a0b0c",
+	})
+
+	ids, err = getThreadDumpTaskManagers(taskManagers, pods, "mycluster-taskmanager-1")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ids, map[string]string{"mycluster-taskmanager-1": "10.12.0.6:6122-d4e5f6"})
+
+	ids, err = getThreadDumpTaskManagers(taskManagers, pods, "mycluster-taskmanager-1, mycluster-taskmanager-7")
+	assert.Error(t, err, "TaskManagers mycluster-taskmanager-7 not registered to the JobManager")
+	assert.DeepEqual(t, ids, map[string]string{"mycluster-taskmanager-1": "10.12.0.6:6122-d4e5f6"})
+}
+
+func TestGetTaskManagerPodName(t *testing.T) {
+	var pods = []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "mycluster-taskmanager-0"},
+		Status: corev1.PodStatus{
+			PodIP:  "10.12.0.5",
+			PodIPs: []corev1.PodIP{{IP: "10.12.0.5"}, {IP: "fd00:10:12::5"}},
+		},
+	}}
+	assert.Equal(t, getTaskManagerPodName(flink.TaskManager{
+		Path: "akka.tcp://flink@10.12.0.5:6122/user/rpc/taskmanager_0"}, pods), "mycluster-taskmanager-0")
+	assert.Equal(t, getTaskManagerPodName(flink.TaskManager{
+		Path: "akka.tcp://flink@[fd00:10:12::5]:6122/user/rpc/taskmanager_0"}, pods), "mycluster-taskmanager-0")
+	assert.Equal(t, getTaskManagerPodName(flink.TaskManager{
+		Path: "akka.tcp://flink@[fd00:10:12::6]:6122/user/rpc/taskmanager_0"}, pods), "")
+	assert.Equal(t, getTaskManagerPodName(flink.TaskManager{
+		Path: "akka.tcp://flink@mycluster-taskmanager-0.mycluster-taskmanager:6122/user/rpc/taskmanager_0"}, pods),
+		"mycluster-taskmanager-0")
+}
+
+func TestNewThreadDumpConfigMap(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{ObjectMeta: metav1.ObjectMeta{Name: "mycluster", Namespace: "default"}}
+	configMap, err := newThreadDumpConfigMap(cluster, map[string]string{
+		"mycluster-jobmanager-0":  "\"main\" Id=1 WAITING\n",
+		"mycluster-taskmanager-0": "\"main\" Id=1 RUNNABLE\n",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, configMap.Name, "mycluster-thread-dump")
+	assert.Equal(t, configMap.Namespace, "default")
+	assert.DeepEqual(t, configMap.Data, map[string]string{
+		"mycluster-jobmanager-0.txt":  "\"main\" Id=1 WAITING\n",
+		"mycluster-taskmanager-0.txt": "\"main\" Id=1 RUNNABLE\n",
+	})
+	assert.Equal(t, len(configMap.OwnerReferences), 1)
+
+	_, err = newThreadDumpConfigMap(cluster, map[string]string{
+		"mycluster-taskmanager-0": strings.Repeat("x", threadDumpConfigMapMaxBytes+1),
+	})
+	assert.ErrorContains(t, err, "set spec.diagnostics.upload")
+}
@@ -0,0 +1,121 @@
+package flinkcluster
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/kafka"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// The interval of the collections of the lag, and the age after which a lag which could
+	// not be collected again is no longer exported.
+	kafkaLagRefreshInterval = 30 * time.Second
+	kafkaLagMaxAge          = 2 * time.Minute
+	// The time a lag collection waits for the brokers.
+	kafkaLagTimeout = 10 * time.Second
+)
+
+// The lag of the consumer group of spec.monitoring.kafkaLag, served on the metrics endpoint
+// of the operator so that it can be served to the external metrics API for the autoscaler.
+var kafkaConsumerLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: render.KafkaConsumerLagMetricName,
+	Help: "The lag of the Kafka consumer group of the running job of the FlinkCluster, in records.",
+}, []string{"namespace", "cluster", "group"})
+
+func init() {
+	metrics.Registry.MustRegister(kafkaConsumerLag)
+}
+
+// observeKafkaLag observes the lag of the consumer group of spec.monitoring.kafkaLag while
+// the job is running. The lag is collected in the background, the last collected lag is
+// observed without waiting for the brokers.
+func (observer *ClusterStateObserver) observeKafkaLag(ctx context.Context, observed *ObservedClusterState) {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = observed.cluster
+	var key = observer.request.NamespacedName.String()
+	observed.kafkaLag = nil
+	if cluster.Spec.Monitoring == nil || cluster.Spec.Monitoring.KafkaLag == nil {
+		observer.kafkaLagCollector.Forget(key)
+		return
+	}
+	var flinkJob = observed.flinkJob.status
+	if flinkJob == nil || getFlinkJobDeploymentState(flinkJob.State) != v1beta1.JobStateRunning {
+		observer.kafkaLagCollector.Forget(key)
+		return
+	}
+
+	var spec = cluster.Spec.Monitoring.KafkaLag
+	var secret *corev1.Secret
+	if spec.SecretRef != nil {
+		var err error
+		secret, err = observer.k8sClientset.CoreV1().Secrets(cluster.Namespace).Get(ctx, spec.SecretRef.Name, metav1.GetOptions{})
+		if err != nil {
+			log.Error(err, "Failed to get the Secret of spec.monitoring.kafkaLag", "secret", spec.SecretRef.Name)
+			return
+		}
+	}
+	config, err := getKafkaConfig(spec, secret)
+	if err != nil {
+		log.Error(err, "Invalid Secret of spec.monitoring.kafkaLag", "secret", spec.SecretRef.Name)
+		return
+	}
+
+	lag, ok, err := observer.kafkaLagCollector.Lag(key, config, spec.Group, spec.Topics)
+	if err != nil {
+		log.Info("Failed to collect the Kafka consumer lag", "group", spec.Group, "error", err)
+	}
+	if !ok {
+		return
+	}
+	log.Info("Observed Kafka consumer lag", "group", spec.Group, "lag", lag)
+	observed.kafkaLag = &lag
+}
+
+// getKafkaConfig returns the connection config of spec.monitoring.kafkaLag with the
+// credentials and the CA certificate of its Secret.
+func getKafkaConfig(spec *v1beta1.KafkaLagSpec, secret *corev1.Secret) (kafka.Config, error) {
+	var config = kafka.Config{BootstrapServers: spec.BootstrapServers, Mechanism: spec.SASLMechanism}
+	if secret != nil {
+		config.Username = string(secret.Data["username"])
+		config.Password = string(secret.Data["password"])
+	}
+	if spec.TLS == nil || !*spec.TLS {
+		return config, nil
+	}
+	config.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	if secret != nil && len(secret.Data["ca.crt"]) > 0 {
+		var pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(secret.Data["ca.crt"]) {
+			return kafka.Config{}, fmt.Errorf("no PEM certificates in ca.crt")
+		}
+		config.TLS.RootCAs = pool
+	}
+	return config, nil
+}
+
+// recordKafkaLagMetric exports the Kafka consumer lag observed in this reconciliation. The
+// metric of the cluster is removed when the lag is not observed, e.g. while the job is not
+// running, so that the autoscaler does not act on a stale lag.
+func recordKafkaLagMetric(name types.NamespacedName, cluster *v1beta1.FlinkCluster, lag *int64) {
+	var labels = prometheus.Labels{"namespace": name.Namespace, "cluster": name.Name}
+	if cluster == nil || cluster.Spec.Monitoring == nil || cluster.Spec.Monitoring.KafkaLag == nil || lag == nil {
+		kafkaConsumerLag.DeletePartialMatch(labels)
+		return
+	}
+	// Removes the metric of the group before it was changed, if any.
+	kafkaConsumerLag.DeletePartialMatch(labels)
+	labels["group"] = cluster.Spec.Monitoring.KafkaLag.Group
+	kafkaConsumerLag.With(labels).Set(float64(*lag))
+}
@@ -0,0 +1,51 @@
+package flinkcluster
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/kafka"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestGetKafkaConfig(t *testing.T) {
+	var tls = true
+	var spec = &v1beta1.KafkaLagSpec{BootstrapServers: "kafka-0.kafka:9093", Group: "orders", SASLMechanism: "SCRAM-SHA-256"}
+	var secret = &corev1.Secret{Data: map[string][]byte{"username": []byte("flink"), "password": []byte("secret")}}
+
+	config, err := getKafkaConfig(spec, secret)
+	assert.NilError(t, err)
+	assert.Equal(t, config.Username, "flink")
+	assert.Equal(t, config.Password, "secret")
+	assert.Equal(t, config.Mechanism, kafka.SASLMechanismSCRAMSHA256)
+	assert.Assert(t, config.TLS == nil)
+
+	spec.TLS = &tls
+	config, err = getKafkaConfig(spec, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, config.TLS != nil && config.TLS.RootCAs == nil)
+
+	secret.Data["ca.crt"] = []byte("not a certificate")
+	_, err = getKafkaConfig(spec, secret)
+	assert.Error(t, err, "no PEM certificates in ca.crt")
+}
+
+func TestRecordKafkaLagMetric(t *testing.T) {
+	var name = types.NamespacedName{Namespace: "default", Name: "orders"}
+	var cluster = &v1beta1.FlinkCluster{Spec: v1beta1.FlinkClusterSpec{
+		Monitoring: &v1beta1.MonitoringSpec{KafkaLag: &v1beta1.KafkaLagSpec{Group: "orders-v1"}},
+	}}
+	var lag = int64(1200)
+	recordKafkaLagMetric(name, cluster, &lag)
+	assert.Equal(t, testutil.ToFloat64(kafkaConsumerLag.WithLabelValues("default", "orders", "orders-v1")), float64(1200))
+
+	cluster.Spec.Monitoring.KafkaLag.Group = "orders-v2"
+	recordKafkaLagMetric(name, cluster, &lag)
+	assert.Equal(t, testutil.CollectAndCount(kafkaConsumerLag), 1)
+
+	recordKafkaLagMetric(name, cluster, nil)
+	assert.Equal(t, testutil.CollectAndCount(kafkaConsumerLag), 0)
+}
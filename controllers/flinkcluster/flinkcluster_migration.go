@@ -0,0 +1,95 @@
+package flinkcluster
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// statusMigration migrates the status of a cluster written by an older operator to the
+// next schema version.
+type statusMigration struct {
+	// What the migration does, logged when it runs.
+	description string
+	migrate     func(cluster *v1beta1.FlinkCluster)
+}
+
+// The migrations of the status of the clusters, the migration at index i migrates the
+// status from schema version i to i+1. The migrations are only appended, so that the
+// operator migrates the clusters reconciled by any older version. Version 0 is the layout
+// of the status written before the schema version was recorded.
+var statusMigrations = []statusMigration{}
+
+// The schema version of the status written by this operator.
+var statusSchemaVersion = int32(len(statusMigrations))
+
+// isStatusSchemaNewer returns true if the status of the cluster was migrated by a newer
+// operator, e.g. while both run during a rollout. The older operator does not reconcile
+// the cluster, so that it does not overwrite the status in the layout it does not know.
+func isStatusSchemaNewer(cluster *v1beta1.FlinkCluster) bool {
+	return cluster != nil && cluster.Status.SchemaVersion > statusSchemaVersion
+}
+
+// isNewStatus returns true if no operator has reconciled the cluster yet, whose status is
+// then written in the current layout without migrations.
+func isNewStatus(cluster *v1beta1.FlinkCluster) bool {
+	return cluster.Status.State == "" && cluster.Status.OperatorVersion == ""
+}
+
+// migrateStatus runs the migrations of the schema versions between the one of the status
+// and statusSchemaVersion, and records the version of the operator. The status of new
+// clusters is stamped with statusSchemaVersion. Returns true if the status was changed.
+func migrateStatus(cluster *v1beta1.FlinkCluster, operatorVersion string) bool {
+	var status = &cluster.Status
+	if status.SchemaVersion >= statusSchemaVersion && status.OperatorVersion == operatorVersion {
+		return false
+	}
+	if !isNewStatus(cluster) {
+		for version := status.SchemaVersion; version < statusSchemaVersion; version++ {
+			statusMigrations[version].migrate(cluster)
+		}
+	}
+	status.SchemaVersion = statusSchemaVersion
+	status.OperatorVersion = operatorVersion
+	return true
+}
+
+// Migrates the status of the cluster reconciled by another operator version before the
+// status is derived from the components. Returns true if the status was updated.
+func (handler *FlinkClusterHandler) reconcileStatusMigration(ctx context.Context) (bool, error) {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = handler.observed.cluster
+	if cluster == nil || (cluster.Status.SchemaVersion >= statusSchemaVersion &&
+		cluster.Status.OperatorVersion == handler.operatorVersion) {
+		return false, nil
+	}
+
+	var migrated bool
+	var err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		var latest = new(v1beta1.FlinkCluster)
+		if err := handler.k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), latest); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if isStatusSchemaNewer(latest) {
+			return nil
+		}
+		for version := latest.Status.SchemaVersion; version < statusSchemaVersion && !isNewStatus(latest); version++ {
+			log.Info("Migrating the status", "schemaVersion", version+1, "migration", statusMigrations[version].description)
+		}
+		var fromVersion = latest.Status.OperatorVersion
+		if !migrateStatus(latest, handler.operatorVersion) {
+			return nil
+		}
+		if err := handler.k8sClient.Status().Update(ctx, latest); err != nil {
+			return err
+		}
+		log.Info("Migrated the status", "fromOperatorVersion", fromVersion, "operatorVersion", handler.operatorVersion,
+			"schemaVersion", statusSchemaVersion)
+		migrated = true
+		return nil
+	})
+	return migrated, err
+}
@@ -0,0 +1,78 @@
+package flinkcluster
+
+import (
+	"context"
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMigrateStatus(t *testing.T) {
+	var migrations, schemaVersion = statusMigrations, statusSchemaVersion
+	t.Cleanup(func() { statusMigrations, statusSchemaVersion = migrations, schemaVersion })
+	statusMigrations = []statusMigration{{
+		description: "Set status.savepoint.jobID",
+		migrate: func(cluster *v1beta1.FlinkCluster) {
+			cluster.Status.Savepoint = &v1beta1.SavepointStatus{JobID: "migrated"}
+		},
+	}}
+	statusSchemaVersion = 1
+
+	// The status written before the schema version was recorded is migrated from version 0.
+	var cluster = &v1beta1.FlinkCluster{
+		Status: v1beta1.FlinkClusterStatus{State: v1beta1.ClusterStateRunning},
+	}
+	assert.Assert(t, migrateStatus(cluster, "v0.5.0"))
+	assert.Equal(t, cluster.Status.Savepoint.JobID, "migrated")
+	assert.Equal(t, cluster.Status.SchemaVersion, int32(1))
+	assert.Equal(t, cluster.Status.OperatorVersion, "v0.5.0")
+	assert.Assert(t, !migrateStatus(cluster, "v0.5.0"))
+
+	// Only the operator version is recorded once the schema is current.
+	cluster.Status.Savepoint = nil
+	assert.Assert(t, migrateStatus(cluster, "v0.6.0"))
+	assert.Assert(t, cluster.Status.Savepoint == nil)
+	assert.Equal(t, cluster.Status.OperatorVersion, "v0.6.0")
+
+	// The status of new clusters is stamped with the current version without migrations.
+	var created = &v1beta1.FlinkCluster{}
+	assert.Assert(t, migrateStatus(created, "v0.6.0"))
+	assert.Assert(t, created.Status.Savepoint == nil)
+	assert.Equal(t, created.Status.SchemaVersion, int32(1))
+
+	var newer = &v1beta1.FlinkCluster{Status: v1beta1.FlinkClusterStatus{SchemaVersion: statusSchemaVersion + 1}}
+	assert.Assert(t, isStatusSchemaNewer(newer))
+	assert.Assert(t, !isStatusSchemaNewer(cluster))
+}
+
+func TestReconcileStatusMigration(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "mycluster", Namespace: "default", Generation: 1},
+	}
+	var scheme = runtime.NewScheme()
+	assert.NilError(t, v1beta1.AddToScheme(scheme))
+	var k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	var handler = &FlinkClusterHandler{
+		k8sClient:       k8sClient,
+		observed:        ObservedClusterState{cluster: cluster},
+		operatorVersion: "v0.5.0",
+	}
+
+	migrated, err := handler.reconcileStatusMigration(context.TODO())
+	assert.NilError(t, err)
+	assert.Assert(t, migrated)
+	var updated = new(v1beta1.FlinkCluster)
+	assert.NilError(t, k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cluster), updated))
+	assert.Equal(t, updated.Status.OperatorVersion, "v0.5.0")
+	assert.Equal(t, updated.Status.SchemaVersion, statusSchemaVersion)
+
+	handler.observed.cluster = updated
+	migrated, err = handler.reconcileStatusMigration(context.TODO())
+	assert.NilError(t, err)
+	assert.Assert(t, !migrated)
+}
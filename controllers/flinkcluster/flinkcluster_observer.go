@@ -18,7 +18,9 @@ package flinkcluster
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,13 +32,17 @@ import (
 	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
 	"github.com/spotify/flink-on-k8s-operator/internal/controllers/history"
 	flink "github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"github.com/spotify/flink-on-k8s-operator/internal/kafka"
+	"github.com/spotify/flink-on-k8s-operator/internal/secrets"
 	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
@@ -52,6 +58,19 @@ type ClusterStateObserver struct {
 	request      ctrl.Request
 	history      history.Interface
 	recorder     record.EventRecorder
+	// The maximum number of job clusters running simultaneously per queue, 0 means no limit.
+	maxRunningJobClusters int
+	// The interval of the snapshots of the job vertices, 0 disables them.
+	jobVertexStatusInterval time.Duration
+	// Resolves the secrets of external secret stores in spec.flinkPropertiesFrom.
+	secretResolver *secrets.Resolver
+	// The hosts outside of the namespace of the cluster which HTTP readiness gates may request.
+	readinessGateAllowedHosts []string
+	// The rules which select the savepoint storage of the jobs, the labels of the JobManager
+	// node are observed only if there are any.
+	savepointStorageRules []SavepointStorageRule
+	// Collects the lag of the consumer group of spec.monitoring.kafkaLag in the background.
+	kafkaLagCollector *kafka.LagCollector
 }
 
 // ObservedClusterState holds observed state of a cluster.
@@ -60,8 +79,12 @@ type ObservedClusterState struct {
 	revisions               []*appsv1.ControllerRevision
 	configMap               *corev1.ConfigMap
 	haConfigMap             *corev1.ConfigMap
+	serviceAccount          *corev1.ServiceAccount
+	role                    *rbacv1.Role
+	roleBinding             *rbacv1.RoleBinding
 	jmStatefulSet           *appsv1.StatefulSet
 	jmService               *corev1.Service
+	jmUIService             *corev1.Service
 	jmIngress               *networkingv1.Ingress
 	tmStatefulSet           *appsv1.StatefulSet
 	tmDeployment            *appsv1.Deployment
@@ -69,19 +92,85 @@ type ObservedClusterState struct {
 	podDisruptionBudget     *policyv1.PodDisruptionBudget
 	horizontalPodAutoscaler *autoscalingv2.HorizontalPodAutoscaler
 	persistentVolumeClaims  *corev1.PersistentVolumeClaimList
+	jmPods                  []corev1.Pod
+	tmPods                  []corev1.Pod
 	flinkJob                FlinkJob
 	flinkJobSubmitter       FlinkJobSubmitter
 	savepoint               Savepoint
+	savepointTriggerJob     *batchv1.Job
+	savepointTriggerPod     *corev1.Pod
 	revision                Revision
 	observeTime             time.Time
 	updateState             UpdateState
+	queuePosition           int32
+	// TaskManagers registered to the JobManager, observed only when spec.taskManager.external
+	// or spec.taskManager.registrationWatchdog is set or while the canary TaskManagers of
+	// spec.canaryUpdate are verified.
+	registeredTaskManagers *flink.TaskManagersOverview
+	// Nodes running the pods of the cluster which are being drained, observed only when
+	// spec.job.adaptiveSavepoint.beforeNodeDrain is enabled.
+	drainingNodes []string
+	// Labels of the node of the running JobManager pod, observed only when the operator has
+	// savepoint storage rules and the job takes savepoints.
+	jmNodeLabels map[string]string
+	// Whether the hostname of spec.jobManager.externalDNS resolves, observed only when it is set.
+	externalDNSResolved bool
+	// JAR files uploaded to the JobManager, observed only when spec.jars or status.jars is set.
+	sessionJars *flink.JarsOverview
+	// The Kubernetes Job which uploads the JAR files of spec.jars and its pod, observed only
+	// when spec.jars is set.
+	jarUploaderJob *batchv1.Job
+	jarUploaderPod *corev1.Pod
+	// Jobs of the session cluster, observed only when spec.idlePolicy is set.
+	sessionJobs *flink.JobsOverview
+	// Hash of the ConfigMaps and Secrets referenced by the spec, observed only when
+	// spec.updateOnReferencedConfigChange is enabled, and of the properties of
+	// spec.flinkPropertiesFrom.
+	referencedConfigHash string
+	// The properties resolved from spec.flinkPropertiesFrom, nil if it is not set.
+	flinkPropertiesFrom map[string]string
+	// The Secret of the properties resolved from spec.flinkPropertiesFrom.
+	flinkPropertiesSecret *corev1.Secret
+	// The first gate of spec.job.readinessGates which does not pass, observed only while the
+	// job is about to be submitted.
+	blockingReadinessGate *v1beta1.JobReadinessGateStatus
+	// Why the savepoint to restore the job from is not compatible with the job, observed only
+	// while the job is about to be submitted and spec.job.savepointOwnership is verified.
+	savepointRestoreBlockedReason string
+	// The result of the probe of spec.job.storageProbe, nil unless the storage was probed in
+	// this reconciliation.
+	storageProbe *storageProbeResult
+	// Why a FlinkCluster of spec.dependsOn is not ready, nil unless the dependencies were
+	// checked in this reconciliation, which they are until the cluster is started.
+	dependenciesNotReadyReason *string
+	// The FlinkClusters of spec.dependsOn whose job succeeded, observed with
+	// dependenciesNotReadyReason.
+	succeededDependencies []string
+	// The lag of the Kafka consumer group of spec.monitoring.kafkaLag, observed only while the
+	// job is running.
+	kafkaLag *int64
 }
 
 type FlinkJob struct {
 	status     *flink.Job
 	list       *flink.JobsOverview
 	exceptions *flink.JobExceptions
-	unexpected []string
+	// Observed only when the job terminated and spec.job.successPolicy matches an accumulator.
+	accumulators *flink.JobAccumulators
+	unexpected   []string
+	// Whether the Flink REST API was reachable, nil if the job was not observed.
+	apiReachable *bool
+	// Observed only while the job started from a savepoint is verified, when spec.job.slo
+	// sets the objectives which depend on them or spec.monitoring.checkpointMetrics is enabled.
+	checkpoints *flink.JobCheckpoints
+	// The alignment time in seconds of the latest checkpoints which are not exported yet by
+	// spec.monitoring.checkpointMetrics, by checkpoint ID.
+	checkpointAlignments map[int64]float64
+	numRestarts          *int64
+	// The aggregated metric of spec.job.slo.maxConsumerLag, observed only when it is set.
+	consumerLag *int64
+	// The snapshot of the job vertices, observed only when it is due.
+	vertices []v1beta1.JobVertexStatus
 }
 
 type FlinkJobSubmitter struct {
@@ -93,6 +182,8 @@ type FlinkJobSubmitter struct {
 type SubmitterLog struct {
 	jobID   string
 	message string
+	// The entry class resolved from the manifest of the JAR file, if spec.job.className is not set.
+	entryClass string
 }
 
 type Savepoint struct {
@@ -143,6 +234,8 @@ func (observer *ClusterStateObserver) observe(ctx context.Context, observed *Obs
 	}
 
 	if observed.cluster != nil {
+		injectFlinkAPIFaults(ctx, observer.flinkClient, observed.cluster)
+
 		// Revisions.
 		if err := observer.observeRevisions(observed); err != nil {
 			log.Error(err, "Failed to get the controllerRevision resource list")
@@ -161,6 +254,24 @@ func (observer *ClusterStateObserver) observe(ctx context.Context, observed *Obs
 			return err
 		}
 
+		// ServiceAccount, Role and RoleBinding for Kubernetes HA services.
+		if err := observer.observeHARBAC(ctx, observed); err != nil {
+			log.Error(err, "Failed to get HA RBAC resources")
+			return err
+		}
+
+		// (Optional) Flink properties resolved from Secrets and external secret stores.
+		if err := observer.observeFlinkPropertiesFrom(ctx, observed); err != nil {
+			log.Error(err, "Failed to resolve spec.flinkPropertiesFrom")
+			return err
+		}
+
+		// (Optional) ConfigMaps and Secrets referenced by the spec.
+		if err := observer.observeReferencedConfig(ctx, observed); err != nil {
+			log.Error(err, "Failed to get the referenced ConfigMaps and Secrets")
+			return err
+		}
+
 		// PodDisruptionBudget.
 		if err := observer.observePodDisruptionBudget(ctx, observed); err != nil {
 			log.Error(err, "Failed to get PodDisruptionBudget")
@@ -168,7 +279,7 @@ func (observer *ClusterStateObserver) observe(ctx context.Context, observed *Obs
 		}
 
 		// JobManager StatefulSet.
-		if !IsApplicationModeCluster(observed.cluster) {
+		if !render.IsApplicationModeCluster(observed.cluster) {
 			if err := observer.observeJobManager(ctx, observed); err != nil {
 				log.Error(err, "Failed to get JobManager StatefulSet")
 				return err
@@ -181,12 +292,21 @@ func (observer *ClusterStateObserver) observe(ctx context.Context, observed *Obs
 			return err
 		}
 
+		// (Optional) JobManager UI service.
+		if err := observer.observeJobManagerUIService(ctx, observed); err != nil {
+			log.Error(err, "Failed to get JobManager UI service")
+			return err
+		}
+
 		// (Optional) JobManager ingress.
 		if err := observer.observeJobManagerIngress(ctx, observed); err != nil {
 			log.Error(err, "Failed to get JobManager ingress")
 			return err
 		}
 
+		// (Optional) External DNS hostname of the JobManager.
+		observer.observeExternalDNS(ctx, observed)
+
 		// TaskManager
 		if err := observer.observeTaskManager(ctx, observed); err != nil {
 			log.Error(err, "Failed to get TaskManager")
@@ -205,8 +325,16 @@ func (observer *ClusterStateObserver) observe(ctx context.Context, observed *Obs
 			return err
 		}
 
+		// (Optional) Savepoint trigger job.
+		if observed.cluster.Spec.Job != nil {
+			if err := observer.observeSavepointTriggerJob(ctx, observed); err != nil {
+				log.Error(err, "Failed to get savepoint trigger job")
+				return err
+			}
+		}
+
 		// (Optional) Savepoint.
-		if err := observer.observeSavepoint(observed.cluster, &observed.savepoint); err != nil {
+		if err := observer.observeSavepoint(observed, &observed.savepoint); err != nil {
 			log.Error(err, "Failed to get Flink job savepoint status")
 		}
 
@@ -215,11 +343,59 @@ func (observer *ClusterStateObserver) observe(ctx context.Context, observed *Obs
 			return err
 		}
 
+		// JobManager and TaskManager pods.
+		if err := observer.observeComponentPods(ctx, observed); err != nil {
+			log.Error(err, "Failed to get JobManager and TaskManager pods")
+			return err
+		}
+
 		// (Optional) job.
 		if err := observer.observeJob(ctx, observed); err != nil {
 			log.Error(err, "Failed to get Flink job status")
 			return err
 		}
+
+		// (Optional) JAR files of the session cluster.
+		observer.observeSessionJars(ctx, observed)
+		if len(observed.cluster.Spec.Jars) > 0 {
+			if err := observer.observeJarUploaderJob(ctx, observed); err != nil {
+				log.Error(err, "Failed to get JAR uploader job")
+				return err
+			}
+		}
+
+		// (Optional) Jobs of the idle session cluster.
+		observer.observeSessionJobs(ctx, observed)
+
+		// (Optional) Readiness gates of the job.
+		observer.observeReadinessGates(ctx, observed)
+
+		// (Optional) Ownership of the savepoint to restore the job from.
+		observer.observeSavepointOwnership(ctx, observed)
+
+		// (Optional) Savepoint storage of the job.
+		observer.observeStorageProbe(ctx, observed)
+
+		// (Optional) Nodes of the pods being drained.
+		observer.observeDrainingNodes(ctx, observed)
+
+		// (Optional) Node of the JobManager for the savepoint storage.
+		observer.observeJobManagerNode(ctx, observed)
+
+		// (Optional) Lag of the Kafka consumer group of the job.
+		observer.observeKafkaLag(ctx, observed)
+
+		// (Optional) Clusters the cluster depends on.
+		if err := observer.observeDependencies(ctx, observed); err != nil {
+			log.Error(err, "Failed to get the dependencies of the cluster")
+			return err
+		}
+
+		// (Optional) Job cluster queue.
+		if err := observer.observeQueuePosition(ctx, observed); err != nil {
+			log.Error(err, "Failed to get the job cluster queue")
+			return err
+		}
 	}
 
 	observed.observeTime = time.Now()
@@ -259,11 +435,11 @@ func (observer *ClusterStateObserver) observeJob(
 	// Extract the log stream from pod only when the job state is Deploying.
 	var recordedJob = observed.cluster.Status.Components.Job
 	var jobName string
-	var applicationMode = IsApplicationModeCluster(observed.cluster)
+	var applicationMode = render.IsApplicationModeCluster(observed.cluster)
 	if applicationMode {
-		jobName = getJobManagerJobName(observed.cluster.Name)
+		jobName = render.JobManagerJobName(observed.cluster.Name)
 	} else {
-		jobName = getSubmitterJobName(observed.cluster.Name)
+		jobName = render.SubmitterJobName(observed.cluster.Name)
 	}
 
 	// Job resource.
@@ -311,7 +487,7 @@ func (observer *ClusterStateObserver) observeJob(
 	if jmReady {
 		// Observe the Flink job status.
 		var flinkJobID string
-		if jobID, ok := jobPod.Labels[JobIdLabel]; ok {
+		if jobID, ok := jobPod.Labels[render.JobIdLabel]; ok {
 			flinkJobID = jobID
 		} else
 		// Get the ID from the job submitter.
@@ -343,6 +519,8 @@ func (observer *ClusterStateObserver) observeFlinkJobStatus(ctx context.Context,
 	// Get Flink job status list.
 	flinkAPIBaseURL := getFlinkAPIBaseURL(observed.cluster)
 	flinkJobList, err := observer.flinkClient.GetJobsOverview(flinkAPIBaseURL)
+	var apiReachable = err == nil
+	flinkJob.apiReachable = &apiReachable
 	if err != nil {
 		// It is normal in many cases, not an error.
 		log.Info("Failed to get Flink job status list.", "error", err)
@@ -384,9 +562,143 @@ func (observer *ClusterStateObserver) observeFlinkJobStatus(ctx context.Context,
 		}
 	}
 
+	if flinkJobStatus != nil && shouldObserveJobAccumulators(observed.cluster, flinkJobStatus) {
+		flinkJobAccumulators, err := observer.flinkClient.GetJobAccumulators(flinkAPIBaseURL, flinkJobID)
+		if err != nil {
+			log.Info("Failed to get Flink job accumulators.", "error", err)
+		} else {
+			log.Info("Observed Flink job accumulators", "accumulators", flinkJobAccumulators)
+			flinkJob.accumulators = flinkJobAccumulators
+		}
+	}
+
+	if flinkJobStatus == nil || getFlinkJobDeploymentState(flinkJobStatus.State) != v1beta1.JobStateRunning {
+		return
+	}
+	var verifying = isRestoreVerificationInProgress(observed.cluster.Status.Components.Job)
+	var slo = observed.cluster.Spec.Job.SLO
+	var checkpointMetrics = observed.cluster.HasCheckpointMetrics()
+	if verifying || (slo != nil && slo.MaxCheckpointAgeSeconds != nil) || isStateDeltaTracked(observed.cluster.Spec.Job) ||
+		checkpointMetrics {
+		flinkJobCheckpoints, err := observer.flinkClient.GetJobCheckpoints(flinkAPIBaseURL, flinkJobID)
+		if err != nil {
+			log.Info("Failed to get Flink job checkpoints.", "error", err)
+		} else {
+			log.Info("Observed Flink job checkpoints", "counts", flinkJobCheckpoints.Counts)
+			flinkJob.checkpoints = flinkJobCheckpoints
+		}
+	}
+	if checkpointMetrics && flinkJob.checkpoints != nil {
+		flinkJob.checkpointAlignments = observer.observeCheckpointAlignments(ctx, flinkAPIBaseURL, flinkJobID, flinkJob.checkpoints)
+	}
+	if verifying || (slo != nil && slo.MaxRestartRatePerHour != nil) {
+		flinkJobMetrics, err := observer.flinkClient.GetJobMetrics(flinkAPIBaseURL, flinkJobID, "numRestarts")
+		if err != nil {
+			log.Info("Failed to get Flink job metrics.", "error", err)
+		}
+		for _, metric := range flinkJobMetrics {
+			if metric.ID != "numRestarts" {
+				continue
+			}
+			if numRestarts, err := strconv.ParseInt(metric.Value, 10, 64); err == nil {
+				log.Info("Observed Flink job restarts", "numRestarts", numRestarts)
+				flinkJob.numRestarts = &numRestarts
+			}
+		}
+	}
+	if slo != nil && slo.MaxConsumerLag != nil {
+		consumerLag, err := observer.observeConsumerLag(flinkAPIBaseURL, flinkJobID, slo.MaxConsumerLag)
+		if err != nil {
+			log.Info("Failed to get Flink job consumer lag.", "error", err)
+		} else {
+			log.Info("Observed Flink job consumer lag", "consumerLag", consumerLag)
+			flinkJob.consumerLag = &consumerLag
+		}
+	}
+	if isJobVertexSnapshotDue(observed.cluster.Status.Components.Job, observer.jobVertexStatusInterval, time.Now()) {
+		vertices, err := observer.observeJobVertices(flinkAPIBaseURL, flinkJobID)
+		if err != nil {
+			log.Info("Failed to get Flink job vertices.", "error", err)
+		} else {
+			log.Info("Observed Flink job vertices", "vertices", vertices)
+			flinkJob.vertices = vertices
+		}
+	}
+}
+
+// observeConsumerLag returns the metric of spec.job.slo.maxConsumerLag aggregated over the
+// subtasks and the vertices of the job.
+func (observer *ClusterStateObserver) observeConsumerLag(
+	flinkAPIBaseURL string, flinkJobID string, objective *v1beta1.ConsumerLagObjective) (int64, error) {
+	details, err := observer.flinkClient.GetJobDetails(flinkAPIBaseURL, flinkJobID)
+	if err != nil {
+		return 0, err
+	}
+	var metrics []flink.AggregatedMetric
+	for _, vertex := range details.Vertices {
+		available, err := observer.flinkClient.GetJobVertexMetrics(flinkAPIBaseURL, flinkJobID, vertex.ID)
+		if err != nil {
+			return 0, err
+		}
+		var ids = getConsumerLagMetricIDs(available, objective.Metric)
+		if len(ids) == 0 {
+			continue
+		}
+		vertexMetrics, err := observer.flinkClient.GetJobVertexMetrics(flinkAPIBaseURL, flinkJobID, vertex.ID, ids...)
+		if err != nil {
+			return 0, err
+		}
+		metrics = append(metrics, vertexMetrics...)
+	}
+	if len(metrics) == 0 {
+		return 0, fmt.Errorf("metric %v not found in the vertices of the job", objective.Metric)
+	}
+	return aggregateConsumerLag(metrics, objective.Aggregation), nil
+}
+
+// observeCheckpointAlignments returns the alignment times of the latest checkpoint and
+// savepoint which are not exported yet.
+func (observer *ClusterStateObserver) observeCheckpointAlignments(
+	ctx context.Context, flinkAPIBaseURL string, flinkJobID string, checkpoints *flink.JobCheckpoints) map[int64]float64 {
+	var log = logr.FromContextOrDiscard(ctx)
+	var alignments = map[int64]float64{}
+	for checkpointType, checkpoint := range getLatestCheckpoints(checkpoints) {
+		if checkpoint == nil || isCheckpointExported(observer.request.NamespacedName, checkpointType, checkpoint.ID) {
+			continue
+		}
+		alignment, err := observer.observeCheckpointAlignment(flinkAPIBaseURL, flinkJobID, checkpoint.ID)
+		if err != nil {
+			log.Info("Failed to get Flink checkpoint alignment.", "checkpoint", checkpoint.ID, "error", err)
+			continue
+		}
+		alignments[checkpoint.ID] = alignment
+	}
+	return alignments
+}
+
+// observeCheckpointAlignment returns the longest alignment time of the subtasks of the
+// vertices in the checkpoint, in seconds.
+func (observer *ClusterStateObserver) observeCheckpointAlignment(
+	flinkAPIBaseURL string, flinkJobID string, checkpointID int64) (float64, error) {
+	details, err := observer.flinkClient.GetCheckpointDetails(flinkAPIBaseURL, flinkJobID, checkpointID)
+	if err != nil {
+		return 0, err
+	}
+	var alignment int64
+	for vertexID := range details.Tasks {
+		taskDetails, err := observer.flinkClient.GetTaskCheckpointDetails(flinkAPIBaseURL, flinkJobID, checkpointID, vertexID)
+		if err != nil {
+			return 0, err
+		}
+		if duration := taskDetails.Summary.Alignment.Duration.Max; duration > alignment {
+			alignment = duration
+		}
+	}
+	return float64(alignment) / 1000, nil
 }
 
-func (observer *ClusterStateObserver) observeSavepoint(cluster *v1beta1.FlinkCluster, savepoint *Savepoint) error {
+func (observer *ClusterStateObserver) observeSavepoint(observed *ObservedClusterState, savepoint *Savepoint) error {
+	var cluster = observed.cluster
 	if cluster == nil ||
 		cluster.Status.Savepoint == nil ||
 		cluster.Status.Savepoint.State != v1beta1.SavepointStateInProgress {
@@ -399,7 +711,13 @@ func (observer *ClusterStateObserver) observeSavepoint(cluster *v1beta1.FlinkClu
 	var jobID = recordedSavepoint.JobID
 	var triggerID = recordedSavepoint.TriggerID
 
-	savepointStatus, err := observer.flinkClient.GetSavepointStatus(flinkAPIBaseURL, jobID, triggerID)
+	var savepointStatus *flink.SavepointStatus
+	var err error
+	if recordedSavepoint.TriggerJob != "" {
+		savepointStatus, err = getSavepointTriggerResult(jobID, observed.savepointTriggerJob, observed.savepointTriggerPod)
+	} else {
+		savepointStatus, err = observer.flinkClient.GetSavepointStatus(flinkAPIBaseURL, jobID, triggerID)
+	}
 	savepoint.status = savepointStatus
 	savepoint.error = err
 
@@ -410,6 +728,44 @@ func (observer *ClusterStateObserver) observeCluster(ctx context.Context, cluste
 	return observer.k8sClient.Get(ctx, observer.request.NamespacedName, cluster)
 }
 
+func (observer *ClusterStateObserver) observeQueuePosition(
+	ctx context.Context,
+	observed *ObservedClusterState) error {
+	observed.queuePosition = 0
+	if observer.maxRunningJobClusters <= 0 || !isQueueCandidate(observed.cluster) {
+		return nil
+	}
+	// Clusters waiting for their dependencies do not take a place in the queue.
+	if reason := observed.dependenciesNotReadyReason; reason != nil && *reason != "" {
+		return nil
+	}
+
+	var clusters = new(v1beta1.FlinkClusterList)
+	if err := observer.k8sClient.List(ctx, clusters, client.InNamespace(observed.cluster.Namespace)); err != nil {
+		return err
+	}
+	observed.queuePosition = getQueuePosition(observed.cluster, clusters.Items, observer.maxRunningJobClusters)
+	return nil
+}
+
+func (observer *ClusterStateObserver) observeDependencies(
+	ctx context.Context,
+	observed *ObservedClusterState) error {
+	observed.dependenciesNotReadyReason = nil
+	observed.succeededDependencies = nil
+	if !shouldCheckDependencies(observed.cluster) {
+		return nil
+	}
+
+	reason, succeeded, err := getDependenciesNotReadyReason(ctx, observer.k8sClient, observed.cluster)
+	if err != nil {
+		return err
+	}
+	observed.dependenciesNotReadyReason = &reason
+	observed.succeededDependencies = succeeded
+	return nil
+}
+
 func (observer *ClusterStateObserver) observeRevisions(
 	observed *ObservedClusterState) error {
 	observed.revisions = []*appsv1.ControllerRevision{}
@@ -429,7 +785,7 @@ func (observer *ClusterStateObserver) observePodDisruptionBudget(
 	observed *ObservedClusterState) error {
 	var clusterName = observer.request.Name
 	observed.podDisruptionBudget = new(policyv1.PodDisruptionBudget)
-	pdbName := getPodDisruptionBudgetName(clusterName)
+	pdbName := render.PodDisruptionBudgetName(clusterName)
 	if err := observer.observeObject(ctx, pdbName, observed.podDisruptionBudget); err != nil {
 		if client.IgnoreNotFound(err) != nil {
 			return err
@@ -444,7 +800,7 @@ func (observer *ClusterStateObserver) observeHorizontalPodAutoscaler(
 	observed *ObservedClusterState) error {
 	var clusterName = observer.request.Name
 	observed.horizontalPodAutoscaler = new(autoscalingv2.HorizontalPodAutoscaler)
-	hpaName := getHorizontalPodAutoscalerName(clusterName)
+	hpaName := render.HorizontalPodAutoscalerName(clusterName)
 	if err := observer.observeObject(ctx, hpaName, observed.horizontalPodAutoscaler); err != nil {
 		if client.IgnoreNotFound(err) != nil {
 			return err
@@ -459,7 +815,7 @@ func (observer *ClusterStateObserver) observeConfigMap(
 	observed *ObservedClusterState) error {
 	var clusterName = observer.request.Name
 	observed.configMap = new(corev1.ConfigMap)
-	configMapName := getConfigMapName(clusterName)
+	configMapName := render.ConfigMapName(clusterName)
 	if err := observer.observeObject(ctx, configMapName, observed.configMap); err != nil {
 		if client.IgnoreNotFound(err) != nil {
 			return err
@@ -489,11 +845,156 @@ func (observer *ClusterStateObserver) observeHAConfigMap(
 	return nil
 }
 
+func (observer *ClusterStateObserver) observeHARBAC(
+	ctx context.Context,
+	observed *ObservedClusterState) error {
+	var name = render.HAServiceAccountName(observer.request.Name)
+
+	observed.serviceAccount = new(corev1.ServiceAccount)
+	if err := observer.observeObject(ctx, name, observed.serviceAccount); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		observed.serviceAccount = nil
+	}
+
+	observed.role = new(rbacv1.Role)
+	if err := observer.observeObject(ctx, name, observed.role); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		observed.role = nil
+	}
+
+	observed.roleBinding = new(rbacv1.RoleBinding)
+	if err := observer.observeObject(ctx, name, observed.roleBinding); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		observed.roleBinding = nil
+	}
+
+	return nil
+}
+
+// Resolves the properties of spec.flinkPropertiesFrom, reading the Secrets directly from
+// the API server as the referenced configs. The Secret the properties are stored in is
+// observed only when the field is set or while the cluster is updated, which deletes the
+// Secret when the field is removed.
+func (observer *ClusterStateObserver) observeFlinkPropertiesFrom(
+	ctx context.Context,
+	observed *ObservedClusterState) error {
+	var cluster = observed.cluster
+	var sources = cluster.Spec.FlinkPropertiesFrom
+	var namespace = observer.request.Namespace
+	observed.flinkPropertiesFrom = nil
+	observed.flinkPropertiesSecret = nil
+	if len(sources) == 0 && !cluster.Status.Revision.IsUpdateTriggered() {
+		return nil
+	}
+
+	secret, err := observer.k8sClientset.CoreV1().Secrets(namespace).Get(
+		ctx, render.FlinkPropertiesSecretName(observer.request.Name), metav1.GetOptions{})
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if err == nil {
+		observed.flinkPropertiesSecret = secret
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+
+	var properties = map[string]string{}
+	for i, source := range sources {
+		switch {
+		case source.SecretRef != nil:
+			secret, err := observer.k8sClientset.CoreV1().Secrets(namespace).Get(ctx, source.SecretRef.Name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("spec.flinkPropertiesFrom[%d]: %v", i, err)
+			}
+			for key, value := range secret.Data {
+				properties[key] = string(value)
+			}
+		case source.External != nil:
+			if observer.secretResolver == nil {
+				return fmt.Errorf("spec.flinkPropertiesFrom[%d]: no external secret stores are configured in the operator", i)
+			}
+			values, err := observer.secretResolver.Resolve(ctx, namespace, string(source.External.Provider), source.External.Name)
+			if err != nil {
+				return fmt.Errorf("spec.flinkPropertiesFrom[%d]: %v", i, err)
+			}
+			for key, value := range values {
+				properties[key] = value
+			}
+		}
+	}
+	observed.flinkPropertiesFrom = properties
+	return nil
+}
+
+// Reads the referenced ConfigMaps and Secrets directly from the API server, so that
+// Secrets are not cached by the operator.
+func (observer *ClusterStateObserver) observeReferencedConfig(
+	ctx context.Context,
+	observed *ObservedClusterState) error {
+	var cluster = observed.cluster
+	observed.referencedConfigHash = ""
+	var update = shouldUpdateOnReferencedConfigChange(cluster)
+	if !update && observed.flinkPropertiesFrom == nil {
+		return nil
+	}
+
+	var namespace = observer.request.Namespace
+	var hash = sha256.New()
+	// The resolved properties always update the cluster, as the pods would not pick them up.
+	if observed.flinkPropertiesFrom != nil {
+		writeFlinkPropertiesFrom(hash, observed.flinkPropertiesFrom)
+	}
+	if !update {
+		observed.referencedConfigHash = fmt.Sprintf("%x", hash.Sum(nil))
+		return nil
+	}
+	for _, ref := range getReferencedConfigs(cluster) {
+		var data map[string][]byte
+		switch ref.kind {
+		case "ConfigMap":
+			configMap, err := observer.k8sClientset.CoreV1().ConfigMaps(namespace).Get(ctx, ref.name, metav1.GetOptions{})
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			if err == nil {
+				data = map[string][]byte{}
+				for key, value := range configMap.Data {
+					data[key] = []byte(value)
+				}
+				for key, value := range configMap.BinaryData {
+					data[key] = value
+				}
+			}
+		case "Secret":
+			secret, err := observer.k8sClientset.CoreV1().Secrets(namespace).Get(ctx, ref.name, metav1.GetOptions{})
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			if err == nil {
+				data = map[string][]byte{}
+				for key, value := range secret.Data {
+					data[key] = value
+				}
+			}
+		}
+		writeReferencedConfig(hash, ref, data)
+	}
+	observed.referencedConfigHash = fmt.Sprintf("%x", hash.Sum(nil))
+	return nil
+}
+
 func (observer *ClusterStateObserver) observeJobManager(
 	ctx context.Context,
 	observed *ObservedClusterState) error {
 	var clusterName = observer.request.Name
-	var jmStatefulSetName = getJobManagerName(clusterName)
+	var jmStatefulSetName = render.JobManagerName(clusterName)
 	observed.jmStatefulSet = new(appsv1.StatefulSet)
 	if err := observer.observeObject(ctx, jmStatefulSetName, observed.jmStatefulSet); err != nil {
 		if client.IgnoreNotFound(err) != nil {
@@ -513,7 +1014,7 @@ func (observer *ClusterStateObserver) observeTaskManager(
 	tmDeploymentType := observed.cluster.Spec.TaskManager.DeploymentType
 	if tmDeploymentType == "" || tmDeploymentType == v1beta1.DeploymentTypeStatefulSet {
 		observed.tmStatefulSet = new(appsv1.StatefulSet)
-		tmName := getTaskManagerName(clusterName)
+		tmName := render.TaskManagerName(clusterName)
 		if err := observer.observeObject(ctx, tmName, observed.tmStatefulSet); err != nil {
 			if client.IgnoreNotFound(err) != nil {
 				return err
@@ -525,7 +1026,7 @@ func (observer *ClusterStateObserver) observeTaskManager(
 	// TaskManager Deployment
 	if tmDeploymentType == v1beta1.DeploymentTypeDeployment {
 		observed.tmDeployment = new(appsv1.Deployment)
-		tmName := getTaskManagerName(clusterName)
+		tmName := render.TaskManagerName(clusterName)
 		if err := observer.observeObject(ctx, tmName, observed.tmDeployment); err != nil {
 			if client.IgnoreNotFound(err) != nil {
 				return err
@@ -534,6 +1035,182 @@ func (observer *ClusterStateObserver) observeTaskManager(
 		}
 	}
 
+	// Externally managed, canary or watched TaskManagers registered to the JobManager.
+	if render.IsTaskManagerExternal(observed.cluster) || observed.cluster.Status.CanaryUpdate.IsActive() ||
+		hasTaskManagerRegistrationWatchdog(observed.cluster) {
+		observer.observeRegisteredTaskManagers(ctx, observed)
+	}
+
+	return nil
+}
+
+// Observes the TaskManagers registered to the JobManager through Flink API, once it is ready.
+func (observer *ClusterStateObserver) observeRegisteredTaskManagers(
+	ctx context.Context,
+	observed *ObservedClusterState) {
+	var log = logr.FromContextOrDiscard(ctx)
+	var jmReady = render.IsApplicationModeCluster(observed.cluster) ||
+		(observed.jmStatefulSet != nil && getStatefulSetState(observed.jmStatefulSet) == v1beta1.ComponentStateReady)
+	if !jmReady {
+		return
+	}
+
+	taskManagers, err := observer.flinkClient.GetTaskManagers(getFlinkAPIBaseURL(observed.cluster))
+	if err != nil {
+		// It is normal while the JobManager is starting, not an error.
+		log.Info("Failed to get Flink TaskManagers.", "error", err)
+		return
+	}
+	log.Info("Observed Flink TaskManagers", "count", len(taskManagers.TaskManagers))
+	observed.registeredTaskManagers = taskManagers
+}
+
+// Observes the JAR files uploaded to the JobManager of the session cluster through Flink API, once it is ready.
+func (observer *ClusterStateObserver) observeSessionJars(
+	ctx context.Context,
+	observed *ObservedClusterState) {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = observed.cluster
+	if len(cluster.Spec.Jars) == 0 && len(cluster.Status.Jars) == 0 {
+		return
+	}
+	if observed.jmStatefulSet == nil || getStatefulSetState(observed.jmStatefulSet) != v1beta1.ComponentStateReady {
+		return
+	}
+
+	jars, err := observer.flinkClient.GetJars(getFlinkAPIBaseURL(cluster))
+	if err != nil {
+		// It is normal while the JobManager is starting, not an error.
+		log.Info("Failed to get Flink JAR files.", "error", err)
+		return
+	}
+	log.Info("Observed Flink JAR files", "count", len(jars.Files))
+	observed.sessionJars = jars
+}
+
+// Observes the jobs of the session cluster with spec.idlePolicy through Flink API, once the
+// JobManager is ready.
+func (observer *ClusterStateObserver) observeSessionJobs(
+	ctx context.Context,
+	observed *ObservedClusterState) {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = observed.cluster
+	if !render.IsIdlePolicyEnabled(cluster) {
+		return
+	}
+	var jmStatefulSet = observed.jmStatefulSet
+	if jmStatefulSet == nil || *jmStatefulSet.Spec.Replicas == 0 ||
+		getStatefulSetState(jmStatefulSet) != v1beta1.ComponentStateReady {
+		return
+	}
+
+	jobs, err := observer.flinkClient.GetJobsOverview(getFlinkAPIBaseURL(cluster))
+	if err != nil {
+		// It is normal while the JobManager is starting, not an error.
+		log.Info("Failed to get Flink jobs.", "error", err)
+		return
+	}
+	log.Info("Observed Flink jobs of the session cluster", "count", len(jobs.Jobs))
+	observed.sessionJobs = jobs
+}
+
+// Checks the readiness gates of the job in order while the job is about to be submitted,
+// and records the first gate which does not pass.
+func (observer *ClusterStateObserver) observeReadinessGates(
+	ctx context.Context,
+	observed *ObservedClusterState) {
+	var log = logr.FromContextOrDiscard(ctx)
+	observed.blockingReadinessGate = nil
+	if !shouldCheckReadinessGates(observed.cluster) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readinessGatesBudget)
+	defer cancel()
+	for i := range observed.cluster.Spec.Job.ReadinessGates {
+		var gate = &observed.cluster.Spec.Job.ReadinessGates[i]
+		if err := observer.checkReadinessGate(ctx, gate); err != nil {
+			log.Info("Job readiness gate does not pass", "gate", gate.Name, "reason", err.Error())
+			observed.blockingReadinessGate = &v1beta1.JobReadinessGateStatus{Name: gate.Name, Message: err.Error()}
+			return
+		}
+	}
+	log.Info("Job readiness gates passed")
+}
+
+// Verifies the ownership metadata of the savepoint to restore the job from while the job is
+// about to be submitted, and records why the savepoint is not compatible.
+func (observer *ClusterStateObserver) observeSavepointOwnership(
+	ctx context.Context,
+	observed *ObservedClusterState) {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = observed.cluster
+	observed.savepointRestoreBlockedReason = ""
+	if !shouldVerifySavepointOwnership(cluster) {
+		return
+	}
+
+	var savepoint = render.FromSavepoint(cluster.Spec.Job, cluster.Status.Components.Job, &cluster.Status.Revision)
+	if savepoint == nil || *savepoint == cluster.Spec.Job.SavepointOwnership.AllowedSavepoint {
+		return
+	}
+	if err := verifySavepointOwnership(ctx, observer.k8sClient, cluster, *savepoint); err != nil {
+		log.Info("Savepoint ownership verification failed", "savepoint", *savepoint, "reason", err.Error())
+		observed.savepointRestoreBlockedReason = fmt.Sprintf("savepoint %v: %v", *savepoint, err)
+		return
+	}
+	log.Info("Savepoint ownership verified", "savepoint", *savepoint)
+}
+
+// Probes the savepoint storage of the job while the job is about to be submitted, until the
+// probe passes for the current spec.
+func (observer *ClusterStateObserver) observeStorageProbe(
+	ctx context.Context,
+	observed *ObservedClusterState) {
+	var log = logr.FromContextOrDiscard(ctx)
+	observed.storageProbe = nil
+	if !shouldProbeStorage(observed.cluster) {
+		return
+	}
+
+	var result = probeStorage(ctx, observer.k8sClient, observed.cluster)
+	switch {
+	case result.failure != "":
+		log.Info("Storage probe failed", "reason", result.failure)
+	case result.warning != "":
+		log.Info("Storage probe inconclusive", "reason", result.warning)
+	default:
+		log.Info("Storage probe passed")
+	}
+	observed.storageProbe = &result
+}
+
+// Returns an error if the readiness gate does not pass.
+func (observer *ClusterStateObserver) checkReadinessGate(ctx context.Context, gate *v1beta1.JobReadinessGate) error {
+	switch {
+	case gate.HTTPGet != nil:
+		return checkHTTPReadinessGate(ctx, gate.HTTPGet, observer.request.Namespace, observer.readinessGateAllowedHosts)
+	case gate.KafkaTopic != nil:
+		ctx, cancel := context.WithTimeout(ctx, defaultReadinessGateTimeoutSeconds*time.Second)
+		defer cancel()
+		exists, err := kafka.TopicExists(ctx, gate.KafkaTopic.BootstrapServers, gate.KafkaTopic.Topic)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("Kafka topic %v does not exist", gate.KafkaTopic.Topic)
+		}
+	case gate.ConfigMapKey != nil:
+		configMap, err := observer.k8sClientset.CoreV1().ConfigMaps(observer.request.Namespace).Get(
+			ctx, gate.ConfigMapKey.Name, metav1.GetOptions{})
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		if err != nil {
+			configMap = nil
+		}
+		return checkConfigMapKeyReadinessGate(gate.ConfigMapKey, configMap)
+	}
 	return nil
 }
 
@@ -542,7 +1219,7 @@ func (observer *ClusterStateObserver) observeTaskManagerService(
 	observed *ObservedClusterState) error {
 	var clusterName = observer.request.Name
 	observed.tmService = new(corev1.Service)
-	name := getTaskManagerName(clusterName)
+	name := render.TaskManagerName(clusterName)
 	if err := observer.observeObject(ctx, name, observed.tmService); err != nil {
 		if client.IgnoreNotFound(err) != nil {
 			return err
@@ -557,7 +1234,7 @@ func (observer *ClusterStateObserver) observeJobManagerService(
 	observed *ObservedClusterState) error {
 	var clusterName = observer.request.Name
 	observed.jmService = new(corev1.Service)
-	jmSvcName := getJobManagerServiceName(clusterName)
+	jmSvcName := render.JobManagerServiceName(clusterName)
 	if err := observer.observeObject(ctx, jmSvcName, observed.jmService); err != nil {
 		if client.IgnoreNotFound(err) != nil {
 			return err
@@ -567,12 +1244,27 @@ func (observer *ClusterStateObserver) observeJobManagerService(
 	return nil
 }
 
+func (observer *ClusterStateObserver) observeJobManagerUIService(
+	ctx context.Context,
+	observed *ObservedClusterState) error {
+	var clusterName = observer.request.Name
+	observed.jmUIService = new(corev1.Service)
+	name := render.JobManagerUIServiceName(clusterName)
+	if err := observer.observeObject(ctx, name, observed.jmUIService); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		observed.jmUIService = nil
+	}
+	return nil
+}
+
 func (observer *ClusterStateObserver) observeJobManagerIngress(
 	ctx context.Context,
 	observed *ObservedClusterState) error {
 	var clusterName = observer.request.Name
 	observed.jmIngress = new(networkingv1.Ingress)
-	jmIngressName := getJobManagerIngressName(clusterName)
+	jmIngressName := render.JobManagerIngressName(clusterName)
 	if err := observer.observeObject(ctx, jmIngressName, observed.jmIngress); err != nil {
 		if client.IgnoreNotFound(err) != nil {
 			return err
@@ -609,6 +1301,36 @@ func (observer *ClusterStateObserver) observeJobSubmitterPod(
 	return nil
 }
 
+func (observer *ClusterStateObserver) observeComponentPods(
+	ctx context.Context,
+	observed *ObservedClusterState) error {
+	var clusterNamespace = observer.request.Namespace
+	var selector = labels.SelectorFromSet(render.ClusterLabels(observed.cluster))
+	var podList = new(corev1.PodList)
+
+	var err = observer.k8sClient.List(
+		ctx,
+		podList,
+		client.InNamespace(clusterNamespace),
+		client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return err
+	}
+
+	observed.jmPods = nil
+	observed.tmPods = nil
+	for _, pod := range podList.Items {
+		switch pod.Labels["component"] {
+		case "jobmanager":
+			observed.jmPods = append(observed.jmPods, pod)
+		case "taskmanager":
+			observed.tmPods = append(observed.tmPods, pod)
+		}
+	}
+
+	return nil
+}
+
 func (observer *ClusterStateObserver) observePersistentVolumeClaims(
 	ctx context.Context,
 	observed *ObservedClusterState) error {
@@ -660,7 +1382,7 @@ func (observer *ClusterStateObserver) syncRevisionStatus(observed *ObservedClust
 	}
 
 	// create a new revision from the current cluster
-	nextRevision, err := newRevision(cluster, util.GetNextRevisionNumber(revisions), &collisionCount)
+	nextRevision, err := render.NewRevision(cluster, observed.referencedConfigHash, util.GetNextRevisionNumber(revisions), &collisionCount)
 	if err != nil {
 		return err
 	}
@@ -680,12 +1402,16 @@ func (observer *ClusterStateObserver) syncRevisionStatus(observed *ObservedClust
 		if err != nil {
 			return err
 		}
+		observer.sendRevisionEvent(cluster, revisions[revisionCount-1], nextRevision)
 	} else {
 		//if there is no equivalent revision we create a new one
 		nextRevision, err = controllerHistory.CreateControllerRevision(cluster, nextRevision, &collisionCount)
 		if err != nil {
 			return err
 		}
+		if revisionCount > 0 {
+			observer.sendRevisionEvent(cluster, revisions[revisionCount-1], nextRevision)
+		}
 	}
 
 	// if the current revision is nil we initialize the history by setting it to the next revision
@@ -704,6 +1430,20 @@ func (observer *ClusterStateObserver) syncRevisionStatus(observed *ObservedClust
 		return fmt.Errorf("current ControlRevision resoucre not found")
 	}
 
+	// Collapse the updates deferred by spec.updatePolicy: the next revision replaced before
+	// its update started is removed from the history.
+	if replaced := getReplacedNextRevision(cluster, revisions, nextRevision); replaced != nil {
+		if err := controllerHistory.DeleteControllerRevision(replaced); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		for i := range observed.revisions {
+			if observed.revisions[i] == replaced {
+				observed.revisions = append(observed.revisions[:i], observed.revisions[i+1:]...)
+				break
+			}
+		}
+	}
+
 	// Update revision status.
 	observed.revision = Revision{
 		currentRevision: currentRevision.DeepCopy(),
@@ -720,18 +1460,33 @@ func (observer *ClusterStateObserver) syncRevisionStatus(observed *ObservedClust
 	return nil
 }
 
+// Records the change of the spec from the last revision to the new one, so that the changes
+// of the clusters can be followed without the audit logs of the API server.
+func (observer *ClusterStateObserver) sendRevisionEvent(
+	cluster *v1beta1.FlinkCluster, last, next *appsv1.ControllerRevision) {
+	var message = fmt.Sprintf("Created revision %v, changed from revision %v: %v",
+		util.GetRevisionWithNameNumber(next), util.GetRevisionWithNameNumber(last), revisionPatch(last, next))
+	if len(message) > 1024 {
+		message = message[:1024] + "..."
+	}
+	observer.recorder.Event(cluster, corev1.EventTypeNormal, "RevisionCreated", message)
+}
+
+// The maximum number of revisions kept when spec.revisionHistoryLimit is unset.
+const defaultRevisionHistoryLimit = 10
+
+// truncateHistory deletes the oldest revisions beyond spec.revisionHistoryLimit, keeping the
+// current and the next revisions which the update in progress needs.
 func (observer *ClusterStateObserver) truncateHistory(observed *ObservedClusterState) error {
 	var cluster = observed.cluster
 	var revisions = observed.revisions
-	// TODO: default limit
-	var historyLimit int
+	var historyLimit = defaultRevisionHistoryLimit
 	if cluster.Spec.RevisionHistoryLimit != nil {
 		historyLimit = int(*cluster.Spec.RevisionHistoryLimit)
-	} else {
-		historyLimit = 10
 	}
 
-	nonLiveHistory := util.GetNonLiveHistory(revisions, historyLimit)
+	nonLiveHistory := util.GetNonLiveHistory(revisions, historyLimit,
+		observed.revision.currentRevision.Name, observed.revision.nextRevision.Name)
 
 	// delete any non-live history to maintain the revision limit.
 	for i := 0; i < len(nonLiveHistory); i++ {
@@ -783,6 +1538,11 @@ func (observer *ClusterStateObserver) logObservedState(ctx context.Context, obse
 		} else {
 			log = log.WithValues("jmService", "nil")
 		}
+		if observed.jmUIService != nil {
+			log = log.WithValues("jmUIService", *observed.jmUIService)
+		} else {
+			log = log.WithValues("jmUIService", "nil")
+		}
 		if observed.jmIngress != nil {
 			log = log.WithValues("jmIngress", *observed.jmIngress)
 		} else {
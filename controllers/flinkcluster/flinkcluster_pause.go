@@ -0,0 +1,44 @@
+package flinkcluster
+
+import (
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	pausedReasonSpec       = "SpecPaused"
+	pausedReasonAnnotation = "AnnotationPaused"
+)
+
+// isPaused returns true if the reconciliation of the cluster is paused by spec.paused or
+// by the paused annotation.
+func isPaused(cluster *v1beta1.FlinkCluster) bool {
+	return cluster != nil && (isPausedBySpec(cluster) || cluster.Annotations[v1beta1.PausedAnnotation] == "true")
+}
+
+func isPausedBySpec(cluster *v1beta1.FlinkCluster) bool {
+	return cluster.Spec.Paused != nil && *cluster.Spec.Paused
+}
+
+// setPausedCondition sets the Paused condition while the cluster is paused and removes it
+// once the cluster is resumed.
+func setPausedCondition(conditions *[]metav1.Condition, cluster *v1beta1.FlinkCluster) {
+	if !isPaused(cluster) {
+		meta.RemoveStatusCondition(conditions, v1beta1.ClusterConditionPaused)
+		return
+	}
+	var condition = metav1.Condition{
+		Type:               v1beta1.ClusterConditionPaused,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: cluster.Generation,
+		Reason:             pausedReasonAnnotation,
+		Message: "The reconciliation is paused by the annotation " + v1beta1.PausedAnnotation +
+			", the status is still updated but no components are changed.",
+	}
+	if isPausedBySpec(cluster) {
+		condition.Reason = pausedReasonSpec
+		condition.Message = "The reconciliation is paused by spec.paused, the status is still updated but no components are changed."
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
@@ -0,0 +1,53 @@
+package flinkcluster
+
+import (
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetPausedCondition(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{ObjectMeta: metav1.ObjectMeta{Name: "mycluster", Generation: 2}}
+	var conditions []metav1.Condition
+
+	setPausedCondition(&conditions, cluster)
+	assert.Assert(t, !isPaused(cluster))
+	assert.Assert(t, meta.FindStatusCondition(conditions, v1beta1.ClusterConditionPaused) == nil)
+
+	cluster.Annotations = map[string]string{v1beta1.PausedAnnotation: "true"}
+	setPausedCondition(&conditions, cluster)
+	assert.Assert(t, isPaused(cluster))
+	var condition = meta.FindStatusCondition(conditions, v1beta1.ClusterConditionPaused)
+	assert.Equal(t, condition.Status, metav1.ConditionTrue)
+	assert.Equal(t, condition.Reason, "AnnotationPaused")
+	assert.Equal(t, condition.ObservedGeneration, int64(2))
+
+	// spec.paused takes precedence in the reason.
+	var paused = true
+	cluster.Spec.Paused = &paused
+	setPausedCondition(&conditions, cluster)
+	assert.Equal(t, meta.FindStatusCondition(conditions, v1beta1.ClusterConditionPaused).Reason, "SpecPaused")
+
+	paused = false
+	cluster.Annotations[v1beta1.PausedAnnotation] = "false"
+	setPausedCondition(&conditions, cluster)
+	assert.Assert(t, !isPaused(cluster))
+	assert.Assert(t, meta.FindStatusCondition(conditions, v1beta1.ClusterConditionPaused) == nil)
+}
+
+func TestPausedIsNotRevisionData(t *testing.T) {
+	var cluster = getObservedClusterState().cluster
+	var revision, err = render.NewRevision(cluster, "", 1, nil)
+	assert.NilError(t, err)
+
+	var paused = true
+	cluster.Spec.Paused = &paused
+	pausedRevision, err := render.NewRevision(cluster, "", 1, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, string(pausedRevision.Data.Raw), string(revision.Data.Raw))
+	assert.Assert(t, cluster.Spec.Paused != nil)
+}
@@ -0,0 +1,109 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	defaultReadinessGateTimeoutSeconds = 3
+	// The time within which the readiness gates are checked in a reconciliation, the gates
+	// left are checked in the next one.
+	readinessGatesBudget = 10 * time.Second
+)
+
+// HTTP client of the readiness gates. It does not follow redirects, so that the requests
+// only go to the hosts allowed by isReadinessGateHostAllowed.
+var readinessGateHTTPClient = &http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+// shouldCheckReadinessGates returns true if spec.job.readinessGates is set and the job is
+// about to be submitted, i.e. it is neither active nor terminated.
+func shouldCheckReadinessGates(cluster *v1beta1.FlinkCluster) bool {
+	var jobSpec = cluster.Spec.Job
+	var job = cluster.Status.Components.Job
+	return jobSpec != nil && len(jobSpec.ReadinessGates) > 0 && !job.IsActive() && !job.IsTerminated(jobSpec)
+}
+
+// isReadinessGateHostAllowed returns true if the host is a Service of the namespace, e.g.
+// `<service>.<namespace>.svc`, or matches one of the allowed hosts of the operator, which
+// are host names or `*.<domain>` patterns.
+func isReadinessGateHostAllowed(host string, namespace string, allowedHosts []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, suffix := range []string{"." + namespace + ".svc", "." + namespace + ".svc.cluster.local"} {
+		if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+			return true
+		}
+	}
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == "" {
+			continue
+		}
+		if allowed == host || (strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkHTTPReadinessGate returns an error if the host of the gate is not allowed, or if the
+// GET request of the gate fails or does not return a 2xx status code.
+func checkHTTPReadinessGate(
+	ctx context.Context, gate *v1beta1.HTTPReadinessGate, namespace string, allowedHosts []string) error {
+	u, err := url.Parse(gate.URL)
+	if err != nil {
+		return err
+	}
+	if !isReadinessGateHostAllowed(u.Hostname(), namespace, allowedHosts) {
+		return fmt.Errorf("host %v is neither a Service of namespace %v nor allowed by the operator", u.Hostname(), namespace)
+	}
+	var timeout = int32(defaultReadinessGateTimeoutSeconds)
+	if gate.TimeoutSeconds != nil {
+		timeout = *gate.TimeoutSeconds
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, gate.URL, nil)
+	if err != nil {
+		return err
+	}
+	response, err := readinessGateHTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("GET %v returned %v", gate.URL, response.Status)
+	}
+	return nil
+}
+
+// checkConfigMapKeyReadinessGate returns an error if the key of the gate is not in the
+// ConfigMap, nil if it does not exist, or does not have the value of the gate.
+func checkConfigMapKeyReadinessGate(gate *v1beta1.ConfigMapKeyReadinessGate, configMap *corev1.ConfigMap) error {
+	if configMap == nil {
+		return fmt.Errorf("ConfigMap %v not found", gate.Name)
+	}
+	value, ok := configMap.Data[gate.Key]
+	if !ok {
+		if binaryValue, binaryOk := configMap.BinaryData[gate.Key]; binaryOk {
+			value, ok = string(binaryValue), true
+		}
+	}
+	if !ok {
+		return fmt.Errorf("key %v not found in ConfigMap %v", gate.Key, gate.Name)
+	}
+	if gate.Value != nil && value != *gate.Value {
+		return fmt.Errorf("key %v of ConfigMap %v is %q, expected %q", gate.Key, gate.Name, value, *gate.Value)
+	}
+	return nil
+}
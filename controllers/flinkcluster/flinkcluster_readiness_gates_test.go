@@ -0,0 +1,110 @@
+package flinkcluster
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestShouldCheckReadinessGates(t *testing.T) {
+	var restartPolicy = v1beta1.JobRestartPolicyNever
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			Job: &v1beta1.JobSpec{
+				RestartPolicy: &restartPolicy,
+				ReadinessGates: []v1beta1.JobReadinessGate{
+					{Name: "flag", ConfigMapKey: &v1beta1.ConfigMapKeyReadinessGate{Name: "upstream", Key: "published"}},
+				},
+			},
+		},
+	}
+	assert.Assert(t, shouldCheckReadinessGates(cluster))
+
+	cluster.Status.Components.Job = &v1beta1.JobStatus{State: v1beta1.JobStatePending}
+	assert.Assert(t, shouldCheckReadinessGates(cluster))
+	cluster.Status.Components.Job.State = v1beta1.JobStateUpdating
+	assert.Assert(t, shouldCheckReadinessGates(cluster))
+
+	cluster.Status.Components.Job.State = v1beta1.JobStateDeploying
+	assert.Assert(t, !shouldCheckReadinessGates(cluster))
+	cluster.Status.Components.Job.State = v1beta1.JobStateRunning
+	assert.Assert(t, !shouldCheckReadinessGates(cluster))
+	cluster.Status.Components.Job.State = v1beta1.JobStateSucceeded
+	assert.Assert(t, !shouldCheckReadinessGates(cluster))
+
+	cluster.Status.Components.Job.State = v1beta1.JobStatePending
+	cluster.Spec.Job.ReadinessGates = nil
+	assert.Assert(t, !shouldCheckReadinessGates(cluster))
+}
+
+func TestIsReadinessGateHostAllowed(t *testing.T) {
+	var allowed = []string{"status.example.com", "*.internal.example.com", ""}
+
+	assert.Assert(t, isReadinessGateHostAllowed("upstream.default.svc", "default", nil))
+	assert.Assert(t, isReadinessGateHostAllowed("upstream.default.svc.cluster.local.", "default", nil))
+	assert.Assert(t, !isReadinessGateHostAllowed("upstream.other.svc", "default", nil))
+	assert.Assert(t, !isReadinessGateHostAllowed("default.svc", "default", nil))
+	assert.Assert(t, !isReadinessGateHostAllowed("169.254.169.254", "default", allowed))
+	assert.Assert(t, !isReadinessGateHostAllowed("", "default", allowed))
+
+	assert.Assert(t, isReadinessGateHostAllowed("Status.Example.com", "default", allowed))
+	assert.Assert(t, isReadinessGateHostAllowed("batch.internal.example.com", "default", allowed))
+	assert.Assert(t, !isReadinessGateHostAllowed("internal.example.com", "default", allowed))
+	assert.Assert(t, !isReadinessGateHostAllowed("example.com", "default", allowed))
+}
+
+func TestCheckHTTPReadinessGate(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2022-05-01/_SUCCESS":
+		case "/redirect":
+			http.Redirect(w, r, "/2022-05-01/_SUCCESS", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	var allowed = []string{"127.0.0.1"}
+
+	var gate = &v1beta1.HTTPReadinessGate{URL: server.URL + "/2022-05-01/_SUCCESS"}
+	assert.NilError(t, checkHTTPReadinessGate(context.Background(), gate, "default", allowed))
+	assert.Error(t, checkHTTPReadinessGate(context.Background(), gate, "default", nil),
+		"host 127.0.0.1 is neither a Service of namespace default nor allowed by the operator")
+
+	gate.URL = server.URL + "/2022-05-02/_SUCCESS"
+	assert.Error(t, checkHTTPReadinessGate(context.Background(), gate, "default", allowed),
+		"GET "+server.URL+"/2022-05-02/_SUCCESS returned 404 Not Found")
+
+	// The redirects are not followed.
+	gate.URL = server.URL + "/redirect"
+	assert.Error(t, checkHTTPReadinessGate(context.Background(), gate, "default", allowed),
+		"GET "+server.URL+"/redirect returned 302 Found")
+}
+
+func TestCheckConfigMapKeyReadinessGate(t *testing.T) {
+	var value = "true"
+	var gate = &v1beta1.ConfigMapKeyReadinessGate{Name: "upstream", Key: "published"}
+	var configMap = &corev1.ConfigMap{Data: map[string]string{"published": "false"}}
+
+	assert.NilError(t, checkConfigMapKeyReadinessGate(gate, configMap))
+
+	gate.Value = &value
+	assert.Error(t, checkConfigMapKeyReadinessGate(gate, configMap),
+		`key published of ConfigMap upstream is "false", expected "true"`)
+
+	configMap.Data["published"] = "true"
+	assert.NilError(t, checkConfigMapKeyReadinessGate(gate, configMap))
+
+	configMap = &corev1.ConfigMap{BinaryData: map[string][]byte{"published": []byte("true")}}
+	assert.NilError(t, checkConfigMapKeyReadinessGate(gate, configMap))
+
+	configMap = &corev1.ConfigMap{Data: map[string]string{"other": "true"}}
+	assert.Error(t, checkConfigMapKeyReadinessGate(gate, configMap), "key published not found in ConfigMap upstream")
+
+	assert.Error(t, checkConfigMapKeyReadinessGate(gate, nil), "ConfigMap upstream not found")
+}
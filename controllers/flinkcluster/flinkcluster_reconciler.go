@@ -19,12 +19,18 @@ package flinkcluster
 import (
 	"context"
 	"fmt"
+	"io"
+	"path"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 
@@ -33,8 +39,9 @@ import (
 	"github.com/spotify/flink-on-k8s-operator/internal/batchscheduler"
 	schedulerTypes "github.com/spotify/flink-on-k8s-operator/internal/batchscheduler/types"
 	"github.com/spotify/flink-on-k8s-operator/internal/flink"
-	"github.com/spotify/flink-on-k8s-operator/internal/model"
 	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/model"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
@@ -46,11 +53,17 @@ import (
 // ClusterReconciler takes actions to drive the observed state towards the
 // desired state.
 type ClusterReconciler struct {
-	k8sClient   client.Client
-	flinkClient *flink.Client
-	observed    ObservedClusterState
-	desired     model.DesiredClusterState
-	recorder    record.EventRecorder
+	k8sClient    client.Client
+	k8sClientset *kubernetes.Clientset
+	restConfig   *rest.Config
+	flinkClient  *flink.Client
+	observed     ObservedClusterState
+	desired      model.DesiredClusterState
+	recorder     record.EventRecorder
+
+	debugContainerImage string
+	operatorImage       string
+	imagePullSecrets    []corev1.LocalObjectReference
 }
 
 const JobCheckInterval = 10 * time.Second
@@ -69,6 +82,35 @@ func (reconciler *ClusterReconciler) reconcile(ctx context.Context) (ctrl.Result
 		return ctrl.Result{}, nil
 	}
 
+	if reconciler.observed.cluster.DeletionTimestamp != nil {
+		return ctrl.Result{}, reconciler.finalizeCluster(ctx)
+	}
+
+	err = reconciler.reconcileDeletionPolicyFinalizer(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Finished job clusters are deleted by spec.job.cleanupPolicy.
+	deleted, err := reconciler.reconcileFinishedClusters(ctx)
+	if err != nil || deleted {
+		return ctrl.Result{}, err
+	}
+
+	// Queued clusters are not started until their dependencies are ready and a slot of
+	// their queue is free.
+	if reconciler.observed.cluster.Status.State == v1beta1.ClusterStateQueued {
+		log.Info("The cluster is queued, no action to take",
+			"position", reconciler.observed.cluster.Status.QueuePosition,
+			"waitingForDependencies", isWaitingForDependencies(reconciler.observed.cluster))
+		return requeueResult, nil
+	}
+
+	err = reconciler.reconcileAdoption(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if shouldUpdateCluster(&reconciler.observed) {
 		log.Info("The cluster update is in progress")
 	}
@@ -83,11 +125,21 @@ func (reconciler *ClusterReconciler) reconcile(ctx context.Context) (ctrl.Result
 		return ctrl.Result{}, err
 	}
 
+	err = reconciler.reconcileFlinkPropertiesSecret(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	err = reconciler.reconcileHAConfigMap(ctx)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
+	err = reconciler.reconcileHARBAC(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	err = reconciler.reconcilePodDisruptionBudget(ctx)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -103,6 +155,11 @@ func (reconciler *ClusterReconciler) reconcile(ctx context.Context) (ctrl.Result
 		return ctrl.Result{}, err
 	}
 
+	err = reconciler.reconcileJobManagerUIService(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	err = reconciler.reconcileJobManagerIngress(ctx)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -118,6 +175,11 @@ func (reconciler *ClusterReconciler) reconcile(ctx context.Context) (ctrl.Result
 		return ctrl.Result{}, err
 	}
 
+	err = reconciler.reconcileTaskManagerRegistration(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	err = reconciler.reconcileHorizontalPodAutoscaler(ctx)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -133,11 +195,67 @@ func (reconciler *ClusterReconciler) reconcile(ctx context.Context) (ctrl.Result
 		return ctrl.Result{}, err
 	}
 
+	err = reconciler.reconcileJars(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = reconciler.reconcileDiagnostics(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = reconciler.reconcileJobPlan(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = reconciler.reconcileStateExport(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = reconciler.reconcileSavepointOwnership(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = reconciler.reconcileSavepointTriggerJob(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	result, err := reconciler.reconcileJob(ctx)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
+	// The registration of externally managed TaskManagers and of the TaskManager pods
+	// which are not registered yet, the JAR files uploaded to the JobManager, the flight
+	// recordings in progress, the termination of the TaskManager pods before the
+	// JobManager is deleted, the opening of the update window of a deferred update, the
+	// tasks of the decommissioned TaskManagers and the jobs of an idle session cluster are
+	// not watched, poll them.
+	var cluster = reconciler.observed.cluster
+	var _, registrationPending = getUnregisteredTaskManagerPods(&reconciler.observed)
+	if result.IsZero() && (render.IsTaskManagerExternal(cluster) || render.IsIdlePolicyEnabled(cluster) || registrationPending ||
+		len(cluster.Spec.Jars) > 0 || isFlightRecordingInProgress(cluster) ||
+		cluster.Status.TaskManagerDecommission != nil ||
+		shouldDeferJobManagerDeletion(&reconciler.observed, reconciler.desired.JmStatefulSet) ||
+		isUpdateDeferred(cluster, &cluster.Status.Revision, reconciler.observed.observeTime)) {
+		return requeueResult, nil
+	}
+
+	// Delete the finished cluster once its TTL expires.
+	if expiry := getClusterExpiry(cluster); result.IsZero() && !expiry.IsZero() {
+		return ctrl.Result{RequeueAfter: time.Until(expiry)}, nil
+	}
+
+	// Check the components of the cluster being created once its startup deadline passes.
+	if deadline := getStartupDeadline(cluster); result.IsZero() && !deadline.IsZero() {
+		return ctrl.Result{RequeueAfter: time.Until(deadline) + time.Second}, nil
+	}
+
 	return result, nil
 }
 
@@ -150,7 +268,7 @@ func (reconciler *ClusterReconciler) reconcileBatchScheduler() error {
 
 	scheduler, err := batchscheduler.GetScheduler(schedulerSpec.Name)
 	if err != nil {
-		return err
+		return newUserConfigError(err)
 	}
 
 	options := schedulerTypes.SchedulerOptions{
@@ -158,7 +276,7 @@ func (reconciler *ClusterReconciler) reconcileBatchScheduler() error {
 		ClusterNamespace:  cluster.Namespace,
 		Queue:             schedulerSpec.Queue,
 		PriorityClassName: schedulerSpec.PriorityClassName,
-		OwnerReferences:   []metav1.OwnerReference{ToOwnerReference(cluster)},
+		OwnerReferences:   []metav1.OwnerReference{render.ToOwnerReference(cluster)},
 	}
 	err = scheduler.Schedule(options, &reconciler.desired)
 	if err != nil {
@@ -169,27 +287,76 @@ func (reconciler *ClusterReconciler) reconcileBatchScheduler() error {
 }
 
 func (reconciler *ClusterReconciler) reconcileJobManagerStatefulSet(ctx context.Context) error {
+	if shouldDeferJobManagerDeletion(&reconciler.observed, reconciler.desired.JmStatefulSet) {
+		logr.FromContextOrDiscard(ctx).Info("Deferred JobManager deletion until the TaskManagers are deleted", "component", "JobManager")
+		return nil
+	}
+	var desiredStatefulSet = reconciler.desired.JmStatefulSet
+	var observedStatefulSet = reconciler.observed.jmStatefulSet
+
+	// The JobManager is scaled to and from zero by the idle policy without an update.
+	if desiredStatefulSet != nil && observedStatefulSet != nil &&
+		isComponentUpdated(observedStatefulSet, reconciler.observed.cluster) &&
+		isScaledForIdlePolicy(desiredStatefulSet.Spec.Replicas, observedStatefulSet.Spec.Replicas) {
+		return reconciler.updateComponent(ctx, desiredStatefulSet, "JobManager")
+	}
 	return reconciler.reconcileComponent(
 		ctx,
 		"JobManager",
-		reconciler.desired.JmStatefulSet,
-		reconciler.observed.jmStatefulSet)
+		desiredStatefulSet,
+		observedStatefulSet)
 }
 
 func (reconciler *ClusterReconciler) reconcileTaskManagerStatefulSet(ctx context.Context) error {
+	// The scale-down is held until the TaskManagers it removes are decommissioned.
+	if held, err := reconciler.reconcileTaskManagerDecommission(ctx); held || err != nil {
+		return err
+	}
+
+	var desiredStatefulSet = reconciler.desired.TmStatefulSet
+	var observedStatefulSet = reconciler.observed.tmStatefulSet
+
+	// The canaries of an aborted or failed canary update return to the current revision,
+	// which the desired StatefulSet is rendered from.
+	if desiredStatefulSet != nil && observedStatefulSet != nil &&
+		render.IsCanaryUpdateRejected(reconciler.observed.cluster) &&
+		(observedStatefulSet.Labels[render.RevisionNameLabel] != desiredStatefulSet.Labels[render.RevisionNameLabel] ||
+			getStatefulSetPartition(desiredStatefulSet) != getStatefulSetPartition(observedStatefulSet) ||
+			isScaledForIdlePolicy(desiredStatefulSet.Spec.Replicas, observedStatefulSet.Spec.Replicas)) {
+		return reconciler.updateComponent(ctx, desiredStatefulSet, "TaskManager")
+	}
+
+	// The partition of a canary update changes after the StatefulSet is updated to the
+	// next revision, when the update is promoted to the other TaskManagers. The TaskManagers
+	// are scaled to and from zero by the idle policy without an update.
+	if desiredStatefulSet != nil && observedStatefulSet != nil &&
+		isComponentUpdated(observedStatefulSet, reconciler.observed.cluster) &&
+		(getStatefulSetPartition(desiredStatefulSet) != getStatefulSetPartition(observedStatefulSet) ||
+			isScaledForIdlePolicy(desiredStatefulSet.Spec.Replicas, observedStatefulSet.Spec.Replicas)) {
+		return reconciler.updateComponent(ctx, desiredStatefulSet, "TaskManager")
+	}
 	return reconciler.reconcileComponent(
 		ctx,
 		"TaskManager",
-		reconciler.desired.TmStatefulSet,
-		reconciler.observed.tmStatefulSet)
+		desiredStatefulSet,
+		observedStatefulSet)
 }
 
 func (reconciler *ClusterReconciler) reconcileTaskManagerDeployment(ctx context.Context) error {
+	var desiredDeployment = reconciler.desired.TmDeployment
+	var observedDeployment = reconciler.observed.tmDeployment
+
+	// The TaskManagers are scaled to and from zero by the idle policy without an update.
+	if desiredDeployment != nil && observedDeployment != nil &&
+		isComponentUpdated(observedDeployment, reconciler.observed.cluster) &&
+		isScaledForIdlePolicy(desiredDeployment.Spec.Replicas, observedDeployment.Spec.Replicas) {
+		return reconciler.updateComponent(ctx, desiredDeployment, "TaskManager")
+	}
 	return reconciler.reconcileComponent(
 		ctx,
 		"TaskManager",
-		reconciler.desired.TmDeployment,
-		reconciler.observed.tmDeployment)
+		desiredDeployment,
+		observedDeployment)
 }
 
 func (reconciler *ClusterReconciler) reconcileComponent(
@@ -213,6 +380,10 @@ func (reconciler *ClusterReconciler) reconcileComponent(
 				err = reconciler.deleteComponent(ctx, desiredObj, component)
 			} else {
 				err = reconciler.updateComponent(ctx, desiredObj, component)
+				if isImmutableStatefulSetUpdateError(err, desiredObj) {
+					err = reconciler.recreateStatefulSet(
+						ctx, desiredObj.(*appsv1.StatefulSet), observedObj.(*appsv1.StatefulSet), component)
+				}
 			}
 			if err != nil {
 				return err
@@ -224,6 +395,11 @@ func (reconciler *ClusterReconciler) reconcileComponent(
 	}
 
 	if desiredObjIsNil && !observedObjIsNil {
+		// The object of a component disabled in spec.components is managed externally.
+		if !isOwnedBy(observedObj, reconciler.observed.cluster) {
+			log.Info("Component is not owned by the cluster, no action", "component", component)
+			return nil
+		}
 		return reconciler.deleteComponent(ctx, observedObj, component)
 	}
 
@@ -256,6 +432,10 @@ func (reconciler *ClusterReconciler) createComponent(
 		WithValues("component", component).
 		WithValues("object", obj)
 
+	if dropCreation(reconciler.observed.cluster, component) {
+		log.Info("Dropped creation by fault injection")
+		return nil
+	}
 	if err := reconciler.k8sClient.Create(ctx, obj); err != nil {
 		log.Error(err, "Failed to create")
 		return err
@@ -280,6 +460,42 @@ func (reconciler *ClusterReconciler) updateComponent(ctx context.Context, desire
 	return nil
 }
 
+// Deletes the StatefulSet whose immutable fields changed so that it is created from the
+// desired spec in the next reconciliation. The pods are orphaned and adopted by the new
+// StatefulSet if its selector is unchanged, otherwise they are deleted with it.
+func (reconciler *ClusterReconciler) recreateStatefulSet(
+	ctx context.Context, desired, observed *appsv1.StatefulSet, component string) error {
+	log := logr.FromContextOrDiscard(ctx).
+		WithValues("component", component).
+		WithValues("object", observed)
+	if observed.DeletionTimestamp != nil {
+		log.Info("StatefulSet is being deleted for recreation")
+		return nil
+	}
+
+	var propagation = metav1.DeletePropagationBackground
+	if canOrphanStatefulSetPods(observed, desired) {
+		propagation = metav1.DeletePropagationOrphan
+	}
+	var err = reconciler.k8sClient.Delete(
+		ctx,
+		observed,
+		client.PropagationPolicy(propagation),
+		client.Preconditions{UID: &observed.UID})
+	if client.IgnoreNotFound(err) != nil {
+		log.Error(err, "Failed to delete StatefulSet for recreation")
+		return err
+	}
+
+	log.Info("Deleted StatefulSet to recreate it with changed immutable fields", "propagationPolicy", propagation)
+	reconciler.recorder.Event(
+		reconciler.observed.cluster,
+		corev1.EventTypeNormal,
+		"Recreating",
+		fmt.Sprintf("Recreating %v StatefulSet as its immutable fields changed", component))
+	return nil
+}
+
 func (reconciler *ClusterReconciler) deleteComponent(
 	ctx context.Context, obj client.Object, component string) error {
 	log := logr.FromContextOrDiscard(ctx).
@@ -309,6 +525,19 @@ func (reconciler *ClusterReconciler) reconcileJobManagerService(ctx context.Cont
 	return reconciler.reconcileComponent(ctx, "JobManagerService", desiredJmService, observedJmService)
 }
 
+func (reconciler *ClusterReconciler) reconcileJobManagerUIService(ctx context.Context) error {
+	var desiredJmUIService = reconciler.desired.JmUIService
+	var observedJmUIService = reconciler.observed.jmUIService
+
+	if desiredJmUIService != nil && observedJmUIService != nil {
+		// v1.Service API does not handle update correctly when below values are empty.
+		desiredJmUIService.SetResourceVersion(observedJmUIService.GetResourceVersion())
+		desiredJmUIService.Spec.ClusterIP = observedJmUIService.Spec.ClusterIP
+	}
+
+	return reconciler.reconcileComponent(ctx, "JobManagerUIService", desiredJmUIService, observedJmUIService)
+}
+
 func (reconciler *ClusterReconciler) reconcileJobManagerIngress(ctx context.Context) error {
 	var desiredJmIngress = reconciler.desired.JmIngress
 	var observedJmIngress = reconciler.observed.jmIngress
@@ -323,6 +552,53 @@ func (reconciler *ClusterReconciler) reconcileConfigMap(ctx context.Context) err
 	return reconciler.reconcileComponent(ctx, "ConfigMap", desiredConfigMap, observedConfigMap)
 }
 
+// Reconciles the Secret of the properties resolved from spec.flinkPropertiesFrom as the
+// other components, but without logging its data.
+func (reconciler *ClusterReconciler) reconcileFlinkPropertiesSecret(ctx context.Context) error {
+	var log = logr.FromContextOrDiscard(ctx).WithValues("component", "FlinkPropertiesSecret")
+	var desired = reconciler.desired.FlinkPropertiesSecret
+	var observed = reconciler.observed.flinkPropertiesSecret
+	var k8sClient = reconciler.k8sClient
+
+	switch {
+	case desired != nil && observed == nil:
+		if err := k8sClient.Create(ctx, desired); err != nil {
+			log.Error(err, "Failed to create")
+			return err
+		}
+		log.Info("Created", "name", desired.Name)
+	case desired != nil && shouldUpdateCluster(&reconciler.observed) &&
+		!isComponentUpdated(observed, reconciler.observed.cluster):
+		desired.ResourceVersion = observed.ResourceVersion
+		if err := k8sClient.Update(ctx, desired); err != nil {
+			log.Error(err, "Failed to update")
+			return err
+		}
+		log.Info("Updated", "name", desired.Name)
+	case desired == nil && observed != nil:
+		if err := k8sClient.Delete(ctx, observed); client.IgnoreNotFound(err) != nil {
+			log.Error(err, "Failed to delete")
+			return err
+		}
+		log.Info("Deleted", "name", observed.Name)
+	}
+	return nil
+}
+
+// Reconciles the ServiceAccount, Role and RoleBinding for Kubernetes HA services.
+func (reconciler *ClusterReconciler) reconcileHARBAC(ctx context.Context) error {
+	var desired = reconciler.desired
+	var observed = reconciler.observed
+
+	if err := reconciler.reconcileComponent(ctx, "ServiceAccount", desired.ServiceAccount, observed.serviceAccount); err != nil {
+		return err
+	}
+	if err := reconciler.reconcileComponent(ctx, "Role", desired.Role, observed.role); err != nil {
+		return err
+	}
+	return reconciler.reconcileComponent(ctx, "RoleBinding", desired.RoleBinding, observed.roleBinding)
+}
+
 // Set the owner reference of the cluster to the HA ConfigMap (if it doesn't already have one)
 func (reconciler *ClusterReconciler) reconcileHAConfigMap(ctx context.Context) error {
 	var observedHAConfigMap = reconciler.observed.haConfigMap
@@ -330,7 +606,7 @@ func (reconciler *ClusterReconciler) reconcileHAConfigMap(ctx context.Context) e
 		return nil
 	}
 	if observedHAConfigMap.OwnerReferences == nil || len(observedHAConfigMap.OwnerReferences) == 0 {
-		observedHAConfigMap.OwnerReferences = []metav1.OwnerReference{ToOwnerReference(reconciler.observed.cluster)}
+		observedHAConfigMap.OwnerReferences = []metav1.OwnerReference{render.ToOwnerReference(reconciler.observed.cluster)}
 		err := reconciler.updateComponent(ctx, observedHAConfigMap, "HA ConfigMap")
 		if err != nil {
 			return err
@@ -396,6 +672,449 @@ func (reconciler *ClusterReconciler) reconcilePersistentVolumeClaim(ctx context.
 	return err
 }
 
+// Uploads the JAR files of spec.jars to the JobManager of the session cluster, and deletes
+// the JAR files it uploaded which are removed from spec.jars. The JAR IDs are recorded in
+// status.jars.
+func (reconciler *ClusterReconciler) reconcileJars(ctx context.Context) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = reconciler.observed.cluster
+	var sessionJars = reconciler.observed.sessionJars
+	// Not observed until the JobManager is ready.
+	if sessionJars == nil {
+		return nil
+	}
+
+	var apiBaseURL = getFlinkAPIBaseURL(cluster)
+	var uploaded = map[string]bool{}
+	for _, jar := range sessionJars.Files {
+		uploaded[jar.ID] = true
+	}
+	var recorded = cluster.Status.Jars
+	var known = append([]v1beta1.SessionJarStatus{}, recorded...)
+	var uploaderJob = reconciler.observed.jarUploaderJob
+	var uploaderFailure string
+	if uploaderJob != nil && uploaderJob.DeletionTimestamp == nil {
+		var results []v1beta1.SessionJarStatus
+		results, uploaderFailure = getJarUploaderResult(uploaderJob, reconciler.observed.jarUploaderPod)
+		for _, jar := range results {
+			log.Info("Uploaded JAR file", "name", jar.Name, "id", jar.ID)
+			reconciler.recorder.Eventf(cluster, corev1.EventTypeNormal, "JarUploaded",
+				"Uploaded JAR file %v as %v", jar.Name, jar.ID)
+			uploaded[jar.ID] = true
+		}
+		known = append(results, known...)
+	}
+
+	var jars []v1beta1.SessionJarStatus
+	var pending []v1beta1.SessionJar
+	for _, jar := range cluster.Spec.Jars {
+		if jarStatus := getUploadedJarStatus(jar, known, uploaded); jarStatus != nil {
+			jars = append(jars, *jarStatus)
+		} else {
+			pending = append(pending, jar)
+		}
+	}
+	if len(pending) > maxJarsPerUploader {
+		pending = pending[:maxJarsPerUploader]
+	}
+	var err = reconciler.reconcileJarUploaderJob(ctx, pending, jars, uploaderFailure)
+
+	// Keep the recorded JAR files of the pending ones until they are uploaded,
+	// and delete the others which are no longer referenced.
+	var referenced = map[string]bool{}
+	for _, jar := range jars {
+		referenced[jar.ID] = true
+	}
+	var reconciled = map[string]bool{}
+	for _, jar := range jars {
+		reconciled[jar.Name] = true
+	}
+	var kept []v1beta1.SessionJarStatus
+	for _, jar := range recorded {
+		if !reconciled[jar.Name] && uploaded[jar.ID] && isJarInSpec(cluster, jar.Name) {
+			kept = append(kept, jar)
+			referenced[jar.ID] = true
+		}
+	}
+	for _, jar := range recorded {
+		if referenced[jar.ID] || !uploaded[jar.ID] {
+			continue
+		}
+		log.Info("Deleting JAR file", "name", jar.Name, "id", jar.ID)
+		if deleteErr := reconciler.flinkClient.DeleteJar(apiBaseURL, jar.ID); deleteErr != nil {
+			log.Error(deleteErr, "Failed to delete JAR file", "name", jar.Name, "id", jar.ID)
+			if err == nil {
+				err = newFlinkRESTUnavailableError(deleteErr)
+			}
+			// Retry on the next reconciliation.
+			kept = append(kept, jar)
+			referenced[jar.ID] = true
+			continue
+		}
+		// JAR files with the same checksum share the ID.
+		delete(uploaded, jar.ID)
+	}
+	jars = append(jars, kept...)
+
+	if !reflect.DeepEqual(jars, recorded) && (len(jars) > 0 || len(recorded) > 0) {
+		if updateErr := reconciler.updateJarsStatus(ctx, jars); updateErr != nil && err == nil {
+			err = updateErr
+		}
+	}
+	return err
+}
+
+// Returns the status of the JAR file of spec.jars if the JobManager already has it or
+// another JAR file with the same checksum, nil if it must be uploaded.
+func getUploadedJarStatus(
+	jar v1beta1.SessionJar,
+	known []v1beta1.SessionJarStatus,
+	uploaded map[string]bool) *v1beta1.SessionJarStatus {
+	var reuse = func(found *v1beta1.SessionJarStatus) *v1beta1.SessionJarStatus {
+		return &v1beta1.SessionJarStatus{Name: jar.Name, URI: jar.URI, SHA256: found.SHA256, ID: found.ID}
+	}
+	if found := findUploadedJar(known, uploaded, func(j v1beta1.SessionJarStatus) bool {
+		return j.Name == jar.Name && j.URI == jar.URI && (jar.SHA256 == "" || strings.EqualFold(j.SHA256, jar.SHA256))
+	}); found != nil {
+		return reuse(found)
+	}
+	if jar.SHA256 != "" {
+		if found := findUploadedJar(known, uploaded, func(j v1beta1.SessionJarStatus) bool {
+			return strings.EqualFold(j.SHA256, jar.SHA256)
+		}); found != nil {
+			return reuse(found)
+		}
+	}
+	return nil
+}
+
+func isJarInSpec(cluster *v1beta1.FlinkCluster, name string) bool {
+	for _, jar := range cluster.Spec.Jars {
+		if jar.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Creates the JAR uploader Job for the pending JAR files, and deletes it once it completed,
+// or when it uploads other JAR files than the pending ones. A failed Job is reported once
+// and retried after an interval, unless the pending JAR files change.
+func (reconciler *ClusterReconciler) reconcileJarUploaderJob(
+	ctx context.Context,
+	pending []v1beta1.SessionJar,
+	jars []v1beta1.SessionJarStatus,
+	failure string) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = reconciler.observed.cluster
+	var job = reconciler.observed.jarUploaderJob
+	if job != nil {
+		if job.DeletionTimestamp != nil {
+			return nil
+		}
+		var finished = job.Status.Succeeded > 0 || job.Status.Failed > 0
+		var requestChanged = len(pending) == 0 || job.Annotations[jarUploaderRequestAnnotation] != getJarUploadRequest(pending)
+		if failure != "" && job.Annotations[jarUploaderReportedAnnotation] == "" {
+			log.Info("Failed to upload JAR files", "reason", failure)
+			reconciler.recorder.Event(cluster, corev1.EventTypeWarning, "JarUploadFailed", failure)
+			var patch = client.MergeFrom(job.DeepCopy())
+			metav1.SetMetaDataAnnotation(&job.ObjectMeta, jarUploaderReportedAnnotation, "true")
+			if err := reconciler.k8sClient.Patch(ctx, job, patch); err != nil {
+				return err
+			}
+		}
+		var retry = failure != "" && !time.Now().Before(getJarUploaderRetryTime(job))
+		if (failure != "" && !requestChanged && !retry) || (!finished && !requestChanged) {
+			return nil
+		}
+		var propagation = metav1.DeletePropagationBackground
+		if err := reconciler.k8sClient.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &propagation}); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		log.Info("Deleted JAR uploader job", "name", job.Name)
+		return nil
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	var uploaded = map[string]string{}
+	for _, jar := range jars {
+		uploaded[strings.ToLower(jar.SHA256)] = jar.ID
+	}
+	job = newJarUploaderJob(cluster, reconciler.operatorImage, reconciler.imagePullSecrets, pending, uploaded)
+	if err := reconciler.k8sClient.Create(ctx, job); err != nil {
+		return err
+	}
+	for _, jar := range pending {
+		log.Info("Uploading JAR file", "name", jar.Name, "uri", jar.URI)
+	}
+	log.Info("Created JAR uploader job", "name", job.Name)
+	return nil
+}
+
+func (reconciler *ClusterReconciler) updateJarsStatus(ctx context.Context, jars []v1beta1.SessionJarStatus) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var clusterClone = reconciler.observed.cluster.DeepCopy()
+	clusterClone.Status.Jars = jars
+	util.SetTimestamp(&clusterClone.Status.LastUpdateTime)
+	var err = reconciler.k8sClient.Status().Update(ctx, clusterClone)
+	if err != nil {
+		log.Error(err, "Failed to update JAR files status", "error", err)
+	} else {
+		log.Info("Succeeded to update JAR files status.", "jars", jars)
+	}
+	return err
+}
+
+// Takes the thread dumps requested with the user control, injects the debug containers
+// requested with the user control, and starts the flight recordings requested with the user
+// control. The heap dumps and the flight recordings are collected by the diagnostics
+// collectors of the pods.
+func (reconciler *ClusterReconciler) reconcileDiagnostics(ctx context.Context) error {
+	var cluster = reconciler.observed.cluster
+	switch getNewControlRequest(cluster) {
+	case v1beta1.ControlNameThreadDump:
+		reconciler.takeThreadDumps(ctx)
+	case v1beta1.ControlNameDebug:
+		reconciler.injectDebugContainer(ctx)
+	}
+	if cluster.Spec.Diagnostics == nil {
+		return nil
+	}
+
+	var pods []*corev1.Pod
+	for _, podList := range [][]corev1.Pod{reconciler.observed.jmPods, reconciler.observed.tmPods} {
+		for i := range podList {
+			pods = append(pods, &podList[i])
+		}
+	}
+	if getNewControlRequest(cluster) == v1beta1.ControlNameFlightRecording {
+		reconciler.startFlightRecordings(ctx, pods)
+	} else if isFlightRecordingInProgress(cluster) {
+		reconciler.finishFlightRecordings(ctx)
+	}
+	return nil
+}
+
+func (reconciler *ClusterReconciler) startFlightRecordings(ctx context.Context, pods []*corev1.Pod) {
+	var log = logr.FromContextOrDiscard(ctx)
+	var seconds = getFlightRecordingSeconds(reconciler.observed.cluster.Spec.Diagnostics)
+	var tc = &util.TimeConverter{}
+	var now = time.Now()
+	var fileName = "recording-" + now.UTC().Format(diagnosticsTimeFormat) + ".jfr"
+	var started, failures []string
+	for _, pod := range pods {
+		if !isMainContainerRunning(pod) {
+			continue
+		}
+		var stdout = new(strings.Builder)
+		var err = reconciler.execInMainContainer(ctx, pod, getFlightRecordingCommand(fileName, seconds), stdout)
+		if err == nil && !strings.Contains(stdout.String(), "Started recording") {
+			err = fmt.Errorf("failed to start flight recording in pod %v: %v", pod.Name, strings.TrimSpace(stdout.String()))
+		}
+		if err != nil {
+			log.Error(err, "Failed to start flight recording", "pod", pod.Name)
+			failures = append(failures, err.Error())
+			continue
+		}
+		log.Info("Started flight recording", "pod", pod.Name, "file", fileName)
+		started = append(started, pod.Name)
+	}
+
+	var controlStatus = getControlStatus(v1beta1.ControlNameFlightRecording, v1beta1.ControlStateInProgress)
+	controlStatus.Details = map[string]string{
+		flightRecordingFileKey:       fileName,
+		flightRecordingPodsKey:       strings.Join(started, ","),
+		flightRecordingFinishTimeKey: tc.ToString(now.Add(time.Duration(seconds) * time.Second)),
+	}
+	if len(started) == 0 {
+		// Nothing to collect, the updater fails the control with the message.
+		controlStatus.Message = "no flight recordings started"
+		if len(failures) > 0 {
+			controlStatus.Message = strings.Join(failures, "; ")
+		}
+		controlStatus.Details[controlCollectTimeKey] = tc.ToString(now)
+	}
+	var savepointStatus *v1beta1.SavepointStatus
+	reconciler.updateStatus(ctx, &savepointStatus, &controlStatus)
+}
+
+// Finishes the flight-recording control once the recordings are written, which the
+// diagnostics collectors of the pods then collect.
+func (reconciler *ClusterReconciler) finishFlightRecordings(ctx context.Context) {
+	var cluster = reconciler.observed.cluster
+	var control = cluster.Status.Control
+	if control.Details[controlCollectTimeKey] != "" {
+		return
+	}
+	if !util.HasTimeElapsed(control.Details[flightRecordingFinishTimeKey], time.Now(), flightRecordingGracePeriodSeconds) {
+		return
+	}
+
+	var fileName = control.Details[flightRecordingFileKey]
+	reconciler.recorder.Eventf(cluster, corev1.EventTypeNormal, "DiagnosticsCollected",
+		"Wrote flight recording %v of pods %v", path.Join(render.DiagnosticsPath, fileName), control.Details[flightRecordingPodsKey])
+	var controlStatus = control.DeepCopy()
+	util.SetTimestamp(&controlStatus.UpdateTime)
+	controlStatus.Details[controlCollectTimeKey] = controlStatus.UpdateTime
+	var savepointStatus *v1beta1.SavepointStatus
+	reconciler.updateStatus(ctx, &savepointStatus, &controlStatus)
+}
+
+// Returns the value of the Authorization header of the uploads to the object storage.
+func (reconciler *ClusterReconciler) getUploadAuthorization(ctx context.Context, upload *v1beta1.DiagnosticsUpload) (string, error) {
+	return getAuthorization(ctx, reconciler.k8sClient, reconciler.observed.cluster.Namespace, upload.AuthorizationSecret)
+}
+
+// Takes thread dumps of the JobManager and the selected TaskManagers through the Flink REST API.
+// They are uploaded to spec.diagnostics.upload if specified, otherwise they are stored in a
+// ConfigMap of the cluster.
+func (reconciler *ClusterReconciler) takeThreadDumps(ctx context.Context) {
+	var log = logr.FromContextOrDiscard(ctx)
+	var observed = &reconciler.observed
+	var cluster = observed.cluster
+	// Wait for the JobManager.
+	if observed.jmStatefulSet == nil || getStatefulSetState(observed.jmStatefulSet) != v1beta1.ComponentStateReady {
+		return
+	}
+
+	var apiBaseURL = getFlinkAPIBaseURL(cluster)
+	var threadDumps = map[string]string{}
+	var failures []string
+	var jmName = "jobmanager"
+	if len(observed.jmPods) > 0 {
+		jmName = observed.jmPods[0].Name
+	}
+	if threadDump, err := reconciler.flinkClient.GetJobManagerThreadDump(apiBaseURL); err != nil {
+		failures = append(failures, fmt.Sprintf("failed to take thread dump of the JobManager: %v", err))
+	} else {
+		threadDumps[jmName] = getThreadDumpText(threadDump)
+	}
+	var taskManagerIDs map[string]string
+	taskManagers, err := reconciler.flinkClient.GetTaskManagers(apiBaseURL)
+	if err == nil {
+		taskManagerIDs, err = getThreadDumpTaskManagers(
+			taskManagers.TaskManagers, observed.tmPods, cluster.Annotations[v1beta1.ThreadDumpTaskManagersAnnotation])
+	}
+	if err != nil {
+		failures = append(failures, fmt.Sprintf("failed to get TaskManagers: %v", err))
+	}
+	for name, id := range taskManagerIDs {
+		threadDump, err := reconciler.flinkClient.GetTaskManagerThreadDump(apiBaseURL, id)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("failed to take thread dump of TaskManager %v: %v", name, err))
+			continue
+		}
+		threadDumps[name] = getThreadDumpText(threadDump)
+	}
+	log.Info("Took thread dumps", "count", len(threadDumps), "failures", failures)
+
+	var controlStatus = getControlStatus(v1beta1.ControlNameThreadDump, v1beta1.ControlStateInProgress)
+	controlStatus.Details = map[string]string{}
+	if len(threadDumps) > 0 {
+		if cluster.Spec.Diagnostics != nil && cluster.Spec.Diagnostics.Upload != nil {
+			failures = append(failures, reconciler.uploadThreadDumps(ctx, threadDumps)...)
+		} else if err := reconciler.storeThreadDumps(ctx, threadDumps); err != nil {
+			failures = append(failures, err.Error())
+		} else {
+			controlStatus.Details[threadDumpConfigMapKey] = getThreadDumpConfigMapName(cluster.Name)
+		}
+	}
+	sort.Strings(failures)
+	controlStatus.Message = strings.Join(failures, "; ")
+	controlStatus.Details[controlCollectTimeKey] = controlStatus.UpdateTime
+	var savepointStatus *v1beta1.SavepointStatus
+	reconciler.updateStatus(ctx, &savepointStatus, &controlStatus)
+}
+
+// Injects the ephemeral container of the debug user control into the JobManager or
+// TaskManager pod, so that the pod can be debugged without changing its spec.
+func (reconciler *ClusterReconciler) injectDebugContainer(ctx context.Context) {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = reconciler.observed.cluster
+	var controlStatus = getControlStatus(v1beta1.ControlNameDebug, v1beta1.ControlStateInProgress)
+	controlStatus.Details = map[string]string{}
+
+	var err error
+	var pod *corev1.Pod
+	if reconciler.debugContainerImage == "" {
+		err = fmt.Errorf("the debug container image of the operator is not configured")
+	} else {
+		pod, err = getDebugTargetPod(&reconciler.observed, cluster.Annotations[v1beta1.DebugPodAnnotation])
+	}
+	if err == nil {
+		var container = newDebugContainer(pod, reconciler.debugContainerImage, time.Now())
+		var updated = pod.DeepCopy()
+		updated.Spec.EphemeralContainers = append(updated.Spec.EphemeralContainers, container)
+		_, err = reconciler.k8sClientset.CoreV1().Pods(pod.Namespace).UpdateEphemeralContainers(
+			ctx, pod.Name, updated, metav1.UpdateOptions{})
+		if err == nil {
+			log.Info("Injected debug container", "pod", pod.Name, "container", container.Name)
+			controlStatus.Details[debugPodKey] = pod.Name
+			controlStatus.Details[debugContainerKey] = container.Name
+		}
+	}
+	if err != nil {
+		log.Error(err, "Failed to inject debug container")
+		controlStatus.Message = fmt.Sprintf("failed to inject debug container: %v", err)
+	}
+	controlStatus.Details[controlCollectTimeKey] = controlStatus.UpdateTime
+	var savepointStatus *v1beta1.SavepointStatus
+	reconciler.updateStatus(ctx, &savepointStatus, &controlStatus)
+}
+
+// Uploads the thread dumps to `<uri>/<namespace>/<cluster>/<pod>/threaddump-<time>.txt`,
+// and returns the failures.
+func (reconciler *ClusterReconciler) uploadThreadDumps(ctx context.Context, threadDumps map[string]string) []string {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = reconciler.observed.cluster
+	authorization, err := reconciler.getUploadAuthorization(ctx, cluster.Spec.Diagnostics.Upload)
+	if err != nil {
+		return []string{fmt.Sprintf("failed to get the upload authorization: %v", err)}
+	}
+	var fileName = "threaddump-" + time.Now().UTC().Format(diagnosticsTimeFormat) + ".txt"
+	var failures []string
+	for name, threadDump := range threadDumps {
+		location, err := getDiagnosticsUploadLocation(cluster.Spec.Diagnostics.Upload.URI, cluster.Namespace, cluster.Name, name, fileName)
+		if err == nil {
+			err = uploadDiagnosticsFile(ctx, location, authorization, []byte(threadDump))
+		}
+		if err != nil {
+			log.Error(err, "Failed to upload thread dump", "name", name)
+			failures = append(failures, fmt.Sprintf("failed to upload thread dump of %v: %v", name, err))
+			continue
+		}
+		reconciler.recorder.Eventf(cluster, corev1.EventTypeNormal, "DiagnosticsCollected",
+			"Collected thread dump of %v at %v", name, location)
+	}
+	return failures
+}
+
+// Stores the thread dumps in the thread dump ConfigMap of the cluster, replacing the previous ones.
+func (reconciler *ClusterReconciler) storeThreadDumps(ctx context.Context, threadDumps map[string]string) error {
+	var cluster = reconciler.observed.cluster
+	configMap, err := newThreadDumpConfigMap(cluster, threadDumps)
+	if err != nil {
+		return err
+	}
+	err = reconciler.k8sClient.Create(ctx, configMap)
+	if errors.IsAlreadyExists(err) {
+		err = reconciler.k8sClient.Update(ctx, configMap)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store thread dumps in ConfigMap %v: %v", configMap.Name, err)
+	}
+	reconciler.recorder.Eventf(cluster, corev1.EventTypeNormal, "DiagnosticsCollected",
+		"Collected thread dumps of %v JVMs at ConfigMap %v", len(threadDumps), configMap.Name)
+	return nil
+}
+
+func (reconciler *ClusterReconciler) execInMainContainer(
+	ctx context.Context, pod *corev1.Pod, command []string, stdout io.Writer) error {
+	return util.ExecInPod(ctx, reconciler.restConfig, reconciler.k8sClientset, pod, pod.Spec.Containers[0].Name, command, stdout)
+}
+
 func (reconciler *ClusterReconciler) reconcileJob(ctx context.Context) (ctrl.Result, error) {
 	log := logr.FromContextOrDiscard(ctx)
 	var desiredJob = reconciler.desired.Job
@@ -413,6 +1132,20 @@ func (reconciler *ClusterReconciler) reconcileJob(ctx context.Context) (ctrl.Res
 
 	observedSubmitter := observed.flinkJobSubmitter.job
 
+	// Garbage collect the finished job submitter.
+	if desiredJob != nil && shouldDeleteFinishedSubmitter(&observed, time.Now()) {
+		log.Info("Finished job submitter expired", "ttlSecondsAfterFinished", *jobSpec.SubmitterTTLSecondsAfterFinished)
+		if err := reconciler.deleteJob(ctx, observedSubmitter); err != nil {
+			return requeueResult, err
+		}
+		observedSubmitter = nil
+	}
+
+	// The spec is rolled back once the job which failed the restore verification is stopped.
+	if shouldRollbackUpdate(&observed) {
+		return requeueResult, reconciler.rollbackUpdate(ctx)
+	}
+
 	if desiredJob != nil && job.IsTerminated(jobSpec) {
 		return ctrl.Result{}, nil
 	}
@@ -437,6 +1170,33 @@ func (reconciler *ClusterReconciler) reconcileJob(ctx context.Context) (ctrl.Res
 		}
 	}
 
+	// The job is not submitted until its readiness gates pass.
+	if desiredJob != nil && !job.IsActive() && observed.blockingReadinessGate != nil {
+		log.Info("Job submission is blocked by readiness gate",
+			"gate", observed.blockingReadinessGate.Name, "reason", observed.blockingReadinessGate.Message)
+		return requeueResult, nil
+	}
+
+	// The job is not restored from a savepoint of an incompatible job.
+	if desiredJob != nil && !job.IsActive() && observed.savepointRestoreBlockedReason != "" {
+		log.Info("Job submission is blocked by savepoint ownership", "reason", observed.savepointRestoreBlockedReason)
+		return requeueResult, nil
+	}
+
+	// The job is not submitted while its savepoint storage is not accessible.
+	if desiredJob != nil && !job.IsActive() && observed.storageProbe != nil && observed.storageProbe.failure != "" {
+		log.Info("Job submission is blocked by storage probe", "reason", observed.storageProbe.failure)
+		return requeueResult, nil
+	}
+
+	// The job is not restored from a savepoint which the Flink version of the cluster cannot restore.
+	if desiredJob != nil && !job.IsActive() {
+		if err := checkRestoreFlinkVersion(observed.cluster); err != nil {
+			log.Info("Job submission is blocked by the Flink version of the savepoint", "reason", err.Error())
+			return ctrl.Result{}, newPermanentError(err)
+		}
+	}
+
 	// Create new Flink job submitter when starting new job, updating job or restarting job in failure.
 	if desiredJob != nil && !job.IsActive() {
 		log.Info("Deploying Flink job")
@@ -454,6 +1214,21 @@ func (reconciler *ClusterReconciler) reconcileJob(ctx context.Context) (ctrl.Res
 			return ctrl.Result{}, nil
 		}
 
+		// The job state may be ambiguous, e.g. the submitter pod is lost or the JobManager restarted, while the
+		// recorded job has been recovered by the JobManager. Query the jobs right before the submission so that
+		// the same job is not submitted twice.
+		if !render.IsApplicationModeCluster(observed.cluster) {
+			duplicateJob, err := reconciler.findDuplicateJob()
+			if err != nil {
+				log.Info("Failed to check duplicate of the job to submit, will retry", "error", err.Error())
+				return requeueResult, nil
+			}
+			if duplicateJob != nil {
+				log.Info("Found the job already running in JobManager, skip job submission", "job", *duplicateJob)
+				return requeueResult, nil
+			}
+		}
+
 		// Create Flink job submitter
 		log.Info("Updating job status to proceed creating new job submitter")
 		// Job status must be updated before creating a job submitter to ensure the observed job is the job submitted by the operator.
@@ -465,7 +1240,7 @@ func (reconciler *ClusterReconciler) reconcileJob(ctx context.Context) (ctrl.Res
 
 		cr := getCurrentRevisionName(&observed.cluster.Status.Revision)
 		if observedSubmitter != nil {
-			if observedSubmitter.Labels[RevisionNameLabel] == cr {
+			if observedSubmitter.Labels[render.RevisionNameLabel] == cr {
 				log.Info("Found old job submitter")
 				err = reconciler.deleteJob(ctx, observedSubmitter)
 				if err != nil {
@@ -474,6 +1249,9 @@ func (reconciler *ClusterReconciler) reconcileJob(ctx context.Context) (ctrl.Res
 			}
 		} else {
 			err = reconciler.createJob(ctx, desiredJob)
+			if err == nil {
+				reconciler.auditJobSubmission(ctx)
+			}
 		}
 
 		return requeueResult, err
@@ -485,13 +1263,25 @@ func (reconciler *ClusterReconciler) reconcileJob(ctx context.Context) (ctrl.Res
 			return requeueResult, nil
 		}
 
+		// Stop the job restored from an incompatible savepoint without a savepoint, it is regarded as failed.
+		if isRestoreVerificationFailed(job) {
+			log.Info("Stopping job as it failed the restore verification", "jobID", jobID,
+				"savepoint", job.RestoreVerification.Savepoint, "reason", job.RestoreVerification.Message)
+			if err := reconciler.cancelRunningJobs(ctx, false /* takeSavepoint */); err != nil && !errors.IsResourceExpired(err) {
+				return requeueResult, err
+			}
+			reconciler.audit(ctx, newAuditRecord(&observed.cluster.Status, time.Now(), auditDecisionRestoreVerificationFailed, fmt.Sprintf(
+				"Stopped the job without a savepoint as its restore from savepoint %v failed the verification: %v",
+				job.RestoreVerification.Savepoint, job.RestoreVerification.Message)))
+			return requeueResult, nil
+		}
+
 		// Suspend or stop job to proceed update.
-		if recorded.Revision.IsUpdateTriggered() && isJobUpdate(observed.revisions, observed.cluster) {
-			log.Info("Preparing job update")
-			var takeSavepoint = jobSpec.TakeSavepointOnUpdate == nil || *jobSpec.TakeSavepointOnUpdate
-			var shouldSuspend = takeSavepoint && util.IsBlank(jobSpec.FromSavepoint)
-			if shouldSuspend {
-				newSavepointStatus, err = reconciler.trySuspendJob(ctx)
+		if isStoppingJobForUpdate(&observed) {
+			var stopMode = getUpdateStopMode(observed.cluster)
+			log.Info("Preparing job update", "updateStopMode", stopMode)
+			if stopMode != v1beta1.JobUpdateStopModeCancel {
+				newSavepointStatus, err = reconciler.trySuspendJob(ctx, stopMode)
 			} else if shouldUpdateJob(&observed) {
 				err = reconciler.cancelJob(ctx)
 			}
@@ -502,7 +1292,7 @@ func (reconciler *ClusterReconciler) reconcileJob(ctx context.Context) (ctrl.Res
 		if len(jobID) > 0 {
 			var savepointReason = reconciler.shouldTakeSavepoint()
 			if savepointReason != "" {
-				newSavepointStatus, err = reconciler.triggerSavepoint(ctx, jobID, savepointReason, false)
+				newSavepointStatus, err = reconciler.triggerSavepoint(ctx, jobID, savepointReason, "")
 			}
 			// Get new control status when the savepoint reason matches the requested control.
 			var userControl = getNewControlRequest(observed.cluster)
@@ -549,6 +1339,42 @@ func (reconciler *ClusterReconciler) reconcileJob(ctx context.Context) (ctrl.Res
 	return ctrl.Result{}, nil
 }
 
+// Records why the job is submitted and the savepoint it is restored from.
+func (reconciler *ClusterReconciler) auditJobSubmission(ctx context.Context) {
+	var observed = &reconciler.observed
+	var cluster = observed.cluster
+	var job = cluster.Status.Components.Job
+
+	var message string
+	switch {
+	case job.ShouldRestart(cluster.Spec.Job):
+		message = fmt.Sprintf("Submitted the job to restart the %v job %v", strings.ToLower(string(job.State)), job.ID)
+	case shouldUpdateJob(observed):
+		message = fmt.Sprintf("Submitted the job of revision %v to apply the update", cluster.Status.Revision.NextRevision)
+	default:
+		message = "Submitted the job"
+	}
+	var savepoint, source = render.SelectFromSavepoint(cluster.Spec.Job, job, &cluster.Status.Revision)
+	if savepoint != nil {
+		message = fmt.Sprintf("%v, restored from savepoint %v, chosen as %v", message, *savepoint, source)
+	} else {
+		message = fmt.Sprintf("%v without a savepoint", message)
+	}
+	var record = newAuditRecord(&cluster.Status, time.Now(), auditDecisionJobSubmit, message)
+	// The ID of the submitted job is not known yet.
+	record.JobID = ""
+	reconciler.audit(ctx, record)
+}
+
+// Appends the decision to the audit records of the cluster. The reconciliation proceeds
+// if the records cannot be written.
+func (reconciler *ClusterReconciler) audit(ctx context.Context, record auditRecord) {
+	var log = logr.FromContextOrDiscard(ctx)
+	if err := appendAuditRecords(ctx, reconciler.k8sClient, reconciler.observed.cluster, []auditRecord{record}); err != nil {
+		log.Error(err, "Failed to append the audit record", "record", record)
+	}
+}
+
 func (reconciler *ClusterReconciler) createJob(ctx context.Context, job *batchv1.Job) error {
 	log := logr.FromContextOrDiscard(ctx)
 	var k8sClient = reconciler.k8sClient
@@ -589,7 +1415,10 @@ func (reconciler *ClusterReconciler) getFlinkJobID() string {
 	return ""
 }
 
-func (reconciler *ClusterReconciler) trySuspendJob(ctx context.Context) (*v1beta1.SavepointStatus, error) {
+// trySuspendJob stops the job with a savepoint by the stop mode, unless the savepoint is in
+// progress or is waiting to be retried.
+func (reconciler *ClusterReconciler) trySuspendJob(
+	ctx context.Context, stopMode v1beta1.JobUpdateStopMode) (*v1beta1.SavepointStatus, error) {
 	log := logr.FromContextOrDiscard(ctx)
 	var recorded = reconciler.observed.cluster.Status
 
@@ -602,8 +1431,8 @@ func (reconciler *ClusterReconciler) trySuspendJob(ctx context.Context) (*v1beta
 	log.Info("Checking the conditions for progressing")
 	var canSuspend = reconciler.canSuspendJob(ctx, jobID, recorded.Savepoint)
 	if canSuspend {
-		log.Info("Triggering savepoint for suspending job")
-		var newSavepointStatus, err = reconciler.triggerSavepoint(ctx, jobID, v1beta1.SavepointReasonUpdate, true)
+		log.Info("Triggering savepoint for suspending job", "updateStopMode", stopMode)
+		var newSavepointStatus, err = reconciler.triggerSavepoint(ctx, jobID, v1beta1.SavepointReasonUpdate, stopMode)
 		if err != nil {
 			log.Info("Failed to trigger savepoint", "jobID", jobID, "triggerID", newSavepointStatus.TriggerID, "error", err)
 		} else {
@@ -647,7 +1476,20 @@ func (reconciler *ClusterReconciler) cancelUnexpectedJobs(
 	return reconciler.cancelJobs(ctx, takeSavepoint, unexpectedJobs)
 }
 
-// Cancel running jobs.
+// findDuplicateJob queries JobManager for an active Flink job which is the same as the recorded job.
+func (reconciler *ClusterReconciler) findDuplicateJob() (*flink.Job, error) {
+	var cluster = reconciler.observed.cluster
+	var recorded = cluster.Status.Components.Job
+	if recorded == nil || (recorded.ID == "" && recorded.Name == "") {
+		return nil, nil
+	}
+	jobs, err := reconciler.flinkClient.GetJobsOverview(getFlinkAPIBaseURL(cluster))
+	if err != nil {
+		return nil, err
+	}
+	return findDuplicateFlinkJob(recorded, jobs), nil
+}
+
 func (reconciler *ClusterReconciler) cancelRunningJobs(
 	ctx context.Context,
 	takeSavepoint bool) error {
@@ -693,7 +1535,7 @@ func (reconciler *ClusterReconciler) cancelFlinkJob(ctx context.Context, jobID s
 
 	var apiBaseURL = getFlinkAPIBaseURL(reconciler.observed.cluster)
 	log.Info("Stoping job", "jobID", jobID)
-	return reconciler.flinkClient.StopJob(apiBaseURL, jobID)
+	return newFlinkRESTUnavailableError(reconciler.flinkClient.StopJob(apiBaseURL, jobID))
 }
 
 // canSuspendJob
@@ -775,26 +1617,56 @@ func (reconciler *ClusterReconciler) shouldTakeSavepoint() v1beta1.SavepointReas
 			return v1beta1.SavepointReasonScheduled
 		}
 	}
-	return ""
+	// Savepoint by the state changes and the node drains
+	return getAdaptiveSavepointReason(jobSpec, job, savepoint, time.Now())
 }
 
-// Trigger savepoint for a job then return savepoint status to update.
+// Trigger savepoint for a job then return savepoint status to update. The job is stopped
+// with the savepoint by the stop mode, or keeps running if the stop mode is empty. The
+// savepoint is triggered by a Kubernetes Job instead of the Flink REST API by
+// spec.job.savepointTriggerMode.
 func (reconciler *ClusterReconciler) triggerSavepoint(
 	ctx context.Context,
 	jobID string,
 	triggerReason v1beta1.SavepointReason,
-	cancel bool) (*v1beta1.SavepointStatus, error) {
+	stopMode v1beta1.JobUpdateStopMode) (*v1beta1.SavepointStatus, error) {
 	log := logr.FromContextOrDiscard(ctx)
 	var cluster = reconciler.observed.cluster
 	var apiBaseURL = getFlinkAPIBaseURL(reconciler.observed.cluster)
+	var triggerMode = getSavepointTriggerMode(cluster.Spec.Job)
 	var triggerSuccess bool
 	var savepointTriggerID *flink.SavepointTriggerID
 	var triggerID string
+	var triggerJob string
 	var message string
 	var err error
 
-	log.Info(fmt.Sprintf("Trigger savepoint for %s", triggerReason), "jobID", jobID)
-	savepointTriggerID, err = reconciler.flinkClient.TriggerSavepoint(apiBaseURL, jobID, *cluster.Spec.Job.SavepointsDir, cancel)
+	log.Info(fmt.Sprintf("Trigger savepoint for %s", triggerReason), "jobID", jobID, "triggerMode", triggerMode)
+	if triggerMode != v1beta1.SavepointTriggerModeJob {
+		switch err = reconciler.injectSavepointFault(ctx); {
+		case err != nil:
+			// The injected failure is handled like a failure of the Flink API.
+		case stopMode == v1beta1.JobUpdateStopModeStopWithSavepoint:
+			savepointTriggerID, err = reconciler.flinkClient.StopJobWithSavepoint(apiBaseURL, jobID, getSavepointsDir(cluster))
+		default:
+			var cancel = stopMode == v1beta1.JobUpdateStopModeCancelWithSavepoint
+			savepointTriggerID, err = reconciler.flinkClient.TriggerSavepoint(apiBaseURL, jobID, getSavepointsDir(cluster), cancel)
+		}
+	}
+	if triggerMode == v1beta1.SavepointTriggerModeJob ||
+		(triggerMode == v1beta1.SavepointTriggerModeAuto && flink.IsConnectionError(err)) {
+		if err != nil {
+			log.Info("Failed to connect to the JobManager, triggering savepoint with a job", "jobID", jobID, "error", err)
+		}
+		triggerJob, err = reconciler.createSavepointTriggerJob(ctx, jobID, stopMode)
+		if err != nil {
+			log.Info("Failed to create savepoint trigger job", "jobID", jobID, "error", err)
+			return reconciler.getNewSavepointStatus("", triggerReason, err.Error(), false), err
+		}
+		newSavepointStatus := reconciler.getNewSavepointStatus("", triggerReason, "", true)
+		newSavepointStatus.TriggerJob = triggerJob
+		return newSavepointStatus, nil
+	}
 	if err != nil {
 		// limit message size to 1KiB
 		if message = err.Error(); len(message) > 1024 {
@@ -808,8 +1680,22 @@ func (reconciler *ClusterReconciler) triggerSavepoint(
 		log.Info("Successfully savepoint triggered", "jobID", jobID, "triggerID", triggerID)
 	}
 	newSavepointStatus := reconciler.getNewSavepointStatus(triggerID, triggerReason, message, triggerSuccess)
+	if triggerSuccess {
+		newSavepointStatus.FlinkVersion = reconciler.getJobManagerFlinkVersion(ctx, apiBaseURL)
+	}
+
+	return newSavepointStatus, newFlinkRESTUnavailableError(err)
+}
 
-	return newSavepointStatus, err
+// Gets the Flink version of the JobManager, empty if the request fails.
+func (reconciler *ClusterReconciler) getJobManagerFlinkVersion(ctx context.Context, apiBaseURL string) string {
+	log := logr.FromContextOrDiscard(ctx)
+	config, err := reconciler.flinkClient.GetConfig(apiBaseURL)
+	if err != nil {
+		log.Info("Failed to get the Flink version of the JobManager", "error", err)
+		return ""
+	}
+	return config.FlinkVersion
 }
 
 // Takes savepoint for a job then update job status with the info.
@@ -818,8 +1704,9 @@ func (reconciler *ClusterReconciler) takeSavepoint(ctx context.Context, jobID st
 	apiBaseURL := getFlinkAPIBaseURL(reconciler.observed.cluster)
 
 	log.Info("Taking savepoint.", "jobID", jobID)
-	status, err := reconciler.flinkClient.TakeSavepoint(apiBaseURL, jobID, *reconciler.observed.cluster.Spec.Job.SavepointsDir)
+	status, err := reconciler.flinkClient.TakeSavepoint(apiBaseURL, jobID, getSavepointsDir(reconciler.observed.cluster))
 	log.Info("Savepoint status.", "status", status, "error", err)
+	err = newFlinkRESTUnavailableError(err)
 
 	if err == nil && len(status.FailureCause.StackTrace) > 0 {
 		err = fmt.Errorf("%s", status.FailureCause.StackTrace)
@@ -897,6 +1784,8 @@ func (reconciler *ClusterReconciler) updateJobDeployStatus(ctx context.Context)
 	newJob.ID = ""
 	newJob.StartTime = ""
 	newJob.CompletionTime = nil
+	newJob.RestoreVerification = nil
+	newJob.EntryClass = ""
 
 	// Mark as job submitter is deployed.
 	util.SetTimestamp(&newJob.DeployTime)
@@ -906,6 +1795,9 @@ func (reconciler *ClusterReconciler) updateJobDeployStatus(ctx context.Context)
 	var fromSavepoint = getFromSavepoint(desiredJobSubmitter.Spec)
 	newJob.FromSavepoint = fromSavepoint
 	if newJob.SavepointLocation != "" {
+		if newJob.SavepointLocation != fromSavepoint {
+			newJob.SavepointFlinkVersion = ""
+		}
 		newJob.SavepointLocation = fromSavepoint
 	}
 
@@ -0,0 +1,153 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reasons of the TaskManagersNotRegistered condition.
+const (
+	registrationReasonRegistered       = "Registered"
+	registrationReasonDeadlineExceeded = "RegistrationDeadlineExceeded"
+)
+
+func hasTaskManagerRegistrationWatchdog(cluster *v1beta1.FlinkCluster) bool {
+	return cluster.Spec.TaskManager != nil && cluster.Spec.TaskManager.RegistrationWatchdog != nil
+}
+
+// getPodReadyTime returns when the pod became ready, zero if it is not ready or is being deleted.
+func getPodReadyTime(pod *corev1.Pod) time.Time {
+	if pod.DeletionTimestamp != nil {
+		return time.Time{}
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+			return cond.LastTransitionTime.Time
+		}
+	}
+	return time.Time{}
+}
+
+// getUnregisteredTaskManagerPods returns the names of the ready TaskManager pods which are not
+// registered to the JobManager past the deadline of spec.taskManager.registrationWatchdog,
+// counted from when both the pod and the JobManager were ready, and true if any ready pod is
+// not registered yet, past the deadline or not. Nothing is returned while the registered
+// TaskManagers are not observed, e.g. while the JobManager is not ready.
+func getUnregisteredTaskManagerPods(observed *ObservedClusterState) ([]string, bool) {
+	if !hasTaskManagerRegistrationWatchdog(observed.cluster) || observed.registeredTaskManagers == nil {
+		return nil, false
+	}
+	var deadline = time.Duration(observed.cluster.Spec.TaskManager.RegistrationWatchdog.DeadlineSeconds) * time.Second
+
+	var jmReadyTime time.Time
+	for i := range observed.jmPods {
+		if readyTime := getPodReadyTime(&observed.jmPods[i]); readyTime.After(jmReadyTime) {
+			jmReadyTime = readyTime
+		}
+	}
+	var registered = map[string]bool{}
+	for _, tm := range observed.registeredTaskManagers.TaskManagers {
+		if name := getTaskManagerPodName(tm, observed.tmPods); name != "" {
+			registered[name] = true
+		}
+	}
+
+	var pods []string
+	var waiting bool
+	for i := range observed.tmPods {
+		var pod = &observed.tmPods[i]
+		var readyTime = getPodReadyTime(pod)
+		if readyTime.IsZero() || registered[pod.Name] {
+			continue
+		}
+		waiting = true
+		if readyTime.Before(jmReadyTime) {
+			readyTime = jmReadyTime
+		}
+		if !observed.observeTime.Before(readyTime.Add(deadline)) {
+			pods = append(pods, pod.Name)
+		}
+	}
+	sort.Strings(pods)
+	return pods, waiting
+}
+
+// setTaskManagerRegistrationCondition tracks spec.taskManager.registrationWatchdog with the
+// TaskManagersNotRegistered condition, true while ready TaskManager pods are not registered
+// past the deadline. The condition is kept while the registration is not observed.
+func setTaskManagerRegistrationCondition(conditions *[]metav1.Condition, observed *ObservedClusterState) {
+	var cluster = observed.cluster
+	if !hasTaskManagerRegistrationWatchdog(cluster) {
+		meta.RemoveStatusCondition(conditions, v1beta1.ClusterConditionTaskManagersNotRegistered)
+		return
+	}
+	if observed.registeredTaskManagers == nil {
+		return
+	}
+
+	var pods, _ = getUnregisteredTaskManagerPods(observed)
+	var condition = metav1.Condition{
+		Type:               v1beta1.ClusterConditionTaskManagersNotRegistered,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: cluster.Generation,
+		LastTransitionTime: metav1.NewTime(observed.observeTime),
+		Reason:             registrationReasonRegistered,
+		Message:            "The ready TaskManager pods are registered to the JobManager.",
+	}
+	if len(pods) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = registrationReasonDeadlineExceeded
+		condition.Message = fmt.Sprintf("TaskManager pods %v did not register to the JobManager within %v seconds of being ready, "+
+			"check jobmanager.rpc.address and the network policies between them.",
+			strings.Join(pods, ", "), cluster.Spec.TaskManager.RegistrationWatchdog.DeadlineSeconds)
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
+
+// Deletes the TaskManager pods which did not register to the JobManager within the deadline
+// of spec.taskManager.registrationWatchdog, if restartPods is enabled, so that their
+// controller recreates them.
+func (reconciler *ClusterReconciler) reconcileTaskManagerRegistration(ctx context.Context) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var observed = &reconciler.observed
+	var cluster = observed.cluster
+	if !hasTaskManagerRegistrationWatchdog(cluster) {
+		return nil
+	}
+	var restartPods = cluster.Spec.TaskManager.RegistrationWatchdog.RestartPods
+	if restartPods == nil || !*restartPods {
+		return nil
+	}
+
+	var unregistered = map[string]bool{}
+	var pods, _ = getUnregisteredTaskManagerPods(observed)
+	for _, name := range pods {
+		unregistered[name] = true
+	}
+	for i := range observed.tmPods {
+		var pod = &observed.tmPods[i]
+		if !unregistered[pod.Name] {
+			continue
+		}
+		log.Info("Restarting TaskManager pod which did not register to the JobManager", "pod", pod.Name)
+		var err = reconciler.k8sClient.Delete(ctx, pod, client.Preconditions{UID: &pod.UID})
+		if client.IgnoreNotFound(err) != nil {
+			log.Error(err, "Failed to delete TaskManager pod", "pod", pod.Name)
+			return err
+		}
+		reconciler.recorder.Eventf(cluster, corev1.EventTypeWarning, "RestartedTaskManager",
+			"Restarted TaskManager pod %v which did not register to the JobManager within %v seconds",
+			pod.Name, cluster.Spec.TaskManager.RegistrationWatchdog.DeadlineSeconds)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package flinkcluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newReadyPod(name, ip string, readyTime time.Time) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name + "-uid")},
+		Status: corev1.PodStatus{
+			PodIP: ip,
+			Conditions: []corev1.PodCondition{{
+				Type:               corev1.PodReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(readyTime),
+			}},
+		},
+	}
+}
+
+func getRegistrationWatchdogObservedState(now time.Time) *ObservedClusterState {
+	return &ObservedClusterState{
+		cluster: &v1beta1.FlinkCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default", Generation: 1},
+			Spec: v1beta1.FlinkClusterSpec{TaskManager: &v1beta1.TaskManagerSpec{
+				RegistrationWatchdog: &v1beta1.TaskManagerRegistrationWatchdog{DeadlineSeconds: 120},
+			}},
+		},
+		jmPods: []corev1.Pod{newReadyPod("cluster-jobmanager-0", "10.12.0.10", now.Add(-10*time.Minute))},
+		tmPods: []corev1.Pod{
+			newReadyPod("cluster-taskmanager-0", "10.12.0.0", now.Add(-5*time.Minute)),
+			newReadyPod("cluster-taskmanager-1", "10.12.0.1", now.Add(-5*time.Minute)),
+			newReadyPod("cluster-taskmanager-2", "10.12.0.2", now.Add(-time.Minute)),
+		},
+		registeredTaskManagers: &flink.TaskManagersOverview{TaskManagers: []flink.TaskManager{
+			{ID: "10.12.0.0:6122-8bd5e1", Path: "akka.tcp://flink@10.12.0.0:6122/user/rpc/taskmanager_0"},
+		}},
+		observeTime: now,
+	}
+}
+
+func TestGetUnregisteredTaskManagerPods(t *testing.T) {
+	var now = time.Now().Truncate(time.Second)
+	var observed = getRegistrationWatchdogObservedState(now)
+
+	// taskmanager-2 is not registered yet, within the deadline.
+	var pods, waiting = getUnregisteredTaskManagerPods(observed)
+	assert.DeepEqual(t, pods, []string{"cluster-taskmanager-1"})
+	assert.Assert(t, waiting)
+
+	// The deadline starts when the JobManager is ready too.
+	observed.jmPods[0] = newReadyPod("cluster-jobmanager-0", "10.12.0.10", now.Add(-time.Minute))
+	pods, waiting = getUnregisteredTaskManagerPods(observed)
+	assert.Equal(t, len(pods), 0)
+	assert.Assert(t, waiting)
+
+	observed.registeredTaskManagers.TaskManagers = append(observed.registeredTaskManagers.TaskManagers,
+		flink.TaskManager{ID: "10.12.0.1:6122-6c2d1a", Path: "akka.tcp://flink@10.12.0.1:6122/user/rpc/taskmanager_0"},
+		flink.TaskManager{ID: "10.12.0.2:6122-1f4e7b", Path: "akka.tcp://flink@10.12.0.2:6122/user/rpc/taskmanager_0"})
+	pods, waiting = getUnregisteredTaskManagerPods(observed)
+	assert.Equal(t, len(pods), 0)
+	assert.Assert(t, !waiting)
+
+	// The registration is not observed.
+	observed.registeredTaskManagers = nil
+	pods, waiting = getUnregisteredTaskManagerPods(observed)
+	assert.Equal(t, len(pods), 0)
+	assert.Assert(t, !waiting)
+}
+
+func TestSetTaskManagerRegistrationCondition(t *testing.T) {
+	var now = time.Now().Truncate(time.Second)
+	var observed = getRegistrationWatchdogObservedState(now)
+	var conditions []metav1.Condition
+
+	setTaskManagerRegistrationCondition(&conditions, observed)
+	var condition = meta.FindStatusCondition(conditions, v1beta1.ClusterConditionTaskManagersNotRegistered)
+	assert.Equal(t, condition.Status, metav1.ConditionTrue)
+	assert.Equal(t, condition.Reason, "RegistrationDeadlineExceeded")
+	assert.Equal(t, condition.Message, "TaskManager pods cluster-taskmanager-1 did not register to the JobManager within 120 seconds "+
+		"of being ready, check jobmanager.rpc.address and the network policies between them.")
+
+	// The condition is kept while the registration is not observed.
+	var registered = observed.registeredTaskManagers
+	observed.registeredTaskManagers = nil
+	setTaskManagerRegistrationCondition(&conditions, observed)
+	assert.Equal(t, meta.FindStatusCondition(conditions, v1beta1.ClusterConditionTaskManagersNotRegistered).Status, metav1.ConditionTrue)
+
+	observed.registeredTaskManagers = registered
+	observed.tmPods = observed.tmPods[:1]
+	setTaskManagerRegistrationCondition(&conditions, observed)
+	assert.Equal(t, meta.FindStatusCondition(conditions, v1beta1.ClusterConditionTaskManagersNotRegistered).Status, metav1.ConditionFalse)
+
+	observed.cluster.Spec.TaskManager.RegistrationWatchdog = nil
+	setTaskManagerRegistrationCondition(&conditions, observed)
+	assert.Assert(t, meta.FindStatusCondition(conditions, v1beta1.ClusterConditionTaskManagersNotRegistered) == nil)
+}
+
+func TestReconcileTaskManagerRegistration(t *testing.T) {
+	var now = time.Now().Truncate(time.Second)
+	var observed = getRegistrationWatchdogObservedState(now)
+	var scheme = runtime.NewScheme()
+	assert.NilError(t, corev1.AddToScheme(scheme))
+	var k8sClient = fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(&observed.tmPods[0], &observed.tmPods[1], &observed.tmPods[2]).Build()
+	var recorder = record.NewFakeRecorder(1)
+	var reconciler = ClusterReconciler{k8sClient: k8sClient, recorder: recorder, observed: *observed}
+
+	// The pods are not restarted by default.
+	assert.NilError(t, reconciler.reconcileTaskManagerRegistration(context.TODO()))
+	var pods = new(corev1.PodList)
+	assert.NilError(t, k8sClient.List(context.TODO(), pods))
+	assert.Equal(t, len(pods.Items), 3)
+
+	var restartPods = true
+	observed.cluster.Spec.TaskManager.RegistrationWatchdog.RestartPods = &restartPods
+	assert.NilError(t, reconciler.reconcileTaskManagerRegistration(context.TODO()))
+	assert.Equal(t, <-recorder.Events, "Warning RestartedTaskManager Restarted TaskManager pod cluster-taskmanager-1 "+
+		"which did not register to the JobManager within 120 seconds")
+	var err = k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(&observed.tmPods[1]), new(corev1.Pod))
+	assert.Assert(t, errors.IsNotFound(err))
+	assert.NilError(t, k8sClient.List(context.TODO(), pods))
+	assert.Equal(t, len(pods.Items), 2)
+}
@@ -0,0 +1,145 @@
+package flinkcluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/controllers/history"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getPreviousRevision returns the revision preceding the named revision in the history,
+// nil if there is none, e.g. as it was truncated by the revision history limit.
+func getPreviousRevision(revisions []*appsv1.ControllerRevision, name string) *appsv1.ControllerRevision {
+	history.SortControllerRevisions(revisions)
+	for i := 1; i < len(revisions); i++ {
+		if util.GetRevisionWithNameNumber(revisions[i]) == name {
+			return revisions[i-1]
+		}
+	}
+	return nil
+}
+
+// deriveUpdateFailure records the failure of the update to the current revision once its
+// job fails the restore verification. The failure of the update which rolled back a
+// previous failure is marked as such, so that it is not rolled back again.
+func deriveUpdateFailure(
+	observed *ObservedClusterState,
+	revision *v1beta1.RevisionStatus,
+	recorded *v1beta1.UpdateFailureStatus,
+	job *v1beta1.JobStatus,
+	now time.Time) *v1beta1.UpdateFailureStatus {
+	if !isRestoreVerificationFailed(job) || (recorded != nil && recorded.Revision == revision.CurrentRevision) {
+		return recorded.DeepCopy()
+	}
+
+	var rollback = false
+	if recorded != nil && recorded.RollbackRevision != "" {
+		var previous = getPreviousRevision(observed.revisions, revision.CurrentRevision)
+		rollback = previous != nil && util.GetRevisionWithNameNumber(previous) == recorded.Revision
+	}
+	var tc = &util.TimeConverter{}
+	return &v1beta1.UpdateFailureStatus{
+		Revision: revision.CurrentRevision,
+		Reason: fmt.Sprintf("The job restored from savepoint %v failed the verification: %v.",
+			job.RestoreVerification.Savepoint, job.RestoreVerification.Message),
+		Time:     tc.ToString(now),
+		Rollback: rollback,
+	}
+}
+
+// shouldRollbackUpdate returns true if the spec is to be rolled back to the previous
+// revision by `restoreVerification.onFailure`: the job of the current revision failed the
+// verification and is stopped, and the failed update is neither rolled back yet nor a
+// rollback itself.
+func shouldRollbackUpdate(observed *ObservedClusterState) bool {
+	var cluster = observed.cluster
+	var jobSpec = cluster.Spec.Job
+	var status = &cluster.Status
+	var job = status.Components.Job
+	var failure = status.Revision.UpdateFailure
+	return jobSpec != nil && jobSpec.RestoreVerification != nil &&
+		jobSpec.RestoreVerification.OnFailure == v1beta1.RestoreVerificationFailureActionRollback &&
+		isRestoreVerificationFailed(job) && job.IsStopped() &&
+		!status.Revision.IsUpdateTriggered() &&
+		failure != nil && failure.Revision == status.Revision.CurrentRevision &&
+		failure.RollbackRevision == "" && !failure.Rollback
+}
+
+// getRollbackPatch returns the merge patch of the cluster which reverts its spec from the
+// failed revision to the previous revision, and restores the job from the savepoint. The
+// fields which are not recorded in the revisions are left as they are.
+func getRollbackPatch(failed, previous *appsv1.ControllerRevision, savepoint string) ([]byte, error) {
+	var spec map[string]any
+	if err := json.Unmarshal([]byte(revisionPatch(failed, previous)), &spec); err != nil {
+		return nil, err
+	}
+	if spec == nil {
+		spec = map[string]any{}
+	}
+	// The hash of the referenced ConfigMaps and Secrets is not a field of the spec.
+	delete(spec, render.ReferencedConfigHashKey)
+	var job, _ = spec["job"].(map[string]any)
+	if job == nil {
+		job = map[string]any{}
+		spec["job"] = job
+	}
+	job["fromSavepoint"] = savepoint
+	return json.Marshal(map[string]any{"spec": spec})
+}
+
+// Rolls the spec back to the previous revision as the job of the current revision failed
+// the restore verification, then records the revision rolled back to. The job of the
+// previous revision is restored from the savepoint the failed job was started from, which
+// was the last savepoint of the previous job.
+func (reconciler *ClusterReconciler) rollbackUpdate(ctx context.Context) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var observed = &reconciler.observed
+	var cluster = observed.cluster
+	var failure = cluster.Status.Revision.UpdateFailure
+	var savepoint = cluster.Status.Components.Job.RestoreVerification.Savepoint
+
+	var failed = observed.revision.currentRevision
+	var previous = getPreviousRevision(observed.revisions, cluster.Status.Revision.CurrentRevision)
+	if failed == nil || previous == nil {
+		log.Info("No previous revision to roll back to", "revision", failure.Revision)
+		return nil
+	}
+	patch, err := getRollbackPatch(failed, previous, savepoint)
+	if err != nil {
+		return err
+	}
+	if err := reconciler.k8sClient.Patch(ctx, cluster.DeepCopy(), client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return err
+	}
+
+	var rollbackRevision = util.GetRevisionWithNameNumber(previous)
+	var message = fmt.Sprintf("Rolled back the spec from revision %v to revision %v as its job failed the restore verification, "+
+		"restoring the job from savepoint %v", failure.Revision, rollbackRevision, savepoint)
+	log.Info("Rolled back the update", "revision", failure.Revision, "rollbackRevision", rollbackRevision, "savepoint", savepoint)
+	reconciler.recorder.Event(cluster, corev1.EventTypeWarning, "UpdateRolledBack", message)
+	reconciler.audit(ctx, newAuditRecord(&cluster.Status, time.Now(), auditDecisionRollback, message))
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		var latest v1beta1.FlinkCluster
+		if err := reconciler.k8sClient.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}, &latest); err != nil {
+			return err
+		}
+		var latestFailure = latest.Status.Revision.UpdateFailure
+		if latestFailure == nil || latestFailure.Revision != failure.Revision {
+			return nil
+		}
+		latestFailure.RollbackRevision = rollbackRevision
+		return reconciler.k8sClient.Status().Update(ctx, &latest)
+	})
+}
@@ -0,0 +1,176 @@
+package flinkcluster
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/model"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// getRollbackObservedState returns a cluster whose job of the current revision 2, updated
+// from revision 1, failed the restore verification of savepoint-1 and is stopped.
+func getRollbackObservedState(t *testing.T) *ObservedClusterState {
+	var jarFile = "gs://my-bucket/jobs/clicks-1.jar"
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "clicks", Namespace: "default"},
+		Spec: v1beta1.FlinkClusterSpec{
+			Image: v1beta1.ImageSpec{Name: "flink:1.14.4"},
+			Job: &v1beta1.JobSpec{
+				JarFile: &jarFile,
+				RestoreVerification: &v1beta1.RestoreVerification{
+					TimeoutSeconds: 600,
+					OnFailure:      v1beta1.RestoreVerificationFailureActionRollback,
+				},
+			},
+		},
+	}
+	previous, err := render.NewRevision(cluster, "", 1, nil)
+	assert.NilError(t, err)
+
+	jarFile = "gs://my-bucket/jobs/clicks-2.jar"
+	cluster.Spec.Image.Name = "flink:1.15.2"
+	failed, err := render.NewRevision(cluster, "", 2, nil)
+	assert.NilError(t, err)
+
+	var failedRevision = util.GetRevisionWithNameNumber(failed)
+	cluster.Status = v1beta1.FlinkClusterStatus{
+		State: v1beta1.ClusterStateRunning,
+		Revision: v1beta1.RevisionStatus{
+			CurrentRevision: failedRevision,
+			NextRevision:    failedRevision,
+			UpdateFailure: &v1beta1.UpdateFailureStatus{
+				Revision: failedRevision,
+				Reason:   "The job restored from savepoint gs://my-bucket/savepoints/savepoint-1 failed the verification: no checkpoint completed within 600s.",
+				Time:     "2022-05-01T12:10:00Z",
+			},
+		},
+		Components: v1beta1.FlinkClusterComponentsStatus{
+			Job: &v1beta1.JobStatus{
+				ID:                "8f5a5b7e0a7f4c1bd3c6e5b4a3928170",
+				State:             v1beta1.JobStateFailed,
+				FromSavepoint:     "gs://my-bucket/savepoints/savepoint-1",
+				SavepointLocation: "gs://my-bucket/savepoints/savepoint-1",
+				RestoreVerification: &v1beta1.RestoreVerificationStatus{
+					Savepoint: "gs://my-bucket/savepoints/savepoint-1",
+					State:     v1beta1.RestoreVerificationStateFailed,
+					Message:   "no checkpoint completed within 600s",
+				},
+			},
+		},
+	}
+	return &ObservedClusterState{
+		cluster:   cluster,
+		revisions: []*appsv1.ControllerRevision{failed, previous},
+		revision:  Revision{currentRevision: failed, nextRevision: failed},
+	}
+}
+
+func TestDeriveUpdateFailure(t *testing.T) {
+	var now = time.Date(2022, 5, 1, 12, 10, 0, 0, time.UTC)
+	var observed = getRollbackObservedState(t)
+	var status = &observed.cluster.Status
+	var job = status.Components.Job
+
+	// The failure of the update to the current revision is recorded once.
+	var failure = deriveUpdateFailure(observed, &status.Revision, nil, job, now)
+	assert.DeepEqual(t, failure, status.Revision.UpdateFailure)
+	failure.RollbackRevision = util.GetRevisionWithNameNumber(observed.revisions[1])
+	assert.DeepEqual(t, deriveUpdateFailure(observed, &status.Revision, failure, job, now.Add(time.Minute)), failure)
+
+	// The update which rolled back the failed update is not rolled back again if it fails.
+	rollback, err := render.NewRevision(observed.cluster, "", 3, nil)
+	assert.NilError(t, err)
+	observed.revisions = append(observed.revisions, rollback)
+	var revision = v1beta1.RevisionStatus{
+		CurrentRevision: util.GetRevisionWithNameNumber(rollback),
+		NextRevision:    util.GetRevisionWithNameNumber(rollback),
+	}
+	var rollbackFailure = deriveUpdateFailure(observed, &revision, failure, job, now.Add(time.Hour))
+	assert.Equal(t, rollbackFailure.Revision, revision.CurrentRevision)
+	assert.Assert(t, rollbackFailure.Rollback)
+
+	// The failure is kept while no job fails the verification.
+	job.RestoreVerification.State = v1beta1.RestoreVerificationStateSucceeded
+	assert.DeepEqual(t, deriveUpdateFailure(observed, &revision, failure, job, now.Add(time.Hour)), failure)
+	assert.Assert(t, deriveUpdateFailure(observed, &revision, nil, job, now) == nil)
+}
+
+func TestGetRollbackPatch(t *testing.T) {
+	var observed = getRollbackObservedState(t)
+	var failed, previous = observed.revisions[0], observed.revisions[1]
+
+	patch, err := getRollbackPatch(failed, previous, "gs://my-bucket/savepoints/savepoint-1")
+	assert.NilError(t, err)
+	var decoded map[string]any
+	assert.NilError(t, json.Unmarshal(patch, &decoded))
+	assert.DeepEqual(t, decoded, map[string]any{"spec": map[string]any{
+		"image": map[string]any{"name": "flink:1.14.4"},
+		"job": map[string]any{
+			"jarFile":       "gs://my-bucket/jobs/clicks-1.jar",
+			"fromSavepoint": "gs://my-bucket/savepoints/savepoint-1",
+		},
+	}})
+
+	// The hash of the referenced configs is not patched into the spec.
+	failed, err = render.NewRevision(observed.cluster, "3f2a", 2, nil)
+	assert.NilError(t, err)
+	patch, err = getRollbackPatch(failed, previous, "gs://my-bucket/savepoints/savepoint-1")
+	assert.NilError(t, err)
+	assert.NilError(t, json.Unmarshal(patch, &decoded))
+	_, found := decoded["spec"].(map[string]any)[render.ReferencedConfigHashKey]
+	assert.Assert(t, !found)
+}
+
+func TestReconcileRollbackOnRestoreVerificationFailure(t *testing.T) {
+	var observed = getRollbackObservedState(t)
+	var cluster = observed.cluster
+	var previousRevision = util.GetRevisionWithNameNumber(observed.revisions[1])
+	var scheme = runtime.NewScheme()
+	assert.NilError(t, corev1.AddToScheme(scheme))
+	assert.NilError(t, v1beta1.AddToScheme(scheme))
+	var k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster.DeepCopy()).Build()
+	var recorder = record.NewFakeRecorder(1)
+	var reconciler = ClusterReconciler{
+		k8sClient: k8sClient,
+		recorder:  recorder,
+		observed:  *observed,
+		desired:   model.DesiredClusterState{},
+	}
+
+	// The job is not rolled back by default.
+	cluster.Spec.Job.RestoreVerification.OnFailure = ""
+	assert.Assert(t, !shouldRollbackUpdate(observed))
+	cluster.Spec.Job.RestoreVerification.OnFailure = v1beta1.RestoreVerificationFailureActionRollback
+	assert.Assert(t, shouldRollbackUpdate(observed))
+
+	// The spec is rolled back to the previous revision, restored from the savepoint of the failed job.
+	_, err := reconciler.reconcileJob(context.TODO())
+	assert.NilError(t, err)
+	var rolledBack v1beta1.FlinkCluster
+	assert.NilError(t, k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cluster), &rolledBack))
+	assert.Equal(t, rolledBack.Spec.Image.Name, "flink:1.14.4")
+	assert.Equal(t, *rolledBack.Spec.Job.JarFile, "gs://my-bucket/jobs/clicks-1.jar")
+	assert.Equal(t, *rolledBack.Spec.Job.FromSavepoint, "gs://my-bucket/savepoints/savepoint-1")
+	assert.DeepEqual(t, rolledBack.Spec.Job.RestoreVerification, cluster.Spec.Job.RestoreVerification)
+	assert.Equal(t, rolledBack.Status.Revision.UpdateFailure.RollbackRevision, previousRevision)
+	assert.Equal(t, <-recorder.Events, "Warning UpdateRolledBack Rolled back the spec from revision "+
+		cluster.Status.Revision.CurrentRevision+" to revision "+previousRevision+
+		" as its job failed the restore verification, restoring the job from savepoint gs://my-bucket/savepoints/savepoint-1")
+
+	// The failed update is rolled back once.
+	observed.cluster = &rolledBack
+	assert.Assert(t, !shouldRollbackUpdate(observed))
+}
@@ -0,0 +1,203 @@
+package flinkcluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/objectstore"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	savepointOwnershipFileSuffix = ".owner.json"
+	savepointOwnershipTimeout    = 30 * time.Second
+)
+
+// Client of the object storage to write and read the ownership metadata of
+// spec.job.savepointOwnership, with the credentials of the operator.
+var savepointOwnershipStore = objectstore.NewClient(savepointOwnershipTimeout)
+
+// savepointOwnership is the metadata written next to a savepoint of a job.
+type savepointOwnership struct {
+	Namespace  string            `json:"namespace"`
+	Cluster    string            `json:"cluster"`
+	Revision   string            `json:"revision"`
+	JobID      string            `json:"jobID"`
+	EntryClass string            `json:"entryClass,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Time       string            `json:"time"`
+}
+
+// getSavepointOwnershipLocation returns the location of the ownership metadata of the
+// savepoint, `<savepoint>.owner.json`.
+func getSavepointOwnershipLocation(savepoint string) (string, error) {
+	if !objectstore.IsSupported(savepoint) {
+		return "", fmt.Errorf("unsupported savepoint location %v", savepoint)
+	}
+	return strings.TrimSuffix(savepoint, "/") + savepointOwnershipFileSuffix, nil
+}
+
+// newSavepointOwnership returns the ownership metadata of the latest savepoint of the job.
+func newSavepointOwnership(cluster *v1beta1.FlinkCluster, now time.Time) *savepointOwnership {
+	var job = cluster.Status.Components.Job
+	return &savepointOwnership{
+		Namespace:  cluster.Namespace,
+		Cluster:    cluster.Name,
+		Revision:   getCurrentRevisionName(&cluster.Status.Revision),
+		JobID:      job.ID,
+		EntryClass: job.EntryClass,
+		Labels:     cluster.Spec.Job.SavepointOwnership.Labels,
+		Time:       now.UTC().Format(time.RFC3339),
+	}
+}
+
+// shouldRecordSavepointOwnership returns true if spec.job.savepointOwnership is set and the
+// latest savepoint of the job has no metadata yet. The savepoints given by the spec are not
+// taken of the job, their metadata is not written.
+func shouldRecordSavepointOwnership(jobSpec *v1beta1.JobSpec, job *v1beta1.JobStatus) bool {
+	if jobSpec == nil || jobSpec.SavepointOwnership == nil || job == nil || job.SavepointLocation == "" {
+		return false
+	}
+	var location = job.SavepointLocation
+	if (jobSpec.FromSavepoint != nil && *jobSpec.FromSavepoint == location) ||
+		jobSpec.SavepointOwnership.AllowedSavepoint == location {
+		return false
+	}
+	return job.SavepointOwnership == nil || job.SavepointOwnership.RecordedSavepoint != location
+}
+
+// shouldVerifySavepointOwnership returns true if the restores of spec.job.savepointOwnership
+// are verified and the job is about to be submitted.
+func shouldVerifySavepointOwnership(cluster *v1beta1.FlinkCluster) bool {
+	var jobSpec = cluster.Spec.Job
+	var job = cluster.Status.Components.Job
+	if jobSpec == nil || jobSpec.SavepointOwnership == nil {
+		return false
+	}
+	var verifyRestore = jobSpec.SavepointOwnership.VerifyRestore
+	return (verifyRestore == nil || *verifyRestore) && !job.IsActive() && !job.IsTerminated(jobSpec)
+}
+
+// checkSavepointOwnership returns an error if the savepoint of the metadata is not compatible
+// with the job: the labels differ, or the entry classes differ when either has no labels.
+func checkSavepointOwnership(ownership *v1beta1.SavepointOwnership, entryClass string, owner *savepointOwnership) error {
+	if len(ownership.Labels) > 0 && len(owner.Labels) > 0 {
+		if !labels.Equals(ownership.Labels, owner.Labels) {
+			return fmt.Errorf("it was taken of job %v of cluster %v/%v with labels %v, not %v", owner.JobID,
+				owner.Namespace, owner.Cluster, labels.Set(owner.Labels), labels.Set(ownership.Labels))
+		}
+		return nil
+	}
+	if entryClass != "" && owner.EntryClass != "" && entryClass != owner.EntryClass {
+		return fmt.Errorf("it was taken of job %v of cluster %v/%v with entry class %v, not %v", owner.JobID,
+			owner.Namespace, owner.Cluster, owner.EntryClass, entryClass)
+	}
+	return nil
+}
+
+// readSavepointOwnership reads the ownership metadata, nil if it does not exist.
+func readSavepointOwnership(ctx context.Context, location, authorization string) (*savepointOwnership, error) {
+	body, err := savepointOwnershipStore.Get(ctx, location, authorization)
+	if errors.Is(err, objectstore.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %v: %v", location, err)
+	}
+	defer body.Close()
+	var owner savepointOwnership
+	if err := json.NewDecoder(body).Decode(&owner); err != nil {
+		return nil, fmt.Errorf("invalid ownership metadata %v: %v", location, err)
+	}
+	return &owner, nil
+}
+
+// verifySavepointOwnership returns an error if the savepoint is not compatible with the job,
+// has no ownership metadata or its ownership metadata cannot be read.
+func verifySavepointOwnership(ctx context.Context, k8sClient client.Client, cluster *v1beta1.FlinkCluster, savepoint string) error {
+	var jobSpec = cluster.Spec.Job
+	var location, err = getSavepointOwnershipLocation(savepoint)
+	if err != nil {
+		return err
+	}
+	authorization, err := getAuthorization(ctx, k8sClient, cluster.Namespace, jobSpec.SavepointOwnership.AuthorizationSecret)
+	if err != nil {
+		return err
+	}
+	owner, err := readSavepointOwnership(ctx, location, authorization)
+	if err != nil {
+		return err
+	}
+	if owner == nil {
+		return fmt.Errorf("it has no ownership metadata, set spec.job.savepointOwnership.allowedSavepoint to restore it anyway")
+	}
+	var entryClass string
+	if jobSpec.ClassName != nil {
+		entryClass = *jobSpec.ClassName
+	} else if job := cluster.Status.Components.Job; job != nil {
+		entryClass = job.EntryClass
+	}
+	return checkSavepointOwnership(jobSpec.SavepointOwnership, entryClass, owner)
+}
+
+// Writes the ownership metadata of the latest savepoint of the job for
+// spec.job.savepointOwnership. A failure is retried in the next reconciliation.
+func (reconciler *ClusterReconciler) reconcileSavepointOwnership(ctx context.Context) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = reconciler.observed.cluster
+	var job = cluster.Status.Components.Job
+	if !shouldRecordSavepointOwnership(cluster.Spec.Job, job) {
+		return nil
+	}
+
+	var savepoint = job.SavepointLocation
+	var err = reconciler.writeSavepointOwnership(ctx, savepoint, newSavepointOwnership(cluster, time.Now()))
+	if err != nil {
+		log.Info("Failed to write savepoint ownership, will retry", "savepoint", savepoint, "error", err)
+		reconciler.recorder.Eventf(cluster, corev1.EventTypeWarning, "SavepointOwnershipFailed",
+			"Failed to write the ownership metadata of savepoint %v: %v", savepoint, err)
+		return nil
+	}
+
+	var clusterClone = cluster.DeepCopy()
+	var newJob = clusterClone.Status.Components.Job
+	if newJob.SavepointOwnership == nil {
+		newJob.SavepointOwnership = &v1beta1.SavepointOwnershipStatus{}
+	}
+	newJob.SavepointOwnership.RecordedSavepoint = savepoint
+	util.SetTimestamp(&clusterClone.Status.LastUpdateTime)
+	err = reconciler.k8sClient.Status().Update(ctx, clusterClone)
+	if err != nil {
+		log.Error(err, "Failed to update savepoint ownership status", "error", err)
+	} else {
+		log.Info("Succeeded to update savepoint ownership status.", "savepoint", savepoint)
+	}
+	return err
+}
+
+func (reconciler *ClusterReconciler) writeSavepointOwnership(ctx context.Context, savepoint string, owner *savepointOwnership) error {
+	var cluster = reconciler.observed.cluster
+	location, err := getSavepointOwnershipLocation(savepoint)
+	if err != nil {
+		return err
+	}
+	authorization, err := getAuthorization(ctx, reconciler.k8sClient, cluster.Namespace,
+		cluster.Spec.Job.SavepointOwnership.AuthorizationSecret)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(owner)
+	if err != nil {
+		return err
+	}
+	return savepointOwnershipStore.Put(ctx, location, authorization, data)
+}
@@ -0,0 +1,118 @@
+package flinkcluster
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetSavepointOwnershipLocation(t *testing.T) {
+	var location, err = getSavepointOwnershipLocation("gs://my-bucket/savepoints/savepoint-8f5a5b-2c1d3e4f5a6b/")
+	assert.NilError(t, err)
+	assert.Equal(t, location, "gs://my-bucket/savepoints/savepoint-8f5a5b-2c1d3e4f5a6b.owner.json")
+
+	location, err = getSavepointOwnershipLocation("s3a://my-bucket/savepoints/savepoint-8f5a5b-2c1d3e4f5a6b")
+	assert.NilError(t, err)
+	assert.Equal(t, location, "s3a://my-bucket/savepoints/savepoint-8f5a5b-2c1d3e4f5a6b.owner.json")
+
+	_, err = getSavepointOwnershipLocation("hdfs://namenode/savepoints/savepoint-8f5a5b-2c1d3e4f5a6b")
+	assert.Error(t, err, "unsupported savepoint location hdfs://namenode/savepoints/savepoint-8f5a5b-2c1d3e4f5a6b")
+}
+
+func TestShouldRecordSavepointOwnership(t *testing.T) {
+	var fromSavepoint = "gs://my-bucket/other/savepoint-1"
+	var jobSpec = &v1beta1.JobSpec{
+		FromSavepoint:      &fromSavepoint,
+		SavepointOwnership: &v1beta1.SavepointOwnership{AllowedSavepoint: "gs://my-bucket/other/savepoint-2"},
+	}
+	var job = &v1beta1.JobStatus{SavepointLocation: "gs://my-bucket/savepoints/savepoint-3"}
+	assert.Assert(t, shouldRecordSavepointOwnership(jobSpec, job))
+	assert.Assert(t, !shouldRecordSavepointOwnership(&v1beta1.JobSpec{}, job))
+
+	job.SavepointOwnership = &v1beta1.SavepointOwnershipStatus{RecordedSavepoint: job.SavepointLocation}
+	assert.Assert(t, !shouldRecordSavepointOwnership(jobSpec, job))
+
+	// The savepoints of other jobs given by the spec are not claimed.
+	job.SavepointLocation = fromSavepoint
+	assert.Assert(t, !shouldRecordSavepointOwnership(jobSpec, job))
+	job.SavepointLocation = jobSpec.SavepointOwnership.AllowedSavepoint
+	assert.Assert(t, !shouldRecordSavepointOwnership(jobSpec, job))
+}
+
+func TestCheckSavepointOwnership(t *testing.T) {
+	var owner = &savepointOwnership{
+		Namespace:  "default",
+		Cluster:    "clicks",
+		JobID:      "8f5a5b7e0a7f4c1bd3c6e5b4a3928170",
+		EntryClass: "com.example.Clicks",
+		Labels:     map[string]string{"pipeline": "clicks"},
+	}
+	var ownership = &v1beta1.SavepointOwnership{Labels: map[string]string{"pipeline": "clicks"}}
+	assert.NilError(t, checkSavepointOwnership(ownership, "com.example.ClicksV2", owner))
+
+	ownership.Labels = map[string]string{"pipeline": "orders"}
+	assert.Error(t, checkSavepointOwnership(ownership, "com.example.Clicks", owner),
+		"it was taken of job 8f5a5b7e0a7f4c1bd3c6e5b4a3928170 of cluster default/clicks with labels pipeline=clicks, not pipeline=orders")
+
+	// Without labels on either side, the entry classes are compared.
+	ownership.Labels = nil
+	assert.NilError(t, checkSavepointOwnership(ownership, "com.example.Clicks", owner))
+	assert.NilError(t, checkSavepointOwnership(ownership, "", owner))
+	assert.Error(t, checkSavepointOwnership(ownership, "com.example.Orders", owner),
+		"it was taken of job 8f5a5b7e0a7f4c1bd3c6e5b4a3928170 of cluster default/clicks with entry class com.example.Clicks, not com.example.Orders")
+}
+
+func TestVerifySavepointOwnership(t *testing.T) {
+	var owner = savepointOwnership{Namespace: "default", Cluster: "clicks", JobID: "1234", EntryClass: "com.example.Clicks"}
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Authorization") != "Bearer token":
+			w.WriteHeader(http.StatusForbidden)
+		case r.URL.Path == "/savepoints/savepoint-1.owner.json":
+			json.NewEncoder(w).Encode(owner)
+		case r.URL.Path == "/savepoints/savepoint-2.owner.json":
+			w.Write([]byte("{"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var className = "com.example.Orders"
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"},
+		Spec: v1beta1.FlinkClusterSpec{Job: &v1beta1.JobSpec{
+			ClassName: &className,
+			SavepointOwnership: &v1beta1.SavepointOwnership{AuthorizationSecret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "savepoint-ownership"},
+				Key:                  "authorization",
+			}},
+		}},
+	}
+	var secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "savepoint-ownership", Namespace: "default"},
+		Data:       map[string][]byte{"authorization": []byte("Bearer token\n")},
+	}
+	var scheme = runtime.NewScheme()
+	assert.NilError(t, corev1.AddToScheme(scheme))
+	var k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	var err = verifySavepointOwnership(context.TODO(), k8sClient, cluster, server.URL+"/savepoints/savepoint-1")
+	assert.Error(t, err, "it was taken of job 1234 of cluster default/clicks with entry class com.example.Clicks, not com.example.Orders")
+
+	// Savepoints without metadata are not restored unless they are allowed.
+	err = verifySavepointOwnership(context.TODO(), k8sClient, cluster, server.URL+"/savepoints/savepoint-0")
+	assert.Error(t, err, "it has no ownership metadata, set spec.job.savepointOwnership.allowedSavepoint to restore it anyway")
+
+	err = verifySavepointOwnership(context.TODO(), k8sClient, cluster, server.URL+"/savepoints/savepoint-2")
+	assert.ErrorContains(t, err, "invalid ownership metadata")
+}
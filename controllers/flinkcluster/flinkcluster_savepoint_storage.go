@@ -0,0 +1,126 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// SavepointStorageRule selects the savepoint storage of the jobs whose JobManager runs on a
+// node of the region and the zone. Empty region or zone match any.
+type SavepointStorageRule struct {
+	Region string `json:"region,omitempty"`
+	Zone   string `json:"zone,omitempty"`
+	// The bucket and prefix the savepoints are written under, in
+	// `<savepointsDir>/<namespace>/<cluster name>`.
+	SavepointsDir string `json:"savepointsDir"`
+}
+
+// SavepointStorageConfig is the config of the savepoint storage of the jobs by the topology of
+// the node of their JobManager, e.g. so that multi-region installations write the savepoints
+// to the storage of their region. The first matching rule applies.
+type SavepointStorageConfig struct {
+	Rules []SavepointStorageRule `json:"rules"`
+}
+
+// LoadSavepointStorageConfig reads the savepoint storage config from a YAML or JSON file.
+func LoadSavepointStorageConfig(path string) (*SavepointStorageConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config = new(SavepointStorageConfig)
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("invalid savepoint storage config %v: %v", path, err)
+	}
+	for i, rule := range config.Rules {
+		switch {
+		case rule.SavepointsDir == "":
+			return nil, fmt.Errorf("invalid savepoint storage config %v: rules[%d].savepointsDir is unspecified", path, i)
+		case rule.Region == "" && rule.Zone == "":
+			return nil, fmt.Errorf("invalid savepoint storage config %v: rules[%d] must set region or zone", path, i)
+		}
+	}
+	return config, nil
+}
+
+// getSavepointsDir returns where the savepoints of the job are written to, the savepoints dir
+// selected for the topology of the JobManager node if any, otherwise spec.job.savepointsDir.
+func getSavepointsDir(cluster *v1beta1.FlinkCluster) string {
+	if job := cluster.Status.Components.Job; job != nil && job.SavepointsDir != "" {
+		return job.SavepointsDir
+	}
+	if cluster.Spec.Job == nil || cluster.Spec.Job.SavepointsDir == nil {
+		return ""
+	}
+	return *cluster.Spec.Job.SavepointsDir
+}
+
+// selectSavepointStorage returns the first rule matching the topology labels of the node,
+// nil if none does.
+func selectSavepointStorage(rules []SavepointStorageRule, nodeLabels map[string]string) *SavepointStorageRule {
+	var region, zone = nodeLabels[corev1.LabelTopologyRegion], nodeLabels[corev1.LabelTopologyZone]
+	for i := range rules {
+		var rule = &rules[i]
+		if (rule.Region == "" || rule.Region == region) && (rule.Zone == "" || rule.Zone == zone) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// deriveSavepointsDir returns the savepoints dir selected by the rules for the labels of the
+// JobManager node, which is empty if the job does not take savepoints or no rule matches.
+// The recorded dir is kept while the JobManager node is not known.
+func deriveSavepointsDir(
+	rules []SavepointStorageRule,
+	cluster *v1beta1.FlinkCluster,
+	recorded string,
+	jmNodeLabels map[string]string) string {
+	var jobSpec = cluster.Spec.Job
+	if len(rules) == 0 || jobSpec == nil || jobSpec.SavepointsDir == nil {
+		return ""
+	}
+	if jmNodeLabels == nil {
+		return recorded
+	}
+	var rule = selectSavepointStorage(rules, jmNodeLabels)
+	if rule == nil {
+		return ""
+	}
+	return strings.TrimSuffix(rule.SavepointsDir, "/") + "/" + cluster.Namespace + "/" + cluster.Name
+}
+
+// Observes the labels of the node of the running JobManager pod, if the job takes savepoints
+// and the operator selects their storage by topology.
+func (observer *ClusterStateObserver) observeJobManagerNode(ctx context.Context, observed *ObservedClusterState) {
+	var log = logr.FromContextOrDiscard(ctx)
+	var jobSpec = observed.cluster.Spec.Job
+	observed.jmNodeLabels = nil
+	if len(observer.savepointStorageRules) == 0 || jobSpec == nil || jobSpec.SavepointsDir == nil {
+		return
+	}
+
+	for _, pod := range observed.jmPods {
+		if pod.Spec.NodeName == "" || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		node, err := observer.k8sClientset.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+		if err != nil {
+			log.Info("Failed to get node", "node", pod.Spec.NodeName, "error", err)
+			return
+		}
+		observed.jmNodeLabels = node.Labels
+		if observed.jmNodeLabels == nil {
+			observed.jmNodeLabels = map[string]string{}
+		}
+		return
+	}
+}
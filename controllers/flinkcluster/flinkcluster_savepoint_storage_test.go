@@ -0,0 +1,68 @@
+package flinkcluster
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestLoadSavepointStorageConfig(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "savepoint-storage.yaml")
+	os.WriteFile(path, []byte(`
+rules:
+- region: europe-west1
+  savepointsDir: gs://savepoints-europe-west1/flink
+- region: us-east1
+  zone: us-east1-b
+  savepointsDir: gs://savepoints-us-east1-b/flink
+`), 0644)
+	config, err := LoadSavepointStorageConfig(path)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, config.Rules[1], SavepointStorageRule{
+		Region: "us-east1", Zone: "us-east1-b", SavepointsDir: "gs://savepoints-us-east1-b/flink"})
+
+	os.WriteFile(path, []byte("rules:\n- savepointsDir: gs://savepoints/flink\n"), 0644)
+	_, err = LoadSavepointStorageConfig(path)
+	assert.ErrorContains(t, err, "rules[0] must set region or zone")
+
+	os.WriteFile(path, []byte("rules:\n- region: europe-west1\n"), 0644)
+	_, err = LoadSavepointStorageConfig(path)
+	assert.ErrorContains(t, err, "rules[0].savepointsDir is unspecified")
+}
+
+func TestDeriveSavepointsDir(t *testing.T) {
+	var rules = []SavepointStorageRule{
+		{Region: "us-east1", Zone: "us-east1-b", SavepointsDir: "gs://savepoints-us-east1-b/flink/"},
+		{Region: "us-east1", SavepointsDir: "gs://savepoints-us-east1/flink"},
+	}
+	var cluster = getObservedClusterState().cluster
+	var savepointsDir = "gs://savepoints/flink"
+	var nodeLabels = func(region, zone string) map[string]string {
+		return map[string]string{corev1.LabelTopologyRegion: region, corev1.LabelTopologyZone: zone}
+	}
+
+	// The job does not take savepoints.
+	assert.Equal(t, deriveSavepointsDir(rules, cluster, "", nodeLabels("us-east1", "us-east1-b")), "")
+
+	cluster.Spec.Job.SavepointsDir = &savepointsDir
+	assert.Equal(t, deriveSavepointsDir(rules, cluster, "", nodeLabels("us-east1", "us-east1-b")),
+		"gs://savepoints-us-east1-b/flink/default/fjc")
+	assert.Equal(t, deriveSavepointsDir(rules, cluster, "", nodeLabels("us-east1", "us-east1-c")),
+		"gs://savepoints-us-east1/flink/default/fjc")
+	assert.Equal(t, deriveSavepointsDir(rules, cluster, "gs://savepoints-us-east1/flink/default/fjc",
+		nodeLabels("europe-west1", "europe-west1-b")), "")
+	assert.Equal(t, deriveSavepointsDir(nil, cluster, "gs://savepoints-us-east1/flink/default/fjc",
+		nodeLabels("us-east1", "us-east1-b")), "")
+
+	// The selected dir is kept while the JobManager node is unknown.
+	assert.Equal(t, deriveSavepointsDir(rules, cluster, "gs://savepoints-us-east1/flink/default/fjc", nil),
+		"gs://savepoints-us-east1/flink/default/fjc")
+
+	assert.Equal(t, getSavepointsDir(cluster), "gs://savepoints/flink")
+	cluster.Status.Components.Job = &v1beta1.JobStatus{SavepointsDir: "gs://savepoints-us-east1/flink/default/fjc"}
+	assert.Equal(t, getSavepointsDir(cluster), "gs://savepoints-us-east1/flink/default/fjc")
+}
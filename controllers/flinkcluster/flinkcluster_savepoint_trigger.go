@@ -0,0 +1,183 @@
+package flinkcluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Runs the Flink CLI given as the arguments, and writes the end of its output to the
+// termination log of the container, where the operator reads the savepoint path from.
+const savepointTriggerScript = `output=$("$@" 2>&1) && status=0 || status=$?
+echo "${output}"
+echo "${output}" | tail -c 4000 >/dev/termination-log
+exit "${status}"`
+
+// The path of the savepoint in the output of `flink savepoint` and `flink stop`, and of
+// `flink cancel --withSavepoint`.
+var savepointPathRegexp = regexp.MustCompile(`(?:Savepoint completed\. Path: |Savepoint stored in )(\S+)`)
+
+// Gets the name of the Kubernetes Job which triggers the savepoints of the cluster.
+func getSavepointTriggerJobName(clusterName string) string {
+	return clusterName + "-savepoint-trigger"
+}
+
+func getSavepointTriggerMode(jobSpec *v1beta1.JobSpec) v1beta1.SavepointTriggerMode {
+	if jobSpec == nil || jobSpec.SavepointTriggerMode == nil {
+		return v1beta1.SavepointTriggerModeAuto
+	}
+	return *jobSpec.SavepointTriggerMode
+}
+
+// newSavepointTriggerJob returns the Kubernetes Job which runs the Flink CLI of the image to
+// take a savepoint of the job, and to stop it by the stop mode unless it is empty. The pods
+// have the labels of the cluster and of the job submitter, so that the NetworkPolicies which
+// let the job submitter reach the JobManager apply to them too. The image is pulled with the
+// given image pull secrets unless the cluster sets spec.image.pullSecrets.
+func newSavepointTriggerJob(
+	cluster *v1beta1.FlinkCluster,
+	jobID string,
+	stopMode v1beta1.JobUpdateStopMode,
+	imagePullSecrets []corev1.LocalObjectReference) *batchv1.Job {
+	var jobSpec = cluster.Spec.Job
+	var address = net.JoinHostPort(render.JobManagerAddress(cluster), strconv.Itoa(int(*cluster.Spec.JobManager.Ports.UI)))
+	var dir = getSavepointsDir(cluster)
+	var args = []string{"bash", "-c", savepointTriggerScript, "savepoint-trigger", "/opt/flink/bin/flink"}
+	switch stopMode {
+	case v1beta1.JobUpdateStopModeStopWithSavepoint:
+		args = append(args, "stop", "--jobmanager", address, "--savepointPath", dir, jobID)
+	case v1beta1.JobUpdateStopModeCancelWithSavepoint:
+		args = append(args, "cancel", "--jobmanager", address, "--withSavepoint", dir, jobID)
+	default:
+		args = append(args, "savepoint", "--jobmanager", address, jobID, dir)
+	}
+
+	var labels = render.MergeLabels(jobSpec.PodLabels, render.ComponentLabels(cluster, "savepoint-trigger"))
+	var backoffLimit int32 = 0
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       cluster.Namespace,
+			Name:            getSavepointTriggerJobName(cluster.Name),
+			OwnerReferences: []metav1.OwnerReference{render.ToOwnerReference(cluster)},
+			Labels:          labels,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:            "main",
+						Image:           cluster.Spec.Image.Name,
+						ImagePullPolicy: cluster.Spec.Image.PullPolicy,
+						Args:            args,
+						EnvFrom:         cluster.Spec.EnvFrom,
+					}},
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ImagePullSecrets:   render.ImagePullSecrets(cluster, imagePullSecrets),
+					SecurityContext:    jobSpec.SecurityContext,
+					HostAliases:        jobSpec.HostAliases,
+					ServiceAccountName: render.ServiceAccountName(cluster),
+					Affinity:           jobSpec.Affinity,
+					NodeSelector:       jobSpec.NodeSelector,
+					Tolerations:        jobSpec.Tolerations,
+				},
+			},
+			BackoffLimit: &backoffLimit,
+		},
+	}
+}
+
+// getSavepointTriggerResult returns the status of the savepoint from the Kubernetes Job which
+// triggered it and its pod, nil while the Job is running.
+func getSavepointTriggerResult(jobID string, job *batchv1.Job, pod *corev1.Pod) (*flink.SavepointStatus, error) {
+	if job == nil {
+		return nil, fmt.Errorf("savepoint trigger job is not found")
+	}
+	if job.Status.Succeeded == 0 && job.Status.Failed == 0 {
+		return nil, nil
+	}
+
+	var output string
+	if pod != nil && len(pod.Status.ContainerStatuses) > 0 {
+		if terminated := pod.Status.ContainerStatuses[0].State.Terminated; terminated != nil {
+			output = strings.TrimSpace(terminated.Message)
+		}
+	}
+	var status = &flink.SavepointStatus{JobID: jobID, Completed: true}
+	if result := savepointPathRegexp.FindStringSubmatch(output); job.Status.Succeeded > 0 && len(result) > 0 {
+		status.Location = strings.TrimSuffix(result[1], ".")
+		return status, nil
+	}
+	if output == "" {
+		output = "no output"
+	}
+	status.FailureCause.StackTrace = fmt.Sprintf("savepoint trigger job %v failed: %v", job.Name, output)
+	return status, nil
+}
+
+func (observer *ClusterStateObserver) observeSavepointTriggerJob(
+	ctx context.Context,
+	observed *ObservedClusterState) error {
+	var jobName = getSavepointTriggerJobName(observed.cluster.Name)
+	var job = new(batchv1.Job)
+	if err := observer.observeObject(ctx, jobName, job); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		observed.savepointTriggerJob = nil
+		observed.savepointTriggerPod = nil
+		return nil
+	}
+	observed.savepointTriggerJob = job
+
+	var pod = new(corev1.Pod)
+	if err := observer.observeJobSubmitterPod(ctx, jobName, pod); err != nil {
+		return err
+	}
+	observed.savepointTriggerPod = pod
+	if pod.Name == "" {
+		observed.savepointTriggerPod = nil
+	}
+	return nil
+}
+
+// Triggers the savepoint with the Kubernetes Job, returns its name.
+func (reconciler *ClusterReconciler) createSavepointTriggerJob(
+	ctx context.Context, jobID string, stopMode v1beta1.JobUpdateStopMode) (string, error) {
+	var log = logr.FromContextOrDiscard(ctx)
+	var job = newSavepointTriggerJob(reconciler.observed.cluster, jobID, stopMode, reconciler.imagePullSecrets)
+	if err := reconciler.k8sClient.Create(ctx, job); err != nil {
+		return "", err
+	}
+	log.Info("Created savepoint trigger job", "name", job.Name, "jobID", jobID, "updateStopMode", stopMode)
+	return job.Name, nil
+}
+
+// Deletes the Kubernetes Job which triggered a savepoint once the savepoint is not in progress.
+func (reconciler *ClusterReconciler) reconcileSavepointTriggerJob(ctx context.Context) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var job = reconciler.observed.savepointTriggerJob
+	var savepoint = reconciler.observed.cluster.Status.Savepoint
+	if job == nil || job.DeletionTimestamp != nil ||
+		(savepoint != nil && savepoint.State == v1beta1.SavepointStateInProgress && savepoint.TriggerJob == job.Name) {
+		return nil
+	}
+	var propagation = metav1.DeletePropagationBackground
+	if err := reconciler.k8sClient.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &propagation}); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	log.Info("Deleted savepoint trigger job", "name", job.Name)
+	return nil
+}
@@ -0,0 +1,113 @@
+package flinkcluster
+
+import (
+	"context"
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewSavepointTriggerJob(t *testing.T) {
+	var cluster = getObservedClusterState().cluster
+	var savepointsDir = "gs://my-bucket/savepoints"
+	cluster.Spec.Job.SavepointsDir = &savepointsDir
+	var jobID = "ec74209eb4e3db8ae72db00bd7a830aa"
+
+	var job = newSavepointTriggerJob(cluster, jobID, "", nil)
+	assert.Equal(t, job.Name, "fjc-savepoint-trigger")
+	assert.Equal(t, job.Labels["component"], "savepoint-trigger")
+	assert.Equal(t, job.Labels["cluster"], "fjc")
+	assert.Equal(t, *job.Spec.BackoffLimit, int32(0))
+	var container = job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, container.Image, cluster.Spec.Image.Name)
+	assert.DeepEqual(t, container.Args[3:], []string{"savepoint-trigger", "/opt/flink/bin/flink",
+		"savepoint", "--jobmanager", "fjc-jobmanager:8081", jobID, savepointsDir})
+
+	job = newSavepointTriggerJob(cluster, jobID, v1beta1.JobUpdateStopModeStopWithSavepoint, nil)
+	assert.DeepEqual(t, job.Spec.Template.Spec.Containers[0].Args[5:], []string{
+		"stop", "--jobmanager", "fjc-jobmanager:8081", "--savepointPath", savepointsDir, jobID})
+
+	job = newSavepointTriggerJob(cluster, jobID, v1beta1.JobUpdateStopModeCancelWithSavepoint, nil)
+	assert.DeepEqual(t, job.Spec.Template.Spec.Containers[0].Args[5:], []string{
+		"cancel", "--jobmanager", "fjc-jobmanager:8081", "--withSavepoint", savepointsDir, jobID})
+}
+
+func TestGetSavepointTriggerResult(t *testing.T) {
+	var jobID = "ec74209eb4e3db8ae72db00bd7a830aa"
+	var job = &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "fjc-savepoint-trigger"}}
+	var pod = &corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{}}}}
+	var terminate = func(message string) {
+		pod.Status.ContainerStatuses[0].State.Terminated = &corev1.ContainerStateTerminated{Message: message}
+	}
+
+	_, err := getSavepointTriggerResult(jobID, nil, nil)
+	assert.Error(t, err, "savepoint trigger job is not found")
+
+	// The job is running.
+	status, err := getSavepointTriggerResult(jobID, job, pod)
+	assert.NilError(t, err)
+	assert.Assert(t, status == nil)
+
+	job.Status.Succeeded = 1
+	terminate("Triggering savepoint for job ec74209eb4e3db8ae72db00bd7a830aa.\n" +
+		"Waiting for response...\n" +
+		"Savepoint completed. Path: gs://my-bucket/savepoints/savepoint-ec7420-4a1b2c3d4e5f\n" +
+		"You can resume your program from this savepoint with the run command.\n")
+	status, err = getSavepointTriggerResult(jobID, job, pod)
+	assert.NilError(t, err)
+	assert.Assert(t, status.IsSuccessful())
+	assert.Equal(t, status.Location, "gs://my-bucket/savepoints/savepoint-ec7420-4a1b2c3d4e5f")
+
+	terminate("Cancelled job ec74209eb4e3db8ae72db00bd7a830aa. Savepoint stored in gs://my-bucket/savepoints/savepoint-ec7420-6f7a8b9c0d1e.\n")
+	status, err = getSavepointTriggerResult(jobID, job, pod)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Location, "gs://my-bucket/savepoints/savepoint-ec7420-6f7a8b9c0d1e")
+
+	job.Status.Succeeded = 0
+	job.Status.Failed = 1
+	terminate("java.util.concurrent.TimeoutException\n")
+	status, err = getSavepointTriggerResult(jobID, job, pod)
+	assert.NilError(t, err)
+	assert.Assert(t, status.IsFailed())
+	assert.Equal(t, status.FailureCause.StackTrace, "savepoint trigger job fjc-savepoint-trigger failed: java.util.concurrent.TimeoutException")
+}
+
+func TestTriggerSavepointWithJob(t *testing.T) {
+	var observed = getObservedClusterState()
+	var mode = v1beta1.SavepointTriggerModeJob
+	var savepointsDir = "gs://my-bucket/savepoints"
+	observed.cluster.Spec.Job.SavepointTriggerMode = &mode
+	observed.cluster.Spec.Job.SavepointsDir = &savepointsDir
+	var scheme = runtime.NewScheme()
+	assert.NilError(t, batchv1.AddToScheme(scheme))
+	var k8sClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+	var reconciler = ClusterReconciler{k8sClient: k8sClient, recorder: record.NewFakeRecorder(1), observed: *observed}
+
+	status, err := reconciler.triggerSavepoint(context.TODO(), "ec74209eb4e3db8ae72db00bd7a830aa", v1beta1.SavepointReasonUpdate, "")
+	assert.NilError(t, err)
+	assert.Equal(t, status.State, v1beta1.SavepointStateInProgress)
+	assert.Equal(t, status.TriggerJob, "fjc-savepoint-trigger")
+	assert.Equal(t, status.TriggerID, "")
+	var job = new(batchv1.Job)
+	assert.NilError(t, k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "fjc-savepoint-trigger"}, job))
+
+	// The job is kept while the savepoint is in progress, and deleted afterwards.
+	reconciler.observed.savepointTriggerJob = job
+	reconciler.observed.cluster.Status.Savepoint = status
+	assert.NilError(t, reconciler.reconcileSavepointTriggerJob(context.TODO()))
+	assert.NilError(t, k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(job), new(batchv1.Job)))
+
+	status.State = v1beta1.SavepointStateSucceeded
+	assert.NilError(t, reconciler.reconcileSavepointTriggerJob(context.TODO()))
+	err = k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(job), new(batchv1.Job))
+	assert.Assert(t, errors.IsNotFound(err))
+}
@@ -0,0 +1,62 @@
+package flinkcluster
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+)
+
+// getSavepointFlinkVersion returns the Flink version which took the savepoint, empty if it is
+// not known, e.g. for savepoints taken outside the operator.
+func getSavepointFlinkVersion(job *v1beta1.JobStatus, savepoint string) string {
+	if job == nil || job.SavepointLocation != savepoint {
+		return ""
+	}
+	return job.SavepointFlinkVersion
+}
+
+// checkSavepointFlinkVersion returns an error if Flink of the target version cannot restore
+// the savepoints taken with the savepoint version. Flink restores the savepoints of its own
+// and older minor versions of the same major version, the patch versions do not matter.
+// Versions which cannot be parsed are not checked.
+func checkSavepointFlinkVersion(savepointVersion, targetVersion string) error {
+	source, err := version.NewVersion(savepointVersion)
+	if err != nil {
+		return nil
+	}
+	target, err := version.NewVersion(targetVersion)
+	if err != nil {
+		return nil
+	}
+	var s, t = source.Segments(), target.Segments()
+	switch {
+	case s[0] != t[0]:
+		return fmt.Errorf("Flink %v cannot restore savepoints of Flink %v, which is of another major version",
+			targetVersion, savepointVersion)
+	case s[1] > t[1]:
+		return fmt.Errorf("Flink %v cannot restore savepoints of the newer Flink %v, downgrades are not supported",
+			targetVersion, savepointVersion)
+	}
+	return nil
+}
+
+// checkRestoreFlinkVersion returns an error if the job is about to be restored from a
+// savepoint which the Flink version of the cluster cannot restore.
+func checkRestoreFlinkVersion(cluster *v1beta1.FlinkCluster) error {
+	var job = cluster.Status.Components.Job
+	var savepoint = render.FromSavepoint(cluster.Spec.Job, job, &cluster.Status.Revision)
+	if savepoint == nil {
+		return nil
+	}
+	var savepointVersion = getSavepointFlinkVersion(job, *savepoint)
+	if savepointVersion == "" {
+		return nil
+	}
+	if err := checkSavepointFlinkVersion(savepointVersion, cluster.Spec.FlinkVersion); err != nil {
+		return fmt.Errorf("cannot restore the job from savepoint %v: %v, "+
+			"set spec.flinkVersion to a compatible version or spec.job.fromSavepoint to another savepoint", *savepoint, err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package flinkcluster
+
+import (
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+)
+
+func TestCheckSavepointFlinkVersion(t *testing.T) {
+	var tests = []struct {
+		savepointVersion string
+		targetVersion    string
+		err              string
+	}{
+		{"1.15.2", "1.15", ""},
+		{"1.15.2", "1.15.1", ""},
+		{"1.14.6", "1.16", ""},
+		{"1.16.1", "1.15", "Flink 1.15 cannot restore savepoints of the newer Flink 1.16.1, downgrades are not supported"},
+		{"1.18.1", "2.0", "Flink 2.0 cannot restore savepoints of Flink 1.18.1, which is of another major version"},
+		{"<unknown>", "1.15", ""},
+	}
+	for _, test := range tests {
+		var err = checkSavepointFlinkVersion(test.savepointVersion, test.targetVersion)
+		if test.err == "" {
+			assert.NilError(t, err, test.savepointVersion, test.targetVersion)
+		} else {
+			assert.Error(t, err, test.err)
+		}
+	}
+}
+
+func TestCheckRestoreFlinkVersion(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			FlinkVersion: "1.15",
+			Job:          &v1beta1.JobSpec{},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Components: v1beta1.FlinkClusterComponentsStatus{
+				Job: &v1beta1.JobStatus{
+					SavepointLocation:     "gs://my-bucket/savepoints/savepoint-1",
+					SavepointFlinkVersion: "1.16.1",
+				},
+			},
+		},
+	}
+	assert.Error(t, checkRestoreFlinkVersion(cluster),
+		"cannot restore the job from savepoint gs://my-bucket/savepoints/savepoint-1: "+
+			"Flink 1.15 cannot restore savepoints of the newer Flink 1.16.1, downgrades are not supported, "+
+			"set spec.flinkVersion to a compatible version or spec.job.fromSavepoint to another savepoint")
+
+	cluster.Spec.FlinkVersion = "1.16"
+	assert.NilError(t, checkRestoreFlinkVersion(cluster))
+
+	// The versions of the savepoints taken outside the operator are not known.
+	var fromSavepoint = "gs://my-bucket/savepoints/savepoint-0"
+	cluster.Spec.FlinkVersion = "1.15"
+	cluster.Spec.Job.FromSavepoint = &fromSavepoint
+	cluster.Status.Components.Job.SavepointLocation = ""
+	assert.NilError(t, checkRestoreFlinkVersion(cluster))
+}
@@ -0,0 +1,205 @@
+package flinkcluster
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Reasons of the SLOViolated condition.
+const (
+	sloReasonObjectivesViolated = "ObjectivesViolated"
+	sloReasonObjectivesMet      = "ObjectivesMet"
+	sloReasonJobNotRunning      = "JobNotRunning"
+)
+
+// Whether the running jobs violate the objectives of spec.job.slo, served on the metrics
+// endpoint of the operator so that alerts can be routed off the cluster state.
+var jobSLOViolated = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "flink_operator_job_slo_violated",
+	Help: "Whether the running job of the FlinkCluster violates the service level objective, 1 if violated.",
+}, []string{"namespace", "cluster", "objective"})
+
+func init() {
+	metrics.Registry.MustRegister(jobSLOViolated)
+}
+
+// getConsumerLagMetricIDs returns the IDs of the available vertex metrics which are named
+// by the metric of spec.job.slo.maxConsumerLag, either directly or as an operator metric.
+func getConsumerLagMetricIDs(available []flink.AggregatedMetric, name string) []string {
+	var ids []string
+	for _, metric := range available {
+		if metric.ID == name || strings.HasSuffix(metric.ID, "."+name) {
+			ids = append(ids, metric.ID)
+		}
+	}
+	return ids
+}
+
+// aggregateConsumerLag aggregates the consumer lag metrics of the vertices of a job.
+func aggregateConsumerLag(metrics []flink.AggregatedMetric, aggregation v1beta1.ConsumerLagAggregation) int64 {
+	var lag float64
+	for _, metric := range metrics {
+		if aggregation == v1beta1.ConsumerLagAggregationMax {
+			if metric.Max > lag {
+				lag = metric.Max
+			}
+		} else {
+			lag += metric.Sum
+		}
+	}
+	return int64(lag)
+}
+
+// evaluateJobSLO evaluates spec.job.slo for the running job with the observations of this
+// reconciliation. The objectives which could not be observed keep their recorded result.
+func evaluateJobSLO(slo *v1beta1.JobSLO, recorded *v1beta1.JobSLOStatus, flinkJob *FlinkJob, now time.Time) *v1beta1.JobSLOStatus {
+	var status = &v1beta1.JobSLOStatus{}
+	var recordedViolations = map[string]v1beta1.JobSLOViolation{}
+	if recorded != nil {
+		status.ObservedNumRestarts = recorded.ObservedNumRestarts
+		for _, restartTime := range recorded.RestartTimes {
+			if now.Sub(restartTime.Time) < time.Hour {
+				status.RestartTimes = append(status.RestartTimes, restartTime)
+			}
+		}
+		for _, violation := range recorded.Violations {
+			recordedViolations[violation.Objective] = violation
+		}
+	}
+	var evaluate = func(objective string, observed bool, violated func() bool, message string) {
+		if !observed {
+			if violation, ok := recordedViolations[objective]; ok {
+				status.Violations = append(status.Violations, violation)
+			}
+		} else if violated() {
+			status.Violations = append(status.Violations, v1beta1.JobSLOViolation{Objective: objective, Message: message})
+		}
+	}
+
+	if slo.MaxCheckpointAgeSeconds != nil {
+		var maxAge = *slo.MaxCheckpointAgeSeconds
+		evaluate(v1beta1.JobSLOObjectiveMaxCheckpointAge,
+			flinkJob.checkpoints != nil && flinkJob.status != nil,
+			func() bool {
+				// The job is regarded as checkpointed when it starts.
+				var latest = flinkJob.status.StartTime
+				if completed := flinkJob.checkpoints.Latest.Completed; completed != nil && completed.LatestAckTimestamp > latest {
+					latest = completed.LatestAckTimestamp
+				}
+				return now.Sub(time.UnixMilli(latest)) > time.Duration(maxAge)*time.Second
+			},
+			fmt.Sprintf("no checkpoint completed in the last %vs", maxAge))
+	}
+
+	if slo.MaxRestartRatePerHour != nil {
+		var maxRate = *slo.MaxRestartRatePerHour
+		if numRestarts := flinkJob.numRestarts; numRestarts != nil {
+			// The restarts are counted from the first observation of the metric, which restarts from 0
+			// for every submission of the job. One restart more than allowed is enough to record.
+			if status.ObservedNumRestarts != nil {
+				for i := *status.ObservedNumRestarts; i < *numRestarts && len(status.RestartTimes) <= int(maxRate); i++ {
+					status.RestartTimes = append(status.RestartTimes, metav1.NewTime(now))
+				}
+			}
+			status.ObservedNumRestarts = numRestarts
+		}
+		evaluate(v1beta1.JobSLOObjectiveMaxRestartRate,
+			flinkJob.numRestarts != nil,
+			func() bool { return len(status.RestartTimes) > int(maxRate) },
+			fmt.Sprintf("the job restarted more than %v times in the last hour", maxRate))
+	} else {
+		status.RestartTimes = nil
+		status.ObservedNumRestarts = nil
+	}
+
+	if objective := slo.MaxConsumerLag; objective != nil {
+		evaluate(v1beta1.JobSLOObjectiveMaxConsumerLag,
+			flinkJob.consumerLag != nil,
+			func() bool { return *flinkJob.consumerLag > objective.Max },
+			fmt.Sprintf("the consumer lag measured by %v is more than %v", objective.Metric, objective.Max))
+	}
+
+	return status
+}
+
+// setJobSLOCondition sets the SLOViolated condition of the cluster from the evaluation of
+// spec.job.slo in the job status, or removes it if spec.job.slo is not set.
+func setJobSLOCondition(conditions *[]metav1.Condition, cluster *v1beta1.FlinkCluster, job *v1beta1.JobStatus) {
+	if cluster.Spec.Job == nil || cluster.Spec.Job.SLO == nil {
+		meta.RemoveStatusCondition(conditions, v1beta1.ClusterConditionSLOViolated)
+		return
+	}
+
+	var condition = metav1.Condition{
+		Type:               v1beta1.ClusterConditionSLOViolated,
+		ObservedGeneration: cluster.Generation,
+	}
+	switch {
+	case job == nil || job.SLO == nil:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = sloReasonJobNotRunning
+		condition.Message = "The job is not running."
+	case len(job.SLO.Violations) > 0:
+		var messages []string
+		for _, violation := range job.SLO.Violations {
+			messages = append(messages, fmt.Sprintf("%v: %v", violation.Objective, violation.Message))
+		}
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = sloReasonObjectivesViolated
+		condition.Message = strings.Join(messages, "; ")
+	default:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = sloReasonObjectivesMet
+		condition.Message = "The job meets its service level objectives."
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
+
+// recordJobSLOMetrics exports the evaluation of spec.job.slo recorded in the status of the
+// cluster. The metrics of the objectives which are not evaluated, e.g. while the job is not
+// running, are removed, and so are all the metrics of the cluster when it is deleted.
+func recordJobSLOMetrics(name types.NamespacedName, cluster *v1beta1.FlinkCluster, err error) {
+	if cluster == nil {
+		if err == nil {
+			jobSLOViolated.DeletePartialMatch(prometheus.Labels{"namespace": name.Namespace, "cluster": name.Name})
+		}
+		return
+	}
+
+	var slo *v1beta1.JobSLO
+	if cluster.Spec.Job != nil {
+		slo = cluster.Spec.Job.SLO
+	}
+	var status *v1beta1.JobSLOStatus
+	if job := cluster.Status.Components.Job; job != nil {
+		status = job.SLO
+	}
+	var objectives = map[string]bool{
+		v1beta1.JobSLOObjectiveMaxCheckpointAge: slo != nil && slo.MaxCheckpointAgeSeconds != nil,
+		v1beta1.JobSLOObjectiveMaxRestartRate:   slo != nil && slo.MaxRestartRatePerHour != nil,
+		v1beta1.JobSLOObjectiveMaxConsumerLag:   slo != nil && slo.MaxConsumerLag != nil,
+	}
+	for objective, enabled := range objectives {
+		var labels = prometheus.Labels{"namespace": name.Namespace, "cluster": name.Name, "objective": objective}
+		if !enabled || status == nil {
+			jobSLOViolated.Delete(labels)
+			continue
+		}
+		var violated float64
+		for _, violation := range status.Violations {
+			if violation.Objective == objective {
+				violated = 1
+			}
+		}
+		jobSLOViolated.With(labels).Set(violated)
+	}
+}
@@ -0,0 +1,127 @@
+package flinkcluster
+
+import (
+	"testing"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetConsumerLagMetricIDs(t *testing.T) {
+	var available = []flink.AggregatedMetric{
+		{ID: "numRecordsIn"},
+		{ID: "pendingRecords"},
+		{ID: "Source__orders.pendingRecords"},
+		{ID: "Source__orders.maxPendingRecords"},
+	}
+	assert.DeepEqual(t, getConsumerLagMetricIDs(available, "pendingRecords"),
+		[]string{"pendingRecords", "Source__orders.pendingRecords"})
+	assert.Assert(t, getConsumerLagMetricIDs(available, "records-lag-max") == nil)
+}
+
+func TestAggregateConsumerLag(t *testing.T) {
+	var metrics = []flink.AggregatedMetric{
+		{ID: "Source__orders.pendingRecords", Max: 300, Sum: 500},
+		{ID: "Source__payments.pendingRecords", Max: 100, Sum: 150},
+	}
+	assert.Equal(t, aggregateConsumerLag(metrics, v1beta1.ConsumerLagAggregationSum), int64(650))
+	assert.Equal(t, aggregateConsumerLag(metrics, ""), int64(650))
+	assert.Equal(t, aggregateConsumerLag(metrics, v1beta1.ConsumerLagAggregationMax), int64(300))
+}
+
+func TestEvaluateJobSLO(t *testing.T) {
+	var now = time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	var maxCheckpointAge, maxRestartRate = int32(600), int32(1)
+	var slo = &v1beta1.JobSLO{
+		MaxCheckpointAgeSeconds: &maxCheckpointAge,
+		MaxRestartRatePerHour:   &maxRestartRate,
+		MaxConsumerLag:          &v1beta1.ConsumerLagObjective{Metric: "pendingRecords", Max: 1000},
+	}
+	var numRestarts, consumerLag = int64(2), int64(100)
+	var flinkJob = FlinkJob{
+		status: &flink.Job{StartTime: now.Add(-time.Hour).UnixMilli()},
+		checkpoints: &flink.JobCheckpoints{Latest: flink.LatestCheckpoints{
+			Completed: &flink.CheckpointStatistics{LatestAckTimestamp: now.Add(-time.Minute).UnixMilli()},
+		}},
+		numRestarts: &numRestarts,
+		consumerLag: &consumerLag,
+	}
+
+	// The restarts are counted from the first observation.
+	var status = evaluateJobSLO(slo, nil, &flinkJob, now)
+	assert.DeepEqual(t, status, &v1beta1.JobSLOStatus{ObservedNumRestarts: &numRestarts})
+
+	// The job restarts twice, the consumer lag grows and no checkpoint completes.
+	var restarted, lagging = int64(4), int64(2000)
+	flinkJob.numRestarts = &restarted
+	flinkJob.consumerLag = &lagging
+	flinkJob.checkpoints.Latest.Completed = nil
+	status = evaluateJobSLO(slo, status, &flinkJob, now)
+	assert.DeepEqual(t, status, &v1beta1.JobSLOStatus{
+		Violations: []v1beta1.JobSLOViolation{
+			{Objective: v1beta1.JobSLOObjectiveMaxCheckpointAge, Message: "no checkpoint completed in the last 600s"},
+			{Objective: v1beta1.JobSLOObjectiveMaxRestartRate, Message: "the job restarted more than 1 times in the last hour"},
+			{Objective: v1beta1.JobSLOObjectiveMaxConsumerLag, Message: "the consumer lag measured by pendingRecords is more than 1000"},
+		},
+		RestartTimes:        []metav1.Time{metav1.NewTime(now), metav1.NewTime(now)},
+		ObservedNumRestarts: &restarted,
+	})
+
+	// The objectives which are not observed keep their result, the restarts expire after an hour.
+	var later = now.Add(time.Hour)
+	status = evaluateJobSLO(slo, status, &FlinkJob{}, later)
+	assert.DeepEqual(t, status, &v1beta1.JobSLOStatus{
+		Violations: []v1beta1.JobSLOViolation{
+			{Objective: v1beta1.JobSLOObjectiveMaxCheckpointAge, Message: "no checkpoint completed in the last 600s"},
+			{Objective: v1beta1.JobSLOObjectiveMaxRestartRate, Message: "the job restarted more than 1 times in the last hour"},
+			{Objective: v1beta1.JobSLOObjectiveMaxConsumerLag, Message: "the consumer lag measured by pendingRecords is more than 1000"},
+		},
+		ObservedNumRestarts: &restarted,
+	})
+
+	flinkJob.checkpoints.Latest.Completed = &flink.CheckpointStatistics{LatestAckTimestamp: later.UnixMilli()}
+	flinkJob.consumerLag = &consumerLag
+	status = evaluateJobSLO(slo, status, &flinkJob, later)
+	assert.DeepEqual(t, status, &v1beta1.JobSLOStatus{ObservedNumRestarts: &restarted})
+}
+
+func TestSetJobSLOCondition(t *testing.T) {
+	var maxCheckpointAge = int32(600)
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec: v1beta1.FlinkClusterSpec{
+			Job: &v1beta1.JobSpec{SLO: &v1beta1.JobSLO{MaxCheckpointAgeSeconds: &maxCheckpointAge}},
+		},
+	}
+	var conditions []metav1.Condition
+
+	setJobSLOCondition(&conditions, cluster, &v1beta1.JobStatus{State: v1beta1.JobStateDeploying})
+	var condition = meta.FindStatusCondition(conditions, v1beta1.ClusterConditionSLOViolated)
+	assert.Equal(t, condition.Status, metav1.ConditionUnknown)
+	assert.Equal(t, condition.Reason, "JobNotRunning")
+	assert.Equal(t, condition.ObservedGeneration, int64(2))
+
+	setJobSLOCondition(&conditions, cluster, &v1beta1.JobStatus{
+		State: v1beta1.JobStateRunning,
+		SLO: &v1beta1.JobSLOStatus{Violations: []v1beta1.JobSLOViolation{
+			{Objective: v1beta1.JobSLOObjectiveMaxCheckpointAge, Message: "no checkpoint completed in the last 600s"},
+		}},
+	})
+	condition = meta.FindStatusCondition(conditions, v1beta1.ClusterConditionSLOViolated)
+	assert.Equal(t, condition.Status, metav1.ConditionTrue)
+	assert.Equal(t, condition.Reason, "ObjectivesViolated")
+	assert.Equal(t, condition.Message, "maxCheckpointAgeSeconds: no checkpoint completed in the last 600s")
+
+	setJobSLOCondition(&conditions, cluster, &v1beta1.JobStatus{State: v1beta1.JobStateRunning, SLO: &v1beta1.JobSLOStatus{}})
+	condition = meta.FindStatusCondition(conditions, v1beta1.ClusterConditionSLOViolated)
+	assert.Equal(t, condition.Status, metav1.ConditionFalse)
+	assert.Equal(t, condition.Reason, "ObjectivesMet")
+
+	cluster.Spec.Job.SLO = nil
+	setJobSLOCondition(&conditions, cluster, &v1beta1.JobStatus{State: v1beta1.JobStateRunning})
+	assert.Equal(t, len(conditions), 0)
+}
@@ -0,0 +1,142 @@
+package flinkcluster
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Reasons of the StartupDeadlineExceeded condition. Once the deadline passed, the reason
+// is the one why the first component is not ready, e.g. ImagePullBackOff.
+const (
+	startupDeadlineReasonStarting = "Starting"
+	startupDeadlineReasonNotReady = "ComponentsNotReady"
+)
+
+func getStartupDeadlineAction(cluster *v1beta1.FlinkCluster) v1beta1.StartupDeadlineAction {
+	if cluster.Spec.StartupDeadlineAction == nil {
+		return v1beta1.StartupDeadlineActionStopReconciling
+	}
+	return *cluster.Spec.StartupDeadlineAction
+}
+
+// setStartupDeadlineCondition tracks spec.startupDeadlineSeconds with the
+// StartupDeadlineExceeded condition. It is false from when the cluster starts to be created,
+// restarting when the spec is updated, and true once the deadline passed before the
+// components were ready. It is removed when the cluster is no longer created, except when
+// the exceeded deadline stopped the cluster.
+func setStartupDeadlineCondition(
+	conditions *[]metav1.Condition,
+	cluster *v1beta1.FlinkCluster,
+	status *v1beta1.FlinkClusterStatus,
+	now time.Time) {
+	var seconds = cluster.Spec.StartupDeadlineSeconds
+	var condition = meta.FindStatusCondition(*conditions, v1beta1.ClusterConditionStartupDeadlineExceeded)
+	var exceeded = condition != nil && condition.Status == metav1.ConditionTrue
+	switch {
+	case seconds == nil:
+		meta.RemoveStatusCondition(conditions, v1beta1.ClusterConditionStartupDeadlineExceeded)
+		return
+	case status.State == v1beta1.ClusterStateStopping ||
+		status.State == v1beta1.ClusterStatePartiallyStopped ||
+		status.State == v1beta1.ClusterStateStopped:
+		if !exceeded {
+			meta.RemoveStatusCondition(conditions, v1beta1.ClusterConditionStartupDeadlineExceeded)
+		}
+		return
+	case status.State != v1beta1.ClusterStateCreating:
+		meta.RemoveStatusCondition(conditions, v1beta1.ClusterConditionStartupDeadlineExceeded)
+		return
+	}
+
+	if condition == nil || condition.ObservedGeneration != cluster.Generation {
+		// Set the condition anew, which keeps its transition time otherwise.
+		meta.RemoveStatusCondition(conditions, v1beta1.ClusterConditionStartupDeadlineExceeded)
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               v1beta1.ClusterConditionStartupDeadlineExceeded,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: cluster.Generation,
+			LastTransitionTime: metav1.NewTime(now),
+			Reason:             startupDeadlineReasonStarting,
+			Message:            fmt.Sprintf("Waiting up to %v seconds for the components to be ready.", *seconds),
+		})
+		return
+	}
+	var deadline = condition.LastTransitionTime.Add(time.Duration(*seconds) * time.Second)
+	if exceeded || now.Before(deadline) {
+		return
+	}
+	var reason, notReady = getStartupNotReadyReasons(status)
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               v1beta1.ClusterConditionStartupDeadlineExceeded,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: cluster.Generation,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             reason,
+		Message:            fmt.Sprintf("The components were not ready within %v seconds: %v.", *seconds, notReady),
+	})
+}
+
+// getStartupNotReadyReasons returns the reason why the first component of the status is not
+// ready, and the reasons of all of them.
+func getStartupNotReadyReasons(status *v1beta1.FlinkClusterStatus) (string, string) {
+	var reason string
+	var reasons []string
+	var add = func(component string, notReadyReason string) {
+		if notReadyReason == "" {
+			return
+		}
+		if reason == "" {
+			reason = notReadyReason
+		}
+		reasons = append(reasons, component+" "+notReadyReason)
+	}
+	if jm := status.Components.JobManager; jm != nil {
+		add("JobManager", jm.NotReadyReason)
+	}
+	if tm := status.Components.TaskManager; tm != nil {
+		add("TaskManager", tm.NotReadyReason)
+	}
+	if job := status.Components.Job; job != nil {
+		add("job submitter", job.NotReadyReason)
+	}
+	if reason == "" {
+		return startupDeadlineReasonNotReady, "no reason reported by the pods"
+	}
+	return reason, strings.Join(reasons, ", ")
+}
+
+// isStartupDeadlineExceeded returns true if the components of the current generation of the
+// cluster were not ready within spec.startupDeadlineSeconds.
+func isStartupDeadlineExceeded(cluster *v1beta1.FlinkCluster) bool {
+	var condition = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.ClusterConditionStartupDeadlineExceeded)
+	return cluster.Spec.StartupDeadlineSeconds != nil && condition != nil &&
+		condition.Status == metav1.ConditionTrue && condition.ObservedGeneration == cluster.Generation
+}
+
+// getStartupDeadline returns when spec.startupDeadlineSeconds passes for the cluster being
+// created, zero if it is not tracked.
+func getStartupDeadline(cluster *v1beta1.FlinkCluster) time.Time {
+	var seconds = cluster.Spec.StartupDeadlineSeconds
+	var condition = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.ClusterConditionStartupDeadlineExceeded)
+	if seconds == nil || condition == nil || condition.Status != metav1.ConditionFalse {
+		return time.Time{}
+	}
+	return condition.LastTransitionTime.Add(time.Duration(*seconds) * time.Second)
+}
+
+// getStartupDeadlineError returns the permanent error which stops the reconciliation of the
+// cluster whose startup deadline passed, nil if the deadline did not pass or spec.startupDeadlineAction
+// is not StopReconciling.
+func getStartupDeadlineError(cluster *v1beta1.FlinkCluster) error {
+	if cluster == nil || !isStartupDeadlineExceeded(cluster) ||
+		getStartupDeadlineAction(cluster) != v1beta1.StartupDeadlineActionStopReconciling {
+		return nil
+	}
+	var condition = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.ClusterConditionStartupDeadlineExceeded)
+	return newPermanentError(fmt.Errorf("startup deadline exceeded: %v", condition.Message))
+}
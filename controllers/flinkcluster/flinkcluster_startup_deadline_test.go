@@ -0,0 +1,82 @@
+package flinkcluster
+
+import (
+	"testing"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetStartupDeadlineCondition(t *testing.T) {
+	var seconds = int32(300)
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec:       v1beta1.FlinkClusterSpec{StartupDeadlineSeconds: &seconds},
+	}
+	var status = &v1beta1.FlinkClusterStatus{
+		State: v1beta1.ClusterStateCreating,
+		Components: v1beta1.FlinkClusterComponentsStatus{
+			JobManager:  &v1beta1.JobManagerStatus{State: v1beta1.ComponentStateNotReady, NotReadyReason: "ImagePullBackOff"},
+			TaskManager: &v1beta1.TaskManagerStatus{State: v1beta1.ComponentStateNotReady, NotReadyReason: "Unschedulable"},
+		},
+	}
+	var start = time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	var getCondition = func() *metav1.Condition {
+		return meta.FindStatusCondition(status.Conditions, v1beta1.ClusterConditionStartupDeadlineExceeded)
+	}
+
+	setStartupDeadlineCondition(&status.Conditions, cluster, status, start)
+	assert.Equal(t, getCondition().Status, metav1.ConditionFalse)
+	cluster.Status = *status
+	assert.Equal(t, getStartupDeadline(cluster), start.Add(5*time.Minute))
+
+	setStartupDeadlineCondition(&status.Conditions, cluster, status, start.Add(4*time.Minute))
+	assert.Equal(t, getCondition().Status, metav1.ConditionFalse)
+
+	setStartupDeadlineCondition(&status.Conditions, cluster, status, start.Add(5*time.Minute))
+	assert.Equal(t, getCondition().Status, metav1.ConditionTrue)
+	assert.Equal(t, getCondition().Reason, "ImagePullBackOff")
+	assert.Equal(t, getCondition().Message,
+		"The components were not ready within 300 seconds: JobManager ImagePullBackOff, TaskManager Unschedulable.")
+	cluster.Status = *status
+	assert.Assert(t, isStartupDeadlineExceeded(cluster))
+	assert.Assert(t, getStartupDeadline(cluster).IsZero())
+	assert.ErrorContains(t, getStartupDeadlineError(cluster), "startup deadline exceeded")
+	assert.Equal(t, getErrorType(getStartupDeadlineError(cluster)), ErrorTypePermanent)
+
+	// The deadline restarts when the spec is updated.
+	cluster.Generation = 2
+	assert.Assert(t, !isStartupDeadlineExceeded(cluster))
+	setStartupDeadlineCondition(&status.Conditions, cluster, status, start.Add(10*time.Minute))
+	assert.Equal(t, getCondition().Status, metav1.ConditionFalse)
+	assert.Equal(t, getCondition().LastTransitionTime.Time, start.Add(10*time.Minute))
+
+	status.State = v1beta1.ClusterStateRunning
+	setStartupDeadlineCondition(&status.Conditions, cluster, status, start.Add(11*time.Minute))
+	assert.Assert(t, getCondition() == nil)
+}
+
+func TestGetStartupDeadlineErrorCleanup(t *testing.T) {
+	var seconds = int32(60)
+	var action = v1beta1.StartupDeadlineActionCleanup
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{StartupDeadlineSeconds: &seconds, StartupDeadlineAction: &action},
+		Status: v1beta1.FlinkClusterStatus{
+			State: v1beta1.ClusterStateStopping,
+			Conditions: []metav1.Condition{{
+				Type:   v1beta1.ClusterConditionStartupDeadlineExceeded,
+				Status: metav1.ConditionTrue,
+				Reason: "ImagePullBackOff",
+			}},
+		},
+	}
+	assert.Assert(t, isStartupDeadlineExceeded(cluster))
+	assert.NilError(t, getStartupDeadlineError(cluster))
+
+	// The exceeded deadline is kept while the cluster is stopped by the cleanup policy.
+	setStartupDeadlineCondition(&cluster.Status.Conditions, cluster, &cluster.Status, time.Now())
+	assert.Assert(t, isStartupDeadlineExceeded(cluster))
+}
@@ -0,0 +1,212 @@
+package flinkcluster
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	stateExportFileName = "state.yaml"
+	// The prefix of the first line of signed exports, followed by the HMAC-SHA256 of the
+	// rest of the file in hex.
+	stateExportSignaturePrefix = "# hmac-sha256: "
+	// The delay of the retry of a failed export, doubled after each consecutive failure up to
+	// the maximum.
+	stateExportRetryInterval    = 30 * time.Second
+	stateExportMaxRetryInterval = 30 * time.Minute
+)
+
+// shouldExportState returns true if spec.stateExport is set and the running revision of the
+// cluster is not exported yet. The state is not exported while an update is in progress, nor
+// before the retry delay of the last failure of the revision elapses.
+func shouldExportState(cluster *v1beta1.FlinkCluster, now time.Time) bool {
+	var status = &cluster.Status
+	if cluster.Spec.StateExport == nil || status.State != v1beta1.ClusterStateRunning ||
+		status.Revision.CurrentRevision == "" || status.Revision.IsUpdateTriggered() {
+		return false
+	}
+	var revision = getCurrentRevisionName(&status.Revision)
+	var export = status.StateExport
+	if export == nil {
+		return true
+	}
+	if export.Revision == revision {
+		return false
+	}
+	if failure := export.LastFailure; failure != nil && failure.Revision == revision {
+		return !now.Before(util.GetTime(failure.FailureTime).Add(getStateExportRetryInterval(failure.Attempts)))
+	}
+	return true
+}
+
+// getStateExportRetryInterval returns the delay of the retry after the consecutive failures.
+func getStateExportRetryInterval(attempts int32) time.Duration {
+	var interval = stateExportRetryInterval
+	for i := int32(1); i < attempts && interval < stateExportMaxRetryInterval; i++ {
+		interval *= 2
+	}
+	if interval > stateExportMaxRetryInterval {
+		return stateExportMaxRetryInterval
+	}
+	return interval
+}
+
+// getStateExportFailure returns the failure of the export of the revision following the
+// recorded one.
+func getStateExportFailure(recorded *v1beta1.StateExportStatus, revision string, err error) *v1beta1.StateExportFailure {
+	var failure = &v1beta1.StateExportFailure{Revision: revision, Attempts: 1, Message: err.Error()}
+	if recorded != nil && recorded.LastFailure != nil && recorded.LastFailure.Revision == revision {
+		failure.Attempts = recorded.LastFailure.Attempts + 1
+	}
+	util.SetTimestamp(&failure.FailureTime)
+	return failure
+}
+
+// newStateExport returns the multi-document YAML of the cluster with its status followed by
+// the resources of its desired state, signed with the key if it is not empty. The Secret of
+// the resolved Flink properties is left out, so that its values are not exported.
+func newStateExport(
+	cluster *v1beta1.FlinkCluster,
+	desired *model.DesiredClusterState,
+	scheme *runtime.Scheme,
+	signingKey string) ([]byte, error) {
+	var exported = cluster.DeepCopy()
+	exported.ManagedFields = nil
+	var objects = []client.Object{exported}
+	for _, object := range []client.Object{
+		desired.ConfigMap,
+		desired.ServiceAccount,
+		desired.Role,
+		desired.RoleBinding,
+		desired.PodDisruptionBudget,
+		desired.JmStatefulSet,
+		desired.JmService,
+		desired.JmUIService,
+		desired.JmIngress,
+		desired.TmStatefulSet,
+		desired.TmDeployment,
+		desired.TmService,
+		desired.HorizontalPodAutoscaler,
+		desired.Job,
+	} {
+		// The fields of the desired state are typed nil pointers when unset.
+		if reflect.ValueOf(object).IsNil() {
+			continue
+		}
+		objects = append(objects, object.DeepCopyObject().(client.Object))
+	}
+
+	var body bytes.Buffer
+	for i, object := range objects {
+		gvk, err := apiutil.GVKForObject(object, scheme)
+		if err != nil {
+			return nil, err
+		}
+		object.GetObjectKind().SetGroupVersionKind(gvk)
+		document, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			body.WriteString("---\n")
+		}
+		body.Write(document)
+	}
+	if signingKey == "" {
+		return body.Bytes(), nil
+	}
+	var mac = hmac.New(sha256.New, []byte(signingKey))
+	mac.Write(body.Bytes())
+	var signed = []byte(stateExportSignaturePrefix + hex.EncodeToString(mac.Sum(nil)) + "\n")
+	return append(signed, body.Bytes()...), nil
+}
+
+// Exports the state of the running revision for spec.stateExport, once for each revision. A
+// failure is recorded in the status and retried with an exponential backoff.
+func (reconciler *ClusterReconciler) reconcileStateExport(ctx context.Context) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var cluster = reconciler.observed.cluster
+	if !shouldExportState(cluster, time.Now()) {
+		return nil
+	}
+
+	var revision = getCurrentRevisionName(&cluster.Status.Revision)
+	var export = cluster.Spec.StateExport
+	var status = &v1beta1.StateExportStatus{Revision: revision, Signed: export.SigningKeySecret != nil}
+	var err error
+	status.URL, err = reconciler.uploadStateExport(ctx, export, revision)
+	if err != nil {
+		var failure = getStateExportFailure(cluster.Status.StateExport, revision, err)
+		log.Info("Failed to export cluster state, will retry", "revision", revision, "attempts", failure.Attempts,
+			"retryInterval", getStateExportRetryInterval(failure.Attempts), "error", err)
+		reconciler.recorder.Eventf(cluster, corev1.EventTypeWarning, "StateExportFailed",
+			"Failed to export the state of revision %v: %v", revision, err)
+		// The last export is kept.
+		status = &v1beta1.StateExportStatus{LastFailure: failure}
+		if recorded := cluster.Status.StateExport; recorded != nil {
+			status = recorded.DeepCopy()
+			status.LastFailure = failure
+		}
+		return reconciler.updateStateExportStatus(ctx, status)
+	}
+	util.SetTimestamp(&status.ExportTime)
+	reconciler.recorder.Eventf(cluster, corev1.EventTypeNormal, "StateExported",
+		"Exported the state of revision %v to %v", revision, status.URL)
+	return reconciler.updateStateExportStatus(ctx, status)
+}
+
+// Uploads the state to `<uri>/<namespace>/<cluster>/<revision>/state.yaml`, returns its location.
+func (reconciler *ClusterReconciler) uploadStateExport(
+	ctx context.Context, export *v1beta1.StateExportSpec, revision string) (string, error) {
+	var cluster = reconciler.observed.cluster
+	location, err := getDiagnosticsUploadLocation(export.Upload.URI, cluster.Namespace, cluster.Name, revision, stateExportFileName)
+	if err != nil {
+		return "", err
+	}
+	signingKey, err := getAuthorization(ctx, reconciler.k8sClient, cluster.Namespace, export.SigningKeySecret)
+	if err != nil {
+		return "", err
+	}
+	if export.SigningKeySecret != nil && signingKey == "" {
+		return "", fmt.Errorf("signing key %v of Secret %v is empty", export.SigningKeySecret.Key, export.SigningKeySecret.Name)
+	}
+	state, err := newStateExport(cluster, &reconciler.desired, reconciler.k8sClient.Scheme(), signingKey)
+	if err != nil {
+		return "", err
+	}
+	authorization, err := reconciler.getUploadAuthorization(ctx, &export.Upload)
+	if err != nil {
+		return "", err
+	}
+	return location, uploadDiagnosticsFile(ctx, location, authorization, state)
+}
+
+func (reconciler *ClusterReconciler) updateStateExportStatus(ctx context.Context, export *v1beta1.StateExportStatus) error {
+	var log = logr.FromContextOrDiscard(ctx)
+	var clusterClone = reconciler.observed.cluster.DeepCopy()
+	clusterClone.Status.StateExport = export
+	util.SetTimestamp(&clusterClone.Status.LastUpdateTime)
+	var err = reconciler.k8sClient.Status().Update(ctx, clusterClone)
+	if err != nil {
+		log.Error(err, "Failed to update state export status", "error", err)
+	} else {
+		log.Info("Succeeded to update state export status.", "stateExport", export)
+	}
+	return err
+}
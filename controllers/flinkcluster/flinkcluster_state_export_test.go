@@ -0,0 +1,101 @@
+package flinkcluster
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestShouldExportState(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			StateExport: &v1beta1.StateExportSpec{Upload: v1beta1.DiagnosticsUpload{URI: "gs://my-bucket/exports"}},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			State:    v1beta1.ClusterStateRunning,
+			Revision: v1beta1.RevisionStatus{CurrentRevision: "mycluster-85dc8f749-2", NextRevision: "mycluster-85dc8f749-2"},
+		},
+	}
+	var now = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.Assert(t, shouldExportState(cluster, now))
+
+	cluster.Status.StateExport = &v1beta1.StateExportStatus{Revision: "mycluster-85dc8f749"}
+	assert.Assert(t, !shouldExportState(cluster, now))
+
+	// The next revision is exported once the update finishes.
+	cluster.Status.Revision.NextRevision = "mycluster-6f9c4d7b8-3"
+	assert.Assert(t, !shouldExportState(cluster, now))
+	cluster.Status.Revision.CurrentRevision = "mycluster-6f9c4d7b8-3"
+	assert.Assert(t, shouldExportState(cluster, now))
+
+	// A failed export is retried once the retry interval of the failures elapses.
+	cluster.Status.StateExport.LastFailure = &v1beta1.StateExportFailure{
+		Revision:    "mycluster-6f9c4d7b8",
+		Attempts:    3,
+		FailureTime: "2024-03-01T12:00:00Z",
+	}
+	assert.Assert(t, !shouldExportState(cluster, now.Add(119*time.Second)))
+	assert.Assert(t, shouldExportState(cluster, now.Add(120*time.Second)))
+	// The failures of another revision do not delay the export.
+	cluster.Status.StateExport.LastFailure.Revision = "mycluster-85dc8f749"
+	assert.Assert(t, shouldExportState(cluster, now))
+
+	cluster.Status.State = v1beta1.ClusterStateReconciling
+	assert.Assert(t, !shouldExportState(cluster, now))
+}
+
+func TestGetStateExportFailure(t *testing.T) {
+	assert.Equal(t, getStateExportRetryInterval(1), 30*time.Second)
+	assert.Equal(t, getStateExportRetryInterval(2), time.Minute)
+	assert.Equal(t, getStateExportRetryInterval(7), 30*time.Minute)
+	assert.Equal(t, getStateExportRetryInterval(100), 30*time.Minute)
+
+	var failure = getStateExportFailure(nil, "mycluster-6f9c4d7b8", errors.New("access denied"))
+	assert.Equal(t, failure.Attempts, int32(1))
+	assert.Equal(t, failure.Message, "access denied")
+	assert.Assert(t, failure.FailureTime != "")
+
+	var recorded = &v1beta1.StateExportStatus{Revision: "mycluster-85dc8f749", LastFailure: failure}
+	failure = getStateExportFailure(recorded, "mycluster-6f9c4d7b8", errors.New("access denied"))
+	assert.Equal(t, failure.Attempts, int32(2))
+	failure = getStateExportFailure(recorded, "mycluster-7a1b2c3d4", errors.New("access denied"))
+	assert.Equal(t, failure.Attempts, int32(1))
+}
+
+func TestNewStateExport(t *testing.T) {
+	var observed = getObservedClusterState()
+	observed.cluster.Status.State = v1beta1.ClusterStateRunning
+	var desired = getDesiredClusterState(observed, render.Options{})
+	var scheme = runtime.NewScheme()
+	assert.NilError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NilError(t, v1beta1.AddToScheme(scheme))
+
+	state, err := newStateExport(observed.cluster, desired, scheme, "")
+	assert.NilError(t, err)
+	var documents = strings.Split(string(state), "---\n")
+	assert.Assert(t, strings.HasPrefix(documents[0], "apiVersion: flinkoperator.k8s.io/v1beta1\nkind: FlinkCluster\n"))
+	assert.Assert(t, strings.Contains(documents[0], "state: Running"))
+	var kinds []string
+	for _, document := range documents[1:] {
+		kinds = append(kinds, strings.SplitN(strings.SplitN(document, "kind: ", 2)[1], "\n", 2)[0])
+	}
+	assert.DeepEqual(t, kinds, []string{"ConfigMap", "StatefulSet", "Service", "Ingress", "StatefulSet", "Service", "Job"})
+	// The objects of the desired state are not changed.
+	assert.Equal(t, desired.JmStatefulSet.Kind, "")
+
+	signed, err := newStateExport(observed.cluster, desired, scheme, "my-key")
+	assert.NilError(t, err)
+	var mac = hmac.New(sha256.New, []byte("my-key"))
+	mac.Write(state)
+	assert.Equal(t, string(signed), "# hmac-sha256: "+hex.EncodeToString(mac.Sum(nil))+"\n"+string(state))
+}
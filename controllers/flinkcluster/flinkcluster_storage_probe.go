@@ -0,0 +1,157 @@
+package flinkcluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/cloudauth"
+	"github.com/spotify/flink-on-k8s-operator/internal/objectstore"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	storageProbeTimeout = 30 * time.Second
+	// The object written into the savepoints dir to probe it, deleted right after.
+	storageProbeObject = ".flink-operator-probe"
+
+	// Reasons of the StorageUnavailable condition.
+	storageProbeReasonSucceeded    = "ProbeSucceeded"
+	storageProbeReasonFailed       = "ProbeFailed"
+	storageProbeReasonInconclusive = "ProbeInconclusive"
+)
+
+// Client of the requests of spec.job.storageProbe, with the credentials of the operator.
+var storageProbeClient = objectstore.NewClient(storageProbeTimeout)
+
+// storageProbeResult is the result of a probe of the savepoint storage.
+type storageProbeResult struct {
+	// Why the storage is not accessible, which blocks the submission of the job.
+	failure string
+	// Why the storage could not be probed, e.g. as the operator has no credentials for it,
+	// which does not block the submission of the job.
+	warning string
+}
+
+// shouldProbeStorage returns true if spec.job.storageProbe is set, the job is about to be
+// submitted and the storage has not been probed successfully since the spec last changed.
+func shouldProbeStorage(cluster *v1beta1.FlinkCluster) bool {
+	var jobSpec = cluster.Spec.Job
+	var job = cluster.Status.Components.Job
+	if jobSpec == nil || jobSpec.StorageProbe == nil || job.IsActive() || job.IsTerminated(jobSpec) {
+		return false
+	}
+	var condition = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.ClusterConditionStorageUnavailable)
+	return condition == nil || condition.Status != metav1.ConditionFalse || condition.ObservedGeneration != cluster.Generation
+}
+
+// probeStorage checks that the savepoints dir of the job is writable and the savepoint to
+// restore the job from is readable. Locations which are not supported, or which the operator
+// cannot authenticate to, are reported as warnings.
+func probeStorage(ctx context.Context, k8sClient client.Client, cluster *v1beta1.FlinkCluster) storageProbeResult {
+	var jobSpec = cluster.Spec.Job
+	authorization, err := getAuthorization(ctx, k8sClient, cluster.Namespace, jobSpec.StorageProbe.AuthorizationSecret)
+	if err != nil {
+		return storageProbeResult{failure: fmt.Sprintf("failed to get the authorization secret: %v", err)}
+	}
+	var warnings []string
+	if savepointsDir := getSavepointsDir(cluster); savepointsDir != "" {
+		var err = probeSavepointsDir(ctx, savepointsDir, authorization)
+		if warning, failed := classifyStorageProbeError(savepointsDir, err); failed {
+			return storageProbeResult{failure: fmt.Sprintf("savepointsDir %v is not writable: %v", savepointsDir, err)}
+		} else if warning != "" {
+			warnings = append(warnings, "savepointsDir "+warning)
+		}
+	}
+	if jobSpec.FromSavepoint != nil && *jobSpec.FromSavepoint != "" {
+		var err = probeSavepoint(ctx, *jobSpec.FromSavepoint, authorization)
+		if warning, failed := classifyStorageProbeError(*jobSpec.FromSavepoint, err); failed {
+			return storageProbeResult{failure: fmt.Sprintf("fromSavepoint %v is not readable: %v", *jobSpec.FromSavepoint, err)}
+		} else if warning != "" {
+			warnings = append(warnings, "fromSavepoint "+warning)
+		}
+	}
+	return storageProbeResult{warning: strings.Join(warnings, "; ")}
+}
+
+// classifyStorageProbeError returns whether the error of the probe of the location fails the
+// probe, otherwise why the location could not be probed, if it could not.
+func classifyStorageProbeError(location string, err error) (warning string, failed bool) {
+	switch {
+	case !objectstore.IsSupported(location):
+		return fmt.Sprintf("%v was not probed as its storage is not supported", location), false
+	case err == nil:
+		return "", false
+	case errors.Is(err, cloudauth.ErrNoCredentials), errors.Is(err, objectstore.ErrAccessDenied):
+		return fmt.Sprintf("%v could not be probed with the credentials of the operator: %v", location, err), false
+	}
+	return "", true
+}
+
+// probeSavepointsDir writes and deletes an object in the savepoints dir. Failures to delete
+// it are ignored, as the job does not need to delete savepoints.
+func probeSavepointsDir(ctx context.Context, savepointsDir, authorization string) error {
+	if !objectstore.IsSupported(savepointsDir) {
+		return nil
+	}
+	var probe = strings.TrimSuffix(savepointsDir, "/") + "/" + storageProbeObject
+	if err := storageProbeClient.Put(ctx, probe, authorization, []byte("probe")); err != nil {
+		return err
+	}
+	storageProbeClient.Delete(ctx, probe, authorization)
+	return nil
+}
+
+// probeSavepoint checks that the metadata file of the savepoint exists.
+func probeSavepoint(ctx context.Context, savepoint, authorization string) error {
+	if !objectstore.IsSupported(savepoint) {
+		return nil
+	}
+	var metadata = strings.TrimSuffix(savepoint, "/")
+	if !strings.HasSuffix(metadata, "/_metadata") {
+		metadata += "/_metadata"
+	}
+	var err = storageProbeClient.Head(ctx, metadata, authorization)
+	if errors.Is(err, objectstore.ErrNotFound) {
+		return fmt.Errorf("%v does not exist", metadata)
+	}
+	return err
+}
+
+// setStorageUnavailableCondition records the result of the probe of spec.job.storageProbe with
+// the StorageUnavailable condition, which is unknown if the probe was inconclusive. The
+// condition is kept while the storage is not probed, and removed when the probe is not set.
+func setStorageUnavailableCondition(conditions *[]metav1.Condition, cluster *v1beta1.FlinkCluster, probe *storageProbeResult) {
+	if cluster.Spec.Job == nil || cluster.Spec.Job.StorageProbe == nil {
+		meta.RemoveStatusCondition(conditions, v1beta1.ClusterConditionStorageUnavailable)
+		return
+	}
+	if probe == nil {
+		return
+	}
+
+	var condition = metav1.Condition{
+		Type:               v1beta1.ClusterConditionStorageUnavailable,
+		ObservedGeneration: cluster.Generation,
+	}
+	switch {
+	case probe.failure != "":
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = storageProbeReasonFailed
+		condition.Message = probe.failure
+	case probe.warning != "":
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = storageProbeReasonInconclusive
+		condition.Message = probe.warning
+	default:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = storageProbeReasonSucceeded
+		condition.Message = "The savepoint storage of the job is accessible."
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
@@ -0,0 +1,134 @@
+package flinkcluster
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestShouldProbeStorage(t *testing.T) {
+	var savepointsDir = "gs://my-bucket/savepoints"
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec: v1beta1.FlinkClusterSpec{Job: &v1beta1.JobSpec{
+			SavepointsDir: &savepointsDir,
+			StorageProbe:  &v1beta1.StorageProbe{},
+		}},
+	}
+	assert.Assert(t, shouldProbeStorage(cluster))
+
+	// The storage is not probed again until the spec changes.
+	setStorageUnavailableCondition(&cluster.Status.Conditions, cluster, &storageProbeResult{})
+	assert.Assert(t, !shouldProbeStorage(cluster))
+	cluster.Generation = 3
+	assert.Assert(t, shouldProbeStorage(cluster))
+
+	cluster.Status.Components.Job = &v1beta1.JobStatus{State: v1beta1.JobStateRunning}
+	assert.Assert(t, !shouldProbeStorage(cluster))
+}
+
+func TestProbeStorage(t *testing.T) {
+	var written = map[string]bool{}
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Authorization") != "Bearer token":
+			w.WriteHeader(http.StatusForbidden)
+		case r.Method == http.MethodPut && r.URL.Path == "/savepoints/.flink-operator-probe":
+			written[r.URL.Path] = true
+		case r.Method == http.MethodDelete:
+			delete(written, r.URL.Path)
+		case r.Method == http.MethodHead && r.URL.Path == "/savepoints/savepoint-1/_metadata":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var savepointsDir = server.URL + "/savepoints/"
+	var fromSavepoint = server.URL + "/savepoints/savepoint-1"
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "clicks", Namespace: "default"},
+		Spec: v1beta1.FlinkClusterSpec{Job: &v1beta1.JobSpec{
+			SavepointsDir: &savepointsDir,
+			FromSavepoint: &fromSavepoint,
+			StorageProbe: &v1beta1.StorageProbe{AuthorizationSecret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "storage"},
+				Key:                  "authorization",
+			}},
+		}},
+	}
+	var secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "storage", Namespace: "default"},
+		Data:       map[string][]byte{"authorization": []byte("Bearer token")},
+	}
+	var scheme = runtime.NewScheme()
+	assert.NilError(t, corev1.AddToScheme(scheme))
+	var k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	assert.DeepEqual(t, probeStorage(context.TODO(), k8sClient, cluster), storageProbeResult{}, cmp.AllowUnexported(storageProbeResult{}))
+	assert.Equal(t, len(written), 0)
+
+	var missingSavepoint = server.URL + "/savepoints/savepoint-2"
+	cluster.Spec.Job.FromSavepoint = &missingSavepoint
+	var result = probeStorage(context.TODO(), k8sClient, cluster)
+	assert.Assert(t, strings.Contains(result.failure, "fromSavepoint "+missingSavepoint+" is not readable"))
+	assert.Assert(t, strings.Contains(result.failure, "_metadata does not exist"))
+
+	var readOnlyDir = server.URL + "/read-only"
+	cluster.Spec.Job.SavepointsDir = &readOnlyDir
+	result = probeStorage(context.TODO(), k8sClient, cluster)
+	assert.Assert(t, strings.Contains(result.failure, "savepointsDir "+readOnlyDir+" is not writable"))
+
+	// Storage which rejects the credentials of the operator does not block the job.
+	secret.Data["authorization"] = []byte("Bearer other")
+	assert.NilError(t, k8sClient.Update(context.TODO(), secret))
+	result = probeStorage(context.TODO(), k8sClient, cluster)
+	assert.Equal(t, result.failure, "")
+	assert.Assert(t, strings.Contains(result.warning, "could not be probed with the credentials of the operator"))
+
+	// Locations on other storage are not probed.
+	var hdfsDir = "hdfs://namenode/savepoints"
+	cluster.Spec.Job.SavepointsDir = &hdfsDir
+	cluster.Spec.Job.FromSavepoint = nil
+	result = probeStorage(context.TODO(), k8sClient, cluster)
+	assert.Equal(t, result.failure, "")
+	assert.Equal(t, result.warning, "savepointsDir hdfs://namenode/savepoints was not probed as its storage is not supported")
+}
+
+func TestSetStorageUnavailableCondition(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec:       v1beta1.FlinkClusterSpec{Job: &v1beta1.JobSpec{StorageProbe: &v1beta1.StorageProbe{}}},
+	}
+	var conditions []metav1.Condition
+	var failure = "savepointsDir gs://my-bucket/savepoints is not writable: PUT gs://my-bucket/savepoints/.flink-operator-probe: not found"
+	setStorageUnavailableCondition(&conditions, cluster, &storageProbeResult{failure: failure})
+	var condition = meta.FindStatusCondition(conditions, v1beta1.ClusterConditionStorageUnavailable)
+	assert.Equal(t, condition.Status, metav1.ConditionTrue)
+	assert.Equal(t, condition.Message, failure)
+
+	// The condition is kept while the storage is not probed.
+	setStorageUnavailableCondition(&conditions, cluster, nil)
+	assert.Equal(t, meta.FindStatusCondition(conditions, v1beta1.ClusterConditionStorageUnavailable).Status, metav1.ConditionTrue)
+
+	setStorageUnavailableCondition(&conditions, cluster, &storageProbeResult{warning: "savepointsDir hdfs://namenode/savepoints was not probed"})
+	assert.Equal(t, meta.FindStatusCondition(conditions, v1beta1.ClusterConditionStorageUnavailable).Status, metav1.ConditionUnknown)
+
+	setStorageUnavailableCondition(&conditions, cluster, &storageProbeResult{})
+	assert.Equal(t, meta.FindStatusCondition(conditions, v1beta1.ClusterConditionStorageUnavailable).Status, metav1.ConditionFalse)
+
+	cluster.Spec.Job.StorageProbe = nil
+	setStorageUnavailableCondition(&conditions, cluster, nil)
+	assert.Assert(t, meta.FindStatusCondition(conditions, v1beta1.ClusterConditionStorageUnavailable) == nil)
+}
@@ -0,0 +1,135 @@
+package flinkcluster
+
+import (
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+)
+
+// The phases of the update in the order they are reached.
+var updatePhases = []v1beta1.UpdatePhase{
+	v1beta1.UpdatePhaseSavepointTriggered,
+	v1beta1.UpdatePhaseJobStopped,
+	v1beta1.UpdatePhaseResourcesUpdated,
+	v1beta1.UpdatePhaseJobResubmitted,
+}
+
+func getUpdatePhaseIndex(phase v1beta1.UpdatePhase) int {
+	for i, p := range updatePhases {
+		if p == phase {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasReachedUpdatePhase returns true if the update in the phase has reached the target phase.
+func hasReachedUpdatePhase(phase, target v1beta1.UpdatePhase) bool {
+	return phase != "" && getUpdatePhaseIndex(phase) >= getUpdatePhaseIndex(target)
+}
+
+// carryOverUpdatePhase returns the recorded phase of the update to the next revision. When
+// the next revision changes during the update, the job stopped for the update stays stopped
+// for the new revision, while the job already submitted is stopped again.
+func carryOverUpdatePhase(recorded *v1beta1.RevisionStatus, nextRevision string) v1beta1.UpdatePhase {
+	var phase = recorded.UpdatePhase
+	if recorded.NextRevision == nextRevision {
+		return phase
+	}
+	if hasReachedUpdatePhase(phase, v1beta1.UpdatePhaseJobStopped) &&
+		!hasReachedUpdatePhase(phase, v1beta1.UpdatePhaseJobResubmitted) {
+		return v1beta1.UpdatePhaseJobStopped
+	}
+	return ""
+}
+
+// getRecordedUpdatePhase returns the phase the update to the observed next revision reached
+// before this reconciliation, which the update resumes from.
+func getRecordedUpdatePhase(observed *ObservedClusterState) v1beta1.UpdatePhase {
+	var recorded = &observed.cluster.Status.Revision
+	if observed.revision.nextRevision == nil {
+		return recorded.UpdatePhase
+	}
+	return carryOverUpdatePhase(recorded, util.GetRevisionWithNameNumber(observed.revision.nextRevision))
+}
+
+// isJobStoppedForUpdate returns true if the job of the current revision was stopped for the
+// update to the next revision.
+func isJobStoppedForUpdate(observed *ObservedClusterState) bool {
+	return hasReachedUpdatePhase(getRecordedUpdatePhase(observed), v1beta1.UpdatePhaseJobStopped)
+}
+
+// isNextJobSubmittedForUpdate returns true if the job of the next revision was submitted for
+// the update to the next revision.
+func isNextJobSubmittedForUpdate(observed *ObservedClusterState) bool {
+	return hasReachedUpdatePhase(getRecordedUpdatePhase(observed), v1beta1.UpdatePhaseJobResubmitted)
+}
+
+// getJobUpdateState returns the state of an update which changes the job, driven by the
+// recorded phase of the update: the update is prepared until the savepoint of the running
+// job is ready, and is in progress until the job is stopped, the components are updated and
+// the job of the next revision is submitted. The status is updated before any action is
+// taken, so the actions of a reconciliation follow the recorded phase.
+func getJobUpdateState(observed *ObservedClusterState) UpdateState {
+	var cluster = observed.cluster
+	switch phase := getRecordedUpdatePhase(observed); {
+	case !hasReachedUpdatePhase(phase, v1beta1.UpdatePhaseJobStopped):
+		if !cluster.Status.Components.Job.UpdateReady(cluster.Spec.Job, observed.observeTime) {
+			return UpdateStatePreparing
+		}
+		return UpdateStateInProgress
+	case !hasReachedUpdatePhase(phase, v1beta1.UpdatePhaseJobResubmitted):
+		return UpdateStateInProgress
+	case render.IsCanaryUpdatePending(cluster) || !isClusterUpdateToDate(observed):
+		return UpdateStateInProgress
+	}
+	return UpdateStateFinished
+}
+
+// isJobResubmitted returns true if the job submitter of the next revision is observed.
+func isJobResubmitted(observed *ObservedClusterState) bool {
+	var submitter = observed.flinkJobSubmitter.job
+	return submitter != nil && isComponentUpdated(submitter, observed.cluster)
+}
+
+// deriveUpdatePhase advances the phase of the update to the next revision. The phases are
+// only moved forward so that the update does not go back to a step it has already passed,
+// e.g. the job is not stopped again once the job of the next revision is submitted.
+func deriveUpdatePhase(
+	observed *ObservedClusterState,
+	revision *v1beta1.RevisionStatus,
+	job *v1beta1.JobStatus,
+	savepoint *v1beta1.SavepointStatus,
+) v1beta1.UpdatePhase {
+	if !revision.IsUpdateTriggered() {
+		return ""
+	}
+	var phase = carryOverUpdatePhase(&observed.cluster.Status.Revision, revision.NextRevision)
+	var updateState = observed.updateState
+	if updateState != UpdateStatePreparing && updateState != UpdateStateInProgress {
+		return phase
+	}
+
+	var jobUpdate = isJobUpdate(observed.revisions, observed.cluster)
+	if jobUpdate {
+		if phase == "" && job != nil && savepoint != nil && savepoint.JobID == job.ID &&
+			savepoint.TriggerReason == v1beta1.SavepointReasonUpdate {
+			phase = v1beta1.UpdatePhaseSavepointTriggered
+		}
+		if !hasReachedUpdatePhase(phase, v1beta1.UpdatePhaseJobStopped) &&
+			updateState == UpdateStateInProgress && !job.IsActive() {
+			phase = v1beta1.UpdatePhaseJobStopped
+		}
+		if !hasReachedUpdatePhase(phase, v1beta1.UpdatePhaseJobStopped) {
+			return phase
+		}
+	}
+	if !hasReachedUpdatePhase(phase, v1beta1.UpdatePhaseResourcesUpdated) &&
+		!render.IsCanaryUpdatePending(observed.cluster) && isClusterUpdateToDate(observed) {
+		phase = v1beta1.UpdatePhaseResourcesUpdated
+	}
+	if jobUpdate && phase == v1beta1.UpdatePhaseResourcesUpdated && isJobResubmitted(observed) {
+		phase = v1beta1.UpdatePhaseJobResubmitted
+	}
+	return phase
+}
@@ -0,0 +1,129 @@
+package flinkcluster
+
+import (
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newJobUpdateObservedState() *ObservedClusterState {
+	var currentLabels = map[string]string{render.RevisionNameLabel: "cluster-85dc8f749"}
+	return &ObservedClusterState{
+		cluster: &v1beta1.FlinkCluster{
+			Spec: v1beta1.FlinkClusterSpec{
+				TaskManager: &v1beta1.TaskManagerSpec{DeploymentType: v1beta1.DeploymentTypeStatefulSet},
+				Job:         &v1beta1.JobSpec{},
+			},
+			Status: v1beta1.FlinkClusterStatus{
+				Components: v1beta1.FlinkClusterComponentsStatus{Job: &v1beta1.JobStatus{ID: "aaa", State: v1beta1.JobStateRunning}},
+				Revision:   v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-aa5e3a87z-3"},
+			},
+		},
+		revisions: []*appsv1.ControllerRevision{
+			{Revision: 2, Data: runtime.RawExtension{Raw: []byte(`{"spec":{"job":{"jarFile":"v1.jar"}}}`)}},
+			{Revision: 3, Data: runtime.RawExtension{Raw: []byte(`{"spec":{"job":{"jarFile":"v2.jar"}}}`)}},
+		},
+		flinkJobSubmitter: FlinkJobSubmitter{job: &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Labels: currentLabels}}},
+		configMap:         &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: currentLabels}},
+		jmStatefulSet:     &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: currentLabels}},
+		tmStatefulSet:     &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: currentLabels}},
+		jmService:         &corev1.Service{ObjectMeta: metav1.ObjectMeta{Labels: currentLabels}},
+		tmService:         &corev1.Service{ObjectMeta: metav1.ObjectMeta{Labels: currentLabels}},
+	}
+}
+
+// Updates the components of the observed state to the next revision.
+func updateObservedComponents(observed *ObservedClusterState) {
+	for _, component := range getUpdatedComponents(observed) {
+		component.SetLabels(map[string]string{render.RevisionNameLabel: "cluster-aa5e3a87z"})
+	}
+}
+
+func TestDeriveUpdatePhase(t *testing.T) {
+	var observed = newJobUpdateObservedState()
+	var revision = observed.cluster.Status.Revision
+	var job = observed.cluster.Status.Components.Job
+	var nextLabels = map[string]string{render.RevisionNameLabel: "cluster-aa5e3a87z"}
+	var derive = func(savepoint *v1beta1.SavepointStatus) v1beta1.UpdatePhase {
+		observed.cluster.Status.Revision.UpdatePhase = deriveUpdatePhase(observed, &revision, job, savepoint)
+		return observed.cluster.Status.Revision.UpdatePhase
+	}
+
+	// The update is deferred.
+	observed.updateState = UpdateStateNoUpdate
+	assert.Equal(t, derive(nil), v1beta1.UpdatePhase(""))
+
+	observed.updateState = UpdateStatePreparing
+	assert.Equal(t, derive(nil), v1beta1.UpdatePhase(""))
+	var savepoint = &v1beta1.SavepointStatus{JobID: "aaa", TriggerReason: v1beta1.SavepointReasonUpdate}
+	assert.Equal(t, derive(savepoint), v1beta1.UpdatePhaseSavepointTriggered)
+
+	observed.updateState = UpdateStateInProgress
+	job.State = v1beta1.JobStateUpdating
+	assert.Equal(t, derive(savepoint), v1beta1.UpdatePhaseJobStopped)
+
+	updateObservedComponents(observed)
+	assert.Equal(t, derive(savepoint), v1beta1.UpdatePhaseResourcesUpdated)
+
+	observed.flinkJobSubmitter.job.Labels = nextLabels
+	job.State = v1beta1.JobStateDeploying
+	assert.Equal(t, derive(savepoint), v1beta1.UpdatePhaseJobResubmitted)
+
+	// The phase is not moved back by the job of the next revision.
+	assert.Equal(t, derive(nil), v1beta1.UpdatePhaseJobResubmitted)
+
+	// The update finished.
+	revision.CurrentRevision = revision.NextRevision
+	assert.Equal(t, derive(nil), v1beta1.UpdatePhase(""))
+}
+
+func TestCarryOverUpdatePhase(t *testing.T) {
+	var recorded = &v1beta1.RevisionStatus{NextRevision: "cluster-aa5e3a87z-3", UpdatePhase: v1beta1.UpdatePhaseResourcesUpdated}
+	assert.Equal(t, carryOverUpdatePhase(recorded, "cluster-aa5e3a87z-3"), v1beta1.UpdatePhaseResourcesUpdated)
+
+	// The stopped job is not stopped again for the new revision.
+	assert.Equal(t, carryOverUpdatePhase(recorded, "cluster-bb6f4b98a-4"), v1beta1.UpdatePhaseJobStopped)
+
+	// The submitted job and the job with a pending savepoint are stopped for the new revision.
+	recorded.UpdatePhase = v1beta1.UpdatePhaseJobResubmitted
+	assert.Equal(t, carryOverUpdatePhase(recorded, "cluster-bb6f4b98a-4"), v1beta1.UpdatePhase(""))
+	recorded.UpdatePhase = v1beta1.UpdatePhaseSavepointTriggered
+	assert.Equal(t, carryOverUpdatePhase(recorded, "cluster-bb6f4b98a-4"), v1beta1.UpdatePhase(""))
+}
+
+func TestGetUpdateStateResumesFromUpdatePhase(t *testing.T) {
+	var observed = newJobUpdateObservedState()
+	var status = &observed.cluster.Status
+	var nextLabels = map[string]string{render.RevisionNameLabel: "cluster-aa5e3a87z"}
+
+	// The running job is stopped with a savepoint first.
+	assert.Equal(t, getUpdateState(observed), UpdateStatePreparing)
+	assert.Assert(t, isStoppingJobForUpdate(observed))
+
+	// The savepoint of the stopped job is not checked again after the operator restarted.
+	status.Components.Job.State = v1beta1.JobStateUpdating
+	assert.Equal(t, getUpdateState(observed), UpdateStatePreparing)
+	status.Revision.UpdatePhase = v1beta1.UpdatePhaseJobStopped
+	assert.Equal(t, getUpdateState(observed), UpdateStateInProgress)
+	assert.Assert(t, shouldUpdateCluster(&ObservedClusterState{
+		cluster: observed.cluster, revisions: observed.revisions, updateState: UpdateStateInProgress}))
+
+	// The update is in progress until the job of the next revision is submitted.
+	updateObservedComponents(observed)
+	status.Revision.UpdatePhase = v1beta1.UpdatePhaseResourcesUpdated
+	assert.Equal(t, getUpdateState(observed), UpdateStateInProgress)
+
+	// The job of the next revision is not stopped for the update.
+	observed.flinkJobSubmitter.job.Labels = nextLabels
+	status.Components.Job.State = v1beta1.JobStateDeploying
+	assert.Assert(t, !isStoppingJobForUpdate(observed))
+	status.Revision.UpdatePhase = v1beta1.UpdatePhaseJobResubmitted
+	assert.Equal(t, getUpdateState(observed), UpdateStateFinished)
+}
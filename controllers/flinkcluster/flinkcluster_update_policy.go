@@ -0,0 +1,216 @@
+package flinkcluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Reasons of the PendingUpdate condition.
+const (
+	updatePolicyReasonOutsideWindow   = "OutsideUpdateWindow"
+	updatePolicyReasonDebouncing      = "Debouncing"
+	updatePolicyReasonNoPendingUpdate = "NoPendingUpdate"
+)
+
+// hasUpdatePolicy returns true if spec.updatePolicy can defer the updates of the cluster.
+func hasUpdatePolicy(cluster *v1beta1.FlinkCluster) bool {
+	var policy = cluster.Spec.UpdatePolicy
+	return policy != nil && (policy.Window != nil || policy.DebounceSeconds != nil)
+}
+
+// getUpdateWindowLocation returns the time zone of the schedule of the update window, the
+// time zone of the window, else the time zone of the cluster, else UTC.
+func getUpdateWindowLocation(cluster *v1beta1.FlinkCluster) *time.Location {
+	for _, timezone := range []*string{cluster.Spec.UpdatePolicy.Window.Timezone, cluster.Spec.Timezone} {
+		if timezone != nil {
+			if loc, err := time.LoadLocation(*timezone); err == nil {
+				return loc
+			}
+		}
+	}
+	return time.UTC
+}
+
+// isInUpdateWindow returns true if the window is open at the time, i.e. the schedule has an
+// opening within the duration of the window before it, in the time zone of the schedule. A
+// schedule which cannot be parsed, as the validating webhook is disabled, does not defer the
+// updates.
+func isInUpdateWindow(window *v1beta1.UpdateWindow, loc *time.Location, now time.Time) bool {
+	schedule, err := util.ParseCronSchedule(window.Schedule)
+	if err != nil {
+		return true
+	}
+	var t = now.In(loc)
+	var duration = time.Duration(window.DurationSeconds) * time.Second
+	for start := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location()); now.Sub(start) < duration; start = start.Add(-time.Minute) {
+		if schedule.Matches(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// getNextUpdateWindow returns the next opening of the window after the time, the zero time
+// if there is none.
+func getNextUpdateWindow(window *v1beta1.UpdateWindow, loc *time.Location, now time.Time) time.Time {
+	schedule, err := util.ParseCronSchedule(window.Schedule)
+	if err != nil {
+		return time.Time{}
+	}
+	return schedule.Next(now.In(loc))
+}
+
+// isUpdateDebouncing returns true if the next revision changed within
+// spec.updatePolicy.debounceSeconds before the time.
+func isUpdateDebouncing(policy *v1beta1.UpdatePolicy, revision *v1beta1.RevisionStatus, now time.Time) bool {
+	return policy.DebounceSeconds != nil && revision.NextRevisionTime != "" &&
+		!util.HasTimeElapsed(revision.NextRevisionTime, now, int(*policy.DebounceSeconds))
+}
+
+// isUpdateDeferred returns true if the update to the next revision has not started yet and
+// is deferred by spec.updatePolicy, as the spec changed within the debounce period or the
+// update window is closed.
+func isUpdateDeferred(cluster *v1beta1.FlinkCluster, revision *v1beta1.RevisionStatus, now time.Time) bool {
+	if !hasUpdatePolicy(cluster) || !revision.IsUpdateTriggered() || revision.UpdateStartTime != "" {
+		return false
+	}
+	var policy = cluster.Spec.UpdatePolicy
+	return isUpdateDebouncing(policy, revision, now) ||
+		(policy.Window != nil && !isInUpdateWindow(policy.Window, getUpdateWindowLocation(cluster), now))
+}
+
+// deriveNextRevisionTime returns the time when the next revision last changed, which starts
+// the debounce period of spec.updatePolicy.debounceSeconds.
+func deriveNextRevisionTime(
+	cluster *v1beta1.FlinkCluster,
+	revision *v1beta1.RevisionStatus,
+	recorded *v1beta1.RevisionStatus,
+	now time.Time) string {
+	var policy = cluster.Spec.UpdatePolicy
+	switch {
+	case policy == nil || policy.DebounceSeconds == nil || !revision.IsUpdateTriggered():
+		return ""
+	case revision.NextRevision == recorded.NextRevision && recorded.NextRevisionTime != "":
+		return recorded.NextRevisionTime
+	}
+	var tc = &util.TimeConverter{}
+	return tc.ToString(now)
+}
+
+// deriveUpdateStartTime returns the time when the update to the next revision started once
+// spec.updatePolicy no longer deferred it. It is kept until the update finishes, including
+// when the spec changes again during the update, so that the update is not interrupted.
+func deriveUpdateStartTime(
+	cluster *v1beta1.FlinkCluster,
+	revision *v1beta1.RevisionStatus,
+	recorded *v1beta1.RevisionStatus,
+	now time.Time) string {
+	switch {
+	case !hasUpdatePolicy(cluster) || !revision.IsUpdateTriggered():
+		return ""
+	case recorded.UpdateStartTime != "":
+		return recorded.UpdateStartTime
+	case !isUpdateDeferred(cluster, revision, now):
+		var tc = &util.TimeConverter{}
+		return tc.ToString(now)
+	}
+	return ""
+}
+
+// isIgnoredUpdate returns true if the next revision differs from the current revision only in
+// the fields of spec.updatePolicy.ignoreFields, so that it becomes the current revision
+// without an update.
+func isIgnoredUpdate(cluster *v1beta1.FlinkCluster, revision *Revision) bool {
+	var policy = cluster.Spec.UpdatePolicy
+	if policy == nil || len(policy.IgnoreFields) == 0 ||
+		revision.currentRevision == nil || revision.nextRevision == nil ||
+		revision.currentRevision.Name == revision.nextRevision.Name {
+		return false
+	}
+
+	var current, next map[string]any
+	if json.Unmarshal(revision.currentRevision.Data.Raw, &current) != nil ||
+		json.Unmarshal(revision.nextRevision.Data.Raw, &next) != nil {
+		return false
+	}
+	for _, path := range policy.IgnoreFields {
+		var fields = append([]string{"spec"}, strings.Split(path, ".")...)
+		unstructured.RemoveNestedField(current, fields...)
+		unstructured.RemoveNestedField(next, fields...)
+	}
+	return reflect.DeepEqual(current, next)
+}
+
+// getReplacedNextRevision returns the recorded next revision if its update was deferred by
+// spec.updatePolicy and has not started before the spec changed to the new next revision.
+// It is removed from the revision history so that the history reflects the updates applied.
+func getReplacedNextRevision(
+	cluster *v1beta1.FlinkCluster,
+	revisions []*appsv1.ControllerRevision,
+	nextRevision *appsv1.ControllerRevision) *appsv1.ControllerRevision {
+	var recorded = &cluster.Status.Revision
+	if !hasUpdatePolicy(cluster) || !recorded.IsUpdateTriggered() || recorded.UpdateStartTime != "" {
+		return nil
+	}
+	var name = render.NextRevisionName(recorded)
+	if name == nextRevision.Name || name == getCurrentRevisionName(recorded) {
+		return nil
+	}
+	for _, revision := range revisions {
+		if revision.Name == name {
+			return revision
+		}
+	}
+	return nil
+}
+
+// setPendingUpdateCondition sets the PendingUpdate condition of the cluster while the update
+// to the next revision is deferred, or removes it if spec.updatePolicy is not set.
+func setPendingUpdateCondition(
+	conditions *[]metav1.Condition,
+	cluster *v1beta1.FlinkCluster,
+	revision *v1beta1.RevisionStatus,
+	now time.Time) {
+	if !hasUpdatePolicy(cluster) {
+		meta.RemoveStatusCondition(conditions, v1beta1.ClusterConditionPendingUpdate)
+		return
+	}
+
+	var policy = cluster.Spec.UpdatePolicy
+	var condition = metav1.Condition{
+		Type:               v1beta1.ClusterConditionPendingUpdate,
+		ObservedGeneration: cluster.Generation,
+	}
+	switch {
+	case !isUpdateDeferred(cluster, revision, now):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = updatePolicyReasonNoPendingUpdate
+		condition.Message = "No update is deferred."
+	case isUpdateDebouncing(policy, revision, now):
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = updatePolicyReasonDebouncing
+		condition.Message = fmt.Sprintf(
+			"The update to revision %v is deferred until the spec is unchanged for %v seconds.",
+			revision.NextRevision, *policy.DebounceSeconds)
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = updatePolicyReasonOutsideWindow
+		condition.Message = fmt.Sprintf("The update to revision %v is deferred until the update window opens", revision.NextRevision)
+		if next := getNextUpdateWindow(policy.Window, getUpdateWindowLocation(cluster), now); !next.IsZero() {
+			condition.Message += " at " + next.Format(time.RFC3339)
+		}
+		condition.Message += "."
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
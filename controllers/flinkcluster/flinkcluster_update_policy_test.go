@@ -0,0 +1,243 @@
+package flinkcluster
+
+import (
+	"testing"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsInUpdateWindow(t *testing.T) {
+	// Saturdays from 02:00 for 2 hours.
+	var window = &v1beta1.UpdateWindow{Schedule: "0 2 * * 6", DurationSeconds: 7200}
+	assert.Assert(t, !isInUpdateWindow(window, time.UTC, time.Date(2022, 5, 7, 1, 59, 59, 0, time.UTC)))
+	assert.Assert(t, isInUpdateWindow(window, time.UTC, time.Date(2022, 5, 7, 2, 0, 0, 0, time.UTC)))
+	assert.Assert(t, isInUpdateWindow(window, time.UTC, time.Date(2022, 5, 7, 3, 59, 59, 0, time.UTC)))
+	assert.Assert(t, !isInUpdateWindow(window, time.UTC, time.Date(2022, 5, 7, 4, 0, 0, 0, time.UTC)))
+	assert.Assert(t, !isInUpdateWindow(window, time.UTC, time.Date(2022, 5, 8, 2, 30, 0, 0, time.UTC)))
+
+	// The window spans midnight.
+	window = &v1beta1.UpdateWindow{Schedule: "0 23 * * 6", DurationSeconds: 7200}
+	assert.Assert(t, isInUpdateWindow(window, time.UTC, time.Date(2022, 5, 8, 0, 30, 0, 0, time.UTC)))
+
+	// The schedule is in the given time zone.
+	stockholm, _ := time.LoadLocation("Europe/Stockholm")
+	window = &v1beta1.UpdateWindow{Schedule: "0 2 * * 6", DurationSeconds: 3600}
+	assert.Assert(t, isInUpdateWindow(window, stockholm, time.Date(2022, 5, 7, 0, 30, 0, 0, time.UTC)))
+	assert.Assert(t, !isInUpdateWindow(window, stockholm, time.Date(2022, 5, 7, 2, 30, 0, 0, time.UTC)))
+
+	// Invalid schedules do not defer updates.
+	window = &v1beta1.UpdateWindow{Schedule: "0 2 * *", DurationSeconds: 3600}
+	assert.Assert(t, isInUpdateWindow(window, time.UTC, time.Date(2022, 5, 4, 13, 0, 0, 0, time.UTC)))
+}
+
+func TestGetUpdateWindowLocation(t *testing.T) {
+	var windowTimezone, clusterTimezone = "Europe/Stockholm", "America/New_York"
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			UpdatePolicy: &v1beta1.UpdatePolicy{
+				Window: &v1beta1.UpdateWindow{Schedule: "0 2 * * 6", DurationSeconds: 7200, Timezone: &windowTimezone},
+			},
+			Timezone: &clusterTimezone,
+		},
+	}
+	assert.Equal(t, getUpdateWindowLocation(cluster).String(), "Europe/Stockholm")
+
+	// The schedule falls back to the time zone of the cluster, then UTC.
+	cluster.Spec.UpdatePolicy.Window.Timezone = nil
+	assert.Equal(t, getUpdateWindowLocation(cluster).String(), "America/New_York")
+	cluster.Spec.Timezone = nil
+	assert.Equal(t, getUpdateWindowLocation(cluster), time.UTC)
+}
+
+func TestIsUpdateDeferred(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			UpdatePolicy: &v1beta1.UpdatePolicy{
+				Window: &v1beta1.UpdateWindow{Schedule: "0 2 * * 6", DurationSeconds: 7200},
+			},
+		},
+	}
+	var revision = &v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-aa5e3a87z-3"}
+	var closed = time.Date(2022, 5, 4, 13, 0, 0, 0, time.UTC)
+	var open = time.Date(2022, 5, 7, 2, 30, 0, 0, time.UTC)
+
+	assert.Assert(t, isUpdateDeferred(cluster, revision, closed))
+	assert.Assert(t, !isUpdateDeferred(cluster, revision, open))
+
+	// The update started in the window is not interrupted when the window closes.
+	revision.UpdateStartTime = "2022-05-07T02:30:00Z"
+	assert.Assert(t, !isUpdateDeferred(cluster, revision, closed))
+
+	revision.UpdateStartTime = ""
+	revision.NextRevision = "cluster-85dc8f749-2"
+	assert.Assert(t, !isUpdateDeferred(cluster, revision, closed))
+
+	revision.NextRevision = "cluster-aa5e3a87z-3"
+	cluster.Spec.UpdatePolicy = nil
+	assert.Assert(t, !isUpdateDeferred(cluster, revision, closed))
+}
+
+func TestDeriveUpdateStartTime(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			UpdatePolicy: &v1beta1.UpdatePolicy{
+				Window: &v1beta1.UpdateWindow{Schedule: "0 2 * * 6", DurationSeconds: 7200},
+			},
+		},
+	}
+	var revision = &v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-aa5e3a87z-3"}
+	var recorded = &v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-aa5e3a87z-3"}
+	var closed = time.Date(2022, 5, 4, 13, 0, 0, 0, time.UTC)
+	var open = time.Date(2022, 5, 7, 2, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, deriveUpdateStartTime(cluster, revision, recorded, closed), "")
+	assert.Equal(t, deriveUpdateStartTime(cluster, revision, recorded, open), "2022-05-07T02:30:00Z")
+
+	// The start time is kept until the update finishes.
+	recorded.UpdateStartTime = "2022-05-07T02:30:00Z"
+	revision.NextRevision = "cluster-bb6f4b98a-4"
+	assert.Equal(t, deriveUpdateStartTime(cluster, revision, recorded, closed), "2022-05-07T02:30:00Z")
+
+	revision.CurrentRevision = "cluster-bb6f4b98a-4"
+	assert.Equal(t, deriveUpdateStartTime(cluster, revision, recorded, closed), "")
+}
+
+func TestSetPendingUpdateCondition(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Generation: 3},
+		Spec: v1beta1.FlinkClusterSpec{
+			UpdatePolicy: &v1beta1.UpdatePolicy{
+				Window: &v1beta1.UpdateWindow{Schedule: "0 2 * * 6", DurationSeconds: 7200},
+			},
+		},
+	}
+	var revision = &v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-aa5e3a87z-3"}
+	var conditions []metav1.Condition
+
+	setPendingUpdateCondition(&conditions, cluster, revision, time.Date(2022, 5, 4, 13, 0, 0, 0, time.UTC))
+	var condition = meta.FindStatusCondition(conditions, v1beta1.ClusterConditionPendingUpdate)
+	assert.Equal(t, condition.Status, metav1.ConditionTrue)
+	assert.Equal(t, condition.Reason, updatePolicyReasonOutsideWindow)
+	assert.Equal(t, condition.ObservedGeneration, int64(3))
+	assert.Equal(t, condition.Message,
+		"The update to revision cluster-aa5e3a87z-3 is deferred until the update window opens at 2022-05-07T02:00:00Z.")
+
+	setPendingUpdateCondition(&conditions, cluster, revision, time.Date(2022, 5, 7, 2, 30, 0, 0, time.UTC))
+	condition = meta.FindStatusCondition(conditions, v1beta1.ClusterConditionPendingUpdate)
+	assert.Equal(t, condition.Status, metav1.ConditionFalse)
+	assert.Equal(t, condition.Reason, updatePolicyReasonNoPendingUpdate)
+
+	cluster.Spec.UpdatePolicy = nil
+	setPendingUpdateCondition(&conditions, cluster, revision, time.Date(2022, 5, 4, 13, 0, 0, 0, time.UTC))
+	assert.Assert(t, meta.FindStatusCondition(conditions, v1beta1.ClusterConditionPendingUpdate) == nil)
+}
+
+func TestUpdateDebounce(t *testing.T) {
+	var debounceSeconds int32 = 300
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			UpdatePolicy: &v1beta1.UpdatePolicy{DebounceSeconds: &debounceSeconds},
+		},
+	}
+	var recorded = &v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-85dc8f749-2"}
+	var revision = &v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-aa5e3a87z-3"}
+	var now = time.Date(2022, 5, 4, 13, 0, 0, 0, time.UTC)
+
+	// The debounce period starts when the next revision changes.
+	revision.NextRevisionTime = deriveNextRevisionTime(cluster, revision, recorded, now)
+	assert.Equal(t, revision.NextRevisionTime, "2022-05-04T13:00:00Z")
+	assert.Equal(t, deriveUpdateStartTime(cluster, revision, recorded, now), "")
+	assert.Assert(t, isUpdateDeferred(cluster, revision, now.Add(299*time.Second)))
+
+	// Another change restarts the debounce period.
+	recorded = revision.DeepCopy()
+	revision = &v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-bb6f4b98a-4"}
+	revision.NextRevisionTime = deriveNextRevisionTime(cluster, revision, recorded, now.Add(200*time.Second))
+	assert.Equal(t, revision.NextRevisionTime, "2022-05-04T13:03:20Z")
+	assert.Assert(t, isUpdateDeferred(cluster, revision, now.Add(400*time.Second)))
+
+	// The update starts after the quiet period.
+	recorded = revision.DeepCopy()
+	revision.NextRevisionTime = deriveNextRevisionTime(cluster, revision, recorded, now.Add(501*time.Second))
+	assert.Equal(t, revision.NextRevisionTime, "2022-05-04T13:03:20Z")
+	assert.Assert(t, !isUpdateDeferred(cluster, revision, now.Add(501*time.Second)))
+	assert.Equal(t, deriveUpdateStartTime(cluster, revision, recorded, now.Add(501*time.Second)), "2022-05-04T13:08:21Z")
+
+	var conditions []metav1.Condition
+	setPendingUpdateCondition(&conditions, cluster, revision, now.Add(400*time.Second))
+	var condition = meta.FindStatusCondition(conditions, v1beta1.ClusterConditionPendingUpdate)
+	assert.Equal(t, condition.Status, metav1.ConditionTrue)
+	assert.Equal(t, condition.Reason, updatePolicyReasonDebouncing)
+	assert.Equal(t, condition.Message,
+		"The update to revision cluster-bb6f4b98a-4 is deferred until the spec is unchanged for 300 seconds.")
+}
+
+func TestGetReplacedNextRevision(t *testing.T) {
+	var debounceSeconds int32 = 300
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			UpdatePolicy: &v1beta1.UpdatePolicy{DebounceSeconds: &debounceSeconds},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-aa5e3a87z-3"},
+		},
+	}
+	var revisions = []*appsv1.ControllerRevision{
+		{ObjectMeta: metav1.ObjectMeta{Name: "cluster-85dc8f749"}, Revision: 2},
+		{ObjectMeta: metav1.ObjectMeta{Name: "cluster-aa5e3a87z"}, Revision: 3},
+	}
+	var nextRevision = &appsv1.ControllerRevision{ObjectMeta: metav1.ObjectMeta{Name: "cluster-bb6f4b98a"}, Revision: 4}
+
+	assert.Equal(t, getReplacedNextRevision(cluster, revisions, nextRevision), revisions[1])
+	assert.Assert(t, getReplacedNextRevision(cluster, revisions, revisions[1]) == nil)
+
+	// The update has started.
+	cluster.Status.Revision.UpdateStartTime = "2022-05-04T13:08:21Z"
+	assert.Assert(t, getReplacedNextRevision(cluster, revisions, nextRevision) == nil)
+
+	cluster.Status.Revision.UpdateStartTime = ""
+	cluster.Spec.UpdatePolicy = nil
+	assert.Assert(t, getReplacedNextRevision(cluster, revisions, nextRevision) == nil)
+}
+
+func TestIsIgnoredUpdate(t *testing.T) {
+	var jarFile = "gs://my-bucket/my-job.jar"
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			Job: &v1beta1.JobSpec{JarFile: &jarFile},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				PodAnnotations: map[string]string{"example.com/owner": "team-a"},
+			},
+			UpdatePolicy: &v1beta1.UpdatePolicy{IgnoreFields: []string{"job.resources", "taskManager.podAnnotations"}},
+		},
+	}
+	var newRevision = func(name string) *appsv1.ControllerRevision {
+		var revision, err = render.NewRevision(cluster, "", 1, nil)
+		assert.NilError(t, err)
+		return &appsv1.ControllerRevision{ObjectMeta: metav1.ObjectMeta{Name: name}, Data: revision.Data}
+	}
+	var revision = &Revision{currentRevision: newRevision("cluster-85dc8f749")}
+
+	cluster.Spec.Job.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")}
+	cluster.Spec.TaskManager.PodAnnotations["example.com/owner"] = "team-b"
+	revision.nextRevision = newRevision("cluster-aa5e3a87z")
+	assert.Assert(t, isIgnoredUpdate(cluster, revision))
+
+	// Other fields changed too.
+	cluster.Spec.TaskManager.PodLabels = map[string]string{"team": "b"}
+	revision.nextRevision = newRevision("cluster-bb6f4b98a")
+	assert.Assert(t, !isIgnoredUpdate(cluster, revision))
+
+	cluster.Spec.TaskManager.PodLabels = nil
+	cluster.Spec.UpdatePolicy = nil
+	revision.nextRevision = newRevision("cluster-aa5e3a87z")
+	assert.Assert(t, !isIgnoredUpdate(cluster, revision))
+}
@@ -23,16 +23,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"golang.org/x/net/context"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/go-logr/logr"
 	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/notification"
 	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
@@ -48,7 +55,13 @@ const (
 type ClusterStatusUpdater struct {
 	k8sClient client.Client
 	recorder  record.EventRecorder
+	notifier  *notification.Notifier
 	observed  ObservedClusterState
+	// The interval of the snapshots of the job vertices, 0 disables them.
+	jobVertexStatusInterval time.Duration
+	// The rules which select the savepoint storage of the jobs by the topology of the node of
+	// their JobManager.
+	savepointStorageRules []SavepointStorageRule
 }
 
 type Status interface {
@@ -89,8 +102,20 @@ func (updater *ClusterStatusUpdater) updateStatusIfChanged(ctx context.Context)
 			"new", newStatus)
 		updater.createStatusChangeEvents(oldStatus, newStatus)
 		var tc = &util.TimeConverter{}
-		newStatus.LastUpdateTime = tc.ToString(time.Now())
-		return true, updater.updateClusterStatus(ctx, newStatus)
+		var now = time.Now()
+		newStatus.LastUpdateTime = tc.ToString(now)
+		if err := updater.updateClusterStatus(ctx, newStatus); err != nil {
+			return true, err
+		}
+		// Notify only once the transition is recorded, so that it is not notified again on conflicts.
+		for _, event := range getNotificationEvents(updater.observed.cluster, oldStatus, newStatus, now) {
+			updater.notifier.Notify(event)
+		}
+		var records = getAuditRecords(&updater.observed, oldStatus, newStatus, now)
+		if err := appendAuditRecords(ctx, updater.k8sClient, updater.observed.cluster, records); err != nil {
+			log.Error(err, "Failed to append the audit records", "records", records)
+		}
+		return true, nil
 	}
 
 	log.Info("No status change", "state", oldStatus.State)
@@ -155,6 +180,22 @@ func (updater *ClusterStatusUpdater) createStatusChangeEvents(
 			newStatus.Components.TaskManager.State)
 	}
 
+	// Container terminations.
+	if newJm := newStatus.Components.JobManager; newJm != nil {
+		var oldTermination *v1beta1.ContainerTerminationStatus
+		if oldStatus.Components.JobManager != nil {
+			oldTermination = oldStatus.Components.JobManager.LastTermination
+		}
+		updater.createTerminationEvent("JobManager", oldTermination, newJm.LastTermination, newJm.Restarts)
+	}
+	if newTm := newStatus.Components.TaskManager; newTm != nil {
+		var oldTermination *v1beta1.ContainerTerminationStatus
+		if oldStatus.Components.TaskManager != nil {
+			oldTermination = oldStatus.Components.TaskManager.LastTermination
+		}
+		updater.createTerminationEvent("TaskManager", oldTermination, newTm.LastTermination, newTm.Restarts)
+	}
+
 	// Job.
 	if oldStatus.Components.Job == nil && newStatus.Components.Job != nil {
 		updater.createStatusEvent("Job", newStatus.Components.Job.State)
@@ -167,6 +208,92 @@ func (updater *ClusterStatusUpdater) createStatusChangeEvents(
 			newStatus.Components.Job.State)
 	}
 
+	// Job readiness gates.
+	if newJob := newStatus.Components.Job; newJob != nil && newJob.BlockingReadinessGate != nil {
+		var oldJob = oldStatus.Components.Job
+		if oldJob == nil || oldJob.BlockingReadinessGate == nil ||
+			oldJob.BlockingReadinessGate.Name != newJob.BlockingReadinessGate.Name {
+			updater.recorder.Eventf(updater.observed.cluster, corev1.EventTypeNormal, "JobBlocked",
+				"Job submission is blocked by readiness gate %v: %v",
+				newJob.BlockingReadinessGate.Name, newJob.BlockingReadinessGate.Message)
+		}
+	}
+
+	// Savepoint ownership verification.
+	if newJob := newStatus.Components.Job; newJob != nil && newJob.SavepointOwnership != nil &&
+		newJob.SavepointOwnership.RestoreBlockedReason != "" {
+		var oldJob = oldStatus.Components.Job
+		if oldJob == nil || oldJob.SavepointOwnership == nil ||
+			oldJob.SavepointOwnership.RestoreBlockedReason != newJob.SavepointOwnership.RestoreBlockedReason {
+			updater.recorder.Eventf(updater.observed.cluster, corev1.EventTypeWarning, "SavepointRestoreBlocked",
+				"Job submission is blocked by the ownership of the %v", newJob.SavepointOwnership.RestoreBlockedReason)
+		}
+	}
+
+	// Startup deadline.
+	var oldDeadline = meta.FindStatusCondition(oldStatus.Conditions, v1beta1.ClusterConditionStartupDeadlineExceeded)
+	var newDeadline = meta.FindStatusCondition(newStatus.Conditions, v1beta1.ClusterConditionStartupDeadlineExceeded)
+	if newDeadline != nil && newDeadline.Status == metav1.ConditionTrue &&
+		(oldDeadline == nil || oldDeadline.Status != metav1.ConditionTrue) {
+		updater.recorder.Event(updater.observed.cluster, corev1.EventTypeWarning, "StartupDeadlineExceeded", newDeadline.Message)
+	}
+
+	// Storage probe.
+	var oldStorage = meta.FindStatusCondition(oldStatus.Conditions, v1beta1.ClusterConditionStorageUnavailable)
+	var newStorage = meta.FindStatusCondition(newStatus.Conditions, v1beta1.ClusterConditionStorageUnavailable)
+	if newStorage != nil && newStorage.Status != metav1.ConditionFalse &&
+		(oldStorage == nil || oldStorage.Status != newStorage.Status || oldStorage.Message != newStorage.Message) {
+		var reason = "StorageUnavailable"
+		if newStorage.Status == metav1.ConditionUnknown {
+			reason = "StorageProbeInconclusive"
+		}
+		updater.recorder.Event(updater.observed.cluster, corev1.EventTypeWarning, reason, newStorage.Message)
+	}
+
+	// Dependencies.
+	var oldDependencies = meta.FindStatusCondition(oldStatus.Conditions, v1beta1.ClusterConditionWaitingForDependencies)
+	var newDependencies = meta.FindStatusCondition(newStatus.Conditions, v1beta1.ClusterConditionWaitingForDependencies)
+	if newDependencies != nil && newDependencies.Status == metav1.ConditionTrue &&
+		(oldDependencies == nil || oldDependencies.Status != metav1.ConditionTrue || oldDependencies.Message != newDependencies.Message) {
+		updater.recorder.Event(updater.observed.cluster, corev1.EventTypeNormal, "WaitingForDependencies", newDependencies.Message)
+	} else if newDependencies != nil && newDependencies.Status == metav1.ConditionFalse &&
+		oldDependencies != nil && oldDependencies.Status == metav1.ConditionTrue {
+		updater.recorder.Event(updater.observed.cluster, corev1.EventTypeNormal, "DependenciesReady", newDependencies.Message)
+	}
+
+	// TaskManager registration watchdog.
+	var oldRegistration = meta.FindStatusCondition(oldStatus.Conditions, v1beta1.ClusterConditionTaskManagersNotRegistered)
+	var newRegistration = meta.FindStatusCondition(newStatus.Conditions, v1beta1.ClusterConditionTaskManagersNotRegistered)
+	if newRegistration != nil && newRegistration.Status == metav1.ConditionTrue &&
+		(oldRegistration == nil || oldRegistration.Status != metav1.ConditionTrue || oldRegistration.Message != newRegistration.Message) {
+		updater.recorder.Event(updater.observed.cluster, corev1.EventTypeWarning, "TaskManagersNotRegistered", newRegistration.Message)
+	}
+
+	// Pause.
+	var oldPaused = meta.FindStatusCondition(oldStatus.Conditions, v1beta1.ClusterConditionPaused)
+	var newPaused = meta.FindStatusCondition(newStatus.Conditions, v1beta1.ClusterConditionPaused)
+	if oldPaused == nil && newPaused != nil {
+		updater.recorder.Event(updater.observed.cluster, corev1.EventTypeNormal, "Paused", newPaused.Message)
+	} else if oldPaused != nil && newPaused == nil {
+		updater.recorder.Event(updater.observed.cluster, corev1.EventTypeNormal, "Resumed", "The reconciliation is resumed.")
+	}
+
+	// Canary update.
+	if oldCanary, newCanary := oldStatus.CanaryUpdate, newStatus.CanaryUpdate; newCanary != nil {
+		if oldCanary == nil || oldCanary.Revision != newCanary.Revision {
+			updater.createStatusEvent("Canary update", newCanary.State)
+		} else if oldCanary.State != newCanary.State {
+			updater.createStatusChangeEvent("Canary update", oldCanary.State, newCanary.State)
+		}
+	}
+
+	// Update failure.
+	if oldFailure, newFailure := oldStatus.Revision.UpdateFailure, newStatus.Revision.UpdateFailure; newFailure != nil &&
+		(oldFailure == nil || oldFailure.Revision != newFailure.Revision) {
+		updater.recorder.Eventf(updater.observed.cluster, corev1.EventTypeWarning, "UpdateFailed",
+			"The update to revision %v failed. %v", newFailure.Revision, newFailure.Reason)
+	}
+
 	// Cluster.
 	if oldStatus.State != newStatus.State {
 		updater.createStatusChangeEvent("Cluster", oldStatus.State, newStatus.State)
@@ -185,6 +312,79 @@ func (updater *ClusterStatusUpdater) createStatusChangeEvents(
 	}
 }
 
+// Gets the notifications of the state transitions of the cluster.
+func getNotificationEvents(
+	cluster *v1beta1.FlinkCluster,
+	oldStatus v1beta1.FlinkClusterStatus,
+	newStatus v1beta1.FlinkClusterStatus,
+	now time.Time) []notification.Event {
+	var events []notification.Event
+	var newEvent = func(eventType notification.EventType, message string) notification.Event {
+		var event = notification.Event{
+			Type:      eventType,
+			Namespace: cluster.Namespace,
+			Name:      cluster.Name,
+			Message:   message,
+			Time:      now,
+		}
+		if newStatus.Components.Job != nil {
+			event.JobID = newStatus.Components.Job.ID
+		}
+		if ingress := newStatus.Components.JobManagerIngress; ingress != nil && len(ingress.URLs) > 0 {
+			event.WebUIURL = ingress.URLs[0]
+		}
+		return event
+	}
+
+	var oldJob, newJob = oldStatus.Components.Job, newStatus.Components.Job
+	if newJob != nil && newJob.State == v1beta1.JobStateFailed &&
+		(oldJob == nil || oldJob.State != v1beta1.JobStateFailed) {
+		var message = "Job failed"
+		if len(newJob.FailureReasons) > 0 {
+			message = fmt.Sprintf("Job failed: %v", newJob.FailureReasons[0])
+		}
+		events = append(events, newEvent(notification.EventJobFailed, message))
+	}
+
+	var oldSavepoint, newSavepoint = oldStatus.Savepoint, newStatus.Savepoint
+	if newSavepoint != nil &&
+		(newSavepoint.State == v1beta1.SavepointStateFailed || newSavepoint.State == v1beta1.SavepointStateTriggerFailed) &&
+		(oldSavepoint == nil || oldSavepoint.State != newSavepoint.State || oldSavepoint.TriggerID != newSavepoint.TriggerID) {
+		var message = fmt.Sprintf("Savepoint %v", strings.ToLower(newSavepoint.State))
+		if newSavepoint.Message != "" {
+			message = fmt.Sprintf("%v: %v", message, newSavepoint.Message)
+		}
+		events = append(events, newEvent(notification.EventSavepointFailed, message))
+	}
+
+	if oldStatus.State == v1beta1.ClusterStateRunning {
+		switch newStatus.State {
+		case v1beta1.ClusterStateDegraded:
+			events = append(events, newEvent(notification.EventClusterDegraded, "TaskManagers are not ready"))
+		case v1beta1.ClusterStateReconciling:
+			events = append(events, newEvent(notification.EventClusterDegraded, "Cluster components are not ready"))
+		}
+	}
+	if newStatus.State != oldStatus.State {
+		switch newStatus.State {
+		case v1beta1.ClusterStateSuspended:
+			var message = "Job stopped with a savepoint"
+			if newJob != nil && newJob.SavepointLocation != "" {
+				message = fmt.Sprintf("Job stopped with savepoint %v", newJob.SavepointLocation)
+			}
+			events = append(events, newEvent(notification.EventClusterSuspended, message))
+		case v1beta1.ClusterStateRollingBack:
+			var message = "Rolling back the failed update"
+			if failure := newStatus.Revision.UpdateFailure; failure != nil {
+				message = fmt.Sprintf("Rolling back the failed update from revision %v to revision %v",
+					failure.Revision, failure.RollbackRevision)
+			}
+			events = append(events, newEvent(notification.EventClusterRollingBack, message))
+		}
+	}
+	return events
+}
+
 func (updater *ClusterStatusUpdater) createStatusEvent(name string, status Status) {
 	updater.recorder.Event(
 		updater.observed.cluster,
@@ -202,12 +402,32 @@ func (updater *ClusterStatusUpdater) createStatusChangeEvent(
 		fmt.Sprintf("%v status changed: %v -> %v", name, oldStatus, newStatus))
 }
 
+// Creates a warning event for a new last termination of a JobManager or TaskManager container,
+// with the memory limit to try if it was OOMKilled.
+func (updater *ClusterStatusUpdater) createTerminationEvent(
+	name string, oldTermination, newTermination *v1beta1.ContainerTerminationStatus, restarts int32) {
+	if newTermination == nil || (oldTermination != nil &&
+		oldTermination.Pod == newTermination.Pod && oldTermination.FinishedAt == newTermination.FinishedAt) {
+		return
+	}
+	var reason = "ContainerTerminated"
+	var message = fmt.Sprintf("%v container of pod %v terminated with %v, exit code %v, %v restarts in total",
+		name, newTermination.Pod, newTermination.Reason, newTermination.ExitCode, restarts)
+	if newTermination.Reason == "OOMKilled" {
+		reason = "ContainerOOMKilled"
+		if newTermination.SuggestedMemory != nil {
+			message = fmt.Sprintf("%v, consider a memory limit of %v", message, newTermination.SuggestedMemory)
+		}
+	}
+	updater.recorder.Event(updater.observed.cluster, corev1.EventTypeWarning, reason, message)
+}
+
 func (updater *ClusterStatusUpdater) deriveClusterStatus(
 	ctx context.Context,
 	cluster *v1beta1.FlinkCluster,
 	observed *ObservedClusterState) v1beta1.FlinkClusterStatus {
 	var totalComponents int
-	if IsApplicationModeCluster(cluster) {
+	if render.IsApplicationModeCluster(cluster) {
 		// jmService, tmStatefulSet.
 		totalComponents = 2
 	} else {
@@ -241,7 +461,7 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 	// JobManager StatefulSet.
 	var observedJmStatefulSet = observed.jmStatefulSet
 	jmStatus := &status.Components.JobManager
-	if !IsApplicationModeCluster(cluster) {
+	if !render.IsApplicationModeCluster(cluster) {
 		if !isComponentUpdated(observedJmStatefulSet, observed.cluster) && shouldUpdateCluster(observed) {
 			*jmStatus = new(v1beta1.JobManagerStatus)
 			recorded.Components.JobManager.DeepCopyInto(*jmStatus)
@@ -256,7 +476,10 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 			}
 			if (*jmStatus).State == v1beta1.ComponentStateReady {
 				runningComponents++
+			} else {
+				(*jmStatus).NotReadyReason = getPodsNotReadyReason(observed.jmPods)
 			}
+			(*jmStatus).Restarts, (*jmStatus).LastTermination = getPodsContainerTermination(observed.jmPods, "jobmanager")
 		} else if recorded.Components.JobManager != nil {
 			*jmStatus = &v1beta1.JobManagerStatus{
 				Name:  recorded.Components.JobManager.Name,
@@ -271,31 +494,43 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 		recorded.Components.JobManagerService.DeepCopyInto(&status.Components.JobManagerService)
 		status.Components.JobManagerService.State = v1beta1.ComponentStateUpdating
 	} else if observedJmService != nil {
+		// The UI service, if any, is the one exposed by the access scope.
+		var exposedService = observedJmService
+		if render.ShouldSplitJobManagerUIService(observed.cluster) && observed.jmUIService != nil {
+			exposedService = observed.jmUIService
+		}
 		var nodePort int32
 		var loadBalancerIngress []corev1.LoadBalancerIngress
+		var notReadyReason string
 		state := v1beta1.ComponentStateNotReady
 
-		switch observedJmService.Spec.Type {
+		switch exposedService.Spec.Type {
 		case corev1.ServiceTypeClusterIP:
-			if observedJmService.Spec.ClusterIP != "" {
+			if exposedService.Spec.ClusterIP != "" {
 				state = v1beta1.ComponentStateReady
 				runningComponents++
+			} else {
+				notReadyReason = "ClusterIPNotAssigned"
 			}
 		case corev1.ServiceTypeLoadBalancer:
-			if len(observedJmService.Status.LoadBalancer.Ingress) > 0 {
+			if len(exposedService.Status.LoadBalancer.Ingress) > 0 {
 				state = v1beta1.ComponentStateReady
 				runningComponents++
-				loadBalancerIngress = observedJmService.Status.LoadBalancer.Ingress
+				loadBalancerIngress = exposedService.Status.LoadBalancer.Ingress
+			} else {
+				notReadyReason = "LoadBalancerPending"
 			}
 		case corev1.ServiceTypeNodePort:
-			if len(observedJmService.Spec.Ports) > 0 {
+			if len(exposedService.Spec.Ports) > 0 {
 				state = v1beta1.ComponentStateReady
 				runningComponents++
-				for _, port := range observedJmService.Spec.Ports {
+				for _, port := range exposedService.Spec.Ports {
 					if port.Name == "ui" {
 						nodePort = port.NodePort
 					}
 				}
+			} else {
+				notReadyReason = "NodePortNotAssigned"
 			}
 		}
 
@@ -305,6 +540,7 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 				State:               state,
 				NodePort:            nodePort,
 				LoadBalancerIngress: loadBalancerIngress,
+				NotReadyReason:      notReadyReason,
 			}
 	} else if recorded.Components.JobManagerService.Name != "" {
 		status.Components.JobManagerService =
@@ -358,7 +594,7 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 				if ingress.Hostname != "" {
 					addr = ingress.Hostname
 				} else if ingress.IP != "" {
-					addr = ingress.IP
+					addr = getURLHost(ingress.IP)
 				}
 				// If ingress spec does not have host, get ip or hostname of loadbalancer.
 				if !useHost && addr != "" {
@@ -376,17 +612,20 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 		}
 
 		// Jobmanager ingress state become ready when LB for ingress is specified.
+		var notReadyReason string
 		if loadbalancerReady {
 			state = v1beta1.ComponentStateReady
 		} else {
 			state = v1beta1.ComponentStateNotReady
+			notReadyReason = "LoadBalancerPending"
 		}
 
 		status.Components.JobManagerIngress =
 			&v1beta1.JobManagerIngressStatus{
-				Name:  observedJmIngress.Name,
-				State: state,
-				URLs:  urls,
+				Name:           observedJmIngress.Name,
+				State:          state,
+				URLs:           urls,
+				NotReadyReason: notReadyReason,
 			}
 	} else if recorded.Components.JobManagerIngress != nil &&
 		recorded.Components.JobManagerIngress.Name != "" {
@@ -396,9 +635,15 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 				State: v1beta1.ComponentStateDeleted,
 			}
 	}
-	labelSelector := labels.SelectorFromSet(getComponentLabels(cluster, "taskmanager"))
+	labelSelector := labels.SelectorFromSet(render.ComponentLabels(cluster, "taskmanager"))
 	var clusterTmDeploymentType = cluster.Spec.TaskManager.DeploymentType
-	if clusterTmDeploymentType == "" || clusterTmDeploymentType == v1beta1.DeploymentTypeStatefulSet {
+	if render.IsTaskManagerExternal(cluster) {
+		// Externally managed TaskManagers.
+		status.Components.TaskManager = getExternalTaskManagerStatus(observed.registeredTaskManagers, labelSelector.String())
+		if status.Components.TaskManager.State == v1beta1.ComponentStateReady {
+			runningComponents++
+		}
+	} else if clusterTmDeploymentType == "" || clusterTmDeploymentType == v1beta1.DeploymentTypeStatefulSet {
 		// TaskManager StatefulSet.
 		var observedTmStatefulSet = observed.tmStatefulSet
 		tmStatus := &status.Components.TaskManager
@@ -417,7 +662,10 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 			}
 			if (*tmStatus).State == v1beta1.ComponentStateReady {
 				runningComponents++
+			} else {
+				(*tmStatus).NotReadyReason = getPodsNotReadyReason(observed.tmPods)
 			}
+			(*tmStatus).Restarts, (*tmStatus).LastTermination = getPodsContainerTermination(observed.tmPods, "taskmanager")
 		} else if recorded.Components.TaskManager != nil {
 			*tmStatus = &v1beta1.TaskManagerStatus{
 				Name:  recorded.Components.TaskManager.Name,
@@ -443,7 +691,10 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 			}
 			if (*tmStatus).State == v1beta1.ComponentStateReady {
 				runningComponents++
+			} else {
+				(*tmStatus).NotReadyReason = getPodsNotReadyReason(observed.tmPods)
 			}
+			(*tmStatus).Restarts, (*tmStatus).LastTermination = getPodsContainerTermination(observed.tmPods, "taskmanager")
 		} else if recorded.Components.TaskManager != nil {
 			*tmStatus = &v1beta1.TaskManagerStatus{
 				Name:  recorded.Components.TaskManager.Name,
@@ -452,11 +703,21 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 		}
 	}
 
+	// (Optional) Idle policy.
+	// The idle session cluster scaled to zero is in the Idle state.
+	status.Idle = deriveIdleStatus(observed, observed.observeTime)
+	var scaledToZero = status.Idle != nil && status.Idle.ScaledToZero
+
 	// Derive the new cluster state.
 	var jobStatus = recorded.Components.Job
 	switch recorded.State {
-	case "", v1beta1.ClusterStateCreating:
-		if runningComponents < totalComponents {
+	case "", v1beta1.ClusterStateQueued, v1beta1.ClusterStateCreating:
+		if reason := observed.dependenciesNotReadyReason; reason != nil && *reason != "" {
+			status.State = v1beta1.ClusterStateQueued
+		} else if observed.queuePosition > 0 {
+			status.State = v1beta1.ClusterStateQueued
+			status.QueuePosition = observed.queuePosition
+		} else if runningComponents < totalComponents {
 			status.State = v1beta1.ClusterStateCreating
 			if jobStatus.IsStopped() {
 				var policy = observed.cluster.Spec.Job.CleanupPolicy
@@ -474,7 +735,8 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 		} else {
 			status.State = v1beta1.ClusterStateRunning
 		}
-	case v1beta1.ClusterStateUpdating:
+	case v1beta1.ClusterStateUpdating,
+		v1beta1.ClusterStateRollingBack:
 		if shouldUpdateCluster(observed) {
 			status.State = v1beta1.ClusterStateUpdating
 		} else if runningComponents < totalComponents {
@@ -487,9 +749,14 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 			status.State = v1beta1.ClusterStateRunning
 		}
 	case v1beta1.ClusterStateRunning,
-		v1beta1.ClusterStateReconciling:
+		v1beta1.ClusterStateDegraded,
+		v1beta1.ClusterStateReconciling,
+		v1beta1.ClusterStateSuspending,
+		v1beta1.ClusterStateSuspended:
 		if shouldUpdateCluster(observed) {
 			status.State = v1beta1.ClusterStateUpdating
+		} else if scaledToZero {
+			status.State = v1beta1.ClusterStateIdle
 		} else if !recorded.Revision.IsUpdateTriggered() && jobStatus.IsStopped() {
 			var policy = observed.cluster.Spec.Job.CleanupPolicy
 			if jobStatus.State == v1beta1.JobStateSucceeded &&
@@ -501,9 +768,15 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 			} else if jobStatus.State == v1beta1.JobStateCancelled &&
 				policy.AfterJobCancelled != v1beta1.CleanupActionKeepCluster {
 				status.State = v1beta1.ClusterStateStopping
+			} else if isJobSuspended(jobStatus, recorded.Savepoint) {
+				status.State = v1beta1.ClusterStateSuspended
 			} else {
 				status.State = v1beta1.ClusterStateRunning
 			}
+		} else if isJobSuspending(jobStatus, recorded.Savepoint) {
+			status.State = v1beta1.ClusterStateSuspending
+		} else if isTaskManagerDegraded(&status.Components, runningComponents, totalComponents) {
+			status.State = v1beta1.ClusterStateDegraded
 		} else if runningComponents < totalComponents {
 			status.State = v1beta1.ClusterStateReconciling
 		} else {
@@ -520,15 +793,36 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 		} else {
 			status.State = v1beta1.ClusterStateStopping
 		}
+	case v1beta1.ClusterStateIdle:
+		if shouldUpdateCluster(observed) {
+			status.State = v1beta1.ClusterStateUpdating
+		} else if scaledToZero {
+			status.State = v1beta1.ClusterStateIdle
+		} else if runningComponents < totalComponents {
+			status.State = v1beta1.ClusterStateReconciling
+		} else {
+			status.State = v1beta1.ClusterStateRunning
+		}
 	case v1beta1.ClusterStateStopped:
-		if recorded.Revision.IsUpdateTriggered() {
+		if recorded.Revision.IsUpdateTriggered() &&
+			!isUpdateDeferred(observed.cluster, &recorded.Revision, observed.observeTime) {
 			status.State = v1beta1.ClusterStateUpdating
 		} else {
 			status.State = v1beta1.ClusterStateStopped
 		}
+	case v1beta1.ClusterStateDeleting:
+		status.State = v1beta1.ClusterStateDeleting
 	default:
 		panic(fmt.Sprintf("Unknown cluster state: %v", recorded.State))
 	}
+	// The update which rolls back a failed update is reported as such.
+	if status.State == v1beta1.ClusterStateUpdating && isUpdateRollingBack(&recorded.Revision) {
+		status.State = v1beta1.ClusterStateRollingBack
+	}
+	// The deleted cluster does not go back to any other state.
+	if observed.cluster.DeletionTimestamp != nil {
+		status.State = v1beta1.ClusterStateDeleting
+	}
 
 	// (Optional) Job.
 	// Update job status.
@@ -557,6 +851,68 @@ func (updater *ClusterStatusUpdater) deriveClusterStatus(
 		observed.updateState,
 		&observed.revision,
 		&recorded.Revision)
+	// The changes of spec.updatePolicy.ignoreFields are recorded without an update.
+	if observed.updateState == UpdateStateNoUpdate && isIgnoredUpdate(cluster, &observed.revision) {
+		status.Revision.CurrentRevision = status.Revision.NextRevision
+	}
+	status.Revision.NextRevisionTime = deriveNextRevisionTime(
+		cluster, &status.Revision, &recorded.Revision, observed.observeTime)
+	status.Revision.UpdateStartTime = deriveUpdateStartTime(
+		cluster, &status.Revision, &recorded.Revision, observed.observeTime)
+	status.Revision.UpdatePhase = deriveUpdatePhase(
+		observed, &status.Revision, status.Components.Job, status.Savepoint)
+	// The update fails if its job fails the restore verification.
+	status.Revision.UpdateFailure = deriveUpdateFailure(
+		observed, &status.Revision, recorded.Revision.UpdateFailure, status.Components.Job, observed.observeTime)
+
+	// The generation is observed once the cluster is updated to its spec.
+	status.ObservedGeneration = recorded.ObservedGeneration
+	if !status.Revision.IsUpdateTriggered() {
+		status.ObservedGeneration = cluster.Generation
+	}
+	status.UpdateProgress = deriveUpdateProgress(observed, &status.Revision)
+
+	// (Optional) Canary update.
+	// Update the canary update status of the next revision.
+	status.CanaryUpdate = deriveCanaryUpdateStatus(observed, &status.Revision, observed.observeTime)
+
+	// Keep the permanent reconcile error until the spec is updated.
+	if isReconcileStopped(cluster) {
+		status.ReconcileError = recorded.ReconcileError.DeepCopy()
+	}
+
+	// The uploaded JAR files are recorded by the reconciler.
+	status.Jars = append([]v1beta1.SessionJarStatus(nil), recorded.Jars...)
+
+	// The state exports are recorded by the reconciler.
+	status.StateExport = recorded.StateExport.DeepCopy()
+
+	// The decommission of the TaskManagers is started by the reconciler.
+	status.TaskManagerDecommission = deriveTaskManagerDecommissionStatus(observed, recorded.TaskManagerDecommission)
+
+	status.Endpoints = deriveEndpointsStatus(cluster, &status.Components, observed.externalDNSResolved)
+	status.Resources = deriveResourcesStatus(observed)
+
+	// The succeeded dependencies are kept once the dependencies are no longer checked.
+	if observed.dependenciesNotReadyReason != nil {
+		status.SucceededDependencies = observed.succeededDependencies
+	} else {
+		status.SucceededDependencies = append([]string(nil), recorded.SucceededDependencies...)
+	}
+
+	// The versions are recorded by the status migration.
+	status.OperatorVersion = recorded.OperatorVersion
+	status.SchemaVersion = recorded.SchemaVersion
+
+	// The conditions keep their transition times while their status is unchanged.
+	status.Conditions = append([]metav1.Condition(nil), recorded.Conditions...)
+	setJobSLOCondition(&status.Conditions, cluster, status.Components.Job)
+	setPendingUpdateCondition(&status.Conditions, cluster, &status.Revision, observed.observeTime)
+	setStartupDeadlineCondition(&status.Conditions, cluster, &status, observed.observeTime)
+	setStorageUnavailableCondition(&status.Conditions, cluster, observed.storageProbe)
+	setWaitingForDependenciesCondition(&status.Conditions, cluster, observed.dependenciesNotReadyReason)
+	setTaskManagerRegistrationCondition(&status.Conditions, observed)
+	setPausedCondition(&status.Conditions, cluster)
 
 	return status
 }
@@ -575,7 +931,7 @@ func (updater *ClusterStatusUpdater) getFlinkJobID() *string {
 
 	var observedJobSubmitter = updater.observed.flinkJobSubmitter
 	if observedJobSubmitter.pod != nil {
-		if jobId, ok := observedJobSubmitter.pod.Labels[JobIdLabel]; ok {
+		if jobId, ok := observedJobSubmitter.pod.Labels[render.JobIdLabel]; ok {
 			return &jobId
 		}
 	}
@@ -645,12 +1001,33 @@ func (updater *ClusterStatusUpdater) deriveJobStatus(ctx context.Context) *v1bet
 		}
 	}
 
+	if jobSpec.ClassName != nil {
+		newJob.EntryClass = *jobSpec.ClassName
+	} else if observedSubmitter.log != nil && observedSubmitter.log.entryClass != "" {
+		newJob.EntryClass = observedSubmitter.log.entryClass
+	}
+
 	var newJobState v1beta1.JobState
 	switch {
 	case oldJob == nil:
 		newJobState = v1beta1.JobStatePending
 	case shouldUpdateJob(&observed):
 		newJobState = v1beta1.JobStateUpdating
+	// The job fails as the components were not ready within spec.startupDeadlineSeconds,
+	// without being restarted.
+	case isStartupDeadlineExceeded(observedCluster) &&
+		getStartupDeadlineAction(observedCluster) == v1beta1.StartupDeadlineActionCleanup:
+		if oldJob.IsStopped() {
+			newJobState = oldJob.State
+			break
+		}
+		newJobState = v1beta1.JobStateDeployFailed
+		newJob.FailureReasons = []string{meta.FindStatusCondition(observedCluster.Status.Conditions,
+			v1beta1.ClusterConditionStartupDeadlineExceeded).Message}
+	// The lost job is recovered by JobManager, e.g. after JobManager failover.
+	case oldJob.State == v1beta1.JobStateLost && observedFlinkJob != nil &&
+		getFlinkJobDeploymentState(observedFlinkJob.State) == v1beta1.JobStateRunning:
+		newJobState = v1beta1.JobStateRunning
 	case oldJob.ShouldRestart(jobSpec):
 		newJobState = v1beta1.JobStateRestarting
 	case oldJob.IsStopped():
@@ -661,7 +1038,16 @@ func (updater *ClusterStatusUpdater) deriveJobStatus(ctx context.Context) *v1bet
 	case observedFlinkJob != nil:
 		newJob.ID = observedFlinkJob.Id
 		newJob.Name = observedFlinkJob.Name
-		tmpState := getFlinkJobDeploymentState(observedFlinkJob.State)
+		tmpState, determined := applyJobSuccessPolicy(observedCluster, getFlinkJobDeploymentState(observedFlinkJob.State), observed.flinkJob.accumulators)
+		if !determined {
+			log.Info("Waiting for the job accumulators to apply the success policy")
+			newJobState = oldJob.State
+			break
+		}
+		// The job is stopped by the operator as it failed the restore verification.
+		if tmpState == v1beta1.JobStateCancelled && isRestoreVerificationFailed(oldJob) {
+			tmpState = v1beta1.JobStateFailed
+		}
 		if observedSubmitter.job == nil || tmpState != v1beta1.JobStateSucceeded {
 			newJobState = tmpState
 			break
@@ -682,7 +1068,7 @@ func (updater *ClusterStatusUpdater) deriveJobStatus(ctx context.Context) *v1bet
 		} else {
 			newJobState = oldJob.State
 		}
-	case shouldStopJob(observedCluster):
+	case render.ShouldStopJob(observedCluster):
 		newJobState = v1beta1.JobStateCancelled
 	// When Flink job not found in JobManager or JobManager is unavailable
 	case isFlinkAPIReady(observed.flinkJob.list):
@@ -715,6 +1101,42 @@ func (updater *ClusterStatusUpdater) deriveJobStatus(ctx context.Context) *v1bet
 	// Update State
 	newJob.State = newJobState
 
+	// Record how the job is stopped for the update.
+	if newJob.IsActive() && isStoppingJobForUpdate(&observed) {
+		newJob.UpdateStopMode = getUpdateStopMode(observedCluster)
+	}
+
+	// Surface why the submitter pod is stuck, e.g. it cannot pull the image.
+	newJob.NotReadyReason = ""
+	if pod := observedSubmitter.pod; pod != nil && pod.Status.Phase == corev1.PodPending {
+		newJob.NotReadyReason = getPodNotReadyReason(pod)
+	}
+
+	deriveJobVertices(newJob, observed.flinkJob.vertices, updater.jobVertexStatusInterval, observed.observeTime)
+
+	// Surface the readiness gate blocking the submission of the job.
+	newJob.BlockingReadinessGate = nil
+	if newJob.IsPending() {
+		newJob.BlockingReadinessGate = observed.blockingReadinessGate.DeepCopy()
+	}
+
+	// Surface the verification of the savepoint blocking the submission of the job, the
+	// recorded savepoint is written by the reconciler.
+	if jobSpec.SavepointOwnership == nil {
+		newJob.SavepointOwnership = nil
+	} else {
+		var blockedReason string
+		if newJob.IsPending() {
+			blockedReason = observed.savepointRestoreBlockedReason
+		}
+		if newJob.SavepointOwnership == nil && blockedReason != "" {
+			newJob.SavepointOwnership = &v1beta1.SavepointOwnershipStatus{}
+		}
+		if newJob.SavepointOwnership != nil {
+			newJob.SavepointOwnership.RestoreBlockedReason = blockedReason
+		}
+	}
+
 	// Derived new job status if the state is changed.
 	if oldJob == nil || oldJob.State != newJob.State {
 		// TODO: It would be ideal to set the times with the timestamp retrieved from the Flink API like /jobs/{job-id}.
@@ -734,11 +1156,21 @@ func (updater *ClusterStatusUpdater) deriveJobStatus(ctx context.Context) *v1bet
 			if oldJob.FinalSavepoint {
 				newJob.FinalSavepoint = false
 			}
+			// Verify the job started from a savepoint, unless it is already verified, e.g. lost and recovered.
+			if jobSpec.RestoreVerification != nil && newJob.FromSavepoint != "" && newJob.RestoreVerification == nil {
+				newJob.RestoreVerification = &v1beta1.RestoreVerificationStatus{
+					Savepoint: newJob.FromSavepoint,
+					State:     v1beta1.RestoreVerificationStateInProgress,
+				}
+			}
 		case newJob.IsFailed():
 			if len(newJob.FailureReasons) == 0 {
 				newJob.FailureReasons = []string{}
 				exceptions := observed.flinkJob.exceptions
-				if exceptions != nil && len(exceptions.Exceptions) > 0 {
+				if isRestoreVerificationFailed(newJob) {
+					newJob.FailureReasons = append(newJob.FailureReasons,
+						"Restore verification failed: "+newJob.RestoreVerification.Message)
+				} else if exceptions != nil && len(exceptions.Exceptions) > 0 {
 					for _, e := range exceptions.Exceptions {
 						newJob.FailureReasons = append(newJob.FailureReasons, e.Exception)
 					}
@@ -764,10 +1196,55 @@ func (updater *ClusterStatusUpdater) deriveJobStatus(ctx context.Context) *v1bet
 
 	}
 
+	// Record the run history of the job.
+	var oldJobState v1beta1.JobState
+	if oldJob != nil {
+		oldJobState = oldJob.State
+	}
+	deriveJobRuns(jobSpec, oldJobState, newJob, observedSubmitter.pod)
+
+	// Verify the running job started from a savepoint.
+	if newJob.State == v1beta1.JobStateRunning && isRestoreVerificationInProgress(newJob) && jobSpec.RestoreVerification != nil {
+		var state, message = verifyJobRestore(jobSpec.RestoreVerification, newJob.StartTime,
+			observed.flinkJob.checkpoints, observed.flinkJob.numRestarts, time.Now())
+		if state != v1beta1.RestoreVerificationStateInProgress {
+			log.Info("Verified the job restored from the savepoint", "state", state, "message", message)
+			newJob.RestoreVerification.State = state
+			newJob.RestoreVerification.Message = message
+		}
+	}
+
+	// Evaluate the service level objectives of the running job.
+	if newJob.State == v1beta1.JobStateRunning && jobSpec.SLO != nil {
+		newJob.SLO = evaluateJobSLO(jobSpec.SLO, newJob.SLO, &observed.flinkJob, time.Now())
+	} else {
+		newJob.SLO = nil
+	}
+
+	// Track the state changes and the node drains for the adaptive savepoints.
+	var savepointCompleted = observedSavepoint.status != nil && observedSavepoint.status.IsSuccessful()
+	newJob.AdaptiveSavepoint = deriveAdaptiveSavepointStatus(jobSpec, newJob.AdaptiveSavepoint,
+		observed.flinkJob.checkpoints, observed.drainingNodes, savepointCompleted, time.Now())
+	// The plan of the job is stored by the reconciler, the status is kept while spec.job.planArchive is set.
+	if jobSpec.PlanArchive == nil {
+		newJob.Plan = nil
+	}
+
+	// Select the savepoint storage by the topology of the JobManager node.
+	var savepointsDir = deriveSavepointsDir(updater.savepointStorageRules, observedCluster, newJob.SavepointsDir, observed.jmNodeLabels)
+	if savepointsDir != newJob.SavepointsDir {
+		log.Info("Selected savepoints dir", "savepointsDir", savepointsDir)
+		newJob.SavepointsDir = savepointsDir
+	}
+
 	// Savepoint
-	if observedSavepoint.status != nil && observedSavepoint.status.IsSuccessful() {
+	if savepointCompleted {
 		newJob.SavepointGeneration++
 		newJob.SavepointLocation = observedSavepoint.status.Location
+		newJob.SavepointFlinkVersion = ""
+		if savepoint != nil {
+			newJob.SavepointFlinkVersion = savepoint.FlinkVersion
+		}
 		if finalSavepointRequested(newJob.ID, savepoint) {
 			newJob.FinalSavepoint = true
 		}
@@ -797,6 +1274,106 @@ func (updater *ClusterStatusUpdater) isStatusChanged(
 			"new",
 			newStatus.State)
 	}
+	if !reflect.DeepEqual(newStatus.ReconcileError, currentStatus.ReconcileError) {
+		changed = true
+		log.Info(
+			"Reconcile error changed",
+			"current",
+			currentStatus.ReconcileError,
+			"new",
+			newStatus.ReconcileError)
+	}
+	// The quantities are compared semantically, their string form is cached when they are parsed.
+	if !equality.Semantic.DeepEqual(newStatus.Resources, currentStatus.Resources) {
+		changed = true
+		log.Info(
+			"Resources changed",
+			"current",
+			currentStatus.Resources,
+			"new",
+			newStatus.Resources)
+	}
+	if !reflect.DeepEqual(newStatus.Endpoints, currentStatus.Endpoints) {
+		changed = true
+		log.Info(
+			"Endpoints changed",
+			"current",
+			currentStatus.Endpoints,
+			"new",
+			newStatus.Endpoints)
+	}
+	if newStatus.ObservedGeneration != currentStatus.ObservedGeneration {
+		changed = true
+		log.Info(
+			"Observed generation changed",
+			"current",
+			currentStatus.ObservedGeneration,
+			"new",
+			newStatus.ObservedGeneration)
+	}
+	if !reflect.DeepEqual(newStatus.UpdateProgress, currentStatus.UpdateProgress) {
+		changed = true
+		log.Info(
+			"Update progress changed",
+			"current",
+			currentStatus.UpdateProgress,
+			"new",
+			newStatus.UpdateProgress)
+	}
+	if !reflect.DeepEqual(newStatus.CanaryUpdate, currentStatus.CanaryUpdate) {
+		changed = true
+		log.Info(
+			"Canary update status changed",
+			"current",
+			currentStatus.CanaryUpdate,
+			"new",
+			newStatus.CanaryUpdate)
+	}
+	if !reflect.DeepEqual(newStatus.Idle, currentStatus.Idle) {
+		changed = true
+		log.Info(
+			"Idle status changed",
+			"current",
+			currentStatus.Idle,
+			"new",
+			newStatus.Idle)
+	}
+	if !reflect.DeepEqual(newStatus.TaskManagerDecommission, currentStatus.TaskManagerDecommission) {
+		changed = true
+		log.Info(
+			"TaskManager decommission status changed",
+			"current",
+			currentStatus.TaskManagerDecommission,
+			"new",
+			newStatus.TaskManagerDecommission)
+	}
+	if !reflect.DeepEqual(newStatus.Conditions, currentStatus.Conditions) {
+		changed = true
+		log.Info(
+			"Conditions changed",
+			"current",
+			currentStatus.Conditions,
+			"new",
+			newStatus.Conditions)
+	}
+	if newStatus.QueuePosition != currentStatus.QueuePosition {
+		changed = true
+		log.Info(
+			"Queue position changed",
+			"current",
+			currentStatus.QueuePosition,
+			"new",
+			newStatus.QueuePosition)
+	}
+	if !reflect.DeepEqual(newStatus.SucceededDependencies, currentStatus.SucceededDependencies) {
+		changed = true
+		log.Info(
+			"Succeeded dependencies changed",
+			"current",
+			currentStatus.SucceededDependencies,
+			"new",
+			newStatus.SucceededDependencies)
+	}
 	if !reflect.DeepEqual(newStatus.Control, currentStatus.Control) {
 		log.Info(
 			"Control status changed", "current",
@@ -814,7 +1391,8 @@ func (updater *ClusterStatusUpdater) isStatusChanged(
 			newStatus.Components.ConfigMap)
 		changed = true
 	}
-	if !reflect.DeepEqual(newStatus.Components.JobManager, currentStatus.Components.JobManager) {
+	// The suggested memory of the last termination is compared semantically too.
+	if !equality.Semantic.DeepEqual(newStatus.Components.JobManager, currentStatus.Components.JobManager) {
 		log.Info(
 			"JobManager StatefulSet status changed",
 			"current", currentStatus.Components.JobManager,
@@ -840,7 +1418,8 @@ func (updater *ClusterStatusUpdater) isStatusChanged(
 			changed = true
 		}
 	} else {
-		if newStatus.Components.JobManagerIngress.State != currentStatus.Components.JobManagerIngress.State {
+		if newStatus.Components.JobManagerIngress.State != currentStatus.Components.JobManagerIngress.State ||
+			newStatus.Components.JobManagerIngress.NotReadyReason != currentStatus.Components.JobManagerIngress.NotReadyReason {
 			log.Info(
 				"JobManager ingress status changed",
 				"current",
@@ -850,7 +1429,7 @@ func (updater *ClusterStatusUpdater) isStatusChanged(
 			changed = true
 		}
 	}
-	if !reflect.DeepEqual(newStatus.Components.TaskManager, currentStatus.Components.TaskManager) {
+	if !equality.Semantic.DeepEqual(newStatus.Components.TaskManager, currentStatus.Components.TaskManager) {
 		log.Info(
 			"TaskManager StatefulSet status changed",
 			"current",
@@ -898,13 +1477,17 @@ func (updater *ClusterStatusUpdater) isStatusChanged(
 	var cr = currentStatus.Revision // Current revision status
 	if nr.CurrentRevision != cr.CurrentRevision ||
 		nr.NextRevision != cr.NextRevision ||
+		nr.UpdateStartTime != cr.UpdateStartTime ||
+		nr.NextRevisionTime != cr.NextRevisionTime ||
+		nr.UpdatePhase != cr.UpdatePhase ||
+		!reflect.DeepEqual(nr.UpdateFailure, cr.UpdateFailure) ||
 		(nr.CollisionCount != nil && cr.CollisionCount == nil) ||
 		(cr.CollisionCount != nil && *nr.CollisionCount != *cr.CollisionCount) {
 		log.Info(
 			"FlinkCluster revision status changed", "current",
-			fmt.Sprintf("currentRevision: %v, nextRevision: %v, collisionCount: %v", cr.CurrentRevision, cr.NextRevision, cr.CollisionCount),
+			fmt.Sprintf("currentRevision: %v, nextRevision: %v, collisionCount: %v, updatePhase: %v", cr.CurrentRevision, cr.NextRevision, cr.CollisionCount, cr.UpdatePhase),
 			"new",
-			fmt.Sprintf("currentRevision: %v, nextRevision: %v, collisionCount: %v", nr.CurrentRevision, nr.NextRevision, nr.CollisionCount))
+			fmt.Sprintf("currentRevision: %v, nextRevision: %v, collisionCount: %v, updatePhase: %v", nr.CurrentRevision, nr.NextRevision, nr.CollisionCount, nr.UpdatePhase))
 		changed = true
 	}
 	return changed
@@ -1038,6 +1621,30 @@ func deriveControlStatus(
 		return c
 	}
 
+	// The wake-up control is applied by the updater on the idle status when it is requested.
+	if recordedControl != nil && recordedControl.State == v1beta1.ControlStateRequested &&
+		recordedControl.Name == v1beta1.ControlNameWakeUp {
+		c = recordedControl.DeepCopy()
+		c.State = v1beta1.ControlStateSucceeded
+		util.SetTimestamp(&c.UpdateTime)
+		return c
+	}
+
+	// The canary update controls are applied by the updater on the recorded canary update
+	// status, they succeed if the canary update is still in progress.
+	if recordedControl != nil && recordedControl.State == v1beta1.ControlStateRequested &&
+		(recordedControl.Name == v1beta1.ControlNameCanaryPromote || recordedControl.Name == v1beta1.ControlNameCanaryAbort) {
+		c = recordedControl.DeepCopy()
+		if cluster.Status.CanaryUpdate.IsActive() {
+			c.State = v1beta1.ControlStateSucceeded
+		} else {
+			c.Message = "No canary update in progress."
+			c.State = v1beta1.ControlStateFailed
+		}
+		util.SetTimestamp(&c.UpdateTime)
+		return c
+	}
+
 	// Update control status in progress.
 	if recordedControl != nil && recordedControl.State == v1beta1.ControlStateInProgress {
 		c = recordedControl.DeepCopy()
@@ -1068,6 +1675,16 @@ func deriveControlStatus(
 			} else if newSavepoint.IsFailed() && newSavepoint.TriggerReason == v1beta1.SavepointReasonUserRequested {
 				c.State = v1beta1.ControlStateFailed
 			}
+		case v1beta1.ControlNameFlightRecording, v1beta1.ControlNameThreadDump, v1beta1.ControlNameDebug:
+			// The reconciler records the collection of the files, or the injection of the
+			// debug container, with its failures.
+			if c.Details[controlCollectTimeKey] != "" {
+				if c.Message != "" {
+					c.State = v1beta1.ControlStateFailed
+				} else {
+					c.State = v1beta1.ControlStateSucceeded
+				}
+			}
 		}
 		// Update time when state changed.
 		if c.State != v1beta1.ControlStateInProgress {
@@ -1085,6 +1702,98 @@ func deriveControlStatus(
 	return nil
 }
 
+// Derives the endpoints of the cluster from the status of the JobManager service and ingress.
+func deriveEndpointsStatus(
+	cluster *v1beta1.FlinkCluster,
+	components *v1beta1.FlinkClusterComponentsStatus,
+	externalDNSResolved bool) *v1beta1.FlinkClusterEndpoints {
+	var service = components.JobManagerService
+	if service.Name == "" || service.State == v1beta1.ComponentStateDeleted {
+		return nil
+	}
+
+	var _, uiPort = render.JobManagerUIPort(cluster)
+	var restURL = getFlinkAPIBaseURL(cluster)
+	var endpoints = &v1beta1.FlinkClusterEndpoints{
+		UI:   fmt.Sprintf("http://%s:%d", render.JobManagerServiceHost(cluster), uiPort),
+		REST: restURL,
+	}
+	if ingress := components.JobManagerIngress; ingress != nil && ingress.State == v1beta1.ComponentStateReady {
+		endpoints.IngressUI = append([]string(nil), ingress.URLs...)
+	}
+	for _, ingress := range service.LoadBalancerIngress {
+		var addr = ingress.Hostname
+		if addr == "" {
+			addr = ingress.IP
+		}
+		if addr != "" {
+			endpoints.LoadBalancerUI = append(endpoints.LoadBalancerUI, fmt.Sprintf("http://%s:%d", getURLHost(addr), uiPort))
+		}
+	}
+	if externalDNSResolved {
+		endpoints.ExternalDNSUI = getExternalDNSUI(cluster)
+	}
+
+	endpoints.Metrics = []v1beta1.MetricsEndpoint{{
+		Name: "rest",
+		URLs: []string{restURL + "/jobmanager/metrics", restURL + "/taskmanagers/metrics"},
+	}}
+	var selector = labels.SelectorFromSet(render.ClusterLabels(cluster)).String()
+	var reporters = cluster.GetPrometheusReporterPorts()
+	var names []string
+	for name := range reporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		endpoints.Metrics = append(endpoints.Metrics, v1beta1.MetricsEndpoint{
+			Name:     name,
+			Port:     reporters[name],
+			Selector: selector,
+		})
+	}
+	return endpoints
+}
+
+// Derives the CPU and memory of the pods of the observed components, by the replicas of the
+// StatefulSets and Deployment, which the HorizontalPodAutoscaler scales, and the active pods
+// of the Job of the job submitter or the JobManager in application mode. Nil if there are no
+// pods.
+func deriveResourcesStatus(observed *ObservedClusterState) *v1beta1.ClusterResourcesStatus {
+	var status = &v1beta1.ClusterResourcesStatus{Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+	var add = func(replicas *int32, podSpec *corev1.PodSpec) {
+		if replicas == nil || *replicas <= 0 {
+			return
+		}
+		var pod = v1beta1.GetPodResourceRequirements(podSpec.Containers, podSpec.InitContainers)
+		for _, list := range []struct{ total, pod corev1.ResourceList }{{status.Requests, pod.Requests}, {status.Limits, pod.Limits}} {
+			for name, q := range list.pod {
+				var sum = list.total[name]
+				sum.Add(*resource.NewMilliQuantity(q.MilliValue()*int64(*replicas), q.Format))
+				list.total[name] = sum
+			}
+		}
+		status.Pods += *replicas
+	}
+
+	if statefulSet := observed.jmStatefulSet; statefulSet != nil {
+		add(statefulSet.Spec.Replicas, &statefulSet.Spec.Template.Spec)
+	}
+	if statefulSet := observed.tmStatefulSet; statefulSet != nil {
+		add(statefulSet.Spec.Replicas, &statefulSet.Spec.Template.Spec)
+	}
+	if deployment := observed.tmDeployment; deployment != nil {
+		add(deployment.Spec.Replicas, &deployment.Spec.Template.Spec)
+	}
+	if job := observed.flinkJobSubmitter.job; job != nil {
+		add(&job.Status.Active, &job.Spec.Template.Spec)
+	}
+	if status.Pods == 0 {
+		return nil
+	}
+	return status
+}
+
 func deriveRevisionStatus(
 	updateState UpdateState,
 	observedRevision *Revision,
@@ -1115,6 +1824,54 @@ func deriveRevisionStatus(
 	return r
 }
 
+// deriveUpdateProgress returns the step of the update to the next revision in progress: the
+// job is stopped with a savepoint if the job changed, then the components are deleted and
+// created again, or updated in place if they are not recreated.
+func deriveUpdateProgress(observed *ObservedClusterState, revision *v1beta1.RevisionStatus) *v1beta1.UpdateProgressStatus {
+	var updateState = observed.updateState
+	if updateState != UpdateStatePreparing && updateState != UpdateStateInProgress {
+		return nil
+	}
+
+	var jobUpdate = isJobUpdate(observed.revisions, observed.cluster)
+	var recreate = shouldRecreateOnUpdate(observed)
+	var steps []v1beta1.UpdateStep
+	if jobUpdate {
+		steps = append(steps, v1beta1.UpdateStepSavepoint)
+	}
+	if recreate {
+		steps = append(steps, v1beta1.UpdateStepTeardown, v1beta1.UpdateStepRecreate)
+	} else {
+		steps = append(steps, v1beta1.UpdateStepUpdate)
+	}
+
+	var step v1beta1.UpdateStep
+	switch {
+	case jobUpdate && !hasReachedUpdatePhase(revision.UpdatePhase, v1beta1.UpdatePhaseJobStopped) &&
+		(updateState == UpdateStatePreparing || observed.cluster.Status.Components.Job.IsActive()):
+		step = v1beta1.UpdateStepSavepoint
+	case !recreate:
+		step = v1beta1.UpdateStepUpdate
+	case hasOutdatedComponents(observed):
+		step = v1beta1.UpdateStepTeardown
+	default:
+		step = v1beta1.UpdateStepRecreate
+	}
+
+	var progress = &v1beta1.UpdateProgressStatus{
+		Revision: revision.NextRevision,
+		Step:     step,
+		Total:    int32(len(steps)),
+	}
+	for i, s := range steps {
+		if s == step {
+			progress.Current = int32(i + 1)
+		}
+	}
+	progress.Percentage = (progress.Current - 1) * 100 / progress.Total
+	return progress
+}
+
 func getStatefulSetState(statefulSet *appsv1.StatefulSet) v1beta1.ComponentState {
 	if statefulSet.Status.ReadyReplicas >= *statefulSet.Spec.Replicas {
 		return v1beta1.ComponentStateReady
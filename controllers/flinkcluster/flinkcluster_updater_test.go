@@ -18,13 +18,21 @@ package flinkcluster
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/notification"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
 	"gotest.tools/v3/assert"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 )
 
 func TestGetStatefulSetStateNotReady(t *testing.T) {
@@ -215,4 +223,553 @@ func TestClusterStatus(t *testing.T) {
 		assert.Equal(t, newStatus.State, v1beta1.ClusterStateRunning)
 	})
 
+	t.Run("derive degraded and deleting", func(t *testing.T) {
+		replicas := int32(2)
+		var observed = ObservedClusterState{
+			jmStatefulSet: &appsv1.StatefulSet{
+				Spec:   appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 2},
+			},
+			jmService: &corev1.Service{
+				Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, ClusterIP: "127.0.0.1"},
+			},
+			tmStatefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-taskmanager"},
+				Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1},
+			},
+			revision: Revision{
+				currentRevision: &appsv1.ControllerRevision{Revision: 1},
+				nextRevision:    &appsv1.ControllerRevision{Revision: 1},
+			},
+			cluster: &v1beta1.FlinkCluster{
+				Spec: v1beta1.FlinkClusterSpec{
+					TaskManager: &v1beta1.TaskManagerSpec{DeploymentType: v1beta1.DeploymentTypeStatefulSet},
+				},
+				Status: v1beta1.FlinkClusterStatus{State: v1beta1.ClusterStateRunning},
+			},
+		}
+		var updater = &ClusterStatusUpdater{observed: observed}
+		var cluster = observed.cluster
+
+		// Only the TaskManagers are not ready.
+		var newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateDegraded)
+
+		// Other components are not ready.
+		cluster.Status.State = newStatus.State
+		observed.jmService.Spec.ClusterIP = ""
+		newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateReconciling)
+
+		cluster.Status.State = newStatus.State
+		observed.jmService.Spec.ClusterIP = "127.0.0.1"
+		observed.tmStatefulSet.Status.ReadyReplicas = 2
+		newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateRunning)
+
+		// The cluster being deleted stays in Deleting.
+		cluster.Status.State = newStatus.State
+		cluster.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateDeleting)
+		cluster.Status.State = newStatus.State
+		newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateDeleting)
+	})
+
+	t.Run("derive suspending and suspended", func(t *testing.T) {
+		replicas := int32(2)
+		var observed = ObservedClusterState{
+			jmStatefulSet: &appsv1.StatefulSet{
+				Spec:   appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 2},
+			},
+			jmService: &corev1.Service{
+				Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, ClusterIP: "127.0.0.1"},
+			},
+			tmStatefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-taskmanager"},
+				Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status:     appsv1.StatefulSetStatus{ReadyReplicas: 2},
+			},
+			revision: Revision{
+				currentRevision: &appsv1.ControllerRevision{Revision: 1},
+				nextRevision:    &appsv1.ControllerRevision{Revision: 1},
+			},
+			cluster: &v1beta1.FlinkCluster{
+				Spec: v1beta1.FlinkClusterSpec{
+					TaskManager: &v1beta1.TaskManagerSpec{DeploymentType: v1beta1.DeploymentTypeStatefulSet},
+					Job: &v1beta1.JobSpec{
+						CleanupPolicy: &v1beta1.CleanupPolicy{AfterJobCancelled: v1beta1.CleanupActionKeepCluster},
+					},
+				},
+				Status: v1beta1.FlinkClusterStatus{
+					State: v1beta1.ClusterStateRunning,
+					Components: v1beta1.FlinkClusterComponentsStatus{
+						Job: &v1beta1.JobStatus{ID: "8d2c9b3f", State: v1beta1.JobStateRunning},
+					},
+					Savepoint: &v1beta1.SavepointStatus{
+						JobID:         "8d2c9b3f",
+						TriggerReason: v1beta1.SavepointReasonJobCancel,
+						State:         v1beta1.SavepointStateInProgress,
+					},
+				},
+			},
+		}
+		var updater = &ClusterStatusUpdater{observed: observed}
+		var cluster = observed.cluster
+
+		// The job is being stopped with a savepoint.
+		var newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateSuspending)
+		cluster.Status.State = newStatus.State
+		cluster.Status.Savepoint.State = v1beta1.SavepointStateSucceeded
+		newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateSuspending)
+
+		// The job is stopped with the savepoint and the cluster is kept.
+		cluster.Status.Components.Job.State = v1beta1.JobStateCancelled
+		newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateSuspended)
+		cluster.Status.State = newStatus.State
+		newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateSuspended)
+
+		// The cluster is stopped if it is not kept.
+		cluster.Spec.Job.CleanupPolicy.AfterJobCancelled = v1beta1.CleanupActionDeleteCluster
+		newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateStopping)
+
+		// The job goes on running if the savepoint failed.
+		cluster.Status.State = v1beta1.ClusterStateSuspending
+		cluster.Status.Components.Job.State = v1beta1.JobStateRunning
+		cluster.Status.Savepoint.State = v1beta1.SavepointStateFailed
+		newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateRunning)
+
+		// A job stopped for an update is not suspended.
+		cluster.Status.State = v1beta1.ClusterStateRunning
+		cluster.Status.Savepoint.TriggerReason = v1beta1.SavepointReasonUpdate
+		cluster.Status.Savepoint.State = v1beta1.SavepointStateInProgress
+		newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateRunning)
+	})
+
+	t.Run("derive rolling back", func(t *testing.T) {
+		replicas := int32(2)
+		recreateOnUpdate := false
+		var observed = ObservedClusterState{
+			jmStatefulSet: &appsv1.StatefulSet{
+				Spec:   appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status: appsv1.StatefulSetStatus{ReadyReplicas: 2},
+			},
+			jmService: &corev1.Service{
+				Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, ClusterIP: "127.0.0.1"},
+			},
+			tmStatefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-taskmanager"},
+				Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status:     appsv1.StatefulSetStatus{ReadyReplicas: 2},
+			},
+			revision: Revision{
+				currentRevision: &appsv1.ControllerRevision{Revision: 2},
+				nextRevision:    &appsv1.ControllerRevision{Revision: 3},
+			},
+			updateState: UpdateStateInProgress,
+			cluster: &v1beta1.FlinkCluster{
+				Spec: v1beta1.FlinkClusterSpec{
+					TaskManager:      &v1beta1.TaskManagerSpec{DeploymentType: v1beta1.DeploymentTypeStatefulSet},
+					RecreateOnUpdate: &recreateOnUpdate,
+				},
+				Status: v1beta1.FlinkClusterStatus{
+					State: v1beta1.ClusterStateRunning,
+					Components: v1beta1.FlinkClusterComponentsStatus{
+						ConfigMap:   &v1beta1.ConfigMapStatus{Name: "my-configmap", State: v1beta1.ComponentStateReady},
+						JobManager:  &v1beta1.JobManagerStatus{Name: "my-jobmanager", State: v1beta1.ComponentStateReady},
+						TaskManager: &v1beta1.TaskManagerStatus{Name: "my-taskmanager", State: v1beta1.ComponentStateReady},
+					},
+					Revision: v1beta1.RevisionStatus{
+						CurrentRevision: "mycluster-85dc8f749-2",
+						NextRevision:    "mycluster-7bc7d7c6d4-3",
+						UpdateFailure: &v1beta1.UpdateFailureStatus{
+							Revision:         "mycluster-85dc8f749-2",
+							Reason:           "The job restored from savepoint gs://my-bucket/savepoint-1 failed the verification: no checkpoint completed within 600s.",
+							Time:             "2022-05-01T12:10:00Z",
+							RollbackRevision: "mycluster-7bc7d7c6d4-1",
+						},
+					},
+				},
+			},
+		}
+		var updater = &ClusterStatusUpdater{observed: observed}
+		var cluster = observed.cluster
+
+		// The update to the revision rolled back to.
+		var newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateRollingBack)
+		cluster.Status.State = newStatus.State
+		newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateRollingBack)
+
+		// Another update is not a rollback.
+		cluster.Status.State = v1beta1.ClusterStateRunning
+		cluster.Status.Revision.UpdateFailure.RollbackRevision = ""
+		newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateUpdating)
+
+		// The rollback is finished.
+		cluster.Status.State = v1beta1.ClusterStateRollingBack
+		cluster.Status.Revision.UpdateFailure.RollbackRevision = "mycluster-7bc7d7c6d4-1"
+		cluster.Status.Revision.CurrentRevision = "mycluster-7bc7d7c6d4-3"
+		observed.revision.currentRevision = observed.revision.nextRevision
+		observed.updateState = UpdateStateFinished
+		newStatus = updater.deriveClusterStatus(context.TODO(), cluster, &observed)
+		assert.Equal(t, newStatus.State, v1beta1.ClusterStateRunning)
+	})
+}
+
+func TestGetNotificationEvents(t *testing.T) {
+	var now = time.Now()
+	var cluster = &v1beta1.FlinkCluster{ObjectMeta: metav1.ObjectMeta{Name: "mycluster", Namespace: "default"}}
+	var oldStatus = v1beta1.FlinkClusterStatus{
+		State: v1beta1.ClusterStateRunning,
+		Components: v1beta1.FlinkClusterComponentsStatus{
+			Job: &v1beta1.JobStatus{ID: "8d2c9b3f", State: v1beta1.JobStateRunning},
+		},
+		Savepoint: &v1beta1.SavepointStatus{TriggerID: "1", State: v1beta1.SavepointStateInProgress},
+	}
+	assert.Equal(t, len(getNotificationEvents(cluster, oldStatus, oldStatus, now)), 0)
+
+	var newStatus = *oldStatus.DeepCopy()
+	newStatus.State = v1beta1.ClusterStateReconciling
+	newStatus.Components.Job.State = v1beta1.JobStateFailed
+	newStatus.Components.Job.FailureReasons = []string{"java.lang.OutOfMemoryError"}
+	newStatus.Components.JobManagerIngress = &v1beta1.JobManagerIngressStatus{URLs: []string{"https://flink.example.com"}}
+	newStatus.Savepoint.State = v1beta1.SavepointStateFailed
+	newStatus.Savepoint.Message = "Checkpoint expired"
+
+	var events = getNotificationEvents(cluster, oldStatus, newStatus, now)
+	assert.DeepEqual(t, events, []notification.Event{
+		{
+			Type:      notification.EventJobFailed,
+			Namespace: "default",
+			Name:      "mycluster",
+			Message:   "Job failed: java.lang.OutOfMemoryError",
+			JobID:     "8d2c9b3f",
+			WebUIURL:  "https://flink.example.com",
+			Time:      now,
+		},
+		{
+			Type:      notification.EventSavepointFailed,
+			Namespace: "default",
+			Name:      "mycluster",
+			Message:   "Savepoint failed: Checkpoint expired",
+			JobID:     "8d2c9b3f",
+			WebUIURL:  "https://flink.example.com",
+			Time:      now,
+		},
+		{
+			Type:      notification.EventClusterDegraded,
+			Namespace: "default",
+			Name:      "mycluster",
+			Message:   "Cluster components are not ready",
+			JobID:     "8d2c9b3f",
+			WebUIURL:  "https://flink.example.com",
+			Time:      now,
+		},
+	})
+
+	// Transitions are notified once.
+	assert.Equal(t, len(getNotificationEvents(cluster, newStatus, newStatus, now)), 0)
+
+	newStatus = *oldStatus.DeepCopy()
+	newStatus.State = v1beta1.ClusterStateDegraded
+	events = getNotificationEvents(cluster, oldStatus, newStatus, now)
+	assert.Equal(t, len(events), 1)
+	assert.Equal(t, events[0].Type, notification.EventClusterDegraded)
+	assert.Equal(t, events[0].Message, "TaskManagers are not ready")
+
+	newStatus = *oldStatus.DeepCopy()
+	newStatus.State = v1beta1.ClusterStateSuspended
+	newStatus.Components.Job.State = v1beta1.JobStateCancelled
+	newStatus.Components.Job.SavepointLocation = "gs://my-bucket/savepoint-1"
+	events = getNotificationEvents(cluster, oldStatus, newStatus, now)
+	assert.Equal(t, len(events), 1)
+	assert.Equal(t, events[0].Type, notification.EventClusterSuspended)
+	assert.Equal(t, events[0].Message, "Job stopped with savepoint gs://my-bucket/savepoint-1")
+	assert.Equal(t, len(getNotificationEvents(cluster, newStatus, newStatus, now)), 0)
+
+	newStatus = *oldStatus.DeepCopy()
+	newStatus.State = v1beta1.ClusterStateRollingBack
+	newStatus.Revision.UpdateFailure = &v1beta1.UpdateFailureStatus{
+		Revision:         "mycluster-85dc8f749-2",
+		RollbackRevision: "mycluster-7bc7d7c6d4-1",
+	}
+	events = getNotificationEvents(cluster, oldStatus, newStatus, now)
+	assert.Equal(t, len(events), 1)
+	assert.Equal(t, events[0].Type, notification.EventClusterRollingBack)
+	assert.Equal(t, events[0].Message,
+		"Rolling back the failed update from revision mycluster-85dc8f749-2 to revision mycluster-7bc7d7c6d4-1")
+}
+
+func TestCreateTerminationEvent(t *testing.T) {
+	var recorder = record.NewFakeRecorder(2)
+	var updater = &ClusterStatusUpdater{
+		observed: ObservedClusterState{cluster: &v1beta1.FlinkCluster{}},
+		recorder: recorder,
+	}
+	var suggestedMemory = resource.MustParse("1536Mi")
+	var termination = &v1beta1.ContainerTerminationStatus{
+		Pod:             "mycluster-jobmanager-0",
+		Reason:          "OOMKilled",
+		ExitCode:        137,
+		FinishedAt:      "2022-03-01T12:00:00Z",
+		SuggestedMemory: &suggestedMemory,
+	}
+
+	updater.createTerminationEvent("JobManager", nil, termination, 1)
+	assert.Equal(t, <-recorder.Events, "Warning ContainerOOMKilled JobManager container of pod mycluster-jobmanager-0 "+
+		"terminated with OOMKilled, exit code 137, 1 restarts in total, consider a memory limit of 1536Mi")
+
+	// Terminations are reported once.
+	updater.createTerminationEvent("JobManager", termination.DeepCopy(), termination, 1)
+	var next = &v1beta1.ContainerTerminationStatus{
+		Pod:        "mycluster-jobmanager-0",
+		Reason:     "Error",
+		ExitCode:   1,
+		FinishedAt: "2022-03-01T12:05:00Z",
+	}
+	updater.createTerminationEvent("JobManager", termination, next, 2)
+	assert.Equal(t, <-recorder.Events, "Warning ContainerTerminated JobManager container of pod mycluster-jobmanager-0 "+
+		"terminated with Error, exit code 1, 2 restarts in total")
+	assert.Equal(t, len(recorder.Events), 0)
+}
+
+func TestDeriveFlightRecordingControlStatus(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{v1beta1.ControlAnnotation: v1beta1.ControlNameFlightRecording},
+		},
+	}
+	var recorded = &v1beta1.FlinkClusterControlStatus{
+		Name:    v1beta1.ControlNameFlightRecording,
+		State:   v1beta1.ControlStateInProgress,
+		Details: map[string]string{flightRecordingFileKey: "recording-20261014T101500Z.jfr"},
+	}
+	cluster.Status.Control = recorded
+	var job = &v1beta1.JobStatus{}
+
+	// The recordings are not collected yet.
+	var control = deriveControlStatus(cluster, nil, job, recorded)
+	assert.Equal(t, control.State, v1beta1.ControlStateInProgress)
+
+	recorded.Details[controlCollectTimeKey] = "2026-10-14T10:16:10Z"
+	control = deriveControlStatus(cluster, nil, job, recorded)
+	assert.Equal(t, control.State, v1beta1.ControlStateSucceeded)
+
+	recorded.Message = "pod mycluster-taskmanager-1 not found"
+	control = deriveControlStatus(cluster, nil, job, recorded)
+	assert.Equal(t, control.State, v1beta1.ControlStateFailed)
+}
+
+func TestDeriveThreadDumpControlStatus(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{v1beta1.ControlAnnotation: v1beta1.ControlNameThreadDump},
+		},
+	}
+	var recorded = &v1beta1.FlinkClusterControlStatus{
+		Name:    v1beta1.ControlNameThreadDump,
+		State:   v1beta1.ControlStateInProgress,
+		Details: map[string]string{threadDumpConfigMapKey: "mycluster-thread-dump", controlCollectTimeKey: "2026-10-14T10:15:00Z"},
+	}
+	cluster.Status.Control = recorded
+	var job = &v1beta1.JobStatus{}
+
+	var control = deriveControlStatus(cluster, nil, job, recorded)
+	assert.Equal(t, control.State, v1beta1.ControlStateSucceeded)
+	assert.Equal(t, control.Details[threadDumpConfigMapKey], "mycluster-thread-dump")
+
+	recorded.Message = "failed to take thread dump of the JobManager: connection refused"
+	control = deriveControlStatus(cluster, nil, job, recorded)
+	assert.Equal(t, control.State, v1beta1.ControlStateFailed)
+}
+
+func TestDeriveEndpointsStatus(t *testing.T) {
+	var uiPort int32 = 8081
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "mycluster", Namespace: "default"},
+		Spec: v1beta1.FlinkClusterSpec{
+			JobManager: &v1beta1.JobManagerSpec{
+				AccessScope: v1beta1.AccessScopeVPC,
+				Ports:       v1beta1.JobManagerPorts{UI: &uiPort},
+			},
+			FlinkProperties: map[string]string{
+				"metrics.reporter.prom.factory.class": "org.apache.flink.metrics.prometheus.PrometheusReporterFactory",
+			},
+		},
+	}
+	var components = &v1beta1.FlinkClusterComponentsStatus{
+		JobManagerService: v1beta1.JobManagerServiceStatus{
+			Name:                "mycluster-jobmanager",
+			State:               v1beta1.ComponentStateReady,
+			LoadBalancerIngress: []corev1.LoadBalancerIngress{{IP: "10.128.0.10"}},
+		},
+		JobManagerIngress: &v1beta1.JobManagerIngressStatus{
+			Name:  "mycluster-jobmanager",
+			State: v1beta1.ComponentStateReady,
+			URLs:  []string{"https://mycluster.example.com"},
+		},
+	}
+
+	assert.DeepEqual(t, deriveEndpointsStatus(cluster, components, false), &v1beta1.FlinkClusterEndpoints{
+		UI:             "http://mycluster-jobmanager.default.svc.cluster.local:8081",
+		IngressUI:      []string{"https://mycluster.example.com"},
+		LoadBalancerUI: []string{"http://10.128.0.10:8081"},
+		REST:           "http://mycluster-jobmanager.default.svc.cluster.local:8081",
+		Metrics: []v1beta1.MetricsEndpoint{
+			{
+				Name: "rest",
+				URLs: []string{
+					"http://mycluster-jobmanager.default.svc.cluster.local:8081/jobmanager/metrics",
+					"http://mycluster-jobmanager.default.svc.cluster.local:8081/taskmanagers/metrics",
+				},
+			},
+			{Name: "prom", Port: 9249, Selector: "app=flink,cluster=mycluster"},
+		},
+	})
+
+	// The web UI is served through the read-only UI proxy.
+	var readOnlyUI = true
+	cluster.Spec.JobManager.ReadOnlyUI = &readOnlyUI
+	components.JobManagerIngress.State = v1beta1.ComponentStateNotReady
+	var endpoints = deriveEndpointsStatus(cluster, components, false)
+	assert.Equal(t, endpoints.UI, fmt.Sprintf("http://mycluster-jobmanager.default.svc.cluster.local:%d", v1beta1.ReadOnlyUIProxyPort))
+	assert.Equal(t, endpoints.REST, "http://mycluster-jobmanager.default.svc.cluster.local:8081")
+	assert.Assert(t, endpoints.IngressUI == nil)
+
+	// The web UI is published under the external DNS hostname once it resolves.
+	cluster.Spec.JobManager.ReadOnlyUI = nil
+	cluster.Spec.JobManager.ExternalDNS = &v1beta1.ExternalDNSSpec{Hostname: "{{$clusterName}}.flink.example.com"}
+	assert.Equal(t, deriveEndpointsStatus(cluster, components, false).ExternalDNSUI, "")
+	assert.Equal(t, deriveEndpointsStatus(cluster, components, true).ExternalDNSUI, "http://mycluster.flink.example.com:8081")
+
+	components.JobManagerService.State = v1beta1.ComponentStateDeleted
+	assert.Assert(t, deriveEndpointsStatus(cluster, components, false) == nil)
+}
+
+func TestDeriveResourcesStatus(t *testing.T) {
+	var podSpec = func(cpu, memory string, limits bool) corev1.PodSpec {
+		var resources = corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}}
+		if limits {
+			resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory)}
+		}
+		return corev1.PodSpec{Containers: []corev1.Container{{Resources: resources}}}
+	}
+	var jmReplicas, tmReplicas int32 = 1, 3
+	var observed = &ObservedClusterState{
+		jmStatefulSet: &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{
+			Replicas: &jmReplicas,
+			Template: corev1.PodTemplateSpec{Spec: podSpec("500m", "1Gi", true)},
+		}},
+		tmStatefulSet: &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{
+			Replicas: &tmReplicas,
+			Template: corev1.PodTemplateSpec{Spec: podSpec("2", "4Gi", true)},
+		}},
+		flinkJobSubmitter: FlinkJobSubmitter{job: &batchv1.Job{
+			Spec:   batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: podSpec("200m", "512Mi", false)}},
+			Status: batchv1.JobStatus{Active: 1},
+		}},
+	}
+
+	var status = deriveResourcesStatus(observed)
+	assert.Equal(t, status.Pods, int32(5))
+	assert.Equal(t, status.Requests.Cpu().String(), "6700m")
+	assert.Equal(t, status.Requests.Memory().String(), "13824Mi")
+	assert.Assert(t, status.Limits.Cpu().IsZero())
+	assert.Equal(t, status.Limits.Memory().String(), "13Gi")
+
+	// The TaskManagers are scaled and the job submitter completed.
+	tmReplicas = 1
+	observed.flinkJobSubmitter.job.Status = batchv1.JobStatus{Succeeded: 1}
+	status = deriveResourcesStatus(observed)
+	assert.Equal(t, status.Pods, int32(2))
+	assert.Equal(t, status.Requests.Cpu().String(), "2500m")
+	assert.Equal(t, status.Limits.Memory().String(), "5Gi")
+
+	// A parsed status is not changed.
+	var recorded = &v1beta1.ClusterResourcesStatus{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2.5"), corev1.ResourceMemory: resource.MustParse("5Gi")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("5120Mi")},
+		Pods:     2,
+	}
+	var updater = &ClusterStatusUpdater{}
+	assert.Assert(t, !updater.isStatusChanged(context.TODO(),
+		v1beta1.FlinkClusterStatus{Resources: recorded}, v1beta1.FlinkClusterStatus{Resources: status}))
+
+	observed.jmStatefulSet = nil
+	observed.tmStatefulSet = nil
+	assert.Assert(t, deriveResourcesStatus(observed) == nil)
+}
+
+func TestDeriveUpdateProgress(t *testing.T) {
+	var recreateOnUpdate = true
+	var revision = &v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-aa5e3a87z-3"}
+	var observed = &ObservedClusterState{
+		cluster: &v1beta1.FlinkCluster{
+			Spec: v1beta1.FlinkClusterSpec{
+				TaskManager:      &v1beta1.TaskManagerSpec{DeploymentType: v1beta1.DeploymentTypeStatefulSet},
+				Job:              &v1beta1.JobSpec{},
+				RecreateOnUpdate: &recreateOnUpdate,
+			},
+			Status: v1beta1.FlinkClusterStatus{
+				Components: v1beta1.FlinkClusterComponentsStatus{Job: &v1beta1.JobStatus{State: v1beta1.JobStateRunning}},
+				Revision:   *revision,
+			},
+		},
+		revisions: []*appsv1.ControllerRevision{
+			{Revision: 2, Data: runtime.RawExtension{Raw: []byte(`{"spec":{"job":{"jarFile":"v1.jar"}}}`)}},
+			{Revision: 3, Data: runtime.RawExtension{Raw: []byte(`{"spec":{"job":{"jarFile":"v2.jar"}}}`)}},
+		},
+		jmStatefulSet: &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-85dc8f749"}}},
+		tmStatefulSet: &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-aa5e3a87z"}}},
+		updateState:   UpdateStatePreparing,
+	}
+
+	// The job is stopped with a savepoint.
+	assert.DeepEqual(t, deriveUpdateProgress(observed, revision), &v1beta1.UpdateProgressStatus{
+		Revision:   "cluster-aa5e3a87z-3",
+		Step:       v1beta1.UpdateStepSavepoint,
+		Current:    1,
+		Total:      3,
+		Percentage: 0,
+	})
+
+	// The JobManager of the current revision is not deleted yet.
+	observed.updateState = UpdateStateInProgress
+	observed.cluster.Status.Components.Job.State = v1beta1.JobStateSucceeded
+	var progress = deriveUpdateProgress(observed, revision)
+	assert.Equal(t, progress.Step, v1beta1.UpdateStepTeardown)
+	assert.Equal(t, progress.Current, int32(2))
+	assert.Equal(t, progress.Percentage, int32(33))
+
+	observed.jmStatefulSet = nil
+	progress = deriveUpdateProgress(observed, revision)
+	assert.Equal(t, progress.Step, v1beta1.UpdateStepRecreate)
+	assert.Equal(t, progress.Percentage, int32(66))
+
+	// Updated in place.
+	recreateOnUpdate = false
+	observed.revisions = nil
+	progress = deriveUpdateProgress(observed, revision)
+	assert.Equal(t, progress.Step, v1beta1.UpdateStepUpdate)
+	assert.Equal(t, progress.Current, int32(1))
+	assert.Equal(t, progress.Total, int32(1))
+
+	observed.updateState = UpdateStateFinished
+	assert.Assert(t, deriveUpdateProgress(observed, revision) == nil)
 }
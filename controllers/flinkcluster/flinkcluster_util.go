@@ -17,30 +17,36 @@ limitations under the License.
 package flinkcluster
 
 import (
-	"bytes"
-	"crypto/md5"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"net"
+	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/go-version"
 	"github.com/spotify/flink-on-k8s-operator/internal/flink"
 	"github.com/spotify/flink-on-k8s-operator/internal/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
 	"github.com/spotify/flink-on-k8s-operator/internal/controllers/history"
+	"github.com/spotify/flink-on-k8s-operator/pkg/model"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -48,14 +54,16 @@ const (
 	ControlRetries    = "retries"
 	ControlMaxRetries = "3"
 
-	RevisionNameLabel = "flinkoperator.k8s.io/revision-name"
-	JobIdLabel        = "flinkoperator.k8s.io/job-id"
+	// Job clusters with the same value of this label share a concurrency limit in a namespace.
+	QueueLabel = "flinkoperator.k8s.io/queue"
 
 	SavepointRetryIntervalSeconds = 10
 )
 
 var (
 	jobIdRegexp = regexp.MustCompile("JobID (.*)\n")
+	// Printed by the submitter when it resolves the entry class from the manifest of the JAR file.
+	entryClassRegexp = regexp.MustCompile("Resolved the entry class (\\S+) from the manifest")
 )
 
 type UpdateState string
@@ -83,60 +91,15 @@ type objectMetaForPatch struct {
 }
 
 func getFlinkAPIBaseURL(cluster *v1beta1.FlinkCluster) string {
-	clusterDomain := os.Getenv("CLUSTER_DOMAIN")
-	if clusterDomain == "" {
-		clusterDomain = "cluster.local"
-	}
-
-	return fmt.Sprintf(
-		"http://%s.%s.svc.%s:%d",
-		getJobManagerServiceName(cluster.Name),
-		cluster.Namespace,
-		clusterDomain,
-		*cluster.Spec.JobManager.Ports.UI)
-}
-
-// Gets ConfigMap name
-func getConfigMapName(clusterName string) string {
-	return clusterName + "-configmap"
-}
-
-// Gets PodDisruptionBudgetName name
-func getPodDisruptionBudgetName(clusterName string) string {
-	return "flink-" + clusterName
-}
-
-// Get HorizontalPodAutoscaler name
-func getHorizontalPodAutoscalerName(clusterName string) string {
-	return "flink-" + clusterName
-}
-
-// Gets JobManager StatefulSet name
-func getJobManagerName(clusterName string) string {
-	return clusterName + "-jobmanager"
-}
-
-// Gets JobManager service name
-func getJobManagerServiceName(clusterName string) string {
-	return clusterName + "-jobmanager"
-}
-
-// Gets JobManager ingress name
-func getJobManagerIngressName(clusterName string) string {
-	return clusterName + "-jobmanager"
-}
-
-// Gets TaskManager StatefulSet name
-func getTaskManagerName(clusterName string) string {
-	return clusterName + "-taskmanager"
-}
-
-func getJobManagerJobName(clusterName string) string {
-	return getJobManagerName(clusterName)
+	return fmt.Sprintf("http://%s:%d", render.JobManagerServiceHost(cluster), *cluster.Spec.JobManager.Ports.UI)
 }
 
-func getSubmitterJobName(clusterName string) string {
-	return clusterName + "-job-submitter"
+// Gets the host of a URL, IPv6 literals are enclosed in brackets.
+func getURLHost(host string) string {
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		return "[" + host + "]"
+	}
+	return host
 }
 
 // Checks whether it is possible to take savepoint.
@@ -149,12 +112,34 @@ func canTakeSavepoint(cluster *v1beta1.FlinkCluster) bool {
 		(savepointStatus == nil || savepointStatus.State != v1beta1.SavepointStateInProgress)
 }
 
-// Checks if the job should be stopped because a job-cancel was requested
-func shouldStopJob(cluster *v1beta1.FlinkCluster) bool {
-	var userControl = cluster.Annotations[v1beta1.ControlAnnotation]
-	var cancelRequested = cluster.Spec.Job.CancelRequested
-	return userControl == v1beta1.ControlNameJobCancel ||
-		(cancelRequested != nil && *cancelRequested)
+// Jobs are stopped with a savepoint by the stop endpoint since Flink 1.9.
+var v19, _ = version.NewVersion("1.9")
+
+// getUpdateStopMode returns how the running job is stopped for an update, see
+// spec.job.updateStopMode. The job is cancelled without a savepoint if it is restored from
+// spec.job.fromSavepoint.
+func getUpdateStopMode(cluster *v1beta1.FlinkCluster) v1beta1.JobUpdateStopMode {
+	var jobSpec = cluster.Spec.Job
+	var takeSavepoint = jobSpec.TakeSavepointOnUpdate == nil || *jobSpec.TakeSavepointOnUpdate
+	switch {
+	case !takeSavepoint || !util.IsBlank(jobSpec.FromSavepoint):
+		return v1beta1.JobUpdateStopModeCancel
+	case jobSpec.UpdateStopMode != nil:
+		return *jobSpec.UpdateStopMode
+	}
+	if flinkVersion, err := version.NewVersion(cluster.Spec.FlinkVersion); err == nil && flinkVersion.LessThan(v19) {
+		return v1beta1.JobUpdateStopModeCancelWithSavepoint
+	}
+	return v1beta1.JobUpdateStopModeStopWithSavepoint
+}
+
+// isStoppingJobForUpdate returns true if the running job is to be stopped for the triggered
+// update, unless the update is deferred by spec.updatePolicy or the job has been stopped
+// already, i.e. the running job is the job of the next revision.
+func isStoppingJobForUpdate(observed *ObservedClusterState) bool {
+	var revision = &observed.cluster.Status.Revision
+	return revision.IsUpdateTriggered() && isJobUpdate(observed.revisions, observed.cluster) &&
+		!isUpdateDeferred(observed.cluster, revision, observed.observeTime) && !isJobStoppedForUpdate(observed)
 }
 
 func getFromSavepoint(jobSpec batchv1.JobSpec) string {
@@ -167,76 +152,144 @@ func getFromSavepoint(jobSpec batchv1.JobSpec) string {
 	return ""
 }
 
-// newRevision generates FlinkClusterSpec patch and makes new child ControllerRevision resource with it.
-func newRevision(cluster *v1beta1.FlinkCluster, revision int64, collisionCount *int32) (*appsv1.ControllerRevision, error) {
-	patch, err := newRevisionDataPatch(cluster)
-	if err != nil {
-		return nil, err
+// ConfigMap or Secret referenced by the cluster spec.
+type configReference struct {
+	kind string
+	name string
+}
+
+func shouldUpdateOnReferencedConfigChange(cluster *v1beta1.FlinkCluster) bool {
+	var update = cluster.Spec.UpdateOnReferencedConfigChange
+	return update != nil && *update
+}
+
+// The field index of the clusters by the ConfigMaps and Secrets whose changes update them.
+const referencedConfigIndex = "referencedConfigs"
+
+// Gets the key of the reference in the referencedConfigIndex.
+func (ref configReference) key() string {
+	return ref.kind + "/" + ref.name
+}
+
+// Gets the keys of the ConfigMaps and Secrets whose changes update the cluster: the Secrets of
+// spec.flinkPropertiesFrom, and the referenced configs if updateOnReferencedConfigChange is set.
+func getReferencedConfigKeys(cluster *v1beta1.FlinkCluster) []string {
+	var keys []string
+	for _, source := range cluster.Spec.FlinkPropertiesFrom {
+		if source.SecretRef != nil {
+			keys = append(keys, configReference{kind: "Secret", name: source.SecretRef.Name}.key())
+		}
 	}
-	cr, err := history.NewControllerRevision(cluster,
-		controllerKind,
-		cluster.Labels,
-		runtime.RawExtension{Raw: patch},
-		revision,
-		collisionCount)
-	if err != nil {
-		return nil, err
+	if shouldUpdateOnReferencedConfigChange(cluster) {
+		for _, ref := range getReferencedConfigs(cluster) {
+			keys = append(keys, ref.key())
+		}
 	}
-	if cr.Annotations == nil {
-		cr.Annotations = make(map[string]string)
+	return keys
+}
+
+// Gets the ConfigMaps and Secrets referenced by the cluster spec, sorted and without duplicates.
+func getReferencedConfigs(cluster *v1beta1.FlinkCluster) []configReference {
+	var seen = map[configReference]bool{}
+	var refs []configReference
+	var add = func(kind, name string) {
+		var ref = configReference{kind: kind, name: name}
+		if name == "" || seen[ref] {
+			return
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
 	}
-	for key, value := range cluster.Annotations {
-		cr.Annotations[key] = value
+	var addVolumes = func(volumes []corev1.Volume) {
+		for _, volume := range volumes {
+			if volume.ConfigMap != nil {
+				add("ConfigMap", volume.ConfigMap.Name)
+			}
+			if volume.Secret != nil {
+				add("Secret", volume.Secret.SecretName)
+			}
+		}
 	}
-	cr.SetNamespace(cluster.GetNamespace())
-	cr.GetLabels()[history.ControllerRevisionManagedByLabel] = cluster.GetName()
-	return cr, nil
-}
 
-func newRevisionDataPatch(cluster *v1beta1.FlinkCluster) ([]byte, error) {
-	// Ignore fields not related to rendering job resource.
-	var c *v1beta1.FlinkCluster
-	if cluster.Spec.Job != nil {
-		c = cluster.DeepCopy()
-		c.Spec.Job.CleanupPolicy = nil
-		c.Spec.Job.RestartPolicy = nil
-		c.Spec.Job.CancelRequested = nil
-		c.Spec.Job.SavepointGeneration = 0
-	} else {
-		c = cluster
+	var spec = &cluster.Spec
+	if spec.HadoopConfig != nil {
+		add("ConfigMap", spec.HadoopConfig.ConfigMapName)
+	}
+	if spec.GCPConfig != nil && spec.GCPConfig.ServiceAccount != nil {
+		add("Secret", spec.GCPConfig.ServiceAccount.SecretName)
+	}
+	for _, mount := range spec.ExtraConfigMounts {
+		if mount.ConfigMap != nil {
+			add("ConfigMap", mount.ConfigMap.Name)
+		}
+		if mount.Secret != nil {
+			add("Secret", mount.Secret.Name)
+		}
+	}
+	if spec.ConfigOverride != nil {
+		add("ConfigMap", spec.ConfigOverride.ConfigMapName)
+	}
+	if spec.Networking != nil && spec.Networking.CABundle != nil {
+		add("ConfigMap", spec.Networking.CABundle.ConfigMapName)
+	}
+	for _, injection := range spec.SecretsInjection {
+		add("Secret", injection.SecretKeyRef.Name)
+	}
+	for _, envFrom := range spec.EnvFrom {
+		if envFrom.ConfigMapRef != nil {
+			add("ConfigMap", envFrom.ConfigMapRef.Name)
+		}
+		if envFrom.SecretRef != nil {
+			add("Secret", envFrom.SecretRef.Name)
+		}
+	}
+	if spec.JobManager != nil {
+		addVolumes(spec.JobManager.Volumes)
+	}
+	if spec.TaskManager != nil {
+		addVolumes(spec.TaskManager.Volumes)
+	}
+	if spec.Job != nil {
+		for _, source := range spec.Job.ArgsFrom {
+			if source.ConfigMapKeyRef != nil {
+				add("ConfigMap", source.ConfigMapKeyRef.Name)
+			}
+			if source.SecretKeyRef != nil {
+				add("Secret", source.SecretKeyRef.Name)
+			}
+		}
 	}
 
-	str := &bytes.Buffer{}
-	err := unstructured.UnstructuredJSONScheme.Encode(c, str)
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].kind != refs[j].kind {
+			return refs[i].kind < refs[j].kind
+		}
+		return refs[i].name < refs[j].name
+	})
+	return refs
+}
 
-	if err != nil {
-		return nil, err
+// Writes the data of the referenced ConfigMap or Secret to the hash, nil data means it does not exist.
+func writeReferencedConfig(hash io.Writer, ref configReference, data map[string][]byte) {
+	fmt.Fprintf(hash, "%s/%s\n", ref.kind, ref.name)
+	if data == nil {
+		fmt.Fprint(hash, "<missing>\n")
+		return
 	}
-	var raw map[string]interface{}
-	json.Unmarshal([]byte(str.Bytes()), &raw)
-	objCopy := make(map[string]interface{})
-	spec := raw["spec"].(map[string]interface{})
-	objCopy["spec"] = spec
-	spec["$patch"] = "replace"
-
-	// backward compatibility fix
-	if c.Spec.Job != nil {
-		job := spec["job"].(map[string]interface{})
-		job["restartPolicy"] = nil
+	var keys []string
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%x\n", key, data[key])
 	}
-
-	patch, err := json.Marshal(objCopy)
-	return patch, err
 }
 
 func getCurrentRevisionName(r *v1beta1.RevisionStatus) string {
 	return r.CurrentRevision[:strings.LastIndex(r.CurrentRevision, "-")]
 }
 
-func getNextRevisionName(r *v1beta1.RevisionStatus) string {
-	return r.NextRevision[:strings.LastIndex(r.NextRevision, "-")]
-}
-
 func getRetryCount(data map[string]string) (string, error) {
 	var err error
 	var retries, ok = data["retries"]
@@ -329,6 +382,9 @@ func getSavepointEvent(status v1beta1.SavepointStatus) (eventType string, eventR
 		eventType = corev1.EventTypeNormal
 		eventReason = "SavepointTriggered"
 		eventMessage = fmt.Sprintf("Triggered savepoint %v: triggerID %v.", triggerReason, status.TriggerID)
+		if status.TriggerJob != "" {
+			eventMessage = fmt.Sprintf("Triggered savepoint %v: job %v.", triggerReason, status.TriggerJob)
+		}
 	case v1beta1.SavepointStateSucceeded:
 		eventType = corev1.EventTypeNormal
 		eventReason = "SavepointCreated"
@@ -354,6 +410,30 @@ func hasTimeElapsed(timeToCheckStr string, now time.Time, intervalSec int) bool
 	return now.After(intervalPassedTime)
 }
 
+// Checks whether the update of the StatefulSet is rejected because fields other than the
+// mutable ones changed, e.g. volumeClaimTemplates or serviceName.
+func isImmutableStatefulSetUpdateError(err error, obj client.Object) bool {
+	if _, ok := obj.(*appsv1.StatefulSet); !ok || !errors.IsInvalid(err) {
+		return false
+	}
+	status, ok := err.(errors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return false
+	}
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Field == "spec" && cause.Type == metav1.CauseType(field.ErrorTypeForbidden) {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks whether the pods of the observed StatefulSet are adopted by the desired one
+// after it is deleted with the orphan propagation policy.
+func canOrphanStatefulSetPods(observed, desired *appsv1.StatefulSet) bool {
+	return equality.Semantic.DeepEqual(observed.Spec.Selector, desired.Spec.Selector)
+}
+
 // isComponentUpdated checks whether the component updated.
 // If the component is observed as well as the next revision name in status.nextRevision and component's label `flinkoperator.k8s.io/hash` are equal, then it is updated already.
 // If the component is not observed and it is required, then it is not updated yet.
@@ -395,9 +475,9 @@ func isComponentUpdated(component client.Object, cluster *v1beta1.FlinkCluster)
 	}
 
 	labels := component.GetLabels()
-	nextRevisionName := getNextRevisionName(&cluster.Status.Revision)
+	nextRevisionName := render.NextRevisionName(&cluster.Status.Revision)
 
-	return labels[RevisionNameLabel] == nextRevisionName
+	return labels[render.RevisionNameLabel] == nextRevisionName
 }
 
 func areComponentsUpdated(components []client.Object, cluster *v1beta1.FlinkCluster) bool {
@@ -409,34 +489,61 @@ func areComponentsUpdated(components []client.Object, cluster *v1beta1.FlinkClus
 	return true
 }
 
-// isClusterUpdateToDate checks whether all cluster components are replaced to next revision.
-func isClusterUpdateToDate(observed *ObservedClusterState) bool {
-	if !observed.cluster.Status.Revision.IsUpdateTriggered() {
-		return true
+// getUpdatedComponents returns the observed components which are replaced by the update to
+// the next revision, nil if they are not observed.
+func getUpdatedComponents(observed *ObservedClusterState) []client.Object {
+	var components []client.Object
+	var created = observed.cluster.Spec.Components
+	if created.ShouldCreateConfigMap() {
+		components = append(components, observed.configMap)
 	}
-
-	components := []client.Object{
-		observed.configMap,
-		observed.tmService,
-		observed.jmService,
+	if created.ShouldCreateTmService() {
+		components = append(components, observed.tmService)
+	}
+	if created.ShouldCreateJmService() {
+		components = append(components, observed.jmService)
+		if render.ShouldSplitJobManagerUIService(observed.cluster) {
+			components = append(components, observed.jmUIService)
+		}
 	}
 
-	if !IsApplicationModeCluster(observed.cluster) {
+	if !render.IsApplicationModeCluster(observed.cluster) {
 		components = append(components, observed.jmStatefulSet)
 	}
 
-	if observed.cluster.Spec.PodDisruptionBudget != nil {
+	if observed.cluster.Spec.PodDisruptionBudget != nil && created.ShouldCreatePDB() {
 		components = append(components, observed.podDisruptionBudget)
 	}
 
-	switch observed.cluster.Spec.TaskManager.DeploymentType {
-	case v1beta1.DeploymentTypeDeployment:
+	switch {
+	case render.IsTaskManagerExternal(observed.cluster):
+	case observed.cluster.Spec.TaskManager.DeploymentType == v1beta1.DeploymentTypeDeployment:
 		components = append(components, observed.tmDeployment)
-	case v1beta1.DeploymentTypeStatefulSet:
+	case observed.cluster.Spec.TaskManager.DeploymentType == v1beta1.DeploymentTypeStatefulSet:
 		components = append(components, observed.tmStatefulSet)
 	}
 
-	return areComponentsUpdated(components, observed.cluster)
+	return components
+}
+
+// isClusterUpdateToDate checks whether all cluster components are replaced to next revision.
+func isClusterUpdateToDate(observed *ObservedClusterState) bool {
+	if !observed.cluster.Status.Revision.IsUpdateTriggered() {
+		return true
+	}
+
+	return areComponentsUpdated(getUpdatedComponents(observed), observed.cluster)
+}
+
+// hasOutdatedComponents checks whether any observed component is not replaced to next
+// revision yet, i.e. the components of the current revision are not all deleted.
+func hasOutdatedComponents(observed *ObservedClusterState) bool {
+	for _, c := range getUpdatedComponents(observed) {
+		if !reflect.ValueOf(c).IsNil() && !isComponentUpdated(c, observed.cluster) {
+			return true
+		}
+	}
+	return false
 }
 
 // isFlinkAPIReady checks whether cluster is ready to submit job.
@@ -463,13 +570,19 @@ func getUpdateState(observed *ObservedClusterState) UpdateState {
 	if !clusterStatus.Revision.IsUpdateTriggered() {
 		return UpdateStateNoUpdate
 	}
+	// The update waits for the window of spec.updatePolicy.window.
+	if isUpdateDeferred(observed.cluster, &clusterStatus.Revision, observed.observeTime) {
+		return UpdateStateNoUpdate
+	}
+	// The update is halted once its canary update is aborted or failed.
+	if render.IsCanaryUpdateRejected(observed.cluster) {
+		return UpdateStateNoUpdate
+	}
 
-	jobStatus := clusterStatus.Components.Job
-	switch {
-	case isJobUpdate(observed.revisions, observed.cluster) &&
-		!jobStatus.UpdateReady(observed.cluster.Spec.Job, observed.observeTime):
-		return UpdateStatePreparing
-	case !isClusterUpdateToDate(observed):
+	if isJobUpdate(observed.revisions, observed.cluster) {
+		return getJobUpdateState(observed)
+	}
+	if render.IsCanaryUpdatePending(observed.cluster) || !isClusterUpdateToDate(observed) {
 		return UpdateStateInProgress
 	}
 	return UpdateStateFinished
@@ -488,6 +601,29 @@ func revisionDiff(a, b *appsv1.ControllerRevision) map[string]util.DiffValue {
 	return util.MapDiff(aSpec, bSpec)
 }
 
+// Gets the compact JSON merge patch of the spec from revision a to b.
+func revisionPatch(a, b *appsv1.ControllerRevision) string {
+	patchSpec := func(bytes []byte) map[string]any {
+		var raw map[string]any
+		json.Unmarshal(bytes, &raw)
+		spec, _ := raw["spec"].(map[string]any)
+		delete(spec, "$patch")
+		return spec
+	}
+
+	patch, _ := json.Marshal(util.MergePatch(patchSpec(a.Data.Raw), patchSpec(b.Data.Raw)))
+	return string(patch)
+}
+
+// Writes the properties resolved from spec.flinkPropertiesFrom to the hash.
+func writeFlinkPropertiesFrom(hash io.Writer, properties map[string]string) {
+	var data = make(map[string][]byte, len(properties))
+	for key, value := range properties {
+		data[key] = []byte(value)
+	}
+	writeReferencedConfig(hash, configReference{kind: "FlinkPropertiesFrom"}, data)
+}
+
 func isJobUpdate(revisions []*appsv1.ControllerRevision, cluster *v1beta1.FlinkCluster) bool {
 	if wasJobCancelRequested(cluster.Status.Control) {
 		return false
@@ -499,8 +635,10 @@ func isJobUpdate(revisions []*appsv1.ControllerRevision, cluster *v1beta1.FlinkC
 
 	history.SortControllerRevisions(revisions)
 	diff := revisionDiff(revisions[len(revisions)-2], revisions[len(revisions)-1])
-	_, ok := diff["job"]
-	return ok
+	_, jobChanged := diff["job"]
+	// The job is restarted from a savepoint to pick up the changed ConfigMaps and Secrets.
+	_, configChanged := diff[render.ReferencedConfigHashKey]
+	return jobChanged || configChanged
 }
 
 func isScaleUpdate(revisions []*appsv1.ControllerRevision, cluster *v1beta1.FlinkCluster) bool {
@@ -523,23 +661,85 @@ func isScaleUpdate(revisions []*appsv1.ControllerRevision, cluster *v1beta1.Flin
 }
 
 func shouldUpdateJob(observed *ObservedClusterState) bool {
-	return observed.updateState == UpdateStateInProgress && isJobUpdate(observed.revisions, observed.cluster)
+	return observed.updateState == UpdateStateInProgress && isJobUpdate(observed.revisions, observed.cluster) &&
+		!isNextJobSubmittedForUpdate(observed)
+}
+
+// Gets the desired state of the observed cluster with the settings of the operator in the
+// options, and the resolved properties and referenced configs and the update of the observed
+// state.
+func getDesiredClusterState(observed *ObservedClusterState, options render.Options) *model.DesiredClusterState {
+	options.FlinkPropertiesFrom = observed.flinkPropertiesFrom
+	options.ReferencedConfigHash = observed.referencedConfigHash
+	options.JobUpdate = observed.cluster != nil && shouldUpdateJob(observed)
+	if observed.cluster != nil && render.IsCanaryUpdateRejected(observed.cluster) {
+		options.CurrentRevisionSpec = getRevisionSpec(observed.revision.currentRevision)
+	}
+	return render.DesiredState(observed.cluster, options)
+}
+
+// Gets the spec recorded in the revision, nil if it cannot be decoded.
+func getRevisionSpec(revision *appsv1.ControllerRevision) *v1beta1.FlinkClusterSpec {
+	if revision == nil {
+		return nil
+	}
+	var data struct {
+		Spec v1beta1.FlinkClusterSpec `json:"spec"`
+	}
+	if err := json.Unmarshal(revision.Data.Raw, &data); err != nil {
+		return nil
+	}
+	return &data.Spec
 }
 
 func shouldUpdateCluster(observed *ObservedClusterState) bool {
 	if isJobUpdate(observed.revisions, observed.cluster) {
-		var job = observed.cluster.Status.Components.Job
-		return !job.IsActive() && observed.updateState == UpdateStateInProgress
+		return isJobStoppedForUpdate(observed) && observed.updateState == UpdateStateInProgress
 	}
 
 	return observed.updateState == UpdateStateInProgress
 }
 
 func shouldRecreateOnUpdate(observed *ObservedClusterState) bool {
+	// The canary TaskManagers are updated in place by the rolling update of the StatefulSet.
+	if observed.cluster.Spec.CanaryUpdate != nil {
+		return false
+	}
 	ru := observed.cluster.Spec.RecreateOnUpdate
 	return *ru && !isScaleUpdate(observed.revisions, observed.cluster)
 }
 
+// shouldDeferJobManagerDeletion returns true if the JobManager StatefulSet is to be deleted,
+// on cleanup or to be recreated on update, while the TaskManagers it is deleted with still
+// exist. The TaskManagers are deleted first so that they deregister from the JobManager and
+// the JobManager finalizes the checkpoints before it shuts down.
+func shouldDeferJobManagerDeletion(observed *ObservedClusterState, desiredJmStatefulSet *appsv1.StatefulSet) bool {
+	var cluster = observed.cluster
+	var jmStatefulSet = observed.jmStatefulSet
+	if jmStatefulSet == nil || jmStatefulSet.DeletionTimestamp != nil {
+		return false
+	}
+
+	var tmStatefulSet, tmDeployment = observed.tmStatefulSet, observed.tmDeployment
+	if desiredJmStatefulSet == nil {
+		return tmStatefulSet != nil || tmDeployment != nil || len(observed.tmPods) > 0
+	}
+	if !shouldUpdateCluster(observed) || isComponentUpdated(jmStatefulSet, cluster) || !shouldRecreateOnUpdate(observed) {
+		return false
+	}
+	// The TaskManagers of the next revision are not waited for.
+	if (tmStatefulSet != nil && !isComponentUpdated(tmStatefulSet, cluster)) ||
+		(tmDeployment != nil && !isComponentUpdated(tmDeployment, cluster)) {
+		return true
+	}
+	for _, pod := range observed.tmPods {
+		if pod.DeletionTimestamp != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func getFlinkJobDeploymentState(flinkJobState string) v1beta1.JobState {
 	switch flinkJobState {
 	case "INITIALIZING", "CREATED", "RUNNING", "FAILING", "CANCELLING", "RESTARTING", "RECONCILING", "SUSPENDED":
@@ -566,16 +766,14 @@ func getFlinkJobSubmitLog(clientset *kubernetes.Clientset, observedPod *corev1.P
 }
 
 func getFlinkJobSubmitLogFromString(podLog string) *SubmitterLog {
+	var submitterLog = &SubmitterLog{message: podLog}
 	if result := jobIdRegexp.FindStringSubmatch(podLog); len(result) > 0 {
-		return &SubmitterLog{jobID: result[1], message: podLog}
-	} else {
-		return &SubmitterLog{jobID: "", message: podLog}
+		submitterLog.jobID = result[1]
 	}
-}
-
-func IsApplicationModeCluster(cluster *v1beta1.FlinkCluster) bool {
-	jobSpec := cluster.Spec.Job
-	return jobSpec != nil && jobSpec.Mode != nil && *jobSpec.Mode == v1beta1.JobModeApplication
+	if result := entryClassRegexp.FindStringSubmatch(podLog); len(result) > 0 {
+		submitterLog.entryClass = result[1]
+	}
+	return submitterLog
 }
 
 // checks if job-cancel was requested
@@ -583,11 +781,348 @@ func wasJobCancelRequested(controlStatus *v1beta1.FlinkClusterControlStatus) boo
 	return controlStatus != nil && controlStatus.Name == v1beta1.ControlNameJobCancel
 }
 
-func GenJobId(cluster *v1beta1.FlinkCluster) (string, error) {
-	if cluster == nil || len(cluster.Status.Revision.NextRevision) == 0 {
-		return "", fmt.Errorf("error generating job id: cluster or next revision is nil")
+// isJobSuspending returns true if the job is being stopped with a savepoint on a job-cancel
+// control, and the savepoint has not failed.
+func isJobSuspending(job *v1beta1.JobStatus, savepoint *v1beta1.SavepointStatus) bool {
+	return job != nil && !job.IsStopped() && finalSavepointRequested(job.ID, savepoint) &&
+		savepoint.TriggerReason == v1beta1.SavepointReasonJobCancel && !savepoint.IsFailed()
+}
+
+// isJobSuspended returns true if the job was stopped with a savepoint on a job-cancel control.
+func isJobSuspended(job *v1beta1.JobStatus, savepoint *v1beta1.SavepointStatus) bool {
+	return job != nil && job.State == v1beta1.JobStateCancelled && finalSavepointRequested(job.ID, savepoint) &&
+		savepoint.TriggerReason == v1beta1.SavepointReasonJobCancel && savepoint.State == v1beta1.SavepointStateSucceeded
+}
+
+// isUpdateRollingBack returns true while the cluster is updated from the revision whose job
+// failed the restore verification to the revision its spec was rolled back to.
+func isUpdateRollingBack(revision *v1beta1.RevisionStatus) bool {
+	var failure = revision.UpdateFailure
+	return failure != nil && failure.RollbackRevision != "" && revision.IsUpdateTriggered() &&
+		revision.CurrentRevision == failure.Revision
+}
+
+// Checks whether the cluster is a job cluster waiting to be started.
+func isQueueCandidate(cluster *v1beta1.FlinkCluster) bool {
+	var state = cluster.Status.State
+	return cluster.Spec.Job != nil && cluster.DeletionTimestamp == nil &&
+		(state == "" || state == v1beta1.ClusterStateQueued)
+}
+
+// Checks whether the cluster is a job cluster holding a slot of its queue.
+func occupiesQueueSlot(cluster *v1beta1.FlinkCluster) bool {
+	switch cluster.Status.State {
+	case "", v1beta1.ClusterStateQueued, v1beta1.ClusterStateStopped, v1beta1.ClusterStatePartiallyStopped:
+		return false
+	}
+	return cluster.Spec.Job != nil
+}
+
+// Gets the position of the cluster in its queue, 0 if it can be started now.
+// Waiting clusters are started FIFO by creation time as running job clusters
+// of the same queue free their slots.
+func getQueuePosition(cluster *v1beta1.FlinkCluster, clusters []v1beta1.FlinkCluster, maxRunning int) int32 {
+	if maxRunning <= 0 || !isQueueCandidate(cluster) {
+		return 0
+	}
+
+	var queue = cluster.Labels[QueueLabel]
+	var running = 0
+	var waiting = []*v1beta1.FlinkCluster{cluster}
+	for i := range clusters {
+		var c = &clusters[i]
+		if c.Name == cluster.Name || c.Namespace != cluster.Namespace || c.Labels[QueueLabel] != queue {
+			continue
+		}
+		if occupiesQueueSlot(c) {
+			running++
+		} else if isQueueCandidate(c) && !isWaitingForDependencies(c) {
+			waiting = append(waiting, c)
+		}
+	}
+	sort.SliceStable(waiting, func(i, j int) bool {
+		var ti, tj = waiting[i].CreationTimestamp, waiting[j].CreationTimestamp
+		if ti.Equal(&tj) {
+			return waiting[i].Name < waiting[j].Name
+		}
+		return ti.Before(&tj)
+	})
+
+	var free = maxRunning - running
+	for i, c := range waiting {
+		if c.Name == cluster.Name && i >= free {
+			return int32(i - free + 1)
+		}
+	}
+	return 0
+}
+
+// Gets the reason why the first of the pods, ordered by name, is not ready,
+// e.g. Unschedulable, ImagePullBackOff or CrashLoopBackOff.
+// Returns empty string if all the pods are ready.
+func getPodsNotReadyReason(pods []corev1.Pod) string {
+	var sorted = append([]corev1.Pod{}, pods...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for i := range sorted {
+		if reason := getPodNotReadyReason(&sorted[i]); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+func getPodNotReadyReason(pod *corev1.Pod) string {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded {
+		return ""
+	}
+
+	var readyReason string
+	for _, cond := range pod.Status.Conditions {
+		switch {
+		case cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse:
+			if cond.Reason != "" {
+				return cond.Reason
+			}
+			return "Unschedulable"
+		case cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue:
+			return ""
+		case cond.Type == corev1.PodReady:
+			readyReason = cond.Reason
+		}
+	}
+
+	var statuses = append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if waiting := cs.State.Waiting; waiting != nil &&
+			waiting.Reason != "" && waiting.Reason != "PodInitializing" && waiting.Reason != "ContainerCreating" {
+			return waiting.Reason
+		}
+		if terminated := cs.State.Terminated; terminated != nil && terminated.ExitCode != 0 && terminated.Reason != "" {
+			return terminated.Reason
+		}
+	}
+
+	if pod.Status.Phase == corev1.PodFailed && pod.Status.Reason != "" {
+		return pod.Status.Reason
+	}
+	return readyReason
+}
+
+// Gets the total restarts of the containers of the pods with the name, e.g. jobmanager, and
+// the latest of their last terminations, so that restarts are reported even after the pods
+// are ready again. A memory limit is suggested for OOMKilled containers.
+func getPodsContainerTermination(pods []corev1.Pod, containerName string) (int32, *v1beta1.ContainerTerminationStatus) {
+	var tc util.TimeConverter
+	var restarts int32
+	var last *v1beta1.ContainerTerminationStatus
+	var lastFinishedAt time.Time
+	for i := range pods {
+		var pod = &pods[i]
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.Name != containerName {
+				continue
+			}
+			restarts += cs.RestartCount
+			var terminated = cs.LastTerminationState.Terminated
+			if terminated == nil || (last != nil && !terminated.FinishedAt.Time.After(lastFinishedAt)) {
+				continue
+			}
+			lastFinishedAt = terminated.FinishedAt.Time
+			last = &v1beta1.ContainerTerminationStatus{
+				Pod:      pod.Name,
+				Reason:   terminated.Reason,
+				ExitCode: terminated.ExitCode,
+			}
+			if last.Reason == "" {
+				last.Reason = "Error"
+			}
+			if !terminated.FinishedAt.IsZero() {
+				last.FinishedAt = tc.ToString(terminated.FinishedAt.Time)
+			}
+			if terminated.Reason == "OOMKilled" {
+				last.SuggestedMemory = getSuggestedMemory(pod, containerName)
+			}
+		}
+	}
+	return restarts, last
+}
+
+// Gets 1.5 times the memory limit of the container, or its request without a limit, rounded
+// up to a MiB. Returns nil if neither is set.
+func getSuggestedMemory(pod *corev1.Pod, containerName string) *resource.Quantity {
+	const mebibyte = 1 << 20
+	for _, container := range pod.Spec.Containers {
+		if container.Name != containerName {
+			continue
+		}
+		var memory, ok = container.Resources.Limits[corev1.ResourceMemory]
+		if !ok {
+			memory, ok = container.Resources.Requests[corev1.ResourceMemory]
+		}
+		if !ok || memory.IsZero() {
+			return nil
+		}
+		var suggested = (memory.Value()*3/2 + mebibyte - 1) / mebibyte * mebibyte
+		return resource.NewQuantity(suggested, resource.BinarySI)
+	}
+	return nil
+}
+
+// Checks whether the finished job submitter outlived spec.job.submitterTTLSecondsAfterFinished.
+// The submitter is kept while the job is being deployed, until its result is recorded.
+func shouldDeleteFinishedSubmitter(observed *ObservedClusterState, now time.Time) bool {
+	var cluster = observed.cluster
+	var submitter = observed.flinkJobSubmitter.job
+	var jobSpec = cluster.Spec.Job
+	var jobStatus = cluster.Status.Components.Job
+	if jobSpec == nil || jobSpec.SubmitterTTLSecondsAfterFinished == nil ||
+		submitter == nil || submitter.DeletionTimestamp != nil || render.IsApplicationModeCluster(cluster) || jobStatus == nil || jobStatus.State == v1beta1.JobStateDeploying {
+		return false
+	}
+
+	for _, cond := range submitter.Status.Conditions {
+		if (cond.Type == batchv1.JobComplete || cond.Type == batchv1.JobFailed) && cond.Status == corev1.ConditionTrue {
+			var ttl = time.Duration(*jobSpec.SubmitterTTLSecondsAfterFinished) * time.Second
+			return !now.Before(cond.LastTransitionTime.Add(ttl))
+		}
+	}
+	return false
+}
+
+// findDuplicateFlinkJob returns the active Flink job that a new submission of the recorded job would duplicate.
+// The job is matched by the recorded job ID, which is fixed across JobManager failover, or, when the ID was not
+// recorded or the job was resubmitted with a new ID, by the recorded job name if the job started after the
+// recorded submission, so that another job of the same name, e.g. of a previous revision, is not matched.
+func findDuplicateFlinkJob(recorded *v1beta1.JobStatus, jobs *flink.JobsOverview) *flink.Job {
+	if recorded == nil || jobs == nil || (recorded.ID == "" && recorded.Name == "") {
+		return nil
+	}
+	var deployTime int64
+	if recorded.DeployTime != "" {
+		deployTime = util.GetTime(recorded.DeployTime).UnixMilli()
+	}
+	for i := range jobs.Jobs {
+		var job = &jobs.Jobs[i]
+		if getFlinkJobDeploymentState(job.State) != v1beta1.JobStateRunning {
+			continue
+		}
+		if recorded.ID != "" && job.Id == recorded.ID {
+			return job
+		}
+		if recorded.Name != "" && job.Name == recorded.Name && deployTime > 0 && job.StartTime >= deployTime {
+			return job
+		}
+	}
+	return nil
+}
+
+// Checks whether the accumulators of the terminated job are needed to apply spec.job.successPolicy.
+func shouldObserveJobAccumulators(cluster *v1beta1.FlinkCluster, flinkJob *flink.Job) bool {
+	var policy = cluster.Spec.Job.SuccessPolicy
+	if policy == nil || policy.Type != v1beta1.JobSuccessPolicyAccumulator {
+		return false
+	}
+	var state = getFlinkJobDeploymentState(flinkJob.State)
+	return state == v1beta1.JobStateSucceeded || state == v1beta1.JobStateCancelled
+}
+
+// isClusterTemplate returns true if the cluster is a template of the job clusters with
+// spec.clusterRef, whose components are not created.
+func isClusterTemplate(cluster *v1beta1.FlinkCluster) bool {
+	return cluster != nil && cluster.Annotations[v1beta1.TemplateAnnotation] == "true"
+}
+
+// isTaskManagerDegraded returns true if the TaskManagers are the only cluster component that
+// is not ready, so the JobManager still serves the job with fewer slots.
+func isTaskManagerDegraded(components *v1beta1.FlinkClusterComponentsStatus, runningComponents, totalComponents int) bool {
+	return components.TaskManager != nil && components.TaskManager.State != v1beta1.ComponentStateReady &&
+		runningComponents == totalComponents-1
+}
+
+// getExternalTaskManagerStatus derives the status of the externally managed TaskManagers
+// from the TaskManagers registered to the JobManager, which are not observed while the
+// JobManager is unavailable.
+func getExternalTaskManagerStatus(taskManagers *flink.TaskManagersOverview, selector string) *v1beta1.TaskManagerStatus {
+	var status = &v1beta1.TaskManagerStatus{
+		State:    v1beta1.ComponentStateNotReady,
+		Selector: selector,
+	}
+	if taskManagers == nil {
+		status.NotReadyReason = "JobManagerUnavailable"
+	} else if registered := int32(len(taskManagers.TaskManagers)); registered > 0 {
+		status.State = v1beta1.ComponentStateReady
+		status.Replicas = registered
+		status.ReadyReplicas = registered
+	} else {
+		status.NotReadyReason = "NoTaskManagersRegistered"
+	}
+	status.Ready = fmt.Sprintf("%d/%d", status.ReadyReplicas, status.Replicas)
+	return status
+}
+
+// isRestoreVerificationInProgress returns true if the job started from a savepoint is being verified.
+func isRestoreVerificationInProgress(job *v1beta1.JobStatus) bool {
+	return job != nil && job.RestoreVerification != nil &&
+		job.RestoreVerification.State == v1beta1.RestoreVerificationStateInProgress
+}
+
+// isRestoreVerificationFailed returns true if the job started from a savepoint failed the verification.
+func isRestoreVerificationFailed(job *v1beta1.JobStatus) bool {
+	return job != nil && job.RestoreVerification != nil &&
+		job.RestoreVerification.State == v1beta1.RestoreVerificationStateFailed
+}
+
+// verifyJobRestore evaluates spec.job.restoreVerification for the running job started from a savepoint
+// at startTime. It returns the state of the verification and the reason why it failed.
+func verifyJobRestore(
+	spec *v1beta1.RestoreVerification,
+	startTime string,
+	checkpoints *flink.JobCheckpoints,
+	numRestarts *int64,
+	now time.Time) (v1beta1.RestoreVerificationState, string) {
+	if numRestarts != nil && *numRestarts > int64(spec.MaxRestarts) {
+		return v1beta1.RestoreVerificationStateFailed,
+			fmt.Sprintf("the job restarted %v times, more than maxRestarts %v", *numRestarts, spec.MaxRestarts)
+	}
+	if checkpoints != nil && checkpoints.Counts.Completed > 0 {
+		return v1beta1.RestoreVerificationStateSucceeded, ""
+	}
+	var timeout = spec.TimeoutSeconds
+	if timeout <= 0 {
+		timeout = 600
+	}
+	if startTime != "" && util.HasTimeElapsed(startTime, now, int(timeout)) {
+		return v1beta1.RestoreVerificationStateFailed,
+			fmt.Sprintf("no checkpoint completed within %vs", timeout)
+	}
+	return v1beta1.RestoreVerificationStateInProgress, ""
+}
+
+// applyJobSuccessPolicy derives the state of the terminated job from spec.job.successPolicy.
+// It returns false if the state cannot be determined yet because the job accumulators are not observed.
+func applyJobSuccessPolicy(cluster *v1beta1.FlinkCluster, state v1beta1.JobState, accumulators *flink.JobAccumulators) (v1beta1.JobState, bool) {
+	var policy = cluster.Spec.Job.SuccessPolicy
+	// The job cancelled by user request is never regarded as succeeded.
+	if policy == nil || (state != v1beta1.JobStateSucceeded && state != v1beta1.JobStateCancelled) ||
+		(state == v1beta1.JobStateCancelled && render.ShouldStopJob(cluster)) {
+		return state, true
 	}
 
-	hash := md5.Sum([]byte(cluster.Status.Revision.NextRevision))
-	return hex.EncodeToString(hash[:]), nil
+	switch policy.Type {
+	case v1beta1.JobSuccessPolicyFinishedOrCancelled:
+		return v1beta1.JobStateSucceeded, true
+	case v1beta1.JobSuccessPolicyAccumulator:
+		if accumulators == nil || policy.Accumulator == nil {
+			return state, policy.Accumulator == nil
+		}
+		for _, acc := range accumulators.UserAccumulators {
+			if acc.Name == policy.Accumulator.Name && acc.Value == policy.Accumulator.Value {
+				return v1beta1.JobStateSucceeded, true
+			}
+		}
+		if state == v1beta1.JobStateSucceeded {
+			return v1beta1.JobStateFailed, true
+		}
+	}
+	return state, true
 }
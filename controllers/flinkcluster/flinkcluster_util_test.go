@@ -17,23 +17,37 @@ limitations under the License.
 package flinkcluster
 
 import (
-	"os"
+	"strings"
 	"testing"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/google/go-cmp/cmp"
 	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
 	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render/rendertest"
 	"gotest.tools/v3/assert"
 )
 
+func getObservedClusterState() *ObservedClusterState {
+	return &ObservedClusterState{
+		cluster: rendertest.NewFlinkCluster(),
+	}
+}
+
 func TestTimeConverter(t *testing.T) {
 	var tc = &util.TimeConverter{}
 
@@ -58,97 +72,6 @@ func TestGetRetryCount(t *testing.T) {
 	assert.Equal(t, result2, "2")
 }
 
-func TestNewRevision(t *testing.T) {
-	var jmReplicas int32 = v1beta1.DefaultJobManagerReplicas
-	var tmReplicas int32 = v1beta1.DefaultTaskManagerReplicas
-	var rpcPort int32 = 8001
-	var blobPort int32 = 8002
-	var queryPort int32 = 8003
-	var uiPort int32 = 8004
-	var dataPort int32 = 8005
-	var memoryOffHeapRatio int32 = 25
-	var memoryOffHeapMin = resource.MustParse("600M")
-	var jarFile = "gs://my-bucket/myjob.jar"
-	var parallelism int32 = 2
-	var savepointDir = "/savepoint_dir"
-	restartPolicy := v1beta1.JobRestartPolicyNever
-	var flinkCluster = v1beta1.FlinkCluster{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "mycluster",
-			Namespace: "default",
-		},
-		Spec: v1beta1.FlinkClusterSpec{
-			Image: v1beta1.ImageSpec{
-				Name:       "flink:1.8.1",
-				PullPolicy: corev1.PullPolicy("Always"),
-			},
-			JobManager: &v1beta1.JobManagerSpec{
-				Replicas:    &jmReplicas,
-				AccessScope: v1beta1.AccessScopeVPC,
-				Ports: v1beta1.JobManagerPorts{
-					RPC:   &rpcPort,
-					Blob:  &blobPort,
-					Query: &queryPort,
-					UI:    &uiPort,
-				},
-				MemoryOffHeapRatio: &memoryOffHeapRatio,
-				MemoryOffHeapMin:   memoryOffHeapMin,
-			},
-			TaskManager: &v1beta1.TaskManagerSpec{
-				Replicas: &tmReplicas,
-				Ports: v1beta1.TaskManagerPorts{
-					RPC:   &rpcPort,
-					Data:  &dataPort,
-					Query: &queryPort,
-				},
-				MemoryOffHeapRatio: &memoryOffHeapRatio,
-				MemoryOffHeapMin:   memoryOffHeapMin,
-			},
-			Job: &v1beta1.JobSpec{
-				JarFile:       &jarFile,
-				Parallelism:   &parallelism,
-				SavepointsDir: &savepointDir,
-				RestartPolicy: &restartPolicy,
-			},
-		},
-	}
-
-	var patched = flinkCluster.DeepCopy()
-	// RestartPolicy is not part of the revision.
-	patched.Spec.Job.RestartPolicy = nil
-
-	var collisionCount int32 = 0
-	var controller = true
-	var blockOwnerDeletion = true
-	var raw, _ = newRevisionDataPatch(patched)
-	var revision, _ = newRevision(&flinkCluster, 1, &collisionCount)
-	var expectedRevision = appsv1.ControllerRevision{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "mycluster-7bc87c954f",
-			Namespace: "default",
-			Labels: map[string]string{
-				"flinkoperator.k8s.io/hash":       "7bc87c954f",
-				"flinkoperator.k8s.io/managed-by": "mycluster",
-			},
-			Annotations: map[string]string{},
-			OwnerReferences: []metav1.OwnerReference{{
-				APIVersion:         "flinkoperator.k8s.io/v1beta1",
-				Kind:               "FlinkCluster",
-				Name:               "mycluster",
-				Controller:         &controller,
-				BlockOwnerDeletion: &blockOwnerDeletion,
-			}},
-		},
-		Revision: 1,
-		Data:     runtime.RawExtension{Raw: raw},
-	}
-	assert.Assert(t, revision != nil)
-	assert.DeepEqual(
-		t,
-		*revision,
-		expectedRevision)
-}
-
 func TestCanTakeSavepoint(t *testing.T) {
 	// session cluster
 	var cluster = v1beta1.FlinkCluster{
@@ -206,6 +129,36 @@ func TestCanTakeSavepoint(t *testing.T) {
 	assert.Equal(t, take, false)
 }
 
+func TestGetUpdateStopMode(t *testing.T) {
+	var cluster = v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			FlinkVersion: "1.14",
+			Job:          &v1beta1.JobSpec{},
+		},
+	}
+	assert.Equal(t, getUpdateStopMode(&cluster), v1beta1.JobUpdateStopModeStopWithSavepoint)
+
+	// The deprecated cancel with savepoint is the default before Flink 1.9.
+	cluster.Spec.FlinkVersion = "1.8"
+	assert.Equal(t, getUpdateStopMode(&cluster), v1beta1.JobUpdateStopModeCancelWithSavepoint)
+
+	var mode = v1beta1.JobUpdateStopModeCancelWithSavepoint
+	cluster.Spec.FlinkVersion = "1.14"
+	cluster.Spec.Job.UpdateStopMode = &mode
+	assert.Equal(t, getUpdateStopMode(&cluster), v1beta1.JobUpdateStopModeCancelWithSavepoint)
+
+	// The job restored from fromSavepoint is cancelled without a savepoint.
+	var fromSavepoint = "gs://my-bucket/savepoint-123"
+	cluster.Spec.Job.FromSavepoint = &fromSavepoint
+	assert.Equal(t, getUpdateStopMode(&cluster), v1beta1.JobUpdateStopModeCancel)
+
+	var takeSavepointOnUpdate = false
+	cluster.Spec.Job.FromSavepoint = nil
+	cluster.Spec.Job.UpdateStopMode = nil
+	cluster.Spec.Job.TakeSavepointOnUpdate = &takeSavepointOnUpdate
+	assert.Equal(t, getUpdateStopMode(&cluster), v1beta1.JobUpdateStopModeCancel)
+}
+
 func TestGetNextRevisionNumber(t *testing.T) {
 	var revisions []*appsv1.ControllerRevision
 	var nextRevision = util.GetNextRevisionNumber(revisions)
@@ -228,7 +181,7 @@ func TestIsComponentUpdated(t *testing.T) {
 		Status: v1beta1.FlinkClusterStatus{Revision: v1beta1.RevisionStatus{NextRevision: "cluster-85dc8f749-2"}},
 	}
 	var deploy = &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
-		RevisionNameLabel: "cluster-85dc8f749",
+		render.RevisionNameLabel: "cluster-85dc8f749",
 	}}}
 	var update = isComponentUpdated(deploy, &cluster)
 	assert.Equal(t, update, true)
@@ -242,7 +195,7 @@ func TestIsComponentUpdated(t *testing.T) {
 	assert.Equal(t, update, false)
 
 	var job = &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
-		RevisionNameLabel: "cluster-85dc8f749",
+		render.RevisionNameLabel: "cluster-85dc8f749",
 	}}}
 	update = isComponentUpdated(job, &cluster2)
 	assert.Equal(t, update, true)
@@ -260,6 +213,28 @@ func TestIsComponentUpdated(t *testing.T) {
 	assert.Equal(t, update, true)
 }
 
+func TestGetUpdatedComponentsOfDisabledComponents(t *testing.T) {
+	var observed = getObservedClusterState()
+	var disabled = false
+	observed.cluster.Spec.Components = &v1beta1.ComponentsSpec{
+		CreateConfigMap: &disabled,
+		CreateTmService: &disabled,
+	}
+
+	// The externally managed components are not updated with the cluster.
+	observed.configMap = &corev1.ConfigMap{}
+	observed.tmService = &corev1.Service{}
+	observed.jmService = &corev1.Service{}
+	var updated []client.Object
+	for _, obj := range getUpdatedComponents(observed) {
+		if obj == client.Object(observed.configMap) || obj == client.Object(observed.tmService) ||
+			obj == client.Object(observed.jmService) {
+			updated = append(updated, obj)
+		}
+	}
+	assert.DeepEqual(t, updated, []client.Object{observed.jmService})
+}
+
 func TestGetUpdateState(t *testing.T) {
 	var observed = ObservedClusterState{
 		cluster: &v1beta1.FlinkCluster{
@@ -272,17 +247,28 @@ func TestGetUpdateState(t *testing.T) {
 				Components: v1beta1.FlinkClusterComponentsStatus{Job: &v1beta1.JobStatus{State: v1beta1.JobStateRunning}},
 				Revision:   v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-aa5e3a87z-3"}},
 		},
-		flinkJobSubmitter:   FlinkJobSubmitter{job: &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-85dc8f749"}}}},
-		configMap:           &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-85dc8f749"}}},
-		podDisruptionBudget: &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-85dc8f749"}}},
-		jmStatefulSet:       &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-85dc8f749"}}},
-		tmStatefulSet:       &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-85dc8f749"}}},
-		tmService:           &corev1.Service{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-85dc8f749"}}},
-		jmService:           &corev1.Service{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-85dc8f749"}}},
+		flinkJobSubmitter:   FlinkJobSubmitter{job: &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-85dc8f749"}}}},
+		configMap:           &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-85dc8f749"}}},
+		podDisruptionBudget: &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-85dc8f749"}}},
+		jmStatefulSet:       &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-85dc8f749"}}},
+		tmStatefulSet:       &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-85dc8f749"}}},
+		tmService:           &corev1.Service{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-85dc8f749"}}},
+		jmService:           &corev1.Service{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-85dc8f749"}}},
 	}
 	var state = getUpdateState(&observed)
 	assert.Equal(t, state, UpdateStateInProgress)
 
+	// The update is deferred outside the update window.
+	observed.cluster.Spec.UpdatePolicy = &v1beta1.UpdatePolicy{
+		Window: &v1beta1.UpdateWindow{Schedule: "0 2 * * 6", DurationSeconds: 7200},
+	}
+	observed.observeTime = time.Date(2022, 5, 4, 13, 0, 0, 0, time.UTC)
+	state = getUpdateState(&observed)
+	assert.Equal(t, state, UpdateStateNoUpdate)
+	observed.observeTime = time.Date(2022, 5, 7, 2, 30, 0, 0, time.UTC)
+	state = getUpdateState(&observed)
+	assert.Equal(t, state, UpdateStateInProgress)
+
 	observed = ObservedClusterState{
 		cluster: &v1beta1.FlinkCluster{
 			Spec: v1beta1.FlinkClusterSpec{
@@ -294,9 +280,9 @@ func TestGetUpdateState(t *testing.T) {
 				Revision: v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-aa5e3a87z-3"},
 			},
 		},
-		jmStatefulSet: &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-aa5e3a87z"}}},
-		tmStatefulSet: &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-85dc8f749"}}},
-		jmService:     &corev1.Service{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-85dc8f749"}}},
+		jmStatefulSet: &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-aa5e3a87z"}}},
+		tmStatefulSet: &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-85dc8f749"}}},
+		jmService:     &corev1.Service{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-85dc8f749"}}},
 	}
 	state = getUpdateState(&observed)
 	assert.Equal(t, state, UpdateStateInProgress)
@@ -310,14 +296,14 @@ func TestGetUpdateState(t *testing.T) {
 			},
 			Status: v1beta1.FlinkClusterStatus{Revision: v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-aa5e3a87z-3"}},
 		},
-		flinkJobSubmitter:   FlinkJobSubmitter{job: &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-aa5e3a87z"}}}},
-		configMap:           &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-aa5e3a87z"}}},
-		podDisruptionBudget: &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-aa5e3a87z"}}},
-		jmStatefulSet:       &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-aa5e3a87z"}}},
-		tmStatefulSet:       &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-aa5e3a87z"}}},
-		jmService:           &corev1.Service{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-aa5e3a87z"}}},
-		tmService:           &corev1.Service{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-aa5e3a87z"}}},
-		jmIngress:           &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{RevisionNameLabel: "cluster-aa5e3a87z"}}},
+		flinkJobSubmitter:   FlinkJobSubmitter{job: &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-aa5e3a87z"}}}},
+		configMap:           &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-aa5e3a87z"}}},
+		podDisruptionBudget: &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-aa5e3a87z"}}},
+		jmStatefulSet:       &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-aa5e3a87z"}}},
+		tmStatefulSet:       &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-aa5e3a87z"}}},
+		jmService:           &corev1.Service{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-aa5e3a87z"}}},
+		tmService:           &corev1.Service{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-aa5e3a87z"}}},
+		jmIngress:           &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-aa5e3a87z"}}},
 	}
 	state = getUpdateState(&observed)
 	assert.Equal(t, state, UpdateStateFinished)
@@ -353,9 +339,21 @@ func TestGetFlinkAPIBaseURL(t *testing.T) {
 	var apiBaseURL = getFlinkAPIBaseURL(&cluster)
 	assert.Equal(t, apiBaseURL, "http://mycluster-jobmanager.default.svc.cluster.local:8004")
 
-	os.Setenv("CLUSTER_DOMAIN", "my.domain")
+	t.Setenv("CLUSTER_DOMAIN", "my.domain")
 	apiBaseURL = getFlinkAPIBaseURL(&cluster)
 	assert.Equal(t, apiBaseURL, "http://mycluster-jobmanager.default.svc.my.domain:8004")
+
+	// The domain of the cluster takes precedence.
+	var clusterDomain = "k8s.example.com"
+	cluster.Spec.Networking = &v1beta1.NetworkingSpec{ClusterDomain: &clusterDomain}
+	apiBaseURL = getFlinkAPIBaseURL(&cluster)
+	assert.Equal(t, apiBaseURL, "http://mycluster-jobmanager.default.svc.k8s.example.com:8004")
+}
+
+func TestGetURLHost(t *testing.T) {
+	assert.Equal(t, getURLHost("34.120.1.2"), "34.120.1.2")
+	assert.Equal(t, getURLHost("2600:1900:4000::1"), "[2600:1900:4000::1]")
+	assert.Equal(t, getURLHost("lb.example.com"), "lb.example.com")
 }
 
 func TestGetNonLiveHistory(t *testing.T) {
@@ -371,6 +369,33 @@ func TestGetNonLiveHistory(t *testing.T) {
 	historyLimit = 3
 	nonLiveHistory = util.GetNonLiveHistory(revisions, historyLimit)
 	assert.Equal(t, len(nonLiveHistory), 0)
+
+	// The current and the next revisions are kept.
+	revison0.Name = "mycluster-85dc8f749"
+	revison1.Name = "mycluster-6f9c4d7b8"
+	revison2 := appsv1.ControllerRevision{ObjectMeta: metav1.ObjectMeta{Name: "mycluster-5c7d9b6f4"}, Revision: int64(2)}
+	revisions = append(revisions, &revison2)
+	nonLiveHistory = util.GetNonLiveHistory(revisions, 0, revison0.Name, revison2.Name)
+	assert.Equal(t, len(nonLiveHistory), 1)
+	assert.Equal(t, nonLiveHistory[0].Revision, int64(1))
+}
+
+func TestRevisionPatch(t *testing.T) {
+	var observed = getObservedClusterState()
+	var cluster = observed.cluster
+	last, err := render.NewRevision(cluster, "", 1, nil)
+	assert.NilError(t, err)
+
+	var replicas = int32(8)
+	cluster.Spec.TaskManager.Replicas = &replicas
+	cluster.Spec.FlinkProperties = map[string]string{"taskmanager.numberOfTaskSlots": "2"}
+	cluster.Spec.JobManager.HostAliases = nil
+	next, err := render.NewRevision(cluster, "", 2, nil)
+	assert.NilError(t, err)
+
+	assert.Equal(t, revisionPatch(last, last), "{}")
+	assert.Equal(t, revisionPatch(last, next),
+		`{"flinkProperties":{"taskmanager.numberOfTaskSlots":"2"},"jobManager":{"hostAliases":null},"taskManager":{"replicas":8}}`)
 }
 
 func TestGetFlinkJobSubmitLog(t *testing.T) {
@@ -403,7 +428,528 @@ func TestGetFlinkJobSubmitLog(t *testing.T) {
 	assert.Equal(t, submit.jobID, expected.jobID)
 	assert.Equal(t, submit.message, expected.message)
 
+	assert.Equal(t, submit.entryClass, "")
+
 	// job ID not found
 	submit = getFlinkJobSubmitLogFromString("")
 	assert.Equal(t, submit.jobID, "")
+
+	// entry class resolved by the submitter
+	submit = getFlinkJobSubmitLogFromString(`
+  Resolved the entry class org.apache.flink.streaming.examples.wordcount.WordCount from the manifest of ./examples/streaming/WordCount.jar.
+  /opt/flink/bin/flink run --class org.apache.flink.streaming.examples.wordcount.WordCount --jobmanager flinkjobcluster-sample-jobmanager:8081 ./examples/streaming/WordCount.jar
+  Job has been submitted with JobID ec74209eb4e3db8ae72db00bd7a830aa
+`)
+	assert.Equal(t, submit.jobID, "ec74209eb4e3db8ae72db00bd7a830aa")
+	assert.Equal(t, submit.entryClass, "org.apache.flink.streaming.examples.wordcount.WordCount")
+}
+
+func TestGetQueuePosition(t *testing.T) {
+	var now = metav1.Now()
+	var later = metav1.NewTime(now.Add(time.Minute))
+	var latest = metav1.NewTime(now.Add(2 * time.Minute))
+	var newCluster = func(name string, state v1beta1.ClusterState, created metav1.Time, queue string) v1beta1.FlinkCluster {
+		var cluster = v1beta1.FlinkCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: created,
+			},
+			Spec:   v1beta1.FlinkClusterSpec{Job: &v1beta1.JobSpec{}},
+			Status: v1beta1.FlinkClusterStatus{State: state},
+		}
+		if queue != "" {
+			cluster.Labels = map[string]string{QueueLabel: queue}
+		}
+		return cluster
+	}
+
+	var clusters = []v1beta1.FlinkCluster{
+		newCluster("running", v1beta1.ClusterStateRunning, now, ""),
+		newCluster("stopped", v1beta1.ClusterStateStopped, now, ""),
+		newCluster("queued-1", v1beta1.ClusterStateQueued, now, ""),
+		newCluster("queued-2", v1beta1.ClusterStateQueued, later, ""),
+		newCluster("new", "", latest, ""),
+		newCluster("other-queue", "", now, "batch"),
+	}
+
+	// No limit.
+	assert.Equal(t, getQueuePosition(&clusters[2], clusters, 0), int32(0))
+
+	// One free slot, taken by the oldest waiting cluster.
+	assert.Equal(t, getQueuePosition(&clusters[2], clusters, 2), int32(0))
+	assert.Equal(t, getQueuePosition(&clusters[3], clusters, 2), int32(1))
+	assert.Equal(t, getQueuePosition(&clusters[4], clusters, 2), int32(2))
+
+	// No free slot.
+	assert.Equal(t, getQueuePosition(&clusters[2], clusters, 1), int32(1))
+
+	// Clusters of other queues and running clusters are not queued.
+	assert.Equal(t, getQueuePosition(&clusters[5], clusters, 1), int32(0))
+	assert.Equal(t, getQueuePosition(&clusters[0], clusters, 1), int32(0))
+
+	// Session clusters are not queued.
+	var session = newCluster("session", "", now, "")
+	session.Spec.Job = nil
+	assert.Equal(t, getQueuePosition(&session, clusters, 1), int32(0))
+
+	// Clusters waiting for their dependencies do not take a place in the queue.
+	clusters[2].Status.Conditions = []metav1.Condition{
+		{Type: v1beta1.ClusterConditionWaitingForDependencies, Status: metav1.ConditionTrue}}
+	assert.Equal(t, getQueuePosition(&clusters[3], clusters, 1), int32(1))
+	assert.Equal(t, getQueuePosition(&clusters[4], clusters, 1), int32(2))
+}
+
+func TestGetPodsNotReadyReason(t *testing.T) {
+	var readyPod = corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "tm-0"},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	var unschedulablePod = corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "tm-2"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type:   corev1.PodScheduled,
+				Status: corev1.ConditionFalse,
+				Reason: corev1.PodReasonUnschedulable,
+			}},
+		},
+	}
+	var imagePullPod = corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "tm-1"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+				{Type: corev1.PodReady, Status: corev1.ConditionFalse, Reason: "ContainersNotReady"},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "taskmanager",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+			}},
+		},
+	}
+	var startingPod = corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "tm-3"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionFalse, Reason: "ContainersNotReady"},
+			},
+		},
+	}
+
+	assert.Equal(t, getPodsNotReadyReason(nil), "")
+	assert.Equal(t, getPodsNotReadyReason([]corev1.Pod{readyPod}), "")
+	assert.Equal(t, getPodsNotReadyReason([]corev1.Pod{readyPod, unschedulablePod}), "Unschedulable")
+	assert.Equal(t, getPodsNotReadyReason([]corev1.Pod{unschedulablePod, imagePullPod}), "ImagePullBackOff")
+	assert.Equal(t, getPodsNotReadyReason([]corev1.Pod{startingPod}), "ContainersNotReady")
+}
+
+func TestGetPodsContainerTermination(t *testing.T) {
+	var finishedAt = time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	var newPod = func(name string, restarts int32, terminated *corev1.ContainerStateTerminated) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "taskmanager",
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				},
+			}}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "taskmanager", RestartCount: restarts, LastTerminationState: corev1.ContainerState{Terminated: terminated}},
+				{Name: "log-forwarder", RestartCount: 5},
+			}},
+		}
+	}
+	var pods = []corev1.Pod{
+		newPod("tm-0", 0, nil),
+		newPod("tm-1", 2, &corev1.ContainerStateTerminated{
+			Reason:     "OOMKilled",
+			ExitCode:   137,
+			FinishedAt: metav1.NewTime(finishedAt),
+		}),
+		newPod("tm-2", 1, &corev1.ContainerStateTerminated{
+			Reason:     "Error",
+			ExitCode:   1,
+			FinishedAt: metav1.NewTime(finishedAt.Add(-time.Minute)),
+		}),
+	}
+
+	var restarts, termination = getPodsContainerTermination(pods, "taskmanager")
+	assert.Equal(t, restarts, int32(3))
+	assert.Equal(t, termination.SuggestedMemory.String(), "1536Mi")
+	termination.SuggestedMemory = nil
+	assert.DeepEqual(t, termination, &v1beta1.ContainerTerminationStatus{
+		Pod:        "tm-1",
+		Reason:     "OOMKilled",
+		ExitCode:   137,
+		FinishedAt: "2022-03-01T12:00:00Z",
+	})
+
+	restarts, termination = getPodsContainerTermination(pods[2:], "taskmanager")
+	assert.Equal(t, restarts, int32(1))
+	assert.DeepEqual(t, termination, &v1beta1.ContainerTerminationStatus{
+		Pod:        "tm-2",
+		Reason:     "Error",
+		ExitCode:   1,
+		FinishedAt: "2022-03-01T11:59:00Z",
+	})
+
+	restarts, termination = getPodsContainerTermination(pods[:1], "taskmanager")
+	assert.Equal(t, restarts, int32(0))
+	assert.Assert(t, termination == nil)
+}
+
+func TestShouldDeleteFinishedSubmitter(t *testing.T) {
+	var ttl int32 = 60
+	var finishedAt = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	var newObserved = func(state v1beta1.JobState, ttl *int32, conditions ...batchv1.JobCondition) *ObservedClusterState {
+		return &ObservedClusterState{
+			cluster: &v1beta1.FlinkCluster{
+				Spec: v1beta1.FlinkClusterSpec{
+					Job: &v1beta1.JobSpec{SubmitterTTLSecondsAfterFinished: ttl},
+				},
+				Status: v1beta1.FlinkClusterStatus{
+					Components: v1beta1.FlinkClusterComponentsStatus{
+						Job: &v1beta1.JobStatus{State: state},
+					},
+				},
+			},
+			flinkJobSubmitter: FlinkJobSubmitter{
+				job: &batchv1.Job{Status: batchv1.JobStatus{Conditions: conditions}},
+			},
+		}
+	}
+	var complete = batchv1.JobCondition{
+		Type:               batchv1.JobComplete,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(finishedAt),
+	}
+	var expired = finishedAt.Add(time.Minute)
+	var notExpired = finishedAt.Add(59 * time.Second)
+
+	assert.Assert(t, shouldDeleteFinishedSubmitter(newObserved(v1beta1.JobStateRunning, &ttl, complete), expired))
+	assert.Assert(t, shouldDeleteFinishedSubmitter(newObserved(v1beta1.JobStateFailed, &ttl, complete), expired))
+	assert.Assert(t, !shouldDeleteFinishedSubmitter(newObserved(v1beta1.JobStateRunning, &ttl, complete), notExpired))
+	assert.Assert(t, !shouldDeleteFinishedSubmitter(newObserved(v1beta1.JobStateRunning, nil, complete), expired))
+	assert.Assert(t, !shouldDeleteFinishedSubmitter(newObserved(v1beta1.JobStateDeploying, &ttl, complete), expired))
+	assert.Assert(t, !shouldDeleteFinishedSubmitter(newObserved(v1beta1.JobStateRunning, &ttl), expired))
+
+	var noSubmitter = newObserved(v1beta1.JobStateRunning, &ttl)
+	noSubmitter.flinkJobSubmitter.job = nil
+	assert.Assert(t, !shouldDeleteFinishedSubmitter(noSubmitter, expired))
+}
+
+func TestFindDuplicateFlinkJob(t *testing.T) {
+	var deployTime = "2024-01-01T00:00:00Z"
+	var submitted = util.GetTime(deployTime).Add(time.Minute).UnixMilli()
+	var jobs = &flink.JobsOverview{
+		Jobs: []flink.Job{
+			{Id: "old-id", Name: "wordcount", State: "CANCELED"},
+			{Id: "recovered-id", Name: "wordcount", State: "RESTARTING", StartTime: submitted},
+			{Id: "other-id", Name: "other", State: "FAILED"},
+		},
+	}
+
+	var duplicate = findDuplicateFlinkJob(&v1beta1.JobStatus{ID: "recovered-id"}, jobs)
+	assert.Assert(t, duplicate != nil)
+	assert.Equal(t, duplicate.Id, "recovered-id")
+
+	duplicate = findDuplicateFlinkJob(&v1beta1.JobStatus{ID: "old-id", Name: "wordcount", DeployTime: deployTime}, jobs)
+	assert.Assert(t, duplicate != nil)
+	assert.Equal(t, duplicate.Id, "recovered-id")
+
+	// A job of the same name which was not started by the recorded submission is not a duplicate.
+	assert.Assert(t, findDuplicateFlinkJob(&v1beta1.JobStatus{ID: "old-id", Name: "wordcount"}, jobs) == nil)
+	var laterDeployTime = util.GetTime(deployTime).Add(time.Hour).Format(time.RFC3339)
+	assert.Assert(t, findDuplicateFlinkJob(&v1beta1.JobStatus{ID: "old-id", Name: "wordcount", DeployTime: laterDeployTime}, jobs) == nil)
+
+	assert.Assert(t, findDuplicateFlinkJob(&v1beta1.JobStatus{ID: "old-id"}, jobs) == nil)
+	assert.Assert(t, findDuplicateFlinkJob(&v1beta1.JobStatus{ID: "other-id", Name: "other"}, jobs) == nil)
+	assert.Assert(t, findDuplicateFlinkJob(&v1beta1.JobStatus{}, jobs) == nil)
+	assert.Assert(t, findDuplicateFlinkJob(nil, jobs) == nil)
+	assert.Assert(t, findDuplicateFlinkJob(&v1beta1.JobStatus{ID: "recovered-id"}, nil) == nil)
+}
+
+func TestApplyJobSuccessPolicy(t *testing.T) {
+	var newCluster = func(policy *v1beta1.JobSuccessPolicy) *v1beta1.FlinkCluster {
+		return &v1beta1.FlinkCluster{
+			Spec: v1beta1.FlinkClusterSpec{Job: &v1beta1.JobSpec{SuccessPolicy: policy}},
+		}
+	}
+	var accumulators = &flink.JobAccumulators{
+		UserAccumulators: []flink.UserAccumulator{{Name: "completed", Type: "IntCounter", Value: "1"}},
+	}
+	var assertState = func(cluster *v1beta1.FlinkCluster, state v1beta1.JobState, accumulators *flink.JobAccumulators, expected v1beta1.JobState) {
+		t.Helper()
+		newState, determined := applyJobSuccessPolicy(cluster, state, accumulators)
+		assert.Assert(t, determined)
+		assert.Equal(t, newState, expected)
+	}
+
+	var defaultPolicy = newCluster(nil)
+	assertState(defaultPolicy, v1beta1.JobStateSucceeded, nil, v1beta1.JobStateSucceeded)
+	assertState(defaultPolicy, v1beta1.JobStateCancelled, nil, v1beta1.JobStateCancelled)
+
+	var cancelledAsSuccess = newCluster(&v1beta1.JobSuccessPolicy{Type: v1beta1.JobSuccessPolicyFinishedOrCancelled})
+	assertState(cancelledAsSuccess, v1beta1.JobStateCancelled, nil, v1beta1.JobStateSucceeded)
+	assertState(cancelledAsSuccess, v1beta1.JobStateFailed, nil, v1beta1.JobStateFailed)
+	assertState(cancelledAsSuccess, v1beta1.JobStateRunning, nil, v1beta1.JobStateRunning)
+	cancelledAsSuccess.Annotations = map[string]string{v1beta1.ControlAnnotation: v1beta1.ControlNameJobCancel}
+	assertState(cancelledAsSuccess, v1beta1.JobStateCancelled, nil, v1beta1.JobStateCancelled)
+
+	var accumulatorPolicy = newCluster(&v1beta1.JobSuccessPolicy{
+		Type:        v1beta1.JobSuccessPolicyAccumulator,
+		Accumulator: &v1beta1.JobAccumulatorPredicate{Name: "completed", Value: "1"},
+	})
+	assertState(accumulatorPolicy, v1beta1.JobStateSucceeded, accumulators, v1beta1.JobStateSucceeded)
+	assertState(accumulatorPolicy, v1beta1.JobStateCancelled, accumulators, v1beta1.JobStateSucceeded)
+	assertState(accumulatorPolicy, v1beta1.JobStateSucceeded, &flink.JobAccumulators{}, v1beta1.JobStateFailed)
+	assertState(accumulatorPolicy, v1beta1.JobStateCancelled, &flink.JobAccumulators{}, v1beta1.JobStateCancelled)
+	_, determined := applyJobSuccessPolicy(accumulatorPolicy, v1beta1.JobStateSucceeded, nil)
+	assert.Assert(t, !determined)
+
+	assert.Assert(t, shouldObserveJobAccumulators(accumulatorPolicy, &flink.Job{State: "FINISHED"}))
+	assert.Assert(t, !shouldObserveJobAccumulators(accumulatorPolicy, &flink.Job{State: "RUNNING"}))
+	assert.Assert(t, !shouldObserveJobAccumulators(cancelledAsSuccess, &flink.Job{State: "FINISHED"}))
+}
+
+func TestGetExternalTaskManagerStatus(t *testing.T) {
+	var selector = "app=flink,cluster=mycluster,component=taskmanager"
+	assert.DeepEqual(t, getExternalTaskManagerStatus(nil, selector), &v1beta1.TaskManagerStatus{
+		State:          v1beta1.ComponentStateNotReady,
+		Ready:          "0/0",
+		NotReadyReason: "JobManagerUnavailable",
+		Selector:       selector,
+	})
+	assert.DeepEqual(t, getExternalTaskManagerStatus(&flink.TaskManagersOverview{}, selector), &v1beta1.TaskManagerStatus{
+		State:          v1beta1.ComponentStateNotReady,
+		Ready:          "0/0",
+		NotReadyReason: "NoTaskManagersRegistered",
+		Selector:       selector,
+	})
+	var taskManagers = &flink.TaskManagersOverview{TaskManagers: []flink.TaskManager{{ID: "vm-1"}, {ID: "vm-2"}}}
+	assert.DeepEqual(t, getExternalTaskManagerStatus(taskManagers, selector), &v1beta1.TaskManagerStatus{
+		State:         v1beta1.ComponentStateReady,
+		Replicas:      2,
+		ReadyReplicas: 2,
+		Ready:         "2/2",
+		Selector:      selector,
+	})
+}
+
+func TestVerifyJobRestore(t *testing.T) {
+	var now = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	var startTime = now.Add(-5 * time.Minute).Format(time.RFC3339)
+	var spec = &v1beta1.RestoreVerification{TimeoutSeconds: 600, MaxRestarts: 1}
+	var noCheckpoints = &flink.JobCheckpoints{}
+	var completed = &flink.JobCheckpoints{Counts: flink.CheckpointCounts{Total: 2, Completed: 1}}
+	var restarts = func(n int64) *int64 { return &n }
+
+	var state, message = verifyJobRestore(spec, startTime, noCheckpoints, restarts(0), now)
+	assert.Equal(t, state, v1beta1.RestoreVerificationStateInProgress)
+	assert.Equal(t, message, "")
+
+	state, _ = verifyJobRestore(spec, startTime, completed, restarts(1), now)
+	assert.Equal(t, state, v1beta1.RestoreVerificationStateSucceeded)
+
+	// Not observed yet.
+	state, _ = verifyJobRestore(spec, startTime, nil, nil, now)
+	assert.Equal(t, state, v1beta1.RestoreVerificationStateInProgress)
+
+	state, message = verifyJobRestore(spec, startTime, completed, restarts(2), now)
+	assert.Equal(t, state, v1beta1.RestoreVerificationStateFailed)
+	assert.Equal(t, message, "the job restarted 2 times, more than maxRestarts 1")
+
+	state, message = verifyJobRestore(spec, startTime, noCheckpoints, restarts(0), now.Add(6*time.Minute))
+	assert.Equal(t, state, v1beta1.RestoreVerificationStateFailed)
+	assert.Equal(t, message, "no checkpoint completed within 600s")
+
+	state, message = verifyJobRestore(&v1beta1.RestoreVerification{}, startTime, nil, nil, now.Add(6*time.Minute))
+	assert.Equal(t, state, v1beta1.RestoreVerificationStateFailed)
+	assert.Equal(t, message, "no checkpoint completed within 600s")
+}
+
+func TestGetReferencedConfigs(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			HadoopConfig: &v1beta1.HadoopConfig{ConfigMapName: "hadoop-config"},
+			GCPConfig: &v1beta1.GCPConfig{
+				ServiceAccount: &v1beta1.GCPServiceAccount{SecretName: "gcp-key"},
+			},
+			ExtraConfigMounts: []v1beta1.ExtraConfigMount{{
+				Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "krb5"}},
+			}},
+			ConfigOverride: &v1beta1.ConfigOverrideSpec{ConfigMapName: "flink-conf"},
+			SecretsInjection: []v1beta1.SecretInjection{{
+				Placeholder:  "KAFKA_PASSWORD",
+				SecretKeyRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "kafka"}, Key: "password"},
+			}},
+			EnvFrom: []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}},
+			}},
+			JobManager: &v1beta1.JobManagerSpec{
+				Volumes: []corev1.Volume{{
+					Name: "hadoop",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "hadoop-config"}},
+					},
+				}},
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				Volumes: []corev1.Volume{{
+					Name:         "certs",
+					VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "certs"}},
+				}},
+			},
+			Job: &v1beta1.JobSpec{
+				ArgsFrom: []v1beta1.JobArgSource{{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "job-args"}, Key: "topic"},
+				}, {
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "kafka"}, Key: "token"},
+				}},
+			},
+		},
+	}
+
+	assert.DeepEqual(t, getReferencedConfigs(cluster), []configReference{
+		{kind: "ConfigMap", name: "env"},
+		{kind: "ConfigMap", name: "flink-conf"},
+		{kind: "ConfigMap", name: "hadoop-config"},
+		{kind: "ConfigMap", name: "job-args"},
+		{kind: "Secret", name: "certs"},
+		{kind: "Secret", name: "gcp-key"},
+		{kind: "Secret", name: "kafka"},
+		{kind: "Secret", name: "krb5"},
+	}, cmp.AllowUnexported(configReference{}))
+}
+
+func TestGetReferencedConfigKeys(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			HadoopConfig: &v1beta1.HadoopConfig{ConfigMapName: "hadoop-config"},
+			FlinkPropertiesFrom: []v1beta1.FlinkPropertiesSource{
+				{SecretRef: &corev1.LocalObjectReference{Name: "flink-secrets"}},
+				{External: &v1beta1.ExternalSecretSource{Provider: "AWSSecretsManager", Name: "flink/prod"}},
+			},
+		},
+	}
+	assert.DeepEqual(t, getReferencedConfigKeys(cluster), []string{"Secret/flink-secrets"})
+
+	var update = true
+	cluster.Spec.UpdateOnReferencedConfigChange = &update
+	assert.DeepEqual(t, getReferencedConfigKeys(cluster), []string{"Secret/flink-secrets", "ConfigMap/hadoop-config"})
+
+	assert.Assert(t, getReferencedConfigKeys(&v1beta1.FlinkCluster{}) == nil)
+}
+
+func TestReferencedConfigChangeIsJobUpdate(t *testing.T) {
+	var jarFile = "gs://my-bucket/myjob.jar"
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "mycluster", Namespace: "default"},
+		Spec: v1beta1.FlinkClusterSpec{
+			Job: &v1beta1.JobSpec{JarFile: &jarFile},
+		},
+	}
+	var collisionCount int32
+	var newRevisions = func(hashes ...string) []*appsv1.ControllerRevision {
+		var revisions []*appsv1.ControllerRevision
+		for i, hash := range hashes {
+			revision, err := render.NewRevision(cluster, hash, int64(i+1), &collisionCount)
+			assert.NilError(t, err)
+			revisions = append(revisions, revision)
+		}
+		return revisions
+	}
+
+	var writeHash = func(data map[string][]byte) string {
+		var hash = &strings.Builder{}
+		writeReferencedConfig(hash, configReference{kind: "ConfigMap", name: "hadoop-config"}, data)
+		return hash.String()
+	}
+	var oldHash = writeHash(map[string][]byte{"core-site.xml": []byte("a")})
+	var newHash = writeHash(map[string][]byte{"core-site.xml": []byte("b")})
+	assert.Assert(t, oldHash != newHash)
+	assert.Assert(t, writeHash(nil) != writeHash(map[string][]byte{}))
+
+	assert.Assert(t, isJobUpdate(newRevisions(oldHash, newHash), cluster))
+	assert.Assert(t, !isJobUpdate(newRevisions(oldHash, oldHash), cluster))
+	assert.Assert(t, !isScaleUpdate(newRevisions(oldHash, newHash), cluster))
+}
+
+func TestIsImmutableStatefulSetUpdateError(t *testing.T) {
+	var statefulSet = &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "mycluster-jobmanager"}}
+	var newInvalid = func(err *field.Error) error {
+		return errors.NewInvalid(
+			schema.GroupKind{Group: "apps", Kind: "StatefulSet"}, statefulSet.Name, field.ErrorList{err})
+	}
+
+	var immutableErr = newInvalid(field.Forbidden(field.NewPath("spec"),
+		"updates to statefulset spec for fields other than 'replicas', 'template' and 'updateStrategy' are forbidden"))
+	assert.Assert(t, isImmutableStatefulSetUpdateError(immutableErr, statefulSet))
+	assert.Assert(t, !isImmutableStatefulSetUpdateError(immutableErr, &appsv1.Deployment{}))
+	assert.Assert(t, !isImmutableStatefulSetUpdateError(
+		newInvalid(field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0")), statefulSet))
+	assert.Assert(t, !isImmutableStatefulSetUpdateError(
+		errors.NewConflict(schema.GroupResource{Group: "apps", Resource: "statefulsets"}, statefulSet.Name, nil), statefulSet))
+	assert.Assert(t, !isImmutableStatefulSetUpdateError(nil, statefulSet))
+
+	var desired = statefulSet.DeepCopy()
+	desired.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"component": "jobmanager"}}
+	desired.Spec.ServiceName = "mycluster-jobmanager-headless"
+	var observed = desired.DeepCopy()
+	observed.Spec.ServiceName = "mycluster-jobmanager"
+	assert.Assert(t, canOrphanStatefulSetPods(observed, desired))
+	observed.Spec.Selector.MatchLabels["app"] = "flink"
+	assert.Assert(t, !canOrphanStatefulSetPods(observed, desired))
+}
+
+func TestShouldDeferJobManagerDeletion(t *testing.T) {
+	var recreateOnUpdate = true
+	var observed = &ObservedClusterState{
+		cluster: &v1beta1.FlinkCluster{
+			Spec: v1beta1.FlinkClusterSpec{
+				TaskManager:      &v1beta1.TaskManagerSpec{DeploymentType: v1beta1.DeploymentTypeStatefulSet},
+				RecreateOnUpdate: &recreateOnUpdate,
+			},
+			Status: v1beta1.FlinkClusterStatus{
+				Revision: v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-85dc8f749-2"},
+			},
+		},
+		jmStatefulSet: &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-85dc8f749"}}},
+		tmStatefulSet: &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-85dc8f749"}}},
+		tmPods:        []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "cluster-taskmanager-0"}}},
+	}
+	var desiredJmStatefulSet = observed.jmStatefulSet.DeepCopy()
+	assert.Assert(t, !shouldDeferJobManagerDeletion(observed, desiredJmStatefulSet))
+
+	// On cleanup, the JobManager is deleted once the TaskManager pods are gone.
+	assert.Assert(t, shouldDeferJobManagerDeletion(observed, nil))
+	observed.tmStatefulSet = nil
+	assert.Assert(t, shouldDeferJobManagerDeletion(observed, nil))
+	observed.tmPods = nil
+	assert.Assert(t, !shouldDeferJobManagerDeletion(observed, nil))
+
+	// On update, the JobManager is recreated once the TaskManagers of the current revision are gone.
+	observed.cluster.Status.Revision.NextRevision = "cluster-aa5e3a87z-3"
+	observed.updateState = UpdateStateInProgress
+	observed.tmStatefulSet = &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{render.RevisionNameLabel: "cluster-85dc8f749"}}}
+	assert.Assert(t, shouldDeferJobManagerDeletion(observed, desiredJmStatefulSet))
+	observed.tmStatefulSet.Labels[render.RevisionNameLabel] = "cluster-aa5e3a87z"
+	var deletionTimestamp = metav1.Now()
+	observed.tmPods = []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "cluster-taskmanager-0", DeletionTimestamp: &deletionTimestamp}}}
+	assert.Assert(t, shouldDeferJobManagerDeletion(observed, desiredJmStatefulSet))
+	observed.tmPods = nil
+	assert.Assert(t, !shouldDeferJobManagerDeletion(observed, desiredJmStatefulSet))
+
+	// The components are updated in place.
+	recreateOnUpdate = false
+	observed.tmStatefulSet.Labels[render.RevisionNameLabel] = "cluster-85dc8f749"
+	assert.Assert(t, !shouldDeferJobManagerDeletion(observed, desiredJmStatefulSet))
 }
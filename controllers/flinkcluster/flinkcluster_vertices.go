@@ -0,0 +1,83 @@
+package flinkcluster
+
+import (
+	"math"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+)
+
+// The metric of the time the subtasks of a vertex are busy, in milliseconds per second.
+const busyTimeMetric = "busyTimeMsPerSecond"
+
+// The maximum length of the vertex names recorded in the job status, which may be long for
+// chained operators.
+const maxVertexNameLength = 128
+
+// isJobVertexSnapshotDue returns true if the snapshot of the job vertices is to be refreshed,
+// which is never if the interval is not positive.
+func isJobVertexSnapshotDue(recorded *v1beta1.JobStatus, interval time.Duration, now time.Time) bool {
+	if interval <= 0 {
+		return false
+	}
+	if recorded == nil || recorded.VerticesTime == "" {
+		return true
+	}
+	return util.HasTimeElapsed(recorded.VerticesTime, now, int(interval.Seconds()))
+}
+
+// newJobVertexStatus returns the snapshot of a job vertex from its back pressure and the busy
+// time aggregated over its subtasks, either of which is nil if it could not be observed.
+func newJobVertexStatus(
+	vertex flink.JobVertex,
+	backPressure *flink.JobVertexBackPressure,
+	metrics []flink.AggregatedMetric) v1beta1.JobVertexStatus {
+	var name = vertex.Name
+	if len(name) > maxVertexNameLength {
+		name = name[:maxVertexNameLength-3] + "..."
+	}
+	var status = v1beta1.JobVertexStatus{Name: name, Parallelism: int32(vertex.Parallelism)}
+	if backPressure != nil {
+		status.BackPressureLevel = backPressure.Level
+	}
+	for _, metric := range metrics {
+		if metric.ID == busyTimeMetric {
+			var percent = int32(math.Round(metric.Max / 10))
+			status.MaxBusyPercent = &percent
+		}
+	}
+	return status
+}
+
+// observeJobVertices returns the snapshot of the vertices of the running job. The vertices
+// whose back pressure or busy time could not be observed are recorded without them.
+func (observer *ClusterStateObserver) observeJobVertices(
+	flinkAPIBaseURL string, flinkJobID string) ([]v1beta1.JobVertexStatus, error) {
+	details, err := observer.flinkClient.GetJobDetails(flinkAPIBaseURL, flinkJobID)
+	if err != nil {
+		return nil, err
+	}
+	var vertices = make([]v1beta1.JobVertexStatus, 0, len(details.Vertices))
+	for _, vertex := range details.Vertices {
+		backPressure, _ := observer.flinkClient.GetJobVertexBackPressure(flinkAPIBaseURL, flinkJobID, vertex.ID)
+		metrics, _ := observer.flinkClient.GetJobVertexMetrics(flinkAPIBaseURL, flinkJobID, vertex.ID, busyTimeMetric)
+		vertices = append(vertices, newJobVertexStatus(vertex, backPressure, metrics))
+	}
+	return vertices, nil
+}
+
+// deriveJobVertices sets the snapshot of the job vertices of this reconciliation in the job
+// status, and removes it when the job is not running or the snapshots are disabled.
+func deriveJobVertices(job *v1beta1.JobStatus, observed []v1beta1.JobVertexStatus, interval time.Duration, now time.Time) {
+	switch {
+	case job.State != v1beta1.JobStateRunning || interval <= 0:
+		job.Vertices = nil
+		job.VerticesTime = ""
+	case observed != nil:
+		var tc = &util.TimeConverter{}
+		job.Vertices = observed
+		job.VerticesTime = tc.ToString(now)
+	}
+}
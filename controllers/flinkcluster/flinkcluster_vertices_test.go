@@ -0,0 +1,70 @@
+package flinkcluster
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"gotest.tools/v3/assert"
+)
+
+func TestIsJobVertexSnapshotDue(t *testing.T) {
+	var tc = &util.TimeConverter{}
+	var now = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	var job = &v1beta1.JobStatus{State: v1beta1.JobStateRunning}
+
+	assert.Assert(t, !isJobVertexSnapshotDue(job, 0, now))
+	assert.Assert(t, isJobVertexSnapshotDue(job, 5*time.Minute, now))
+
+	job.VerticesTime = tc.ToString(now.Add(-5 * time.Minute))
+	assert.Assert(t, !isJobVertexSnapshotDue(job, 5*time.Minute, now))
+	assert.Assert(t, isJobVertexSnapshotDue(job, 5*time.Minute, now.Add(time.Second)))
+}
+
+func TestNewJobVertexStatus(t *testing.T) {
+	var vertex = flink.JobVertex{ID: "a", Name: "Source: Kafka -> Map", Parallelism: 4}
+	var backPressure = &flink.JobVertexBackPressure{Status: "ok", Level: "high"}
+	var metrics = []flink.AggregatedMetric{{ID: busyTimeMetric, Max: 874, Avg: 500}}
+
+	var status = newJobVertexStatus(vertex, backPressure, metrics)
+	var busy int32 = 87
+	assert.DeepEqual(t, status, v1beta1.JobVertexStatus{
+		Name:              "Source: Kafka -> Map",
+		Parallelism:       4,
+		BackPressureLevel: "high",
+		MaxBusyPercent:    &busy,
+	})
+
+	// The vertices which could not be observed are recorded without the measurements.
+	vertex.Name = strings.Repeat("Map -> ", 30)
+	status = newJobVertexStatus(vertex, nil, nil)
+	assert.Equal(t, len(status.Name), maxVertexNameLength)
+	assert.Assert(t, strings.HasSuffix(status.Name, "..."))
+	assert.Equal(t, status.BackPressureLevel, "")
+	assert.Assert(t, status.MaxBusyPercent == nil)
+}
+
+func TestDeriveJobVertices(t *testing.T) {
+	var tc = &util.TimeConverter{}
+	var now = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	var vertices = []v1beta1.JobVertexStatus{{Name: "Source", Parallelism: 2, BackPressureLevel: "ok"}}
+	var job = &v1beta1.JobStatus{State: v1beta1.JobStateRunning}
+
+	deriveJobVertices(job, vertices, time.Minute, now)
+	assert.DeepEqual(t, job.Vertices, vertices)
+	assert.Equal(t, job.VerticesTime, tc.ToString(now))
+
+	// The snapshot is kept until it is refreshed.
+	deriveJobVertices(job, nil, time.Minute, now.Add(30*time.Second))
+	assert.DeepEqual(t, job.Vertices, vertices)
+	assert.Equal(t, job.VerticesTime, tc.ToString(now))
+
+	// The snapshot is removed when the job stops.
+	job.State = v1beta1.JobStateCancelled
+	deriveJobVertices(job, nil, time.Minute, now)
+	assert.Assert(t, job.Vertices == nil)
+	assert.Equal(t, job.VerticesTime, "")
+}
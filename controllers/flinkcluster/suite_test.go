@@ -81,7 +81,7 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).ToNot(HaveOccurred())
 
-	reconciler, err := NewReconciler(k8sManager)
+	reconciler, err := NewReconciler(k8sManager, 0)
 	Expect(err).ToNot(HaveOccurred())
 
 	err = reconciler.SetupWithManager(k8sManager, 1)
@@ -0,0 +1,253 @@
+package flinkclusterset
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Finalizer to delete the generated FlinkClusters, which may be in other namespaces
+// and therefore cannot be garbage collected through owner references.
+const clusterSetFinalizer = "flinkoperator.k8s.io/cluster-set-cleanup"
+
+var requeueResult = ctrl.Result{RequeueAfter: 10 * time.Second, Requeue: true}
+
+// FlinkClusterSetReconciler reconciles a FlinkClusterSet object
+type FlinkClusterSetReconciler struct {
+	Client        client.Client
+	EventRecorder record.EventRecorder
+}
+
+func NewReconciler(mgr manager.Manager) *FlinkClusterSetReconciler {
+	return &FlinkClusterSetReconciler{
+		Client:        mgr.GetClient(),
+		EventRecorder: mgr.GetEventRecorderFor("FlinkOperator"),
+	}
+}
+
+// +kubebuilder:rbac:groups=flinkoperator.k8s.io,resources=flinkclustersets,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=flinkoperator.k8s.io,resources=flinkclustersets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=flinkoperator.k8s.io,resources=flinkclustersets/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// Reconcile generates the FlinkClusters of a FlinkClusterSet custom resource.
+func (r *FlinkClusterSetReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	var set = new(v1beta1.FlinkClusterSet)
+	if err := r.Client.Get(ctx, request.NamespacedName, set); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	var observed = new(v1beta1.FlinkClusterList)
+	if err := r.Client.List(ctx, observed, client.MatchingLabels{
+		v1beta1.ClusterSetNameLabel:      set.Name,
+		v1beta1.ClusterSetNamespaceLabel: set.Namespace,
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if set.DeletionTimestamp != nil {
+		return r.cleanup(ctx, set, observed.Items)
+	}
+
+	if !controllerutil.ContainsFinalizer(set, clusterSetFinalizer) {
+		controllerutil.AddFinalizer(set, clusterSetFinalizer)
+		return ctrl.Result{}, r.Client.Update(ctx, set)
+	}
+
+	var namespaces []corev1.Namespace
+	if hasNamespaceGenerator(set) {
+		var namespaceList = new(corev1.NamespaceList)
+		if err := r.Client.List(ctx, namespaceList); err != nil {
+			return ctrl.Result{}, err
+		}
+		namespaces = namespaceList.Items
+	}
+
+	var newStatus = v1beta1.FlinkClusterSetStatus{ObservedGeneration: set.Generation}
+	desired, err := getDesiredClusters(set, namespaces)
+	if err == nil {
+		err = r.syncClusters(ctx, desired, observed.Items)
+	}
+	if err != nil {
+		log.Error(err, "Failed to generate FlinkClusters")
+		r.EventRecorder.Event(set, corev1.EventTypeWarning, "GenerateFailed", err.Error())
+		newStatus.Error = err.Error()
+	}
+	for key := range desired {
+		newStatus.Clusters = append(newStatus.Clusters, key)
+	}
+	sort.Strings(newStatus.Clusters)
+
+	if err := r.updateStatusIfChanged(ctx, set, newStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+	if newStatus.Error != "" {
+		return requeueResult, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// Creates, updates and deletes the FlinkClusters to match the desired ones.
+func (r *FlinkClusterSetReconciler) syncClusters(
+	ctx context.Context,
+	desired map[string]*v1beta1.FlinkCluster,
+	observed []v1beta1.FlinkCluster) error {
+	log := logr.FromContextOrDiscard(ctx)
+
+	var existing = map[string]*v1beta1.FlinkCluster{}
+	for i := range observed {
+		var cluster = &observed[i]
+		if _, ok := desired[getClusterKey(cluster)]; !ok {
+			log.Info("Deleting FlinkCluster no longer generated", "cluster", getClusterKey(cluster))
+			if err := r.deleteCluster(ctx, cluster); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+		existing[getClusterKey(cluster)] = cluster
+	}
+
+	for key, cluster := range desired {
+		var current = existing[key]
+		if current == nil {
+			log.Info("Creating FlinkCluster", "cluster", key)
+			if err := r.Client.Create(ctx, cluster); err != nil {
+				return err
+			}
+			continue
+		}
+		if current.Annotations[templateHashAnnotation] == cluster.Annotations[templateHashAnnotation] {
+			continue
+		}
+
+		log.Info("Updating FlinkCluster", "cluster", key)
+		var updated = current.DeepCopy()
+		if updated.Labels == nil {
+			updated.Labels = map[string]string{}
+		}
+		for k, v := range cluster.Labels {
+			updated.Labels[k] = v
+		}
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		for k, v := range cluster.Annotations {
+			updated.Annotations[k] = v
+		}
+		updated.Spec = cluster.Spec
+		if err := r.Client.Update(ctx, updated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deletes the generated FlinkClusters, then removes the finalizer once they are gone.
+func (r *FlinkClusterSetReconciler) cleanup(
+	ctx context.Context,
+	set *v1beta1.FlinkClusterSet,
+	observed []v1beta1.FlinkCluster) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+	if !controllerutil.ContainsFinalizer(set, clusterSetFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if len(observed) > 0 {
+		for i := range observed {
+			if observed[i].DeletionTimestamp != nil {
+				continue
+			}
+			log.Info("Deleting FlinkCluster of the deleted set", "cluster", getClusterKey(&observed[i]))
+			if err := r.deleteCluster(ctx, &observed[i]); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return requeueResult, nil
+	}
+
+	controllerutil.RemoveFinalizer(set, clusterSetFinalizer)
+	return ctrl.Result{}, r.Client.Update(ctx, set)
+}
+
+// Deletes a generated FlinkCluster, confirming the deletion first in case the validating
+// webhook requires it for running job clusters.
+func (r *FlinkClusterSetReconciler) deleteCluster(ctx context.Context, cluster *v1beta1.FlinkCluster) error {
+	if cluster.Annotations[v1beta1.ConfirmDeletionAnnotation] != "true" {
+		var patch = fmt.Sprintf(`{"metadata":{"annotations":{%q:"true"}}}`, v1beta1.ConfirmDeletionAnnotation)
+		if err := r.Client.Patch(ctx, cluster, client.RawPatch(types.MergePatchType, []byte(patch))); err != nil {
+			return err
+		}
+	}
+	return r.Client.Delete(ctx, cluster)
+}
+
+func (r *FlinkClusterSetReconciler) updateStatusIfChanged(
+	ctx context.Context,
+	set *v1beta1.FlinkClusterSet,
+	newStatus v1beta1.FlinkClusterSetStatus) error {
+	var oldStatus = set.Status
+	oldStatus.LastUpdateTime = ""
+	if reflect.DeepEqual(oldStatus, newStatus) {
+		return nil
+	}
+
+	util.SetTimestamp(&newStatus.LastUpdateTime)
+	set.Status = newStatus
+	return r.Client.Status().Update(ctx, set)
+}
+
+// Enqueues the FlinkClusterSet of the generated FlinkCluster.
+func (r *FlinkClusterSetReconciler) mapClusterToSet(obj client.Object) []reconcile.Request {
+	var name = obj.GetLabels()[v1beta1.ClusterSetNameLabel]
+	var namespace = obj.GetLabels()[v1beta1.ClusterSetNamespaceLabel]
+	if name == "" || namespace == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}}
+}
+
+// Enqueues the FlinkClusterSets with namespace generators when a namespace changes.
+func (r *FlinkClusterSetReconciler) mapNamespaceToSets(_ client.Object) []reconcile.Request {
+	var sets = new(v1beta1.FlinkClusterSetList)
+	if err := r.Client.List(context.Background(), sets); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range sets.Items {
+		if hasNamespaceGenerator(&sets.Items[i]) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: sets.Items[i].Name, Namespace: sets.Items[i].Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager registers this reconciler with the controller manager and
+// starts watching FlinkClusterSet, FlinkCluster and Namespace resources.
+func (r *FlinkClusterSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.FlinkClusterSet{}).
+		Watches(&source.Kind{Type: &v1beta1.FlinkCluster{}}, handler.EnqueueRequestsFromMapFunc(r.mapClusterToSet)).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToSets)).
+		Complete(r)
+}
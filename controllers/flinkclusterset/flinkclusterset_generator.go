@@ -0,0 +1,160 @@
+package flinkclusterset
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// Annotation of the generated FlinkCluster with the hash of its rendered template.
+	templateHashAnnotation = "flinkoperator.k8s.io/cluster-set-template-hash"
+
+	// Parameter given by the namespace generator.
+	namespaceParam = "namespace"
+)
+
+var paramRegexp = regexp.MustCompile(`{{\s*([A-Za-z0-9_.-]+)\s*}}`)
+
+// Generates the parameter sets of the generators in order.
+// The namespace generator selects from the given namespaces.
+func generateParams(set *v1beta1.FlinkClusterSet, namespaces []corev1.Namespace) ([]map[string]string, error) {
+	var params []map[string]string
+	for i, generator := range set.Spec.Generators {
+		switch {
+		case generator.List != nil && generator.Namespaces != nil:
+			return nil, fmt.Errorf("spec.generators[%d]: only one of list or namespaces can be specified", i)
+		case generator.List != nil:
+			params = append(params, generator.List...)
+		case generator.Namespaces != nil:
+			selector, err := metav1.LabelSelectorAsSelector(&generator.Namespaces.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("spec.generators[%d].namespaces.selector: %v", i, err)
+			}
+			for _, ns := range namespaces {
+				if selector.Matches(labels.Set(ns.Labels)) {
+					params = append(params, map[string]string{namespaceParam: ns.Name})
+				}
+			}
+		default:
+			return nil, fmt.Errorf("spec.generators[%d]: one of list or namespaces must be specified", i)
+		}
+	}
+	return params, nil
+}
+
+// Substitutes the parameters referenced in the string fields of the template.
+func renderTemplate(template *v1beta1.FlinkClusterTemplate, params map[string]string) (*v1beta1.FlinkClusterTemplate, []byte, error) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var unresolved []string
+	var seen = map[string]bool{}
+	data = paramRegexp.ReplaceAllFunc(data, func(ref []byte) []byte {
+		var name = string(paramRegexp.FindSubmatch(ref)[1])
+		value, ok := params[name]
+		if !ok {
+			if !seen[name] {
+				seen[name] = true
+				unresolved = append(unresolved, name)
+			}
+			return ref
+		}
+		// Escape the value to be embedded in a JSON string.
+		escaped, _ := json.Marshal(value)
+		return escaped[1 : len(escaped)-1]
+	})
+	if len(unresolved) > 0 {
+		return nil, nil, fmt.Errorf("unresolved template parameters: %v", strings.Join(unresolved, ", "))
+	}
+
+	var rendered = new(v1beta1.FlinkClusterTemplate)
+	if err := json.Unmarshal(data, rendered); err != nil {
+		return nil, nil, err
+	}
+	return rendered, data, nil
+}
+
+// Generates the FlinkCluster of the set for the parameter set.
+func newFlinkCluster(set *v1beta1.FlinkClusterSet, params map[string]string) (*v1beta1.FlinkCluster, error) {
+	template, data, err := renderTemplate(&set.Spec.Template, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var namespace = template.Metadata.Namespace
+	if namespace == "" {
+		namespace = set.Namespace
+	}
+	var clusterLabels = map[string]string{}
+	for k, v := range template.Metadata.Labels {
+		clusterLabels[k] = v
+	}
+	clusterLabels[v1beta1.ClusterSetNameLabel] = set.Name
+	clusterLabels[v1beta1.ClusterSetNamespaceLabel] = set.Namespace
+	var annotations = map[string]string{}
+	for k, v := range template.Metadata.Annotations {
+		annotations[k] = v
+	}
+	hash := fnv.New32a()
+	hash.Write(data)
+	annotations[templateHashAnnotation] = fmt.Sprintf("%x", hash.Sum32())
+
+	return &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        template.Metadata.Name,
+			Namespace:   namespace,
+			Labels:      clusterLabels,
+			Annotations: annotations,
+		},
+		Spec: template.Spec,
+	}, nil
+}
+
+// Generates the FlinkClusters of the set, keyed by `<namespace>/<name>`.
+func getDesiredClusters(set *v1beta1.FlinkClusterSet, namespaces []corev1.Namespace) (map[string]*v1beta1.FlinkCluster, error) {
+	params, err := generateParams(set, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters = map[string]*v1beta1.FlinkCluster{}
+	for _, p := range params {
+		cluster, err := newFlinkCluster(set, p)
+		if err != nil {
+			return nil, err
+		}
+		if cluster.Name == "" {
+			return nil, fmt.Errorf("spec.template.metadata.name is empty for parameters %v", p)
+		}
+		var key = getClusterKey(cluster)
+		if _, ok := clusters[key]; ok {
+			return nil, fmt.Errorf("duplicate FlinkCluster %v, spec.template.metadata.name must be unique for each parameter set", key)
+		}
+		clusters[key] = cluster
+	}
+	return clusters, nil
+}
+
+func getClusterKey(cluster *v1beta1.FlinkCluster) string {
+	return cluster.Namespace + "/" + cluster.Name
+}
+
+// Checks whether the namespaces must be observed to generate the set.
+func hasNamespaceGenerator(set *v1beta1.FlinkClusterSet) bool {
+	for _, generator := range set.Spec.Generators {
+		if generator.Namespaces != nil {
+			return true
+		}
+	}
+	return false
+}
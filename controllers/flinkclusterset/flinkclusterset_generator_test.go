@@ -0,0 +1,112 @@
+package flinkclusterset
+
+import (
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newClusterSet(generators ...v1beta1.FlinkClusterSetGenerator) *v1beta1.FlinkClusterSet {
+	var jarFile = "gs://my-bucket/{{region}}/my-job.jar"
+	return &v1beta1.FlinkClusterSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-job", Namespace: "flink"},
+		Spec: v1beta1.FlinkClusterSetSpec{
+			Generators: generators,
+			Template: v1beta1.FlinkClusterTemplate{
+				Metadata: v1beta1.FlinkClusterTemplateMeta{
+					Name:   "my-job-{{region}}",
+					Labels: map[string]string{"region": "{{region}}"},
+				},
+				Spec: v1beta1.FlinkClusterSpec{
+					FlinkVersion: "1.14",
+					Image:        v1beta1.ImageSpec{Name: "flink:1.14"},
+					Job: &v1beta1.JobSpec{
+						JarFile: &jarFile,
+						Args:    []string{"--region", "{{region}}"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGetDesiredClustersFromList(t *testing.T) {
+	var set = newClusterSet(v1beta1.FlinkClusterSetGenerator{
+		List: []map[string]string{{"region": "us-east1"}, {"region": "europe-west1"}},
+	})
+
+	clusters, err := getDesiredClusters(set, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(clusters), 2)
+
+	var cluster = clusters["flink/my-job-us-east1"]
+	assert.Assert(t, cluster != nil)
+	assert.DeepEqual(t, cluster.Labels, map[string]string{
+		"region":                         "us-east1",
+		v1beta1.ClusterSetNameLabel:      "my-job",
+		v1beta1.ClusterSetNamespaceLabel: "flink",
+	})
+	assert.Equal(t, *cluster.Spec.Job.JarFile, "gs://my-bucket/us-east1/my-job.jar")
+	assert.DeepEqual(t, cluster.Spec.Job.Args, []string{"--region", "us-east1"})
+	assert.Assert(t, cluster.Annotations[templateHashAnnotation] != "")
+	assert.Assert(t, cluster.Annotations[templateHashAnnotation] !=
+		clusters["flink/my-job-europe-west1"].Annotations[templateHashAnnotation])
+
+	// The template of the set is not modified.
+	assert.Equal(t, set.Spec.Template.Metadata.Name, "my-job-{{region}}")
+}
+
+func TestGetDesiredClustersFromNamespaces(t *testing.T) {
+	var set = newClusterSet(v1beta1.FlinkClusterSetGenerator{
+		Namespaces: &v1beta1.FlinkClusterSetNamespaceGenerator{
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"flink": "enabled"}},
+		},
+	})
+	set.Spec.Template.Metadata.Name = "my-job"
+	set.Spec.Template.Metadata.Namespace = "{{namespace}}"
+	set.Spec.Template.Metadata.Labels = nil
+	var jarFile = "gs://my-bucket/my-job.jar"
+	set.Spec.Template.Spec.Job.JarFile = &jarFile
+	set.Spec.Template.Spec.Job.Args = []string{"--tenant", "{{ namespace }}"}
+	var namespaces = []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Labels: map[string]string{"flink": "enabled"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "tenant-b"}},
+	}
+
+	clusters, err := getDesiredClusters(set, namespaces)
+	assert.NilError(t, err)
+	assert.Equal(t, len(clusters), 1)
+	var cluster = clusters["tenant-a/my-job"]
+	assert.Assert(t, cluster != nil)
+	assert.DeepEqual(t, cluster.Spec.Job.Args, []string{"--tenant", "tenant-a"})
+	assert.Equal(t, cluster.Labels[v1beta1.ClusterSetNamespaceLabel], "flink")
+}
+
+func TestGetDesiredClustersInvalid(t *testing.T) {
+	var set = newClusterSet(v1beta1.FlinkClusterSetGenerator{
+		List: []map[string]string{{"zone": "us-east1-b"}},
+	})
+	var _, err = getDesiredClusters(set, nil)
+	assert.Error(t, err, "unresolved template parameters: region")
+
+	set = newClusterSet(v1beta1.FlinkClusterSetGenerator{
+		List: []map[string]string{{"region": "us-east1"}, {"region": "us-east1"}},
+	})
+	_, err = getDesiredClusters(set, nil)
+	assert.Error(t, err, "duplicate FlinkCluster flink/my-job-us-east1, spec.template.metadata.name must be unique for each parameter set")
+
+	set = newClusterSet(v1beta1.FlinkClusterSetGenerator{})
+	_, err = getDesiredClusters(set, nil)
+	assert.Error(t, err, "spec.generators[0]: one of list or namespaces must be specified")
+
+	// Values are escaped in the rendered template.
+	set = newClusterSet(v1beta1.FlinkClusterSetGenerator{
+		List: []map[string]string{{"region": `us"east1`}},
+	})
+	clusters, err := getDesiredClusters(set, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, clusters[`flink/my-job-us"east1`].Spec.Job.Args[1], `us"east1`)
+}
@@ -1,7 +1,7 @@
 package types
 
 import (
-	"github.com/spotify/flink-on-k8s-operator/internal/model"
+	"github.com/spotify/flink-on-k8s-operator/pkg/model"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -29,8 +29,8 @@ import (
 	volcanoclient "volcano.sh/apis/pkg/client/clientset/versioned"
 
 	schedulerinterface "github.com/spotify/flink-on-k8s-operator/internal/batchscheduler/types"
-	"github.com/spotify/flink-on-k8s-operator/internal/model"
 	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/model"
 )
 
 const (
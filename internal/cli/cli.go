@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Subcommands of the operator binary by the user controls they request, so that scripts do
+// not need to know the annotation.
+var commands = map[string]string{
+	"savepoint": v1beta1.ControlNameSavepoint,
+	"cancel":    v1beta1.ControlNameJobCancel,
+}
+
+// The interval of the polls of the cluster with --wait.
+var pollInterval = 2 * time.Second
+
+// IsCommand returns true if the name is a subcommand rather than a flag of the manager.
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// Run runs the subcommand of args, e.g. `savepoint default/my-cluster --wait`, with the
+// output written to out.
+func Run(ctx context.Context, k8sClient client.Client, args []string, out io.Writer) error {
+	var command = args[0]
+	var control, ok = commands[command]
+	if !ok {
+		return fmt.Errorf("unknown command %q, available commands: %v", command, strings.Join(getCommandNames(), ", "))
+	}
+
+	var flags = flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(out)
+	var wait = flags.Bool("wait", false, "Wait until the operator completes the control.")
+	var timeout = flags.Duration("timeout", 10*time.Minute, "The maximum time to wait with --wait.")
+	flags.Usage = func() {
+		fmt.Fprintf(out, "Usage: flink-operator %v <namespace>/<name> [--wait] [--timeout <duration>]\n", command)
+		flags.PrintDefaults()
+	}
+	// The flags may follow the cluster.
+	var positional []string
+	for rest := args[1:]; ; rest = flags.Args()[1:] {
+		if err := flags.Parse(rest); err == flag.ErrHelp {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+	}
+	if len(positional) != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected a single cluster, got %d arguments", len(positional))
+	}
+	key, err := parseClusterKey(positional[0])
+	if err != nil {
+		return err
+	}
+
+	previous, err := requestControl(ctx, k8sClient, key, control)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Requested %v of %v\n", control, key)
+	if !*wait {
+		return nil
+	}
+	return waitForControl(ctx, k8sClient, key, control, previous, *timeout, out)
+}
+
+func getCommandNames() []string {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseClusterKey(arg string) (types.NamespacedName, error) {
+	var parts = strings.Split(arg, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return types.NamespacedName{}, fmt.Errorf("invalid cluster %q, expected <namespace>/<name>", arg)
+	}
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
+
+// requestControl sets the user control annotation of the cluster. The update is checked like
+// the validating webhook does, so that invalid requests fail with its message even where the
+// webhook is disabled, and applied only if the cluster has not changed since. Returns the
+// control status of the cluster the request was applied to.
+func requestControl(
+	ctx context.Context,
+	k8sClient client.Client,
+	key types.NamespacedName,
+	control string) (*v1beta1.FlinkClusterControlStatus, error) {
+	var cluster = &v1beta1.FlinkCluster{}
+	if err := k8sClient.Get(ctx, key, cluster); err != nil {
+		return nil, err
+	}
+	var requested = cluster.DeepCopy()
+	if requested.Annotations == nil {
+		requested.Annotations = map[string]string{}
+	}
+	requested.Annotations[v1beta1.ControlAnnotation] = control
+	if err := (&v1beta1.Validator{}).ValidateUpdate(cluster, requested); err != nil {
+		return nil, err
+	}
+	var err = k8sClient.Patch(ctx, requested, client.MergeFromWithOptions(cluster, client.MergeFromWithOptimisticLock{}))
+	return cluster.Status.Control, err
+}
+
+// waitForControl waits until the operator clears the user control annotation, which it does
+// when the control finishes or is refused, and reports the result of the control. The
+// control status is the one of the request only if it changed since the previous status, as
+// a control of the same name may have run before, e.g. an earlier savepoint.
+func waitForControl(
+	ctx context.Context,
+	k8sClient client.Client,
+	key types.NamespacedName,
+	control string,
+	previous *v1beta1.FlinkClusterControlStatus,
+	timeout time.Duration,
+	out io.Writer) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var ticker = time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var cluster = &v1beta1.FlinkCluster{}
+	for {
+		if err := k8sClient.Get(ctx, key, cluster); err != nil {
+			return err
+		}
+		if cluster.Annotations[v1beta1.ControlAnnotation] != control {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %v of %v", control, key)
+		case <-ticker.C:
+		}
+	}
+
+	var status = cluster.Status.Control
+	switch {
+	case status == nil || status.Name != control || reflect.DeepEqual(status, previous):
+		return fmt.Errorf("%v of %v was refused by the operator", control, key)
+	case status.State == v1beta1.ControlStateFailed:
+		return fmt.Errorf("%v of %v failed: %v", control, key, status.Message)
+	}
+	fmt.Fprintf(out, "Completed %v of %v\n", control, key)
+	if job := cluster.Status.Components.Job; control == v1beta1.ControlNameSavepoint && job != nil && job.SavepointLocation != "" {
+		fmt.Fprintf(out, "Savepoint: %v\n", job.SavepointLocation)
+	}
+	return nil
+}
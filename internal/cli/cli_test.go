@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestClient(t *testing.T) (client.Client, types.NamespacedName) {
+	var savepointsDir = "gs://my-bucket/savepoints"
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "clicks", Namespace: "default"},
+		Spec: v1beta1.FlinkClusterSpec{
+			Job: &v1beta1.JobSpec{SavepointsDir: &savepointsDir},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Components: v1beta1.FlinkClusterComponentsStatus{
+				Job: &v1beta1.JobStatus{State: v1beta1.JobStateRunning},
+			},
+		},
+	}
+	var scheme = runtime.NewScheme()
+	assert.NilError(t, v1beta1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build(), client.ObjectKeyFromObject(cluster)
+}
+
+func TestRun(t *testing.T) {
+	var k8sClient, key = newTestClient(t)
+	var out bytes.Buffer
+	assert.NilError(t, Run(context.TODO(), k8sClient, []string{"savepoint", "default/clicks"}, &out))
+	assert.Equal(t, out.String(), "Requested savepoint of default/clicks\n")
+
+	var cluster = &v1beta1.FlinkCluster{}
+	assert.NilError(t, k8sClient.Get(context.TODO(), key, cluster))
+	assert.Equal(t, cluster.Annotations[v1beta1.ControlAnnotation], v1beta1.ControlNameSavepoint)
+
+	assert.Error(t, Run(context.TODO(), k8sClient, []string{"cancel", "clicks"}, &out),
+		`invalid cluster "clicks", expected <namespace>/<name>`)
+	assert.ErrorContains(t, Run(context.TODO(), k8sClient, []string{"cancel", "default/orders"}, &out), "not found")
+}
+
+func TestRunValidation(t *testing.T) {
+	var k8sClient, key = newTestClient(t)
+	var cluster = &v1beta1.FlinkCluster{}
+	assert.NilError(t, k8sClient.Get(context.TODO(), key, cluster))
+	cluster.Status.Components.Job.State = v1beta1.JobStateSucceeded
+	assert.NilError(t, k8sClient.Update(context.TODO(), cluster))
+
+	// The same messages as the validating webhook.
+	var err = Run(context.TODO(), k8sClient, []string{"cancel", "default/clicks"}, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "job-cancel is not allowed because job is not started yet or already terminated")
+	err = Run(context.TODO(), k8sClient, []string{"savepoint", "default/clicks"}, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "savepoint is not allowed because job is not started yet or already stopped")
+
+	assert.NilError(t, k8sClient.Get(context.TODO(), key, cluster))
+	assert.Equal(t, len(cluster.Annotations), 0)
+}
+
+// completeControl clears the user control annotation of the cluster once it is set, after
+// updating the status of the cluster like the operator does.
+func completeControl(k8sClient client.Client, key types.NamespacedName, updateStatus func(cluster *v1beta1.FlinkCluster)) {
+	for {
+		var cluster = &v1beta1.FlinkCluster{}
+		if err := k8sClient.Get(context.TODO(), key, cluster); err == nil && cluster.Annotations[v1beta1.ControlAnnotation] != "" {
+			delete(cluster.Annotations, v1beta1.ControlAnnotation)
+			updateStatus(cluster)
+			if k8sClient.Update(context.TODO(), cluster) == nil {
+				return
+			}
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+func TestRunWait(t *testing.T) {
+	pollInterval = 10 * time.Millisecond
+	defer func() { pollInterval = 2 * time.Second }()
+
+	var k8sClient, key = newTestClient(t)
+	// The operator completes the savepoint and clears the annotation.
+	go completeControl(k8sClient, key, func(cluster *v1beta1.FlinkCluster) {
+		cluster.Status.Control = &v1beta1.FlinkClusterControlStatus{
+			Name:  v1beta1.ControlNameSavepoint,
+			State: v1beta1.ControlStateSucceeded,
+		}
+		cluster.Status.Components.Job.SavepointLocation = "gs://my-bucket/savepoints/savepoint-1"
+	})
+
+	var out bytes.Buffer
+	assert.NilError(t, Run(context.TODO(), k8sClient, []string{"savepoint", "default/clicks", "--wait", "--timeout=5s"}, &out))
+	assert.Equal(t, out.String(), "Requested savepoint of default/clicks\n"+
+		"Completed savepoint of default/clicks\n"+
+		"Savepoint: gs://my-bucket/savepoints/savepoint-1\n")
+
+	// The operator refuses the control.
+	var cluster = &v1beta1.FlinkCluster{}
+	assert.NilError(t, k8sClient.Get(context.TODO(), key, cluster))
+	cluster.Status.Control = nil
+	assert.NilError(t, k8sClient.Update(context.TODO(), cluster))
+	go completeControl(k8sClient, key, func(cluster *v1beta1.FlinkCluster) {})
+	var err = Run(context.TODO(), k8sClient, []string{"cancel", "--wait", "default/clicks"}, &out)
+	assert.Error(t, err, "job-cancel of default/clicks was refused by the operator")
+
+	// The operator refuses the control and the status is of an earlier savepoint.
+	assert.NilError(t, k8sClient.Get(context.TODO(), key, cluster))
+	cluster.Status.Control = &v1beta1.FlinkClusterControlStatus{
+		Name:       v1beta1.ControlNameSavepoint,
+		State:      v1beta1.ControlStateSucceeded,
+		UpdateTime: "2024-03-01T12:00:00Z",
+	}
+	assert.NilError(t, k8sClient.Update(context.TODO(), cluster))
+	go completeControl(k8sClient, key, func(cluster *v1beta1.FlinkCluster) {})
+	err = Run(context.TODO(), k8sClient, []string{"savepoint", "--wait", "default/clicks"}, &out)
+	assert.Error(t, err, "savepoint of default/clicks was refused by the operator")
+}
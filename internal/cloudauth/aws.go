@@ -0,0 +1,216 @@
+// Package cloudauth authenticates the requests of the operator and of the pods it runs to the
+// APIs of the cloud providers, with the credentials of the workload they run as: the AWS_*
+// environment variables or the web identity of IAM roles for service accounts on AWS, and the
+// service account of the metadata server, e.g. through Workload Identity, on GCP.
+package cloudauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoCredentials is returned when no credentials are configured for the provider.
+var ErrNoCredentials = errors.New("no credentials are configured")
+
+// UnsignedPayload is the payload hash of the requests to S3 whose body is not signed, e.g.
+// streamed uploads.
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// AWSCredentials are the credentials the requests to AWS are signed with.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// AWSCredentialsProvider gets the credentials of the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// and AWS_SESSION_TOKEN environment variables, otherwise exchanges the web identity token of
+// AWS_WEB_IDENTITY_TOKEN_FILE for the credentials of the AWS_ROLE_ARN role, as set by IAM
+// roles for service accounts. The exchanged credentials are cached until shortly before they
+// expire.
+type AWSCredentialsProvider struct {
+	// The endpoint of STS, https://sts.<region>.amazonaws.com if AWS_REGION is set, otherwise
+	// https://sts.amazonaws.com, if empty.
+	STSEndpoint string
+
+	client *http.Client
+	now    func() time.Time
+	getenv func(string) string
+
+	mu      sync.Mutex
+	cached  AWSCredentials
+	expires time.Time
+}
+
+// NewAWSCredentialsProvider returns the provider of the credentials of the process.
+func NewAWSCredentialsProvider() *AWSCredentialsProvider {
+	return &AWSCredentialsProvider{client: &http.Client{Timeout: 10 * time.Second}, now: time.Now, getenv: os.Getenv}
+}
+
+// Get returns the credentials, ErrNoCredentials if none are configured.
+func (p *AWSCredentialsProvider) Get(ctx context.Context) (AWSCredentials, error) {
+	if id, secret := p.getenv("AWS_ACCESS_KEY_ID"), p.getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return AWSCredentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: p.getenv("AWS_SESSION_TOKEN")}, nil
+	}
+	var roleARN, tokenFile = p.getenv("AWS_ROLE_ARN"), p.getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	if roleARN == "" || tokenFile == "" {
+		return AWSCredentials{}, ErrNoCredentials
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var now = p.now()
+	if p.cached.AccessKeyID != "" && now.Before(p.expires) {
+		return p.cached, nil
+	}
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("failed to read the web identity token: %v", err)
+	}
+	creds, expiration, err := p.assumeRoleWithWebIdentity(ctx, roleARN, strings.TrimSpace(string(token)))
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	// Exchange the token again five minutes before the credentials expire.
+	p.cached = creds
+	p.expires = expiration.Add(-5 * time.Minute)
+	return creds, nil
+}
+
+func (p *AWSCredentialsProvider) assumeRoleWithWebIdentity(
+	ctx context.Context, roleARN, token string) (AWSCredentials, time.Time, error) {
+	var endpoint = p.STSEndpoint
+	if endpoint == "" {
+		endpoint = "https://sts.amazonaws.com"
+		if region := p.getenv("AWS_REGION"); region != "" {
+			endpoint = fmt.Sprintf("https://sts.%s.amazonaws.com", region)
+		}
+	}
+	var sessionName = p.getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = fmt.Sprintf("flink-operator-%d", p.now().Unix())
+	}
+	var form = url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleARN},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {token},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", strings.NewReader(form.Encode()))
+	if err != nil {
+		return AWSCredentials{}, time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return AWSCredentials{}, time.Time{}, fmt.Errorf("failed to assume role %v: %v", roleARN, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return AWSCredentials{}, time.Time{}, fmt.Errorf("failed to assume role %v: %v", roleARN, resp.Status)
+	}
+	var result struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return AWSCredentials{}, time.Time{}, fmt.Errorf("failed to assume role %v: %v", roleARN, err)
+	}
+	var creds = result.Credentials
+	return AWSCredentials{AccessKeyID: creds.AccessKeyID, SecretAccessKey: creds.SecretAccessKey, SessionToken: creds.SessionToken},
+		creds.Expiration, nil
+}
+
+// HashPayload returns the payload hash of a request body to sign.
+func HashPayload(body []byte) string {
+	return hashHex(body)
+}
+
+// SignAWSRequest signs the request and its headers with Signature Version 4, with the payload
+// hash of HashPayload or UnsignedPayload. The requests to S3 carry the payload hash in the
+// X-Amz-Content-Sha256 header.
+// https://docs.aws.amazon.com/general/latest/gr/sigv4_signing.html
+func SignAWSRequest(req *http.Request, payloadHash, service, region string, creds AWSCredentials, now time.Time) {
+	var amzDate = now.UTC().Format("20060102T150405Z")
+	var date = amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	if service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	var headers = map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		if strings.EqualFold(key, "Authorization") {
+			continue
+		}
+		headers[strings.ToLower(key)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, headers[name])
+	}
+	var signedHeaders = strings.Join(names, ";")
+	var path = req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	var canonicalRequest = strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	var scope = fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+	var stringToSign = strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+	var key = []byte("AWS4" + creds.SecretAccessKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	var signature = hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// Encodes the query sorted by key, with spaces encoded as %20 as Signature Version 4 requires.
+func canonicalQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func hashHex(data []byte) string {
+	var sum = sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	var mac = hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,109 @@
+package cloudauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// The example of the Signature Version 4 test suite.
+func TestSignAWSRequest(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	var creds = AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	SignAWSRequest(req, HashPayload(nil), "iam", "us-east-1", creds, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	assert.Equal(t, req.Header.Get("Authorization"),
+		"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, "+
+			"SignedHeaders=content-type;host;x-amz-date, "+
+			"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7")
+}
+
+func TestAWSCredentialsProviderWebIdentity(t *testing.T) {
+	var calls int
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		r.ParseForm()
+		if r.Form.Get("Action") != "AssumeRoleWithWebIdentity" || r.Form.Get("WebIdentityToken") != "jwt" ||
+			r.Form.Get("RoleArn") != "arn:aws:iam::123456789012:role/flink" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`<AssumeRoleWithWebIdentityResponse><AssumeRoleWithWebIdentityResult><Credentials>` +
+			`<AccessKeyId>ASIA</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>session</SessionToken>` +
+			`<Expiration>2023-01-01T13:00:00Z</Expiration>` +
+			`</Credentials></AssumeRoleWithWebIdentityResult></AssumeRoleWithWebIdentityResponse>`))
+	}))
+	defer server.Close()
+
+	var tokenFile = filepath.Join(t.TempDir(), "token")
+	assert.NilError(t, os.WriteFile(tokenFile, []byte("jwt\n"), 0600))
+	var env = map[string]string{
+		"AWS_ROLE_ARN":                "arn:aws:iam::123456789012:role/flink",
+		"AWS_WEB_IDENTITY_TOKEN_FILE": tokenFile,
+	}
+	var now = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	var provider = NewAWSCredentialsProvider()
+	provider.STSEndpoint = server.URL
+	provider.getenv = func(key string) string { return env[key] }
+	provider.now = func() time.Time { return now }
+
+	creds, err := provider.Get(context.Background())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, creds, AWSCredentials{AccessKeyID: "ASIA", SecretAccessKey: "secret", SessionToken: "session"})
+
+	// The credentials are cached until five minutes before they expire.
+	now = now.Add(50 * time.Minute)
+	_, err = provider.Get(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, calls, 1)
+	now = now.Add(5 * time.Minute)
+	_, err = provider.Get(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, calls, 2)
+
+	// The static credentials take precedence.
+	env["AWS_ACCESS_KEY_ID"], env["AWS_SECRET_ACCESS_KEY"] = "AKID", "key"
+	creds, _ = provider.Get(context.Background())
+	assert.Equal(t, creds.AccessKeyID, "AKID")
+
+	env = map[string]string{}
+	_, err = provider.Get(context.Background())
+	assert.Equal(t, err, ErrNoCredentials)
+}
+
+func TestGCPTokenSource(t *testing.T) {
+	var calls int
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"access_token": "token", "expires_in": 3600}`))
+	}))
+	defer server.Close()
+
+	var now = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	var source = NewGCPTokenSource()
+	source.MetadataEndpoint = server.URL
+	source.getenv = func(string) string { return "" }
+	source.now = func() time.Time { return now }
+
+	token, err := source.Token(context.Background())
+	assert.NilError(t, err)
+	assert.Equal(t, token, "token")
+
+	// The token is cached until a minute before it expires.
+	now = now.Add(58 * time.Minute)
+	source.Token(context.Background())
+	assert.Equal(t, calls, 1)
+	now = now.Add(time.Minute)
+	source.Token(context.Background())
+	assert.Equal(t, calls, 2)
+}
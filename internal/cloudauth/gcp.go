@@ -0,0 +1,123 @@
+package cloudauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+)
+
+// The scope of the access tokens, which the IAM roles of the service account restrict.
+const gcpCloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// GCPTokenSource gets the access tokens of the service account key file of
+// GOOGLE_APPLICATION_CREDENTIALS, otherwise of the service account of the metadata server,
+// e.g. the Kubernetes service account of the pod through Workload Identity. The tokens are
+// cached until a minute before they expire.
+type GCPTokenSource struct {
+	// The endpoint of the metadata server, http://metadata.google.internal if empty.
+	MetadataEndpoint string
+
+	client *http.Client
+	now    func() time.Time
+	getenv func(string) string
+
+	mu          sync.Mutex
+	keyFile     oauth2.TokenSource
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewGCPTokenSource returns the token source of the credentials of the process.
+func NewGCPTokenSource() *GCPTokenSource {
+	return &GCPTokenSource{client: &http.Client{Timeout: 10 * time.Second}, now: time.Now, getenv: os.Getenv}
+}
+
+// Token returns an access token.
+func (s *GCPTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if path := s.getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		return s.keyFileToken(ctx, path)
+	}
+
+	var now = s.now()
+	if s.token != "" && now.Before(s.tokenExpiry) {
+		return s.token, nil
+	}
+	var endpoint = s.MetadataEndpoint
+	if endpoint == "" {
+		endpoint = "http://metadata.google.internal"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		endpoint+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get an access token from the metadata server: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get an access token from the metadata server: %v", resp.Status)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to get an access token from the metadata server: %v", err)
+	}
+	// Request another token a minute before the token expires.
+	s.token = token.AccessToken
+	s.tokenExpiry = now.Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return s.token, nil
+}
+
+// Gets a token of the service account key file, which is read once.
+func (s *GCPTokenSource) keyFileToken(ctx context.Context, path string) (string, error) {
+	if s.keyFile == nil {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read GOOGLE_APPLICATION_CREDENTIALS: %v", err)
+		}
+		var key struct {
+			Type         string `json:"type"`
+			ClientEmail  string `json:"client_email"`
+			PrivateKey   string `json:"private_key"`
+			PrivateKeyID string `json:"private_key_id"`
+			TokenURI     string `json:"token_uri"`
+		}
+		if err := json.Unmarshal(data, &key); err != nil {
+			return "", fmt.Errorf("failed to parse GOOGLE_APPLICATION_CREDENTIALS: %v", err)
+		}
+		if key.Type != "service_account" {
+			return "", fmt.Errorf("GOOGLE_APPLICATION_CREDENTIALS of type %q is not supported, only service_account", key.Type)
+		}
+		var config = &jwt.Config{
+			Email:        key.ClientEmail,
+			PrivateKey:   []byte(key.PrivateKey),
+			PrivateKeyID: key.PrivateKeyID,
+			TokenURL:     key.TokenURI,
+			Scopes:       []string{gcpCloudPlatformScope},
+		}
+		if config.TokenURL == "" {
+			config.TokenURL = "https://oauth2.googleapis.com/token"
+		}
+		// The token source refreshes the token shortly before it expires.
+		s.keyFile = config.TokenSource(context.WithValue(context.Background(), oauth2.HTTPClient, s.client))
+	}
+	token, err := s.keyFile.Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to get an access token of GOOGLE_APPLICATION_CREDENTIALS: %v", err)
+	}
+	return token.AccessToken, nil
+}
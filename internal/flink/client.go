@@ -19,13 +19,19 @@ package flink
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	neturl "net/url"
+	"path"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 const (
@@ -50,12 +56,25 @@ func (e *responseError) Error() string {
 
 type roundTripper struct {
 	Proxied http.RoundTripper
+	// (Optional) Rate limiter of the calls to the cluster.
+	limiter *RateLimiter
+	cluster types.NamespacedName
+	// Faults injected into the calls, empty unless built with the faultinjection build tag.
+	faults faultInjection
 }
 
 func (rt *roundTripper) RoundTrip(req *http.Request) (res *http.Response, e error) {
+	if rt.limiter != nil {
+		if err := rt.limiter.wait(req.Context(), rt.cluster); err != nil {
+			return nil, err
+		}
+	}
+	if err := rt.injectFaults(req); err != nil {
+		return nil, err
+	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "flink-operator")
-	resp, err := rt.Proxied.RoundTrip(req)
+	resp, err := rt.Proxied.RoundTrip(withConnectionTrace(req))
 	if err != nil {
 		return nil, err
 	}
@@ -85,6 +104,169 @@ type JobExceptions struct {
 	Exceptions []JobException `json:"all-exceptions"`
 }
 
+// UserAccumulator defines a user-defined accumulator of a Flink job.
+type UserAccumulator struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// JobAccumulators defines the accumulators of a Flink job.
+type JobAccumulators struct {
+	UserAccumulators []UserAccumulator `json:"user-task-accumulators"`
+}
+
+// CheckpointCounts defines the checkpoint counts of a Flink job.
+type CheckpointCounts struct {
+	Restored   int `json:"restored"`
+	Total      int `json:"total"`
+	InProgress int `json:"in_progress"`
+	Completed  int `json:"completed"`
+	Failed     int `json:"failed"`
+}
+
+// CheckpointStatistics defines the statistics of a checkpoint.
+type CheckpointStatistics struct {
+	ID                 int64  `json:"id"`
+	Status             string `json:"status"`
+	IsSavepoint        bool   `json:"is_savepoint"`
+	TriggerTimestamp   int64  `json:"trigger_timestamp"`
+	LatestAckTimestamp int64  `json:"latest_ack_timestamp"`
+	StateSize          int64  `json:"state_size"`
+	// The size of the data persisted for the checkpoint, less than the state size with
+	// incremental checkpoints, and 0 if the state did not change. Flink 1.15+, nil before.
+	CheckpointedSize *int64 `json:"checkpointed_size"`
+	// The duration from the trigger to the latest acknowledgement, in milliseconds.
+	EndToEndDuration int64 `json:"end_to_end_duration"`
+	// The location of the completed checkpoint.
+	ExternalPath string `json:"external_path"`
+	// The statistics of the vertices, listed only by GetCheckpointDetails.
+	Tasks map[string]TaskCheckpointStatistics `json:"tasks"`
+}
+
+// TaskCheckpointStatistics defines the statistics of a checkpoint of a vertex.
+type TaskCheckpointStatistics struct {
+	ID               string `json:"id"`
+	EndToEndDuration int64  `json:"end_to_end_duration"`
+}
+
+// MinMaxAvgStatistics defines statistics aggregated over the subtasks of a vertex.
+type MinMaxAvgStatistics struct {
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+	Avg int64 `json:"avg"`
+}
+
+// TaskCheckpointDetails defines the statistics of a checkpoint of the subtasks of a vertex.
+type TaskCheckpointDetails struct {
+	Summary struct {
+		Alignment struct {
+			// The time the barriers were aligned, in milliseconds.
+			Duration MinMaxAvgStatistics `json:"duration"`
+		} `json:"alignment"`
+	} `json:"summary"`
+}
+
+// LatestCheckpoints defines the latest checkpoints of a Flink job.
+type LatestCheckpoints struct {
+	// Nil if no checkpoint completed yet.
+	Completed *CheckpointStatistics `json:"completed"`
+	// Nil if no savepoint completed yet.
+	Savepoint *CheckpointStatistics `json:"savepoint"`
+}
+
+// JobCheckpoints defines the checkpoint statistics of a Flink job.
+type JobCheckpoints struct {
+	Counts CheckpointCounts  `json:"counts"`
+	Latest LatestCheckpoints `json:"latest"`
+	// The recent checkpoints and savepoints, the latest first.
+	History []CheckpointStatistics `json:"history"`
+}
+
+// JobVertex defines a vertex of the execution graph of a Flink job.
+type JobVertex struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Parallelism int    `json:"parallelism"`
+}
+
+// JobDetails defines the details of a Flink job.
+type JobDetails struct {
+	ID       string      `json:"jid"`
+	Vertices []JobVertex `json:"vertices"`
+}
+
+// JobPlan defines the dataflow plan of a Flink job, the JSON of the job graph.
+type JobPlan struct {
+	Plan json.RawMessage `json:"plan"`
+}
+
+// AggregatedMetric defines a metric aggregated over the subtasks of a job vertex.
+// Only the ID is set when the available metrics are listed.
+type AggregatedMetric struct {
+	ID  string  `json:"id"`
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+	Avg float64 `json:"avg"`
+	Sum float64 `json:"sum"`
+}
+
+// JobVertexBackPressure defines the back pressure of a job vertex sampled by the JobManager.
+// The level is empty until the first sample completes.
+type JobVertexBackPressure struct {
+	Status string `json:"status"`
+	Level  string `json:"backpressure-level"`
+}
+
+// JobMetric defines a metric of a Flink job.
+type JobMetric struct {
+	ID    string `json:"id"`
+	Value string `json:"value"`
+}
+
+// TaskManager defines a TaskManager registered to the JobManager.
+type TaskManager struct {
+	ID          string `json:"id"`
+	Path        string `json:"path"`
+	SlotsNumber int    `json:"slotsNumber"`
+	FreeSlots   int    `json:"freeSlots"`
+}
+
+// TaskManagersOverview defines the TaskManagers registered to the JobManager.
+type TaskManagersOverview struct {
+	TaskManagers []TaskManager `json:"taskmanagers"`
+}
+
+// ThreadInfo defines a thread of a thread dump.
+type ThreadInfo struct {
+	ThreadName            string `json:"threadName"`
+	StringifiedThreadInfo string `json:"stringifiedThreadInfo"`
+}
+
+// ThreadDump defines a thread dump of the JobManager or a TaskManager.
+type ThreadDump struct {
+	ThreadInfos []ThreadInfo `json:"threadInfos"`
+}
+
+// Jar defines a JAR file uploaded to the JobManager.
+type Jar struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Uploaded int64  `json:"uploaded"`
+}
+
+// JarsOverview defines the JAR files uploaded to the JobManager.
+type JarsOverview struct {
+	Files []Jar `json:"files"`
+}
+
+// JarUploadResponse defines the response of a JAR file upload.
+type JarUploadResponse struct {
+	// The path of the file in the upload directory of the JobManager, the base name is the JAR ID.
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+}
+
 // Job defines Flink job status.
 type Job struct {
 	Id        string `json:"jid"`
@@ -95,6 +277,12 @@ type Job struct {
 	Duration  int64  `json:"duration"`
 }
 
+// DashboardConfig defines the configuration of the web UI, with the version of the JobManager.
+type DashboardConfig struct {
+	FlinkVersion  string `json:"flink-version"`
+	FlinkRevision string `json:"flink-revision"`
+}
+
 // JobsOverview defines Flink job overview list.
 type JobsOverview struct {
 	Jobs []Job
@@ -144,6 +332,21 @@ func (s *SavepointStatus) IsFailed() bool {
 	return s.Completed && s.FailureCause.StackTrace != ""
 }
 
+// GetConfig returns the configuration of the web UI, e.g. the Flink version of the JobManager.
+func (c *Client) GetConfig(apiBaseURL string) (*DashboardConfig, error) {
+	resp, err := c.httpClient.Get(apiBaseURL + "/config")
+	if err != nil {
+		return nil, err
+	}
+
+	config := &DashboardConfig{}
+	if err := parseJson(resp, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
 func (c *Client) GetJobsOverview(apiBaseURL string) (*JobsOverview, error) {
 	resp, err := c.httpClient.Get(apiBaseURL + "/jobs/overview")
 	if err != nil {
@@ -192,6 +395,24 @@ func (c *Client) TriggerSavepoint(apiBaseURL string, jobID string, dir string, c
 	return triggerID, err
 }
 
+// StopJobWithSavepoint triggers an async operation which stops the job with a savepoint. The
+// status of the operation is returned by GetSavepointStatus with the trigger ID.
+func (c *Client) StopJobWithSavepoint(apiBaseURL string, jobID string, dir string) (*SavepointTriggerID, error) {
+	url := fmt.Sprintf("%s/jobs/%s/stop", apiBaseURL, jobID)
+	jsonStr := fmt.Sprintf(`{
+		"targetDirectory" : "%s",
+		"drain" : false
+	}`, dir)
+	resp, err := c.httpClient.Post(url, "application/json", strings.NewReader(jsonStr))
+	if err != nil {
+		return nil, err
+	}
+
+	triggerID := &SavepointTriggerID{}
+	err = parseJson(resp, triggerID)
+	return triggerID, err
+}
+
 // GetSavepointStatus returns savepoint status.
 //
 // Flink API response examples:
@@ -313,8 +534,349 @@ func (c *Client) GetJobExceptions(apiBaseURL string, jobId string) (*JobExceptio
 	return exp, nil
 }
 
+// GetJobAccumulators returns the accumulators of the job.
+func (c *Client) GetJobAccumulators(apiBaseURL string, jobId string) (*JobAccumulators, error) {
+	url := fmt.Sprintf("%s/jobs/%s/accumulators", apiBaseURL, jobId)
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	accumulators := &JobAccumulators{}
+	if err := parseJson(resp, accumulators); err != nil {
+		return nil, err
+	}
+
+	return accumulators, nil
+}
+
+// GetTaskManagers returns the TaskManagers registered to the JobManager.
+func (c *Client) GetTaskManagers(apiBaseURL string) (*TaskManagersOverview, error) {
+	url := fmt.Sprintf("%s/taskmanagers", apiBaseURL)
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	taskManagers := &TaskManagersOverview{}
+	if err := parseJson(resp, taskManagers); err != nil {
+		return nil, err
+	}
+
+	return taskManagers, nil
+}
+
+// GetJobManagerThreadDump returns a thread dump of the JobManager, available since Flink 1.13.
+func (c *Client) GetJobManagerThreadDump(apiBaseURL string) (*ThreadDump, error) {
+	url := fmt.Sprintf("%s/jobmanager/thread-dump", apiBaseURL)
+	return c.getThreadDump(url)
+}
+
+// GetTaskManagerThreadDump returns a thread dump of the TaskManager.
+func (c *Client) GetTaskManagerThreadDump(apiBaseURL string, taskManagerID string) (*ThreadDump, error) {
+	url := fmt.Sprintf("%s/taskmanagers/%s/thread-dump", apiBaseURL, taskManagerID)
+	return c.getThreadDump(url)
+}
+
+func (c *Client) getThreadDump(url string) (*ThreadDump, error) {
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	threadDump := &ThreadDump{}
+	if err := parseJson(resp, threadDump); err != nil {
+		return nil, err
+	}
+
+	return threadDump, nil
+}
+
+// GetJobCheckpoints returns the checkpoint statistics of the job.
+func (c *Client) GetJobCheckpoints(apiBaseURL string, jobId string) (*JobCheckpoints, error) {
+	url := fmt.Sprintf("%s/jobs/%s/checkpoints", apiBaseURL, jobId)
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints := &JobCheckpoints{}
+	if err := parseJson(resp, checkpoints); err != nil {
+		return nil, err
+	}
+
+	return checkpoints, nil
+}
+
+// GetCheckpointDetails returns the statistics of the checkpoint of the job with those of its vertices.
+func (c *Client) GetCheckpointDetails(apiBaseURL string, jobId string, checkpointId int64) (*CheckpointStatistics, error) {
+	url := fmt.Sprintf("%s/jobs/%s/checkpoints/details/%d", apiBaseURL, jobId, checkpointId)
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint := &CheckpointStatistics{}
+	if err := parseJson(resp, checkpoint); err != nil {
+		return nil, err
+	}
+
+	return checkpoint, nil
+}
+
+// GetTaskCheckpointDetails returns the statistics of the checkpoint of the job for the subtasks of the vertex.
+func (c *Client) GetTaskCheckpointDetails(apiBaseURL string, jobId string, checkpointId int64, vertexId string) (*TaskCheckpointDetails, error) {
+	url := fmt.Sprintf("%s/jobs/%s/checkpoints/details/%d/subtasks/%s", apiBaseURL, jobId, checkpointId, vertexId)
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &TaskCheckpointDetails{}
+	if err := parseJson(resp, details); err != nil {
+		return nil, err
+	}
+
+	return details, nil
+}
+
+// TriggerCheckpoint triggers a checkpoint of the job, for Flink 1.17+, and returns the ID
+// of the trigger. The checkpoint is taken asynchronously, its status is returned by
+// GetCheckpointTriggerStatus with the trigger ID.
+func (c *Client) TriggerCheckpoint(apiBaseURL string, jobId string) (string, error) {
+	url := fmt.Sprintf("%s/jobs/%s/checkpoints", apiBaseURL, jobId)
+	resp, err := c.httpClient.Post(url, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return "", &responseError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	triggerID := &SavepointTriggerID{}
+	if err := parseJson(resp, triggerID); err != nil {
+		return "", err
+	}
+	if triggerID.RequestID == "" {
+		return "", fmt.Errorf("no request-id in the response of POST %v", url)
+	}
+	return triggerID.RequestID, nil
+}
+
+// CheckpointTriggerStatus is the status of a checkpoint triggered by TriggerCheckpoint.
+type CheckpointTriggerStatus struct {
+	// Completed or not, successfully or with a failure.
+	Completed bool
+	// The ID of the checkpoint, non-zero when the checkpoint succeeded.
+	CheckpointID int64
+	// Cause of the failure, non-empty when the checkpoint failed.
+	FailureCause SavepointFailureCause
+}
+
+func (s *CheckpointTriggerStatus) IsSuccessful() bool {
+	return s.Completed && s.FailureCause == SavepointFailureCause{}
+}
+
+// GetCheckpointTriggerStatus returns the status of the checkpoint triggered by
+// TriggerCheckpoint, for Flink 1.17+.
+//
+// Flink API response examples:
+//
+//	{"status": {"id": "IN_PROGRESS"}, "operation": null}
+//	{"status": {"id": "COMPLETED"}, "operation": {"checkpointId": 8}}
+//	{"status": {"id": "COMPLETED"}, "operation": {"failure-cause": {"class": "...", "stack-trace": "..."}}}
+func (c *Client) GetCheckpointTriggerStatus(
+	apiBaseURL string, jobID string, triggerID string) (*CheckpointTriggerStatus, error) {
+	var url = fmt.Sprintf("%s/jobs/%s/checkpoints/%s", apiBaseURL, jobID, triggerID)
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var body struct {
+		Status    SavepointStateID `json:"status"`
+		Operation *struct {
+			CheckpointID int64                  `json:"checkpointId"`
+			FailureCause *SavepointFailureCause `json:"failure-cause"`
+		} `json:"operation"`
+	}
+	if err := parseJson(resp, &body); err != nil {
+		return nil, err
+	}
+	var status = &CheckpointTriggerStatus{Completed: body.Status.ID == savepointStateCompleted}
+	if op := body.Operation; op != nil {
+		status.CheckpointID = op.CheckpointID
+		if op.FailureCause != nil {
+			status.FailureCause = *op.FailureCause
+		}
+	}
+	return status, nil
+}
+
+// GetJobMetrics returns the given metrics of the job, e.g. `numRestarts`.
+func (c *Client) GetJobMetrics(apiBaseURL string, jobId string, metrics ...string) ([]JobMetric, error) {
+	url := fmt.Sprintf("%s/jobs/%s/metrics?get=%s", apiBaseURL, jobId, strings.Join(metrics, ","))
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobMetrics []JobMetric
+	if err := parseJson(resp, &jobMetrics); err != nil {
+		return nil, err
+	}
+
+	return jobMetrics, nil
+}
+
+// GetJobDetails returns the details of the job, e.g. its vertices.
+func (c *Client) GetJobDetails(apiBaseURL string, jobId string) (*JobDetails, error) {
+	url := fmt.Sprintf("%s/jobs/%s", apiBaseURL, jobId)
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &JobDetails{}
+	if err := parseJson(resp, details); err != nil {
+		return nil, err
+	}
+
+	return details, nil
+}
+
+// GetJobPlan returns the dataflow plan of the job.
+func (c *Client) GetJobPlan(apiBaseURL string, jobId string) (*JobPlan, error) {
+	url := fmt.Sprintf("%s/jobs/%s/plan", apiBaseURL, jobId)
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &JobPlan{}
+	if err := parseJson(resp, plan); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// GetJobVertexMetrics returns the given metrics of the vertex aggregated over its subtasks,
+// or the IDs of the available metrics if none is given.
+func (c *Client) GetJobVertexMetrics(apiBaseURL string, jobId string, vertexId string, metrics ...string) ([]AggregatedMetric, error) {
+	url := fmt.Sprintf("%s/jobs/%s/vertices/%s/subtasks/metrics", apiBaseURL, jobId, vertexId)
+	if len(metrics) > 0 {
+		var escaped = make([]string, len(metrics))
+		for i, metric := range metrics {
+			escaped[i] = neturl.QueryEscape(metric)
+		}
+		url += fmt.Sprintf("?get=%s&agg=min,max,avg,sum", strings.Join(escaped, ","))
+	}
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var vertexMetrics []AggregatedMetric
+	if err := parseJson(resp, &vertexMetrics); err != nil {
+		return nil, err
+	}
+
+	return vertexMetrics, nil
+}
+
+// GetJobVertexBackPressure returns the back pressure of the vertex, which the JobManager
+// samples on demand.
+func (c *Client) GetJobVertexBackPressure(apiBaseURL string, jobId string, vertexId string) (*JobVertexBackPressure, error) {
+	url := fmt.Sprintf("%s/jobs/%s/vertices/%s/backpressure", apiBaseURL, jobId, vertexId)
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	backPressure := &JobVertexBackPressure{}
+	if err := parseJson(resp, backPressure); err != nil {
+		return nil, err
+	}
+
+	return backPressure, nil
+}
+
+// GetJars returns the JAR files uploaded to the JobManager.
+func (c *Client) GetJars(apiBaseURL string) (*JarsOverview, error) {
+	url := fmt.Sprintf("%s/jars", apiBaseURL)
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	jars := &JarsOverview{}
+	if err := parseJson(resp, jars); err != nil {
+		return nil, err
+	}
+
+	return jars, nil
+}
+
+// UploadJar uploads a JAR file to the JobManager and returns its ID.
+func (c *Client) UploadJar(apiBaseURL string, fileName string, jar io.Reader) (string, error) {
+	url := fmt.Sprintf("%s/jars/upload", apiBaseURL)
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="jarfile"; filename="%s"`, fileName))
+		header.Set("Content-Type", "application/x-java-archive")
+		part, err := form.CreatePart(header)
+		if err == nil {
+			_, err = io.Copy(part, jar)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+	resp, err := c.httpClient.Post(url, form.FormDataContentType(), body)
+	// Unblock the writer if the request failed before reading the body.
+	body.Close()
+	if err != nil {
+		return "", err
+	}
+
+	uploadResp := &JarUploadResponse{}
+	if err := parseJson(resp, uploadResp); err != nil {
+		return "", err
+	}
+	if uploadResp.Filename == "" {
+		return "", fmt.Errorf("failed to upload JAR file %v: no filename in the response", fileName)
+	}
+
+	return path.Base(uploadResp.Filename), nil
+}
+
+// DeleteJar deletes a JAR file from the JobManager.
+func (c *Client) DeleteJar(apiBaseURL string, jarID string) error {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/jars/%s", apiBaseURL, jarID), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to delete JAR file %v: %v", jarID, resp.Status)
+	}
+
+	return nil
+}
+
+// NewDefaultClient returns a client which reuses the connections of the pool shared by the
+// clients, see ConfigureTransport.
 func NewDefaultClient(log logr.Logger) *Client {
-	return NewClient(log, &http.Client{})
+	return NewClient(log, &http.Client{Transport: getSharedTransport()})
 }
 
 func NewClient(log logr.Logger, httpClient *http.Client) *Client {
@@ -325,3 +887,13 @@ func NewClient(log logr.Logger, httpClient *http.Client) *Client {
 
 	return &Client{log: log, httpClient: httpClient}
 }
+
+// NewClusterClient returns a client of the Flink API of the cluster, whose calls are limited
+// by the rate limiter unless it is nil.
+func NewClusterClient(log logr.Logger, cluster types.NamespacedName, limiter *RateLimiter) *Client {
+	var client = NewDefaultClient(log)
+	var rt = client.httpClient.Transport.(*roundTripper)
+	rt.limiter = limiter
+	rt.cluster = cluster
+	return client
+}
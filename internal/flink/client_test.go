@@ -0,0 +1,52 @@
+package flink
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"gotest.tools/v3/assert"
+)
+
+func TestTriggerCheckpoint(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/jobs/job-1/checkpoints":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"request-id": "a1b2c3"}`))
+		case r.URL.Path == "/jobs/job-1/checkpoints/a1b2c3":
+			w.Write([]byte(`{"status": {"id": "COMPLETED"}, "operation": {"checkpointId": 8}}`))
+		case r.URL.Path == "/jobs/job-1/checkpoints/d4e5f6":
+			w.Write([]byte(`{"status": {"id": "COMPLETED"}, "operation": {"failure-cause": ` +
+				`{"class": "org.apache.flink.runtime.checkpoint.CheckpointException", "stack-trace": "..."}}}`))
+		case r.URL.Path == "/jobs/job-1/checkpoints/g7h8i9":
+			w.Write([]byte(`{"status": {"id": "IN_PROGRESS"}, "operation": null}`))
+		default:
+			// Flink before 1.17 does not serve the trigger of checkpoints.
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+	var client = NewDefaultClient(logr.Discard())
+
+	triggerID, err := client.TriggerCheckpoint(server.URL, "job-1")
+	assert.NilError(t, err)
+	assert.Equal(t, triggerID, "a1b2c3")
+	_, err = client.TriggerCheckpoint(server.URL, "job-2")
+	assert.ErrorContains(t, err, "405 Method Not Allowed")
+
+	status, err := client.GetCheckpointTriggerStatus(server.URL, "job-1", "a1b2c3")
+	assert.NilError(t, err)
+	assert.Assert(t, status.IsSuccessful())
+	assert.Equal(t, status.CheckpointID, int64(8))
+
+	status, err = client.GetCheckpointTriggerStatus(server.URL, "job-1", "d4e5f6")
+	assert.NilError(t, err)
+	assert.Assert(t, status.Completed && !status.IsSuccessful())
+	assert.Equal(t, status.FailureCause.ExceptionClass, "org.apache.flink.runtime.checkpoint.CheckpointException")
+
+	status, err = client.GetCheckpointTriggerStatus(server.URL, "job-1", "g7h8i9")
+	assert.NilError(t, err)
+	assert.Assert(t, !status.Completed && !status.IsSuccessful())
+}
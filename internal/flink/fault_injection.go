@@ -0,0 +1,36 @@
+//go:build faultinjection
+
+package flink
+
+import (
+	"net/http"
+	"time"
+)
+
+// faultInjection holds the faults injected into the calls of a client, so that end-to-end
+// tests can exercise the failure paths of the operator deterministically.
+type faultInjection struct {
+	// Delay of the responses, none if zero.
+	responseDelay time.Duration
+}
+
+// SetResponseDelay delays the responses of the Flink API by the duration, e.g. to exercise
+// the timeouts of slow JobManagers. Zero disables the delay.
+func (c *Client) SetResponseDelay(delay time.Duration) {
+	c.httpClient.Transport.(*roundTripper).faults.responseDelay = delay
+}
+
+// Waits for the response delay before the request is sent, unless the request is canceled.
+func (rt *roundTripper) injectFaults(req *http.Request) error {
+	if rt.faults.responseDelay <= 0 {
+		return nil
+	}
+	var timer = time.NewTimer(rt.faults.responseDelay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !faultinjection
+
+package flink
+
+import "net/http"
+
+// No faults are injected unless built with the faultinjection build tag.
+type faultInjection struct{}
+
+func (rt *roundTripper) injectFaults(req *http.Request) error {
+	return nil
+}
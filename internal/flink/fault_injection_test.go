@@ -0,0 +1,42 @@
+//go:build faultinjection
+
+package flink
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestResponseDelay(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"flink-version":"1.16.1"}`))
+	}))
+	defer server.Close()
+	var client = NewClusterClient(logr.Discard(), types.NamespacedName{Namespace: "default", Name: "delayed"}, nil)
+
+	client.SetResponseDelay(200 * time.Millisecond)
+	var start = time.Now()
+	config, err := client.GetConfig(server.URL)
+	assert.NilError(t, err)
+	assert.Equal(t, config.FlinkVersion, "1.16.1")
+	assert.Assert(t, time.Since(start) >= 200*time.Millisecond)
+
+	// The delay ends with the request.
+	client.SetResponseDelay(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	_, err = client.httpClient.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	client.SetResponseDelay(0)
+	_, err = client.GetConfig(server.URL)
+	assert.NilError(t, err)
+}
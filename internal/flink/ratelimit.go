@@ -0,0 +1,101 @@
+package flink
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// The Flink API calls delayed by the rate limiter, served on the metrics endpoint of the operator.
+var (
+	throttledRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "flink_operator_flink_api_throttled_requests_total",
+		Help: "The number of Flink API calls to the FlinkCluster delayed by the rate limit of the operator.",
+	}, []string{"namespace", "cluster"})
+	throttledSeconds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "flink_operator_flink_api_throttled_seconds_total",
+		Help: "The time the Flink API calls to the FlinkCluster were delayed by the rate limit of the operator.",
+	}, []string{"namespace", "cluster"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(throttledRequests, throttledSeconds)
+}
+
+// RateLimiter limits the rate of the Flink API calls with a token bucket per cluster, shared by
+// the clients of all the reconciliations of the cluster.
+type RateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu       sync.Mutex
+	limiters map[types.NamespacedName]*rate.Limiter
+}
+
+// NewRateLimiter returns a rate limiter which allows qps calls per second to each cluster, in
+// bursts of up to burst calls. It returns nil, which does not limit the calls, if qps is not
+// positive.
+func NewRateLimiter(qps float64, burst int) *RateLimiter {
+	if qps <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		limit:    rate.Limit(qps),
+		burst:    burst,
+		limiters: map[types.NamespacedName]*rate.Limiter{},
+	}
+}
+
+func (l *RateLimiter) getLimiter(cluster types.NamespacedName) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var limiter = l.limiters[cluster]
+	if limiter == nil {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[cluster] = limiter
+	}
+	return limiter
+}
+
+// wait blocks until a call to the cluster is allowed, or the context is done.
+func (l *RateLimiter) wait(ctx context.Context, cluster types.NamespacedName) error {
+	var reservation = l.getLimiter(cluster).Reserve()
+	var delay = reservation.Delay()
+	if delay == 0 {
+		return nil
+	}
+
+	var labels = prometheus.Labels{"namespace": cluster.Namespace, "cluster": cluster.Name}
+	throttledRequests.With(labels).Inc()
+	throttledSeconds.With(labels).Add(delay.Seconds())
+	var timer = time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		reservation.Cancel()
+		return ctx.Err()
+	}
+}
+
+// Forget removes the token bucket and the metrics of the deleted cluster.
+func (l *RateLimiter) Forget(cluster types.NamespacedName) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	delete(l.limiters, cluster)
+	l.mu.Unlock()
+	var labels = prometheus.Labels{"namespace": cluster.Namespace, "cluster": cluster.Name}
+	throttledRequests.Delete(labels)
+	throttledSeconds.Delete(labels)
+}
@@ -0,0 +1,65 @@
+package flink
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	assert.Assert(t, NewRateLimiter(0, 10) == nil)
+	var limiter = NewRateLimiter(5, 0)
+	assert.Equal(t, limiter.burst, 1)
+
+	// The nil rate limiter has nothing to forget.
+	var nilLimiter *RateLimiter
+	nilLimiter.Forget(types.NamespacedName{Namespace: "default", Name: "cluster"})
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	var cluster = types.NamespacedName{Namespace: "default", Name: "limited"}
+	var other = types.NamespacedName{Namespace: "default", Name: "other"}
+	var limiter = NewRateLimiter(0.001, 2)
+	var ctx = context.Background()
+
+	// The burst is allowed without delay.
+	assert.NilError(t, limiter.wait(ctx, cluster))
+	assert.NilError(t, limiter.wait(ctx, cluster))
+	assert.Equal(t, testutil.ToFloat64(throttledRequests.WithLabelValues("default", "limited")), float64(0))
+
+	// The token buckets are separate per cluster.
+	assert.NilError(t, limiter.wait(ctx, other))
+
+	// Further calls wait for a token until the context is done.
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.wait(ctx, cluster), context.DeadlineExceeded)
+	assert.Equal(t, testutil.ToFloat64(throttledRequests.WithLabelValues("default", "limited")), float64(1))
+	assert.Assert(t, testutil.ToFloat64(throttledSeconds.WithLabelValues("default", "limited")) > 0)
+
+	limiter.Forget(cluster)
+	assert.Equal(t, len(limiter.limiters), 1)
+	assert.Equal(t, testutil.CollectAndCount(throttledRequests), 0)
+}
+
+func TestClusterClientRateLimit(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jobs": []}`))
+	}))
+	defer server.Close()
+
+	var cluster = types.NamespacedName{Namespace: "default", Name: "client"}
+	var client = NewClusterClient(logr.Discard(), cluster, NewRateLimiter(1000, 1))
+	for i := 0; i < 3; i++ {
+		_, err := client.GetJobsOverview(server.URL)
+		assert.NilError(t, err)
+	}
+	assert.Assert(t, testutil.ToFloat64(throttledRequests.WithLabelValues("default", "client")) >= 1)
+}
@@ -0,0 +1,150 @@
+package flink
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/http2"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// The connections of the Flink API clients, served on the metrics endpoint of the operator.
+var (
+	openedConnections = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "flink_operator_flink_api_connections_opened_total",
+		Help: "The number of connections opened to the Flink API of the clusters.",
+	})
+	openConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "flink_operator_flink_api_open_connections",
+		Help: "The number of open connections to the Flink API of the clusters, idle or in use.",
+	})
+	requestConnections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "flink_operator_flink_api_requests_total",
+		Help: "The number of Flink API calls by whether their connection was reused from the pool.",
+	}, []string{"connection"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(openedConnections, openConnections, requestConnections)
+}
+
+// TransportOptions defines the connection pool shared by the Flink API clients.
+type TransportOptions struct {
+	// The maximum number of idle connections kept to each JobManager.
+	MaxIdleConnsPerHost int
+	// The time after which idle connections are closed.
+	IdleConnTimeout time.Duration
+	// Whether to call the Flink API with HTTP/2 over cleartext (h2c). The REST endpoint of the
+	// JobManager must support it.
+	H2C bool
+}
+
+// DefaultTransportOptions are the options of the connection pool unless configured otherwise.
+var DefaultTransportOptions = TransportOptions{
+	MaxIdleConnsPerHost: 4,
+	IdleConnTimeout:     90 * time.Second,
+}
+
+var (
+	transportMu     sync.Mutex
+	sharedTransport http.RoundTripper
+)
+
+// ConfigureTransport replaces the connection pool shared by the Flink API clients created
+// afterwards.
+func ConfigureTransport(opts TransportOptions) {
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	sharedTransport = newTransport(opts)
+}
+
+func getSharedTransport() http.RoundTripper {
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	if sharedTransport == nil {
+		sharedTransport = newTransport(DefaultTransportOptions)
+	}
+	return sharedTransport
+}
+
+func newTransport(opts TransportOptions) http.RoundTripper {
+	var dialer = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	var dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		openedConnections.Inc()
+		openConnections.Inc()
+		return &countedConn{Conn: conn}, nil
+	}
+
+	if opts.H2C {
+		// HTTP/2 multiplexes the calls to a JobManager over a single connection.
+		return &http2.Transport{
+			AllowHTTP:       true,
+			ReadIdleTimeout: opts.IdleConnTimeout,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dial(ctx, network, addr)
+			},
+		}
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dial,
+		MaxIdleConnsPerHost:   opts.MaxIdleConnsPerHost,
+		IdleConnTimeout:       opts.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// countedConn decrements the open connections once closed.
+type countedConn struct {
+	net.Conn
+	once sync.Once
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(openConnections.Dec)
+	return c.Conn.Close()
+}
+
+// withConnectionTrace counts the request by whether its connection is reused from the pool.
+func withConnectionTrace(req *http.Request) *http.Request {
+	var trace = &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				requestConnections.WithLabelValues("reused").Inc()
+			} else {
+				requestConnections.WithLabelValues("new").Inc()
+			}
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// IsConnectionError returns true if the error of a Flink API call is a failure to connect to
+// the JobManager, e.g. a refused connection or a timeout when a NetworkPolicy drops the
+// packets, rather than an error response of the JobManager.
+func IsConnectionError(err error) bool {
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	var urlErr *url.Error
+	var respErr *responseError
+	switch {
+	case err == nil || errors.As(err, &respErr):
+		return false
+	case errors.As(err, &opErr) || errors.As(err, &dnsErr):
+		return true
+	}
+	return errors.As(err, &urlErr) && urlErr.Timeout()
+}
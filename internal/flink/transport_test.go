@@ -0,0 +1,66 @@
+package flink
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"gotest.tools/v3/assert"
+)
+
+func TestSharedTransportReusesConnections(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jobs": []}`))
+	}))
+	defer server.Close()
+
+	ConfigureTransport(DefaultTransportOptions)
+	var opened = testutil.ToFloat64(openedConnections)
+	var reused = testutil.ToFloat64(requestConnections.WithLabelValues("reused"))
+
+	// Clients of successive reconciliations share the pool.
+	for i := 0; i < 3; i++ {
+		_, err := NewDefaultClient(logr.Discard()).GetJobsOverview(server.URL)
+		assert.NilError(t, err)
+	}
+	assert.Equal(t, testutil.ToFloat64(openedConnections)-opened, float64(1))
+	assert.Equal(t, testutil.ToFloat64(requestConnections.WithLabelValues("reused"))-reused, float64(2))
+}
+
+func TestSharedTransportH2C(t *testing.T) {
+	var protocols []string
+	var handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protocols = append(protocols, r.Proto)
+		w.Write([]byte(`{"jobs": []}`))
+	})
+	var server = httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	defer server.Close()
+
+	ConfigureTransport(TransportOptions{H2C: true})
+	defer ConfigureTransport(DefaultTransportOptions)
+	for i := 0; i < 2; i++ {
+		_, err := NewDefaultClient(logr.Discard()).GetJobsOverview(server.URL)
+		assert.NilError(t, err)
+	}
+	assert.DeepEqual(t, protocols, []string{"HTTP/2.0", "HTTP/2.0"})
+}
+
+func TestIsConnectionError(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	var client = NewDefaultClient(logr.Discard())
+	_, err := client.GetJobsOverview(server.URL)
+	assert.Assert(t, err != nil)
+	assert.Assert(t, !IsConnectionError(err))
+
+	// The JobManager is not listening anymore.
+	server.Close()
+	_, err = client.GetJobsOverview(server.URL)
+	assert.Assert(t, IsConnectionError(err))
+	assert.Assert(t, !IsConnectionError(nil))
+}
@@ -0,0 +1,108 @@
+package kafka
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LagCollector collects the lag of consumer groups in the background, so that its callers
+// do not wait for the brokers. A lag is collected again once the last collection is older
+// than the refresh interval, and is no longer returned once it is older than the maximum
+// age, e.g. while the brokers cannot be reached.
+type LagCollector struct {
+	refreshInterval time.Duration
+	maxAge          time.Duration
+	timeout         time.Duration
+	collect         func(ctx context.Context, config Config, group string, topics []string) (int64, error)
+	now             func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*lagEntry
+}
+
+type lagEntry struct {
+	// The group and topics the lag was collected for, the entry is reset when they change.
+	query string
+	// The config of the next collection, updated by every call so that rotated credentials
+	// are picked up.
+	config Config
+	lag    int64
+	// The time the lag was collected at, and the time of the last collection.
+	collectTime time.Time
+	attemptTime time.Time
+	err         error
+	collecting  bool
+}
+
+// NewLagCollector returns a collector which collects the lags every refresh interval and
+// returns them until they are older than the maximum age. The collections time out after
+// the timeout.
+func NewLagCollector(refreshInterval, maxAge, timeout time.Duration) *LagCollector {
+	return &LagCollector{
+		refreshInterval: refreshInterval,
+		maxAge:          maxAge,
+		timeout:         timeout,
+		collect:         ConsumerGroupLag,
+		now:             time.Now,
+		entries:         map[string]*lagEntry{},
+	}
+}
+
+// Lag returns the last lag of the group collected for the key, and starts collecting it in
+// the background if the last collection is older than the refresh interval. ok is false if
+// no lag younger than the maximum age was collected yet, err is the error of the last
+// collection if it failed. A nil collector collects nothing.
+func (c *LagCollector) Lag(key string, config Config, group string, topics []string) (lag int64, ok bool, err error) {
+	if c == nil {
+		return 0, false, nil
+	}
+	var query = group + "/" + strings.Join(topics, ",")
+	var now = c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var entry = c.entries[key]
+	if entry == nil || entry.query != query {
+		entry = &lagEntry{query: query}
+		c.entries[key] = entry
+	}
+	entry.config = config
+	if !entry.collecting && now.Sub(entry.attemptTime) >= c.refreshInterval {
+		entry.collecting = true
+		entry.attemptTime = now
+		go c.refresh(entry, group, topics)
+	}
+	if entry.collectTime.IsZero() || now.Sub(entry.collectTime) > c.maxAge {
+		return 0, false, entry.err
+	}
+	return entry.lag, true, entry.err
+}
+
+// Forget removes the lag of the key, e.g. once its cluster is deleted.
+func (c *LagCollector) Forget(key string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+func (c *LagCollector) refresh(entry *lagEntry, group string, topics []string) {
+	c.mu.Lock()
+	var config = entry.config
+	c.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	lag, err := c.collect(ctx, config, group, topics)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.collecting = false
+	entry.err = err
+	if err == nil {
+		entry.lag = lag
+		entry.collectTime = c.now()
+	}
+}
@@ -0,0 +1,76 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLagCollector(t *testing.T) {
+	var now = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	var results = make(chan error)
+	var collector = NewLagCollector(30*time.Second, 2*time.Minute, time.Second)
+	collector.now = func() time.Time { return now }
+	var lag int64
+	collector.collect = func(ctx context.Context, config Config, group string, topics []string) (int64, error) {
+		var err = <-results
+		lag += 100
+		return lag, err
+	}
+	// Waits until the collection started by the last call completes.
+	var complete = func(err error) {
+		results <- err
+		for {
+			collector.mu.Lock()
+			var collecting = collector.entries["default/orders"].collecting
+			collector.mu.Unlock()
+			if !collecting {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	var config = Config{BootstrapServers: "kafka:9092"}
+
+	// The first call starts the collection without waiting for it.
+	_, ok, err := collector.Lag("default/orders", config, "orders", nil)
+	assert.Assert(t, !ok)
+	assert.NilError(t, err)
+	complete(nil)
+	value, ok, err := collector.Lag("default/orders", config, "orders", nil)
+	assert.Assert(t, ok)
+	assert.NilError(t, err)
+	assert.Equal(t, value, int64(100))
+
+	// The lag is collected again after the refresh interval, the last lag is returned until then.
+	now = now.Add(30 * time.Second)
+	value, ok, _ = collector.Lag("default/orders", config, "orders", nil)
+	assert.Assert(t, ok)
+	assert.Equal(t, value, int64(100))
+	complete(errors.New("connection refused"))
+	value, ok, err = collector.Lag("default/orders", config, "orders", nil)
+	assert.Assert(t, ok)
+	assert.Equal(t, value, int64(100))
+	assert.Error(t, err, "connection refused")
+
+	// The lag is no longer returned once it is older than the maximum age.
+	now = now.Add(91 * time.Second)
+	_, ok, _ = collector.Lag("default/orders", config, "orders", nil)
+	assert.Assert(t, !ok)
+	complete(errors.New("connection refused"))
+
+	// The lag of another group is collected anew.
+	_, ok, _ = collector.Lag("default/orders", config, "orders-v2", nil)
+	assert.Assert(t, !ok)
+	complete(nil)
+	value, ok, err = collector.Lag("default/orders", config, "orders-v2", nil)
+	assert.Assert(t, ok)
+	assert.NilError(t, err)
+	assert.Equal(t, value, int64(400))
+
+	collector.Forget("default/orders")
+	assert.Equal(t, len(collector.entries), 0)
+}
@@ -0,0 +1,265 @@
+// Package kafka implements the few requests of the Kafka protocol the operator needs, to
+// check whether topics exist and to get the lag of consumer groups, without depending on a
+// full Kafka client.
+package kafka
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	apiKeyMetadata = 3
+	// Metadata v4, supported since Kafka 1.0, is the first version which does not create
+	// missing topics when the brokers enable `auto.create.topics.enable`.
+	metadataVersion = 4
+	clientID        = "flink-operator"
+
+	errorCodeNone                    = 0
+	errorCodeUnknownTopicOrPartition = 3
+
+	// The maximum size of a metadata response, far larger than the response for a single topic.
+	maxResponseSize = 16 << 20
+)
+
+const defaultTimeout = 10 * time.Second
+
+// TopicExists returns whether the topic exists, asking the comma separated `host:port`
+// bootstrap servers in turn until one of them responds.
+func TopicExists(ctx context.Context, bootstrapServers string, topic string) (bool, error) {
+	var errs []string
+	for _, server := range strings.Split(bootstrapServers, ",") {
+		server = strings.TrimSpace(server)
+		if server == "" {
+			continue
+		}
+		exists, err := topicExists(ctx, server, topic)
+		if err == nil {
+			return exists, nil
+		}
+		errs = append(errs, fmt.Sprintf("%v: %v", server, err))
+	}
+	if len(errs) == 0 {
+		return false, fmt.Errorf("no bootstrap servers")
+	}
+	return false, fmt.Errorf("failed to get metadata of topic %v: %v", topic, strings.Join(errs, "; "))
+}
+
+func topicExists(ctx context.Context, server string, topic string) (bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultTimeout)
+		defer cancel()
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	const correlationID = 1
+	if _, err := conn.Write(newMetadataRequest(correlationID, topic)); err != nil {
+		return false, err
+	}
+	var reader = bufio.NewReader(conn)
+	var size int32
+	if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+		return false, err
+	}
+	if size < 4 || size > maxResponseSize {
+		return false, fmt.Errorf("invalid metadata response size %v", size)
+	}
+	var response = make([]byte, size)
+	if _, err := io.ReadFull(reader, response); err != nil {
+		return false, err
+	}
+	return parseMetadataResponse(response, correlationID, topic)
+}
+
+// newMetadataRequest returns the metadata request of the topic, prefixed with its size.
+func newMetadataRequest(correlationID int32, topic string) []byte {
+	var w writer
+	w.int16(apiKeyMetadata)
+	w.int16(metadataVersion)
+	w.int32(correlationID)
+	w.string(clientID)
+	// topics
+	w.int32(1)
+	w.string(topic)
+	// allow_auto_topic_creation
+	w.int8(0)
+
+	var request = make([]byte, 4, 4+len(w.buf))
+	binary.BigEndian.PutUint32(request, uint32(len(w.buf)))
+	return append(request, w.buf...)
+}
+
+// parseMetadataResponse returns whether the topic exists according to the metadata
+// response, without its size prefix.
+func parseMetadataResponse(response []byte, correlationID int32, topic string) (bool, error) {
+	var r = reader{buf: response}
+	if id := r.int32(); r.err == nil && id != correlationID {
+		return false, fmt.Errorf("unexpected correlation ID %v of metadata response", id)
+	}
+	// throttle_time_ms
+	r.int32()
+	// brokers: node_id, host, port, rack
+	for i := r.array(); i > 0 && r.err == nil; i-- {
+		r.int32()
+		r.string()
+		r.int32()
+		r.string()
+	}
+	// cluster_id, controller_id
+	r.string()
+	r.int32()
+	for i := r.array(); i > 0 && r.err == nil; i-- {
+		var errorCode = r.int16()
+		var name = r.string()
+		// is_internal
+		r.int8()
+		// partitions: error_code, partition_index, leader_id, replica_nodes, isr_nodes
+		for j := r.array(); j > 0 && r.err == nil; j-- {
+			r.int16()
+			r.int32()
+			r.int32()
+			r.skip(4 * r.array())
+			r.skip(4 * r.array())
+		}
+		if r.err != nil || name != topic {
+			continue
+		}
+		switch errorCode {
+		case errorCodeNone:
+			return true, nil
+		case errorCodeUnknownTopicOrPartition:
+			return false, nil
+		default:
+			return false, fmt.Errorf("metadata of topic %v has error code %v", topic, errorCode)
+		}
+	}
+	if r.err != nil {
+		return false, fmt.Errorf("invalid metadata response: %v", r.err)
+	}
+	return false, fmt.Errorf("topic %v not found in metadata response", topic)
+}
+
+type writer struct {
+	buf []byte
+}
+
+func (w *writer) int8(v int8) {
+	w.buf = append(w.buf, byte(v))
+}
+
+func (w *writer) int16(v int16) {
+	w.buf = binary.BigEndian.AppendUint16(w.buf, uint16(v))
+}
+
+func (w *writer) int32(v int32) {
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(v))
+}
+
+func (w *writer) int64(v int64) {
+	w.buf = binary.BigEndian.AppendUint64(w.buf, uint64(v))
+}
+
+func (w *writer) string(v string) {
+	w.int16(int16(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+func (w *writer) bytes(v []byte) {
+	w.int32(int32(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// reader reads the primitive types of the Kafka protocol, it records the first error
+// and returns zero values after it.
+type reader struct {
+	buf []byte
+	err error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.buf) {
+		r.err = errors.New("unexpected end of data")
+		return nil
+	}
+	var b = r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *reader) skip(n int) {
+	r.next(n)
+}
+
+func (r *reader) int8() int8 {
+	if b := r.next(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (r *reader) int16() int16 {
+	if b := r.next(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *reader) int32() int32 {
+	if b := r.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r *reader) int64() int64 {
+	if b := r.next(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+// string reads a string or a nullable string, null is returned as the empty string.
+func (r *reader) string() string {
+	var n = r.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(r.next(int(n)))
+}
+
+// bytes reads a byte array or a nullable byte array, null is returned as nil.
+func (r *reader) bytes() []byte {
+	var n = r.int32()
+	if n < 0 {
+		return nil
+	}
+	return r.next(int(n))
+}
+
+// array reads the length of an array, 0 for a null array.
+func (r *reader) array() int {
+	var n = r.int32()
+	if n < 0 {
+		return 0
+	}
+	return int(n)
+}
@@ -0,0 +1,135 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+type topicMetadata struct {
+	name      string
+	errorCode int16
+}
+
+func newMetadataResponse(correlationID int32, topics ...topicMetadata) []byte {
+	var w writer
+	w.int32(correlationID)
+	// throttle_time_ms
+	w.int32(0)
+	// brokers
+	w.int32(1)
+	w.int32(0)
+	w.string("kafka-0.kafka")
+	w.int32(9092)
+	w.int16(-1)
+	// cluster_id, controller_id
+	w.string("cluster")
+	w.int32(0)
+	w.int32(int32(len(topics)))
+	for _, topic := range topics {
+		w.int16(topic.errorCode)
+		w.string(topic.name)
+		w.int8(0)
+		var partitions int32
+		if topic.errorCode == errorCodeNone {
+			partitions = 1
+		}
+		w.int32(partitions)
+		for i := int32(0); i < partitions; i++ {
+			w.int16(0)
+			w.int32(i)
+			w.int32(0)
+			w.int32(1)
+			w.int32(0)
+			w.int32(1)
+			w.int32(0)
+		}
+	}
+	return w.buf
+}
+
+// serveMetadata starts a broker which responds to a metadata request with the topics.
+func serveMetadata(t *testing.T, topics ...topicMetadata) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var size int32
+		if binary.Read(conn, binary.BigEndian, &size) != nil {
+			return
+		}
+		var request = make([]byte, size)
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return
+		}
+		var response = newMetadataResponse(int32(binary.BigEndian.Uint32(request[4:8])), topics...)
+		binary.Write(conn, binary.BigEndian, int32(len(response)))
+		conn.Write(response)
+	}()
+	return listener.Addr().String()
+}
+
+func TestNewMetadataRequest(t *testing.T) {
+	var request = newMetadataRequest(7, "orders")
+	var r = reader{buf: request}
+	assert.Equal(t, int(r.int32()), len(request)-4)
+	assert.Equal(t, r.int16(), int16(apiKeyMetadata))
+	assert.Equal(t, r.int16(), int16(metadataVersion))
+	assert.Equal(t, r.int32(), int32(7))
+	assert.Equal(t, r.string(), clientID)
+	assert.Equal(t, r.array(), 1)
+	assert.Equal(t, r.string(), "orders")
+	assert.Equal(t, r.int8(), int8(0))
+	assert.NilError(t, r.err)
+	assert.Equal(t, len(r.buf), 0)
+}
+
+func TestParseMetadataResponse(t *testing.T) {
+	var exists, err = parseMetadataResponse(newMetadataResponse(1, topicMetadata{name: "orders"}), 1, "orders")
+	assert.NilError(t, err)
+	assert.Assert(t, exists)
+
+	exists, err = parseMetadataResponse(
+		newMetadataResponse(1, topicMetadata{name: "orders", errorCode: errorCodeUnknownTopicOrPartition}), 1, "orders")
+	assert.NilError(t, err)
+	assert.Assert(t, !exists)
+
+	_, err = parseMetadataResponse(newMetadataResponse(1, topicMetadata{name: "orders", errorCode: 5}), 1, "orders")
+	assert.Error(t, err, "metadata of topic orders has error code 5")
+
+	_, err = parseMetadataResponse(newMetadataResponse(2, topicMetadata{name: "orders"}), 1, "orders")
+	assert.Error(t, err, "unexpected correlation ID 2 of metadata response")
+
+	var truncated = newMetadataResponse(1, topicMetadata{name: "orders"})
+	_, err = parseMetadataResponse(truncated[:len(truncated)-10], 1, "orders")
+	assert.Error(t, err, "invalid metadata response: unexpected end of data")
+}
+
+func TestTopicExists(t *testing.T) {
+	var exists, err = TopicExists(context.Background(), serveMetadata(t, topicMetadata{name: "orders"}), "orders")
+	assert.NilError(t, err)
+	assert.Assert(t, exists)
+
+	// The next bootstrap server is asked if one is not reachable.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	var unreachable = listener.Addr().String()
+	listener.Close()
+	var servers = unreachable + "," +
+		serveMetadata(t, topicMetadata{name: "orders", errorCode: errorCodeUnknownTopicOrPartition})
+	exists, err = TopicExists(context.Background(), servers, "orders")
+	assert.NilError(t, err)
+	assert.Assert(t, !exists)
+
+	_, err = TopicExists(context.Background(), unreachable, "orders")
+	assert.ErrorContains(t, err, "failed to get metadata of topic orders: "+unreachable+": ")
+}
@@ -0,0 +1,506 @@
+package kafka
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+)
+
+const (
+	apiKeyListOffsets      = 2
+	apiKeyOffsetFetch      = 9
+	apiKeyFindCoordinator  = 10
+	apiKeySaslHandshake    = 17
+	apiKeySaslAuthenticate = 36
+
+	// ListOffsets v1 returns a single offset per partition, supported since Kafka 0.10.1.
+	listOffsetsVersion = 1
+	// OffsetFetch v2 is the first version which returns all the committed offsets of a
+	// group for a null topic array.
+	offsetFetchVersion     = 2
+	findCoordinatorVersion = 0
+	// SaslHandshake v1 is followed by SaslAuthenticate requests instead of raw SASL tokens.
+	saslHandshakeVersion    = 1
+	saslAuthenticateVersion = 0
+
+	// The timestamp of ListOffsets which requests the offset of the next message.
+	latestTimestamp = -1
+	// The committed offset of a partition without offset.
+	noOffset = -1
+)
+
+// Config defines how the brokers are connected to.
+type Config struct {
+	// Comma separated `host:port` addresses of the brokers to bootstrap from.
+	BootstrapServers string
+	// TLS configuration of the connections, plaintext if nil.
+	TLS *tls.Config
+	// Credentials of the SASL authentication, no authentication if Username is empty.
+	Username string
+	Password string
+	// The SASL mechanism of the authentication, SASLMechanismPlain if empty.
+	Mechanism string
+}
+
+// ConsumerGroupLag returns the sum of the lags of the partitions the consumer group
+// committed offsets for, the difference between the end offset and the committed offset of
+// each partition. Only the partitions of the given topics are counted unless topics is empty.
+func ConsumerGroupLag(ctx context.Context, config Config, group string, topics []string) (int64, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultTimeout)
+		defer cancel()
+	}
+
+	bootstrap, err := connectBootstrapServer(ctx, config)
+	if err != nil {
+		return 0, err
+	}
+	defer bootstrap.Close()
+
+	response, err := bootstrap.request(apiKeyFindCoordinator, findCoordinatorVersion, newFindCoordinatorRequest(group))
+	if err != nil {
+		return 0, err
+	}
+	coordinatorAddress, err := parseFindCoordinatorResponse(response)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find the coordinator of group %v: %v", group, err)
+	}
+	coordinator, err := connect(ctx, config, coordinatorAddress)
+	if err != nil {
+		return 0, err
+	}
+	response, err = coordinator.request(apiKeyOffsetFetch, offsetFetchVersion, newOffsetFetchRequest(group))
+	coordinator.Close()
+	if err != nil {
+		return 0, err
+	}
+	committed, err := parseOffsetFetchResponse(response)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the offsets of group %v: %v", group, err)
+	}
+	if len(topics) > 0 {
+		var selected = map[string]bool{}
+		for _, topic := range topics {
+			selected[topic] = true
+		}
+		for partition := range committed {
+			if !selected[partition.topic] {
+				delete(committed, partition)
+			}
+		}
+	}
+	if len(committed) == 0 {
+		return 0, nil
+	}
+
+	var committedTopics []string
+	for partition := range committed {
+		committedTopics = append(committedTopics, partition.topic)
+	}
+	response, err = bootstrap.request(apiKeyMetadata, metadataVersion, newTopicsMetadataRequest(committedTopics))
+	if err != nil {
+		return 0, err
+	}
+	brokers, leaders, err := parsePartitionLeaders(response)
+	if err != nil {
+		return 0, fmt.Errorf("invalid metadata response: %v", err)
+	}
+
+	var partitionsByLeader = map[int32][]topicPartition{}
+	for partition := range committed {
+		leader, ok := leaders[partition]
+		if !ok {
+			return 0, fmt.Errorf("no leader of partition %v", partition)
+		}
+		partitionsByLeader[leader] = append(partitionsByLeader[leader], partition)
+	}
+	var lag int64
+	for leader, partitions := range partitionsByLeader {
+		address, ok := brokers[leader]
+		if !ok {
+			return 0, fmt.Errorf("unknown broker %v", leader)
+		}
+		endOffsets, err := listEndOffsets(ctx, config, address, partitions)
+		if err != nil {
+			return 0, err
+		}
+		for _, partition := range partitions {
+			endOffset, ok := endOffsets[partition]
+			if !ok {
+				return 0, fmt.Errorf("no end offset of partition %v", partition)
+			}
+			if partitionLag := endOffset - committed[partition]; partitionLag > 0 {
+				lag += partitionLag
+			}
+		}
+	}
+	return lag, nil
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+func (p topicPartition) String() string {
+	return fmt.Sprintf("%v-%v", p.topic, p.partition)
+}
+
+func listEndOffsets(ctx context.Context, config Config, address string, partitions []topicPartition) (map[topicPartition]int64, error) {
+	conn, err := connect(ctx, config, address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	response, err := conn.request(apiKeyListOffsets, listOffsetsVersion, newListOffsetsRequest(partitions))
+	if err != nil {
+		return nil, err
+	}
+	offsets, err := parseListOffsetsResponse(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the offsets of broker %v: %v", address, err)
+	}
+	return offsets, nil
+}
+
+// brokerConn is a connection to a broker, authenticated if the config has credentials.
+type brokerConn struct {
+	net.Conn
+	reader        *bufio.Reader
+	correlationID int32
+}
+
+// connectBootstrapServer connects to the bootstrap servers in turn until one of them accepts.
+func connectBootstrapServer(ctx context.Context, config Config) (*brokerConn, error) {
+	var errs []string
+	for _, server := range strings.Split(config.BootstrapServers, ",") {
+		server = strings.TrimSpace(server)
+		if server == "" {
+			continue
+		}
+		conn, err := connect(ctx, config, server)
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, fmt.Sprintf("%v: %v", server, err))
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no bootstrap servers")
+	}
+	return nil, fmt.Errorf("failed to connect to bootstrap servers: %v", strings.Join(errs, "; "))
+}
+
+func connect(ctx context.Context, config Config, address string) (*brokerConn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if config.TLS != nil {
+		var tlsConfig = config.TLS.Clone()
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName, _, _ = net.SplitHostPort(address)
+		}
+		conn = tls.Client(conn, tlsConfig)
+	}
+	var broker = &brokerConn{Conn: conn, reader: bufio.NewReader(conn)}
+	if config.Username != "" {
+		if err := broker.authenticate(config); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("SASL authentication failed: %v", err)
+		}
+	}
+	return broker, nil
+}
+
+// authenticate authenticates the connection with the SASL mechanism of the config, PLAIN or
+// SCRAM-SHA-256 and SCRAM-SHA-512.
+func (c *brokerConn) authenticate(config Config) error {
+	var mechanism = config.Mechanism
+	if mechanism == "" {
+		mechanism = SASLMechanismPlain
+	}
+	var scram *scramClient
+	if mechanism != SASLMechanismPlain {
+		var err error
+		if scram, err = newSCRAMClient(mechanism, config.Username, config.Password); err != nil {
+			return err
+		}
+	}
+
+	var w writer
+	w.string(mechanism)
+	response, err := c.request(apiKeySaslHandshake, saslHandshakeVersion, w.buf)
+	if err != nil {
+		return err
+	}
+	var r = reader{buf: response}
+	if errorCode := r.int16(); r.err == nil && errorCode != errorCodeNone {
+		var mechanisms []string
+		for i := r.array(); i > 0 && r.err == nil; i-- {
+			mechanisms = append(mechanisms, r.string())
+		}
+		return fmt.Errorf("mechanism %v is not enabled, enabled mechanisms: %v",
+			mechanism, strings.Join(mechanisms, ", "))
+	}
+
+	if scram == nil {
+		_, err = c.saslAuthenticate([]byte("\x00" + config.Username + "\x00" + config.Password))
+		return err
+	}
+	serverFirst, err := c.saslAuthenticate([]byte(scram.first()))
+	if err != nil {
+		return err
+	}
+	clientFinal, err := scram.final(string(serverFirst))
+	if err != nil {
+		return err
+	}
+	serverFinal, err := c.saslAuthenticate([]byte(clientFinal))
+	if err != nil {
+		return err
+	}
+	return scram.verify(string(serverFinal))
+}
+
+// saslAuthenticate sends the SASL token and returns the token of the broker.
+func (c *brokerConn) saslAuthenticate(token []byte) ([]byte, error) {
+	var w writer
+	w.bytes(token)
+	response, err := c.request(apiKeySaslAuthenticate, saslAuthenticateVersion, w.buf)
+	if err != nil {
+		return nil, err
+	}
+	var r = reader{buf: response}
+	var errorCode = r.int16()
+	var message = r.string()
+	var authBytes = r.bytes()
+	if r.err != nil {
+		return nil, fmt.Errorf("invalid SASL authenticate response: %v", r.err)
+	}
+	if errorCode != errorCodeNone {
+		return nil, fmt.Errorf("error code %v: %v", errorCode, message)
+	}
+	return authBytes, nil
+}
+
+// request sends a request with the body and returns the body of its response.
+func (c *brokerConn) request(apiKey int16, version int16, body []byte) ([]byte, error) {
+	c.correlationID++
+	var w writer
+	w.int16(apiKey)
+	w.int16(version)
+	w.int32(c.correlationID)
+	w.string(clientID)
+	w.buf = append(w.buf, body...)
+	var request = make([]byte, 4, 4+len(w.buf))
+	binary.BigEndian.PutUint32(request, uint32(len(w.buf)))
+	if _, err := c.Write(append(request, w.buf...)); err != nil {
+		return nil, err
+	}
+
+	var size int32
+	if err := binary.Read(c.reader, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	if size < 4 || size > maxResponseSize {
+		return nil, fmt.Errorf("invalid response size %v", size)
+	}
+	var response = make([]byte, size)
+	if _, err := io.ReadFull(c.reader, response); err != nil {
+		return nil, err
+	}
+	if id := int32(binary.BigEndian.Uint32(response)); id != c.correlationID {
+		return nil, fmt.Errorf("unexpected correlation ID %v of response", id)
+	}
+	return response[4:], nil
+}
+
+func newFindCoordinatorRequest(group string) []byte {
+	var w writer
+	w.string(group)
+	return w.buf
+}
+
+// parseFindCoordinatorResponse returns the `host:port` address of the coordinator.
+func parseFindCoordinatorResponse(response []byte) (string, error) {
+	var r = reader{buf: response}
+	var errorCode = r.int16()
+	// node_id
+	r.int32()
+	var host = r.string()
+	var port = r.int32()
+	if r.err != nil {
+		return "", fmt.Errorf("invalid response: %v", r.err)
+	}
+	if errorCode != errorCodeNone {
+		return "", fmt.Errorf("error code %v", errorCode)
+	}
+	return net.JoinHostPort(host, fmt.Sprint(port)), nil
+}
+
+func newOffsetFetchRequest(group string) []byte {
+	var w writer
+	w.string(group)
+	// topics, null for all the topics of the group
+	w.int32(-1)
+	return w.buf
+}
+
+// parseOffsetFetchResponse returns the committed offsets of the partitions of the group.
+func parseOffsetFetchResponse(response []byte) (map[topicPartition]int64, error) {
+	var r = reader{buf: response}
+	var offsets = map[topicPartition]int64{}
+	var errs []string
+	for i := r.array(); i > 0 && r.err == nil; i-- {
+		var topic = r.string()
+		// partitions: partition_index, committed_offset, metadata, error_code
+		for j := r.array(); j > 0 && r.err == nil; j-- {
+			var partition = topicPartition{topic: topic, partition: r.int32()}
+			var offset = r.int64()
+			r.string()
+			var errorCode = r.int16()
+			switch {
+			case r.err != nil:
+			case errorCode != errorCodeNone:
+				errs = append(errs, fmt.Sprintf("partition %v has error code %v", partition, errorCode))
+			case offset != noOffset:
+				offsets[partition] = offset
+			}
+		}
+	}
+	var errorCode = r.int16()
+	if r.err != nil {
+		return nil, fmt.Errorf("invalid response: %v", r.err)
+	}
+	if errorCode != errorCodeNone {
+		return nil, fmt.Errorf("error code %v", errorCode)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%v", strings.Join(errs, "; "))
+	}
+	return offsets, nil
+}
+
+func newTopicsMetadataRequest(topics []string) []byte {
+	var unique = map[string]bool{}
+	var names []string
+	for _, topic := range topics {
+		if !unique[topic] {
+			unique[topic] = true
+			names = append(names, topic)
+		}
+	}
+	sort.Strings(names)
+
+	var w writer
+	w.int32(int32(len(names)))
+	for _, topic := range names {
+		w.string(topic)
+	}
+	// allow_auto_topic_creation
+	w.int8(0)
+	return w.buf
+}
+
+// parsePartitionLeaders returns the `host:port` addresses of the brokers by their node IDs,
+// and the node IDs of the leaders of the partitions.
+func parsePartitionLeaders(response []byte) (map[int32]string, map[topicPartition]int32, error) {
+	var r = reader{buf: response}
+	var brokers = map[int32]string{}
+	var leaders = map[topicPartition]int32{}
+	// throttle_time_ms
+	r.int32()
+	// brokers: node_id, host, port, rack
+	for i := r.array(); i > 0 && r.err == nil; i-- {
+		var nodeID = r.int32()
+		var host = r.string()
+		var port = r.int32()
+		r.string()
+		brokers[nodeID] = net.JoinHostPort(host, fmt.Sprint(port))
+	}
+	// cluster_id, controller_id
+	r.string()
+	r.int32()
+	for i := r.array(); i > 0 && r.err == nil; i-- {
+		var topicErrorCode = r.int16()
+		var topic = r.string()
+		// is_internal
+		r.int8()
+		// partitions: error_code, partition_index, leader_id, replica_nodes, isr_nodes
+		for j := r.array(); j > 0 && r.err == nil; j-- {
+			var errorCode = r.int16()
+			var partition = topicPartition{topic: topic, partition: r.int32()}
+			var leader = r.int32()
+			r.skip(4 * r.array())
+			r.skip(4 * r.array())
+			if topicErrorCode == errorCodeNone && errorCode == errorCodeNone && leader >= 0 {
+				leaders[partition] = leader
+			}
+		}
+	}
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	return brokers, leaders, nil
+}
+
+func newListOffsetsRequest(partitions []topicPartition) []byte {
+	var byTopic = map[string][]int32{}
+	var topics []string
+	for _, partition := range partitions {
+		if _, ok := byTopic[partition.topic]; !ok {
+			topics = append(topics, partition.topic)
+		}
+		byTopic[partition.topic] = append(byTopic[partition.topic], partition.partition)
+	}
+	sort.Strings(topics)
+
+	var w writer
+	// replica_id of consumers
+	w.int32(-1)
+	w.int32(int32(len(topics)))
+	for _, topic := range topics {
+		w.string(topic)
+		w.int32(int32(len(byTopic[topic])))
+		for _, partition := range byTopic[topic] {
+			w.int32(partition)
+			w.int64(latestTimestamp)
+		}
+	}
+	return w.buf
+}
+
+// parseListOffsetsResponse returns the offsets of the partitions.
+func parseListOffsetsResponse(response []byte) (map[topicPartition]int64, error) {
+	var r = reader{buf: response}
+	var offsets = map[topicPartition]int64{}
+	for i := r.array(); i > 0 && r.err == nil; i-- {
+		var topic = r.string()
+		// partitions: partition_index, error_code, timestamp, offset
+		for j := r.array(); j > 0 && r.err == nil; j-- {
+			var partition = topicPartition{topic: topic, partition: r.int32()}
+			var errorCode = r.int16()
+			r.int64()
+			var offset = r.int64()
+			if r.err == nil && errorCode != errorCodeNone {
+				return nil, fmt.Errorf("partition %v has error code %v", partition, errorCode)
+			}
+			offsets[partition] = offset
+		}
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("invalid response: %v", r.err)
+	}
+	return offsets, nil
+}
@@ -0,0 +1,230 @@
+package kafka
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// fakeBroker is a single broker cluster which coordinates a consumer group.
+type fakeBroker struct {
+	// Committed offsets of the group and end offsets of the partitions, each topic having
+	// partitions 0 and 1.
+	committed  map[topicPartition]int64
+	endOffsets map[topicPartition]int64
+	// The credentials of the SASL/PLAIN authentication, none if empty.
+	username, password string
+	// The API keys of the requests received.
+	requests []int16
+	mutex    sync.Mutex
+}
+
+// serve starts the broker and returns its address.
+func (b *fakeBroker) serve(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	var host, portString, _ = net.SplitHostPort(listener.Addr().String())
+	var port, _ = strconv.Atoi(portString)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.handle(conn, host, int32(port))
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func (b *fakeBroker) handle(conn net.Conn, host string, port int32) {
+	defer conn.Close()
+	var buffered = bufio.NewReader(conn)
+	for {
+		var size int32
+		if binary.Read(buffered, binary.BigEndian, &size) != nil {
+			return
+		}
+		var request = make([]byte, size)
+		if _, err := io.ReadFull(buffered, request); err != nil {
+			return
+		}
+		var r = &reader{buf: request}
+		var apiKey = r.int16()
+		r.int16()
+		var correlationID = r.int32()
+		r.string()
+		b.mutex.Lock()
+		b.requests = append(b.requests, apiKey)
+		b.mutex.Unlock()
+
+		var w writer
+		w.int32(correlationID)
+		switch apiKey {
+		case apiKeySaslHandshake:
+			w.int16(0)
+			w.int32(1)
+			w.string(SASLMechanismPlain)
+		case apiKeySaslAuthenticate:
+			var authBytes = r.next(int(r.int32()))
+			if string(authBytes) != "\x00"+b.username+"\x00"+b.password {
+				w.int16(58)
+				w.string("Authentication failed")
+			} else {
+				w.int16(0)
+				w.int16(-1)
+			}
+			w.int32(0)
+		case apiKeyFindCoordinator:
+			w.int16(0)
+			w.int32(0)
+			w.string(host)
+			w.int32(port)
+		case apiKeyOffsetFetch:
+			w.int32(int32(len(b.committed)))
+			for partition, offset := range b.committed {
+				w.string(partition.topic)
+				w.int32(1)
+				w.int32(partition.partition)
+				w.int64(offset)
+				w.int16(-1)
+				w.int16(0)
+			}
+			w.int16(0)
+		case apiKeyMetadata:
+			w.int32(0)
+			w.int32(1)
+			w.int32(0)
+			w.string(host)
+			w.int32(port)
+			w.int16(-1)
+			w.string("cluster")
+			w.int32(0)
+			var topics = r.array()
+			w.int32(int32(topics))
+			for i := 0; i < topics; i++ {
+				var topic = r.string()
+				w.int16(0)
+				w.string(topic)
+				w.int8(0)
+				w.int32(2)
+				for partition := int32(0); partition < 2; partition++ {
+					w.int16(0)
+					w.int32(partition)
+					w.int32(0)
+					w.int32(0)
+					w.int32(0)
+				}
+			}
+		case apiKeyListOffsets:
+			r.int32()
+			var topics = r.array()
+			w.int32(int32(topics))
+			for i := 0; i < topics; i++ {
+				var topic = r.string()
+				var partitions = r.array()
+				w.string(topic)
+				w.int32(int32(partitions))
+				for j := 0; j < partitions; j++ {
+					var partition = topicPartition{topic: topic, partition: r.int32()}
+					r.int64()
+					w.int32(partition.partition)
+					w.int16(0)
+					w.int64(-1)
+					w.int64(b.endOffsets[partition])
+				}
+			}
+		default:
+			return
+		}
+		binary.Write(conn, binary.BigEndian, int32(len(w.buf)))
+		conn.Write(w.buf)
+	}
+}
+
+func TestConsumerGroupLag(t *testing.T) {
+	var broker = &fakeBroker{
+		committed: map[topicPartition]int64{
+			{topic: "orders", partition: 0}:   100,
+			{topic: "orders", partition: 1}:   250,
+			{topic: "payments", partition: 0}: 40,
+		},
+		endOffsets: map[topicPartition]int64{
+			{topic: "orders", partition: 0}:   130,
+			{topic: "orders", partition: 1}:   250,
+			{topic: "payments", partition: 0}: 50,
+		},
+	}
+	var address = broker.serve(t)
+
+	lag, err := ConsumerGroupLag(context.Background(), Config{BootstrapServers: address}, "my-job", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, lag, int64(40))
+
+	lag, err = ConsumerGroupLag(context.Background(), Config{BootstrapServers: address}, "my-job", []string{"orders"})
+	assert.NilError(t, err)
+	assert.Equal(t, lag, int64(30))
+
+	lag, err = ConsumerGroupLag(context.Background(), Config{BootstrapServers: address}, "my-job", []string{"clicks"})
+	assert.NilError(t, err)
+	assert.Equal(t, lag, int64(0))
+}
+
+func TestConsumerGroupLagSASL(t *testing.T) {
+	var broker = &fakeBroker{
+		committed:  map[topicPartition]int64{{topic: "orders", partition: 0}: 100},
+		endOffsets: map[topicPartition]int64{{topic: "orders", partition: 0}: 110},
+		username:   "flink",
+		password:   "secret",
+	}
+	var config = Config{BootstrapServers: broker.serve(t), Username: "flink", Password: "secret"}
+
+	lag, err := ConsumerGroupLag(context.Background(), config, "my-job", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, lag, int64(10))
+	assert.Equal(t, broker.requests[0], int16(apiKeySaslHandshake))
+	assert.Equal(t, broker.requests[1], int16(apiKeySaslAuthenticate))
+
+	config.Password = "wrong"
+	_, err = ConsumerGroupLag(context.Background(), config, "my-job", nil)
+	assert.ErrorContains(t, err, "SASL authentication failed: error code 58: Authentication failed")
+}
+
+func TestParseOffsetFetchResponse(t *testing.T) {
+	var w writer
+	w.int32(1)
+	w.string("orders")
+	w.int32(2)
+	w.int32(0)
+	w.int64(100)
+	w.int16(-1)
+	w.int16(0)
+	// A partition without committed offset.
+	w.int32(1)
+	w.int64(noOffset)
+	w.int16(-1)
+	w.int16(0)
+	w.int16(0)
+	offsets, err := parseOffsetFetchResponse(w.buf)
+	assert.NilError(t, err)
+	assert.Equal(t, len(offsets), 1)
+	assert.Equal(t, offsets[topicPartition{topic: "orders", partition: 0}], int64(100))
+
+	// GROUP_AUTHORIZATION_FAILED
+	w = writer{}
+	w.int32(0)
+	w.int16(30)
+	_, err = parseOffsetFetchResponse(w.buf)
+	assert.Error(t, err, "error code 30")
+
+	_, err = parseOffsetFetchResponse(w.buf[:3])
+	assert.Error(t, err, "invalid response: unexpected end of data")
+}
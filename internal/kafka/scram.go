@@ -0,0 +1,137 @@
+package kafka
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// The SASL mechanisms of Config.Mechanism.
+const (
+	SASLMechanismPlain       = "PLAIN"
+	SASLMechanismSCRAMSHA256 = "SCRAM-SHA-256"
+	SASLMechanismSCRAMSHA512 = "SCRAM-SHA-512"
+)
+
+// scramClient is the client side of the SCRAM authentication of RFC 5802, without channel
+// binding as Kafka does not support it.
+type scramClient struct {
+	hash     func() hash.Hash
+	username string
+	password string
+	nonce    string
+
+	clientFirstBare string
+	serverSignature []byte
+}
+
+func newSCRAMClient(mechanism, username, password string) (*scramClient, error) {
+	var client = &scramClient{username: username, password: password}
+	switch mechanism {
+	case SASLMechanismSCRAMSHA256:
+		client.hash = sha256.New
+	case SASLMechanismSCRAMSHA512:
+		client.hash = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported SCRAM mechanism %v", mechanism)
+	}
+	var nonce = make([]byte, 24)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	client.nonce = base64.RawStdEncoding.EncodeToString(nonce)
+	return client, nil
+}
+
+// first returns the client-first-message.
+func (c *scramClient) first() string {
+	var username = strings.NewReplacer("=", "=3D", ",", "=2C").Replace(c.username)
+	c.clientFirstBare = "n=" + username + ",r=" + c.nonce
+	return "n,," + c.clientFirstBare
+}
+
+// final returns the client-final-message with the proof of the password, for the
+// server-first-message.
+func (c *scramClient) final(serverFirst string) (string, error) {
+	var attributes = parseSCRAMAttributes(serverFirst)
+	if message, ok := attributes["e"]; ok {
+		return "", fmt.Errorf("server error: %v", message)
+	}
+	var nonce = attributes["r"]
+	if !strings.HasPrefix(nonce, c.nonce) || len(nonce) == len(c.nonce) {
+		return "", errors.New("invalid server nonce")
+	}
+	salt, err := base64.StdEncoding.DecodeString(attributes["s"])
+	if err != nil || len(salt) == 0 {
+		return "", errors.New("invalid salt")
+	}
+	iterations, err := strconv.Atoi(attributes["i"])
+	if err != nil || iterations < 1 {
+		return "", errors.New("invalid iteration count")
+	}
+
+	var saltedPassword = c.hi([]byte(c.password), salt, iterations)
+	var clientKey = c.hmac(saltedPassword, []byte("Client Key"))
+	var storedKey = c.hash()
+	storedKey.Write(clientKey)
+	// c=biws is the base64 of the GS2 header "n,,".
+	var clientFinalWithoutProof = "c=biws,r=" + nonce
+	var authMessage = []byte(c.clientFirstBare + "," + serverFirst + "," + clientFinalWithoutProof)
+	var proof = c.hmac(storedKey.Sum(nil), authMessage)
+	for i := range proof {
+		proof[i] ^= clientKey[i]
+	}
+	c.serverSignature = c.hmac(c.hmac(saltedPassword, []byte("Server Key")), authMessage)
+	return clientFinalWithoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+// verify returns an error if the server-final-message does not prove that the server knows
+// the password.
+func (c *scramClient) verify(serverFinal string) error {
+	var attributes = parseSCRAMAttributes(serverFinal)
+	if message, ok := attributes["e"]; ok {
+		return fmt.Errorf("server error: %v", message)
+	}
+	signature, err := base64.StdEncoding.DecodeString(attributes["v"])
+	if err != nil || !hmac.Equal(signature, c.serverSignature) {
+		return errors.New("invalid server signature")
+	}
+	return nil
+}
+
+func (c *scramClient) hmac(key, data []byte) []byte {
+	var mac = hmac.New(c.hash, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// hi is the PBKDF2 of the password with a single block, as long as the output of the hash.
+func (c *scramClient) hi(password, salt []byte, iterations int) []byte {
+	var u = c.hmac(password, append(append([]byte{}, salt...), 0, 0, 0, 1))
+	var result = append([]byte{}, u...)
+	for i := 1; i < iterations; i++ {
+		u = c.hmac(password, u)
+		for j := range result {
+			result[j] ^= u[j]
+		}
+	}
+	return result
+}
+
+// parseSCRAMAttributes returns the values of the comma separated `<name>=<value>` attributes.
+func parseSCRAMAttributes(message string) map[string]string {
+	var attributes = map[string]string{}
+	for _, attribute := range strings.Split(message, ",") {
+		if name, value, ok := strings.Cut(attribute, "="); ok {
+			attributes[name] = value
+		}
+	}
+	return attributes
+}
@@ -0,0 +1,30 @@
+package kafka
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// The SCRAM-SHA-256 exchange of RFC 7677.
+func TestSCRAMClient(t *testing.T) {
+	var client = &scramClient{hash: sha256.New, username: "user", password: "pencil", nonce: "rOprNGfwEbeRWgbNEkqO"}
+	assert.Equal(t, client.first(), "n,,n=user,r=rOprNGfwEbeRWgbNEkqO")
+
+	var serverFirst = "r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"
+	clientFinal, err := client.final(serverFirst)
+	assert.NilError(t, err)
+	assert.Equal(t, clientFinal,
+		"c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=")
+	assert.NilError(t, client.verify("v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4="))
+	assert.Error(t, client.verify("v=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="), "invalid server signature")
+	assert.Error(t, client.verify("e=invalid-proof"), "server error: invalid-proof")
+
+	// The nonce of the server must extend the nonce of the client.
+	_, err = client.final("r=aOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	assert.Error(t, err, "invalid server nonce")
+
+	client = &scramClient{hash: sha256.New, username: "a=b,c", nonce: "x"}
+	assert.Equal(t, client.first(), "n,,n=a=3Db=2Cc,r=x")
+}
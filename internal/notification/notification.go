@@ -0,0 +1,201 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/yaml"
+)
+
+// EventType is the type of a state transition to notify about.
+type EventType string
+
+const (
+	// The job of a cluster failed.
+	EventJobFailed EventType = "JobFailed"
+	// A savepoint of a job failed.
+	EventSavepointFailed EventType = "SavepointFailed"
+	// A running cluster started reconciling, e.g. as its components are not ready.
+	EventClusterDegraded EventType = "ClusterDegraded"
+	// The job of a cluster was stopped with a savepoint and the cluster is kept.
+	EventClusterSuspended EventType = "ClusterSuspended"
+	// A cluster started rolling back a failed update to the previous revision.
+	EventClusterRollingBack EventType = "ClusterRollingBack"
+)
+
+// WebhookType defines the payload posted to a webhook.
+type WebhookType string
+
+const (
+	// Posts `{"text": "<message>"}` to a Slack incoming webhook.
+	WebhookTypeSlack WebhookType = "Slack"
+	// Posts the event with the rendered message as JSON.
+	WebhookTypeGeneric WebhookType = "Generic"
+)
+
+const defaultTemplate = `[{{.Type}}] FlinkCluster {{.Namespace}}/{{.Name}}: {{.Message}}{{if .WebUIURL}} ({{.WebUIURL}}){{end}}`
+
+const postTimeout = 10 * time.Second
+
+// Config is the notification config of the operator.
+type Config struct {
+	Webhooks []WebhookConfig `json:"webhooks"`
+}
+
+// WebhookConfig defines a webhook to notify.
+type WebhookConfig struct {
+	// Name of the webhook for logging.
+	Name string `json:"name"`
+
+	// Type of the webhook, one of Slack or Generic, default: Generic.
+	Type WebhookType `json:"type,omitempty"`
+
+	// URL to post the notifications to.
+	URL string `json:"url"`
+
+	// Event types to notify about, default: all.
+	Events []EventType `json:"events,omitempty"`
+
+	// Go template of the message, with the fields of Event.
+	Template string `json:"template,omitempty"`
+}
+
+// Event is a state transition of a cluster.
+type Event struct {
+	Type      EventType `json:"type"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Message   string    `json:"message"`
+	JobID     string    `json:"jobID,omitempty"`
+	// URL of the Flink web UI, if exposed through an ingress.
+	WebUIURL string    `json:"webUIURL,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Notifier posts the events to the configured webhooks.
+type Notifier struct {
+	log        logr.Logger
+	httpClient *http.Client
+	webhooks   []*webhook
+}
+
+type webhook struct {
+	config   WebhookConfig
+	events   map[EventType]bool
+	template *template.Template
+}
+
+// LoadConfig reads the notification config from a YAML or JSON file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config = new(Config)
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("invalid notification config %v: %v", path, err)
+	}
+	return config, nil
+}
+
+// NewNotifier validates the config and creates the notifier.
+func NewNotifier(config *Config, log logr.Logger) (*Notifier, error) {
+	var notifier = &Notifier{
+		log:        log,
+		httpClient: &http.Client{Timeout: postTimeout},
+	}
+	for i, c := range config.Webhooks {
+		if c.URL == "" {
+			return nil, fmt.Errorf("webhooks[%d].url is required", i)
+		}
+		switch c.Type {
+		case "":
+			c.Type = WebhookTypeGeneric
+		case WebhookTypeSlack, WebhookTypeGeneric:
+		default:
+			return nil, fmt.Errorf("webhooks[%d].type: unsupported value %v", i, c.Type)
+		}
+		var events map[EventType]bool
+		for _, e := range c.Events {
+			switch e {
+			case EventJobFailed, EventSavepointFailed, EventClusterDegraded, EventClusterSuspended, EventClusterRollingBack:
+			default:
+				return nil, fmt.Errorf("webhooks[%d].events: unsupported value %v", i, e)
+			}
+			if events == nil {
+				events = map[EventType]bool{}
+			}
+			events[e] = true
+		}
+		var text = c.Template
+		if text == "" {
+			text = defaultTemplate
+		}
+		tmpl, err := template.New(c.Name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("webhooks[%d].template: %v", i, err)
+		}
+		notifier.webhooks = append(notifier.webhooks, &webhook{config: c, events: events, template: tmpl})
+	}
+	return notifier, nil
+}
+
+// Notify posts the event to the webhooks subscribed to its type in the background.
+func (n *Notifier) Notify(event Event) {
+	if n == nil {
+		return
+	}
+	for _, w := range n.webhooks {
+		if w.events != nil && !w.events[event.Type] {
+			continue
+		}
+		go func(w *webhook) {
+			if err := n.post(w, event); err != nil {
+				n.log.Error(err, "Failed to send notification",
+					"webhook", w.config.Name, "event", event.Type, "cluster", event.Namespace+"/"+event.Name)
+			}
+		}(w)
+	}
+}
+
+func (n *Notifier) post(w *webhook, event Event) error {
+	var message strings.Builder
+	if err := w.template.Execute(&message, event); err != nil {
+		return err
+	}
+
+	var payload any
+	switch w.config.Type {
+	case WebhookTypeSlack:
+		payload = map[string]string{"text": message.String()}
+	default:
+		event.Message = message.String()
+		payload = event
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.httpClient.Post(w.config.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// Do not log the URL, which holds the credentials of Slack webhooks.
+		if urlErr, ok := err.(*url.Error); ok {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %v", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package notification
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"gotest.tools/v3/assert"
+)
+
+func TestLoadConfig(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "notification.yaml")
+	os.WriteFile(path, []byte(`
+webhooks:
+- name: slack
+  type: Slack
+  url: https://hooks.slack.com/services/T000/B000/XXXX
+  events: [JobFailed]
+  template: "{{.Name}} failed"
+`), 0644)
+	config, err := LoadConfig(path)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, config.Webhooks, []WebhookConfig{{
+		Name:     "slack",
+		Type:     WebhookTypeSlack,
+		URL:      "https://hooks.slack.com/services/T000/B000/XXXX",
+		Events:   []EventType{EventJobFailed},
+		Template: "{{.Name}} failed",
+	}})
+
+	os.WriteFile(path, []byte("webhooks:\n- name: slack\n  link: https://example.com\n"), 0644)
+	_, err = LoadConfig(path)
+	assert.ErrorContains(t, err, `unknown field "link"`)
+}
+
+func TestNewNotifierInvalid(t *testing.T) {
+	var newNotifier = func(config WebhookConfig) error {
+		_, err := NewNotifier(&Config{Webhooks: []WebhookConfig{config}}, logr.Discard())
+		return err
+	}
+	assert.Error(t, newNotifier(WebhookConfig{Name: "a"}), "webhooks[0].url is required")
+	assert.Error(t, newNotifier(WebhookConfig{URL: "http://a", Type: "Teams"}), "webhooks[0].type: unsupported value Teams")
+	assert.Error(t, newNotifier(WebhookConfig{URL: "http://a", Events: []EventType{"JobSucceeded"}}),
+		"webhooks[0].events: unsupported value JobSucceeded")
+	assert.ErrorContains(t, newNotifier(WebhookConfig{URL: "http://a", Template: "{{.Name"}), "webhooks[0].template")
+}
+
+func TestNotify(t *testing.T) {
+	var requests = make(chan map[string]any, 2)
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body, _ = io.ReadAll(r.Body)
+		var payload map[string]any
+		json.Unmarshal(body, &payload)
+		requests <- payload
+	}))
+	defer server.Close()
+
+	notifier, err := NewNotifier(&Config{Webhooks: []WebhookConfig{
+		{Name: "slack", Type: WebhookTypeSlack, URL: server.URL + "/slack", Events: []EventType{EventJobFailed}},
+		{Name: "generic", URL: server.URL + "/generic", Events: []EventType{EventClusterDegraded}},
+	}}, logr.Discard())
+	assert.NilError(t, err)
+
+	var event = Event{
+		Type:      EventJobFailed,
+		Namespace: "default",
+		Name:      "mycluster",
+		Message:   "Job failed",
+		WebUIURL:  "https://flink.example.com",
+		Time:      time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+	notifier.Notify(event)
+	assert.DeepEqual(t, <-requests, map[string]any{
+		"text": "[JobFailed] FlinkCluster default/mycluster: Job failed (https://flink.example.com)",
+	})
+
+	event.Type = EventClusterDegraded
+	event.WebUIURL = ""
+	notifier.Notify(event)
+	assert.DeepEqual(t, <-requests, map[string]any{
+		"type":      "ClusterDegraded",
+		"namespace": "default",
+		"name":      "mycluster",
+		"message":   "[ClusterDegraded] FlinkCluster default/mycluster: Job failed",
+		"time":      "2022-06-01T12:00:00Z",
+	})
+	assert.Equal(t, len(requests), 0)
+
+	// The notifier is optional.
+	var disabled *Notifier
+	disabled.Notify(event)
+}
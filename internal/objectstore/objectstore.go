@@ -0,0 +1,211 @@
+// Package objectstore reads and writes the objects of the gs://, s3://, s3a://, s3p://,
+// http:// and https:// locations of the cluster specs. The requests to Cloud Storage and S3
+// are authenticated with the credentials of the workload the client runs as, see cloudauth.
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spotify/flink-on-k8s-operator/internal/cloudauth"
+)
+
+var (
+	// ErrNotFound is returned when the object or its bucket does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAccessDenied is returned when the storage rejects the credentials of the request.
+	ErrAccessDenied = errors.New("access denied")
+)
+
+// Client sends the requests to the object storage.
+type Client struct {
+	// The endpoint of Cloud Storage, https://storage.googleapis.com if empty.
+	GCSEndpoint string
+	// The endpoint of S3, whose buckets are addressed in the path if set. The buckets are
+	// addressed in the host name of the regional endpoint of AWS_REGION or
+	// AWS_DEFAULT_REGION if empty, us-east-1 by default.
+	S3Endpoint string
+
+	AWSCredentials *cloudauth.AWSCredentialsProvider
+	GCPTokenSource *cloudauth.GCPTokenSource
+
+	httpClient *http.Client
+	now        func() time.Time
+	getenv     func(string) string
+}
+
+// NewClient returns a client with the credentials of the process, whose requests time out
+// after the timeout, none if zero.
+func NewClient(timeout time.Duration) *Client {
+	return &Client{
+		S3Endpoint:     os.Getenv("AWS_ENDPOINT_URL_S3"),
+		AWSCredentials: cloudauth.NewAWSCredentialsProvider(),
+		GCPTokenSource: cloudauth.NewGCPTokenSource(),
+		httpClient:     &http.Client{Timeout: timeout},
+		now:            time.Now,
+		getenv:         os.Getenv,
+	}
+}
+
+// IsSupported returns true if the client can access the location.
+func IsSupported(location string) bool {
+	u, err := url.Parse(location)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "http", "https", "gs", "s3", "s3a", "s3p":
+		return true
+	}
+	return false
+}
+
+// Get returns the content of the object, which the caller must close.
+func (c *Client) Get(ctx context.Context, location, authorization string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, location, authorization)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Head returns an error if the object does not exist or is not readable.
+func (c *Client) Head(ctx context.Context, location, authorization string) error {
+	resp, err := c.do(ctx, http.MethodHead, location, authorization)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Put writes the object.
+func (c *Client) Put(ctx context.Context, location, authorization string, body []byte) error {
+	resp, err := c.send(ctx, http.MethodPut, location, authorization, bytes.NewReader(body), int64(len(body)), cloudauth.HashPayload(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// PutStream writes the object from the reader of size bytes without buffering it, e.g. a
+// heap dump. The payload of the requests to S3 is not signed, and S3 limits the size of the
+// objects written in a single request to 5 GiB.
+func (c *Client) PutStream(ctx context.Context, location, authorization string, body io.Reader, size int64) error {
+	resp, err := c.send(ctx, http.MethodPut, location, authorization, body, size, cloudauth.UnsignedPayload)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Delete deletes the object.
+func (c *Client) Delete(ctx context.Context, location, authorization string) error {
+	resp, err := c.do(ctx, http.MethodDelete, location, authorization)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Sends the request without a body, see send.
+func (c *Client) do(ctx context.Context, method, location, authorization string) (*http.Response, error) {
+	return c.send(ctx, method, location, authorization, nil, 0, cloudauth.HashPayload(nil))
+}
+
+// Sends the request, and returns the response if its status is successful. A static
+// authorization, if not empty, is sent instead of the credentials of the process to
+// Cloud Storage and HTTP locations. The requests to S3 are always signed, with the hash of
+// the payload.
+func (c *Client) send(
+	ctx context.Context,
+	method, location, authorization string,
+	body io.Reader,
+	size int64,
+	payloadHash string) (*http.Response, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	var requestURL string
+	var region string
+	switch u.Scheme {
+	case "http", "https":
+		requestURL = location
+	case "gs":
+		var endpoint = c.GCSEndpoint
+		if endpoint == "" {
+			endpoint = "https://storage.googleapis.com"
+		}
+		requestURL = fmt.Sprintf("%s/%s%s", endpoint, u.Host, u.EscapedPath())
+	case "s3", "s3a", "s3p":
+		region = c.getS3Region()
+		if c.S3Endpoint != "" {
+			requestURL = fmt.Sprintf("%s/%s%s", strings.TrimSuffix(c.S3Endpoint, "/"), u.Host, u.EscapedPath())
+		} else {
+			requestURL = fmt.Sprintf("https://%s.s3.%s.amazonaws.com%s", u.Host, region, u.EscapedPath())
+		}
+	default:
+		return nil, fmt.Errorf("unsupported location %v", location)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	switch {
+	case region != "":
+		creds, err := c.AWSCredentials.Get(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the AWS credentials: %w", err)
+		}
+		cloudauth.SignAWSRequest(req, payloadHash, "s3", region, creds, c.now())
+	case authorization != "":
+		req.Header.Set("Authorization", authorization)
+	case u.Scheme == "gs":
+		token, err := c.GCPTokenSource.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", cloudauth.ErrNoCredentials, err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%v %v: %w", method, location, ErrNotFound)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("%v %v: %w: %v", method, location, ErrAccessDenied, resp.Status)
+	}
+	return nil, fmt.Errorf("%v %v returned %v", method, location, resp.Status)
+}
+
+func (c *Client) getS3Region() string {
+	for _, key := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := c.getenv(key); region != "" {
+			return region
+		}
+	}
+	return "us-east-1"
+}
@@ -0,0 +1,103 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/spotify/flink-on-k8s-operator/internal/cloudauth"
+)
+
+func TestClientS3(t *testing.T) {
+	var objects = map[string]string{}
+	var payloadHashes = map[string]string{}
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/20230101/eu-west-1/s3/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = string(body)
+			payloadHashes[r.URL.Path] = r.Header.Get("X-Amz-Content-Sha256")
+		case http.MethodGet:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(body))
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "key")
+	var client = NewClient(time.Minute)
+	client.S3Endpoint = server.URL
+	client.getenv = func(key string) string { return map[string]string{"AWS_REGION": "eu-west-1"}[key] }
+	client.now = func() time.Time { return time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC) }
+
+	// The s3a:// and s3p:// schemes of the Flink file systems address the same objects.
+	assert.NilError(t, client.Put(context.Background(), "s3a://my-bucket/savepoints/probe", "", []byte("probe")))
+	body, err := client.Get(context.Background(), "s3p://my-bucket/savepoints/probe", "")
+	assert.NilError(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, string(data), "probe")
+	assert.Equal(t, objects["/my-bucket/savepoints/probe"], "probe")
+
+	// The streamed uploads are signed without their payload.
+	var dump = "heap dump"
+	assert.NilError(t, client.PutStream(context.Background(), "s3://my-bucket/diagnostics/heapdump.hprof", "", strings.NewReader(dump), int64(len(dump))))
+	assert.Equal(t, objects["/my-bucket/diagnostics/heapdump.hprof"], dump)
+	assert.Equal(t, payloadHashes["/my-bucket/diagnostics/heapdump.hprof"], cloudauth.UnsignedPayload)
+	assert.Equal(t, payloadHashes["/my-bucket/savepoints/probe"], cloudauth.HashPayload([]byte("probe")))
+
+	assert.NilError(t, client.Delete(context.Background(), "s3://my-bucket/savepoints/probe", ""))
+	_, err = client.Get(context.Background(), "s3://my-bucket/savepoints/probe", "")
+	assert.Assert(t, errors.Is(err, ErrNotFound))
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	err = client.Head(context.Background(), "s3://my-bucket/savepoints/probe", "")
+	assert.Assert(t, errors.Is(err, cloudauth.ErrNoCredentials))
+}
+
+func TestClientGCS(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/computeMetadata/v1/instance/service-accounts/default/token":
+			w.Write([]byte(`{"access_token": "token", "expires_in": 3600}`))
+		case r.Header.Get("Authorization") != "Bearer token":
+			w.WriteHeader(http.StatusForbidden)
+		case r.URL.Path != "/my-bucket/savepoints/savepoint-1/_metadata":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var client = NewClient(time.Minute)
+	client.GCSEndpoint = server.URL
+	client.GCPTokenSource.MetadataEndpoint = server.URL
+
+	assert.NilError(t, client.Head(context.Background(), "gs://my-bucket/savepoints/savepoint-1/_metadata", ""))
+	var err = client.Head(context.Background(), "gs://my-bucket/savepoints/savepoint-2/_metadata", "")
+	assert.Assert(t, errors.Is(err, ErrNotFound))
+	err = client.Head(context.Background(), "gs://my-bucket/savepoints/savepoint-1/_metadata", "Bearer other")
+	assert.Assert(t, errors.Is(err, ErrAccessDenied))
+
+	_, err = client.Get(context.Background(), "hdfs:///savepoints/savepoint-1/_metadata", "")
+	assert.Error(t, err, "unsupported location hdfs:///savepoints/savepoint-1/_metadata")
+	assert.Assert(t, !IsSupported("hdfs:///savepoints"))
+	assert.Assert(t, IsSupported("s3p://my-bucket/savepoints"))
+}
@@ -0,0 +1,332 @@
+// Package registry reads the manifests of images from their registries through the Docker
+// Registry HTTP API V2, to find the architectures an image is built for.
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// The registry of the images without a registry host, e.g. `flink:1.16`.
+	DockerHub = "docker.io"
+
+	dockerHubHost = "registry-1.docker.io"
+
+	mediaTypeManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeOCIIndex     = "application/vnd.oci.image.index.v1+json"
+	mediaTypeOCIManifest  = "application/vnd.oci.image.manifest.v1+json"
+)
+
+// Credentials are the credentials of a registry, sent with the basic or the token
+// authentication scheme.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Reference is a parsed image reference.
+type Reference struct {
+	// The registry of the image, e.g. `gcr.io` or DockerHub.
+	Registry string
+	// The repository of the image in the registry, e.g. `library/flink`.
+	Repository string
+	// The tag or the digest of the image.
+	Reference string
+}
+
+// ParseReference parses an image reference the way Docker does: the first component of the
+// name is the registry only if it contains a dot or a port or is `localhost`, and the
+// repositories of Docker Hub without a namespace are in `library`.
+func ParseReference(image string) (Reference, error) {
+	var ref = Reference{Registry: DockerHub}
+	var name = image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.Reference = name[:i], name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		var tag = name[i+1:]
+		name = name[:i]
+		if ref.Reference == "" {
+			ref.Reference = tag
+		}
+	}
+	if ref.Reference == "" {
+		ref.Reference = "latest"
+	}
+	if first, rest, found := strings.Cut(name, "/"); found &&
+		(strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry, name = first, rest
+	}
+	if ref.Registry == DockerHub && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	if name == "" || ref.Reference == "" {
+		return Reference{}, fmt.Errorf("invalid image reference %q", image)
+	}
+	ref.Repository = name
+	return ref, nil
+}
+
+// ParseDockerConfig returns the credentials of the registries of a Docker config, the
+// `.dockerconfigjson` or `.dockercfg` key of an image pull secret, keyed by registry.
+func ParseDockerConfig(data []byte) (map[string]Credentials, error) {
+	type authConfig struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	}
+	var config struct {
+		Auths map[string]authConfig `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	if config.Auths == nil {
+		// The legacy format is the map of the auths itself.
+		if err := json.Unmarshal(data, &config.Auths); err != nil {
+			return nil, err
+		}
+	}
+
+	var credentials = map[string]Credentials{}
+	for server, auth := range config.Auths {
+		var creds = Credentials{Username: auth.Username, Password: auth.Password}
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid auth of %v: %v", server, err)
+			}
+			creds.Username, creds.Password, _ = strings.Cut(string(decoded), ":")
+		}
+		credentials[normalizeRegistry(server)] = creds
+	}
+	return credentials, nil
+}
+
+// normalizeRegistry returns the registry of a server of a Docker config, which may be a URL.
+func normalizeRegistry(server string) string {
+	server = strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+	server, _, _ = strings.Cut(server, "/")
+	switch server {
+	case "index.docker.io", dockerHubHost:
+		return DockerHub
+	}
+	return server
+}
+
+// Client reads the manifests of images.
+type Client struct {
+	// Whether the registries are called over HTTP instead of HTTPS.
+	PlainHTTP bool
+
+	client *http.Client
+}
+
+// NewClient returns a client of the registries.
+func NewClient() *Client {
+	return &Client{client: &http.Client{}}
+}
+
+// GetArchitectures returns the architectures the image is built for: the architectures of
+// the manifests of its manifest list or image index, or the architecture of its config.
+// The registry is called anonymously unless the credentials contain its registry.
+func (c *Client) GetArchitectures(
+	ctx context.Context, image string, credentials map[string]Credentials) ([]string, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+	var session = &session{client: c, ref: ref}
+	if creds, ok := credentials[ref.Registry]; ok {
+		session.creds = &creds
+	}
+
+	var manifest struct {
+		MediaType string `json:"mediaType"`
+		Manifests []struct {
+			Platform struct {
+				Architecture string `json:"architecture"`
+			} `json:"platform"`
+		} `json:"manifests"`
+		Config struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+	}
+	var accept = strings.Join([]string{mediaTypeManifestList, mediaTypeOCIIndex, mediaTypeManifest, mediaTypeOCIManifest}, ", ")
+	mediaType, err := session.get(ctx, "manifests/"+ref.Reference, accept, &manifest)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.MediaType == "" {
+		manifest.MediaType = mediaType
+	}
+
+	switch manifest.MediaType {
+	case mediaTypeManifestList, mediaTypeOCIIndex:
+		var architectures []string
+		for _, m := range manifest.Manifests {
+			// The attestation manifests of BuildKit are of the unknown platform.
+			if arch := m.Platform.Architecture; arch != "" && arch != "unknown" {
+				architectures = append(architectures, arch)
+			}
+		}
+		return architectures, nil
+	default:
+		if manifest.Config.Digest == "" {
+			return nil, fmt.Errorf("unsupported manifest type %q of %v", manifest.MediaType, image)
+		}
+		var config struct {
+			Architecture string `json:"architecture"`
+		}
+		if _, err := session.get(ctx, "blobs/"+manifest.Config.Digest, "*/*", &config); err != nil {
+			return nil, err
+		}
+		return []string{config.Architecture}, nil
+	}
+}
+
+// session calls the registry of an image with the token of its repository, once issued.
+type session struct {
+	client *Client
+	ref    Reference
+	creds  *Credentials
+	token  string
+}
+
+// get gets the resource of the repository, decodes it into out and returns its media type.
+func (s *session) get(ctx context.Context, path string, accept string, out interface{}) (string, error) {
+	var host = s.ref.Registry
+	if host == DockerHub {
+		host = dockerHubHost
+	}
+	var scheme = "https"
+	if s.client.PlainHTTP {
+		scheme = "http"
+	}
+	var u = fmt.Sprintf("%s://%s/v2/%s/%s", scheme, host, s.ref.Repository, path)
+
+	resp, err := s.do(ctx, u, accept)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && s.token == "" {
+		var challenge = resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := s.authenticate(ctx, challenge); err != nil {
+			return "", fmt.Errorf("failed to authenticate to %v: %v", s.ref.Registry, err)
+		}
+		if resp, err = s.do(ctx, u, accept); err != nil {
+			return "", err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %v: %v", u, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return "", err
+	}
+	return resp.Header.Get("Content-Type"), nil
+}
+
+func (s *session) do(ctx context.Context, u string, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	switch {
+	case s.token != "":
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	case s.creds != nil:
+		req.SetBasicAuth(s.creds.Username, s.creds.Password)
+	}
+	return s.client.client.Do(req)
+}
+
+// authenticate gets a token for the repository from the token server of the bearer
+// challenge, with the credentials if any.
+// https://docs.docker.com/registry/spec/auth/token/
+func (s *session) authenticate(ctx context.Context, challenge string) error {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		// The credentials were sent with the basic scheme already.
+		return fmt.Errorf("unauthorized")
+	}
+	var attrs = parseChallengeParams(params)
+	realm, err := url.Parse(attrs["realm"])
+	if err != nil || attrs["realm"] == "" {
+		return fmt.Errorf("invalid challenge %q", challenge)
+	}
+	var query = realm.Query()
+	if service, ok := attrs["service"]; ok {
+		query.Set("service", service)
+	}
+	var scope = attrs["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", s.ref.Repository)
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if s.creds != nil {
+		req.SetBasicAuth(s.creds.Username, s.creds.Password)
+	}
+	resp, err := s.client.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %v: %v", realm.Path, resp.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return err
+	}
+	s.token = token.Token
+	if s.token == "" {
+		s.token = token.AccessToken
+	}
+	if s.token == "" {
+		return fmt.Errorf("no token issued")
+	}
+	return nil
+}
+
+// parseChallengeParams parses the `key="value"` parameters of an authentication challenge.
+func parseChallengeParams(params string) map[string]string {
+	var attrs = map[string]string{}
+	for params != "" {
+		var key, rest, found = strings.Cut(strings.TrimLeft(params, ", "), "=")
+		if !found {
+			break
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				break
+			}
+			value, params = rest[1:end+1], rest[end+2:]
+		} else {
+			value, params, _ = strings.Cut(rest, ",")
+		}
+		attrs[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return attrs
+}
@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseReference(t *testing.T) {
+	var tests = map[string]Reference{
+		"flink":                          {Registry: DockerHub, Repository: "library/flink", Reference: "latest"},
+		"flink:1.16":                     {Registry: DockerHub, Repository: "library/flink", Reference: "1.16"},
+		"apache/flink:1.16":              {Registry: DockerHub, Repository: "apache/flink", Reference: "1.16"},
+		"gcr.io/project/flink:1.16":      {Registry: "gcr.io", Repository: "project/flink", Reference: "1.16"},
+		"localhost:5000/flink":           {Registry: "localhost:5000", Repository: "flink", Reference: "latest"},
+		"gcr.io/flink:1.16@sha256:abcd":  {Registry: "gcr.io", Repository: "flink", Reference: "sha256:abcd"},
+		"localhost/flink@sha256:abcd":    {Registry: "localhost", Repository: "flink", Reference: "sha256:abcd"},
+		"registry.example.com:443/a/b/c": {Registry: "registry.example.com:443", Repository: "a/b/c", Reference: "latest"},
+	}
+	for image, expected := range tests {
+		ref, err := ParseReference(image)
+		assert.NilError(t, err, image)
+		assert.DeepEqual(t, ref, expected)
+	}
+}
+
+func TestParseDockerConfig(t *testing.T) {
+	credentials, err := ParseDockerConfig([]byte(`{"auths": {
+		"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"},
+		"gcr.io": {"username": "_json_key", "password": "key"}}}`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, credentials, map[string]Credentials{
+		DockerHub: {Username: "user", Password: "pass"},
+		"gcr.io":  {Username: "_json_key", Password: "key"},
+	})
+
+	credentials, err = ParseDockerConfig([]byte(`{"quay.io": {"auth": "dXNlcjpwYXNz"}}`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, credentials, map[string]Credentials{"quay.io": {Username: "user", Password: "pass"}})
+}
+
+func TestGetArchitectures(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if user, pass, _ := r.BasicAuth(); user != "user" || pass != "pass" ||
+				r.URL.Query().Get("scope") != "repository:flink:pull" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"token": "token"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.Header().Set("WWW-Authenticate",
+				fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:flink:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/flink/manifests/multi":
+			w.Header().Set("Content-Type", mediaTypeOCIIndex)
+			w.Write([]byte(`{"manifests": [
+				{"platform": {"architecture": "amd64", "os": "linux"}},
+				{"platform": {"architecture": "arm64", "os": "linux"}},
+				{"platform": {"architecture": "unknown", "os": "unknown"}}]}`))
+		case "/v2/flink/manifests/single":
+			w.Header().Set("Content-Type", mediaTypeManifest)
+			w.Write([]byte(`{"mediaType": "` + mediaTypeManifest + `", "config": {"digest": "sha256:config"}}`))
+		case "/v2/flink/blobs/sha256:config":
+			w.Write([]byte(`{"architecture": "amd64", "os": "linux"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var host = strings.TrimPrefix(server.URL, "http://")
+	var credentials = map[string]Credentials{host: {Username: "user", Password: "pass"}}
+	var client = NewClient()
+	client.PlainHTTP = true
+
+	architectures, err := client.GetArchitectures(context.Background(), host+"/flink:multi", credentials)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, architectures, []string{"amd64", "arm64"})
+
+	architectures, err = client.GetArchitectures(context.Background(), host+"/flink:single", credentials)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, architectures, []string{"amd64"})
+
+	_, err = client.GetArchitectures(context.Background(), host+"/flink:multi", nil)
+	assert.ErrorContains(t, err, "failed to authenticate")
+
+	_, err = client.GetArchitectures(context.Background(), host+"/flink:missing", credentials)
+	assert.ErrorContains(t, err, "404 Not Found")
+}
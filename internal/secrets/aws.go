@@ -0,0 +1,63 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spotify/flink-on-k8s-operator/internal/cloudauth"
+)
+
+// AWSSecretsManagerProvider gets the secrets of AWS Secrets Manager with the credentials of
+// the operator pod: the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+// environment variables, otherwise the web identity of IAM roles for service accounts.
+// The name of a secret is its name or ARN. The secret string must be a JSON object.
+type AWSSecretsManagerProvider struct {
+	Region string
+	// The endpoint of Secrets Manager, https://secretsmanager.<region>.amazonaws.com if empty.
+	Endpoint string
+
+	client      *http.Client
+	now         func() time.Time
+	credentials func(ctx context.Context) (cloudauth.AWSCredentials, error)
+}
+
+// NewAWSSecretsManagerProvider returns the provider of Secrets Manager in the region.
+func NewAWSSecretsManagerProvider(region string) *AWSSecretsManagerProvider {
+	return &AWSSecretsManagerProvider{
+		Region:      region,
+		client:      &http.Client{},
+		now:         time.Now,
+		credentials: cloudauth.NewAWSCredentialsProvider().Get,
+	}
+}
+
+func (p *AWSSecretsManagerProvider) GetSecret(ctx context.Context, name string) (map[string]string, error) {
+	creds, err := p.credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the AWS credentials: %v", err)
+	}
+	var endpoint = p.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", p.Region)
+	}
+	body, _ := json.Marshal(map[string]string{"SecretId": name})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	cloudauth.SignAWSRequest(req, cloudauth.HashPayload(body), "secretsmanager", p.Region, creds, p.now())
+
+	var resp struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := doJSON(p.client, req, &resp); err != nil {
+		return nil, err
+	}
+	return parseSecretObject([]byte(resp.SecretString))
+}
@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/spotify/flink-on-k8s-operator/internal/cloudauth"
+)
+
+// GCPSecretManagerProvider gets the secrets of Google Cloud Secret Manager with the
+// credentials of the operator pod: the service account key file of
+// GOOGLE_APPLICATION_CREDENTIALS, otherwise the service account of the metadata server, e.g.
+// through Workload Identity.
+// The name of a secret is `projects/<project>/secrets/<secret>`, optionally followed by
+// `/versions/<version>`, the latest version by default. The payload must be a JSON object.
+type GCPSecretManagerProvider struct {
+	// The endpoint of the Secret Manager API, https://secretmanager.googleapis.com if empty.
+	Endpoint string
+	// The source of the access tokens.
+	TokenSource *cloudauth.GCPTokenSource
+
+	client *http.Client
+}
+
+// NewGCPSecretManagerProvider returns the provider of Secret Manager.
+func NewGCPSecretManagerProvider() *GCPSecretManagerProvider {
+	return &GCPSecretManagerProvider{TokenSource: cloudauth.NewGCPTokenSource(), client: &http.Client{}}
+}
+
+func (p *GCPSecretManagerProvider) GetSecret(ctx context.Context, name string) (map[string]string, error) {
+	name = strings.Trim(name, "/")
+	if !strings.HasPrefix(name, "projects/") || !strings.Contains(name, "/secrets/") {
+		return nil, fmt.Errorf("invalid Secret Manager secret name %q, must be projects/<project>/secrets/<secret>", name)
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	token, err := p.TokenSource.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoint = p.Endpoint
+	if endpoint == "" {
+		endpoint = "https://secretmanager.googleapis.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/%s:access", endpoint, name), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var resp struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := doJSON(p.client, req, &resp); err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return nil, err
+	}
+	return parseSecretObject(data)
+}
@@ -0,0 +1,177 @@
+// Package secrets gets the secrets of external secret stores, which the operator resolves
+// the Flink properties of spec.flinkPropertiesFrom from.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const requestTimeout = 10 * time.Second
+
+// Provider gets the secrets of an external secret store.
+type Provider interface {
+	// GetSecret returns the key-value pairs of the named secret.
+	GetSecret(ctx context.Context, name string) (map[string]string, error)
+}
+
+// ScopeConfig restricts the secrets the clusters of each namespace may resolve, as the
+// operator reads them with its own credentials on behalf of the clusters.
+type ScopeConfig struct {
+	// The name prefixes of the secrets of each provider which the clusters of a namespace
+	// may resolve, by namespace, e.g. `team-a: {Vault: [secret/team-a/]}`. The clusters of
+	// namespaces which are not listed may not resolve any secrets.
+	Namespaces map[string]map[string][]string `json:"namespaces"`
+}
+
+// LoadScopeConfig reads the scope config of the YAML file.
+func LoadScopeConfig(path string) (*ScopeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config = new(ScopeConfig)
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("invalid secret store scope config %v: %v", path, err)
+	}
+	return config, nil
+}
+
+// Resolver gets the secrets from the registered providers and caches them for the TTL, so
+// that the stores are not called in every reconciliation. Rotated secrets are picked up
+// once their cache entry expires. Only the secrets in the scope of the namespace of a
+// cluster are resolved; without a scope config no secrets are.
+type Resolver struct {
+	ttl       time.Duration
+	scopes    *ScopeConfig
+	providers map[string]Provider
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[cacheKey]cacheEntry
+}
+
+type cacheKey struct {
+	namespace string
+	provider  string
+	name      string
+}
+
+type cacheEntry struct {
+	values  map[string]string
+	expires time.Time
+}
+
+// NewResolver returns a resolver without providers, which caches the secrets for the TTL and
+// resolves the secrets in the scopes of the config only.
+func NewResolver(ttl time.Duration, scopes *ScopeConfig) *Resolver {
+	return &Resolver{
+		ttl:       ttl,
+		scopes:    scopes,
+		providers: map[string]Provider{},
+		now:       time.Now,
+		cache:     map[cacheKey]cacheEntry{},
+	}
+}
+
+// Register makes the secrets of the provider available under its name.
+func (r *Resolver) Register(name string, provider Provider) {
+	r.providers[name] = provider
+}
+
+// Resolve returns the key-value pairs of the named secret of the provider for a cluster of
+// the namespace. The cache entries are per namespace, so that a secret cached for one
+// namespace is never served to another.
+func (r *Resolver) Resolve(ctx context.Context, namespace, provider, name string) (map[string]string, error) {
+	if !r.inScope(namespace, provider, name) {
+		return nil, fmt.Errorf("secret %v of %v is not in the scope of namespace %v in the operator", name, provider, namespace)
+	}
+	var key = cacheKey{namespace: namespace, provider: provider, name: name}
+	var now = r.now()
+	r.mu.Lock()
+	entry, ok := r.cache[key]
+	r.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.values, nil
+	}
+
+	p, ok := r.providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("secret store %v is not configured in the operator", provider)
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+	}
+	values, err := p.GetSecret(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %v of %v: %v", name, provider, err)
+	}
+	r.mu.Lock()
+	r.cache[key] = cacheEntry{values: values, expires: now.Add(r.ttl)}
+	r.mu.Unlock()
+	return values, nil
+}
+
+// Checks that the name has a prefix allowed for the namespace and no relative path segments,
+// which could escape the prefix.
+func (r *Resolver) inScope(namespace, provider, name string) bool {
+	if r.scopes == nil {
+		return false
+	}
+	for _, segment := range strings.Split(strings.Trim(name, "/"), "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	for _, prefix := range r.scopes.Namespaces[namespace][provider] {
+		if prefix != "" && strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSecretObject returns the key-value pairs of a secret stored as a JSON object. Values
+// which are not strings are kept as JSON.
+func parseSecretObject(data []byte) (map[string]string, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("the secret is not a JSON object: %v", err)
+	}
+	return toStringValues(object), nil
+}
+
+func toStringValues(object map[string]json.RawMessage) map[string]string {
+	var values = make(map[string]string, len(object))
+	for key, raw := range object {
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			value = string(raw)
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// doJSON sends the request and decodes the JSON response into out.
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%v %v: %v", req.Method, req.URL.Path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
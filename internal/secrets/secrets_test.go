@@ -0,0 +1,173 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/spotify/flink-on-k8s-operator/internal/cloudauth"
+)
+
+type fakeProvider struct {
+	calls  int
+	values map[string]string
+}
+
+func (p *fakeProvider) GetSecret(ctx context.Context, name string) (map[string]string, error) {
+	p.calls++
+	if p.values == nil {
+		return nil, fmt.Errorf("not found")
+	}
+	return p.values, nil
+}
+
+func TestResolverCache(t *testing.T) {
+	var now = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	var provider = &fakeProvider{values: map[string]string{"password": "a"}}
+	var scopes = &ScopeConfig{Namespaces: map[string]map[string][]string{
+		"team-a": {"Fake": {"team-a/"}, "Vault": {"secret/team-a/"}},
+		"team-b": {"Fake": {"team-b/"}},
+	}}
+	var resolver = NewResolver(5*time.Minute, scopes)
+	resolver.now = func() time.Time { return now }
+	resolver.Register("Fake", provider)
+
+	values, err := resolver.Resolve(context.Background(), "team-a", "Fake", "team-a/kafka")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, map[string]string{"password": "a"})
+
+	// The secret is cached until the TTL elapses.
+	provider.values = map[string]string{"password": "b"}
+	now = now.Add(4 * time.Minute)
+	values, _ = resolver.Resolve(context.Background(), "team-a", "Fake", "team-a/kafka")
+	assert.DeepEqual(t, values, map[string]string{"password": "a"})
+	assert.Equal(t, provider.calls, 1)
+
+	now = now.Add(time.Minute)
+	values, _ = resolver.Resolve(context.Background(), "team-a", "Fake", "team-a/kafka")
+	assert.DeepEqual(t, values, map[string]string{"password": "b"})
+	assert.Equal(t, provider.calls, 2)
+
+	_, err = resolver.Resolve(context.Background(), "team-a", "Vault", "secret/team-a/kafka")
+	assert.Error(t, err, "secret store Vault is not configured in the operator")
+}
+
+func TestResolverScopes(t *testing.T) {
+	var provider = &fakeProvider{values: map[string]string{"password": "a"}}
+	var scopes = &ScopeConfig{Namespaces: map[string]map[string][]string{
+		"team-a": {"Fake": {"team-a/"}},
+		"team-b": {"Fake": {"team-b/"}},
+	}}
+	var resolver = NewResolver(5*time.Minute, scopes)
+	resolver.Register("Fake", provider)
+
+	_, err := resolver.Resolve(context.Background(), "team-b", "Fake", "team-b/kafka")
+	assert.NilError(t, err)
+
+	// The secret cached for team-b is not served to team-a, which may not read it.
+	_, err = resolver.Resolve(context.Background(), "team-a", "Fake", "team-b/kafka")
+	assert.Error(t, err, "secret team-b/kafka of Fake is not in the scope of namespace team-a in the operator")
+	_, err = resolver.Resolve(context.Background(), "team-a", "Fake", "team-a/../team-b/kafka")
+	assert.ErrorContains(t, err, "is not in the scope of namespace team-a")
+	_, err = resolver.Resolve(context.Background(), "team-c", "Fake", "team-b/kafka")
+	assert.ErrorContains(t, err, "is not in the scope of namespace team-c")
+	assert.Equal(t, provider.calls, 1)
+
+	// Without a scope config no secrets are resolved.
+	resolver = NewResolver(5*time.Minute, nil)
+	resolver.Register("Fake", provider)
+	_, err = resolver.Resolve(context.Background(), "team-b", "Fake", "team-b/kafka")
+	assert.ErrorContains(t, err, "is not in the scope of namespace team-b")
+}
+
+func TestVaultProvider(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			w.Write([]byte(`{"auth": {"client_token": "s.token", "lease_duration": 3600}}`))
+		case "/v1/secret/data/flink/kafka":
+			if r.Header.Get("X-Vault-Token") != "s.token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"data": {"data": {"password": "secret", "port": 9092}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var tokenFile = filepath.Join(t.TempDir(), "token")
+	assert.NilError(t, os.WriteFile(tokenFile, []byte("jwt"), 0600))
+	var provider = NewVaultProvider(server.URL, "flink-operator")
+	provider.TokenFile = tokenFile
+
+	values, err := provider.GetSecret(context.Background(), "secret/flink/kafka")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, map[string]string{"password": "secret", "port": "9092"})
+
+	_, err = provider.GetSecret(context.Background(), "kafka")
+	assert.Error(t, err, `invalid Vault secret name "kafka", must be <mount>/<path>`)
+}
+
+func TestGCPSecretManagerProvider(t *testing.T) {
+	var payload = base64.StdEncoding.EncodeToString([]byte(`{"password": "secret"}`))
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/computeMetadata/v1/instance/service-accounts/default/token":
+			w.Write([]byte(`{"access_token": "token", "expires_in": 3600}`))
+		case "/v1/projects/my-project/secrets/kafka/versions/latest:access":
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(fmt.Sprintf(`{"payload": {"data": %q}}`, payload)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var provider = NewGCPSecretManagerProvider()
+	provider.Endpoint = server.URL
+	provider.TokenSource.MetadataEndpoint = server.URL
+
+	values, err := provider.GetSecret(context.Background(), "projects/my-project/secrets/kafka")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, map[string]string{"password": "secret"})
+
+	_, err = provider.GetSecret(context.Background(), "projects/my-project/secrets/kafka/versions/2")
+	assert.ErrorContains(t, err, "404 Not Found")
+}
+
+func TestAWSSecretsManagerProvider(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/20230101/eu-west-1/secretsmanager/aws4_request") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"Name": "kafka", "SecretString": "{\"password\": \"secret\"}"}`))
+	}))
+	defer server.Close()
+
+	var provider = NewAWSSecretsManagerProvider("eu-west-1")
+	provider.Endpoint = server.URL
+	provider.now = func() time.Time { return time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC) }
+	provider.credentials = func(context.Context) (cloudauth.AWSCredentials, error) {
+		return cloudauth.AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "key"}, nil
+	}
+
+	values, err := provider.GetSecret(context.Background(), "kafka")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, map[string]string{"password": "secret"})
+}
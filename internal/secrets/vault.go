@@ -0,0 +1,127 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The token of the service account of the operator, sent to the Kubernetes auth method.
+const serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// VaultProvider gets the secrets of the KV version 2 secrets engine of HashiCorp Vault. The
+// name of a secret is the path of the engine followed by the path of the secret, e.g.
+// `secret/flink/kafka`.
+type VaultProvider struct {
+	// The address of the Vault server, e.g. https://vault.example.com:8200.
+	Address string
+	// The role of the Kubernetes auth method to log in with. If empty, the token of the
+	// VAULT_TOKEN environment variable is used.
+	Role string
+	// The path of the Kubernetes auth method, "kubernetes" if empty.
+	AuthMount string
+	// The file of the JWT sent to the Kubernetes auth method, the service account token of
+	// the operator if empty.
+	TokenFile string
+
+	client *http.Client
+	now    func() time.Time
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewVaultProvider returns the provider of the Vault server at the address.
+func NewVaultProvider(address string, role string) *VaultProvider {
+	return &VaultProvider{
+		Address: strings.TrimSuffix(address, "/"),
+		Role:    role,
+		client:  &http.Client{},
+		now:     time.Now,
+	}
+}
+
+func (p *VaultProvider) GetSecret(ctx context.Context, name string) (map[string]string, error) {
+	var mount, path, found = strings.Cut(strings.Trim(name, "/"), "/")
+	if !found || path == "" {
+		return nil, fmt.Errorf("invalid Vault secret name %q, must be <mount>/<path>", name)
+	}
+	token, err := p.getToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log in to Vault: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/v1/%s/data/%s", p.Address, mount, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	var resp struct {
+		Data struct {
+			Data map[string]json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err := doJSON(p.client, req, &resp); err != nil {
+		return nil, err
+	}
+	return toStringValues(resp.Data.Data), nil
+}
+
+// getToken returns the token of VAULT_TOKEN, or logs in with the Kubernetes auth method
+// again once the token of the previous login expired.
+func (p *VaultProvider) getToken(ctx context.Context) (string, error) {
+	if p.Role == "" {
+		var token = os.Getenv("VAULT_TOKEN")
+		if token == "" {
+			return "", fmt.Errorf("neither a role nor VAULT_TOKEN is set")
+		}
+		return token, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var now = p.now()
+	if p.token != "" && now.Before(p.tokenExpiry) {
+		return p.token, nil
+	}
+	var tokenFile = p.TokenFile
+	if tokenFile == "" {
+		tokenFile = serviceAccountTokenPath
+	}
+	jwt, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", err
+	}
+	var authMount = p.AuthMount
+	if authMount == "" {
+		authMount = "kubernetes"
+	}
+	body, _ := json.Marshal(map[string]string{"role": p.Role, "jwt": strings.TrimSpace(string(jwt))})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s/v1/auth/%s/login", p.Address, authMount), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	if err := doJSON(p.client, req, &resp); err != nil {
+		return "", err
+	}
+	// Log in again a little before the token expires.
+	var lease = time.Duration(resp.Auth.LeaseDuration) * time.Second
+	p.token = resp.Auth.ClientToken
+	p.tokenExpiry = now.Add(lease - lease/10)
+	return p.token, nil
+}
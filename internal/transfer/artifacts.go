@@ -0,0 +1,81 @@
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spotify/flink-on-k8s-operator/internal/objectstore"
+)
+
+// Artifact is a file to download with `download-artifacts`.
+type Artifact struct {
+	URI string `json:"uri"`
+	// The path to download the file to.
+	Path string `json:"path"`
+	// The expected SHA-256 checksum in hex, not checked if empty.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// Downloads the files of --artifacts, e.g. the extra artifacts of the TaskManagers. Each file
+// is renamed into place once it is downloaded and verified, so that no partial file is left
+// if a download fails.
+func runDownloadArtifacts(ctx context.Context, flags *flag.FlagSet, args []string, out io.Writer) error {
+	var artifactsJSON = flags.String("artifacts", "", "The JSON array of the files to download.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	var artifacts []Artifact
+	if err := json.Unmarshal([]byte(*artifactsJSON), &artifacts); err != nil {
+		return fmt.Errorf("invalid --artifacts: %v", err)
+	}
+
+	var store = objectstore.NewClient(transferTimeout)
+	for _, artifact := range artifacts {
+		fmt.Fprintf(out, "Downloading %v to %v\n", artifact.URI, artifact.Path)
+		if err := downloadArtifact(ctx, store, artifact); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func downloadArtifact(ctx context.Context, store *objectstore.Client, artifact Artifact) error {
+	if err := os.MkdirAll(filepath.Dir(artifact.Path), 0755); err != nil {
+		return err
+	}
+	body, err := store.Get(ctx, artifact.URI, "")
+	if err != nil {
+		return fmt.Errorf("failed to download %v: %v", artifact.URI, err)
+	}
+	defer body.Close()
+
+	file, err := os.CreateTemp(filepath.Dir(artifact.Path), filepath.Base(artifact.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	var hash = sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download %v: %v", artifact.URI, err)
+	}
+	var checksum = hex.EncodeToString(hash.Sum(nil))
+	if artifact.SHA256 != "" && !strings.EqualFold(artifact.SHA256, checksum) {
+		return fmt.Errorf("checksum mismatch of %v: expected sha256 %v, got %v", artifact.URI, artifact.SHA256, checksum)
+	}
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), artifact.Path)
+}
@@ -0,0 +1,55 @@
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDownloadArtifacts(t *testing.T) {
+	var content = []byte("PK\x03\x04udfs")
+	var hash = sha256.Sum256(content)
+	var checksum = hex.EncodeToString(hash[:])
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/udfs.jar" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	var dir = t.TempDir()
+	var artifacts, _ = json.Marshal([]Artifact{
+		{URI: server.URL + "/udfs.jar", Path: filepath.Join(dir, "lib/udfs.jar"), SHA256: checksum},
+		{URI: server.URL + "/udfs.jar", Path: filepath.Join(dir, "plugins/udfs/udfs.jar")},
+	})
+	assert.NilError(t, Run(context.Background(), []string{"download-artifacts", "--artifacts", string(artifacts)}, io.Discard))
+	for _, file := range []string{"lib/udfs.jar", "plugins/udfs/udfs.jar"} {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		assert.NilError(t, err)
+		assert.Equal(t, string(data), string(content))
+	}
+
+	// No file is left when the checksum does not match.
+	artifacts, _ = json.Marshal([]Artifact{
+		{URI: server.URL + "/udfs.jar", Path: filepath.Join(dir, "mismatch/udfs.jar"), SHA256: "00" + checksum[2:]},
+	})
+	var err = Run(context.Background(), []string{"download-artifacts", "--artifacts", string(artifacts)}, io.Discard)
+	assert.ErrorContains(t, err, "checksum mismatch of "+server.URL+"/udfs.jar")
+	entries, _ := os.ReadDir(filepath.Join(dir, "mismatch"))
+	assert.Equal(t, len(entries), 0)
+
+	artifacts, _ = json.Marshal([]Artifact{{URI: server.URL + "/missing.jar", Path: filepath.Join(dir, "lib/missing.jar")}})
+	err = Run(context.Background(), []string{"download-artifacts", "--artifacts", string(artifacts)}, io.Discard)
+	assert.ErrorContains(t, err, "failed to download "+server.URL+"/missing.jar")
+}
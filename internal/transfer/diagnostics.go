@@ -0,0 +1,134 @@
+package transfer
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spotify/flink-on-k8s-operator/internal/objectstore"
+)
+
+// The environment variable of the Authorization header of the uploads of
+// `collect-diagnostics`, kept out of the arguments of the process.
+const DiagnosticsAuthorizationEnvVar = "DIAGNOSTICS_AUTHORIZATION"
+
+// The prefix of the heap dumps renamed by `collect-diagnostics`.
+const heapDumpFilePrefix = "heapdump-"
+
+const diagnosticsTimeFormat = "20060102T150405Z"
+
+// Collects the files the JVM writes to the diagnostics volume, until it is terminated. The
+// heap dumps are renamed with the time they are collected at, so that the JVM can write the
+// next one. If --upload is set, the heap dumps and the flight recordings are uploaded to it
+// and removed from the volume. A file is collected when it has not been modified for the
+// settle period, so that the files being written are skipped.
+func runCollectDiagnostics(ctx context.Context, flags *flag.FlagSet, args []string, out io.Writer) error {
+	var dir = flags.String("dir", "/opt/flink/diagnostics", "The directory of the diagnostics files.")
+	var upload = flags.String("upload", "", "The location of the directory to upload the files to, e.g. gs://<bucket>/<path>.")
+	var interval = flags.Duration("interval", 10*time.Second, "The interval between the scans of the directory.")
+	var settle = flags.Duration("settle", 30*time.Second, "The time after which an unmodified file is collected.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *upload != "" && !objectstore.IsSupported(*upload) {
+		return fmt.Errorf("unsupported --upload %v", *upload)
+	}
+
+	var collector = &diagnosticsCollector{
+		dir:           *dir,
+		upload:        strings.TrimSuffix(*upload, "/"),
+		authorization: os.Getenv(DiagnosticsAuthorizationEnvVar),
+		settle:        *settle,
+		store:         objectstore.NewClient(transferTimeout),
+		out:           out,
+	}
+	var ticker = time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		collector.collect(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+type diagnosticsCollector struct {
+	dir           string
+	upload        string
+	authorization string
+	settle        time.Duration
+	store         *objectstore.Client
+	out           io.Writer
+}
+
+// Collects the settled files of the directory. The failures are logged, and the files are
+// retried on the next scan.
+func (c *diagnosticsCollector) collect(ctx context.Context, now time.Time) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		fmt.Fprintf(c.out, "Failed to read %v: %v\n", c.dir, err)
+		return
+	}
+	var names []string
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || strings.HasPrefix(entry.Name(), ".") ||
+			now.Sub(info.ModTime()) < c.settle {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if isNewHeapDump(name) {
+			var renamed = heapDumpFilePrefix + now.UTC().Format(diagnosticsTimeFormat) + "-" + name
+			if err := os.Rename(filepath.Join(c.dir, name), filepath.Join(c.dir, renamed)); err != nil {
+				fmt.Fprintf(c.out, "Failed to rename heap dump %v: %v\n", name, err)
+				continue
+			}
+			fmt.Fprintf(c.out, "Collected heap dump %v\n", filepath.Join(c.dir, renamed))
+			name = renamed
+		}
+		if c.upload == "" {
+			continue
+		}
+		var location = c.upload + "/" + name
+		if err := c.uploadFile(ctx, filepath.Join(c.dir, name), location); err != nil {
+			fmt.Fprintf(c.out, "Failed to upload %v: %v\n", name, err)
+			continue
+		}
+		fmt.Fprintf(c.out, "Uploaded %v to %v\n", filepath.Join(c.dir, name), location)
+	}
+}
+
+// Uploads the file to the location and removes it.
+func (c *diagnosticsCollector) uploadFile(ctx context.Context, filePath, location string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if err := c.store.PutStream(ctx, location, c.authorization, file, info.Size()); err != nil {
+		return err
+	}
+	return os.Remove(filePath)
+}
+
+// isNewHeapDump returns true if the file is a heap dump the JVM wrote to the default name of
+// -XX:HeapDumpPath, java_pid<pid>.hprof.
+func isNewHeapDump(name string) bool {
+	return strings.HasPrefix(name, "java_pid") && strings.HasSuffix(name, ".hprof")
+}
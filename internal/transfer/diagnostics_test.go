@@ -0,0 +1,90 @@
+package transfer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/spotify/flink-on-k8s-operator/internal/objectstore"
+)
+
+func TestCollectDiagnostics(t *testing.T) {
+	var uploaded = map[string]string{}
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		uploaded[r.URL.Path] = string(body)
+	}))
+	defer server.Close()
+
+	var now = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	var writeFile = func(dir, name string, modTime time.Time) {
+		var filePath = filepath.Join(dir, name)
+		assert.NilError(t, os.WriteFile(filePath, []byte(name), 0644))
+		assert.NilError(t, os.Chtimes(filePath, modTime, modTime))
+	}
+	var listFiles = func(dir string) []string {
+		entries, _ := os.ReadDir(dir)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	// Without an upload location, the heap dumps are renamed and kept in the volume.
+	var dir = t.TempDir()
+	writeFile(dir, "java_pid1.hprof", now.Add(-time.Minute))
+	writeFile(dir, "recording-20230101T115800Z.jfr", now.Add(-time.Minute))
+	var collector = &diagnosticsCollector{dir: dir, settle: 30 * time.Second, out: io.Discard}
+	collector.collect(context.Background(), now)
+	assert.DeepEqual(t, listFiles(dir), []string{
+		"heapdump-20230101T120000Z-java_pid1.hprof",
+		"recording-20230101T115800Z.jfr",
+	})
+
+	// The settled files are uploaded and removed, the ones being written are kept.
+	dir = t.TempDir()
+	writeFile(dir, "java_pid1.hprof", now.Add(-time.Minute))
+	writeFile(dir, "recording-20230101T115800Z.jfr", now.Add(-time.Minute))
+	writeFile(dir, "recording-20230101T115959Z.jfr", now.Add(-time.Second))
+	collector = &diagnosticsCollector{
+		dir:           dir,
+		upload:        server.URL + "/diagnostics/default/mycluster/mycluster-taskmanager-0",
+		authorization: "Bearer token",
+		settle:        30 * time.Second,
+		store:         objectstore.NewClient(time.Minute),
+		out:           io.Discard,
+	}
+	collector.collect(context.Background(), now)
+	assert.DeepEqual(t, listFiles(dir), []string{"recording-20230101T115959Z.jfr"})
+	assert.DeepEqual(t, uploaded, map[string]string{
+		"/diagnostics/default/mycluster/mycluster-taskmanager-0/heapdump-20230101T120000Z-java_pid1.hprof": "java_pid1.hprof",
+		"/diagnostics/default/mycluster/mycluster-taskmanager-0/recording-20230101T115800Z.jfr":            "recording-20230101T115800Z.jfr",
+	})
+
+	// The files whose upload failed are retried on the next scan.
+	writeFile(dir, "java_pid2.hprof", now.Add(-time.Minute))
+	collector.authorization = "Bearer other"
+	collector.collect(context.Background(), now)
+	assert.DeepEqual(t, listFiles(dir), []string{
+		"heapdump-20230101T120000Z-java_pid2.hprof",
+		"recording-20230101T115959Z.jfr",
+	})
+	collector.authorization = "Bearer token"
+	collector.collect(context.Background(), now.Add(time.Minute))
+	assert.DeepEqual(t, listFiles(dir), []string(nil))
+	assert.Equal(t, len(uploaded), 4)
+}
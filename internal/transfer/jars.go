@@ -0,0 +1,128 @@
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"github.com/spotify/flink-on-k8s-operator/internal/objectstore"
+)
+
+// Jar is a JAR file to upload to the JobManager with `upload-jars`.
+type Jar struct {
+	Name string `json:"name"`
+	URI  string `json:"uri"`
+	// The expected SHA-256 checksum in hex, not checked if empty.
+	SHA256 string `json:"sha256,omitempty"`
+	// The file name to upload the JAR file as.
+	FileName string `json:"fileName"`
+}
+
+// UploadedJar is the result of the upload of a JAR file, which `upload-jars` writes to the
+// termination log as a JSON array.
+type UploadedJar struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	// The ID of the JAR file in the JobManager.
+	ID string `json:"id"`
+}
+
+// Downloads the JAR files of --jars and uploads them to the JobManager, unless a JAR file of
+// --uploaded has the same checksum. The results are written to the termination log, or the
+// error if an upload failed.
+func runUploadJars(ctx context.Context, flags *flag.FlagSet, args []string, out io.Writer) error {
+	var jobManager = flags.String("jobmanager", "", "The base URL of the REST API of the JobManager.")
+	var jarsJSON = flags.String("jars", "", "The JSON array of the JAR files to upload.")
+	var uploadedJSON = flags.String("uploaded", "{}", "The JSON object of the IDs of the JAR files uploaded to the JobManager by their SHA-256 checksums.")
+	var terminationLog = flags.String("termination-log", "/dev/termination-log", "The file to write the results to.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	var jars []Jar
+	if err := json.Unmarshal([]byte(*jarsJSON), &jars); err != nil {
+		return fmt.Errorf("invalid --jars: %v", err)
+	}
+	var uploaded map[string]string
+	if err := json.Unmarshal([]byte(*uploadedJSON), &uploaded); err != nil {
+		return fmt.Errorf("invalid --uploaded: %v", err)
+	}
+
+	var store = objectstore.NewClient(transferTimeout)
+	var flinkClient = flink.NewDefaultClient(logr.Discard())
+	var results = []UploadedJar{}
+	for _, jar := range jars {
+		result, err := uploadJar(ctx, store, flinkClient, *jobManager, jar, uploaded)
+		if err != nil {
+			writeTerminationMessage(*terminationLog, err.Error())
+			return err
+		}
+		fmt.Fprintf(out, "Uploaded JAR file %v as %v\n", jar.Name, result.ID)
+		uploaded[result.SHA256] = result.ID
+		results = append(results, *result)
+	}
+	data, _ := json.Marshal(results)
+	return writeTerminationMessage(*terminationLog, string(data))
+}
+
+func uploadJar(
+	ctx context.Context,
+	store *objectstore.Client,
+	flinkClient *flink.Client,
+	jobManager string,
+	jar Jar,
+	uploaded map[string]string) (*UploadedJar, error) {
+	file, checksum, err := downloadJar(ctx, store, jar)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	if id, ok := uploaded[checksum]; ok {
+		return &UploadedJar{Name: jar.Name, SHA256: checksum, ID: id}, nil
+	}
+	id, err := flinkClient.UploadJar(jobManager, jar.FileName, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload JAR file %v: %v", jar.Name, err)
+	}
+	return &UploadedJar{Name: jar.Name, SHA256: checksum, ID: id}, nil
+}
+
+// Downloads the JAR file to a temporary file, and returns the file rewound to its start with
+// its SHA-256 checksum. The caller must close and remove the file.
+func downloadJar(ctx context.Context, store *objectstore.Client, jar Jar) (*os.File, string, error) {
+	body, err := store.Get(ctx, jar.URI, "")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download JAR file %v: %v", jar.Name, err)
+	}
+	defer body.Close()
+
+	file, err := os.CreateTemp("", "flink-jar-*.jar")
+	if err != nil {
+		return nil, "", err
+	}
+	var hash = sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), body)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, "", fmt.Errorf("failed to download JAR file %v: %v", jar.Name, err)
+	}
+	var checksum = hex.EncodeToString(hash.Sum(nil))
+	if jar.SHA256 != "" && !strings.EqualFold(jar.SHA256, checksum) {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, "", fmt.Errorf("checksum mismatch of JAR file %v: expected sha256 %v, got %v", jar.Name, jar.SHA256, checksum)
+	}
+	return file, checksum, nil
+}
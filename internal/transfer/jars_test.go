@@ -0,0 +1,79 @@
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestUploadJars(t *testing.T) {
+	var content = []byte("PK\x03\x04wordcount")
+	var hash = sha256.Sum256(content)
+	var checksum = hex.EncodeToString(hash[:])
+	var uploads int
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wordcount.jar", "/wordcount-copy.jar":
+			w.Write(content)
+		case "/jars/upload":
+			file, header, err := r.FormFile("jarfile")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(file)
+			if string(data) != string(content) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			uploads++
+			fmt.Fprintf(w, `{"filename": "/tmp/flink-web-upload/%d_%s", "status": "success"}`, uploads, header.Filename)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var terminationLog = filepath.Join(t.TempDir(), "termination-log")
+	var jars, _ = json.Marshal([]Jar{
+		{Name: "wordcount", URI: server.URL + "/wordcount.jar", SHA256: checksum, FileName: "wordcount.jar"},
+		{Name: "copy", URI: server.URL + "/wordcount-copy.jar", FileName: "wordcount-copy.jar"},
+	})
+	var args = []string{"upload-jars", "--jobmanager", server.URL, "--jars", string(jars), "--termination-log", terminationLog}
+	assert.NilError(t, Run(context.Background(), args, io.Discard))
+
+	// The JAR files with the same checksum are uploaded once.
+	data, err := os.ReadFile(terminationLog)
+	assert.NilError(t, err)
+	var results []UploadedJar
+	assert.NilError(t, json.Unmarshal(data, &results))
+	assert.DeepEqual(t, results, []UploadedJar{
+		{Name: "wordcount", SHA256: checksum, ID: "1_wordcount.jar"},
+		{Name: "copy", SHA256: checksum, ID: "1_wordcount.jar"},
+	})
+	assert.Equal(t, uploads, 1)
+
+	jars, _ = json.Marshal([]Jar{{Name: "wordcount", URI: server.URL + "/wordcount.jar", SHA256: "00" + checksum[2:], FileName: "wordcount.jar"}})
+	args = []string{"upload-jars", "--jobmanager", server.URL, "--jars", string(jars), "--termination-log", terminationLog}
+	err = Run(context.Background(), args, io.Discard)
+	assert.ErrorContains(t, err, "checksum mismatch of JAR file wordcount")
+	data, _ = os.ReadFile(terminationLog)
+	assert.Equal(t, string(data), err.Error())
+
+	jars, _ = json.Marshal([]Jar{{Name: "missing", URI: server.URL + "/missing.jar", FileName: "missing.jar"}})
+	args = []string{"upload-jars", "--jobmanager", server.URL, "--jars", string(jars), "--termination-log", terminationLog}
+	assert.ErrorContains(t, Run(context.Background(), args, io.Discard), "failed to download JAR file missing")
+
+	assert.Error(t, Run(context.Background(), []string{"download"}, io.Discard),
+		`unknown command "download", available commands: collect-diagnostics, download-artifacts, upload-jars`)
+}
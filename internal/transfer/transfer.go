@@ -0,0 +1,67 @@
+// Package transfer implements the subcommands of the operator binary which the pods of the
+// clusters run to transfer files, e.g. `upload-jars` in the JAR uploader Job of a session
+// cluster, `download-artifacts` in the init container of the TaskManagers and
+// `collect-diagnostics` in the diagnostics collector sidecar of the JobManager and
+// TaskManagers. The files are read and written with the credentials of the pod, i.e. of the
+// service account of the cluster, rather than with those of the operator.
+package transfer
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The timeout of each transfer.
+const transferTimeout = 10 * time.Minute
+
+// The maximum size of a termination message of a container.
+const maxTerminationMessageBytes = 4096
+
+// Subcommands by name.
+var commands = map[string]func(ctx context.Context, flags *flag.FlagSet, args []string, out io.Writer) error{
+	"collect-diagnostics": runCollectDiagnostics,
+	"download-artifacts":  runDownloadArtifacts,
+	"upload-jars":         runUploadJars,
+}
+
+// IsCommand returns true if the name is a transfer subcommand.
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// Run runs the subcommand of args, e.g. `upload-jars --jobmanager <url> --jars <json>`, with
+// the output written to out.
+func Run(ctx context.Context, args []string, out io.Writer) error {
+	var run, ok = commands[args[0]]
+	if !ok {
+		var names []string
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown command %q, available commands: %v", args[0], strings.Join(names, ", "))
+	}
+	var flags = flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(out)
+	return run(ctx, flags, args[1:], out)
+}
+
+// Writes the message to the termination log of the container, which the operator reads the
+// result from, truncated to the size Kubernetes keeps. No file is written if the path is
+// empty.
+func writeTerminationMessage(path, message string) error {
+	if path == "" {
+		return nil
+	}
+	if len(message) > maxTerminationMessageBytes {
+		message = message[len(message)-maxTerminationMessageBytes:]
+	}
+	return os.WriteFile(path, []byte(message), 0644)
+}
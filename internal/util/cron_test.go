@@ -0,0 +1,66 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	for _, spec := range []string{"* * * * *", "0 2 * * 6", "*/15 1-5 1,15 * 1-5", "30 4 1-31/2 6-8 0,7"} {
+		_, err := ParseCronSchedule(spec)
+		assert.NilError(t, err, spec)
+	}
+
+	var _, err = ParseCronSchedule("0 2 * *")
+	assert.Error(t, err, "expected 5 fields, found 4")
+	_, err = ParseCronSchedule("60 2 * * *")
+	assert.Error(t, err, `minute "60" out of range 0-59`)
+	_, err = ParseCronSchedule("0 5-2 * * *")
+	assert.Error(t, err, `hour "5-2" out of range 0-23`)
+	_, err = ParseCronSchedule("0 2 0 * *")
+	assert.Error(t, err, `day of month "0" out of range 1-31`)
+	_, err = ParseCronSchedule("*/0 2 * * *")
+	assert.Error(t, err, `invalid step "0" of minute`)
+	_, err = ParseCronSchedule("0 2 * JAN *")
+	assert.Error(t, err, `invalid month "JAN"`)
+}
+
+func TestCronScheduleMatches(t *testing.T) {
+	var schedule, _ = ParseCronSchedule("0 2 * * 6")
+	// Saturday.
+	assert.Assert(t, schedule.Matches(time.Date(2022, 5, 7, 2, 0, 30, 0, time.UTC)))
+	assert.Assert(t, !schedule.Matches(time.Date(2022, 5, 7, 2, 1, 0, 0, time.UTC)))
+	assert.Assert(t, !schedule.Matches(time.Date(2022, 5, 8, 2, 0, 0, 0, time.UTC)))
+
+	// Sunday as 7.
+	schedule, _ = ParseCronSchedule("0 0 * * 7")
+	assert.Assert(t, schedule.Matches(time.Date(2022, 5, 8, 0, 0, 0, 0, time.UTC)))
+
+	// Either of the restricted day fields matches.
+	schedule, _ = ParseCronSchedule("0 0 1 * 1")
+	assert.Assert(t, schedule.Matches(time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Assert(t, schedule.Matches(time.Date(2022, 5, 2, 0, 0, 0, 0, time.UTC)))
+	assert.Assert(t, !schedule.Matches(time.Date(2022, 5, 3, 0, 0, 0, 0, time.UTC)))
+
+	// The time is matched in its location.
+	schedule, _ = ParseCronSchedule("0 2 * * *")
+	var stockholm, _ = time.LoadLocation("Europe/Stockholm")
+	assert.Assert(t, schedule.Matches(time.Date(2022, 5, 7, 0, 0, 0, 0, time.UTC).In(stockholm)))
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	var schedule, _ = ParseCronSchedule("0 2 * * 6")
+	assert.Equal(t, schedule.Next(time.Date(2022, 5, 4, 13, 27, 10, 0, time.UTC)), time.Date(2022, 5, 7, 2, 0, 0, 0, time.UTC))
+	assert.Equal(t, schedule.Next(time.Date(2022, 5, 7, 2, 0, 0, 0, time.UTC)), time.Date(2022, 5, 14, 2, 0, 0, 0, time.UTC))
+
+	schedule, _ = ParseCronSchedule("*/20 * * * *")
+	assert.Equal(t, schedule.Next(time.Date(2022, 5, 4, 13, 27, 0, 0, time.UTC)), time.Date(2022, 5, 4, 13, 40, 0, 0, time.UTC))
+
+	schedule, _ = ParseCronSchedule("0 0 29 2 *")
+	assert.Equal(t, schedule.Next(time.Date(2022, 5, 4, 0, 0, 0, 0, time.UTC)), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))
+
+	schedule, _ = ParseCronSchedule("0 0 30 2 *")
+	assert.Assert(t, schedule.Next(time.Date(2022, 5, 4, 0, 0, 0, 0, time.UTC)).IsZero())
+}
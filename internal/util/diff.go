@@ -20,3 +20,32 @@ func MapDiff[K comparable, V any](a, b map[K]V) map[K]DiffValue {
 	}
 	return c
 }
+
+// MergePatch returns the JSON merge patch (RFC 7386) which turns a into b: the changed values
+// of b, nested objects diffed recursively, and nil for the keys removed from a.
+func MergePatch(a, b map[string]any) map[string]any {
+	patch := make(map[string]any)
+	for k, bv := range b {
+		av, ok := a[k]
+		if !ok {
+			patch[k] = bv
+			continue
+		}
+		if reflect.DeepEqual(av, bv) {
+			continue
+		}
+		am, aIsMap := av.(map[string]any)
+		bm, bIsMap := bv.(map[string]any)
+		if aIsMap && bIsMap {
+			patch[k] = MergePatch(am, bm)
+		} else {
+			patch[k] = bv
+		}
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	return patch
+}
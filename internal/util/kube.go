@@ -9,6 +9,9 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
 )
 
 func GetPodLogs(clientset *kubernetes.Clientset, pod *corev1.Pod) (string, error) {
@@ -34,6 +37,40 @@ func GetPodLogs(clientset *kubernetes.Clientset, pod *corev1.Pod) (string, error
 	return str, nil
 }
 
+// ExecInPod runs the command in the container of the pod, writing its standard output to
+// stdout. The standard error is returned in the error if the command fails.
+func ExecInPod(
+	ctx context.Context,
+	config *rest.Config,
+	clientset *kubernetes.Clientset,
+	pod *corev1.Pod,
+	container string,
+	command []string,
+	stdout io.Writer) error {
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	stderr := new(bytes.Buffer)
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: stdout, Stderr: stderr})
+	if err != nil {
+		return fmt.Errorf("failed to run %v in pod %s: %v: %s", command[0], pod.Name, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return nil
+}
+
 func GetNextRevisionNumber(revisions []*appsv1.ControllerRevision) int64 {
 	count := len(revisions)
 	if count <= 0 {
@@ -47,17 +84,24 @@ func GetRevisionWithNameNumber(cr *appsv1.ControllerRevision) string {
 	return fmt.Sprintf("%v-%v", cr.Name, cr.Revision)
 }
 
-func GetNonLiveHistory(revisions []*appsv1.ControllerRevision, historyLimit int) []*appsv1.ControllerRevision {
-
-	history := append([]*appsv1.ControllerRevision{}, revisions...)
+// GetNonLiveHistory returns the oldest revisions to delete so that at most historyLimit
+// revisions are kept. The live revisions, named by live, are never deleted.
+func GetNonLiveHistory(revisions []*appsv1.ControllerRevision, historyLimit int, live ...string) []*appsv1.ControllerRevision {
 	nonLiveHistory := make([]*appsv1.ControllerRevision, 0)
-
-	historyLen := len(history)
-	if historyLen <= historyLimit {
-		return nonLiveHistory
+	excess := len(revisions) - historyLimit
+	for _, revision := range revisions {
+		if excess <= 0 {
+			break
+		}
+		var isLive bool
+		for _, name := range live {
+			isLive = isLive || revision.Name == name
+		}
+		if !isLive {
+			nonLiveHistory = append(nonLiveHistory, revision)
+			excess--
+		}
 	}
-
-	nonLiveHistory = append(nonLiveHistory, history[:(historyLen-historyLimit)]...)
 	return nonLiveHistory
 }
 
@@ -18,7 +18,11 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
@@ -26,19 +30,32 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
 	"github.com/spotify/flink-on-k8s-operator/controllers/flinkcluster"
+	"github.com/spotify/flink-on-k8s-operator/controllers/flinkclusterset"
+	"github.com/spotify/flink-on-k8s-operator/internal/cli"
+	"github.com/spotify/flink-on-k8s-operator/internal/flink"
+	"github.com/spotify/flink-on-k8s-operator/internal/notification"
+	"github.com/spotify/flink-on-k8s-operator/internal/secrets"
+	"github.com/spotify/flink-on-k8s-operator/internal/transfer"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render"
 	// +kubebuilder:scaffold:imports
 )
 
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
+
+	// The version of the operator, set at build time with -ldflags "-X main.version=<version>".
+	version = "dev"
 )
 
 var (
@@ -47,6 +64,30 @@ var (
 	leaderElectionID        = flag.String("leader-election-id", "flink-operator-lock", "The name that leader election will use for holding the leader lock")
 	watchNamespace          = flag.String("watch-namespace", "", "Watch custom resources in the namespace, ignore other namespaces. If empty, all namespaces will be watched.")
 	maxConcurrentReconciles = flag.Int("max-concurrent-reconciles", 1, "The maximum number of concurrent Reconciles which can be run. Defaults to 1.")
+	resourceQuotaCheck      = flag.String("resource-quota-check", "", "Check the resource requests of new clusters against the namespace ResourceQuotas in the validating webhook, one of Warn or Reject. Defaults to empty, no check.")
+	requireDeleteConfirm    = flag.Bool("require-deletion-confirmation", false, "Reject the deletion of running job clusters in the validating webhook unless they are annotated with flinkclusters.flinkoperator.k8s.io/confirm-deletion=true.")
+	imageArchCheck          = flag.Bool("image-architecture-check", false, "Reject clusters with spec.architecture in the validating webhook if their image is not built for the architecture. Images whose manifest cannot be read from the registry, with the credentials of the image pull secrets, are not checked.")
+	ipFamilies              = flag.String("ip-families", "", "Comma separated IP families of the services of the Kubernetes cluster, e.g. IPv4,IPv6 for dual-stack clusters, against which the validating webhook checks spec.networking.ipFamilies and ipFamilyPolicy. Defaults to empty, no check.")
+	maxRunningJobClusters   = flag.Int("max-running-job-clusters", 0, "The maximum number of job clusters running simultaneously in a namespace, counted separately per value of the flinkoperator.k8s.io/queue label. Excess clusters are queued. Defaults to 0, no limit.")
+	notificationConfig      = flag.String("notification-config", "", "Path of the YAML config of the webhooks notified of job failures, savepoint failures and degraded clusters. Defaults to empty, no notifications.")
+	flinkAPIQPS             = flag.Float64("flink-api-qps", 0, "The maximum rate of the Flink API calls to each cluster per second. Defaults to 0, no limit.")
+	flinkAPIBurst           = flag.Int("flink-api-burst", 10, "The maximum burst of the Flink API calls to each cluster, applicable with --flink-api-qps. Defaults to 10.")
+	flinkAPIMaxIdleConns    = flag.Int("flink-api-max-idle-conns-per-host", flink.DefaultTransportOptions.MaxIdleConnsPerHost, "The maximum number of idle connections kept to the Flink API of each cluster.")
+	flinkAPIH2C             = flag.Bool("flink-api-h2c", false, "Call the Flink API with HTTP/2 over cleartext (h2c). The REST endpoint of the JobManagers must support it.")
+	jobVertexStatusInterval = flag.Duration("job-vertex-status-interval", 0, "The interval of the snapshots of the back pressure and the busyness of the job vertices in the status of running jobs, e.g. 5m. Each snapshot calls the Flink API twice per vertex. Defaults to 0, no snapshots.")
+	secretStoreScopes       = flag.String("secret-store-scopes-config", "", "Path of the YAML config of the name prefixes of the secrets of each external secret store which the clusters of each namespace may resolve spec.flinkPropertiesFrom from. Defaults to empty, no secrets are resolved.")
+	secretCacheTTL          = flag.Duration("secret-cache-ttl", 5*time.Minute, "The time the secrets of external secret stores resolved for spec.flinkPropertiesFrom are cached, after which rotated secrets are picked up.")
+	vaultAddress            = flag.String("vault-address", "", "The address of the HashiCorp Vault server to resolve spec.flinkPropertiesFrom from. Defaults to empty, Vault is not configured.")
+	vaultRole               = flag.String("vault-role", "", "The role of the Vault Kubernetes auth method the operator logs in with. Defaults to empty, the token of VAULT_TOKEN is used.")
+	awsSecretsManagerRegion = flag.String("aws-secrets-manager-region", "", "The region of the AWS Secrets Manager to resolve spec.flinkPropertiesFrom from, with the credentials of the AWS_* environment variables or the web identity of IAM roles for service accounts. Defaults to empty, Secrets Manager is not configured.")
+	gcpSecretManager        = flag.Bool("gcp-secret-manager", false, "Resolve spec.flinkPropertiesFrom from GCP Secret Manager with the credentials of the service account of the operator pod.")
+	defaultImagePullSecrets = flag.String("default-image-pull-secrets", "", "Comma separated names of the image pull secrets of the pods of the clusters which do not set spec.image.pullSecrets. Defaults to empty, none.")
+	schedulingDefaults      = flag.String("scheduling-defaults-config", "", "Path of the YAML config of the default node selectors, tolerations and affinities of the JobManager, TaskManager and job submitter pods, merged under those of the spec. Defaults to empty, none.")
+	savepointStorageConfig  = flag.String("savepoint-storage-config", "", "Path of the YAML config of the rules which select the savepoints dir of the jobs by the region and zone of the node of their JobManager, over spec.job.savepointsDir. Defaults to empty, spec.job.savepointsDir is used.")
+	operatorImage           = flag.String("operator-image", "", "The image of the operator, which the JAR uploader Jobs of the session clusters, the diagnostics collectors of the pods and the downloaders of the extra artifacts of the TaskManagers run. Defaults to ghcr.io/spotify/flink-operator:<version of the operator>.")
+	readinessGateHosts      = flag.String("readiness-gate-allowed-hosts", "", "Comma separated hosts outside of the namespace of the clusters which their HTTP readiness gates may request, host names or *.<domain> patterns. Defaults to empty, only the Services of the namespace of each cluster.")
+	uiProxyImage            = flag.String("ui-proxy-image", render.DefaultUIProxyImage, "The nginx image of the read-only web UI proxies of the JobManagers and of the init containers rendering flink-conf.yaml, which use its envsubst.")
+	debugContainerImage     = flag.String("debug-container-image", flinkcluster.DefaultDebugContainerImage, "The image of the ephemeral containers injected into the JobManager and TaskManager pods with the debug user control.")
 )
 
 func init() {
@@ -57,10 +98,25 @@ func init() {
 	networkingv1.AddToScheme(scheme)
 	policyv1.AddToScheme(scheme)
 	autoscalingv2.AddToScheme(scheme)
+	rbacv1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
+	// The subcommands, e.g. `savepoint <namespace>/<name>`, operate on clusters through the
+	// API instead of running the manager.
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+	// The transfer subcommands run in the pods of the clusters, with their credentials.
+	if len(os.Args) > 1 && transfer.IsCommand(os.Args[1]) {
+		if err := transfer.Run(ctrl.SetupSignalHandler(), os.Args[1:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	opts := zap.Options{Development: true}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
@@ -82,31 +138,170 @@ func main() {
 		os.Exit(1)
 	}
 
-	reconciler, err := flinkcluster.NewReconciler(mgr)
+	reconciler, err := flinkcluster.NewReconciler(mgr, *maxRunningJobClusters)
 	if err != nil {
 		setupLog.Error(err, "Unable to create reconciler")
 		os.Exit(1)
 	}
+	reconciler.DebugContainerImage = *debugContainerImage
+	reconciler.OperatorImage = *operatorImage
+	if reconciler.OperatorImage == "" {
+		reconciler.OperatorImage = "ghcr.io/spotify/flink-operator:" + version
+	}
+	reconciler.UIProxyImage = *uiProxyImage
+	reconciler.FlinkAPIRateLimiter = flink.NewRateLimiter(*flinkAPIQPS, *flinkAPIBurst)
+	reconciler.JobVertexStatusInterval = *jobVertexStatusInterval
+	reconciler.SecretResolver, err = newSecretResolver()
+	if err != nil {
+		setupLog.Error(err, "Unable to load the secret store scopes")
+		os.Exit(1)
+	}
+	reconciler.OperatorVersion = version
+	if *readinessGateHosts != "" {
+		reconciler.ReadinessGateAllowedHosts = strings.Split(*readinessGateHosts, ",")
+	}
+	if *defaultImagePullSecrets != "" {
+		for _, name := range strings.Split(*defaultImagePullSecrets, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				reconciler.ImagePullSecrets = append(reconciler.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+			}
+		}
+	}
+	if *schedulingDefaults != "" {
+		defaults, err := render.LoadSchedulingDefaults(*schedulingDefaults)
+		if err != nil {
+			setupLog.Error(err, "Unable to load the scheduling defaults")
+			os.Exit(1)
+		}
+		reconciler.SchedulingDefaults = *defaults
+	}
+	if *savepointStorageConfig != "" {
+		config, err := flinkcluster.LoadSavepointStorageConfig(*savepointStorageConfig)
+		if err != nil {
+			setupLog.Error(err, "Unable to load the savepoint storage config")
+			os.Exit(1)
+		}
+		reconciler.SavepointStorageRules = config.Rules
+	}
+	flink.ConfigureTransport(flink.TransportOptions{
+		MaxIdleConnsPerHost: *flinkAPIMaxIdleConns,
+		IdleConnTimeout:     flink.DefaultTransportOptions.IdleConnTimeout,
+		H2C:                 *flinkAPIH2C,
+	})
+	if *notificationConfig != "" {
+		config, err := notification.LoadConfig(*notificationConfig)
+		if err == nil {
+			reconciler.Notifier, err = notification.NewNotifier(config, ctrl.Log.WithName("notification"))
+		}
+		if err != nil {
+			setupLog.Error(err, "Unable to set up notifications")
+			os.Exit(1)
+		}
+	}
 	err = reconciler.SetupWithManager(mgr, *maxConcurrentReconciles)
 	if err != nil {
 		setupLog.Error(err, "Unable to create controller", "controller", "FlinkCluster")
 		os.Exit(1)
 	}
+	if err = mgr.AddMetricsExtraHandler("/diagnostics", reconciler.Diagnostics); err != nil {
+		setupLog.Error(err, "Unable to add the diagnostics endpoint")
+		os.Exit(1)
+	}
+
+	if err = flinkclusterset.NewReconciler(mgr).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "Unable to create controller", "controller", "FlinkClusterSet")
+		os.Exit(1)
+	}
 
 	// Set up webhooks for the custom resource.
 	// Disable it with `FLINK_OPERATOR_ENABLE_WEBHOOKS=false` when we run locally.
 	if os.Getenv("FLINK_OPERATOR_ENABLE_WEBHOOKS") != "false" {
+		switch mode := v1beta1.ResourceQuotaCheckMode(*resourceQuotaCheck); mode {
+		case v1beta1.ResourceQuotaCheckDisabled:
+		case v1beta1.ResourceQuotaCheckWarn, v1beta1.ResourceQuotaCheckReject:
+			v1beta1.EnableResourceQuotaCheck(mgr.GetAPIReader(), mode)
+		default:
+			setupLog.Error(nil, "Invalid resource quota check mode", "mode", mode)
+			os.Exit(1)
+		}
+		v1beta1.EnableClusterTemplates(mgr.GetAPIReader())
+		if *requireDeleteConfirm {
+			v1beta1.RequireDeletionConfirmation()
+		}
+		if *imageArchCheck {
+			v1beta1.EnableImageArchitectureCheck(mgr.GetAPIReader(), strings.Split(*defaultImagePullSecrets, ","))
+		}
+		if *ipFamilies != "" {
+			v1beta1.EnableIPFamilyCheck(strings.Split(*ipFamilies, ","))
+		}
 		if err = (&v1beta1.FlinkCluster{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "Unable to setup webhooks", "webhook", "FlinkCluster")
 			os.Exit(1)
 		}
+		reconciler.Diagnostics.WebhookCertPath = getWebhookCertPath(mgr.GetWebhookServer())
 	}
 
 	// +kubebuilder:scaffold:builder
 
-	setupLog.Info("Starting manager")
+	setupLog.Info("Starting manager", "version", version)
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "Problem running manager")
 		os.Exit(1)
 	}
 }
+
+// Runs a subcommand with the kubeconfig of KUBECONFIG, ~/.kube/config or the in-cluster
+// config, and returns the exit code.
+func runCommand(args []string) int {
+	config, err := ctrl.GetConfig()
+	if err == nil {
+		var k8sClient client.Client
+		k8sClient, err = client.New(config, client.Options{Scheme: scheme})
+		if err == nil {
+			err = cli.Run(ctrl.SetupSignalHandler(), k8sClient, args, os.Stdout)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
+
+// Creates the resolver of the external secret stores configured with the flags, nil if none.
+func newSecretResolver() (*secrets.Resolver, error) {
+	if *vaultAddress == "" && *awsSecretsManagerRegion == "" && !*gcpSecretManager {
+		return nil, nil
+	}
+	var scopes *secrets.ScopeConfig
+	if *secretStoreScopes != "" {
+		var err error
+		if scopes, err = secrets.LoadScopeConfig(*secretStoreScopes); err != nil {
+			return nil, err
+		}
+	}
+	var resolver = secrets.NewResolver(*secretCacheTTL, scopes)
+	if *vaultAddress != "" {
+		resolver.Register(string(v1beta1.ExternalSecretProviderVault), secrets.NewVaultProvider(*vaultAddress, *vaultRole))
+	}
+	if *awsSecretsManagerRegion != "" {
+		resolver.Register(string(v1beta1.ExternalSecretProviderAWSSecretsManager), secrets.NewAWSSecretsManagerProvider(*awsSecretsManagerRegion))
+	}
+	if *gcpSecretManager {
+		resolver.Register(string(v1beta1.ExternalSecretProviderGCPSecretManager), secrets.NewGCPSecretManagerProvider())
+	}
+	return resolver, nil
+}
+
+// Gets the path of the serving certificate of the webhook server, with the defaults of the server.
+func getWebhookCertPath(server *webhook.Server) string {
+	var certDir = server.CertDir
+	if certDir == "" {
+		certDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+	}
+	var certName = server.CertName
+	if certName == "" {
+		certName = "tls.crt"
+	}
+	return filepath.Join(certDir, certName)
+}
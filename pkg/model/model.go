@@ -11,6 +11,8 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
+// Package model defines the desired state of the resources of a FlinkCluster, which the
+// operator reconciles the cluster towards and package render returns.
 package model
 
 import (
@@ -20,17 +22,23 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 )
 
 // DesiredClusterState holds desired state of a cluster.
 type DesiredClusterState struct {
 	JmStatefulSet           *appsv1.StatefulSet
 	JmService               *corev1.Service
+	JmUIService             *corev1.Service
 	JmIngress               *networkingv1.Ingress
 	TmStatefulSet           *appsv1.StatefulSet
 	TmDeployment            *appsv1.Deployment
 	TmService               *corev1.Service
 	ConfigMap               *corev1.ConfigMap
+	FlinkPropertiesSecret   *corev1.Secret
+	ServiceAccount          *corev1.ServiceAccount
+	Role                    *rbacv1.Role
+	RoleBinding             *rbacv1.RoleBinding
 	Job                     *batchv1.Job
 	PodDisruptionBudget     *policyv1.PodDisruptionBudget
 	HorizontalPodAutoscaler *autoscalingv2.HorizontalPodAutoscaler
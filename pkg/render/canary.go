@@ -0,0 +1,59 @@
+package render
+
+import (
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+)
+
+// IsCanaryUpdatePending returns true while the TaskManagers other than the canaries are
+// held back at the current revision, i.e. while the canary update of the next revision
+// is progressing or soaking.
+func IsCanaryUpdatePending(cluster *v1beta1.FlinkCluster) bool {
+	var canary = cluster.Status.CanaryUpdate
+	return cluster.Spec.CanaryUpdate != nil && canary.IsActive() &&
+		cluster.Status.Revision.IsUpdateTriggered() &&
+		canary.Revision == cluster.Status.Revision.NextRevision
+}
+
+// IsCanaryUpdateRejected returns true if the canary update of the next revision was
+// aborted or failed, in which case the update is halted and the canaries return to the
+// current revision until the spec changes.
+func IsCanaryUpdateRejected(cluster *v1beta1.FlinkCluster) bool {
+	var canary = cluster.Status.CanaryUpdate
+	return cluster.Spec.CanaryUpdate != nil && canary != nil &&
+		cluster.Status.Revision.IsUpdateTriggered() &&
+		canary.Revision == cluster.Status.Revision.NextRevision &&
+		(canary.State == v1beta1.CanaryUpdateStateAborted || canary.State == v1beta1.CanaryUpdateStateFailed)
+}
+
+// getCanaryUpdatePartition returns the partition of the TaskManager StatefulSet which
+// updates only the canaries, the pods with the highest ordinals, while the canary update
+// is pending. It returns nil otherwise.
+func getCanaryUpdatePartition(cluster *v1beta1.FlinkCluster) *int32 {
+	if !IsCanaryUpdatePending(cluster) {
+		return nil
+	}
+	var replicas int32
+	if r := cluster.Spec.TaskManager.Replicas; r != nil {
+		replicas = *r
+	}
+	var partition = replicas - cluster.Status.CanaryUpdate.Replicas
+	if partition < 0 {
+		partition = 0
+	}
+	return &partition
+}
+
+// getTaskManagerCluster returns the cluster the TaskManager StatefulSet is rendered from:
+// the cluster at its current revision while the canary update of the next revision is
+// rejected, so that the canaries return to it, the cluster itself otherwise.
+func getTaskManagerCluster(cluster *v1beta1.FlinkCluster, options Options) *v1beta1.FlinkCluster {
+	if options.CurrentRevisionSpec == nil || !IsCanaryUpdateRejected(cluster) {
+		return cluster
+	}
+	var current = cluster.DeepCopy()
+	options.CurrentRevisionSpec.DeepCopyInto(&current.Spec)
+	// The idle policy is not recorded in the revisions and scales the TaskManagers of any.
+	current.Spec.IdlePolicy = cluster.Spec.IdlePolicy.DeepCopy()
+	current.Status.Revision.NextRevision = current.Status.Revision.CurrentRevision
+	return current
+}
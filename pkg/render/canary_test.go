@@ -0,0 +1,87 @@
+package render
+
+import (
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render/rendertest"
+	"gotest.tools/v3/assert"
+)
+
+func TestGetCanaryUpdatePartition(t *testing.T) {
+	var replicas int32 = 4
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			TaskManager: &v1beta1.TaskManagerSpec{
+				DeploymentType: v1beta1.DeploymentTypeStatefulSet,
+				Replicas:       &replicas,
+			},
+			CanaryUpdate: &v1beta1.CanaryUpdateSpec{Percentage: 25, SoakSeconds: 300, ProgressDeadlineSeconds: 600},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{CurrentRevision: "cluster-85dc8f749-2", NextRevision: "cluster-aa5e3a87z-3"},
+		},
+	}
+	assert.Assert(t, getCanaryUpdatePartition(cluster) == nil)
+
+	cluster.Status.CanaryUpdate = &v1beta1.CanaryUpdateStatus{
+		Revision: "cluster-aa5e3a87z-3",
+		State:    v1beta1.CanaryUpdateStateSoaking,
+		Replicas: 1,
+	}
+	var partition int32 = 3
+	assert.DeepEqual(t, getCanaryUpdatePartition(cluster), &partition)
+
+	// The canaries return to the current revision once the update is aborted.
+	cluster.Status.CanaryUpdate.State = v1beta1.CanaryUpdateStateAborted
+	assert.Assert(t, getCanaryUpdatePartition(cluster) == nil)
+	assert.Assert(t, IsCanaryUpdateRejected(cluster))
+
+	cluster.Status.CanaryUpdate.State = v1beta1.CanaryUpdateStatePromoted
+	assert.Assert(t, getCanaryUpdatePartition(cluster) == nil)
+
+	// The canary update of a previous revision does not hold back the next one.
+	cluster.Status.CanaryUpdate.State = v1beta1.CanaryUpdateStateFailed
+	cluster.Status.Revision.NextRevision = "cluster-7f5c9d87b-4"
+	assert.Assert(t, getCanaryUpdatePartition(cluster) == nil)
+}
+
+func TestCanaryUpdateRejectedTaskManagerStatefulSet(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	cluster.Spec.Job = nil
+	cluster.Spec.CanaryUpdate = &v1beta1.CanaryUpdateSpec{Percentage: 25}
+	var currentSpec = cluster.Spec.DeepCopy()
+	cluster.Spec.Image.Name = "flink:1.8.2"
+	cluster.Status.Revision = v1beta1.RevisionStatus{CurrentRevision: "fjc-85dc8f749-1", NextRevision: "fjc-aa5e3a87z-2"}
+	cluster.Status.CanaryUpdate = &v1beta1.CanaryUpdateStatus{
+		Revision: "fjc-aa5e3a87z-2",
+		State:    v1beta1.CanaryUpdateStateSoaking,
+		Replicas: 1,
+	}
+	var options = Options{CurrentRevisionSpec: currentSpec}
+
+	// While the canaries soak, only they get the next revision.
+	var statefulSet = DesiredState(cluster, options).TmStatefulSet
+	assert.Equal(t, statefulSet.Labels[RevisionNameLabel], "fjc-aa5e3a87z")
+	assert.Equal(t, statefulSet.Spec.Template.Spec.Containers[0].Image, "flink:1.8.2")
+	assert.Assert(t, statefulSet.Spec.UpdateStrategy.RollingUpdate != nil)
+
+	// Once the canary update is aborted or failed, the StatefulSet is rendered from the
+	// current revision without partition, so that the canaries return to it.
+	for _, state := range []v1beta1.CanaryUpdateState{v1beta1.CanaryUpdateStateAborted, v1beta1.CanaryUpdateStateFailed} {
+		cluster.Status.CanaryUpdate.State = state
+		var desired = DesiredState(cluster, options)
+		statefulSet = desired.TmStatefulSet
+		assert.Equal(t, statefulSet.Labels[RevisionNameLabel], "fjc-85dc8f749")
+		assert.Equal(t, statefulSet.Spec.Template.Spec.Containers[0].Image, "flink:1.8.1")
+		assert.Assert(t, statefulSet.Spec.UpdateStrategy.RollingUpdate == nil)
+
+		// The other components stay at the next revision.
+		assert.Equal(t, desired.JmStatefulSet.Labels[RevisionNameLabel], "fjc-aa5e3a87z")
+		assert.Equal(t, desired.JmStatefulSet.Spec.Template.Spec.Containers[0].Image, "flink:1.8.2")
+	}
+
+	// The cluster is not modified.
+	assert.Equal(t, cluster.Spec.Image.Name, "flink:1.8.2")
+	assert.Equal(t, cluster.Status.Revision.NextRevision, "fjc-aa5e3a87z-2")
+}
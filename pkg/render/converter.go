@@ -0,0 +1,3099 @@
+/*
+Copyright 2019 Google LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/transfer"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/model"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/hashicorp/go-version"
+)
+
+// Converter which converts the FlinkCluster spec to the desired
+// underlying Kubernetes resource specs.
+
+const (
+	preStopSleepSeconds     = 30
+	flinkConfigMapPath      = "/opt/flink/conf"
+	flinkConfigMapVolume    = "flink-config-volume"
+	submitJobScriptPath     = "/opt/flink-operator/submit-job.sh"
+	gcpServiceAccountVolume = "gcp-service-account-volume"
+	hadoopConfigVolume      = "hadoop-config-volume"
+	jobManagerAddrEnvVar    = "FLINK_JM_ADDR"
+	jobJarUriEnvVar         = "FLINK_JOB_JAR_URI"
+	jobJarFileEnvVar        = "FLINK_JOB_JAR_FILE"
+	jobPyFileUriEnvVar      = "FLINK_JOB_PY_FILE_URI"
+	jobPyFilesUriEnvVar     = "FLINK_JOB_PY_FILES_URI"
+	clusterNameEnvVar       = "FLINK_CLUSTER_NAME"
+	clusterNamespaceEnvVar  = "FLINK_CLUSTER_NAMESPACE"
+	clusterRevisionEnvVar   = "FLINK_CLUSTER_REVISION"
+	savepointPathEnvVar     = "FLINK_SAVEPOINT_PATH"
+	hadoopConfDirEnvVar     = "HADOOP_CONF_DIR"
+	gacEnvVar               = "GOOGLE_APPLICATION_CREDENTIALS"
+	oauth2ProxyName         = "oauth2-proxy"
+	oauth2ProxyDefaultImage = "quay.io/oauth2-proxy/oauth2-proxy:v7.4.0"
+	oauth2ProxyDefaultPort  = 4180
+	uiProxyName             = "ui-proxy"
+	uiProxyConfigKey        = "ui-proxy.conf"
+	artifactCacheVolume     = "artifact-cache-volume"
+	artifactCachePath       = "/opt/flink-operator/artifact-cache"
+	gitSyncName             = "git-sync"
+	gitSyncDefaultImage     = "registry.k8s.io/git-sync/git-sync:v4.2.4"
+	gitRepoVolume           = "git-repo-volume"
+	gitRepoPath             = "/opt/flink-operator/git-repo"
+	gitRepoLink             = "current"
+	gitSecretVolume         = "git-secret-volume"
+	gitSecretPath           = "/etc/git-secret"
+	diagnosticsVolume       = "diagnostics-volume"
+	diagnosticsCollector    = "diagnostics-collector"
+	logVolume               = "log-volume"
+	logPath                 = "/opt/flink/log"
+	logSidecarOutputVolume  = "log-forwarder-output-volume"
+	logSidecarOutputPath    = "/opt/flink-operator/log-forwarder"
+	flinkConfigTemplatePath = "/opt/flink-operator/conf-template"
+	renderedConfigVolume    = "flink-rendered-config-volume"
+	renderConfigName        = "render-flink-config"
+	flinkPropertiesFromVol  = "flink-properties-from-volume"
+	flinkPropertiesFromPath = "/opt/flink-operator/properties-from"
+	flinkConfigOverrideVol  = "flink-config-override-volume"
+	flinkConfigOverridePath = "/opt/flink-operator/conf-override"
+	caBundleVolume          = "ca-bundle-volume"
+	caBundlePath            = "/opt/flink-operator/ca-bundle"
+	truststoreVolume        = "truststore-volume"
+	truststorePath          = "/opt/flink-operator/truststore"
+	truststorePassword      = "changeit"
+	importCABundleName      = "import-ca-bundle"
+	jobArgEnvVarPrefix      = "FLINK_JOB_ARG_"
+	jobSecretArgsEnvVar     = "FLINK_JOB_SECRET_ARGS"
+	flinkHomePath           = "/opt/flink"
+	extraArtifactsName      = "extra-artifacts"
+	extraArtifactsVolume    = "extra-artifacts-volume"
+	extraArtifactsPath      = "/opt/flink-operator/extra-artifacts"
+	flinkPluginsName        = "flink-plugins"
+	flinkPluginsVolume      = "flink-plugins-volume"
+	flinkPluginsPath        = "/opt/flink-operator/plugins"
+	jobManagerStoragePath   = "/opt/flink-operator/jobmanager-storage"
+)
+
+var (
+	backoffLimit                  int32 = 0
+	terminationGracePeriodSeconds int64 = 60
+	flinkSysProps                       = map[string]struct{}{
+		"jobmanager.rpc.address": {},
+		"jobmanager.rpc.port":    {},
+		"blob.server.port":       {},
+		"query.server.port":      {},
+		"rest.port":              {},
+	}
+	v10, _ = version.NewVersion("1.10")
+	// Metrics reporters are configured with their factories since Flink 1.11.
+	v11, _ = version.NewVersion("1.11")
+	// Annotations which make the cloud provider create an internal load balancer.
+	internalLoadBalancerAnnotations = map[string]map[string]string{
+		v1beta1.LoadBalancerProviderGCP: {
+			"networking.gke.io/load-balancer-type": "Internal",
+		},
+		v1beta1.LoadBalancerProviderAWS: {
+			"service.beta.kubernetes.io/aws-load-balancer-internal": "true",
+			"service.beta.kubernetes.io/aws-load-balancer-scheme":   "internal",
+		},
+		v1beta1.LoadBalancerProviderAzure: {
+			"service.beta.kubernetes.io/azure-load-balancer-internal": "true",
+		},
+	}
+)
+
+// ImagePullSecrets returns the image pull secrets of all the pods of the cluster, whose init
+// containers and sidecars are pulled with them as well, the defaults if spec.image.pullSecrets
+// is not set.
+func ImagePullSecrets(flinkCluster *v1beta1.FlinkCluster, defaults []corev1.LocalObjectReference) []corev1.LocalObjectReference {
+	if len(flinkCluster.Spec.Image.PullSecrets) > 0 {
+		return flinkCluster.Spec.Image.PullSecrets
+	}
+	return defaults
+}
+
+// DefaultUIProxyImage is the nginx image of the read-only web UI proxies and of the init
+// containers rendering flink-conf.yaml, which use its envsubst.
+const DefaultUIProxyImage = "nginxinc/nginx-unprivileged:1.25-alpine"
+
+func (options Options) getUIProxyImage() string {
+	if options.UIProxyImage != "" {
+		return options.UIProxyImage
+	}
+	return DefaultUIProxyImage
+}
+
+// DesiredState returns the desired state of the cluster with the settings of the operator
+// in the options, none if the cluster is nil, i.e. has been deleted. Unlike
+// RenderDesiredState, the cluster is neither defaulted nor validated and must have a revision.
+func DesiredState(cluster *v1beta1.FlinkCluster, options Options) *model.DesiredClusterState {
+	state := &model.DesiredClusterState{}
+	// The cluster has been deleted, all resources should be cleaned up.
+	if cluster == nil {
+		return state
+	}
+
+	jobSpec := cluster.Spec.Job
+	applicationMode := IsApplicationModeCluster(cluster)
+	components := cluster.Spec.Components
+
+	if !ShouldCleanup(cluster, "ConfigMap") {
+		if components.ShouldCreateConfigMap() {
+			state.ConfigMap = newConfigMap(cluster)
+		}
+		if options.FlinkPropertiesFrom != nil {
+			state.FlinkPropertiesSecret = newFlinkPropertiesSecret(cluster, options.FlinkPropertiesFrom)
+		}
+	}
+
+	if !ShouldCleanup(cluster, "ServiceAccount") {
+		state.ServiceAccount = newHAServiceAccount(cluster)
+	}
+
+	if !ShouldCleanup(cluster, "Role") {
+		state.Role = newHARole(cluster)
+	}
+
+	if !ShouldCleanup(cluster, "RoleBinding") {
+		state.RoleBinding = newHARoleBinding(cluster)
+	}
+
+	if !ShouldCleanup(cluster, "PodDisruptionBudget") && components.ShouldCreatePDB() {
+		state.PodDisruptionBudget = newPodDisruptionBudget(cluster)
+	}
+
+	if !ShouldCleanup(cluster, "HorizontalPodAutoscaler") {
+		state.HorizontalPodAutoscaler = newHorizontalPodAutoscaler(cluster)
+	}
+
+	if !ShouldCleanup(cluster, "JobManager") && !applicationMode {
+		state.JmStatefulSet = newJobManagerStatefulSet(cluster, options)
+	}
+
+	if !ShouldCleanup(cluster, "TaskManager") && !IsTaskManagerExternal(cluster) {
+		switch cluster.Spec.TaskManager.DeploymentType {
+		case v1beta1.DeploymentTypeStatefulSet:
+			state.TmStatefulSet = newTaskManagerStatefulSet(getTaskManagerCluster(cluster, options), options)
+		case v1beta1.DeploymentTypeDeployment:
+			state.TmDeployment = newTaskManagerDeployment(cluster, options)
+		}
+	}
+	if !ShouldCleanup(cluster, "TaskManagerService") && components.ShouldCreateTmService() {
+		state.TmService = newTaskManagerService(cluster)
+	}
+
+	if !ShouldCleanup(cluster, "JobManagerService") && components.ShouldCreateJmService() {
+		state.JmService = newJobManagerService(cluster)
+		state.JmUIService = newJobManagerUIService(cluster)
+	}
+
+	if !ShouldCleanup(cluster, "JobManagerIngress") && components.ShouldCreateIngress() {
+		state.JmIngress = newJobManagerIngress(cluster)
+	}
+
+	if jobSpec != nil {
+		jobStatus := cluster.Status.Components.Job
+
+		keepJobState := (ShouldStopJob(cluster) || jobStatus.IsStopped()) &&
+			(!options.JobUpdate && !jobStatus.ShouldRestart(jobSpec)) &&
+			ShouldCleanup(cluster, "Job")
+
+		if !keepJobState {
+			state.Job = newJob(cluster, options)
+		}
+	}
+
+	if cluster.Spec.Diagnostics != nil && options.OperatorImage != "" {
+		setDiagnosticsCollector(state, cluster, options.OperatorImage)
+	}
+
+	if options.ReferencedConfigHash != "" {
+		setReferencedConfigHashAnnotation(state, options.ReferencedConfigHash)
+	}
+
+	return state
+}
+
+// Adds the sidecar which collects the files of the diagnostics volume to the JobManager and
+// TaskManager pods, see `collect-diagnostics` of package transfer. It uploads the files to
+// spec.diagnostics.upload with the environment and the volumes of the main container, i.e.
+// with the credentials of the cluster. The JobManager of application mode runs in a Job,
+// which would not complete with the sidecar.
+func setDiagnosticsCollector(state *model.DesiredClusterState, cluster *v1beta1.FlinkCluster, image string) {
+	var templates []*corev1.PodTemplateSpec
+	if state.JmStatefulSet != nil {
+		templates = append(templates, &state.JmStatefulSet.Spec.Template)
+	}
+	if state.TmStatefulSet != nil {
+		templates = append(templates, &state.TmStatefulSet.Spec.Template)
+	}
+	if state.TmDeployment != nil {
+		templates = append(templates, &state.TmDeployment.Spec.Template)
+	}
+	for _, template := range templates {
+		var podSpec = &template.Spec
+		var container = newDiagnosticsCollectorContainer(cluster, podSpec.Containers[0], image)
+		podSpec.Containers = append(podSpec.Containers, container)
+	}
+}
+
+func newDiagnosticsCollectorContainer(
+	cluster *v1beta1.FlinkCluster, mainContainer corev1.Container, image string) corev1.Container {
+	var args = []string{"collect-diagnostics", "--dir", DiagnosticsPath}
+	var envVars = append(append([]corev1.EnvVar{}, mainContainer.Env...), corev1.EnvVar{
+		Name:      "POD_NAME",
+		ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}},
+	})
+	if upload := cluster.Spec.Diagnostics.Upload; upload != nil {
+		// The collector uploads the files to `<uri>/<namespace>/<cluster>/<pod>/<file>`.
+		var location = strings.TrimSuffix(upload.URI, "/") + "/" + path.Join(cluster.Namespace, cluster.Name, "$(POD_NAME)")
+		args = append(args, "--upload", location)
+		if upload.AuthorizationSecret != nil {
+			envVars = append(envVars, corev1.EnvVar{
+				Name:      transfer.DiagnosticsAuthorizationEnvVar,
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: upload.AuthorizationSecret},
+			})
+		}
+	}
+	return corev1.Container{
+		Name:         diagnosticsCollector,
+		Image:        image,
+		Args:         args,
+		Env:          envVars,
+		EnvFrom:      mainContainer.EnvFrom,
+		VolumeMounts: mainContainer.VolumeMounts,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("32Mi"),
+			},
+		},
+	}
+}
+
+// Records the hash of the referenced ConfigMaps and Secrets the pods are created with.
+func setReferencedConfigHashAnnotation(state *model.DesiredClusterState, hash string) {
+	var templates []*corev1.PodTemplateSpec
+	if state.JmStatefulSet != nil {
+		templates = append(templates, &state.JmStatefulSet.Spec.Template)
+	}
+	if state.TmStatefulSet != nil {
+		templates = append(templates, &state.TmStatefulSet.Spec.Template)
+	}
+	if state.TmDeployment != nil {
+		templates = append(templates, &state.TmDeployment.Spec.Template)
+	}
+	if state.Job != nil {
+		templates = append(templates, &state.Job.Spec.Template)
+	}
+	for _, template := range templates {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[ReferencedConfigHashAnnotation] = hash
+	}
+}
+
+func newJobManagerContainer(flinkCluster *v1beta1.FlinkCluster) *corev1.Container {
+	var clusterSpec = flinkCluster.Spec
+	var imageSpec = clusterSpec.Image
+	var jobManagerSpec = clusterSpec.JobManager
+	var rpcPort = corev1.ContainerPort{Name: "rpc", ContainerPort: *jobManagerSpec.Ports.RPC}
+	var blobPort = corev1.ContainerPort{Name: "blob", ContainerPort: *jobManagerSpec.Ports.Blob}
+	var queryPort = corev1.ContainerPort{Name: "query", ContainerPort: *jobManagerSpec.Ports.Query}
+	var uiPort = corev1.ContainerPort{Name: "ui", ContainerPort: *jobManagerSpec.Ports.UI}
+	var ports = []corev1.ContainerPort{rpcPort, blobPort, queryPort, uiPort}
+	for _, port := range jobManagerSpec.ExtraPorts {
+		ports = append(ports, corev1.ContainerPort{Name: port.Name, ContainerPort: port.ContainerPort, Protocol: corev1.Protocol(port.Protocol)})
+	}
+	ports = appendMetricsPort(flinkCluster, ports)
+
+	container := &corev1.Container{
+		Name:            "jobmanager",
+		Image:           imageSpec.Name,
+		ImagePullPolicy: imageSpec.PullPolicy,
+		Args:            []string{"jobmanager"},
+		Ports:           ports,
+		LivenessProbe:   jobManagerSpec.LivenessProbe,
+		ReadinessProbe:  jobManagerSpec.ReadinessProbe,
+		Resources:       jobManagerSpec.Resources,
+		Env:             getEnvVars(flinkCluster),
+		EnvFrom:         flinkCluster.Spec.EnvFrom,
+		VolumeMounts:    jobManagerSpec.VolumeMounts,
+		Lifecycle: &corev1.Lifecycle{
+			PreStop: &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{
+					Command: []string{"sleep", strconv.Itoa(preStopSleepSeconds)},
+				},
+			},
+		},
+	}
+
+	if IsApplicationModeCluster(flinkCluster) {
+		jobSpec := flinkCluster.Spec.Job
+		status := flinkCluster.Status
+		args := []string{"standalone-job"}
+		if parallelism, err := calJobParallelism(flinkCluster); err == nil {
+			args = append(args, fmt.Sprintf("-Dparallelism.default=%d", parallelism))
+		}
+
+		var fromSavepoint = FromSavepoint(jobSpec, status.Components.Job, &status.Revision)
+		if fromSavepoint != nil {
+			args = append(args, "--fromSavepoint", *fromSavepoint)
+		}
+		container.Env = append(getJobEnvVars(flinkCluster, fromSavepoint), container.Env...)
+
+		if jobSpec.AllowNonRestoredState != nil && *jobSpec.AllowNonRestoredState {
+			args = append(args, "--allowNonRestoredState")
+		}
+
+		jobId, _ := GenJobId(flinkCluster)
+		args = append(args,
+			"--job-id", jobId,
+			"--job-classname", *jobSpec.ClassName,
+		)
+
+		args = append(args, jobSpec.Args...)
+		argsFrom, argEnvVars := getJobArgsFrom(jobSpec)
+		container.Args = append(args, argsFrom...)
+		container.Env = append(argEnvVars, container.Env...)
+	}
+	setMetricsReporterSecrets(flinkCluster, container)
+
+	return container
+}
+
+func newJobManagerPodSpec(
+	mainContainer *corev1.Container,
+	flinkCluster *v1beta1.FlinkCluster,
+	options Options) *corev1.PodSpec {
+	var clusterSpec = flinkCluster.Spec
+	var jobManagerSpec = clusterSpec.JobManager
+
+	var podSpec = &corev1.PodSpec{
+		InitContainers:                convertContainers(jobManagerSpec.InitContainers, []corev1.VolumeMount{}, getEnvVars(flinkCluster)),
+		Containers:                    []corev1.Container{*mainContainer},
+		Volumes:                       jobManagerSpec.Volumes,
+		Affinity:                      jobManagerSpec.Affinity,
+		NodeSelector:                  jobManagerSpec.NodeSelector,
+		Tolerations:                   jobManagerSpec.Tolerations,
+		ImagePullSecrets:              ImagePullSecrets(flinkCluster, options.ImagePullSecrets),
+		SecurityContext:               jobManagerSpec.SecurityContext,
+		HostAliases:                   jobManagerSpec.HostAliases,
+		ServiceAccountName:            ServiceAccountName(flinkCluster),
+		TerminationGracePeriodSeconds: getTerminationGracePeriodSeconds(jobManagerSpec.TerminationGracePeriodSeconds),
+	}
+	setFlinkPlugins(flinkCluster, podSpec)
+	setRenderedFlinkConfig(flinkCluster, options.getUIProxyImage(), podSpec)
+	setFlinkConfig(flinkCluster, podSpec)
+	setHadoopConfig(flinkCluster.Spec.HadoopConfig, podSpec)
+	setGCPConfig(flinkCluster.Spec.GCPConfig, podSpec)
+	setStateEncryption(flinkCluster.Spec.StateEncryption, podSpec)
+	setNetworking(flinkCluster, podSpec)
+	setDiagnostics(flinkCluster.Spec.Diagnostics, podSpec)
+	setSchedulingDefaults(options.SchedulingDefaults.JobManager, podSpec)
+	setArchitectureAffinity(flinkCluster.Spec.Architecture, podSpec)
+	// The JobManager of application mode runs in a Job, which would not complete with the sidecar.
+	if !IsApplicationModeCluster(flinkCluster) {
+		setLogSidecar(flinkCluster, "jobmanager", podSpec)
+	}
+	var upstreamPort = *jobManagerSpec.Ports.UI
+	if jobManagerSpec.IsReadOnlyUI() {
+		podSpec.Containers = append(podSpec.Containers, newUIProxyContainer(options.getUIProxyImage()))
+		upstreamPort = v1beta1.ReadOnlyUIProxyPort
+	}
+	if oauth2Proxy := getOAuth2ProxySpec(flinkCluster); oauth2Proxy != nil {
+		podSpec.Containers = append(podSpec.Containers, newOAuth2ProxyContainer(oauth2Proxy, upstreamPort))
+	}
+	podSpec.Containers = append(podSpec.Containers, jobManagerSpec.Sidecars...)
+
+	return podSpec
+}
+
+// Gets the desired JobManager StatefulSet spec from the FlinkCluster spec.
+func newJobManagerStatefulSet(flinkCluster *v1beta1.FlinkCluster, options Options) *appsv1.StatefulSet {
+	var jobManagerSpec = flinkCluster.Spec.JobManager
+	var jobManagerStatefulSetName = JobManagerName(flinkCluster.Name)
+	var podLabels = ComponentLabels(flinkCluster, "jobmanager")
+	podLabels = MergeLabels(podLabels, jobManagerSpec.PodLabels)
+	var statefulSetLabels = MergeLabels(podLabels, getRevisionHashLabels(&flinkCluster.Status.Revision))
+
+	mainContainer := newJobManagerContainer(flinkCluster)
+	podSpec := newJobManagerPodSpec(mainContainer, flinkCluster, options)
+
+	setJobManagerStorage(jobManagerSpec.Storage, podSpec)
+
+	var pvcs []corev1.PersistentVolumeClaim
+	if jobManagerSpec.VolumeClaimTemplates != nil {
+		pvcs = make([]corev1.PersistentVolumeClaim, len(jobManagerSpec.VolumeClaimTemplates))
+		for i, pvc := range jobManagerSpec.VolumeClaimTemplates {
+			// The retained storage claim is not garbage collected with the cluster.
+			if !isJobManagerStorageRetained(jobManagerSpec.Storage, pvc.Name) {
+				pvc.OwnerReferences = []metav1.OwnerReference{ToOwnerReference(flinkCluster)}
+			}
+			pvcs[i] = pvc
+		}
+	}
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       flinkCluster.Namespace,
+			Name:            jobManagerStatefulSetName,
+			OwnerReferences: []metav1.OwnerReference{ToOwnerReference(flinkCluster)},
+			Labels:          statefulSetLabels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:             getIdleReplicas(flinkCluster, "jobmanager", jobManagerSpec.Replicas),
+			Selector:             &metav1.LabelSelector{MatchLabels: podLabels},
+			ServiceName:          jobManagerStatefulSetName,
+			VolumeClaimTemplates: pvcs,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      podLabels,
+					Annotations: getJobManagerPodAnnotations(flinkCluster),
+				},
+				Spec: *podSpec,
+			},
+		},
+	}
+}
+
+// Gets the application protocol of the port in the services, nil if it is not set.
+func getAppProtocol(appProtocols map[string]string, portName string) *string {
+	if appProtocol, ok := appProtocols[portName]; ok {
+		return &appProtocol
+	}
+	return nil
+}
+
+// Gets the service ports of the extra ports with an application protocol, the other extra
+// ports are only exposed on the pods.
+func getExtraServicePorts(extraPorts []v1beta1.NamedPort) []corev1.ServicePort {
+	var ports []corev1.ServicePort
+	for _, port := range extraPorts {
+		if port.AppProtocol == nil {
+			continue
+		}
+		ports = append(ports, corev1.ServicePort{
+			Name:        port.Name,
+			Protocol:    corev1.Protocol(port.Protocol),
+			Port:        port.ContainerPort,
+			TargetPort:  intstr.FromString(port.Name),
+			AppProtocol: port.AppProtocol,
+		})
+	}
+	return ports
+}
+
+// Gets the desired JobManager service spec from a cluster spec.
+func newJobManagerService(flinkCluster *v1beta1.FlinkCluster) *corev1.Service {
+	var clusterNamespace = flinkCluster.Namespace
+	var clusterName = flinkCluster.Name
+	var jobManagerSpec = flinkCluster.Spec.JobManager
+	var appProtocols = jobManagerSpec.Ports.AppProtocols
+	var rpcPort = corev1.ServicePort{
+		Name:        "rpc",
+		Port:        *jobManagerSpec.Ports.RPC,
+		TargetPort:  intstr.FromString("rpc"),
+		AppProtocol: getAppProtocol(appProtocols, "rpc")}
+	var blobPort = corev1.ServicePort{
+		Name:        "blob",
+		Port:        *jobManagerSpec.Ports.Blob,
+		TargetPort:  intstr.FromString("blob"),
+		AppProtocol: getAppProtocol(appProtocols, "blob")}
+	var queryPort = corev1.ServicePort{
+		Name:        "query",
+		Port:        *jobManagerSpec.Ports.Query,
+		TargetPort:  intstr.FromString("query"),
+		AppProtocol: getAppProtocol(appProtocols, "query")}
+	var uiPort = corev1.ServicePort{
+		Name:        "ui",
+		Port:        *jobManagerSpec.Ports.UI,
+		TargetPort:  intstr.FromString("ui"),
+		AppProtocol: getAppProtocol(appProtocols, "ui")}
+	var jobManagerServiceName = JobManagerServiceName(clusterName)
+	selectorLabels := ComponentLabels(flinkCluster, "jobmanager")
+	serviceLabels := MergeLabels(selectorLabels, getRevisionHashLabels(&flinkCluster.Status.Revision))
+	serviceLabels = MergeLabels(serviceLabels, jobManagerSpec.ServiceLabels)
+	var serviceAnnotations = jobManagerSpec.ServiceAnnotations
+
+	var jobManagerService = &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: clusterNamespace,
+			Name:      jobManagerServiceName,
+			OwnerReferences: []metav1.OwnerReference{
+				ToOwnerReference(flinkCluster)},
+			Labels:      serviceLabels,
+			Annotations: serviceAnnotations,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: selectorLabels,
+			Ports: append([]corev1.ServicePort{rpcPort, blobPort, queryPort, uiPort},
+				getExtraServicePorts(jobManagerSpec.ExtraPorts)...),
+		},
+	}
+	if jobManagerSpec.IsReadOnlyUI() {
+		jobManagerService.Spec.Ports = append(jobManagerService.Spec.Ports, corev1.ServicePort{
+			Name:       uiProxyName,
+			Port:       v1beta1.ReadOnlyUIProxyPort,
+			TargetPort: intstr.FromString(uiProxyName)})
+	}
+	if oauth2Proxy := getOAuth2ProxySpec(flinkCluster); oauth2Proxy != nil {
+		jobManagerService.Spec.Ports = append(jobManagerService.Spec.Ports, corev1.ServicePort{
+			Name:       oauth2ProxyName,
+			Port:       getOAuth2ProxyPort(oauth2Proxy),
+			TargetPort: intstr.FromString(oauth2ProxyName)})
+	}
+	// This implementation is specific to GKE, see details at
+	// https://cloud.google.com/kubernetes-engine/docs/how-to/exposing-apps
+	// https://cloud.google.com/kubernetes-engine/docs/how-to/internal-load-balancing
+	switch jobManagerSpec.AccessScope {
+	case v1beta1.AccessScopeCluster:
+		jobManagerService.Spec.Type = corev1.ServiceTypeClusterIP
+	case v1beta1.AccessScopeVPC:
+		jobManagerService.Spec.Type = corev1.ServiceTypeLoadBalancer
+		jobManagerService.Annotations = MergeLabels(serviceAnnotations,
+			map[string]string{
+				"networking.gke.io/load-balancer-type":                         "Internal",
+				"networking.gke.io/internal-load-balancer-allow-global-access": "true",
+			})
+	case v1beta1.AccessScopeExternal:
+		// A load balancer exposes every port of the service, the read-only UI proxy is exposed
+		// by the UI service instead.
+		if ShouldSplitJobManagerUIService(flinkCluster) {
+			jobManagerService.Spec.Type = corev1.ServiceTypeClusterIP
+		} else {
+			jobManagerService.Spec.Type = corev1.ServiceTypeLoadBalancer
+		}
+	case v1beta1.AccessScopeInternalLB:
+		jobManagerService.Spec.Type = corev1.ServiceTypeLoadBalancer
+		// User annotations take precedence over the provider presets.
+		jobManagerService.Annotations = MergeLabels(
+			internalLoadBalancerAnnotations[jobManagerSpec.LoadBalancerProvider], serviceAnnotations)
+	case v1beta1.AccessScopeNodePort:
+		jobManagerService.Spec.Type = corev1.ServiceTypeNodePort
+	case v1beta1.AccessScopeHeadless:
+		// Headless services do not allocate any sort of VIP or LoadBalancer, and merely
+		// collect a set of Pod IPs that are assumed to be independently routable:
+		jobManagerService.Spec.Type = corev1.ServiceTypeClusterIP
+		jobManagerService.Spec.ClusterIP = "None"
+	default:
+		panic(fmt.Sprintf(
+			"Unknown service access cope: %v", jobManagerSpec.AccessScope))
+	}
+	// The ingress publishes the hostname if it is set. User annotations take precedence.
+	if dnsAnnotations := getExternalDNSAnnotations(flinkCluster); dnsAnnotations != nil &&
+		jobManagerSpec.Ingress == nil && !ShouldSplitJobManagerUIService(flinkCluster) {
+		jobManagerService.Annotations = MergeLabels(dnsAnnotations, jobManagerService.Annotations)
+	}
+	setServiceIPFamilies(flinkCluster, jobManagerService)
+	return jobManagerService
+}
+
+// ShouldSplitJobManagerUIService returns whether the read-only UI proxy is exposed by its own
+// load balancer, so that the writable UI and REST port is not reachable from outside the
+// cluster.
+func ShouldSplitJobManagerUIService(flinkCluster *v1beta1.FlinkCluster) bool {
+	var jobManagerSpec = flinkCluster.Spec.JobManager
+	return jobManagerSpec != nil && jobManagerSpec.IsReadOnlyUI() &&
+		jobManagerSpec.AccessScope == v1beta1.AccessScopeExternal
+}
+
+// Gets the desired service which exposes only the read-only UI proxy of the JobManager to
+// the external access scope, nil if the JobManager service is exposed as is.
+func newJobManagerUIService(flinkCluster *v1beta1.FlinkCluster) *corev1.Service {
+	if !ShouldSplitJobManagerUIService(flinkCluster) {
+		return nil
+	}
+	var jobManagerSpec = flinkCluster.Spec.JobManager
+	selectorLabels := ComponentLabels(flinkCluster, "jobmanager")
+	serviceLabels := MergeLabels(selectorLabels, getRevisionHashLabels(&flinkCluster.Status.Revision))
+	serviceLabels = MergeLabels(serviceLabels, jobManagerSpec.ServiceLabels)
+	var serviceAnnotations = jobManagerSpec.ServiceAnnotations
+	// The ingress publishes the hostname if it is set. User annotations take precedence.
+	if dnsAnnotations := getExternalDNSAnnotations(flinkCluster); dnsAnnotations != nil && jobManagerSpec.Ingress == nil {
+		serviceAnnotations = MergeLabels(dnsAnnotations, serviceAnnotations)
+	}
+	var uiService = &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: flinkCluster.Namespace,
+			Name:      JobManagerUIServiceName(flinkCluster.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				ToOwnerReference(flinkCluster)},
+			Labels:      serviceLabels,
+			Annotations: serviceAnnotations,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeLoadBalancer,
+			Selector: selectorLabels,
+			Ports: []corev1.ServicePort{{
+				Name:       uiProxyName,
+				Port:       v1beta1.ReadOnlyUIProxyPort,
+				TargetPort: intstr.FromString(uiProxyName)}},
+		},
+	}
+	setServiceIPFamilies(flinkCluster, uiService)
+	return uiService
+}
+
+// Gets the desired JobManager ingress spec from a cluster spec.
+func newJobManagerIngress(
+	flinkCluster *v1beta1.FlinkCluster) *networkingv1.Ingress {
+	var jobManagerIngressSpec = flinkCluster.Spec.JobManager.Ingress
+	if jobManagerIngressSpec == nil {
+		return nil
+	}
+
+	var clusterNamespace = flinkCluster.Namespace
+	var clusterName = flinkCluster.Name
+	var jobManagerServiceName = JobManagerServiceName(clusterName)
+	var ingressName = JobManagerIngressName(clusterName)
+	var ingressAnnotations = jobManagerIngressSpec.Annotations
+	var ingressHost string
+	var ingressTLS []networkingv1.IngressTLS
+	var labels = MergeLabels(
+		ComponentLabels(flinkCluster, "jobmanager"),
+		getRevisionHashLabels(&flinkCluster.Status.Revision))
+	var pathType = networkingv1.PathTypePrefix
+	var backendPortName, _ = JobManagerUIPort(flinkCluster)
+	if auth := jobManagerIngressSpec.Auth; auth != nil && auth.ExternalAuth != nil {
+		var authAnnotations = map[string]string{
+			"nginx.ingress.kubernetes.io/auth-url": auth.ExternalAuth.URL,
+		}
+		if auth.ExternalAuth.SignInURL != nil {
+			authAnnotations["nginx.ingress.kubernetes.io/auth-signin"] = *auth.ExternalAuth.SignInURL
+		}
+		// User annotations take precedence.
+		ingressAnnotations = MergeLabels(authAnnotations, ingressAnnotations)
+	}
+	if dnsAnnotations := getExternalDNSAnnotations(flinkCluster); dnsAnnotations != nil {
+		// User annotations take precedence.
+		ingressAnnotations = MergeLabels(dnsAnnotations, ingressAnnotations)
+	}
+	if jobManagerIngressSpec.HostFormat != nil {
+		ingressHost = JobManagerIngressHost(*jobManagerIngressSpec.HostFormat, clusterName)
+	}
+	if jobManagerIngressSpec.UseTLS != nil && *jobManagerIngressSpec.UseTLS {
+		var secretName string
+		var hosts []string
+		if ingressHost != "" {
+			hosts = []string{ingressHost}
+		}
+		if jobManagerIngressSpec.TLSSecretName != nil {
+			secretName = *jobManagerIngressSpec.TLSSecretName
+		}
+		if hosts != nil || secretName != "" {
+			ingressTLS = []networkingv1.IngressTLS{{
+				Hosts:      hosts,
+				SecretName: secretName,
+			}}
+		}
+	}
+	var jobManagerIngress = &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: clusterNamespace,
+			Name:      ingressName,
+			OwnerReferences: []metav1.OwnerReference{
+				ToOwnerReference(flinkCluster)},
+			Labels:      labels,
+			Annotations: ingressAnnotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			TLS: ingressTLS,
+			Rules: []networkingv1.IngressRule{{
+				Host: ingressHost,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: jobManagerServiceName,
+									Port: networkingv1.ServiceBackendPort{
+										Name: backendPortName,
+									},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+
+	return jobManagerIngress
+}
+
+// Gets the oauth2-proxy spec of the JobManager ingress, nil if not enabled.
+func getOAuth2ProxySpec(flinkCluster *v1beta1.FlinkCluster) *v1beta1.OAuth2ProxySpec {
+	var ingressSpec = flinkCluster.Spec.JobManager.Ingress
+	if ingressSpec == nil || ingressSpec.Auth == nil {
+		return nil
+	}
+	return ingressSpec.Auth.OAuth2Proxy
+}
+
+// JobManagerUIPort returns the name and the number of the JobManager service port which
+// serves the web UI, through the oauth2-proxy or the read-only UI proxy if enabled.
+func JobManagerUIPort(flinkCluster *v1beta1.FlinkCluster) (string, int32) {
+	if oauth2Proxy := getOAuth2ProxySpec(flinkCluster); oauth2Proxy != nil {
+		return oauth2ProxyName, getOAuth2ProxyPort(oauth2Proxy)
+	} else if flinkCluster.Spec.JobManager.IsReadOnlyUI() {
+		return uiProxyName, v1beta1.ReadOnlyUIProxyPort
+	}
+	return "ui", *flinkCluster.Spec.JobManager.Ports.UI
+}
+
+func getOAuth2ProxyPort(oauth2Proxy *v1beta1.OAuth2ProxySpec) int32 {
+	if oauth2Proxy.Port != nil {
+		return *oauth2Proxy.Port
+	}
+	return oauth2ProxyDefaultPort
+}
+
+// Gets the oauth2-proxy sidecar which authenticates the requests to the Flink web UI.
+func newOAuth2ProxyContainer(oauth2Proxy *v1beta1.OAuth2ProxySpec, upstreamPort int32) corev1.Container {
+	var image = oauth2Proxy.Image
+	if image == "" {
+		image = oauth2ProxyDefaultImage
+	}
+	var port = getOAuth2ProxyPort(oauth2Proxy)
+	var args = []string{
+		"--provider=" + oauth2Proxy.Provider,
+		fmt.Sprintf("--http-address=0.0.0.0:%d", port),
+		fmt.Sprintf("--upstream=http://127.0.0.1:%d", upstreamPort),
+		"--reverse-proxy=true",
+	}
+	args = append(args, oauth2Proxy.ExtraArgs...)
+	var secretEnv = func(name string, ref corev1.SecretKeySelector) corev1.EnvVar {
+		return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: ref.DeepCopy()}}
+	}
+
+	return corev1.Container{
+		Name:  oauth2ProxyName,
+		Image: image,
+		Args:  args,
+		Ports: []corev1.ContainerPort{{Name: oauth2ProxyName, ContainerPort: port}},
+		Env: []corev1.EnvVar{
+			secretEnv("OAUTH2_PROXY_CLIENT_ID", oauth2Proxy.ClientIDSecretRef),
+			secretEnv("OAUTH2_PROXY_CLIENT_SECRET", oauth2Proxy.ClientSecretSecretRef),
+			secretEnv("OAUTH2_PROXY_COOKIE_SECRET", oauth2Proxy.CookieSecretSecretRef),
+		},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{Path: "/ping", Port: intstr.FromString(oauth2ProxyName)},
+			},
+		},
+		Resources: oauth2Proxy.Resources,
+	}
+}
+
+// Gets the proxy sidecar which rejects mutating requests to the Flink web UI.
+// Its config is mounted from the cluster ConfigMap.
+func newUIProxyContainer(image string) corev1.Container {
+	return corev1.Container{
+		Name:  uiProxyName,
+		Image: image,
+		Ports: []corev1.ContainerPort{{Name: uiProxyName, ContainerPort: v1beta1.ReadOnlyUIProxyPort}},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      flinkConfigMapVolume,
+			MountPath: "/etc/nginx/conf.d/default.conf",
+			SubPath:   uiProxyConfigKey,
+			ReadOnly:  true,
+		}},
+	}
+}
+
+func newTaskManagerContainer(flinkCluster *v1beta1.FlinkCluster) *corev1.Container {
+	var imageSpec = flinkCluster.Spec.Image
+	var taskManagerSpec = flinkCluster.Spec.TaskManager
+	var dataPort = corev1.ContainerPort{Name: "data", ContainerPort: *taskManagerSpec.Ports.Data}
+	var rpcPort = corev1.ContainerPort{Name: "rpc", ContainerPort: *taskManagerSpec.Ports.RPC}
+	var queryPort = corev1.ContainerPort{Name: "query", ContainerPort: *taskManagerSpec.Ports.Query}
+	var ports = []corev1.ContainerPort{dataPort, rpcPort, queryPort}
+	for _, port := range taskManagerSpec.ExtraPorts {
+		ports = append(ports, corev1.ContainerPort{Name: port.Name, ContainerPort: port.ContainerPort, Protocol: corev1.Protocol(port.Protocol)})
+	}
+	ports = appendMetricsPort(flinkCluster, ports)
+
+	var container = &corev1.Container{
+		Name:            "taskmanager",
+		Image:           imageSpec.Name,
+		ImagePullPolicy: imageSpec.PullPolicy,
+		Args:            []string{"taskmanager"},
+		Ports:           ports,
+		LivenessProbe:   taskManagerSpec.LivenessProbe,
+		ReadinessProbe:  taskManagerSpec.ReadinessProbe,
+		Resources:       taskManagerSpec.Resources,
+		Env:             getEnvVars(flinkCluster),
+		EnvFrom:         flinkCluster.Spec.EnvFrom,
+		VolumeMounts:    taskManagerSpec.VolumeMounts,
+		Lifecycle: &corev1.Lifecycle{
+			PreStop: &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{
+					Command: []string{"sleep", strconv.Itoa(preStopSleepSeconds)},
+				},
+			},
+		},
+	}
+	setMetricsReporterSecrets(flinkCluster, container)
+
+	return container
+}
+
+func newTaskManagerPodSpec(
+	mainContainer *corev1.Container,
+	flinkCluster *v1beta1.FlinkCluster,
+	options Options) *corev1.PodSpec {
+	var taskManagerSpec = flinkCluster.Spec.TaskManager
+
+	var podSpec = &corev1.PodSpec{
+		InitContainers:                convertContainers(taskManagerSpec.InitContainers, []corev1.VolumeMount{}, getEnvVars(flinkCluster)),
+		Containers:                    []corev1.Container{*mainContainer},
+		Volumes:                       taskManagerSpec.Volumes,
+		Affinity:                      taskManagerSpec.Affinity,
+		NodeSelector:                  taskManagerSpec.NodeSelector,
+		Tolerations:                   taskManagerSpec.Tolerations,
+		ImagePullSecrets:              ImagePullSecrets(flinkCluster, options.ImagePullSecrets),
+		SecurityContext:               taskManagerSpec.SecurityContext,
+		HostAliases:                   taskManagerSpec.HostAliases,
+		ServiceAccountName:            ServiceAccountName(flinkCluster),
+		TerminationGracePeriodSeconds: getTerminationGracePeriodSeconds(taskManagerSpec.TerminationGracePeriodSeconds),
+	}
+
+	setTaskManagerArtifacts(flinkCluster, options.OperatorImage, podSpec)
+	setFlinkPlugins(flinkCluster, podSpec)
+	setRenderedFlinkConfig(flinkCluster, options.getUIProxyImage(), podSpec)
+	setFlinkConfig(flinkCluster, podSpec)
+	setHadoopConfig(flinkCluster.Spec.HadoopConfig, podSpec)
+	setGCPConfig(flinkCluster.Spec.GCPConfig, podSpec)
+	setStateEncryption(flinkCluster.Spec.StateEncryption, podSpec)
+	setNetworking(flinkCluster, podSpec)
+	setDiagnostics(flinkCluster.Spec.Diagnostics, podSpec)
+	setSchedulingDefaults(options.SchedulingDefaults.TaskManager, podSpec)
+	setArchitectureAffinity(flinkCluster.Spec.Architecture, podSpec)
+	setLogSidecar(flinkCluster, "taskmanager", podSpec)
+	podSpec.Containers = append(podSpec.Containers, taskManagerSpec.Sidecars...)
+
+	return podSpec
+}
+
+// getJarDownloadURL returns the URL to download a file of spec.taskManager.extraArtifacts
+// from. `gs://` and `s3://` URIs are mapped to the HTTPS endpoints of Cloud Storage and S3.
+func getJarDownloadURL(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return uri, nil
+	case "gs":
+		return fmt.Sprintf("https://storage.googleapis.com/%s%s", u.Host, u.EscapedPath()), nil
+	case "s3":
+		return fmt.Sprintf("https://%s.s3.amazonaws.com%s", u.Host, u.EscapedPath()), nil
+	default:
+		return "", fmt.Errorf("unsupported JAR file URI %v", uri)
+	}
+}
+
+// setTaskManagerArtifacts adds the init container which downloads spec.taskManager.extraArtifacts
+// into a volume. Each file is mounted into its directory of the Flink home of the TaskManager
+// container, next to the files of the image. The init container runs `download-artifacts` of
+// the operator image, see package transfer, with the environment and the volumes of the main
+// container, i.e. with the credentials of the cluster. Without the operator image, `curl` of
+// the Flink image downloads the files without credentials.
+func setTaskManagerArtifacts(flinkCluster *v1beta1.FlinkCluster, operatorImage string, podSpec *corev1.PodSpec) {
+	var taskManagerSpec = flinkCluster.Spec.TaskManager
+	if len(taskManagerSpec.ExtraArtifacts) == 0 {
+		return
+	}
+
+	var artifacts []transfer.Artifact
+	var scriptArgs []string
+	var volumeMounts []corev1.VolumeMount
+	for _, artifact := range taskManagerSpec.ExtraArtifacts {
+		var file = path.Join(artifact.Directory, artifact.GetFileName())
+		var downloadPath = path.Join(extraArtifactsPath, file)
+		artifacts = append(artifacts, transfer.Artifact{URI: artifact.URI, Path: downloadPath, SHA256: artifact.SHA256})
+		var downloadURL, err = getJarDownloadURL(artifact.URI)
+		if err != nil {
+			downloadURL = artifact.URI
+		}
+		scriptArgs = append(scriptArgs, downloadURL, downloadPath, artifact.SHA256)
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      extraArtifactsVolume,
+			MountPath: path.Join(flinkHomePath, file),
+			SubPath:   file,
+			ReadOnly:  true,
+		})
+	}
+	var container = &podSpec.Containers[0]
+	var downloadMount = corev1.VolumeMount{Name: extraArtifactsVolume, MountPath: extraArtifactsPath}
+	var initContainer = corev1.Container{Name: extraArtifactsName, Resources: taskManagerSpec.Resources}
+	if operatorImage != "" {
+		var artifactsJSON, _ = json.Marshal(artifacts)
+		initContainer.Image = operatorImage
+		initContainer.Args = []string{"download-artifacts", "--artifacts", string(artifactsJSON)}
+		initContainer.Env = append([]corev1.EnvVar{}, container.Env...)
+		initContainer.EnvFrom = container.EnvFrom
+		initContainer.VolumeMounts = appendVolumeMounts(append([]corev1.VolumeMount{}, container.VolumeMounts...), downloadMount)
+	} else {
+		initContainer.Image = flinkCluster.Spec.Image.Name
+		initContainer.ImagePullPolicy = flinkCluster.Spec.Image.PullPolicy
+		initContainer.Command = []string{"bash", "-c", extraArtifactsScript, extraArtifactsName}
+		initContainer.Args = scriptArgs
+		initContainer.VolumeMounts = []corev1.VolumeMount{downloadMount}
+	}
+	container.VolumeMounts = appendVolumeMounts(container.VolumeMounts, volumeMounts...)
+	podSpec.InitContainers = append([]corev1.Container{initContainer}, podSpec.InitContainers...)
+	podSpec.Volumes = appendVolumes(podSpec.Volumes, corev1.Volume{
+		Name:         extraArtifactsVolume,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+}
+
+// setJobManagerStorage mounts the claim of spec.jobManager.storage into the JobManager
+// container, and points its web upload directory there with a dynamic property, which the
+// TaskManagers sharing the ConfigMap do not get. The blob storage directory is not moved, as
+// the BlobServer stores the blobs in a random blobStore-<uuid> directory of it, which it
+// deletes on shutdown.
+func setJobManagerStorage(storage *v1beta1.JobManagerStorageSpec, podSpec *corev1.PodSpec) {
+	if storage == nil {
+		return
+	}
+	var container = &podSpec.Containers[0]
+	container.VolumeMounts = appendVolumeMounts(container.VolumeMounts,
+		corev1.VolumeMount{Name: storage.VolumeClaimTemplate, MountPath: jobManagerStoragePath})
+	container.Args = append(container.Args, "-Dweb.upload.dir="+jobManagerStoragePath+"/upload")
+}
+
+func isJobManagerStorageRetained(storage *v1beta1.JobManagerStorageSpec, claimTemplate string) bool {
+	return storage != nil && storage.VolumeClaimTemplate == claimTemplate &&
+		storage.GetRetentionPolicy() == v1beta1.StorageRetentionPolicyRetain
+}
+
+// setFlinkPlugins adds the init container which copies the JAR files of spec.flinkPlugins from
+// the opt directory of the image into a volume. The directory of each plugin is mounted into
+// the plugins directory of the main container.
+func setFlinkPlugins(flinkCluster *v1beta1.FlinkCluster, podSpec *corev1.PodSpec) {
+	var plugins = flinkCluster.Spec.FlinkPlugins
+	if len(plugins) == 0 {
+		return
+	}
+
+	var args = []string{flinkPluginsPath}
+	var volumeMounts []corev1.VolumeMount
+	for _, plugin := range plugins {
+		args = append(args, string(plugin))
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      flinkPluginsVolume,
+			MountPath: path.Join(flinkHomePath, "plugins", string(plugin)),
+			SubPath:   string(plugin),
+			ReadOnly:  true,
+		})
+	}
+	var container = &podSpec.Containers[0]
+	container.VolumeMounts = appendVolumeMounts(container.VolumeMounts, volumeMounts...)
+
+	var imageSpec = flinkCluster.Spec.Image
+	podSpec.InitContainers = append([]corev1.Container{{
+		Name:            flinkPluginsName,
+		Image:           imageSpec.Name,
+		ImagePullPolicy: imageSpec.PullPolicy,
+		Command:         []string{"bash", "-c", flinkPluginsScript, flinkPluginsName},
+		Args:            args,
+		VolumeMounts:    []corev1.VolumeMount{{Name: flinkPluginsVolume, MountPath: flinkPluginsPath}},
+		Resources:       container.Resources,
+	}}, podSpec.InitContainers...)
+	podSpec.Volumes = appendVolumes(podSpec.Volumes, corev1.Volume{
+		Name:         flinkPluginsVolume,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+}
+
+// Gets the termination grace period of the JobManager and TaskManager pods, the default if unspecified.
+func getTerminationGracePeriodSeconds(seconds *int64) *int64 {
+	if seconds != nil {
+		return seconds
+	}
+	return &terminationGracePeriodSeconds
+}
+
+// Gets the desired TaskManager StatefulSet spec from a cluster spec.
+func newTaskManagerStatefulSet(flinkCluster *v1beta1.FlinkCluster, options Options) *appsv1.StatefulSet {
+	var taskManagerSpec = flinkCluster.Spec.TaskManager
+	var taskManagerStatefulSetName = TaskManagerName(flinkCluster.Name)
+	var podLabels = ComponentLabels(flinkCluster, "taskmanager")
+	podLabels = MergeLabels(podLabels, taskManagerSpec.PodLabels)
+	var statefulSetLabels = MergeLabels(podLabels, getRevisionHashLabels(&flinkCluster.Status.Revision))
+
+	mainContainer := newTaskManagerContainer(flinkCluster)
+	podSpec := newTaskManagerPodSpec(mainContainer, flinkCluster, options)
+
+	var pvcs []corev1.PersistentVolumeClaim
+	if taskManagerSpec.VolumeClaimTemplates != nil {
+		pvcs = make([]corev1.PersistentVolumeClaim, len(taskManagerSpec.VolumeClaimTemplates))
+		for i, pvc := range taskManagerSpec.VolumeClaimTemplates {
+			pvc.OwnerReferences = []metav1.OwnerReference{ToOwnerReference(flinkCluster)}
+			pvcs[i] = pvc
+		}
+	}
+
+	var statefulSet = &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       flinkCluster.Namespace,
+			Name:            taskManagerStatefulSetName,
+			OwnerReferences: []metav1.OwnerReference{ToOwnerReference(flinkCluster)},
+			Labels:          statefulSetLabels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:             getIdleReplicas(flinkCluster, "taskmanager", taskManagerSpec.Replicas),
+			Selector:             &metav1.LabelSelector{MatchLabels: podLabels},
+			ServiceName:          taskManagerStatefulSetName,
+			VolumeClaimTemplates: pvcs,
+			PodManagementPolicy:  "Parallel",
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      podLabels,
+					Annotations: getTaskManagerPodAnnotations(flinkCluster),
+				},
+				Spec: *podSpec,
+			},
+		},
+	}
+
+	// Only the canary TaskManagers are updated until the canary update is promoted.
+	if partition := getCanaryUpdatePartition(flinkCluster); partition != nil {
+		statefulSet.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{
+			Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: partition},
+		}
+	}
+	return statefulSet
+}
+
+func getEphemeralVolumesFromTaskManagerSpec(flinkCluster *v1beta1.FlinkCluster, labels map[string]string) []corev1.Volume {
+	var ephemeralVolumes []corev1.Volume
+	var volumeClaimsInSpec = flinkCluster.Spec.TaskManager.VolumeClaimTemplates
+	for _, volume := range volumeClaimsInSpec {
+		ephemeralVolumes = append(ephemeralVolumes, corev1.Volume{
+			Name: volume.Name,
+			// Ephemeral volume
+			VolumeSource: corev1.VolumeSource{
+				Ephemeral: &corev1.EphemeralVolumeSource{
+					VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: labels,
+						},
+						Spec: volume.Spec,
+					},
+				},
+			},
+		})
+	}
+	return ephemeralVolumes
+}
+
+// Gets the desired TaskManager Deployment spec from a cluster spec.
+func newTaskManagerDeployment(flinkCluster *v1beta1.FlinkCluster, options Options) *appsv1.Deployment {
+	var taskManagerSpec = flinkCluster.Spec.TaskManager
+	var taskManagerDeploymentName = TaskManagerName(flinkCluster.Name)
+	var podLabels = ComponentLabels(flinkCluster, "taskmanager")
+	podLabels = MergeLabels(podLabels, taskManagerSpec.PodLabels)
+	var deploymentLabels = MergeLabels(podLabels, getRevisionHashLabels(&flinkCluster.Status.Revision))
+
+	mainContainer := newTaskManagerContainer(flinkCluster)
+	podSpec := newTaskManagerPodSpec(mainContainer, flinkCluster, options)
+	podSpec.Volumes = append(podSpec.Volumes, getEphemeralVolumesFromTaskManagerSpec(flinkCluster, podLabels)...)
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       flinkCluster.Namespace,
+			Name:            taskManagerDeploymentName,
+			OwnerReferences: []metav1.OwnerReference{ToOwnerReference(flinkCluster)},
+			Labels:          deploymentLabels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: getIdleReplicas(flinkCluster, "taskmanager", taskManagerSpec.Replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      podLabels,
+					Annotations: getTaskManagerPodAnnotations(flinkCluster),
+				},
+				Spec: *podSpec,
+			},
+		},
+	}
+}
+
+// Gets the desired PodDisruptionBudget.
+func newPodDisruptionBudget(flinkCluster *v1beta1.FlinkCluster) *policyv1.PodDisruptionBudget {
+	pdbSpec := flinkCluster.Spec.PodDisruptionBudget
+	if pdbSpec == nil {
+		return nil
+	}
+
+	selectorLabels := ClusterLabels(flinkCluster)
+	labels := MergeLabels(selectorLabels, getRevisionHashLabels(&flinkCluster.Status.Revision))
+	if pdbSpec.Selector == nil {
+		pdbSpec.Selector = new(metav1.LabelSelector)
+	}
+
+	if pdbSpec.Selector.MatchLabels == nil {
+		pdbSpec.Selector.MatchLabels = selectorLabels
+	} else {
+		for k, v := range selectorLabels {
+			pdbSpec.Selector.MatchLabels[k] = v
+		}
+	}
+
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: flinkCluster.Namespace,
+			Name:      PodDisruptionBudgetName(flinkCluster.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				ToOwnerReference(flinkCluster),
+			},
+			Labels: labels,
+		},
+		Spec: *pdbSpec,
+	}
+}
+
+// Gets the desired HorizontalPodAutoscaler.
+func newHorizontalPodAutoscaler(flinkCluster *v1beta1.FlinkCluster) *autoscalingv2.HorizontalPodAutoscaler {
+	hpaSpec := flinkCluster.Spec.TaskManager.HorizontalPodAutoscaler
+	if hpaSpec == nil {
+		return nil
+	}
+
+	selectorLabels := ClusterLabels(flinkCluster)
+	labels := MergeLabels(selectorLabels, getRevisionHashLabels(&flinkCluster.Status.Revision))
+	metrics := hpaSpec.Metrics
+	if kafkaLagMetric := getKafkaLagMetric(flinkCluster); kafkaLagMetric != nil {
+		metrics = append(append([]autoscalingv2.MetricSpec{}, metrics...), *kafkaLagMetric)
+	}
+
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: flinkCluster.Namespace,
+			Name:      HorizontalPodAutoscalerName(flinkCluster.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				ToOwnerReference(flinkCluster),
+			},
+			Labels: labels,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			MaxReplicas: hpaSpec.MaxReplicas,
+			MinReplicas: hpaSpec.MinReplicas,
+			Metrics:     metrics,
+			Behavior:    hpaSpec.Behavior,
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: flinkCluster.APIVersion,
+				Kind:       flinkCluster.Kind,
+				Name:       flinkCluster.Name,
+			},
+		},
+	}
+
+}
+
+// Gets the port of the Prometheus reporter of the JobManager and TaskManagers, the one of the
+// first reporter name if there are several.
+func getMetricsPort(flinkCluster *v1beta1.FlinkCluster) (int32, bool) {
+	var ports = flinkCluster.GetPrometheusReporterPorts()
+	var names []string
+	for name := range ports {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return 0, false
+	}
+	sort.Strings(names)
+	return ports[names[0]], true
+}
+
+// Gets the port of the Prometheus reporter of the TaskManagers if spec.monitoring.exposeTaskManagerMetrics
+// is enabled.
+func getTaskManagerMetricsPort(flinkCluster *v1beta1.FlinkCluster) (int32, bool) {
+	if !flinkCluster.IsTaskManagerMetricsExposed() {
+		return 0, false
+	}
+	return getMetricsPort(flinkCluster)
+}
+
+// Declares the port of the Prometheus reporter as the metrics port of the container, unless
+// extraPorts already declare the port or the name.
+func appendMetricsPort(flinkCluster *v1beta1.FlinkCluster, ports []corev1.ContainerPort) []corev1.ContainerPort {
+	var metricsPort, ok = getMetricsPort(flinkCluster)
+	if !ok {
+		return ports
+	}
+	for _, port := range ports {
+		if port.ContainerPort == metricsPort || port.Name == v1beta1.MetricsPortName {
+			return ports
+		}
+	}
+	return append(ports, corev1.ContainerPort{Name: v1beta1.MetricsPortName, ContainerPort: metricsPort})
+}
+
+// Gets the Prometheus scrape annotations of the pods of a component, merged under its pod
+// annotations which take precedence.
+func getMetricsPodAnnotations(flinkCluster *v1beta1.FlinkCluster, podAnnotations map[string]string, annotate bool) map[string]string {
+	if !annotate {
+		return podAnnotations
+	}
+	if metricsPort, ok := getMetricsPort(flinkCluster); ok {
+		podAnnotations = MergeLabels(map[string]string{
+			"prometheus.io/scrape": "true",
+			"prometheus.io/port":   strconv.Itoa(int(metricsPort)),
+		}, podAnnotations)
+	}
+	return podAnnotations
+}
+
+// Gets the annotations of the JobManager pods, with the Prometheus scrape annotations if
+// spec.monitoring.prometheusAnnotations is enabled.
+func getJobManagerPodAnnotations(flinkCluster *v1beta1.FlinkCluster) map[string]string {
+	return getMetricsPodAnnotations(flinkCluster, flinkCluster.Spec.JobManager.PodAnnotations,
+		flinkCluster.HasPrometheusAnnotations())
+}
+
+// Gets the annotations of the TaskManager pods, with the Prometheus scrape annotations if
+// spec.monitoring.exposeTaskManagerMetrics or spec.monitoring.prometheusAnnotations is enabled.
+func getTaskManagerPodAnnotations(flinkCluster *v1beta1.FlinkCluster) map[string]string {
+	return getMetricsPodAnnotations(flinkCluster, flinkCluster.Spec.TaskManager.PodAnnotations,
+		flinkCluster.IsTaskManagerMetricsExposed() || flinkCluster.HasPrometheusAnnotations())
+}
+
+// Gets the desired TaskManager Headless Service.
+func newTaskManagerService(flinkCluster *v1beta1.FlinkCluster) *corev1.Service {
+	var tmSpec = flinkCluster.Spec.TaskManager
+	if tmSpec == nil {
+		return nil
+	}
+	var clusterNamespace = flinkCluster.Namespace
+	var clusterName = flinkCluster.Name
+	// Service name matches the service name defined in the TM StatefulSet spec
+	var tmSvcName = TaskManagerName(clusterName)
+	selectorLabels := ComponentLabels(flinkCluster, "taskmanager")
+	serviceLabels := MergeLabels(selectorLabels, getRevisionHashLabels(&flinkCluster.Status.Revision))
+
+	var appProtocols = tmSpec.Ports.AppProtocols
+	var tmSvcPorts = []corev1.ServicePort{
+		{
+			Name:        "data",
+			Port:        *tmSpec.Ports.Data,
+			AppProtocol: getAppProtocol(appProtocols, "data"),
+		},
+		{
+			Name:        "rpc",
+			Port:        *tmSpec.Ports.RPC,
+			AppProtocol: getAppProtocol(appProtocols, "rpc"),
+		},
+		{
+			Name:        "query",
+			Port:        *tmSpec.Ports.Query,
+			AppProtocol: getAppProtocol(appProtocols, "query"),
+		},
+	}
+	tmSvcPorts = append(tmSvcPorts, getExtraServicePorts(tmSpec.ExtraPorts)...)
+
+	if metricsPort, ok := getTaskManagerMetricsPort(flinkCluster); ok {
+		tmSvcPorts = append(tmSvcPorts, corev1.ServicePort{
+			Name: v1beta1.MetricsPortName,
+			Port: metricsPort,
+		})
+	}
+
+	var tmService = &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       clusterNamespace,
+			Name:            tmSvcName,
+			OwnerReferences: []metav1.OwnerReference{ToOwnerReference(flinkCluster)},
+			Labels:          serviceLabels,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector:  selectorLabels,
+			ClusterIP: corev1.ClusterIPNone,
+			Type:      corev1.ServiceTypeClusterIP,
+			Ports:     tmSvcPorts,
+		},
+	}
+	setServiceIPFamilies(flinkCluster, tmService)
+	return tmService
+}
+
+// setServiceIPFamilies sets the IP families of spec.networking to the service, which the
+// Kubernetes cluster defaults otherwise.
+func setServiceIPFamilies(flinkCluster *v1beta1.FlinkCluster, service *corev1.Service) {
+	var networking = flinkCluster.Spec.Networking
+	if networking == nil {
+		return
+	}
+	service.Spec.IPFamilyPolicy = networking.IPFamilyPolicy
+	service.Spec.IPFamilies = networking.IPFamilies
+}
+
+// Gets the desired configMap.
+func newConfigMap(flinkCluster *v1beta1.FlinkCluster) *corev1.ConfigMap {
+	appVersion, _ := version.NewVersion(flinkCluster.Spec.FlinkVersion)
+
+	var clusterNamespace = flinkCluster.Namespace
+	var clusterName = flinkCluster.Name
+	var flinkProperties = flinkCluster.Spec.FlinkProperties
+	var jmPorts = flinkCluster.Spec.JobManager.Ports
+	var configMapName = ConfigMapName(clusterName)
+	var labels = MergeLabels(
+		ClusterLabels(flinkCluster),
+		getRevisionHashLabels(&flinkCluster.Status.Revision))
+	var flinkProps = getAddressingProperties(flinkCluster)
+
+	if appVersion == nil || appVersion.LessThan(v10) {
+		var flinkHeapSize = calFlinkHeapSize(flinkCluster)
+		if flinkHeapSize["jobmanager.heap.size"] != "" {
+			flinkProps["jobmanager.heap.size"] = flinkHeapSize["jobmanager.heap.size"]
+		}
+		if flinkHeapSize["taskmanager.heap.size"] != "" {
+			flinkProps["taskmanager.heap.size"] = flinkHeapSize["taskmanager.heap.size"]
+		}
+	} else {
+		var flinkProcessMemorySize = calFlinkMemoryProcessSize(flinkCluster)
+		if flinkProcessMemorySize["jobmanager.memory.process.size"] != "" {
+			flinkProps["jobmanager.memory.process.size"] = flinkProcessMemorySize["jobmanager.memory.process.size"]
+		}
+		if flinkProcessMemorySize["taskmanager.memory.process.size"] != "" {
+			flinkProps["taskmanager.memory.process.size"] = flinkProcessMemorySize["taskmanager.memory.process.size"]
+		}
+	}
+
+	if taskSlots, err := flinkCluster.GetTaskSlots(); err == nil {
+		flinkProps["taskmanager.numberOfTaskSlots"] = strconv.Itoa(int(taskSlots))
+		if slotResources := flinkCluster.Spec.TaskManager.SlotResources; slotResources != nil {
+			for k, v := range calFineGrainedResources(slotResources, taskSlots) {
+				flinkProps[k] = v
+			}
+			if jobSpec := flinkCluster.Spec.Job; jobSpec != nil && jobSpec.AllBlockingShuffle != nil {
+				flinkProps["fine-grained.shuffle-mode.all-blocking"] = strconv.FormatBool(*jobSpec.AllBlockingShuffle)
+			}
+		}
+	}
+
+	// The custom Flink properties of the same reporters take precedence.
+	for k, v := range getMetricsReporterProperties(flinkCluster.Spec.Monitoring, appVersion) {
+		flinkProps[k] = v
+	}
+
+	// Add custom Flink properties.
+	for k, v := range flinkProperties {
+		// Do not allow to override properties from real deployment.
+		if _, ok := flinkSysProps[k]; ok {
+			continue
+		}
+		flinkProps[k] = v
+	}
+	// The encryption properties, which are validated not to be set in the custom Flink properties.
+	if encryption := flinkCluster.Spec.StateEncryption; encryption != nil {
+		for k, v := range encryption.GetFlinkProperties() {
+			flinkProps[k] = v
+		}
+	}
+	// JVM options of the JobManager and TaskManagers, which are validated not to conflict
+	// with the custom Flink properties.
+	if jvmOptions := flinkCluster.Spec.JobManager.JVMOptions; len(jvmOptions) > 0 {
+		flinkProps["env.java.opts.jobmanager"] = strings.Join(jvmOptions, " ")
+	}
+	if jvmOptions := flinkCluster.Spec.TaskManager.JVMOptions; len(jvmOptions) > 0 {
+		flinkProps["env.java.opts.taskmanager"] = strings.Join(jvmOptions, " ")
+	}
+	if jvmOptions := getDiagnosticsJVMOptions(flinkCluster.Spec.Diagnostics); len(jvmOptions) > 0 {
+		for _, key := range []string{"env.java.opts.jobmanager", "env.java.opts.taskmanager"} {
+			flinkProps[key] = strings.TrimSpace(flinkProps[key] + " " + strings.Join(jvmOptions, " "))
+		}
+	}
+	// Set the time zone and the networking of the JVMs, of the Flink CLI of the job submitter as well.
+	var sharedJVMOptions []string
+	if timezone := flinkCluster.Spec.Timezone; timezone != nil {
+		sharedJVMOptions = append(sharedJVMOptions, "-Duser.timezone="+*timezone)
+	}
+	sharedJVMOptions = append(sharedJVMOptions, getNetworkingJVMOptions(flinkCluster)...)
+	if len(sharedJVMOptions) > 0 {
+		var javaOptsKey = "env.java.opts"
+		if _, ok := flinkProps["env.java.opts.all"]; ok {
+			javaOptsKey = "env.java.opts.all"
+		}
+		flinkProps[javaOptsKey] = strings.TrimSpace(flinkProps[javaOptsKey] + " " + strings.Join(sharedJVMOptions, " "))
+	}
+	// Disable job submission and cancellation in the web UI.
+	var readOnlyUI = flinkCluster.Spec.JobManager.IsReadOnlyUI()
+	if readOnlyUI {
+		flinkProps["web.submit.enable"] = "false"
+		flinkProps["web.cancel.enable"] = "false"
+	}
+	var configData = getLogConf(flinkCluster.Spec)
+	configData["flink-conf.yaml"] = getFlinkProperties(flinkProps)
+	// Only the addressing properties are appended to the flink-conf.yaml of spec.configOverride.
+	if flinkCluster.Spec.ConfigOverride != nil {
+		configData["flink-conf.yaml"] = getFlinkProperties(getAddressingProperties(flinkCluster))
+	}
+	configData["submit-job.sh"] = submitJobScript
+	if readOnlyUI {
+		configData[uiProxyConfigKey] = fmt.Sprintf(uiProxyConfig, v1beta1.ReadOnlyUIProxyPort, *jmPorts.UI)
+	}
+	var configMap = &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       clusterNamespace,
+			Name:            configMapName,
+			OwnerReferences: []metav1.OwnerReference{ToOwnerReference(flinkCluster)},
+			Labels:          labels,
+		},
+		Data: configData,
+	}
+
+	return configMap
+}
+
+// Gets the properties which should be provided from real deployed environment.
+func getAddressingProperties(flinkCluster *v1beta1.FlinkCluster) map[string]string {
+	var jmPorts = flinkCluster.Spec.JobManager.Ports
+	var tmPorts = flinkCluster.Spec.TaskManager.Ports
+	return map[string]string{
+		"jobmanager.rpc.address": JobManagerAddress(flinkCluster),
+		"jobmanager.rpc.port":    strconv.FormatInt(int64(*jmPorts.RPC), 10),
+		"blob.server.port":       strconv.FormatInt(int64(*jmPorts.Blob), 10),
+		"query.server.port":      strconv.FormatInt(int64(*jmPorts.Query), 10),
+		"rest.port":              strconv.FormatInt(int64(*jmPorts.UI), 10),
+		"taskmanager.rpc.port":   strconv.FormatInt(int64(*tmPorts.RPC), 10),
+	}
+}
+
+// Checks whether the operator should create the service account and its permissions
+// for Kubernetes HA services, which is the case when no service account is given.
+func shouldCreateHARBAC(flinkCluster *v1beta1.FlinkCluster) bool {
+	return flinkCluster.Spec.ServiceAccountName == nil && flinkCluster.IsKubernetesHighAvailabilityEnabled()
+}
+
+func newHAObjectMeta(flinkCluster *v1beta1.FlinkCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace:       flinkCluster.Namespace,
+		Name:            HAServiceAccountName(flinkCluster.Name),
+		OwnerReferences: []metav1.OwnerReference{ToOwnerReference(flinkCluster)},
+		Labels: MergeLabels(
+			ClusterLabels(flinkCluster),
+			getRevisionHashLabels(&flinkCluster.Status.Revision)),
+	}
+}
+
+// Gets the service account for the Flink pods to access Kubernetes HA services.
+func newHAServiceAccount(flinkCluster *v1beta1.FlinkCluster) *corev1.ServiceAccount {
+	if !shouldCreateHARBAC(flinkCluster) {
+		return nil
+	}
+	return &corev1.ServiceAccount{ObjectMeta: newHAObjectMeta(flinkCluster)}
+}
+
+// Gets the role to edit the ConfigMaps in which Kubernetes HA services store
+// the leader information and the pointers to the job metadata.
+func newHARole(flinkCluster *v1beta1.FlinkCluster) *rbacv1.Role {
+	if !shouldCreateHARBAC(flinkCluster) {
+		return nil
+	}
+	return &rbacv1.Role{
+		ObjectMeta: newHAObjectMeta(flinkCluster),
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"configmaps"},
+				Verbs:     []string{"create", "delete", "get", "list", "patch", "update", "watch"},
+			},
+		},
+	}
+}
+
+func newHARoleBinding(flinkCluster *v1beta1.FlinkCluster) *rbacv1.RoleBinding {
+	if !shouldCreateHARBAC(flinkCluster) {
+		return nil
+	}
+	var name = HAServiceAccountName(flinkCluster.Name)
+	return &rbacv1.RoleBinding{
+		ObjectMeta: newHAObjectMeta(flinkCluster),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     name,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      name,
+				Namespace: flinkCluster.Namespace,
+			},
+		},
+	}
+}
+
+func newJobSubmitterPodSpec(flinkCluster *v1beta1.FlinkCluster, options Options) *corev1.PodSpec {
+	var jobSpec = flinkCluster.Spec.Job
+	if jobSpec == nil {
+		return nil
+	}
+
+	var status = flinkCluster.Status
+	var clusterSpec = flinkCluster.Spec
+	var imageSpec = clusterSpec.Image
+	var jobManagerSpec = clusterSpec.JobManager
+	var jobManagerAddress = net.JoinHostPort(JobManagerAddress(flinkCluster), strconv.Itoa(int(*jobManagerSpec.Ports.UI)))
+
+	var jobArgs = []string{"bash", submitJobScriptPath}
+	jobArgs = append(jobArgs, "--jobmanager", jobManagerAddress)
+	if jobSpec.ClassName != nil {
+		jobArgs = append(jobArgs, "--class", *jobSpec.ClassName)
+	}
+
+	var fromSavepoint = FromSavepoint(jobSpec, status.Components.Job, &status.Revision)
+	if fromSavepoint != nil {
+		jobArgs = append(jobArgs, "--fromSavepoint", *fromSavepoint)
+	}
+
+	if jobSpec.AllowNonRestoredState != nil &&
+		*jobSpec.AllowNonRestoredState {
+		jobArgs = append(jobArgs, "--allowNonRestoredState")
+	}
+
+	if parallelism, err := calJobParallelism(flinkCluster); err == nil {
+		jobArgs = append(jobArgs, "--parallelism", fmt.Sprint(parallelism))
+	}
+
+	if jobSpec.NoLoggingToStdout != nil &&
+		*jobSpec.NoLoggingToStdout {
+		jobArgs = append(jobArgs, "--sysoutLogging")
+	}
+
+	if jobSpec.Mode != nil && *jobSpec.Mode == v1beta1.JobModeDetached {
+		jobArgs = append(jobArgs, "--detached")
+	}
+
+	if jobSpec.ClassPath != nil && len(jobSpec.ClassPath) > 0 {
+		for _, u := range jobSpec.ClassPath {
+			jobArgs = append(jobArgs, "-C", u)
+		}
+	}
+
+	envVars := []corev1.EnvVar{{
+		Name:  jobManagerAddrEnvVar,
+		Value: jobManagerAddress,
+	}}
+	envVars = append(envVars, getJobEnvVars(flinkCluster, fromSavepoint)...)
+	envVars = append(envVars, getEnvVars(flinkCluster)...)
+
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	volumes = append(volumes, jobSpec.Volumes...)
+	volumeMounts = append(volumeMounts, jobSpec.VolumeMounts...)
+
+	// Submit job script config.
+	sbsVolume, sbsMount, confMount := convertSubmitJobScript(flinkCluster)
+	volumes = append(volumes, *sbsVolume)
+	volumeMounts = append(volumeMounts, *sbsMount, *confMount)
+
+	var initContainers []corev1.Container
+	var workingDir string
+	if gitRepo := jobSpec.GitRepo; gitRepo != nil {
+		var gitMount = corev1.VolumeMount{Name: gitRepoVolume, MountPath: gitRepoPath}
+		volumes = append(volumes, corev1.Volume{
+			Name:         gitRepoVolume,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		volumeMounts = append(volumeMounts, gitMount)
+		if gitRepo.SecretRef != nil && isSSHGitURL(gitRepo.URL) {
+			// Readable by the non-root user of git-sync, the only container which mounts it.
+			var mode int32 = 0444
+			volumes = append(volumes, corev1.Volume{
+				Name: gitSecretVolume,
+				VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+					SecretName:  gitRepo.SecretRef.Name,
+					DefaultMode: &mode,
+				}},
+			})
+		}
+		initContainers = append(initContainers, newGitSyncContainer(flinkCluster, gitRepo, gitMount))
+		workingDir = getGitRepoWorkingDir(gitRepo)
+	}
+	if jobSpec.JarFile != nil {
+		var jarFile = *jobSpec.JarFile
+		var mavenRepository *v1beta1.MavenRepositorySpec
+		if v1beta1.IsMavenArtifact(jarFile) {
+			// The coordinates are validated by the webhook.
+			jarFile, _ = v1beta1.GetMavenArtifactURL(jobSpec.MavenRepository, jarFile)
+			mavenRepository = jobSpec.MavenRepository
+			if mavenRepository == nil {
+				mavenRepository = &v1beta1.MavenRepositorySpec{}
+			}
+		}
+		cacheVolume, cacheMount, cachedJarFile := convertArtifactCache(jobSpec.ArtifactCache, jarFile)
+		if cacheVolume == nil && mavenRepository != nil {
+			// Maven artifacts are always fetched by the submitter, into its pod unless cached.
+			cacheVolume = &corev1.Volume{
+				Name:         artifactCacheVolume,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}
+			cacheMount = &corev1.VolumeMount{Name: artifactCacheVolume, MountPath: artifactCachePath}
+			cachedJarFile = getCachedArtifactPath(jarFile)
+		}
+		if cacheVolume != nil {
+			volumes = append(volumes, *cacheVolume)
+			volumeMounts = append(volumeMounts, *cacheMount)
+			initContainers = append(initContainers,
+				newArtifactCacheContainer(flinkCluster, jarFile, cachedJarFile, cacheMount, mavenRepository))
+			jarFile = cachedJarFile
+		}
+		jobArgs = append(jobArgs, jarFile)
+		// The submitter resolves the entry class from the manifest of the JAR file.
+		if jobSpec.ClassName == nil {
+			envVars = append(envVars, corev1.EnvVar{Name: jobJarFileEnvVar, Value: jarFile})
+		}
+	}
+
+	if jobSpec.PyFile != nil {
+		jobArgs = append(jobArgs, "--python", *jobSpec.PyFile)
+	}
+
+	if jobSpec.PyFiles != nil {
+		jobArgs = append(jobArgs, "--pyFiles", *jobSpec.PyFiles)
+	}
+
+	if jobSpec.PyModule != nil {
+		jobArgs = append(jobArgs, "--pyModule", *jobSpec.PyModule)
+	}
+
+	jobArgs = append(jobArgs, jobSpec.Args...)
+	argsFrom, argEnvVars := getJobArgsFrom(jobSpec)
+	jobArgs = append(jobArgs, argsFrom...)
+
+	podSpec := &corev1.PodSpec{
+		InitContainers: append(initContainers, convertContainers(jobSpec.InitContainers, volumeMounts, envVars)...),
+		Containers: []corev1.Container{
+			{
+				Name:            "main",
+				Image:           imageSpec.Name,
+				ImagePullPolicy: imageSpec.PullPolicy,
+				Args:            jobArgs,
+				WorkingDir:      workingDir,
+				Env:             append(argEnvVars, envVars...),
+				EnvFrom:         flinkCluster.Spec.EnvFrom,
+				VolumeMounts:    volumeMounts,
+				Resources:       jobSpec.Resources,
+			},
+		},
+		RestartPolicy:                 corev1.RestartPolicyNever,
+		Volumes:                       volumes,
+		ImagePullSecrets:              ImagePullSecrets(flinkCluster, options.ImagePullSecrets),
+		SecurityContext:               jobSpec.SecurityContext,
+		HostAliases:                   jobSpec.HostAliases,
+		ServiceAccountName:            ServiceAccountName(flinkCluster),
+		Affinity:                      jobSpec.Affinity,
+		NodeSelector:                  jobSpec.NodeSelector,
+		Tolerations:                   jobSpec.Tolerations,
+		TerminationGracePeriodSeconds: jobSpec.TerminationGracePeriodSeconds,
+	}
+
+	setFlinkConfig(flinkCluster, podSpec)
+	setHadoopConfig(flinkCluster.Spec.HadoopConfig, podSpec)
+	setGCPConfig(flinkCluster.Spec.GCPConfig, podSpec)
+	setNetworking(flinkCluster, podSpec)
+	setSchedulingDefaults(options.SchedulingDefaults.JobSubmitter, podSpec)
+	setArchitectureAffinity(flinkCluster.Spec.Architecture, podSpec)
+
+	return podSpec
+}
+
+// Gets the job arguments of spec.job.argsFrom in order, with the env vars which the arguments
+// from ConfigMaps and Secrets reference. Kubernetes expands $(VAR) in the container arguments,
+// so the values do not appear in the pod spec, but they do in the command line of the
+// container; $( in the literal values is escaped.
+func getJobArgsFrom(jobSpec *v1beta1.JobSpec) ([]string, []corev1.EnvVar) {
+	var args []string
+	var envVars []corev1.EnvVar
+	var secretArgs []string
+	for i, source := range jobSpec.ArgsFrom {
+		if source.Value != nil {
+			args = append(args, strings.ReplaceAll(*source.Value, "$(", "$$("))
+			continue
+		}
+		var name = fmt.Sprintf("%v%d", jobArgEnvVarPrefix, i)
+		var envVar = corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{}}
+		if source.SecretKeyRef != nil {
+			envVar.ValueFrom.SecretKeyRef = source.SecretKeyRef.DeepCopy()
+			secretArgs = append(secretArgs, name)
+		} else {
+			envVar.ValueFrom.ConfigMapKeyRef = source.ConfigMapKeyRef.DeepCopy()
+		}
+		args = append(args, fmt.Sprintf("$(%v)", name))
+		envVars = append(envVars, envVar)
+	}
+	if len(secretArgs) > 0 {
+		envVars = append(envVars, corev1.EnvVar{Name: jobSecretArgsEnvVar, Value: strings.Join(secretArgs, " ")})
+	}
+	return args, envVars
+}
+
+// Gets the artifact cache volume and the cached path of the remote JAR file.
+// Returns nil if the cache is not configured or the JAR file is not remote.
+func convertArtifactCache(cacheSpec *v1beta1.ArtifactCacheSpec, jarFile string) (*corev1.Volume, *corev1.VolumeMount, string) {
+	if cacheSpec == nil || !(strings.HasPrefix(jarFile, "http://") || strings.HasPrefix(jarFile, "https://")) {
+		return nil, nil, ""
+	}
+
+	var volume = &corev1.Volume{Name: artifactCacheVolume}
+	switch {
+	case cacheSpec.PersistentVolumeClaim != nil:
+		volume.PersistentVolumeClaim = cacheSpec.PersistentVolumeClaim.DeepCopy()
+	case cacheSpec.HostPath != nil:
+		var hostPathType = corev1.HostPathDirectoryOrCreate
+		volume.HostPath = &corev1.HostPathVolumeSource{Path: *cacheSpec.HostPath, Type: &hostPathType}
+	default:
+		return nil, nil, ""
+	}
+	var volumeMount = &corev1.VolumeMount{Name: artifactCacheVolume, MountPath: artifactCachePath}
+	return volume, volumeMount, getCachedArtifactPath(jarFile)
+}
+
+// Gets the path of the remote JAR file in the artifact cache. Artifacts are stored by the hash
+// of their URI, keeping the file name.
+func getCachedArtifactPath(jarFile string) string {
+	var uriHash = fnv.New64a()
+	uriHash.Write([]byte(jarFile))
+	var fileName = path.Base(strings.SplitN(strings.SplitN(jarFile, "?", 2)[0], "#", 2)[0])
+	return path.Join(artifactCachePath, fmt.Sprintf("%016x", uriHash.Sum64()), fileName)
+}
+
+// Gets the init container which downloads the remote JAR file into the artifact cache unless it is cached.
+// The JAR files are verified against the pinned SHA-256 checksum of the spec, or else the ones
+// fetched from a Maven repository against their SHA-1 checksum.
+func newArtifactCacheContainer(
+	flinkCluster *v1beta1.FlinkCluster,
+	jarFile string,
+	cachedJarFile string,
+	cacheMount *corev1.VolumeMount,
+	mavenRepository *v1beta1.MavenRepositorySpec) corev1.Container {
+	var imageSpec = flinkCluster.Spec.Image
+	var envVars = []corev1.EnvVar{
+		{Name: "ARTIFACT_URI", Value: jarFile},
+		{Name: "ARTIFACT_PATH", Value: cachedJarFile},
+	}
+	if checksum := flinkCluster.Spec.Job.JarFileSHA256; len(checksum) != 0 {
+		envVars = append(envVars, corev1.EnvVar{Name: "ARTIFACT_SHA256", Value: checksum})
+	} else if mavenRepository != nil {
+		envVars = append(envVars, corev1.EnvVar{Name: "ARTIFACT_CHECKSUM_URI", Value: jarFile + ".sha1"})
+	}
+	if mavenRepository != nil {
+		if secretRef := mavenRepository.SecretRef; secretRef != nil {
+			var secretKeyEnv = func(name, key string) corev1.EnvVar {
+				return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: *secretRef, Key: key},
+				}}
+			}
+			envVars = append(envVars, secretKeyEnv("ARTIFACT_USERNAME", "username"), secretKeyEnv("ARTIFACT_PASSWORD", "password"))
+		}
+	}
+	return corev1.Container{
+		Name:            "artifact-cache",
+		Image:           imageSpec.Name,
+		ImagePullPolicy: imageSpec.PullPolicy,
+		Command:         []string{"bash", "-c", artifactCacheScript},
+		Env:             append(envVars, getTimezoneEnvVars(flinkCluster)...),
+		EnvFrom:         flinkCluster.Spec.EnvFrom,
+		VolumeMounts:    []corev1.VolumeMount{*cacheMount},
+		Resources:       flinkCluster.Spec.Job.Resources,
+	}
+}
+
+// Gets the init container which checks out the Git repository into the shared volume with git-sync.
+func newGitSyncContainer(
+	flinkCluster *v1beta1.FlinkCluster, gitRepo *v1beta1.GitRepoSpec, gitMount corev1.VolumeMount) corev1.Container {
+	var image = gitSyncDefaultImage
+	if gitRepo.Image != nil {
+		image = *gitRepo.Image
+	}
+	var args = []string{
+		"--repo=" + gitRepo.URL,
+		"--root=" + gitRepoPath,
+		"--link=" + gitRepoLink,
+		"--one-time",
+		"--depth=1",
+	}
+	if gitRepo.Ref != nil {
+		args = append(args, "--ref="+*gitRepo.Ref)
+	}
+	var envVars []corev1.EnvVar
+	var volumeMounts = []corev1.VolumeMount{gitMount}
+	if secretRef := gitRepo.SecretRef; secretRef != nil {
+		if isSSHGitURL(gitRepo.URL) {
+			args = append(args,
+				"--ssh-key-file="+path.Join(gitSecretPath, "ssh-privatekey"),
+				"--ssh-known-hosts-file="+path.Join(gitSecretPath, "known_hosts"))
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: gitSecretVolume, MountPath: gitSecretPath, ReadOnly: true})
+		} else {
+			var optional = true
+			var secretKeyEnv = func(name, key string) corev1.EnvVar {
+				return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: *secretRef, Key: key, Optional: &optional},
+				}}
+			}
+			envVars = append(envVars, secretKeyEnv("GITSYNC_USERNAME", "username"), secretKeyEnv("GITSYNC_PASSWORD", "password"))
+		}
+	}
+	return corev1.Container{
+		Name:         gitSyncName,
+		Image:        image,
+		Args:         args,
+		Env:          envVars,
+		VolumeMounts: volumeMounts,
+		Resources:    flinkCluster.Spec.Job.Resources,
+	}
+}
+
+// isSSHGitURL returns true if the Git repository is cloned over SSH, e.g. git@github.com:org/repo.git.
+func isSSHGitURL(url string) bool {
+	return strings.HasPrefix(url, "ssh://") || (strings.Contains(url, "@") && !strings.Contains(url, "://"))
+}
+
+// Gets the directory of the checked out Git repository the job is submitted from.
+func getGitRepoWorkingDir(gitRepo *v1beta1.GitRepoSpec) string {
+	var workingDir = path.Join(gitRepoPath, gitRepoLink)
+	if gitRepo.Path != nil {
+		workingDir = path.Join(workingDir, *gitRepo.Path)
+	}
+	return workingDir
+}
+
+func newJob(flinkCluster *v1beta1.FlinkCluster, options Options) *batchv1.Job {
+	jobSpec := flinkCluster.Spec.Job
+	if jobSpec == nil {
+		return nil
+	}
+
+	recorded := flinkCluster.Status
+	jobManagerSpec := flinkCluster.Spec.JobManager
+	labels := ClusterLabels(flinkCluster)
+	labels = MergeLabels(labels, getRevisionHashLabels(&recorded.Revision))
+
+	var jobName string
+	var annotations map[string]string
+	var podSpec *corev1.PodSpec
+
+	if IsApplicationModeCluster(flinkCluster) {
+		jobId, _ := GenJobId(flinkCluster)
+		labels = MergeLabels(labels, ComponentLabels(flinkCluster, "jobmanager"))
+		labels = MergeLabels(labels, jobManagerSpec.PodLabels)
+		labels = MergeLabels(labels, map[string]string{JobIdLabel: jobId})
+		jobName = JobManagerJobName(flinkCluster.Name)
+		annotations = getJobManagerPodAnnotations(flinkCluster)
+		mainContainer := newJobManagerContainer(flinkCluster)
+		podSpec = newJobManagerPodSpec(mainContainer, flinkCluster, options)
+	} else {
+		jobName = SubmitterJobName(flinkCluster.Name)
+		labels = MergeLabels(labels, jobSpec.PodLabels)
+		annotations = jobSpec.PodAnnotations
+		podSpec = newJobSubmitterPodSpec(flinkCluster, options)
+	}
+
+	// Disable the retry mechanism of k8s Job, all retries should be initiated
+	// by the operator based on the job restart policy. This is because Flink
+	// jobs are stateful, if a job fails after running for 10 hours, we probably
+	// don't want to start over from the beginning, instead we want to resume
+	// the job from the latest savepoint which means strictly speaking it is no
+	// longer the same job as the previous one because the `--fromSavepoint`
+	// parameter has changed.
+	podSpec.RestartPolicy = corev1.RestartPolicyNever
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       flinkCluster.Namespace,
+			Name:            jobName,
+			OwnerReferences: []metav1.OwnerReference{ToOwnerReference(flinkCluster)},
+			Labels:          labels,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      labels,
+					Annotations: annotations,
+				},
+				Spec: *podSpec,
+			},
+			BackoffLimit: &backoffLimit,
+		},
+	}
+}
+
+// FromSavepoint decides from which savepoint Flink job should be restored when the job created, updated or restarted
+//
+// case 1) Restore job from the user provided savepoint
+// When FlinkCluster is created or updated, if spec.job.fromSavepoint is specified, Flink job will be restored from it.
+//
+// case 2) Restore Flink job from the latest savepoint.
+// When FlinkCluster is updated with no spec.job.fromSavepoint, or job is restarted from the failed state,
+// Flink job will be restored from the latest savepoint created by the operator.
+//
+// case 3) When latest created savepoint is unavailable, use the savepoint from which current job was restored.
+func FromSavepoint(jobSpec *v1beta1.JobSpec, jobStatus *v1beta1.JobStatus, revision *v1beta1.RevisionStatus) *string {
+	var savepoint, _ = SelectFromSavepoint(jobSpec, jobStatus, revision)
+	return savepoint
+}
+
+// SelectFromSavepoint selects the savepoint to restore the job from as FromSavepoint, and
+// describes why it is chosen for the audit records.
+func SelectFromSavepoint(jobSpec *v1beta1.JobSpec, jobStatus *v1beta1.JobStatus, revision *v1beta1.RevisionStatus) (*string, string) {
+	switch {
+	// Updating with FromSavepoint provided
+	case revision.IsUpdateTriggered() && !util.IsBlank(jobSpec.FromSavepoint):
+		return jobSpec.FromSavepoint, "spec.job.fromSavepoint of the update"
+	// Latest savepoint
+	case jobStatus != nil && jobStatus.SavepointLocation != "":
+		return &jobStatus.SavepointLocation, fmt.Sprintf("the latest savepoint of the job, taken at %v", jobStatus.SavepointTime)
+	// The savepoint from which current job was restored
+	case jobStatus != nil && jobStatus.FromSavepoint != "":
+		return &jobStatus.FromSavepoint, "the savepoint the previous job was restored from, no savepoint was taken since"
+	}
+	// Creating for the first time or other situation
+	if !util.IsBlank(jobSpec.FromSavepoint) {
+		return jobSpec.FromSavepoint, "spec.job.fromSavepoint"
+	}
+	return nil, ""
+}
+
+func appendVolumes(volumes []corev1.Volume, newVolumes ...corev1.Volume) []corev1.Volume {
+	for _, mounts := range newVolumes {
+		var conflict = false
+		for _, mount := range volumes {
+			if mounts.Name == mount.Name {
+				conflict = true
+				break
+			}
+		}
+		if !conflict {
+			volumes = append(volumes, mounts)
+		}
+	}
+
+	return volumes
+}
+
+func appendVolumeMounts(volumeMounts []corev1.VolumeMount, newVolumeMounts ...corev1.VolumeMount) []corev1.VolumeMount {
+	for _, mounts := range newVolumeMounts {
+		var conflict = false
+		for _, mount := range volumeMounts {
+			if mounts.MountPath == mount.MountPath {
+				conflict = true
+				break
+			}
+		}
+		if !conflict {
+			volumeMounts = append(volumeMounts, mounts)
+		}
+	}
+
+	return volumeMounts
+}
+
+func appendEnvVars(envVars []corev1.EnvVar, newEnvVars ...corev1.EnvVar) []corev1.EnvVar {
+	for _, envVar := range newEnvVars {
+		var conflict = false
+		for _, env := range envVars {
+			if envVar.Name == env.Name {
+				conflict = true
+				break
+			}
+		}
+		if !conflict {
+			envVars = append(envVars, envVar)
+		}
+	}
+
+	return envVars
+}
+
+// Copy any non-duplicate volume mounts and env vars to each specified container
+func convertContainer(container corev1.Container, volumeMounts []corev1.VolumeMount, envVars []corev1.EnvVar) corev1.Container {
+	container.VolumeMounts = appendVolumeMounts(container.VolumeMounts, volumeMounts...)
+	container.Env = appendEnvVars(container.Env, envVars...)
+
+	return container
+}
+
+// Gets the environment variables shared by all JobManager, TaskManager and job containers.
+func getEnvVars(flinkCluster *v1beta1.FlinkCluster) []corev1.EnvVar {
+	var timezoneEnvVars = getTimezoneEnvVars(flinkCluster)
+	if len(timezoneEnvVars) == 0 {
+		return flinkCluster.Spec.EnvVars
+	}
+	return append(append([]corev1.EnvVar{}, flinkCluster.Spec.EnvVars...), timezoneEnvVars...)
+}
+
+// Gets the environment variables describing the deployment of the job, so that the job can tag
+// its metrics and lineage with them: the cluster name and namespace, the revision the job is
+// submitted for and the savepoint it is restored from, if any. They precede spec.envVars, which
+// can override them.
+func getJobEnvVars(flinkCluster *v1beta1.FlinkCluster, fromSavepoint *string) []corev1.EnvVar {
+	var envVars = []corev1.EnvVar{
+		{Name: clusterNameEnvVar, Value: flinkCluster.Name},
+		{Name: clusterNamespaceEnvVar, Value: flinkCluster.Namespace},
+	}
+	if revision := &flinkCluster.Status.Revision; revision.NextRevision != "" {
+		envVars = append(envVars, corev1.EnvVar{Name: clusterRevisionEnvVar, Value: NextRevisionName(revision)})
+	}
+	if fromSavepoint != nil {
+		envVars = append(envVars, corev1.EnvVar{Name: savepointPathEnvVar, Value: *fromSavepoint})
+	}
+	return envVars
+}
+
+// Gets the TZ environment variable of spec.timezone, nil if unspecified.
+func getTimezoneEnvVars(flinkCluster *v1beta1.FlinkCluster) []corev1.EnvVar {
+	if flinkCluster.Spec.Timezone == nil {
+		return nil
+	}
+	return []corev1.EnvVar{{Name: "TZ", Value: *flinkCluster.Spec.Timezone}}
+}
+
+// Copy any non-duplicate volume mounts and env vars to the specified containers
+func convertContainers(containers []corev1.Container, volumeMounts []corev1.VolumeMount, envVars []corev1.EnvVar) []corev1.Container {
+	var updatedContainers = []corev1.Container{}
+	for _, container := range containers {
+		updatedContainer := convertContainer(container, volumeMounts, envVars)
+		updatedContainers = append(updatedContainers, updatedContainer)
+	}
+	return updatedContainers
+}
+
+// Converts the FlinkCluster as owner reference for its child resources.
+func ToOwnerReference(
+	flinkCluster *v1beta1.FlinkCluster) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion:         flinkCluster.APIVersion,
+		Kind:               flinkCluster.Kind,
+		Name:               flinkCluster.Name,
+		UID:                flinkCluster.UID,
+		Controller:         &[]bool{true}[0],
+		BlockOwnerDeletion: &[]bool{false}[0],
+	}
+}
+
+// Gets Flink properties
+func getFlinkProperties(properties map[string]string) string {
+	var keys = make([]string, len(properties))
+	i := 0
+	for k := range properties {
+		keys[i] = k
+		i = i + 1
+	}
+	sort.Strings(keys)
+	var builder strings.Builder
+	for _, key := range keys {
+		builder.WriteString(fmt.Sprintf("%s: %s\n", key, properties[key]))
+	}
+	return builder.String()
+}
+
+var jobManagerIngressHostRegex = regexp.MustCompile(`{{\s*[$]clusterName\s*}}`)
+
+// JobManagerIngressHost returns the host of the JobManager ingress of the host format.
+func JobManagerIngressHost(ingressHostFormat string, clusterName string) string {
+	// TODO: Validating webhook should verify hostFormat
+	return jobManagerIngressHostRegex.ReplaceAllString(ingressHostFormat, clusterName)
+}
+
+// ShouldCleanup checks whether the component should be deleted according to the cleanup
+// policy. Always return false for session cluster.
+func ShouldCleanup(cluster *v1beta1.FlinkCluster, component string) bool {
+	var jobStatus = cluster.Status.Components.Job
+	// Session cluster.
+	if jobStatus == nil {
+		return false
+	}
+
+	if cluster.Status.Revision.IsUpdateTriggered() {
+		return false
+	}
+
+	var _, action = GetCleanupAction(cluster.Spec.Job, jobStatus.State)
+	switch action {
+	case v1beta1.CleanupActionDeleteCluster:
+		return true
+	case v1beta1.CleanupActionDeleteTaskManager:
+		return component == "TaskManager"
+	case v1beta1.CleanupActionKeepJobManagerOnly:
+		// The JobManager keeps serving the web UI and the REST API with its configuration,
+		// and runs the job in application mode.
+		switch component {
+		case "JobManager", "JobManagerService", "ConfigMap", "ServiceAccount", "Role", "RoleBinding", "Job":
+			return false
+		}
+		return true
+	}
+
+	return false
+}
+
+// GetCleanupAction returns the action of the cleanup policy for the final state of the job,
+// with the name of its field in spec.job.cleanupPolicy.
+func GetCleanupAction(jobSpec *v1beta1.JobSpec, jobState v1beta1.JobState) (string, v1beta1.CleanupAction) {
+	switch jobState {
+	case v1beta1.JobStateSucceeded:
+		return "afterJobSucceeds", jobSpec.CleanupPolicy.AfterJobSucceeds
+	case v1beta1.JobStateFailed, v1beta1.JobStateLost, v1beta1.JobStateDeployFailed:
+		return "afterJobFails", jobSpec.CleanupPolicy.AfterJobFails
+	case v1beta1.JobStateCancelled:
+		return "afterJobCancelled", jobSpec.CleanupPolicy.AfterJobCancelled
+	}
+	return "", ""
+}
+
+// IsApplicationModeCluster returns true if the job of the cluster runs in application mode.
+func IsApplicationModeCluster(cluster *v1beta1.FlinkCluster) bool {
+	jobSpec := cluster.Spec.Job
+	return jobSpec != nil && jobSpec.Mode != nil && *jobSpec.Mode == v1beta1.JobModeApplication
+}
+
+// IsTaskManagerExternal returns true if the TaskManagers are managed outside of the operator.
+func IsTaskManagerExternal(cluster *v1beta1.FlinkCluster) bool {
+	var external = cluster.Spec.TaskManager.External
+	return external != nil && *external
+}
+
+// ShouldStopJob checks if the job should be stopped because a job-cancel was requested.
+func ShouldStopJob(cluster *v1beta1.FlinkCluster) bool {
+	var userControl = cluster.Annotations[v1beta1.ControlAnnotation]
+	var cancelRequested = cluster.Spec.Job.CancelRequested
+	return userControl == v1beta1.ControlNameJobCancel ||
+		(cancelRequested != nil && *cancelRequested)
+}
+
+func calJobParallelism(cluster *v1beta1.FlinkCluster) (int32, error) {
+	if cluster.Spec.Job.Parallelism != nil {
+		return *cluster.Spec.Job.Parallelism, nil
+	}
+
+	value, err := cluster.GetTaskSlots()
+	if err != nil {
+		return 0, err
+	}
+
+	parallelism := *cluster.Spec.TaskManager.Replicas * value
+	return parallelism, nil
+}
+
+func calFlinkHeapSize(cluster *v1beta1.FlinkCluster) map[string]string {
+	jm := cluster.Spec.JobManager
+	tm := cluster.Spec.TaskManager
+
+	if jm.MemoryOffHeapRatio == nil || tm.MemoryOffHeapRatio == nil {
+		return nil
+	}
+
+	var flinkHeapSize = make(map[string]string)
+
+	jmHeapSizeMB := calHeapSize(
+		jm.GetResources().Memory().Value(),
+		jm.MemoryOffHeapMin.Value(),
+		int64(*jm.MemoryOffHeapRatio))
+	if jmHeapSizeMB > 0 {
+		flinkHeapSize["jobmanager.heap.size"] = strconv.FormatInt(jmHeapSizeMB, 10) + "m"
+	}
+
+	tmHeapSizeMB := calHeapSize(
+		tm.GetResources().Memory().Value(),
+		tm.MemoryOffHeapMin.Value(),
+		int64(*tm.MemoryOffHeapRatio))
+	if tmHeapSizeMB > 0 {
+		flinkHeapSize["taskmanager.heap.size"] = strconv.FormatInt(tmHeapSizeMB, 10) + "m"
+	}
+
+	return flinkHeapSize
+}
+
+// Converts memory value to the format of divisor and returns ceiling of the value.
+func convertResourceMemoryToInt64(memory resource.Quantity, divisor resource.Quantity) int64 {
+	return int64(math.Ceil(float64(memory.Value()) / float64(divisor.Value())))
+}
+
+// Calculate heap size in MB
+func calHeapSize(memSize int64, offHeapMin int64, offHeapRatio int64) int64 {
+	var heapSizeMB int64
+	offHeapSize := int64(math.Ceil(float64(memSize*offHeapRatio) / 100))
+	if offHeapSize < offHeapMin {
+		offHeapSize = offHeapMin
+	}
+	heapSizeCalculated := memSize - offHeapSize
+	if heapSizeCalculated > 0 {
+		divisor := resource.MustParse("1Mi")
+		heapSizeQuantity := resource.NewQuantity(heapSizeCalculated, resource.DecimalSI)
+		heapSizeMB = convertResourceMemoryToInt64(*heapSizeQuantity, divisor)
+	}
+	return heapSizeMB
+}
+
+func calProcessMemorySize(memSize, ratio int64) int64 {
+	size := int64(math.Ceil(float64((memSize * ratio)) / 100))
+	divisor := resource.MustParse("1Mi")
+	quantity := resource.NewQuantity(size, resource.DecimalSI)
+	return convertResourceMemoryToInt64(*quantity, divisor)
+}
+
+// Calculate process memory size in MB
+func calFlinkMemoryProcessSize(cluster *v1beta1.FlinkCluster) map[string]string {
+	var flinkProcessMemory = make(map[string]string)
+	jm := cluster.Spec.JobManager
+	tm := cluster.Spec.TaskManager
+
+	jmMemoryByte := jm.GetResources().Memory().Value()
+	jmRatio := int64(*jm.MemoryProcessRatio)
+	jmSizeMB := calProcessMemorySize(jmMemoryByte, jmRatio)
+	if jmSizeMB > 0 {
+		flinkProcessMemory["jobmanager.memory.process.size"] = strconv.FormatInt(jmSizeMB, 10) + "m"
+	}
+
+	tmMemByte := tm.GetResources().Memory().Value()
+	ratio := int64(*tm.MemoryProcessRatio)
+	sizeMB := calProcessMemorySize(tmMemByte, ratio)
+	if sizeMB > 0 {
+		flinkProcessMemory["taskmanager.memory.process.size"] = strconv.FormatInt(sizeMB, 10) + "m"
+	}
+
+	return flinkProcessMemory
+}
+
+// Calculate the TaskManager resources of Flink fine-grained resource management
+// from the resource profile of a slot.
+func calFineGrainedResources(slot *v1beta1.SlotResources, taskSlots int32) map[string]string {
+	var properties = map[string]string{
+		"cluster.fine-grained-resource-management.enabled": "true",
+		"taskmanager.cpu.cores": strconv.FormatFloat(
+			float64(slot.CPUCores.MilliValue()*int64(taskSlots))/1000, 'f', -1, 64),
+	}
+	var divisor = resource.MustParse("1Mi")
+	var memories = map[string]resource.Quantity{
+		"taskmanager.memory.task.heap.size":     slot.TaskHeapMemory,
+		"taskmanager.memory.task.off-heap.size": slot.TaskOffHeapMemory,
+		"taskmanager.memory.managed.size":       slot.ManagedMemory,
+	}
+	for k, memory := range memories {
+		if memory.IsZero() {
+			continue
+		}
+		var total = resource.NewQuantity(memory.Value()*int64(taskSlots), resource.BinarySI)
+		properties[k] = strconv.FormatInt(convertResourceMemoryToInt64(*total, divisor), 10) + "m"
+	}
+	return properties
+}
+
+// Gets the volume of the Flink conf directory. The generated ConfigMap is
+// projected together with spec.extraConfigMounts when they are specified.
+func newFlinkConfigVolume(flinkCluster *v1beta1.FlinkCluster) corev1.Volume {
+	var configMapName = ConfigMapName(flinkCluster.Name)
+	var extraMounts = flinkCluster.Spec.ExtraConfigMounts
+	if len(extraMounts) == 0 {
+		return corev1.Volume{
+			Name: flinkConfigMapVolume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: configMapName,
+					},
+				},
+			},
+		}
+	}
+
+	var sources = []corev1.VolumeProjection{{
+		ConfigMap: &corev1.ConfigMapProjection{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: configMapName,
+			},
+		},
+	}}
+	for _, mount := range extraMounts {
+		sources = append(sources, corev1.VolumeProjection{
+			ConfigMap: mount.ConfigMap.DeepCopy(),
+			Secret:    mount.Secret.DeepCopy(),
+		})
+	}
+	return corev1.Volume{
+		Name: flinkConfigMapVolume,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: sources,
+			},
+		},
+	}
+}
+
+func setFlinkConfig(flinkCluster *v1beta1.FlinkCluster, podSpec *corev1.PodSpec) bool {
+	var envVars []corev1.EnvVar
+	volumes := []corev1.Volume{newFlinkConfigVolume(flinkCluster)}
+	volumeMounts := []corev1.VolumeMount{{
+		Name:      flinkConfigMapVolume,
+		MountPath: flinkConfigMapPath,
+	}}
+
+	podSpec.Containers = convertContainers(podSpec.Containers, volumeMounts, envVars)
+	podSpec.InitContainers = convertContainers(podSpec.InitContainers, volumeMounts, envVars)
+	podSpec.Volumes = appendVolumes(podSpec.Volumes, volumes...)
+	return true
+}
+
+// setRenderedFlinkConfig renders flink-conf.yaml with an init container running the image,
+// which must provide envsubst, so that secrets are never stored in the ConfigMap: the
+// secrets of spec.secretsInjection and the key of spec.stateEncryption.gcsCustomerKey are
+// substituted for their placeholders and the properties resolved from
+// spec.flinkPropertiesFrom are appended from their Secret. The files of the ConfigMap of
+// spec.configOverride replace the generated ones, followed by the addressing properties. The
+// containers mount the rendered config in place of the ConfigMap, thus it must be set before
+// setFlinkConfig, whose mounts of the same path are then skipped.
+func setRenderedFlinkConfig(flinkCluster *v1beta1.FlinkCluster, image string, podSpec *corev1.PodSpec) bool {
+	var injections = getSecretInjections(flinkCluster)
+	var propertiesFrom = len(flinkCluster.Spec.FlinkPropertiesFrom) > 0
+	var override = flinkCluster.Spec.ConfigOverride
+	if len(injections) == 0 && !propertiesFrom && override == nil {
+		return false
+	}
+
+	var envVars []corev1.EnvVar
+	var placeholders []string
+	for _, injection := range injections {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:      injection.Placeholder,
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: injection.SecretKeyRef.DeepCopy()},
+		})
+		placeholders = append(placeholders, "${"+injection.Placeholder+"}")
+	}
+	var renderedMount = corev1.VolumeMount{Name: renderedConfigVolume, MountPath: flinkConfigMapPath}
+	var renderMounts = []corev1.VolumeMount{
+		{Name: flinkConfigMapVolume, MountPath: flinkConfigTemplatePath, ReadOnly: true},
+		renderedMount,
+	}
+	var volumes = []corev1.Volume{{
+		Name: renderedConfigVolume,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory},
+		},
+	}}
+
+	var flinkConf = flinkConfigMapPath + "/flink-conf.yaml"
+	var commands = []string{fmt.Sprintf("cp -L %s/* %s/", flinkConfigTemplatePath, flinkConfigMapPath)}
+	if override != nil {
+		// The files of the override replace the generated ones, the generated flink-conf.yaml
+		// is kept if the override has none, e.g. if it overrides only the log config.
+		commands = append(commands, fmt.Sprintf("cp -L %s/* %s/", flinkConfigOverridePath, flinkConfigMapPath))
+		renderMounts = append(renderMounts,
+			corev1.VolumeMount{Name: flinkConfigOverrideVol, MountPath: flinkConfigOverridePath, ReadOnly: true})
+		volumes = append(volumes, corev1.Volume{
+			Name: flinkConfigOverrideVol,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: override.ConfigMapName},
+				},
+			},
+		})
+	}
+	if len(placeholders) > 0 {
+		// Only the declared placeholders are substituted, the other variables are kept as they are.
+		commands = append(commands, fmt.Sprintf("envsubst '%[2]s' < %[1]s > %[1]s.tmp && mv %[1]s.tmp %[1]s",
+			flinkConf, strings.Join(placeholders, " ")))
+	}
+	// The appended files start on a new line, as the last line of the ConfigMaps may have no
+	// line break, e.g. if they are created with --from-literal.
+	if override != nil {
+		// The generated flink-conf.yaml has only the addressing properties, which take precedence.
+		commands = append(commands, fmt.Sprintf("if [ -f %[1]s/flink-conf.yaml ]; then echo >> %[2]s && cat %[3]s/flink-conf.yaml >> %[2]s; fi",
+			flinkConfigOverridePath, flinkConf, flinkConfigTemplatePath))
+	}
+	if propertiesFrom {
+		// The properties appended last override the ones of the ConfigMap.
+		commands = append(commands, fmt.Sprintf("echo >> %[1]s && cat %[2]s/flink-conf.yaml >> %[1]s",
+			flinkConf, flinkPropertiesFromPath))
+		renderMounts = append(renderMounts,
+			corev1.VolumeMount{Name: flinkPropertiesFromVol, MountPath: flinkPropertiesFromPath, ReadOnly: true})
+		volumes = append(volumes, corev1.Volume{
+			Name: flinkPropertiesFromVol,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: FlinkPropertiesSecretName(flinkCluster.Name)},
+			},
+		})
+	}
+	var renderContainer = corev1.Container{
+		Name:         renderConfigName,
+		Image:        image,
+		Command:      []string{"sh", "-c", strings.Join(commands, " && ")},
+		Env:          envVars,
+		VolumeMounts: renderMounts,
+	}
+
+	podSpec.Containers = convertContainers(podSpec.Containers, []corev1.VolumeMount{renderedMount}, nil)
+	podSpec.InitContainers = append([]corev1.Container{renderContainer},
+		convertContainers(podSpec.InitContainers, []corev1.VolumeMount{renderedMount}, nil)...)
+	podSpec.Volumes = appendVolumes(podSpec.Volumes, volumes...)
+	return true
+}
+
+// Gets the Secret of the properties resolved from spec.flinkPropertiesFrom, which the
+// init container of setRenderedFlinkConfig appends to flink-conf.yaml.
+func newFlinkPropertiesSecret(flinkCluster *v1beta1.FlinkCluster, properties map[string]string) *corev1.Secret {
+	var labels = MergeLabels(
+		ClusterLabels(flinkCluster),
+		getRevisionHashLabels(&flinkCluster.Status.Revision))
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       flinkCluster.Namespace,
+			Name:            FlinkPropertiesSecretName(flinkCluster.Name),
+			OwnerReferences: []metav1.OwnerReference{ToOwnerReference(flinkCluster)},
+			Labels:          labels,
+		},
+		Data: map[string][]byte{"flink-conf.yaml": []byte(getFlinkProperties(properties))},
+	}
+}
+
+func convertSubmitJobScript(flinkCluster *v1beta1.FlinkCluster) (*corev1.Volume, *corev1.VolumeMount, *corev1.VolumeMount) {
+	confVol := newFlinkConfigVolume(flinkCluster)
+	scriptMount := &corev1.VolumeMount{
+		Name:      flinkConfigMapVolume,
+		MountPath: submitJobScriptPath,
+		SubPath:   "submit-job.sh",
+	}
+	confMount := &corev1.VolumeMount{
+		Name:      flinkConfigMapVolume,
+		MountPath: flinkConfigMapPath,
+	}
+	return &confVol, scriptMount, confMount
+}
+
+func setHadoopConfig(hadoopConfig *v1beta1.HadoopConfig, podSpec *corev1.PodSpec) bool {
+	if hadoopConfig == nil {
+		return false
+	}
+
+	var volumes = []corev1.Volume{{
+		Name: hadoopConfigVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: hadoopConfig.ConfigMapName,
+				},
+			},
+		},
+	}}
+	var volumeMounts = []corev1.VolumeMount{{
+		Name:      hadoopConfigVolume,
+		MountPath: hadoopConfig.MountPath,
+		ReadOnly:  true,
+	}}
+	var envVars = []corev1.EnvVar{{
+		Name:  hadoopConfDirEnvVar,
+		Value: hadoopConfig.MountPath,
+	}}
+
+	podSpec.Containers = convertContainers(podSpec.Containers, volumeMounts, envVars)
+	podSpec.InitContainers = convertContainers(podSpec.InitContainers, volumeMounts, envVars)
+	podSpec.Volumes = appendVolumes(podSpec.Volumes, volumes...)
+	return true
+}
+
+func setGCPConfig(gcpConfig *v1beta1.GCPConfig, podSpec *corev1.PodSpec) bool {
+	if gcpConfig == nil {
+		return false
+	}
+
+	var saConfig = gcpConfig.ServiceAccount
+	var saVolume = corev1.Volume{
+		Name: gcpServiceAccountVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: gcpConfig.ServiceAccount.SecretName,
+			},
+		},
+	}
+	var saMount = corev1.VolumeMount{
+		Name:      gcpServiceAccountVolume,
+		MountPath: gcpConfig.ServiceAccount.MountPath,
+		ReadOnly:  true,
+	}
+	if !strings.HasSuffix(saMount.MountPath, "/") {
+		saMount.MountPath = saMount.MountPath + "/"
+	}
+
+	var saEnv = corev1.EnvVar{
+		Name:  gacEnvVar,
+		Value: saMount.MountPath + saConfig.KeyFile,
+	}
+
+	volumes := []corev1.Volume{saVolume}
+	volumeMounts := []corev1.VolumeMount{saMount}
+	envVars := []corev1.EnvVar{saEnv}
+	podSpec.Containers = convertContainers(podSpec.Containers, volumeMounts, envVars)
+	podSpec.InitContainers = convertContainers(podSpec.InitContainers, volumeMounts, envVars)
+	podSpec.Volumes = appendVolumes(podSpec.Volumes, volumes...)
+	return true
+}
+
+// Gets the secrets substituted into flink-conf.yaml, of spec.secretsInjection and of the
+// customer-supplied key of Cloud Storage.
+func getSecretInjections(flinkCluster *v1beta1.FlinkCluster) []v1beta1.SecretInjection {
+	var injections = flinkCluster.Spec.SecretsInjection
+	if encryption := flinkCluster.Spec.StateEncryption; encryption != nil && encryption.GCSCustomerKey != nil {
+		var customerKey = encryption.GCSCustomerKey
+		injections = append(append([]v1beta1.SecretInjection{}, injections...),
+			v1beta1.SecretInjection{Placeholder: v1beta1.GCSEncryptionKeyPlaceholder, SecretKeyRef: customerKey.KeySecretRef},
+			v1beta1.SecretInjection{Placeholder: v1beta1.GCSEncryptionKeyHashPlaceholder, SecretKeyRef: customerKey.KeyHashSecretRef})
+	}
+	return injections
+}
+
+// setStateEncryption sets the AWS credentials of spec.stateEncryption.awsKMS to the Flink
+// container, whose S3 plugins pick them up from the environment. The other containers do
+// not get them. The encryption properties are set in flink-conf.yaml.
+func setStateEncryption(encryption *v1beta1.StateEncryptionSpec, podSpec *corev1.PodSpec) {
+	if encryption == nil || encryption.AWSKMS == nil || encryption.AWSKMS.AccessKeyIDSecretRef == nil {
+		return
+	}
+
+	var kms = encryption.AWSKMS
+	var envVars = []corev1.EnvVar{
+		{
+			Name:      "AWS_ACCESS_KEY_ID",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: kms.AccessKeyIDSecretRef.DeepCopy()},
+		},
+		{
+			Name:      "AWS_SECRET_ACCESS_KEY",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: kms.SecretAccessKeySecretRef.DeepCopy()},
+		},
+	}
+	var mainContainer = &podSpec.Containers[0]
+	mainContainer.Env = append(mainContainer.Env, envVars...)
+}
+
+// setNetworking sets the proxy environment variables of spec.networking.proxy to the containers,
+// and imports the certificates of spec.networking.caBundle with an init container into the
+// truststore which the JVM options of getNetworkingJVMOptions point to. The init container
+// runs first, so that the other init containers, e.g. the artifact cache, trust them too.
+func setNetworking(flinkCluster *v1beta1.FlinkCluster, podSpec *corev1.PodSpec) bool {
+	var networking = flinkCluster.Spec.Networking
+	if networking == nil {
+		return false
+	}
+
+	var envVars = getProxyEnvVars(flinkCluster)
+	var volumeMounts []corev1.VolumeMount
+	var volumes []corev1.Volume
+	var caBundle = networking.CABundle
+	if caBundle != nil {
+		var bundleFile = truststorePath + "/ca-certificates.crt"
+		envVars = append(envVars,
+			corev1.EnvVar{Name: "SSL_CERT_FILE", Value: bundleFile},
+			corev1.EnvVar{Name: "CURL_CA_BUNDLE", Value: bundleFile})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: truststoreVolume, MountPath: truststorePath, ReadOnly: true})
+		volumes = append(volumes,
+			corev1.Volume{
+				Name: caBundleVolume,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: caBundle.ConfigMapName},
+					},
+				},
+			},
+			corev1.Volume{
+				Name:         truststoreVolume,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			})
+	}
+
+	podSpec.Containers = convertContainers(podSpec.Containers, volumeMounts, envVars)
+	podSpec.InitContainers = convertContainers(podSpec.InitContainers, volumeMounts, envVars)
+	if caBundle != nil {
+		var imageSpec = flinkCluster.Spec.Image
+		var importContainer = corev1.Container{
+			Name:            importCABundleName,
+			Image:           imageSpec.Name,
+			ImagePullPolicy: imageSpec.PullPolicy,
+			Command:         []string{"bash", "-c", importCABundleScript},
+			Env: []corev1.EnvVar{
+				{Name: "CA_BUNDLE_FILE", Value: caBundlePath + "/" + caBundle.GetKey()},
+				{Name: "TRUSTSTORE_DIR", Value: truststorePath},
+				{Name: "TRUSTSTORE_PASSWORD", Value: truststorePassword},
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: caBundleVolume, MountPath: caBundlePath, ReadOnly: true},
+				{Name: truststoreVolume, MountPath: truststorePath},
+			},
+		}
+		podSpec.InitContainers = append([]corev1.Container{importContainer}, podSpec.InitContainers...)
+	}
+	podSpec.Volumes = appendVolumes(podSpec.Volumes, volumes...)
+	return true
+}
+
+// Gets the proxy environment variables of spec.networking.proxy, in upper and lower case as
+// tools read either. Nil if unspecified.
+func getProxyEnvVars(flinkCluster *v1beta1.FlinkCluster) []corev1.EnvVar {
+	var networking = flinkCluster.Spec.Networking
+	if networking == nil || networking.Proxy == nil {
+		return nil
+	}
+
+	var proxy = networking.Proxy
+	var envVars []corev1.EnvVar
+	var add = func(name, value string) {
+		envVars = append(envVars,
+			corev1.EnvVar{Name: name, Value: value},
+			corev1.EnvVar{Name: strings.ToLower(name), Value: value})
+	}
+	if proxy.HTTPProxy != nil {
+		add("HTTP_PROXY", *proxy.HTTPProxy)
+	}
+	if proxy.HTTPSProxy != nil {
+		add("HTTPS_PROXY", *proxy.HTTPSProxy)
+	}
+	add("NO_PROXY", strings.Join(getNoProxyHosts(flinkCluster), ","))
+	return envVars
+}
+
+// Gets the JVM options of the proxy and the truststore of spec.networking.
+func getNetworkingJVMOptions(flinkCluster *v1beta1.FlinkCluster) []string {
+	var networking = flinkCluster.Spec.Networking
+	if networking == nil {
+		return nil
+	}
+
+	var options []string
+	if proxy := networking.Proxy; proxy != nil {
+		// The URLs are validated by the webhook.
+		if proxy.HTTPProxy != nil {
+			if host, port, err := v1beta1.ParseProxyURL(*proxy.HTTPProxy); err == nil {
+				options = append(options, "-Dhttp.proxyHost="+host, "-Dhttp.proxyPort="+port)
+			}
+		}
+		if proxy.HTTPSProxy != nil {
+			if host, port, err := v1beta1.ParseProxyURL(*proxy.HTTPSProxy); err == nil {
+				options = append(options, "-Dhttps.proxyHost="+host, "-Dhttps.proxyPort="+port)
+			}
+		}
+		// The JVM matches the subdomains of a domain with a leading wildcard instead of a dot.
+		var hosts []string
+		for _, host := range getNoProxyHosts(flinkCluster) {
+			if strings.HasPrefix(host, ".") {
+				host = "*" + host
+			}
+			hosts = append(hosts, host)
+		}
+		options = append(options, "-Dhttp.nonProxyHosts="+strings.Join(hosts, "|"))
+	}
+	if networking.CABundle != nil {
+		options = append(options,
+			"-Djavax.net.ssl.trustStore="+truststorePath+"/cacerts",
+			"-Djavax.net.ssl.trustStorePassword="+truststorePassword)
+	}
+	// The JVM prefers IPv4 addresses otherwise, which IPv6-only pods do not have.
+	if len(networking.IPFamilies) > 0 && networking.IPFamilies[0] == corev1.IPv6Protocol {
+		options = append(options, "-Djava.net.preferIPv6Addresses=true")
+	}
+	return options
+}
+
+// Gets the hosts of spec.networking.proxy.noProxy, followed by the local and the JobManager
+// service hosts, which are never proxied.
+func getNoProxyHosts(flinkCluster *v1beta1.FlinkCluster) []string {
+	var hosts []string
+	if noProxy := flinkCluster.Spec.Networking.Proxy.NoProxy; noProxy != nil {
+		for _, host := range strings.Split(*noProxy, ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	hosts = append(hosts, "localhost", "127.0.0.1", JobManagerServiceName(flinkCluster.Name))
+	if address := JobManagerAddress(flinkCluster); address != JobManagerServiceName(flinkCluster.Name) {
+		hosts = append(hosts, address)
+	}
+	return hosts
+}
+
+// setDiagnostics mounts the volume of the diagnostics files to the main container.
+func setDiagnostics(diagnostics *v1beta1.DiagnosticsSpec, podSpec *corev1.PodSpec) bool {
+	if diagnostics == nil {
+		return false
+	}
+
+	var volumeSource = corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+	if diagnostics.Volume != nil {
+		volumeSource = *diagnostics.Volume
+	}
+	var volumes = []corev1.Volume{{
+		Name:         diagnosticsVolume,
+		VolumeSource: volumeSource,
+	}}
+	var volumeMounts = []corev1.VolumeMount{{
+		Name:      diagnosticsVolume,
+		MountPath: DiagnosticsPath,
+	}}
+
+	podSpec.Containers = convertContainers(podSpec.Containers, volumeMounts, nil)
+	podSpec.Volumes = appendVolumes(podSpec.Volumes, volumes...)
+	return true
+}
+
+// setArchitectureAffinity requires nodes of spec.architecture for the pods. The requirement
+// is added to every node selector term of the affinity, as the terms are ORed.
+func setArchitectureAffinity(architecture *v1beta1.Architecture, podSpec *corev1.PodSpec) bool {
+	if architecture == nil || *architecture == v1beta1.ArchitectureAny {
+		return false
+	}
+
+	var requirement = corev1.NodeSelectorRequirement{
+		Key:      corev1.LabelArchStable,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{string(*architecture)},
+	}
+	// The affinity is shared with the cluster spec, which must not be modified.
+	var affinity = podSpec.Affinity.DeepCopy()
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	}
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	var required = affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil || len(required.NodeSelectorTerms) == 0 {
+		required = &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{}}}
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = required
+	}
+	for i := range required.NodeSelectorTerms {
+		var term = &required.NodeSelectorTerms[i]
+		term.MatchExpressions = append(term.MatchExpressions, requirement)
+	}
+	podSpec.Affinity = affinity
+	return true
+}
+
+// setLogSidecar shares the log directory of the main container with the log forwarder of
+// spec.logging.sidecar and appends the forwarder to the containers. If the containers already
+// mount a volume at the log directory, the forwarder shares that volume.
+func setLogSidecar(flinkCluster *v1beta1.FlinkCluster, component string, podSpec *corev1.PodSpec) {
+	var logging = flinkCluster.Spec.Logging
+	if logging == nil || logging.Sidecar == nil {
+		return
+	}
+
+	var volumes []corev1.Volume
+	var logVolumeName = getMountedVolumeName(podSpec.Containers, logPath)
+	if logVolumeName == "" {
+		logVolumeName = logVolume
+		volumes = append(volumes, corev1.Volume{
+			Name:         logVolume,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		podSpec.Containers = convertContainers(podSpec.Containers, []corev1.VolumeMount{{
+			Name:      logVolume,
+			MountPath: logPath,
+		}}, nil)
+	}
+
+	var volumeMounts = []corev1.VolumeMount{{
+		Name:      logVolumeName,
+		MountPath: logPath,
+		ReadOnly:  true,
+	}}
+	if secret := logging.Sidecar.OutputSecret; secret != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name:         logSidecarOutputVolume,
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secret}},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      logSidecarOutputVolume,
+			MountPath: logSidecarOutputPath,
+			ReadOnly:  true,
+		})
+	}
+	var envVars = []corev1.EnvVar{
+		{Name: "FLINK_CLUSTER", Value: flinkCluster.Name},
+		{Name: "FLINK_COMPONENT", Value: component},
+		{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+		}},
+		{Name: "POD_NAMESPACE", ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+		}},
+	}
+
+	var sidecar = convertContainer(*logging.Sidecar.Template.DeepCopy(), volumeMounts, envVars)
+	podSpec.Containers = append(podSpec.Containers, sidecar)
+	podSpec.Volumes = appendVolumes(podSpec.Volumes, volumes...)
+}
+
+// getMountedVolumeName returns the name of the volume the first of the containers mounts at
+// the path, empty if none does.
+func getMountedVolumeName(containers []corev1.Container, path string) string {
+	for _, container := range containers {
+		for _, mount := range container.VolumeMounts {
+			if mount.MountPath == path {
+				return mount.Name
+			}
+		}
+	}
+	return ""
+}
+
+// Reporter and factory classes of the metrics reporters of spec.monitoring.reporters.
+var metricsReporterClasses = map[string]struct{ reporter, factory string }{
+	"prometheus": {"org.apache.flink.metrics.prometheus.PrometheusReporter", "org.apache.flink.metrics.prometheus.PrometheusReporterFactory"},
+	"datadog":    {"org.apache.flink.metrics.datadog.DatadogHttpReporter", "org.apache.flink.metrics.datadog.DatadogHttpReporterFactory"},
+	"statsd":     {"org.apache.flink.metrics.statsd.StatsDReporter", "org.apache.flink.metrics.statsd.StatsDReporterFactory"},
+	"slf4j":      {"org.apache.flink.metrics.slf4j.Slf4jReporter", "org.apache.flink.metrics.slf4j.Slf4jReporterFactory"},
+}
+
+// getMetricsReporterType returns the type of the metrics reporter, the key of metricsReporterClasses.
+func getMetricsReporterType(reporter *v1beta1.MetricsReporter) string {
+	switch {
+	case reporter.Prometheus != nil:
+		return "prometheus"
+	case reporter.Datadog != nil:
+		return "datadog"
+	case reporter.StatsD != nil:
+		return "statsd"
+	default:
+		return "slf4j"
+	}
+}
+
+// getMetricsReporterProperties returns the Flink properties of spec.monitoring.reporters. The
+// reporters are configured with `factory.class` since Flink 1.11, with `class` before.
+func getMetricsReporterProperties(monitoring *v1beta1.MonitoringSpec, flinkVersion *version.Version) map[string]string {
+	if monitoring == nil {
+		return nil
+	}
+	var props = map[string]string{}
+	for i := range monitoring.Reporters {
+		var reporter = &monitoring.Reporters[i]
+		var prefix = "metrics.reporter." + reporter.Name + "."
+		var classes = metricsReporterClasses[getMetricsReporterType(reporter)]
+		if flinkVersion == nil || flinkVersion.LessThan(v11) {
+			props[prefix+"class"] = classes.reporter
+		} else {
+			props[prefix+"factory.class"] = classes.factory
+		}
+		if reporter.Interval != nil {
+			props[prefix+"interval"] = *reporter.Interval
+		}
+		if prometheus := reporter.Prometheus; prometheus != nil && prometheus.Port != nil {
+			props[prefix+"port"] = strconv.Itoa(int(*prometheus.Port))
+		}
+		if datadog := reporter.Datadog; datadog != nil {
+			if len(datadog.Tags) > 0 {
+				props[prefix+"tags"] = strings.Join(datadog.Tags, ",")
+			}
+			if datadog.DataCenter != nil {
+				props[prefix+"dataCenter"] = *datadog.DataCenter
+			}
+		}
+		if statsD := reporter.StatsD; statsD != nil {
+			props[prefix+"host"] = statsD.Host
+			props[prefix+"port"] = strconv.Itoa(int(statsD.Port))
+		}
+	}
+	return props
+}
+
+// setMetricsReporterSecrets passes the Datadog API keys of spec.monitoring.reporters to the
+// JobManager or TaskManager container. The keys are read from their Secrets into environment
+// variables, and set as dynamic properties which Kubernetes expands in the container args,
+// so that they are not written to the Flink ConfigMap.
+func setMetricsReporterSecrets(flinkCluster *v1beta1.FlinkCluster, container *corev1.Container) {
+	var monitoring = flinkCluster.Spec.Monitoring
+	if monitoring == nil {
+		return
+	}
+	var envVars []corev1.EnvVar
+	var args []string
+	for _, reporter := range monitoring.Reporters {
+		if reporter.Datadog == nil {
+			continue
+		}
+		var apiKeySecret = reporter.Datadog.APIKeySecret
+		var envVarName = "FLINK_METRICS_REPORTER_" +
+			strings.ToUpper(strings.ReplaceAll(reporter.Name, "-", "_")) + "_APIKEY"
+		envVars = append(envVars, corev1.EnvVar{
+			Name:      envVarName,
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &apiKeySecret},
+		})
+		args = append(args, fmt.Sprintf("-Dmetrics.reporter.%s.apikey=$(%s)", reporter.Name, envVarName))
+	}
+	if len(args) == 0 {
+		return
+	}
+	container.Env = append(append([]corev1.EnvVar{}, container.Env...), envVars...)
+	// The dynamic properties follow the command of the entrypoint, e.g. `taskmanager`, before
+	// the job arguments of application mode.
+	container.Args = append(append(append([]string{}, container.Args[:1]...), args...), container.Args[1:]...)
+}
+
+// getDiagnosticsJVMOptions returns the JVM options of the JobManager and TaskManagers to
+// write heap dumps to the diagnostics volume, and to exit after the dump if
+// spec.diagnostics.exitOnOutOfMemory is set.
+func getDiagnosticsJVMOptions(diagnostics *v1beta1.DiagnosticsSpec) []string {
+	if diagnostics == nil {
+		return nil
+	}
+	var options []string
+	if diagnostics.HeapDumpOnOutOfMemory == nil || *diagnostics.HeapDumpOnOutOfMemory {
+		options = append(options, "-XX:+HeapDumpOnOutOfMemoryError", "-XX:HeapDumpPath="+DiagnosticsPath)
+	}
+	if diagnostics.ExitOnOutOfMemory != nil && *diagnostics.ExitOnOutOfMemory {
+		options = append(options, "-XX:+ExitOnOutOfMemoryError")
+	}
+	return options
+}
+
+// ClusterLabels returns the labels of all the resources of the cluster.
+func ClusterLabels(cluster *v1beta1.FlinkCluster) map[string]string {
+	return map[string]string{
+		"cluster": cluster.Name,
+		"app":     "flink",
+	}
+}
+
+// ServiceAccountName returns the service account of the pods of the cluster, the one of the
+// Kubernetes HA services if spec.serviceAccountName is not set.
+func ServiceAccountName(flinkCluster *v1beta1.FlinkCluster) string {
+	if serviceAccount := flinkCluster.Spec.ServiceAccountName; serviceAccount != nil {
+		return *serviceAccount
+	}
+	if shouldCreateHARBAC(flinkCluster) {
+		return HAServiceAccountName(flinkCluster.Name)
+	}
+
+	return ""
+}
+
+// ComponentLabels returns the labels of the resources of a component of the cluster.
+func ComponentLabels(cluster *v1beta1.FlinkCluster, component string) map[string]string {
+	return MergeLabels(ClusterLabels(cluster), map[string]string{
+		"component": component,
+	})
+}
+
+func getRevisionHashLabels(r *v1beta1.RevisionStatus) map[string]string {
+	return map[string]string{
+		RevisionNameLabel: NextRevisionName(r),
+	}
+}
+
+// MergeLabels returns the union of the labels, those of labels2 taking precedence.
+func MergeLabels(labels1 map[string]string, labels2 map[string]string) map[string]string {
+	var mergedLabels = make(map[string]string)
+	for k, v := range labels1 {
+		mergedLabels[k] = v
+	}
+	for k, v := range labels2 {
+		mergedLabels[k] = v
+	}
+	return mergedLabels
+}
+
+const (
+	DefaultLog4jConfig = `log4j.rootLogger=INFO, console
+log4j.logger.akka=INFO
+log4j.logger.org.apache.kafka=INFO
+log4j.logger.org.apache.hadoop=INFO
+log4j.logger.org.apache.zookeeper=INFO
+log4j.appender.console=org.apache.log4j.ConsoleAppender
+log4j.appender.console.layout=org.apache.log4j.PatternLayout
+log4j.appender.console.layout.ConversionPattern=%d{yyyy-MM-dd HH:mm:ss,SSS} %-5p %-60c %x - %m%n
+log4j.logger.org.apache.flink.shaded.akka.org.jboss.netty.channel.DefaultChannelPipeline=ERROR, console`
+	DefaultLogbackConfig = `<configuration>
+    <appender name="console" class="ch.qos.logback.core.ConsoleAppender">
+        <encoder>
+            <pattern>%d{yyyy-MM-dd HH:mm:ss.SSS} [%thread] %-5level %logger{60} %X{sourceThread} - %msg%n</pattern>
+        </encoder>
+    </appender>
+    <root level="INFO">
+        <appender-ref ref="console"/>
+    </root>
+    <logger name="akka" level="INFO">
+        <appender-ref ref="console"/>
+    </logger>
+    <logger name="org.apache.kafka" level="INFO">
+        <appender-ref ref="console"/>
+    </logger>
+    <logger name="org.apache.hadoop" level="INFO">
+        <appender-ref ref="console"/>
+    </logger>
+    <logger name="org.apache.zookeeper" level="INFO">
+        <appender-ref ref="console"/>
+    </logger>
+    <logger name="org.apache.flink.shaded.akka.org.jboss.netty.channel.DefaultChannelPipeline" level="ERROR">
+        <appender-ref ref="console"/>
+    </logger>
+</configuration>`
+)
+
+// TODO: Wouldn't it be better to create a file, put it in an operator image, and read from them?.
+// Provide logging profiles
+func getLogConf(spec v1beta1.FlinkClusterSpec) map[string]string {
+	result := spec.LogConfig
+	if result == nil {
+		result = make(map[string]string, 2)
+	}
+	if _, isPresent := result["log4j-console.properties"]; !isPresent {
+		result["log4j-console.properties"] = DefaultLog4jConfig
+	}
+	if _, isPresent := result["log4j-cli.properties"]; !isPresent {
+		result["log4j-cli.properties"] = DefaultLog4jConfig
+	}
+	if _, isPresent := result["logback-console.xml"]; !isPresent {
+		result["logback-console.xml"] = DefaultLogbackConfig
+	}
+	return result
+}
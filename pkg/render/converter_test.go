@@ -0,0 +1,3226 @@
+/*
+Copyright 2019 Google LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render/rendertest"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Defaults used in the upcoming tests
+var (
+	controller                         = true
+	blockOwnerDeletion                 = false
+	parallelism        int32           = 2
+	jmRPCPort          int32           = 6123
+	jmBlobPort         int32           = 6124
+	jmQueryPort        int32           = 6125
+	jmUIPort           int32           = 8081
+	tmDataPort         int32           = 6121
+	tmRPCPort          int32           = 6122
+	tmQueryPort        int32           = 6125
+	replicas           int32           = 42
+	tolerationSeconds  int64           = 30
+	restartPolicy                      = v1beta1.JobRestartPolicyFromSavepointOnFailure
+	className                          = "org.apache.flink.examples.java.wordcount.WordCount"
+	serviceAccount                     = "default"
+	jarFile                            = "/cache/my-job.jar"
+	hostFormat                         = "{{$clusterName}}.example.com"
+	memoryOffHeapRatio int32           = 25
+	memoryOffHeapMin                   = resource.MustParse("600M")
+	memoryProcessRatio int32           = 80
+	jobMode            v1beta1.JobMode = v1beta1.JobModeDetached
+	jobBackoffLimit    int32           = 0
+	ingressPathType                    = networkingv1.PathTypePrefix
+	storageClassName                   = "default-class"
+	jmReadinessProbe                   = corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromInt(int(jmRPCPort)),
+			},
+		},
+		TimeoutSeconds:      10,
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       5,
+		FailureThreshold:    60,
+	}
+	jmLivenessProbe = corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromInt(int(jmRPCPort)),
+			},
+		},
+		TimeoutSeconds:      10,
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       60,
+		FailureThreshold:    5,
+	}
+	tmReadinessProbe = corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromInt(int(tmRPCPort)),
+			},
+		},
+		TimeoutSeconds:      10,
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       5,
+		FailureThreshold:    60,
+	}
+	tmLivenessProbe = corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromInt(int(tmRPCPort)),
+			},
+		},
+		TimeoutSeconds:      10,
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       60,
+		FailureThreshold:    5,
+	}
+	tolerations = []corev1.Toleration{
+		{
+			Key:               "toleration-key",
+			Effect:            "toleration-effect",
+			Operator:          "toleration-operator",
+			TolerationSeconds: &tolerationSeconds,
+			Value:             "toleration-value",
+		},
+		{
+			Key:               "toleration-key2",
+			Effect:            "toleration-effect2",
+			Operator:          "toleration-operator2",
+			TolerationSeconds: &tolerationSeconds,
+			Value:             "toleration-value2",
+		},
+	}
+	hostAliases = []corev1.HostAlias{
+		{
+			IP: "127.0.0.1",
+			Hostnames: []string{
+				"test-localhost-alias1",
+				"test-localhost-alias2",
+			},
+		},
+	}
+	userAndGroupId  int64 = 9999
+	securityContext       = corev1.PodSecurityContext{
+		RunAsUser:  &userAndGroupId,
+		RunAsGroup: &userAndGroupId,
+	}
+)
+
+func TestGetDesiredClusterState(t *testing.T) {
+
+	// Setup.
+	var cluster = rendertest.NewFlinkCluster()
+
+	// Run.
+	var desiredState = DesiredState(cluster, Options{})
+
+	// Verify.
+
+	// JmStatefulSet
+	var expectedDesiredJmStatefulSet = appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fjc-jobmanager",
+			Namespace: "default",
+			Labels: map[string]string{
+				"app":             "flink",
+				"cluster":         "fjc",
+				"component":       "jobmanager",
+				RevisionNameLabel: "fjc-85dc8f749",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         "flinkoperator.k8s.io/v1beta1",
+					Kind:               "FlinkCluster",
+					Name:               "fjc",
+					Controller:         &controller,
+					BlockOwnerDeletion: &blockOwnerDeletion,
+				},
+			},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app":       "flink",
+					"cluster":   "fjc",
+					"component": "jobmanager",
+				},
+			},
+			ServiceName: "fjc-jobmanager",
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app":       "flink",
+						"cluster":   "fjc",
+						"component": "jobmanager",
+					},
+					Annotations: map[string]string{
+						"example.com": "example",
+					},
+				},
+				Spec: corev1.PodSpec{
+					InitContainers: make([]corev1.Container, 0),
+					Containers: []corev1.Container{
+						{
+							Name:  "jobmanager",
+							Image: "flink:1.8.1",
+							Args:  []string{"jobmanager"},
+							Ports: []corev1.ContainerPort{
+								{Name: "rpc", ContainerPort: jmRPCPort},
+								{Name: "blob", ContainerPort: jmBlobPort},
+								{Name: "query", ContainerPort: jmQueryPort},
+								{Name: "ui", ContainerPort: jmUIPort},
+							},
+							LivenessProbe:  &jmLivenessProbe,
+							ReadinessProbe: &jmReadinessProbe,
+							Env: []corev1.EnvVar{
+								{
+									Name:  "FOO",
+									Value: "abc",
+								},
+								{Name: "HADOOP_CONF_DIR", Value: "/etc/hadoop/conf"},
+								{
+									Name:  "GOOGLE_APPLICATION_CREDENTIALS",
+									Value: "/etc/gcp_service_account/gcp_service_account_key.json",
+								},
+							},
+							EnvFrom: []corev1.EnvFromSource{
+								{
+									ConfigMapRef: &corev1.ConfigMapEnvSource{
+										LocalObjectReference: corev1.LocalObjectReference{
+											Name: "foomap",
+										},
+									},
+								},
+							},
+							Resources: corev1.ResourceRequirements{
+								Requests: map[corev1.ResourceName]resource.Quantity{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("256Mi"),
+								},
+								Limits: map[corev1.ResourceName]resource.Quantity{
+									corev1.ResourceCPU:    resource.MustParse("200m"),
+									corev1.ResourceMemory: resource.MustParse("512Mi"),
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "flink-config-volume",
+									MountPath: "/opt/flink/conf",
+								},
+								{
+									Name:      "hadoop-config-volume",
+									MountPath: "/etc/hadoop/conf",
+									ReadOnly:  true,
+								},
+								{
+									Name:      "gcp-service-account-volume",
+									MountPath: "/etc/gcp_service_account/",
+									ReadOnly:  true,
+								},
+							},
+							Lifecycle: &corev1.Lifecycle{
+								PreStop: &corev1.LifecycleHandler{
+									Exec: &corev1.ExecAction{
+										Command: []string{"sleep", strconv.Itoa(preStopSleepSeconds)},
+									},
+								},
+							},
+						},
+					},
+					TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
+					Tolerations:                   tolerations,
+					HostAliases:                   hostAliases,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:  &userAndGroupId,
+						RunAsGroup: &userAndGroupId,
+					},
+					ServiceAccountName: serviceAccount,
+					Volumes: []corev1.Volume{
+						{
+							Name: "flink-config-volume",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: "fjc-configmap",
+									},
+								},
+							},
+						},
+						{
+							Name: "hadoop-config-volume",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: "hadoop-configmap",
+									},
+								},
+							},
+						},
+						{
+							Name: "gcp-service-account-volume",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: "gcp-service-account-secret",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Assert(t, desiredState.JmStatefulSet != nil)
+	assert.DeepEqual(
+		t,
+		*desiredState.JmStatefulSet,
+		expectedDesiredJmStatefulSet,
+		cmpopts.IgnoreUnexported(resource.Quantity{}))
+
+	// JmService
+	var expectedDesiredJmService = corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fjc-jobmanager",
+			Namespace: "default",
+			Labels: map[string]string{
+				RevisionNameLabel: "fjc-85dc8f749",
+				"app":             "flink",
+				"cluster":         "fjc",
+				"component":       "jobmanager",
+			},
+			Annotations: map[string]string{
+				"networking.gke.io/load-balancer-type":                         "Internal",
+				"networking.gke.io/internal-load-balancer-allow-global-access": "true",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         "flinkoperator.k8s.io/v1beta1",
+					Kind:               "FlinkCluster",
+					Name:               "fjc",
+					Controller:         &controller,
+					BlockOwnerDeletion: &blockOwnerDeletion,
+				},
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type: "LoadBalancer",
+			Selector: map[string]string{
+				"app":       "flink",
+				"cluster":   "fjc",
+				"component": "jobmanager",
+			},
+			Ports: []corev1.ServicePort{
+				{Name: "rpc", Port: 6123, TargetPort: intstr.FromString("rpc")},
+				{Name: "blob", Port: 6124, TargetPort: intstr.FromString("blob")},
+				{Name: "query", Port: 6125, TargetPort: intstr.FromString("query")},
+				{Name: "ui", Port: 8081, TargetPort: intstr.FromString("ui")},
+			},
+		},
+	}
+	assert.Assert(t, desiredState.JmService != nil)
+	assert.DeepEqual(
+		t,
+		*desiredState.JmService,
+		expectedDesiredJmService)
+
+	// JmIngress
+	var expectedDesiredJmIngress = networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fjc-jobmanager",
+			Namespace: "default",
+			Labels: map[string]string{
+				"app":             "flink",
+				"cluster":         "fjc",
+				"component":       "jobmanager",
+				RevisionNameLabel: "fjc-85dc8f749",
+			},
+			Annotations: map[string]string{
+				"kubernetes.io/ingress.class":                "nginx",
+				"certmanager.k8s.io/cluster-issuer":          "letsencrypt-stg",
+				"nginx.ingress.kubernetes.io/rewrite-target": "/",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         "flinkoperator.k8s.io/v1beta1",
+					Kind:               "FlinkCluster",
+					Name:               "fjc",
+					Controller:         &controller,
+					BlockOwnerDeletion: &blockOwnerDeletion,
+				},
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{
+				Host: "fjc.example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &ingressPathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: "fjc-jobmanager",
+									Port: networkingv1.ServiceBackendPort{
+										Name: "ui",
+									},
+								},
+							}},
+						}},
+				},
+			}},
+			TLS: []networkingv1.IngressTLS{{
+				Hosts: []string{"fjc.example.com"},
+			}},
+		},
+	}
+
+	assert.Assert(t, desiredState.JmIngress != nil)
+	assert.DeepEqual(
+		t,
+		*desiredState.JmIngress,
+		expectedDesiredJmIngress)
+
+	// TmStatefulSet
+	var expectedDesiredTmStatefulSet = appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fjc-taskmanager",
+			Namespace: "default",
+			Labels: map[string]string{
+				"app":             "flink",
+				"cluster":         "fjc",
+				"component":       "taskmanager",
+				RevisionNameLabel: "fjc-85dc8f749",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         "flinkoperator.k8s.io/v1beta1",
+					Kind:               "FlinkCluster",
+					Name:               "fjc",
+					Controller:         &controller,
+					BlockOwnerDeletion: &blockOwnerDeletion,
+				},
+			},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:            &replicas,
+			ServiceName:         "fjc-taskmanager",
+			PodManagementPolicy: "Parallel",
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app":       "flink",
+					"cluster":   "fjc",
+					"component": "taskmanager",
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pvc-test",
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								APIVersion:         "flinkoperator.k8s.io/v1beta1",
+								Kind:               "FlinkCluster",
+								Name:               "fjc",
+								Controller:         &controller,
+								BlockOwnerDeletion: &blockOwnerDeletion,
+							},
+						},
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources: corev1.ResourceRequirements{
+							Requests: map[corev1.ResourceName]resource.Quantity{
+								corev1.ResourceStorage: resource.MustParse("100Gi"),
+							},
+						},
+						StorageClassName: &storageClassName,
+					},
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app":       "flink",
+						"cluster":   "fjc",
+						"component": "taskmanager",
+					},
+					Annotations: map[string]string{
+						"example.com": "example",
+					},
+				},
+				Spec: corev1.PodSpec{
+					InitContainers: make([]corev1.Container, 0),
+					Containers: []corev1.Container{
+						{
+							Name:  "taskmanager",
+							Image: "flink:1.8.1",
+							Args:  []string{"taskmanager"},
+							Ports: []corev1.ContainerPort{
+								{Name: "data", ContainerPort: 6121},
+								{Name: "rpc", ContainerPort: 6122},
+								{Name: "query", ContainerPort: 6125},
+							},
+							LivenessProbe:  &tmLivenessProbe,
+							ReadinessProbe: &tmReadinessProbe,
+							Env: []corev1.EnvVar{
+								{
+									Name:  "FOO",
+									Value: "abc",
+								},
+								{Name: "HADOOP_CONF_DIR", Value: "/etc/hadoop/conf"},
+								{
+									Name:  "GOOGLE_APPLICATION_CREDENTIALS",
+									Value: "/etc/gcp_service_account/gcp_service_account_key.json",
+								},
+							},
+							EnvFrom: []corev1.EnvFromSource{
+								{
+									ConfigMapRef: &corev1.ConfigMapEnvSource{
+										LocalObjectReference: corev1.LocalObjectReference{
+											Name: "foomap",
+										},
+									},
+								},
+							},
+							Resources: corev1.ResourceRequirements{
+								Requests: map[corev1.ResourceName]resource.Quantity{
+									corev1.ResourceCPU:    resource.MustParse("200m"),
+									corev1.ResourceMemory: resource.MustParse("512Mi"),
+								},
+								Limits: map[corev1.ResourceName]resource.Quantity{
+									corev1.ResourceCPU:    resource.MustParse("500m"),
+									corev1.ResourceMemory: resource.MustParse("1Gi"),
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "cache-volume", MountPath: "/cache"},
+								{Name: "flink-config-volume", MountPath: "/opt/flink/conf"},
+								{
+									Name:      "hadoop-config-volume",
+									MountPath: "/etc/hadoop/conf",
+									ReadOnly:  true,
+								},
+								{
+									Name:      "gcp-service-account-volume",
+									MountPath: "/etc/gcp_service_account/",
+									ReadOnly:  true,
+								},
+							},
+							Lifecycle: &corev1.Lifecycle{
+								PreStop: &corev1.LifecycleHandler{
+									Exec: &corev1.ExecAction{
+										Command: []string{"sleep", strconv.Itoa(preStopSleepSeconds)},
+									},
+								},
+							},
+						},
+						{Name: "sidecar", Image: "alpine"},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "cache-volume",
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
+							},
+						},
+						{
+							Name: "flink-config-volume",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: "fjc-configmap",
+									},
+								},
+							},
+						},
+						{
+							Name: "hadoop-config-volume",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: "hadoop-configmap",
+									},
+								},
+							},
+						},
+						{
+							Name: "gcp-service-account-volume",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: "gcp-service-account-secret",
+								},
+							},
+						},
+					},
+					TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
+					Tolerations:                   tolerations,
+					HostAliases:                   hostAliases,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:  &userAndGroupId,
+						RunAsGroup: &userAndGroupId,
+					},
+					ServiceAccountName: serviceAccount,
+				},
+			},
+		},
+	}
+
+	assert.Assert(t, desiredState.TmStatefulSet != nil)
+	assert.DeepEqual(
+		t,
+		*desiredState.TmStatefulSet,
+		expectedDesiredTmStatefulSet,
+		cmpopts.IgnoreUnexported(resource.Quantity{}))
+
+	// Job
+	var expectedDesiredJob = batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fjc-job-submitter",
+			Namespace: "default",
+			Labels: map[string]string{
+				"app":             "flink",
+				"cluster":         "fjc",
+				RevisionNameLabel: "fjc-85dc8f749",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "flinkoperator.k8s.io/v1beta1",
+					Kind:               "FlinkCluster",
+					Name:               "fjc",
+					Controller:         &controller,
+					BlockOwnerDeletion: &blockOwnerDeletion,
+				},
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app":                                "flink",
+						"cluster":                            "fjc",
+						"flinkoperator.k8s.io/revision-name": "fjc-85dc8f749",
+					},
+					Annotations: map[string]string{
+						"example.com": "example",
+					},
+				},
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{
+						{
+							Name:    "gcs-downloader",
+							Image:   "google/cloud-sdk",
+							Command: []string{"gsutil"},
+							Args: []string{
+								"cp", "gs://my-bucket/my-job.jar", "/cache/my-job.jar",
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "cache-volume", MountPath: "/cache"},
+								{
+									Name:      "flink-config-volume",
+									MountPath: "/opt/flink-operator/submit-job.sh",
+									SubPath:   "submit-job.sh",
+								},
+								{Name: "flink-config-volume", MountPath: "/opt/flink/conf"},
+								{Name: "hadoop-config-volume", ReadOnly: true, MountPath: "/etc/hadoop/conf"},
+								{Name: "gcp-service-account-volume", ReadOnly: true, MountPath: "/etc/gcp_service_account/"},
+							},
+							Env: []corev1.EnvVar{
+								{Name: "FLINK_JM_ADDR", Value: "fjc-jobmanager:8081"},
+								{Name: "FLINK_CLUSTER_NAME", Value: "fjc"},
+								{Name: "FLINK_CLUSTER_NAMESPACE", Value: "default"},
+								{Name: "FLINK_CLUSTER_REVISION", Value: "fjc-85dc8f749"},
+								{Name: "FOO", Value: "abc"},
+								{Name: "HADOOP_CONF_DIR", Value: "/etc/hadoop/conf"},
+								{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: "/etc/gcp_service_account/gcp_service_account_key.json"},
+							},
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:  "main",
+							Image: "flink:1.8.1",
+							Args: []string{
+								"bash",
+								"/opt/flink-operator/submit-job.sh",
+								"--jobmanager",
+								"fjc-jobmanager:8081",
+								"--class",
+								"org.apache.flink.examples.java.wordcount.WordCount",
+								"--parallelism",
+								"2",
+								"--detached",
+								"/cache/my-job.jar",
+								"--input",
+								"./README.txt",
+							},
+							Env: []corev1.EnvVar{
+								{Name: "FLINK_JM_ADDR", Value: "fjc-jobmanager:8081"},
+								{Name: "FLINK_CLUSTER_NAME", Value: "fjc"},
+								{Name: "FLINK_CLUSTER_NAMESPACE", Value: "default"},
+								{Name: "FLINK_CLUSTER_REVISION", Value: "fjc-85dc8f749"},
+								{Name: "FOO", Value: "abc"},
+								{Name: "HADOOP_CONF_DIR", Value: "/etc/hadoop/conf"},
+								{
+									Name:  "GOOGLE_APPLICATION_CREDENTIALS",
+									Value: "/etc/gcp_service_account/gcp_service_account_key.json",
+								},
+							},
+							EnvFrom: []corev1.EnvFromSource{
+								{
+									ConfigMapRef: &corev1.ConfigMapEnvSource{
+										LocalObjectReference: corev1.LocalObjectReference{
+											Name: "foomap",
+										},
+									},
+								},
+							},
+							Resources: corev1.ResourceRequirements{
+								Requests: map[corev1.ResourceName]resource.Quantity{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("256Mi"),
+								},
+								Limits: map[corev1.ResourceName]resource.Quantity{
+									corev1.ResourceCPU:    resource.MustParse("200m"),
+									corev1.ResourceMemory: resource.MustParse("512Mi"),
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "cache-volume", MountPath: "/cache"},
+								{
+									Name:      "flink-config-volume",
+									MountPath: "/opt/flink-operator/submit-job.sh",
+									SubPath:   "submit-job.sh",
+								},
+								{
+									Name:      "flink-config-volume",
+									MountPath: "/opt/flink/conf",
+								},
+								{
+									Name:      "hadoop-config-volume",
+									MountPath: "/etc/hadoop/conf",
+									ReadOnly:  true,
+								},
+								{
+									Name:      "gcp-service-account-volume",
+									MountPath: "/etc/gcp_service_account/",
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					RestartPolicy: corev1.RestartPolicyNever,
+					Volumes: []corev1.Volume{
+						{
+							Name: "cache-volume",
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
+							},
+						},
+						{
+							Name: "flink-config-volume",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: "fjc-configmap",
+									},
+								},
+							},
+						},
+						{
+							Name: "hadoop-config-volume",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: "hadoop-configmap",
+									},
+								},
+							},
+						},
+						{
+							Name: "gcp-service-account-volume",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: "gcp-service-account-secret",
+								},
+							},
+						},
+					},
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:  &userAndGroupId,
+						RunAsGroup: &userAndGroupId,
+					},
+					HostAliases:        hostAliases,
+					ServiceAccountName: serviceAccount,
+				},
+			},
+			BackoffLimit: &jobBackoffLimit,
+		},
+	}
+
+	assert.Assert(t, desiredState.Job != nil)
+	assert.DeepEqual(
+		t,
+		*desiredState.Job,
+		expectedDesiredJob)
+
+	// ConfigMap
+	var flinkConfYaml = `blob.server.port: 6124
+jobmanager.rpc.address: fjc-jobmanager
+jobmanager.rpc.port: 6123
+query.server.port: 6125
+rest.port: 8081
+taskmanager.heap.size: 452m
+taskmanager.numberOfTaskSlots: 1
+taskmanager.rpc.port: 6122
+`
+	var expectedConfigMap = corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fjc-configmap",
+			Namespace: "default",
+			Labels: map[string]string{
+				"app":             "flink",
+				"cluster":         "fjc",
+				RevisionNameLabel: "fjc-85dc8f749",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         "flinkoperator.k8s.io/v1beta1",
+					Kind:               "FlinkCluster",
+					Name:               "fjc",
+					Controller:         &controller,
+					BlockOwnerDeletion: &blockOwnerDeletion,
+				},
+			},
+		},
+		Data: map[string]string{
+			"flink-conf.yaml":          flinkConfYaml,
+			"extra-file.txt":           "hello!",
+			"log4j-console.properties": "foo",
+			"log4j-cli.properties":     "baz",
+			"logback-console.xml":      "bar",
+			"submit-job.sh":            submitJobScript,
+		},
+	}
+	assert.Assert(t, desiredState.ConfigMap != nil)
+	assert.DeepEqual(
+		t,
+		*desiredState.ConfigMap,
+		expectedConfigMap)
+}
+
+func TestTmDeploymentTypeDeployment(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	cluster.Spec.TaskManager.DeploymentType = v1beta1.DeploymentTypeDeployment
+
+	var desired = DesiredState(cluster, Options{})
+
+	assert.Assert(t, desired.TmStatefulSet == nil)
+
+	var expectedDesiredTmDeployment = appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fjc-taskmanager",
+			Namespace: "default",
+			Labels: map[string]string{
+				"app":             "flink",
+				"cluster":         "fjc",
+				"component":       "taskmanager",
+				RevisionNameLabel: "fjc-85dc8f749",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         "flinkoperator.k8s.io/v1beta1",
+					Kind:               "FlinkCluster",
+					Name:               "fjc",
+					Controller:         &controller,
+					BlockOwnerDeletion: &blockOwnerDeletion,
+				},
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app":       "flink",
+					"cluster":   "fjc",
+					"component": "taskmanager",
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app":       "flink",
+						"cluster":   "fjc",
+						"component": "taskmanager",
+					},
+					Annotations: map[string]string{
+						"example.com": "example",
+					},
+				},
+				Spec: corev1.PodSpec{
+					InitContainers: make([]corev1.Container, 0),
+					Containers: []corev1.Container{
+						{
+							Name:  "taskmanager",
+							Image: "flink:1.8.1",
+							Args:  []string{"taskmanager"},
+							Ports: []corev1.ContainerPort{
+								{Name: "data", ContainerPort: 6121},
+								{Name: "rpc", ContainerPort: 6122},
+								{Name: "query", ContainerPort: 6125},
+							},
+							LivenessProbe:  &tmLivenessProbe,
+							ReadinessProbe: &tmReadinessProbe,
+							Env: []corev1.EnvVar{
+								{
+									Name:  "FOO",
+									Value: "abc",
+								},
+								{Name: "HADOOP_CONF_DIR", Value: "/etc/hadoop/conf"},
+								{
+									Name:  "GOOGLE_APPLICATION_CREDENTIALS",
+									Value: "/etc/gcp_service_account/gcp_service_account_key.json",
+								},
+							},
+							EnvFrom: []corev1.EnvFromSource{
+								{
+									ConfigMapRef: &corev1.ConfigMapEnvSource{
+										LocalObjectReference: corev1.LocalObjectReference{
+											Name: "foomap",
+										},
+									},
+								},
+							},
+							Resources: corev1.ResourceRequirements{
+								Requests: map[corev1.ResourceName]resource.Quantity{
+									corev1.ResourceCPU:    resource.MustParse("200m"),
+									corev1.ResourceMemory: resource.MustParse("512Mi"),
+								},
+								Limits: map[corev1.ResourceName]resource.Quantity{
+									corev1.ResourceCPU:    resource.MustParse("500m"),
+									corev1.ResourceMemory: resource.MustParse("1Gi"),
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "cache-volume", MountPath: "/cache"},
+								{Name: "flink-config-volume", MountPath: "/opt/flink/conf"},
+								{
+									Name:      "hadoop-config-volume",
+									MountPath: "/etc/hadoop/conf",
+									ReadOnly:  true,
+								},
+								{
+									Name:      "gcp-service-account-volume",
+									MountPath: "/etc/gcp_service_account/",
+									ReadOnly:  true,
+								},
+							},
+							Lifecycle: &corev1.Lifecycle{
+								PreStop: &corev1.LifecycleHandler{
+									Exec: &corev1.ExecAction{
+										Command: []string{"sleep", strconv.Itoa(preStopSleepSeconds)},
+									},
+								},
+							},
+						},
+						{Name: "sidecar", Image: "alpine"},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "cache-volume",
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
+							},
+						},
+						{
+							Name: "flink-config-volume",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: "fjc-configmap",
+									},
+								},
+							},
+						},
+						{
+							Name: "hadoop-config-volume",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: "hadoop-configmap",
+									},
+								},
+							},
+						},
+						{
+							Name: "gcp-service-account-volume",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: "gcp-service-account-secret",
+								},
+							},
+						},
+						{
+							Name: "pvc-test",
+							VolumeSource: corev1.VolumeSource{
+								Ephemeral: &corev1.EphemeralVolumeSource{
+									VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+										ObjectMeta: metav1.ObjectMeta{
+											Labels: map[string]string{
+												"app":       "flink",
+												"cluster":   "fjc",
+												"component": "taskmanager",
+											},
+										},
+										Spec: corev1.PersistentVolumeClaimSpec{
+											AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+											Resources: corev1.ResourceRequirements{
+												Requests: map[corev1.ResourceName]resource.Quantity{
+													corev1.ResourceStorage: resource.MustParse("100Gi"),
+												},
+											},
+											StorageClassName: &storageClassName,
+										},
+									},
+								},
+							},
+						},
+					},
+					TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
+					Tolerations:                   tolerations,
+					HostAliases:                   hostAliases,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:  &userAndGroupId,
+						RunAsGroup: &userAndGroupId,
+					},
+					ServiceAccountName: serviceAccount,
+				},
+			},
+		},
+	}
+
+	assert.Assert(t, desired.TmDeployment != nil)
+	assert.DeepEqual(
+		t,
+		*desired.TmDeployment,
+		expectedDesiredTmDeployment,
+		cmpopts.IgnoreUnexported(resource.Quantity{}))
+}
+
+func TestSecurityContext(t *testing.T) {
+	var jmRPCPort int32 = 6123
+	var jmBlobPort int32 = 6124
+	var jmQueryPort int32 = 6125
+	var jmUIPort int32 = 8081
+	var tmDataPort int32 = 6121
+	var tmRPCPort int32 = 6122
+	var tmQueryPort int32 = 6125
+	var tmReplicas int32 = v1beta1.DefaultTaskManagerReplicas
+
+	var userAndGroupId int64 = 9999
+	var securityContext = corev1.PodSecurityContext{
+		RunAsUser:  &userAndGroupId,
+		RunAsGroup: &userAndGroupId,
+	}
+	var jobMode = v1beta1.JobModeDetached
+
+	// Provided security context
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fjc",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			Job: &v1beta1.JobSpec{
+				Mode:            &jobMode,
+				SecurityContext: &securityContext,
+			},
+			JobManager: &v1beta1.JobManagerSpec{
+				AccessScope: v1beta1.AccessScopeVPC,
+				Ports: v1beta1.JobManagerPorts{
+					RPC:   &jmRPCPort,
+					Blob:  &jmBlobPort,
+					Query: &jmQueryPort,
+					UI:    &jmUIPort,
+				},
+				SecurityContext: &securityContext,
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				Replicas:       &tmReplicas,
+				DeploymentType: v1beta1.DeploymentTypeStatefulSet,
+				Ports: v1beta1.TaskManagerPorts{
+					Data:  &tmDataPort,
+					RPC:   &tmRPCPort,
+					Query: &tmQueryPort,
+				},
+				SecurityContext: &securityContext,
+			},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{NextRevision: "fjc-85dc8f749-1"},
+		},
+	}
+
+	var desired = DesiredState(cluster, Options{})
+
+	assert.DeepEqual(t, desired.Job.Spec.Template.Spec.SecurityContext, &securityContext)
+	assert.DeepEqual(t, desired.JmStatefulSet.Spec.Template.Spec.SecurityContext, &securityContext)
+	assert.DeepEqual(t, desired.TmStatefulSet.Spec.Template.Spec.SecurityContext, &securityContext)
+
+	// No security context
+	var cluster2 = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fjc",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			Job: &v1beta1.JobSpec{Mode: &jobMode},
+			JobManager: &v1beta1.JobManagerSpec{
+				AccessScope: v1beta1.AccessScopeVPC,
+				Ports: v1beta1.JobManagerPorts{
+					RPC:   &jmRPCPort,
+					Blob:  &jmBlobPort,
+					Query: &jmQueryPort,
+					UI:    &jmUIPort,
+				},
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				Replicas:       &tmReplicas,
+				DeploymentType: v1beta1.DeploymentTypeStatefulSet,
+				Ports: v1beta1.TaskManagerPorts{
+					Data:  &tmDataPort,
+					RPC:   &tmRPCPort,
+					Query: &tmQueryPort,
+				},
+			},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{NextRevision: "fjc-85dc8f749-1"},
+		},
+	}
+
+	var desired2 = DesiredState(cluster2, Options{})
+
+	assert.Assert(t, desired2.Job.Spec.Template.Spec.SecurityContext == nil)
+	assert.Assert(t, desired2.JmStatefulSet.Spec.Template.Spec.SecurityContext == nil)
+	assert.Assert(t, desired2.TmStatefulSet.Spec.Template.Spec.SecurityContext == nil)
+}
+
+func TestCalFlinkHeapSize(t *testing.T) {
+	var memoryOffHeapRatio int32 = 25
+	var memoryOffHeapMin = resource.MustParse("600M")
+
+	// Case 1: Heap sizes are computed from memoryOffHeapMin or memoryOffHeapRatio
+	cluster := &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mycluster",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			JobManager: &v1beta1.JobManagerSpec{
+				Resources: corev1.ResourceRequirements{
+					Limits: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+				},
+				MemoryOffHeapRatio: &memoryOffHeapRatio,
+				MemoryOffHeapMin:   memoryOffHeapMin,
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				Resources: corev1.ResourceRequirements{
+					Limits: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceMemory: resource.MustParse("4Gi"),
+					},
+				},
+				MemoryOffHeapRatio: &memoryOffHeapRatio,
+				MemoryOffHeapMin:   memoryOffHeapMin,
+			},
+		},
+	}
+
+	flinkHeapSize := calFlinkHeapSize(cluster)
+	expectedFlinkHeapSize := map[string]string{
+		"jobmanager.heap.size":  "452m",  // get values calculated with limit - memoryOffHeapMin
+		"taskmanager.heap.size": "3072m", // get values calculated with limit - limit * memoryOffHeapRatio / 100
+	}
+	assert.Assert(t, len(flinkHeapSize) == 2)
+	assert.DeepEqual(
+		t,
+		flinkHeapSize,
+		expectedFlinkHeapSize)
+
+	// Case 2: No values when memory limits are missing or insufficient
+	cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mycluster",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			JobManager: &v1beta1.JobManagerSpec{
+				Resources: corev1.ResourceRequirements{
+					Limits: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceMemory: resource.MustParse("500Mi"),
+					},
+				},
+				MemoryOffHeapRatio: &memoryOffHeapRatio,
+				MemoryOffHeapMin:   memoryOffHeapMin,
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				MemoryOffHeapRatio: &memoryOffHeapRatio,
+				MemoryOffHeapMin:   memoryOffHeapMin,
+			},
+		},
+	}
+
+	flinkHeapSize = calFlinkHeapSize(cluster)
+	assert.Assert(t, len(flinkHeapSize) == 0)
+}
+
+func TestCalFlinkMemoryProcessSize(t *testing.T) {
+	var memoryProcessRatio int32 = 80
+
+	// Case 1: memory size is computed from memoryProcessRatio
+	cluster := &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mycluster",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			JobManager: &v1beta1.JobManagerSpec{
+				Resources: corev1.ResourceRequirements{
+					Limits: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+				},
+				MemoryProcessRatio: &memoryProcessRatio,
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				Resources: corev1.ResourceRequirements{
+					Limits: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceMemory: resource.MustParse("4Gi"),
+					},
+				},
+				MemoryProcessRatio: &memoryProcessRatio,
+			},
+		},
+	}
+
+	flinkHeapSize := calFlinkMemoryProcessSize(cluster)
+	expectedFlinkHeapSize := map[string]string{
+		"jobmanager.memory.process.size":  "820m",
+		"taskmanager.memory.process.size": "3277m",
+	}
+	assert.Assert(t, len(flinkHeapSize) == 2)
+	assert.DeepEqual(
+		t,
+		flinkHeapSize,
+		expectedFlinkHeapSize)
+
+	// Case 2: No values when memory limits are missing or insufficient
+	cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mycluster",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			JobManager: &v1beta1.JobManagerSpec{
+				Resources: corev1.ResourceRequirements{
+					Limits: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceMemory: resource.MustParse("500Mi"),
+					},
+				},
+				MemoryProcessRatio: &memoryProcessRatio,
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				MemoryProcessRatio: &memoryProcessRatio,
+			},
+		},
+	}
+
+	flinkHeapSize = calFlinkMemoryProcessSize(cluster)
+	assert.Assert(t, len(flinkHeapSize) == 1)
+}
+
+func Test_getLogConf(t *testing.T) {
+	type args struct {
+		spec v1beta1.FlinkClusterSpec
+	}
+	tests := []struct {
+		name string
+		args args
+		want map[string]string
+	}{
+		{
+			name: "nil map uses defaults",
+			args: args{v1beta1.FlinkClusterSpec{LogConfig: nil}},
+			want: map[string]string{
+				"log4j-console.properties": DefaultLog4jConfig,
+				"log4j-cli.properties":     DefaultLog4jConfig,
+				"logback-console.xml":      DefaultLogbackConfig,
+			},
+		},
+		{
+			name: "map missing log4j-console.properties uses default",
+			args: args{v1beta1.FlinkClusterSpec{LogConfig: map[string]string{
+				"logback-console.xml": "xyz",
+			}}},
+			want: map[string]string{
+				"log4j-console.properties": DefaultLog4jConfig,
+				"log4j-cli.properties":     DefaultLog4jConfig,
+				"logback-console.xml":      "xyz",
+			},
+		},
+		{
+			name: "map missing logback-console.xml uses default",
+			args: args{v1beta1.FlinkClusterSpec{LogConfig: map[string]string{
+				"log4j-console.properties": "xyz",
+			}}},
+			want: map[string]string{
+				"log4j-console.properties": "xyz",
+				"log4j-cli.properties":     DefaultLog4jConfig,
+				"logback-console.xml":      DefaultLogbackConfig,
+			},
+		},
+		{
+			name: "map missing log4j-cli.properties uses default",
+			args: args{v1beta1.FlinkClusterSpec{LogConfig: map[string]string{
+				"log4j-console.properties": "xyz",
+				"logback-console.xml":      "xyz",
+			}}},
+			want: map[string]string{
+				"log4j-console.properties": "xyz",
+				"log4j-cli.properties":     DefaultLog4jConfig,
+				"logback-console.xml":      "xyz",
+			},
+		},
+		{
+			name: "map with all three keys overrides defaults",
+			args: args{v1beta1.FlinkClusterSpec{LogConfig: map[string]string{
+				"log4j-console.properties": "hello",
+				"log4j-cli.properties":     "xyz",
+				"logback-console.xml":      "world",
+			}}},
+			want: map[string]string{
+				"log4j-console.properties": "hello",
+				"logback-console.xml":      "world",
+				"log4j-cli.properties":     "xyz",
+			},
+		},
+		{
+			name: "extra keys preserved",
+			args: args{v1beta1.FlinkClusterSpec{LogConfig: map[string]string{
+				"log4j-console.properties": "abc",
+				"file.txt":                 "def",
+			}}},
+			want: map[string]string{
+				"log4j-console.properties": "abc",
+				"log4j-cli.properties":     DefaultLog4jConfig,
+				"logback-console.xml":      DefaultLogbackConfig,
+				"file.txt":                 "def",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getLogConf(tt.args.spec); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getLogConf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassPath(t *testing.T) {
+	var jmRPCPort int32 = 6123
+	var jmBlobPort int32 = 6124
+	var jmQueryPort int32 = 6125
+	var jmUIPort int32 = 8081
+	var tmDataPort int32 = 6121
+	var tmRPCPort int32 = 6122
+	var tmQueryPort int32 = 6125
+	var tmReplicas int32 = v1beta1.DefaultTaskManagerReplicas
+
+	var jobMode = v1beta1.JobModeDetached
+	var jarFile = "/cache/my-job.jar"
+	var className = "org.apache.flink.examples.java.wordcount.WordCount"
+	var parallelism int32 = 3
+
+	// Provided security context
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fjc",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			Job: &v1beta1.JobSpec{
+				Mode: &jobMode,
+				ClassPath: []string{
+					"gs://bucketname/staging/grpc-stub-1.41.1-B66xkeEg13eE_nKyL0lylg.jar",
+					"gs://bucketname/staging/better-files_2.12-3.8.0-1q2TM0GQpc7_Sq5wOwjsgw.jar",
+					"gs://bucketname/staging/opencsv-2.3-nuurqgB9wymEXlqzwStOaw.jar",
+					"gs://bucketname/staging/scio-parquet_2.12-0.11.4-iG_V62XRj-25MYxrNRj-Sg.jar",
+					"gs://bucketname/staging/slf4j-jdk14-1.7.33-iTAADCGj87621OCVYpg-8w.jar",
+				},
+				Args:        []string{"--input", "./README.txt"},
+				ClassName:   &className,
+				JarFile:     &jarFile,
+				Parallelism: &parallelism,
+			},
+			JobManager: &v1beta1.JobManagerSpec{
+				AccessScope: v1beta1.AccessScopeVPC,
+				Ports: v1beta1.JobManagerPorts{
+					RPC:   &jmRPCPort,
+					Blob:  &jmBlobPort,
+					Query: &jmQueryPort,
+					UI:    &jmUIPort,
+				},
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				Replicas: &tmReplicas,
+				Ports: v1beta1.TaskManagerPorts{
+					Data:  &tmDataPort,
+					RPC:   &tmRPCPort,
+					Query: &tmQueryPort,
+				},
+			},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{NextRevision: "fjc-85dc8f749-1"},
+		},
+	}
+
+	var desired = DesiredState(cluster, Options{})
+
+	expectedArgs := []string{
+		"bash", "/opt/flink-operator/submit-job.sh",
+		"--jobmanager", "fjc-jobmanager:8081",
+		"--class", className,
+		"--parallelism", strconv.FormatInt(int64(parallelism), 10),
+		"--detached",
+		"-C", "gs://bucketname/staging/grpc-stub-1.41.1-B66xkeEg13eE_nKyL0lylg.jar",
+		"-C", "gs://bucketname/staging/better-files_2.12-3.8.0-1q2TM0GQpc7_Sq5wOwjsgw.jar",
+		"-C", "gs://bucketname/staging/opencsv-2.3-nuurqgB9wymEXlqzwStOaw.jar",
+		"-C", "gs://bucketname/staging/scio-parquet_2.12-0.11.4-iG_V62XRj-25MYxrNRj-Sg.jar",
+		"-C", "gs://bucketname/staging/slf4j-jdk14-1.7.33-iTAADCGj87621OCVYpg-8w.jar",
+		jarFile,
+		"--input", "./README.txt",
+	}
+
+	args := desired.Job.Spec.Template.Spec.Containers[0].Args
+
+	assert.DeepEqual(t, args, expectedArgs)
+	assert.DeepEqual(t, desired.Job.Spec.Template.Spec.Containers[0].Env, []corev1.EnvVar{
+		{Name: "FLINK_JM_ADDR", Value: "fjc-jobmanager:8081"},
+		{Name: "FLINK_CLUSTER_NAME", Value: "fjc"},
+		{Name: "FLINK_CLUSTER_NAMESPACE", Value: "default"},
+		{Name: "FLINK_CLUSTER_REVISION", Value: "fjc-85dc8f749"},
+	})
+
+	// The submitter resolves the entry class from the JAR file when it is not specified.
+	cluster.Spec.Job.ClassName = nil
+	desired = DesiredState(cluster, Options{})
+	assert.DeepEqual(t, desired.Job.Spec.Template.Spec.Containers[0].Env, []corev1.EnvVar{
+		{Name: "FLINK_JM_ADDR", Value: "fjc-jobmanager:8081"},
+		{Name: "FLINK_CLUSTER_NAME", Value: "fjc"},
+		{Name: "FLINK_CLUSTER_NAMESPACE", Value: "default"},
+		{Name: "FLINK_CLUSTER_REVISION", Value: "fjc-85dc8f749"},
+		{Name: "FLINK_JOB_JAR_FILE", Value: jarFile},
+	})
+}
+
+func TestExtraConfigMounts(t *testing.T) {
+	var jmRPCPort int32 = 6123
+	var jmBlobPort int32 = 6124
+	var jmQueryPort int32 = 6125
+	var jmUIPort int32 = 8081
+	var tmDataPort int32 = 6121
+	var tmRPCPort int32 = 6122
+	var tmQueryPort int32 = 6125
+	var tmReplicas int32 = v1beta1.DefaultTaskManagerReplicas
+	var jarFile = "/cache/my-job.jar"
+
+	var extraConfigMounts = []v1beta1.ExtraConfigMount{
+		{
+			ConfigMap: &corev1.ConfigMapProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "hadoop-conf"},
+				Items:                []corev1.KeyToPath{{Key: "core-site.xml", Path: "core-site.xml"}},
+			},
+		},
+		{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "krb5"},
+			},
+		},
+	}
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fjc",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			Job: &v1beta1.JobSpec{
+				JarFile: &jarFile,
+			},
+			JobManager: &v1beta1.JobManagerSpec{
+				AccessScope: v1beta1.AccessScopeVPC,
+				Ports: v1beta1.JobManagerPorts{
+					RPC:   &jmRPCPort,
+					Blob:  &jmBlobPort,
+					Query: &jmQueryPort,
+					UI:    &jmUIPort,
+				},
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				Replicas:       &tmReplicas,
+				DeploymentType: v1beta1.DeploymentTypeStatefulSet,
+				Ports: v1beta1.TaskManagerPorts{
+					Data:  &tmDataPort,
+					RPC:   &tmRPCPort,
+					Query: &tmQueryPort,
+				},
+			},
+			ExtraConfigMounts: extraConfigMounts,
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{NextRevision: "fjc-85dc8f749-1"},
+		},
+	}
+
+	var desired = DesiredState(cluster, Options{})
+
+	var expectedVolume = corev1.Volume{
+		Name: "flink-config-volume",
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{
+						ConfigMap: &corev1.ConfigMapProjection{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "fjc-configmap"},
+						},
+					},
+					{ConfigMap: extraConfigMounts[0].ConfigMap},
+					{Secret: extraConfigMounts[1].Secret},
+				},
+			},
+		},
+	}
+	var podSpecs = []corev1.PodSpec{
+		desired.JmStatefulSet.Spec.Template.Spec,
+		desired.TmStatefulSet.Spec.Template.Spec,
+		desired.Job.Spec.Template.Spec,
+	}
+	for _, podSpec := range podSpecs {
+		var found = false
+		for _, volume := range podSpec.Volumes {
+			if volume.Name == "flink-config-volume" {
+				assert.DeepEqual(t, volume, expectedVolume)
+				found = true
+			}
+		}
+		assert.Assert(t, found, "flink-config-volume is expected")
+	}
+}
+
+func TestSecretsInjection(t *testing.T) {
+	var jmRPCPort int32 = 6123
+	var jmBlobPort int32 = 6124
+	var jmQueryPort int32 = 6125
+	var jmUIPort int32 = 8081
+	var tmDataPort int32 = 6121
+	var tmRPCPort int32 = 6122
+	var tmQueryPort int32 = 6125
+	var tmReplicas int32 = v1beta1.DefaultTaskManagerReplicas
+	var jarFile = "/cache/my-job.jar"
+	var jaasConfig = `org.apache.kafka.common.security.plain.PlainLoginModule required password="${KAFKA_PASSWORD}";`
+	var secretKeyRef = corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "kafka"},
+		Key:                  "password",
+	}
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fjc",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			Job: &v1beta1.JobSpec{
+				JarFile: &jarFile,
+			},
+			JobManager: &v1beta1.JobManagerSpec{
+				AccessScope: v1beta1.AccessScopeVPC,
+				Ports: v1beta1.JobManagerPorts{
+					RPC:   &jmRPCPort,
+					Blob:  &jmBlobPort,
+					Query: &jmQueryPort,
+					UI:    &jmUIPort,
+				},
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				Replicas:       &tmReplicas,
+				DeploymentType: v1beta1.DeploymentTypeStatefulSet,
+				Ports: v1beta1.TaskManagerPorts{
+					Data:  &tmDataPort,
+					RPC:   &tmRPCPort,
+					Query: &tmQueryPort,
+				},
+			},
+			FlinkProperties: map[string]string{"properties.sasl.jaas.config": jaasConfig},
+			SecretsInjection: []v1beta1.SecretInjection{
+				{Placeholder: "KAFKA_PASSWORD", SecretKeyRef: secretKeyRef},
+			},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{NextRevision: "fjc-85dc8f749-1"},
+		},
+	}
+
+	var desired = DesiredState(cluster, Options{})
+
+	// The ConfigMap keeps the placeholders.
+	assert.Assert(t, strings.Contains(desired.ConfigMap.Data["flink-conf.yaml"], jaasConfig))
+
+	var renderedMount = corev1.VolumeMount{Name: "flink-rendered-config-volume", MountPath: "/opt/flink/conf"}
+	for _, podSpec := range []corev1.PodSpec{
+		desired.JmStatefulSet.Spec.Template.Spec,
+		desired.TmStatefulSet.Spec.Template.Spec,
+	} {
+		var render = podSpec.InitContainers[0]
+		assert.Equal(t, render.Name, "render-flink-config")
+		assert.DeepEqual(t, render.Env, []corev1.EnvVar{{
+			Name:      "KAFKA_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &secretKeyRef},
+		}})
+		// The placeholders are substituted in place, in the copied flink-conf.yaml.
+		assert.DeepEqual(t, render.Command, []string{"sh", "-c", "cp -L /opt/flink-operator/conf-template/* /opt/flink/conf/ && " +
+			"envsubst '${KAFKA_PASSWORD}' < /opt/flink/conf/flink-conf.yaml > /opt/flink/conf/flink-conf.yaml.tmp && " +
+			"mv /opt/flink/conf/flink-conf.yaml.tmp /opt/flink/conf/flink-conf.yaml"})
+		assert.DeepEqual(t, render.VolumeMounts, []corev1.VolumeMount{
+			{Name: "flink-config-volume", MountPath: "/opt/flink-operator/conf-template", ReadOnly: true},
+			renderedMount,
+		})
+		var mounted = false
+		for _, mount := range podSpec.Containers[0].VolumeMounts {
+			assert.Assert(t, mount.Name != "flink-config-volume", "the ConfigMap is not expected to be mounted")
+			mounted = mounted || mount == renderedMount
+		}
+		assert.Assert(t, mounted, "the rendered config is expected to be mounted")
+	}
+
+	// The job submitter mounts the ConfigMap as it is.
+	for _, container := range desired.Job.Spec.Template.Spec.InitContainers {
+		assert.Assert(t, container.Name != "render-flink-config")
+	}
+}
+
+func TestConfigOverride(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	cluster.Spec.FlinkProperties = nil
+	cluster.Spec.ConfigOverride = &v1beta1.ConfigOverrideSpec{ConfigMapName: "my-flink-conf"}
+
+	var desired = DesiredState(cluster, Options{})
+
+	// The config is rendered with the UI proxy image of the operator.
+	var options = Options{UIProxyImage: "registry.example.com/nginx-unprivileged:1.25-alpine"}
+	var podSpec = DesiredState(cluster, options).TmStatefulSet.Spec.Template.Spec
+	assert.Equal(t, podSpec.InitContainers[0].Name, "render-flink-config")
+	assert.Equal(t, podSpec.InitContainers[0].Image, "registry.example.com/nginx-unprivileged:1.25-alpine")
+
+	// Only the addressing properties are generated.
+	assert.Equal(t, desired.ConfigMap.Data["flink-conf.yaml"], `blob.server.port: 6124
+jobmanager.rpc.address: fjc-jobmanager
+jobmanager.rpc.port: 6123
+query.server.port: 6125
+rest.port: 8081
+taskmanager.rpc.port: 6122
+`)
+	assert.Equal(t, desired.ConfigMap.Data["submit-job.sh"], submitJobScript)
+
+	var overrideVolume = corev1.Volume{
+		Name: "flink-config-override-volume",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "my-flink-conf"},
+			},
+		},
+	}
+	for _, podSpec := range []corev1.PodSpec{
+		desired.JmStatefulSet.Spec.Template.Spec,
+		desired.TmStatefulSet.Spec.Template.Spec,
+	} {
+		var render = podSpec.InitContainers[0]
+		assert.Equal(t, render.Name, "render-flink-config")
+		assert.Equal(t, render.Image, "nginxinc/nginx-unprivileged:1.25-alpine")
+		// The addressing properties start on a new line and are appended only if the override
+		// has a flink-conf.yaml, otherwise the generated one is kept as it is.
+		assert.DeepEqual(t, render.Command, []string{"sh", "-c", "cp -L /opt/flink-operator/conf-template/* /opt/flink/conf/ && " +
+			"cp -L /opt/flink-operator/conf-override/* /opt/flink/conf/ && " +
+			"if [ -f /opt/flink-operator/conf-override/flink-conf.yaml ]; then " +
+			"echo >> /opt/flink/conf/flink-conf.yaml && " +
+			"cat /opt/flink-operator/conf-template/flink-conf.yaml >> /opt/flink/conf/flink-conf.yaml; fi"})
+		assert.DeepEqual(t, render.VolumeMounts[2], corev1.VolumeMount{
+			Name: "flink-config-override-volume", MountPath: "/opt/flink-operator/conf-override", ReadOnly: true,
+		})
+		var found = false
+		for _, volume := range podSpec.Volumes {
+			found = found || volume.Name == overrideVolume.Name
+			if volume.Name == overrideVolume.Name {
+				assert.DeepEqual(t, volume, overrideVolume)
+			}
+		}
+		assert.Assert(t, found, "the ConfigMap of configOverride is expected to be mounted")
+	}
+
+	// The placeholders are substituted after the override is copied, so that an override
+	// without flink-conf.yaml, e.g. of the log config only, leaves the generated one to substitute.
+	cluster.Spec.SecretsInjection = []v1beta1.SecretInjection{{
+		Placeholder: "KAFKA_PASSWORD",
+		SecretKeyRef: corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "kafka"},
+			Key:                  "password",
+		},
+	}}
+	desired = DesiredState(cluster, Options{})
+	assert.DeepEqual(t, desired.TmStatefulSet.Spec.Template.Spec.InitContainers[0].Command, []string{"sh", "-c",
+		"cp -L /opt/flink-operator/conf-template/* /opt/flink/conf/ && " +
+			"cp -L /opt/flink-operator/conf-override/* /opt/flink/conf/ && " +
+			"envsubst '${KAFKA_PASSWORD}' < /opt/flink/conf/flink-conf.yaml > /opt/flink/conf/flink-conf.yaml.tmp && " +
+			"mv /opt/flink/conf/flink-conf.yaml.tmp /opt/flink/conf/flink-conf.yaml && " +
+			"if [ -f /opt/flink-operator/conf-override/flink-conf.yaml ]; then " +
+			"echo >> /opt/flink/conf/flink-conf.yaml && " +
+			"cat /opt/flink-operator/conf-template/flink-conf.yaml >> /opt/flink/conf/flink-conf.yaml; fi"})
+}
+
+func TestFlinkPropertiesFrom(t *testing.T) {
+	var jmRPCPort int32 = 6123
+	var jmBlobPort int32 = 6124
+	var jmQueryPort int32 = 6125
+	var jmUIPort int32 = 8081
+	var tmDataPort int32 = 6121
+	var tmRPCPort int32 = 6122
+	var tmQueryPort int32 = 6125
+	var tmReplicas int32 = v1beta1.DefaultTaskManagerReplicas
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mycluster",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			JobManager: &v1beta1.JobManagerSpec{
+				AccessScope: v1beta1.AccessScopeVPC,
+				Ports: v1beta1.JobManagerPorts{
+					RPC:   &jmRPCPort,
+					Blob:  &jmBlobPort,
+					Query: &jmQueryPort,
+					UI:    &jmUIPort,
+				},
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				Replicas:       &tmReplicas,
+				DeploymentType: v1beta1.DeploymentTypeStatefulSet,
+				Ports: v1beta1.TaskManagerPorts{
+					Data:  &tmDataPort,
+					RPC:   &tmRPCPort,
+					Query: &tmQueryPort,
+				},
+			},
+			FlinkPropertiesFrom: []v1beta1.FlinkPropertiesSource{{
+				External: &v1beta1.ExternalSecretSource{Provider: v1beta1.ExternalSecretProviderVault, Name: "secret/flink"},
+			}},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{NextRevision: "mycluster-85dc8f749-1"},
+		},
+	}
+	var flinkPropertiesFrom = map[string]string{"s3.secret-key": "secret", "s3.access-key": "key"}
+
+	var desired = DesiredState(cluster, Options{FlinkPropertiesFrom: flinkPropertiesFrom})
+
+	// The resolved properties are stored in a Secret of the cluster, not in the ConfigMap.
+	var secret = desired.FlinkPropertiesSecret
+	assert.Equal(t, secret.Name, "mycluster-flink-properties")
+	assert.Equal(t, string(secret.Data["flink-conf.yaml"]), "s3.access-key: key\ns3.secret-key: secret\n")
+	assert.Assert(t, !strings.Contains(desired.ConfigMap.Data["flink-conf.yaml"], "s3.secret-key"))
+
+	for _, podSpec := range []corev1.PodSpec{
+		desired.JmStatefulSet.Spec.Template.Spec,
+		desired.TmStatefulSet.Spec.Template.Spec,
+	} {
+		var render = podSpec.InitContainers[0]
+		assert.Equal(t, render.Name, "render-flink-config")
+		assert.DeepEqual(t, render.Command, []string{"sh", "-c",
+			"cp -L /opt/flink-operator/conf-template/* /opt/flink/conf/ && " +
+				"echo >> /opt/flink/conf/flink-conf.yaml && " +
+				"cat /opt/flink-operator/properties-from/flink-conf.yaml >> /opt/flink/conf/flink-conf.yaml"})
+		var found = false
+		for _, volume := range podSpec.Volumes {
+			if volume.Name == "flink-properties-from-volume" {
+				assert.Equal(t, volume.Secret.SecretName, "mycluster-flink-properties")
+				found = true
+			}
+		}
+		assert.Assert(t, found, "flink-properties-from-volume is expected")
+	}
+
+	// The Secret is removed with the field.
+	cluster.Spec.FlinkPropertiesFrom = nil
+	desired = DesiredState(cluster, Options{})
+	assert.Assert(t, desired.FlinkPropertiesSecret == nil)
+	for _, container := range desired.JmStatefulSet.Spec.Template.Spec.InitContainers {
+		assert.Assert(t, container.Name != "render-flink-config")
+	}
+}
+
+func TestStateEncryption(t *testing.T) {
+	var secretKeyRef = func(name, key string) corev1.SecretKeySelector {
+		return corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key}
+	}
+	var accessKeyID, secretAccessKey = secretKeyRef("aws", "access-key-id"), secretKeyRef("aws", "secret-access-key")
+	var cluster = rendertest.NewFlinkCluster()
+	cluster.Spec.StateEncryption = &v1beta1.StateEncryptionSpec{
+		AWSKMS: &v1beta1.AWSKMSEncryptionSpec{
+			KeyID:                    "arn:aws:kms:us-east-1:111122223333:key/flink",
+			AccessKeyIDSecretRef:     &accessKeyID,
+			SecretAccessKeySecretRef: &secretAccessKey,
+		},
+	}
+
+	var desired = DesiredState(cluster, Options{})
+	var flinkConf = desired.ConfigMap.Data["flink-conf.yaml"]
+	assert.Assert(t, strings.Contains(flinkConf, "fs.s3a.server-side-encryption-algorithm: SSE-KMS\n"+
+		"fs.s3a.server-side-encryption.key: arn:aws:kms:us-east-1:111122223333:key/flink\n"))
+	assert.Assert(t, strings.Contains(flinkConf, "s3.sse.kms-key-id: arn:aws:kms:us-east-1:111122223333:key/flink\n"))
+	var credentials = []corev1.EnvVar{
+		{Name: "AWS_ACCESS_KEY_ID", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &accessKeyID}},
+		{Name: "AWS_SECRET_ACCESS_KEY", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &secretAccessKey}},
+	}
+	for _, podSpec := range []corev1.PodSpec{
+		desired.JmStatefulSet.Spec.Template.Spec,
+		desired.TmStatefulSet.Spec.Template.Spec,
+	} {
+		var env = podSpec.Containers[0].Env
+		assert.DeepEqual(t, env[len(env)-2:], credentials)
+		for _, container := range append(podSpec.InitContainers, podSpec.Containers[1:]...) {
+			for _, envVar := range container.Env {
+				assert.Assert(t, envVar.Name != "AWS_ACCESS_KEY_ID", container.Name)
+			}
+		}
+	}
+
+	// The customer-supplied key of Cloud Storage is substituted into flink-conf.yaml.
+	var key, keyHash = secretKeyRef("gcs-csek", "key"), secretKeyRef("gcs-csek", "key-hash")
+	cluster.Spec.StateEncryption = &v1beta1.StateEncryptionSpec{
+		GCSCustomerKey: &v1beta1.GCSCustomerKeySpec{KeySecretRef: key, KeyHashSecretRef: keyHash},
+	}
+	desired = DesiredState(cluster, Options{})
+	assert.Assert(t, strings.Contains(desired.ConfigMap.Data["flink-conf.yaml"], "fs.gs.encryption.algorithm: AES256\n"+
+		"fs.gs.encryption.key: ${FLINK_OPERATOR_GCS_ENCRYPTION_KEY}\n"+
+		"fs.gs.encryption.key.hash: ${FLINK_OPERATOR_GCS_ENCRYPTION_KEY_HASH}\n"))
+	for _, podSpec := range []corev1.PodSpec{
+		desired.JmStatefulSet.Spec.Template.Spec,
+		desired.TmStatefulSet.Spec.Template.Spec,
+	} {
+		var render = podSpec.InitContainers[0]
+		assert.Equal(t, render.Name, "render-flink-config")
+		assert.DeepEqual(t, render.Env[:2], []corev1.EnvVar{
+			{Name: "FLINK_OPERATOR_GCS_ENCRYPTION_KEY", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &key}},
+			{Name: "FLINK_OPERATOR_GCS_ENCRYPTION_KEY_HASH", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &keyHash}},
+		})
+		for _, container := range podSpec.Containers {
+			for _, envVar := range container.Env {
+				assert.Assert(t, envVar.Name != "AWS_ACCESS_KEY_ID")
+			}
+		}
+	}
+	assert.Equal(t, len(cluster.Spec.SecretsInjection), 0)
+}
+
+func TestImagePullSecrets(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{}
+
+	var defaults = []corev1.LocalObjectReference{{Name: "registry"}, {Name: "mirror"}}
+	assert.DeepEqual(t, ImagePullSecrets(cluster, defaults), defaults)
+
+	// The secrets of the spec replace the defaults of the operator.
+	cluster.Spec.Image.PullSecrets = []corev1.LocalObjectReference{{Name: "team-registry"}}
+	assert.DeepEqual(t, ImagePullSecrets(cluster, defaults), []corev1.LocalObjectReference{{Name: "team-registry"}})
+}
+
+func TestDisabledComponents(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var disabled = false
+	cluster.Spec.Components = &v1beta1.ComponentsSpec{
+		CreateIngress:   &disabled,
+		CreateConfigMap: &disabled,
+		CreateTmService: &disabled,
+	}
+
+	var desired = DesiredState(cluster, Options{})
+	assert.Assert(t, desired.JmIngress == nil)
+	assert.Assert(t, desired.ConfigMap == nil)
+	assert.Assert(t, desired.TmService == nil)
+	assert.Assert(t, desired.JmService != nil)
+	assert.Assert(t, desired.JmStatefulSet != nil)
+}
+
+func TestArchitectureAffinity(t *testing.T) {
+	var arm64 = v1beta1.ArchitectureARM64
+	var requirement = corev1.NodeSelectorRequirement{
+		Key:      "kubernetes.io/arch",
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{"arm64"},
+	}
+
+	// Any architecture.
+	var anyArch = v1beta1.ArchitectureAny
+	var podSpec = &corev1.PodSpec{}
+	assert.Equal(t, setArchitectureAffinity(&anyArch, podSpec), false)
+	assert.Assert(t, podSpec.Affinity == nil)
+
+	// Without affinity.
+	assert.Equal(t, setArchitectureAffinity(&arm64, podSpec), true)
+	assert.DeepEqual(t, podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms,
+		[]corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{requirement}}})
+
+	// The requirement is added to every term, without modifying the spec.
+	var zone = corev1.NodeSelectorRequirement{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}
+	var pool = corev1.NodeSelectorRequirement{Key: "pool", Operator: corev1.NodeSelectorOpExists}
+	var specAffinity = &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{zone}},
+					{MatchExpressions: []corev1.NodeSelectorRequirement{pool}},
+				},
+			},
+		},
+	}
+	podSpec = &corev1.PodSpec{Affinity: specAffinity}
+	setArchitectureAffinity(&arm64, podSpec)
+	assert.DeepEqual(t, podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms,
+		[]corev1.NodeSelectorTerm{
+			{MatchExpressions: []corev1.NodeSelectorRequirement{zone, requirement}},
+			{MatchExpressions: []corev1.NodeSelectorRequirement{pool, requirement}},
+		})
+	assert.Equal(t, len(specAffinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions), 1)
+}
+
+func TestJobManagerIngressAuth(t *testing.T) {
+	var jmRPCPort int32 = 6123
+	var jmBlobPort int32 = 6124
+	var jmQueryPort int32 = 6125
+	var jmUIPort int32 = 8081
+	var hostFormat = "{{$clusterName}}.example.com"
+	var signInURL = "https://auth.example.com/oauth2/start"
+	var secretRef = func(key string) corev1.SecretKeySelector {
+		return corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "oauth2"}, Key: key}
+	}
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "flinkjobcluster-sample",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			JobManager: &v1beta1.JobManagerSpec{
+				AccessScope: v1beta1.AccessScopeCluster,
+				Ports: v1beta1.JobManagerPorts{
+					RPC:   &jmRPCPort,
+					Blob:  &jmBlobPort,
+					Query: &jmQueryPort,
+					UI:    &jmUIPort,
+				},
+				Ingress: &v1beta1.JobManagerIngressSpec{
+					HostFormat: &hostFormat,
+					Auth: &v1beta1.JobManagerIngressAuthSpec{
+						OAuth2Proxy: &v1beta1.OAuth2ProxySpec{
+							Provider:              "google",
+							ClientIDSecretRef:     secretRef("client-id"),
+							ClientSecretSecretRef: secretRef("client-secret"),
+							CookieSecretSecretRef: secretRef("cookie-secret"),
+							ExtraArgs:             []string{"--email-domain=example.com"},
+						},
+					},
+				},
+			},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{NextRevision: "flinkjobcluster-sample-85dc8f749-1"},
+		},
+	}
+
+	var jmPodSpec = newJobManagerPodSpec(newJobManagerContainer(cluster), cluster, Options{})
+	assert.Equal(t, len(jmPodSpec.Containers), 2)
+	var proxy = jmPodSpec.Containers[1]
+	assert.Equal(t, proxy.Name, "oauth2-proxy")
+	assert.Equal(t, proxy.Image, "quay.io/oauth2-proxy/oauth2-proxy:v7.4.0")
+	assert.DeepEqual(t, proxy.Args, []string{
+		"--provider=google",
+		"--http-address=0.0.0.0:4180",
+		"--upstream=http://127.0.0.1:8081",
+		"--reverse-proxy=true",
+		"--email-domain=example.com",
+	})
+	assert.DeepEqual(t, proxy.Ports, []corev1.ContainerPort{{Name: "oauth2-proxy", ContainerPort: 4180}})
+	assert.DeepEqual(t, proxy.Env[2], corev1.EnvVar{
+		Name:      "OAUTH2_PROXY_COOKIE_SECRET",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "oauth2"}, Key: "cookie-secret"}},
+	})
+
+	var service = newJobManagerService(cluster)
+	assert.DeepEqual(t, service.Spec.Ports[len(service.Spec.Ports)-1], corev1.ServicePort{
+		Name:       "oauth2-proxy",
+		Port:       4180,
+		TargetPort: intstr.FromString("oauth2-proxy"),
+	})
+
+	var ingress = newJobManagerIngress(cluster)
+	assert.Equal(t, ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port.Name, "oauth2-proxy")
+
+	cluster.Spec.JobManager.Ingress.Auth = &v1beta1.JobManagerIngressAuthSpec{
+		ExternalAuth: &v1beta1.IngressExternalAuthSpec{
+			URL:       "https://auth.example.com/oauth2/auth",
+			SignInURL: &signInURL,
+		},
+	}
+	jmPodSpec = newJobManagerPodSpec(newJobManagerContainer(cluster), cluster, Options{})
+	assert.Equal(t, len(jmPodSpec.Containers), 1)
+	ingress = newJobManagerIngress(cluster)
+	assert.Equal(t, ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port.Name, "ui")
+	assert.DeepEqual(t, ingress.Annotations, map[string]string{
+		"nginx.ingress.kubernetes.io/auth-url":    "https://auth.example.com/oauth2/auth",
+		"nginx.ingress.kubernetes.io/auth-signin": signInURL,
+	})
+}
+
+func TestReadOnlyUI(t *testing.T) {
+	var jmRPCPort int32 = 6123
+	var jmBlobPort int32 = 6124
+	var jmQueryPort int32 = 6125
+	var jmUIPort int32 = 8081
+	var tmRPCPort int32 = 6122
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "flinkjobcluster-sample",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			JobManager: &v1beta1.JobManagerSpec{
+				AccessScope: v1beta1.AccessScopeExternal,
+				Ports: v1beta1.JobManagerPorts{
+					RPC:   &jmRPCPort,
+					Blob:  &jmBlobPort,
+					Query: &jmQueryPort,
+					UI:    &jmUIPort,
+				},
+				Ingress: &v1beta1.JobManagerIngressSpec{},
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				Ports: v1beta1.TaskManagerPorts{RPC: &tmRPCPort},
+			},
+			FlinkProperties: map[string]string{"web.submit.enable": "true"},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{NextRevision: "flinkjobcluster-sample-85dc8f749-1"},
+		},
+	}
+
+	var configMap = newConfigMap(cluster)
+	assert.Assert(t, strings.Contains(configMap.Data["flink-conf.yaml"], "web.submit.enable: false\n"))
+	assert.Assert(t, strings.Contains(configMap.Data["flink-conf.yaml"], "web.cancel.enable: false\n"))
+	assert.Assert(t, strings.Contains(configMap.Data["ui-proxy.conf"], "listen 8082;"))
+	assert.Assert(t, strings.Contains(configMap.Data["ui-proxy.conf"], "proxy_pass http://127.0.0.1:8081;"))
+
+	var jmPodSpec = newJobManagerPodSpec(newJobManagerContainer(cluster), cluster, Options{})
+	assert.Equal(t, len(jmPodSpec.Containers), 2)
+	assert.DeepEqual(t, jmPodSpec.Containers[1], corev1.Container{
+		Name:  "ui-proxy",
+		Image: "nginxinc/nginx-unprivileged:1.25-alpine",
+		Ports: []corev1.ContainerPort{{Name: "ui-proxy", ContainerPort: 8082}},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      "flink-config-volume",
+			MountPath: "/etc/nginx/conf.d/default.conf",
+			SubPath:   "ui-proxy.conf",
+			ReadOnly:  true,
+		}},
+	})
+
+	// Only the proxy port is exposed by the external load balancer.
+	var service = newJobManagerService(cluster)
+	assert.Equal(t, service.Spec.Type, corev1.ServiceTypeClusterIP)
+	assert.Equal(t, service.Spec.Ports[len(service.Spec.Ports)-1].Name, "ui-proxy")
+	var uiService = newJobManagerUIService(cluster)
+	assert.Equal(t, uiService.Name, "flinkjobcluster-sample-jobmanager-ui")
+	assert.Equal(t, uiService.Spec.Type, corev1.ServiceTypeLoadBalancer)
+	assert.DeepEqual(t, uiService.Spec.Ports, []corev1.ServicePort{{
+		Name:       "ui-proxy",
+		Port:       8082,
+		TargetPort: intstr.FromString("ui-proxy"),
+	}})
+	var ingress = newJobManagerIngress(cluster)
+	assert.Equal(t, ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port.Name, "ui-proxy")
+
+	var writable = false
+	cluster.Spec.JobManager.ReadOnlyUI = &writable
+	configMap = newConfigMap(cluster)
+	assert.Assert(t, strings.Contains(configMap.Data["flink-conf.yaml"], "web.submit.enable: true\n"))
+	_, ok := configMap.Data["ui-proxy.conf"]
+	assert.Assert(t, !ok)
+	jmPodSpec = newJobManagerPodSpec(newJobManagerContainer(cluster), cluster, Options{})
+	assert.Equal(t, len(jmPodSpec.Containers), 1)
+	service = newJobManagerService(cluster)
+	assert.Equal(t, service.Spec.Type, corev1.ServiceTypeLoadBalancer)
+	assert.Assert(t, newJobManagerUIService(cluster) == nil)
+}
+
+func TestExternalTaskManager(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var external = true
+	cluster.Spec.TaskManager.External = &external
+
+	var desired = DesiredState(cluster, Options{})
+
+	assert.Assert(t, desired.TmStatefulSet == nil)
+	assert.Assert(t, desired.TmDeployment == nil)
+	assert.Assert(t, desired.JmStatefulSet != nil)
+	assert.Assert(t, desired.JmService != nil)
+	assert.Assert(t, desired.TmService != nil)
+}
+
+func TestTimezone(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var timezone = "Europe/Stockholm"
+	cluster.Spec.Timezone = &timezone
+	cluster.Spec.FlinkProperties = map[string]string{"env.java.opts": "-XX:+UseG1GC"}
+
+	var desired = DesiredState(cluster, Options{})
+
+	var tzEnv = corev1.EnvVar{Name: "TZ", Value: timezone}
+	var env = []corev1.EnvVar{{Name: "FOO", Value: "abc"}, tzEnv}
+	// Followed by the environment variables of the Hadoop and GCP configs.
+	assert.DeepEqual(t, desired.JmStatefulSet.Spec.Template.Spec.Containers[0].Env[:2], env)
+	assert.DeepEqual(t, desired.TmStatefulSet.Spec.Template.Spec.Containers[0].Env[:2], env)
+	assert.DeepEqual(t, desired.Job.Spec.Template.Spec.Containers[0].Env[4:6], env)
+	assert.Assert(t, strings.Contains(desired.ConfigMap.Data["flink-conf.yaml"],
+		"env.java.opts: -XX:+UseG1GC -Duser.timezone=Europe/Stockholm\n"))
+	assert.Equal(t, len(cluster.Spec.EnvVars), 1)
+}
+
+func TestNetworking(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var timezone = "Europe/Stockholm"
+	var httpsProxy = "http://proxy.example.com:3128"
+	var noProxy = "10.0.0.1, .svc"
+	cluster.Spec.Timezone = &timezone
+	cluster.Spec.Networking = &v1beta1.NetworkingSpec{
+		Proxy:    &v1beta1.ProxySpec{HTTPSProxy: &httpsProxy, NoProxy: &noProxy},
+		CABundle: &v1beta1.CABundleSpec{ConfigMapName: "corporate-ca"},
+	}
+
+	var desired = DesiredState(cluster, Options{})
+
+	assert.Assert(t, strings.Contains(desired.ConfigMap.Data["flink-conf.yaml"], "env.java.opts: -Duser.timezone=Europe/Stockholm"+
+		" -Dhttps.proxyHost=proxy.example.com -Dhttps.proxyPort=3128"+
+		" -Dhttp.nonProxyHosts=10.0.0.1|*.svc|localhost|127.0.0.1|fjc-jobmanager"+
+		" -Djavax.net.ssl.trustStore=/opt/flink-operator/truststore/cacerts -Djavax.net.ssl.trustStorePassword=changeit\n"))
+
+	var truststoreMount = corev1.VolumeMount{Name: "truststore-volume", MountPath: "/opt/flink-operator/truststore", ReadOnly: true}
+	for _, podSpec := range []corev1.PodSpec{
+		desired.JmStatefulSet.Spec.Template.Spec,
+		desired.TmStatefulSet.Spec.Template.Spec,
+		desired.Job.Spec.Template.Spec,
+	} {
+		assert.Assert(t, hasVolume(podSpec.Volumes, "ca-bundle-volume"))
+		assert.Assert(t, hasVolume(podSpec.Volumes, "truststore-volume"))
+		var importContainer = podSpec.InitContainers[0]
+		assert.Equal(t, importContainer.Name, "import-ca-bundle")
+		assert.Equal(t, importContainer.Env[0].Value, "/opt/flink-operator/ca-bundle/ca.crt")
+		for _, container := range append(podSpec.InitContainers[1:], podSpec.Containers[0]) {
+			assert.Assert(t, hasVolumeMount(container.VolumeMounts, truststoreMount), container.Name)
+			var env = map[string]string{}
+			for _, envVar := range container.Env {
+				env[envVar.Name] = envVar.Value
+			}
+			assert.Equal(t, env["HTTPS_PROXY"], httpsProxy, container.Name)
+			assert.Equal(t, env["https_proxy"], httpsProxy, container.Name)
+			assert.Equal(t, env["NO_PROXY"], "10.0.0.1,.svc,localhost,127.0.0.1,fjc-jobmanager", container.Name)
+			assert.Equal(t, env["SSL_CERT_FILE"], "/opt/flink-operator/truststore/ca-certificates.crt", container.Name)
+			_, ok := env["HTTP_PROXY"]
+			assert.Assert(t, !ok, container.Name)
+		}
+	}
+}
+
+func TestClusterDomain(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var clusterDomain = "k8s.example.com"
+	var noProxy = ".svc"
+	var httpsProxy = "http://proxy.example.com:3128"
+	cluster.Spec.Networking = &v1beta1.NetworkingSpec{
+		Proxy:         &v1beta1.ProxySpec{HTTPSProxy: &httpsProxy, NoProxy: &noProxy},
+		IPFamilies:    []corev1.IPFamily{corev1.IPv6Protocol},
+		ClusterDomain: &clusterDomain,
+	}
+
+	var desired = DesiredState(cluster, Options{})
+
+	var flinkConf = desired.ConfigMap.Data["flink-conf.yaml"]
+	assert.Assert(t, strings.Contains(flinkConf, "jobmanager.rpc.address: fjc-jobmanager.default.svc.k8s.example.com\n"))
+	assert.Assert(t, strings.Contains(flinkConf,
+		" -Dhttp.nonProxyHosts=*.svc|localhost|127.0.0.1|fjc-jobmanager|fjc-jobmanager.default.svc.k8s.example.com"+
+			" -Djava.net.preferIPv6Addresses=true\n"), flinkConf)
+	var args = desired.Job.Spec.Template.Spec.Containers[0].Args
+	assert.DeepEqual(t, args[2:4], []string{"--jobmanager", "fjc-jobmanager.default.svc.k8s.example.com:8081"})
+}
+
+func TestTaskManagerExtraArtifacts(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var fileName = "flink-udfs.jar"
+	cluster.Spec.TaskManager.ExtraArtifacts = []v1beta1.TaskManagerArtifact{
+		{
+			URI:       "gs://my-bucket/udfs/udfs-1.2.jar?generation=1",
+			Directory: "lib",
+			FileName:  &fileName,
+			SHA256:    "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+		},
+		{URI: "https://repo.example.com/flink-s3-fs-presto-1.15.1.jar", Directory: "plugins/s3-fs-presto"},
+	}
+
+	var desired = DesiredState(cluster, Options{})
+
+	var podSpec = desired.TmStatefulSet.Spec.Template.Spec
+	assert.Assert(t, hasVolume(podSpec.Volumes, "extra-artifacts-volume"))
+	var initContainer = podSpec.InitContainers[0]
+	assert.Equal(t, initContainer.Name, "extra-artifacts")
+	assert.DeepEqual(t, initContainer.Args, []string{
+		"https://storage.googleapis.com/my-bucket/udfs/udfs-1.2.jar",
+		"/opt/flink-operator/extra-artifacts/lib/flink-udfs.jar",
+		"5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+		"https://repo.example.com/flink-s3-fs-presto-1.15.1.jar",
+		"/opt/flink-operator/extra-artifacts/plugins/s3-fs-presto/flink-s3-fs-presto-1.15.1.jar",
+		"",
+	})
+	var container = podSpec.Containers[0]
+	assert.Assert(t, hasVolumeMount(container.VolumeMounts, corev1.VolumeMount{
+		Name:      "extra-artifacts-volume",
+		MountPath: "/opt/flink/lib/flink-udfs.jar",
+		SubPath:   "lib/flink-udfs.jar",
+		ReadOnly:  true,
+	}))
+	assert.Assert(t, hasVolumeMount(container.VolumeMounts, corev1.VolumeMount{
+		Name:      "extra-artifacts-volume",
+		MountPath: "/opt/flink/plugins/s3-fs-presto/flink-s3-fs-presto-1.15.1.jar",
+		SubPath:   "plugins/s3-fs-presto/flink-s3-fs-presto-1.15.1.jar",
+		ReadOnly:  true,
+	}))
+
+	// The JobManager does not download them.
+	assert.Assert(t, !hasVolume(desired.JmStatefulSet.Spec.Template.Spec.Volumes, "extra-artifacts-volume"))
+
+	// The operator image downloads them with the credentials of the cluster.
+	cluster.Spec.GCPConfig = &v1beta1.GCPConfig{
+		ServiceAccount: &v1beta1.GCPServiceAccount{SecretName: "gcp-sa", KeyFile: "key.json", MountPath: "/etc/gcp"},
+	}
+	desired = DesiredState(cluster, Options{OperatorImage: "ghcr.io/spotify/flink-operator:latest"})
+	podSpec = desired.TmStatefulSet.Spec.Template.Spec
+	initContainer = podSpec.InitContainers[0]
+	assert.Equal(t, initContainer.Name, "extra-artifacts")
+	assert.Equal(t, initContainer.Image, "ghcr.io/spotify/flink-operator:latest")
+	assert.DeepEqual(t, initContainer.Args, []string{
+		"download-artifacts",
+		"--artifacts",
+		`[{"uri":"gs://my-bucket/udfs/udfs-1.2.jar?generation=1",` +
+			`"path":"/opt/flink-operator/extra-artifacts/lib/flink-udfs.jar",` +
+			`"sha256":"5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"},` +
+			`{"uri":"https://repo.example.com/flink-s3-fs-presto-1.15.1.jar",` +
+			`"path":"/opt/flink-operator/extra-artifacts/plugins/s3-fs-presto/flink-s3-fs-presto-1.15.1.jar"}]`,
+	})
+	assert.Assert(t, hasVolumeMount(initContainer.VolumeMounts, corev1.VolumeMount{
+		Name:      "extra-artifacts-volume",
+		MountPath: "/opt/flink-operator/extra-artifacts",
+	}))
+	assert.Assert(t, hasVolumeMount(initContainer.VolumeMounts, corev1.VolumeMount{
+		Name:      "gcp-service-account-volume",
+		MountPath: "/etc/gcp/",
+		ReadOnly:  true,
+	}))
+	var credentials string
+	for _, envVar := range initContainer.Env {
+		if envVar.Name == "GOOGLE_APPLICATION_CREDENTIALS" {
+			credentials = envVar.Value
+		}
+	}
+	assert.Equal(t, credentials, "/etc/gcp/key.json")
+}
+
+func TestFlinkPlugins(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	cluster.Spec.FlinkPlugins = []v1beta1.FlinkPlugin{v1beta1.FlinkPluginS3FsHadoop, v1beta1.FlinkPluginGSFsHadoop}
+
+	var desired = DesiredState(cluster, Options{})
+
+	for _, podSpec := range []corev1.PodSpec{desired.JmStatefulSet.Spec.Template.Spec, desired.TmStatefulSet.Spec.Template.Spec} {
+		assert.Assert(t, hasVolume(podSpec.Volumes, "flink-plugins-volume"))
+		var initContainer = podSpec.InitContainers[0]
+		assert.Equal(t, initContainer.Name, "flink-plugins")
+		assert.Equal(t, initContainer.Image, cluster.Spec.Image.Name)
+		assert.DeepEqual(t, initContainer.Args, []string{"/opt/flink-operator/plugins", "s3-fs-hadoop", "gs-fs-hadoop"})
+		var container = podSpec.Containers[0]
+		assert.Assert(t, hasVolumeMount(container.VolumeMounts, corev1.VolumeMount{
+			Name:      "flink-plugins-volume",
+			MountPath: "/opt/flink/plugins/s3-fs-hadoop",
+			SubPath:   "s3-fs-hadoop",
+			ReadOnly:  true,
+		}))
+		assert.Assert(t, hasVolumeMount(container.VolumeMounts, corev1.VolumeMount{
+			Name:      "flink-plugins-volume",
+			MountPath: "/opt/flink/plugins/gs-fs-hadoop",
+			SubPath:   "gs-fs-hadoop",
+			ReadOnly:  true,
+		}))
+	}
+}
+
+func TestJobManagerStorage(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var jobManagerSpec = cluster.Spec.JobManager
+	jobManagerSpec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+		{ObjectMeta: metav1.ObjectMeta{Name: "jm-storage"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "jm-data"}},
+	}
+	jobManagerSpec.Storage = &v1beta1.JobManagerStorageSpec{VolumeClaimTemplate: "jm-storage"}
+
+	var desired = DesiredState(cluster, Options{})
+	var container = desired.JmStatefulSet.Spec.Template.Spec.Containers[0]
+	assert.Assert(t, hasVolumeMount(container.VolumeMounts, corev1.VolumeMount{
+		Name:      "jm-storage",
+		MountPath: "/opt/flink-operator/jobmanager-storage",
+	}))
+	assert.DeepEqual(t, container.Args, []string{
+		"jobmanager",
+		"-Dweb.upload.dir=/opt/flink-operator/jobmanager-storage/upload",
+	})
+	for _, pvc := range desired.JmStatefulSet.Spec.VolumeClaimTemplates {
+		assert.Equal(t, len(pvc.OwnerReferences), 1)
+	}
+	// The TaskManagers are not changed.
+	assert.DeepEqual(t, desired.TmStatefulSet.Spec.Template.Spec.Containers[0].Args, []string{"taskmanager"})
+
+	// Only the retained storage claim is not owned by the cluster.
+	var retain = v1beta1.StorageRetentionPolicyRetain
+	jobManagerSpec.Storage.RetentionPolicy = &retain
+	desired = DesiredState(cluster, Options{})
+	var pvcs = desired.JmStatefulSet.Spec.VolumeClaimTemplates
+	assert.Equal(t, len(pvcs[0].OwnerReferences), 0)
+	assert.Equal(t, len(pvcs[1].OwnerReferences), 1)
+}
+
+func TestJobEnvVars(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var savepoint = "gs://my-bucket/savepoints/savepoint-123"
+	cluster.Spec.Job.FromSavepoint = &savepoint
+	cluster.Spec.EnvVars = []corev1.EnvVar{{Name: "FLINK_CLUSTER_NAME", Value: "my-cluster"}}
+
+	var jobEnv = []corev1.EnvVar{
+		{Name: "FLINK_CLUSTER_NAME", Value: "fjc"},
+		{Name: "FLINK_CLUSTER_NAMESPACE", Value: "default"},
+		{Name: "FLINK_CLUSTER_REVISION", Value: "fjc-85dc8f749"},
+		{Name: "FLINK_SAVEPOINT_PATH", Value: savepoint},
+	}
+	var desired = DesiredState(cluster, Options{})
+	var env = desired.Job.Spec.Template.Spec.Containers[0].Env
+	assert.DeepEqual(t, env[1:5], jobEnv)
+	// Followed by spec.envVars, which override them.
+	assert.DeepEqual(t, env[5], cluster.Spec.EnvVars[0])
+	for _, envVar := range desired.JmStatefulSet.Spec.Template.Spec.Containers[0].Env {
+		assert.Assert(t, envVar.Name != "FLINK_CLUSTER_NAMESPACE")
+	}
+
+	// The JobManager runs the job in application mode.
+	var mode = v1beta1.JobModeApplication
+	cluster.Spec.Job.Mode = &mode
+	desired = DesiredState(cluster, Options{})
+	env = desired.Job.Spec.Template.Spec.Containers[0].Env
+	assert.Equal(t, desired.Job.Spec.Template.Spec.Containers[0].Name, "jobmanager")
+	assert.DeepEqual(t, env[:5], append(jobEnv, cluster.Spec.EnvVars[0]))
+	assert.Equal(t, len(cluster.Spec.EnvVars), 1)
+}
+
+func TestJobArgsFrom(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var verbose = "--verbose"
+	var template = "$(date)"
+	cluster.Spec.Job.Args = []string{"--input", "./README.txt"}
+	cluster.Spec.Job.ArgsFrom = []v1beta1.JobArgSource{
+		{Value: &verbose},
+		{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "job-args"}, Key: "topic"}},
+		{Value: &template},
+		{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "kafka"}, Key: "token"}},
+	}
+	var expectedArgs = []string{"--input", "./README.txt", "--verbose", "$(FLINK_JOB_ARG_1)", "$$(date)", "$(FLINK_JOB_ARG_3)"}
+	var expectedEnv = []corev1.EnvVar{
+		{Name: "FLINK_JOB_ARG_1", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: cluster.Spec.Job.ArgsFrom[1].ConfigMapKeyRef}},
+		{Name: "FLINK_JOB_ARG_3", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: cluster.Spec.Job.ArgsFrom[3].SecretKeyRef}},
+		{Name: "FLINK_JOB_SECRET_ARGS", Value: "FLINK_JOB_ARG_3"},
+	}
+
+	var desired = DesiredState(cluster, Options{})
+	var container = desired.Job.Spec.Template.Spec.Containers[0]
+	assert.DeepEqual(t, container.Args[len(container.Args)-len(expectedArgs):], expectedArgs)
+	assert.DeepEqual(t, container.Env[:3], expectedEnv)
+	// The values are not exposed to the init containers.
+	for _, initContainer := range desired.Job.Spec.Template.Spec.InitContainers {
+		for _, envVar := range initContainer.Env {
+			assert.Assert(t, !strings.HasPrefix(envVar.Name, "FLINK_JOB_ARG_"), initContainer.Name)
+		}
+	}
+
+	// The JobManager runs the job in application mode.
+	var mode = v1beta1.JobModeApplication
+	cluster.Spec.Job.Mode = &mode
+	desired = DesiredState(cluster, Options{})
+	container = desired.Job.Spec.Template.Spec.Containers[0]
+	assert.DeepEqual(t, container.Args[len(container.Args)-len(expectedArgs):], expectedArgs)
+	assert.DeepEqual(t, container.Env[:3], expectedEnv)
+}
+
+func TestJVMOptions(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	cluster.Spec.JobManager.JVMOptions = []string{"-XX:+UseG1GC", "-XX:MaxGCPauseMillis=200"}
+	cluster.Spec.TaskManager.JVMOptions = []string{"-XX:+UseG1GC"}
+
+	var desired = DesiredState(cluster, Options{})
+
+	var flinkConf = desired.ConfigMap.Data["flink-conf.yaml"]
+	assert.Assert(t, strings.Contains(flinkConf, "env.java.opts.jobmanager: -XX:+UseG1GC -XX:MaxGCPauseMillis=200\n"))
+	assert.Assert(t, strings.Contains(flinkConf, "env.java.opts.taskmanager: -XX:+UseG1GC\n"))
+}
+
+func TestTerminationGracePeriodSeconds(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var desired = DesiredState(cluster, Options{})
+	assert.Equal(t, *desired.JmStatefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds, int64(60))
+	assert.Equal(t, *desired.TmStatefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds, int64(60))
+	assert.Assert(t, desired.Job.Spec.Template.Spec.TerminationGracePeriodSeconds == nil)
+
+	var jmGracePeriod, tmGracePeriod, submitterGracePeriod int64 = 120, 30, 10
+	cluster.Spec.JobManager.TerminationGracePeriodSeconds = &jmGracePeriod
+	cluster.Spec.TaskManager.TerminationGracePeriodSeconds = &tmGracePeriod
+	cluster.Spec.Job.TerminationGracePeriodSeconds = &submitterGracePeriod
+	desired = DesiredState(cluster, Options{})
+	assert.Equal(t, *desired.JmStatefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds, int64(120))
+	assert.Equal(t, *desired.TmStatefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds, int64(30))
+	assert.Equal(t, *desired.Job.Spec.Template.Spec.TerminationGracePeriodSeconds, int64(10))
+}
+
+func TestJVMDiagnostics(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	cluster.Spec.TaskManager.JVMOptions = []string{"-XX:+UseG1GC"}
+	cluster.Spec.Diagnostics = &v1beta1.DiagnosticsSpec{}
+
+	var desired = DesiredState(cluster, Options{})
+
+	// The JVMs do not exit on OutOfMemoryError unless requested.
+	var flinkConf = desired.ConfigMap.Data["flink-conf.yaml"]
+	assert.Assert(t, strings.Contains(flinkConf,
+		"env.java.opts.jobmanager: -XX:+HeapDumpOnOutOfMemoryError -XX:HeapDumpPath=/opt/flink/diagnostics\n"))
+	assert.Assert(t, strings.Contains(flinkConf,
+		"env.java.opts.taskmanager: -XX:+UseG1GC -XX:+HeapDumpOnOutOfMemoryError -XX:HeapDumpPath=/opt/flink/diagnostics\n"))
+	// No diagnostics collector without the operator image.
+	for _, container := range desired.TmStatefulSet.Spec.Template.Spec.Containers {
+		assert.Assert(t, container.Name != "diagnostics-collector")
+	}
+	var volume = corev1.Volume{
+		Name:         "diagnostics-volume",
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}
+	var mount = corev1.VolumeMount{Name: "diagnostics-volume", MountPath: "/opt/flink/diagnostics"}
+	for _, podSpec := range []corev1.PodSpec{desired.JmStatefulSet.Spec.Template.Spec, desired.TmStatefulSet.Spec.Template.Spec} {
+		assert.DeepEqual(t, podSpec.Volumes[len(podSpec.Volumes)-1], volume)
+		var mounts = podSpec.Containers[0].VolumeMounts
+		assert.DeepEqual(t, mounts[len(mounts)-1], mount)
+	}
+
+	var heapDump = false
+	cluster.Spec.Diagnostics = &v1beta1.DiagnosticsSpec{
+		HeapDumpOnOutOfMemory: &heapDump,
+		Volume:                &corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/flink"}},
+	}
+	desired = DesiredState(cluster, Options{})
+	flinkConf = desired.ConfigMap.Data["flink-conf.yaml"]
+	assert.Assert(t, !strings.Contains(flinkConf, "env.java.opts.jobmanager"))
+	assert.Assert(t, strings.Contains(flinkConf, "env.java.opts.taskmanager: -XX:+UseG1GC\n"))
+	var jmVolumes = desired.JmStatefulSet.Spec.Template.Spec.Volumes
+	assert.DeepEqual(t, jmVolumes[len(jmVolumes)-1].VolumeSource, *cluster.Spec.Diagnostics.Volume)
+
+	var exitOnOutOfMemory = true
+	cluster.Spec.Diagnostics = &v1beta1.DiagnosticsSpec{
+		ExitOnOutOfMemory: &exitOnOutOfMemory,
+		Upload: &v1beta1.DiagnosticsUpload{
+			URI: "gs://my-bucket/diagnostics/",
+			AuthorizationSecret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "diagnostics"},
+				Key:                  "authorization",
+			},
+		},
+	}
+	desired = DesiredState(cluster, Options{OperatorImage: "ghcr.io/spotify/flink-operator:v0.5.0"})
+	flinkConf = desired.ConfigMap.Data["flink-conf.yaml"]
+	assert.Assert(t, strings.Contains(flinkConf,
+		"env.java.opts.jobmanager: -XX:+HeapDumpOnOutOfMemoryError -XX:HeapDumpPath=/opt/flink/diagnostics -XX:+ExitOnOutOfMemoryError\n"))
+	for _, podSpec := range []corev1.PodSpec{desired.JmStatefulSet.Spec.Template.Spec, desired.TmStatefulSet.Spec.Template.Spec} {
+		var collector = podSpec.Containers[len(podSpec.Containers)-1]
+		assert.Equal(t, collector.Name, "diagnostics-collector")
+		assert.Equal(t, collector.Image, "ghcr.io/spotify/flink-operator:v0.5.0")
+		assert.DeepEqual(t, collector.Args, []string{"collect-diagnostics", "--dir", "/opt/flink/diagnostics",
+			"--upload", "gs://my-bucket/diagnostics/default/fjc/$(POD_NAME)"})
+		// The collector uploads with the environment and the volumes of the main container.
+		assert.DeepEqual(t, collector.VolumeMounts, podSpec.Containers[0].VolumeMounts)
+		assert.DeepEqual(t, collector.Env[len(collector.Env)-2:], []corev1.EnvVar{
+			{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+			{Name: "DIAGNOSTICS_AUTHORIZATION", ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: cluster.Spec.Diagnostics.Upload.AuthorizationSecret,
+			}},
+		})
+	}
+}
+
+func TestLogSidecar(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	cluster.Spec.Logging = &v1beta1.LoggingSpec{Sidecar: &v1beta1.LogSidecarSpec{
+		Template:     corev1.Container{Name: "fluent-bit", Image: "fluent/fluent-bit:2.1"},
+		OutputSecret: "fluent-bit-output",
+	}}
+
+	var desired = DesiredState(cluster, Options{})
+
+	var mount = corev1.VolumeMount{Name: "log-volume", MountPath: "/opt/flink/log"}
+	for component, podSpec := range map[string]corev1.PodSpec{
+		"jobmanager":  desired.JmStatefulSet.Spec.Template.Spec,
+		"taskmanager": desired.TmStatefulSet.Spec.Template.Spec,
+	} {
+		var mounts = podSpec.Containers[0].VolumeMounts
+		assert.DeepEqual(t, mounts[len(mounts)-1], mount)
+
+		var sidecar *corev1.Container
+		for i := range podSpec.Containers {
+			if podSpec.Containers[i].Name == "fluent-bit" {
+				sidecar = &podSpec.Containers[i]
+			}
+		}
+		assert.Assert(t, sidecar != nil)
+		assert.Equal(t, sidecar.Image, "fluent/fluent-bit:2.1")
+		assert.DeepEqual(t, sidecar.VolumeMounts, []corev1.VolumeMount{
+			{Name: "log-volume", MountPath: "/opt/flink/log", ReadOnly: true},
+			{Name: "log-forwarder-output-volume", MountPath: "/opt/flink-operator/log-forwarder", ReadOnly: true},
+		})
+		assert.DeepEqual(t, sidecar.Env[:2], []corev1.EnvVar{
+			{Name: "FLINK_CLUSTER", Value: cluster.Name},
+			{Name: "FLINK_COMPONENT", Value: component},
+		})
+
+		var volumes = podSpec.Volumes[len(podSpec.Volumes)-2:]
+		assert.DeepEqual(t, volumes, []corev1.Volume{
+			{Name: "log-volume", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			{Name: "log-forwarder-output-volume", VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "fluent-bit-output"},
+			}},
+		})
+	}
+}
+
+func TestLogSidecarWithMountedLogDirectory(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	cluster.Spec.Logging = &v1beta1.LoggingSpec{Sidecar: &v1beta1.LogSidecarSpec{
+		Template: corev1.Container{Name: "fluent-bit", Image: "fluent/fluent-bit:2.1"},
+	}}
+	cluster.Spec.TaskManager.Volumes = append(cluster.Spec.TaskManager.Volumes, corev1.Volume{
+		Name:         "flink-logs",
+		VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/log/flink"}},
+	})
+	cluster.Spec.TaskManager.VolumeMounts = append(cluster.Spec.TaskManager.VolumeMounts,
+		corev1.VolumeMount{Name: "flink-logs", MountPath: "/opt/flink/log"})
+
+	var desired = DesiredState(cluster, Options{})
+
+	// The forwarder shares the volume mounted by the user, and no log volume is added.
+	var podSpec = desired.TmStatefulSet.Spec.Template.Spec
+	var sidecar *corev1.Container
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == "fluent-bit" {
+			sidecar = &podSpec.Containers[i]
+		}
+	}
+	assert.Assert(t, sidecar != nil)
+	assert.DeepEqual(t, sidecar.VolumeMounts, []corev1.VolumeMount{
+		{Name: "flink-logs", MountPath: "/opt/flink/log", ReadOnly: true},
+	})
+	for _, volume := range podSpec.Volumes {
+		assert.Assert(t, volume.Name != "log-volume")
+	}
+}
+
+func TestExposeTaskManagerMetrics(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var expose = true
+	cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{ExposeTaskManagerMetrics: &expose}
+	cluster.Spec.FlinkProperties = map[string]string{
+		"metrics.reporter.prom.factory.class": "org.apache.flink.metrics.prometheus.PrometheusReporterFactory",
+		"metrics.reporter.prom.port":          "9250",
+	}
+	cluster.Spec.TaskManager.PodAnnotations = map[string]string{"prometheus.io/scrape": "false"}
+
+	var desired = DesiredState(cluster, Options{})
+	var ports = desired.TmStatefulSet.Spec.Template.Spec.Containers[0].Ports
+	assert.DeepEqual(t, ports[len(ports)-1], corev1.ContainerPort{Name: "metrics", ContainerPort: 9250})
+	assert.DeepEqual(t, desired.TmStatefulSet.Spec.Template.Annotations, map[string]string{
+		"prometheus.io/scrape": "false",
+		"prometheus.io/port":   "9250",
+	})
+	var servicePorts = desired.TmService.Spec.Ports
+	assert.DeepEqual(t, servicePorts[len(servicePorts)-1], corev1.ServicePort{Name: "metrics", Port: 9250})
+	assert.Equal(t, desired.TmService.Spec.ClusterIP, corev1.ClusterIPNone)
+
+	// The reporter port is already exposed with extraPorts.
+	cluster.Spec.TaskManager.ExtraPorts = []v1beta1.NamedPort{{Name: "prom", ContainerPort: 9250}}
+	desired = DesiredState(cluster, Options{})
+	ports = desired.TmStatefulSet.Spec.Template.Spec.Containers[0].Ports
+	assert.DeepEqual(t, ports[len(ports)-1], corev1.ContainerPort{Name: "prom", ContainerPort: 9250})
+	servicePorts = desired.TmService.Spec.Ports
+	assert.DeepEqual(t, servicePorts[len(servicePorts)-1], corev1.ServicePort{Name: "metrics", Port: 9250})
+
+	expose = false
+	desired = DesiredState(cluster, Options{})
+	assert.DeepEqual(t, desired.TmStatefulSet.Spec.Template.Annotations, map[string]string{"prometheus.io/scrape": "false"})
+	assert.Equal(t, len(desired.TmService.Spec.Ports), 3)
+}
+
+func TestMetricsPortAndAnnotations(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var port = int32(9250)
+	cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+		Reporters: []v1beta1.MetricsReporter{{Name: "prom", Prometheus: &v1beta1.PrometheusReporter{Port: &port}}},
+	}
+
+	// The metrics port is declared without annotations.
+	var desired = DesiredState(cluster, Options{})
+	var jmPorts = desired.JmStatefulSet.Spec.Template.Spec.Containers[0].Ports
+	assert.DeepEqual(t, jmPorts[len(jmPorts)-1], corev1.ContainerPort{Name: "metrics", ContainerPort: 9250})
+	var tmPorts = desired.TmStatefulSet.Spec.Template.Spec.Containers[0].Ports
+	assert.DeepEqual(t, tmPorts[len(tmPorts)-1], corev1.ContainerPort{Name: "metrics", ContainerPort: 9250})
+	assert.Assert(t, desired.JmStatefulSet.Spec.Template.Annotations["prometheus.io/scrape"] == "")
+	assert.Equal(t, len(desired.TmService.Spec.Ports), 3)
+
+	var annotate = true
+	cluster.Spec.Monitoring.PrometheusAnnotations = &annotate
+	cluster.Spec.JobManager.PodAnnotations = map[string]string{"prometheus.io/port": "9999"}
+	desired = DesiredState(cluster, Options{})
+	assert.DeepEqual(t, desired.JmStatefulSet.Spec.Template.Annotations, map[string]string{
+		"prometheus.io/scrape": "true",
+		"prometheus.io/port":   "9999",
+	})
+	assert.DeepEqual(t, desired.TmStatefulSet.Spec.Template.Annotations, map[string]string{
+		"example.com":          "example",
+		"prometheus.io/scrape": "true",
+		"prometheus.io/port":   "9250",
+	})
+
+	// The port declared by extraPorts is not declared again.
+	cluster.Spec.JobManager.ExtraPorts = []v1beta1.NamedPort{{Name: "metrics", ContainerPort: 9999}}
+	desired = DesiredState(cluster, Options{})
+	jmPorts = desired.JmStatefulSet.Spec.Template.Spec.Containers[0].Ports
+	assert.DeepEqual(t, jmPorts[len(jmPorts)-1], corev1.ContainerPort{Name: "metrics", ContainerPort: 9999})
+}
+
+func TestServiceAppProtocols(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var desired = DesiredState(cluster, Options{})
+	for _, port := range desired.JmService.Spec.Ports {
+		assert.Assert(t, port.AppProtocol == nil, port.Name)
+	}
+
+	var http, tcp = "http", "tcp"
+	cluster.Spec.JobManager.Ports.AppProtocols = map[string]string{"ui": "http"}
+	cluster.Spec.JobManager.ExtraPorts = []v1beta1.NamedPort{
+		{Name: "jmx", ContainerPort: 9999},
+		{Name: "grpc", ContainerPort: 9090, AppProtocol: &http},
+	}
+	cluster.Spec.TaskManager.Ports.AppProtocols = map[string]string{"rpc": "tcp"}
+	cluster.Spec.TaskManager.ExtraPorts = []v1beta1.NamedPort{
+		{Name: "statsd", ContainerPort: 8125, Protocol: "UDP", AppProtocol: &tcp},
+	}
+	desired = DesiredState(cluster, Options{})
+
+	// Only the extra ports with an application protocol are added to the services.
+	var jmPorts = desired.JmService.Spec.Ports
+	assert.Equal(t, len(jmPorts), 5)
+	assert.DeepEqual(t, jmPorts[3], corev1.ServicePort{
+		Name: "ui", Port: 8081, TargetPort: intstr.FromString("ui"), AppProtocol: &http})
+	assert.Assert(t, jmPorts[0].AppProtocol == nil)
+	assert.DeepEqual(t, jmPorts[4], corev1.ServicePort{
+		Name: "grpc", Port: 9090, TargetPort: intstr.FromString("grpc"), AppProtocol: &http})
+
+	var tmPorts = desired.TmService.Spec.Ports
+	assert.Equal(t, len(tmPorts), 4)
+	assert.DeepEqual(t, tmPorts[1], corev1.ServicePort{Name: "rpc", Port: 6122, AppProtocol: &tcp})
+	assert.DeepEqual(t, tmPorts[3], corev1.ServicePort{
+		Name: "statsd", Protocol: corev1.ProtocolUDP, Port: 8125, TargetPort: intstr.FromString("statsd"), AppProtocol: &tcp})
+}
+
+func TestCanaryUpdatePartition(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var desired = DesiredState(cluster, Options{})
+	assert.DeepEqual(t, desired.TmStatefulSet.Spec.UpdateStrategy, appsv1.StatefulSetUpdateStrategy{})
+
+	cluster.Spec.CanaryUpdate = &v1beta1.CanaryUpdateSpec{Percentage: 50}
+	cluster.Status.Revision = v1beta1.RevisionStatus{CurrentRevision: "flinkjobcluster-sample-85dc8f749-1", NextRevision: "flinkjobcluster-sample-aa5e3a87z-2"}
+	cluster.Status.CanaryUpdate = &v1beta1.CanaryUpdateStatus{
+		Revision: "flinkjobcluster-sample-aa5e3a87z-2",
+		State:    v1beta1.CanaryUpdateStateProgressing,
+		Replicas: 1,
+	}
+	desired = DesiredState(cluster, Options{})
+	var partition = *cluster.Spec.TaskManager.Replicas - 1
+	assert.DeepEqual(t, desired.TmStatefulSet.Spec.UpdateStrategy, appsv1.StatefulSetUpdateStrategy{
+		Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+	})
+
+	cluster.Status.CanaryUpdate.State = v1beta1.CanaryUpdateStatePromoted
+	desired = DesiredState(cluster, Options{})
+	assert.DeepEqual(t, desired.TmStatefulSet.Spec.UpdateStrategy, appsv1.StatefulSetUpdateStrategy{})
+}
+
+func TestMetricsReporters(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var interval = "60 SECONDS"
+	var dataCenter = "EU"
+	var apiKeySecret = corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "datadog"},
+		Key:                  "api-key",
+	}
+	cluster.Spec.FlinkVersion = "1.15"
+	cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{Reporters: []v1beta1.MetricsReporter{
+		{Name: "prom", Prometheus: &v1beta1.PrometheusReporter{}},
+		{Name: "dd", Interval: &interval, Datadog: &v1beta1.DatadogReporter{
+			APIKeySecret: apiKeySecret,
+			Tags:         []string{"env:prod", "team:data"},
+			DataCenter:   &dataCenter,
+		}},
+		{Name: "statsd", StatsD: &v1beta1.StatsDReporter{Host: "statsd.monitoring", Port: 8125}},
+	}}
+	cluster.Spec.FlinkProperties = map[string]string{"metrics.reporter.dd.maxMetricsPerRequest": "1000"}
+
+	var desired = DesiredState(cluster, Options{})
+	var flinkConf = desired.ConfigMap.Data["flink-conf.yaml"]
+	for _, property := range []string{
+		"metrics.reporter.prom.factory.class: org.apache.flink.metrics.prometheus.PrometheusReporterFactory\n",
+		"metrics.reporter.dd.factory.class: org.apache.flink.metrics.datadog.DatadogHttpReporterFactory\n",
+		"metrics.reporter.dd.interval: 60 SECONDS\n",
+		"metrics.reporter.dd.tags: env:prod,team:data\n",
+		"metrics.reporter.dd.dataCenter: EU\n",
+		"metrics.reporter.dd.maxMetricsPerRequest: 1000\n",
+		"metrics.reporter.statsd.factory.class: org.apache.flink.metrics.statsd.StatsDReporterFactory\n",
+		"metrics.reporter.statsd.host: statsd.monitoring\n",
+		"metrics.reporter.statsd.port: 8125\n",
+	} {
+		assert.Assert(t, strings.Contains(flinkConf, property), property)
+	}
+	assert.Assert(t, !strings.Contains(flinkConf, "apikey"))
+
+	var envVar = corev1.EnvVar{
+		Name:      "FLINK_METRICS_REPORTER_DD_APIKEY",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &apiKeySecret},
+	}
+	for _, container := range []corev1.Container{
+		desired.JmStatefulSet.Spec.Template.Spec.Containers[0],
+		desired.TmStatefulSet.Spec.Template.Spec.Containers[0],
+	} {
+		var found bool
+		for _, e := range container.Env {
+			if e.Name == envVar.Name {
+				assert.DeepEqual(t, e, envVar)
+				found = true
+			}
+		}
+		assert.Assert(t, found)
+		assert.DeepEqual(t, container.Args[1:], []string{"-Dmetrics.reporter.dd.apikey=$(FLINK_METRICS_REPORTER_DD_APIKEY)"})
+	}
+	// The job submitter does not report metrics.
+	for _, envVar := range desired.Job.Spec.Template.Spec.Containers[0].Env {
+		assert.Assert(t, envVar.Name != "FLINK_METRICS_REPORTER_DD_APIKEY")
+	}
+
+	// Reporters are configured with their classes before Flink 1.11.
+	cluster.Spec.FlinkVersion = "1.10"
+	desired = DesiredState(cluster, Options{})
+	flinkConf = desired.ConfigMap.Data["flink-conf.yaml"]
+	assert.Assert(t, strings.Contains(flinkConf, "metrics.reporter.prom.class: org.apache.flink.metrics.prometheus.PrometheusReporter\n"))
+	assert.Assert(t, !strings.Contains(flinkConf, "factory.class"))
+}
+
+func TestInternalLoadBalancerService(t *testing.T) {
+	var jmRPCPort int32 = 6123
+	var jmBlobPort int32 = 6124
+	var jmQueryPort int32 = 6125
+	var jmUIPort int32 = 8081
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "flinkjobcluster-sample",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			JobManager: &v1beta1.JobManagerSpec{
+				AccessScope:          v1beta1.AccessScopeInternalLB,
+				LoadBalancerProvider: v1beta1.LoadBalancerProviderAWS,
+				Ports: v1beta1.JobManagerPorts{
+					RPC:   &jmRPCPort,
+					Blob:  &jmBlobPort,
+					Query: &jmQueryPort,
+					UI:    &jmUIPort,
+				},
+				ServiceAnnotations: map[string]string{
+					"service.beta.kubernetes.io/aws-load-balancer-scheme": "internet-facing",
+				},
+			},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{NextRevision: "flinkjobcluster-sample-85dc8f749-1"},
+		},
+	}
+
+	var service = newJobManagerService(cluster)
+	assert.Equal(t, service.Spec.Type, corev1.ServiceTypeLoadBalancer)
+	assert.DeepEqual(t, service.Annotations, map[string]string{
+		"service.beta.kubernetes.io/aws-load-balancer-internal": "true",
+		"service.beta.kubernetes.io/aws-load-balancer-scheme":   "internet-facing",
+	})
+
+	cluster.Spec.JobManager.LoadBalancerProvider = v1beta1.LoadBalancerProviderAzure
+	cluster.Spec.JobManager.ServiceAnnotations = nil
+	service = newJobManagerService(cluster)
+	assert.DeepEqual(t, service.Annotations, map[string]string{
+		"service.beta.kubernetes.io/azure-load-balancer-internal": "true",
+	})
+
+	cluster.Spec.JobManager.LoadBalancerProvider = v1beta1.LoadBalancerProviderGCP
+	service = newJobManagerService(cluster)
+	assert.DeepEqual(t, service.Annotations, map[string]string{
+		"networking.gke.io/load-balancer-type": "Internal",
+	})
+}
+
+func TestServiceIPFamilies(t *testing.T) {
+	var jmRPCPort int32 = 6123
+	var jmBlobPort int32 = 6124
+	var jmQueryPort int32 = 6125
+	var jmUIPort int32 = 8081
+	var tmDataPort int32 = 6121
+	var tmRPCPort int32 = 6122
+	var tmQueryPort int32 = 6125
+	var ipFamilyPolicy = corev1.IPFamilyPolicyPreferDualStack
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "flinkjobcluster-sample",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			JobManager: &v1beta1.JobManagerSpec{
+				AccessScope: v1beta1.AccessScopeCluster,
+				Ports: v1beta1.JobManagerPorts{
+					RPC:   &jmRPCPort,
+					Blob:  &jmBlobPort,
+					Query: &jmQueryPort,
+					UI:    &jmUIPort,
+				},
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				Ports: v1beta1.TaskManagerPorts{
+					Data:  &tmDataPort,
+					RPC:   &tmRPCPort,
+					Query: &tmQueryPort,
+				},
+			},
+			Networking: &v1beta1.NetworkingSpec{
+				IPFamilyPolicy: &ipFamilyPolicy,
+				IPFamilies:     []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol},
+			},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{NextRevision: "flinkjobcluster-sample-85dc8f749-1"},
+		},
+	}
+
+	for _, service := range []*corev1.Service{newJobManagerService(cluster), newTaskManagerService(cluster)} {
+		assert.Equal(t, *service.Spec.IPFamilyPolicy, corev1.IPFamilyPolicyPreferDualStack)
+		assert.DeepEqual(t, service.Spec.IPFamilies, []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol})
+	}
+
+	cluster.Spec.Networking = nil
+	for _, service := range []*corev1.Service{newJobManagerService(cluster), newTaskManagerService(cluster)} {
+		assert.Assert(t, service.Spec.IPFamilyPolicy == nil)
+		assert.Assert(t, service.Spec.IPFamilies == nil)
+	}
+}
+
+func TestArtifactCache(t *testing.T) {
+	var jmRPCPort int32 = 6123
+	var jmBlobPort int32 = 6124
+	var jmQueryPort int32 = 6125
+	var jmUIPort int32 = 8081
+	var jarFile = "https://repo.example.com/jobs/my-job-1.0.jar?token=abc"
+	var parallelism int32 = 2
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "flinkjobcluster-sample",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			Image: v1beta1.ImageSpec{Name: "flink:1.14.2"},
+			JobManager: &v1beta1.JobManagerSpec{
+				AccessScope: v1beta1.AccessScopeCluster,
+				Ports: v1beta1.JobManagerPorts{
+					RPC:   &jmRPCPort,
+					Blob:  &jmBlobPort,
+					Query: &jmQueryPort,
+					UI:    &jmUIPort,
+				},
+			},
+			Job: &v1beta1.JobSpec{
+				JarFile:     &jarFile,
+				Parallelism: &parallelism,
+				ArtifactCache: &v1beta1.ArtifactCacheSpec{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "flink-artifacts"},
+				},
+				InitContainers: []corev1.Container{{Name: "user-init"}},
+			},
+		},
+	}
+
+	var podSpec = newJobSubmitterPodSpec(cluster, Options{})
+	var mainContainer = podSpec.Containers[0]
+	var cachedJarFile = mainContainer.Args[len(mainContainer.Args)-1]
+	assert.Assert(t, strings.HasPrefix(cachedJarFile, "/opt/flink-operator/artifact-cache/"))
+	assert.Assert(t, strings.HasSuffix(cachedJarFile, "/my-job-1.0.jar"))
+
+	assert.Equal(t, len(podSpec.InitContainers), 2)
+	var cacheContainer = podSpec.InitContainers[0]
+	assert.Equal(t, cacheContainer.Name, "artifact-cache")
+	assert.Equal(t, cacheContainer.Image, "flink:1.14.2")
+	assert.DeepEqual(t, cacheContainer.Env, []corev1.EnvVar{
+		{Name: "ARTIFACT_URI", Value: jarFile},
+		{Name: "ARTIFACT_PATH", Value: cachedJarFile},
+	})
+	var cacheMount = corev1.VolumeMount{Name: "artifact-cache-volume", MountPath: "/opt/flink-operator/artifact-cache"}
+	assert.DeepEqual(t, cacheContainer.VolumeMounts[0], cacheMount)
+	var foundMount = false
+	for _, mount := range mainContainer.VolumeMounts {
+		foundMount = foundMount || mount == cacheMount
+	}
+	assert.Assert(t, foundMount, "artifact-cache-volume mount is expected")
+	var foundVolume = false
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == "artifact-cache-volume" {
+			assert.DeepEqual(t, volume.VolumeSource, corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "flink-artifacts"},
+			})
+			foundVolume = true
+		}
+	}
+	assert.Assert(t, foundVolume, "artifact-cache-volume is expected")
+
+	// Local JAR files are not cached.
+	var localJarFile = "/cache/my-job.jar"
+	cluster.Spec.Job.JarFile = &localJarFile
+	podSpec = newJobSubmitterPodSpec(cluster, Options{})
+	mainContainer = podSpec.Containers[0]
+	assert.Equal(t, mainContainer.Args[len(mainContainer.Args)-1], localJarFile)
+	assert.Equal(t, len(podSpec.InitContainers), 1)
+}
+
+func TestMavenArtifact(t *testing.T) {
+	var jmUIPort int32 = 8081
+	var jarFile = "mvn:com.acme:pipeline:1.4.2"
+	var parallelism int32 = 2
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "flinkjobcluster-sample",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			Image: v1beta1.ImageSpec{Name: "flink:1.14.2"},
+			JobManager: &v1beta1.JobManagerSpec{
+				Ports: v1beta1.JobManagerPorts{UI: &jmUIPort},
+			},
+			Job: &v1beta1.JobSpec{
+				JarFile:     &jarFile,
+				Parallelism: &parallelism,
+			},
+		},
+	}
+
+	// The artifact is fetched from Maven Central into the pod when it is not cached.
+	var podSpec = newJobSubmitterPodSpec(cluster, Options{})
+	var mainContainer = podSpec.Containers[0]
+	var fetchedJarFile = mainContainer.Args[len(mainContainer.Args)-1]
+	assert.Assert(t, strings.HasPrefix(fetchedJarFile, "/opt/flink-operator/artifact-cache/"))
+	assert.Assert(t, strings.HasSuffix(fetchedJarFile, "/pipeline-1.4.2.jar"))
+	var fetchContainer = podSpec.InitContainers[0]
+	var artifactURL = "https://repo1.maven.org/maven2/com/acme/pipeline/1.4.2/pipeline-1.4.2.jar"
+	assert.DeepEqual(t, fetchContainer.Env, []corev1.EnvVar{
+		{Name: "ARTIFACT_URI", Value: artifactURL},
+		{Name: "ARTIFACT_PATH", Value: fetchedJarFile},
+		{Name: "ARTIFACT_CHECKSUM_URI", Value: artifactURL + ".sha1"},
+	})
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == "artifact-cache-volume" {
+			assert.Assert(t, volume.EmptyDir != nil)
+		}
+	}
+	assert.Assert(t, hasVolume(podSpec.Volumes, "artifact-cache-volume"))
+
+	// The credentials of the repository are read from the secret.
+	var repositoryURL = "https://maven.acme.com/releases"
+	cluster.Spec.Job.MavenRepository = &v1beta1.MavenRepositorySpec{
+		URL:       &repositoryURL,
+		SecretRef: &corev1.LocalObjectReference{Name: "maven-credentials"},
+	}
+	cluster.Spec.Job.ArtifactCache = &v1beta1.ArtifactCacheSpec{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "flink-artifacts"},
+	}
+	podSpec = newJobSubmitterPodSpec(cluster, Options{})
+	fetchContainer = podSpec.InitContainers[0]
+	assert.Equal(t, fetchContainer.Env[0].Value, "https://maven.acme.com/releases/com/acme/pipeline/1.4.2/pipeline-1.4.2.jar")
+	assert.Equal(t, fetchContainer.Env[3].Name, "ARTIFACT_USERNAME")
+	assert.Equal(t, fetchContainer.Env[3].ValueFrom.SecretKeyRef.Name, "maven-credentials")
+	assert.Equal(t, fetchContainer.Env[4].ValueFrom.SecretKeyRef.Key, "password")
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == "artifact-cache-volume" {
+			assert.Equal(t, volume.PersistentVolumeClaim.ClaimName, "flink-artifacts")
+		}
+	}
+
+	// The pinned checksum is verified instead of the checksum of the repository.
+	cluster.Spec.Job.JarFileSHA256 = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	podSpec = newJobSubmitterPodSpec(cluster, Options{})
+	fetchContainer = podSpec.InitContainers[0]
+	assert.DeepEqual(t, fetchContainer.Env[2], corev1.EnvVar{Name: "ARTIFACT_SHA256", Value: cluster.Spec.Job.JarFileSHA256})
+	for _, envVar := range fetchContainer.Env {
+		assert.Assert(t, envVar.Name != "ARTIFACT_CHECKSUM_URI")
+	}
+}
+
+func TestGitRepo(t *testing.T) {
+	var jmUIPort int32 = 8081
+	var pyFile = "jobs/word_count.py"
+	var ref = "v1.2.0"
+	var repoPath = "pipelines"
+	var parallelism int32 = 2
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "flinkjobcluster-sample",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			Image: v1beta1.ImageSpec{Name: "flink:1.14.2"},
+			JobManager: &v1beta1.JobManagerSpec{
+				Ports: v1beta1.JobManagerPorts{UI: &jmUIPort},
+			},
+			Job: &v1beta1.JobSpec{
+				PyFile:      &pyFile,
+				Parallelism: &parallelism,
+				GitRepo: &v1beta1.GitRepoSpec{
+					URL:       "https://github.com/example/pipelines.git",
+					Ref:       &ref,
+					Path:      &repoPath,
+					SecretRef: &corev1.LocalObjectReference{Name: "git-credentials"},
+				},
+				InitContainers: []corev1.Container{{Name: "user-init"}},
+			},
+		},
+	}
+
+	var podSpec = newJobSubmitterPodSpec(cluster, Options{})
+	var mainContainer = podSpec.Containers[0]
+	assert.Equal(t, mainContainer.WorkingDir, "/opt/flink-operator/git-repo/current/pipelines")
+	var gitMount = corev1.VolumeMount{Name: "git-repo-volume", MountPath: "/opt/flink-operator/git-repo"}
+	assert.Assert(t, hasVolumeMount(mainContainer.VolumeMounts, gitMount), "git-repo-volume mount is expected")
+	assert.Assert(t, hasVolume(podSpec.Volumes, "git-repo-volume"))
+	assert.Assert(t, !hasVolume(podSpec.Volumes, "git-secret-volume"))
+
+	assert.Equal(t, len(podSpec.InitContainers), 2)
+	var gitSyncContainer = podSpec.InitContainers[0]
+	assert.Equal(t, gitSyncContainer.Name, "git-sync")
+	assert.Equal(t, gitSyncContainer.Image, "registry.k8s.io/git-sync/git-sync:v4.2.4")
+	assert.DeepEqual(t, gitSyncContainer.Args, []string{
+		"--repo=https://github.com/example/pipelines.git",
+		"--root=/opt/flink-operator/git-repo",
+		"--link=current",
+		"--one-time",
+		"--depth=1",
+		"--ref=v1.2.0",
+	})
+	assert.Assert(t, hasVolumeMount(gitSyncContainer.VolumeMounts, gitMount))
+	assert.Equal(t, len(gitSyncContainer.Env), 2)
+	assert.Equal(t, gitSyncContainer.Env[0].Name, "GITSYNC_USERNAME")
+	assert.Equal(t, gitSyncContainer.Env[0].ValueFrom.SecretKeyRef.Name, "git-credentials")
+	assert.Equal(t, gitSyncContainer.Env[1].ValueFrom.SecretKeyRef.Key, "password")
+
+	// The SSH key and known hosts are mounted into the git-sync container only.
+	cluster.Spec.Job.GitRepo = &v1beta1.GitRepoSpec{
+		URL:       "git@github.com:example/pipelines.git",
+		SecretRef: &corev1.LocalObjectReference{Name: "git-ssh"},
+	}
+	podSpec = newJobSubmitterPodSpec(cluster, Options{})
+	mainContainer = podSpec.Containers[0]
+	gitSyncContainer = podSpec.InitContainers[0]
+	assert.Equal(t, mainContainer.WorkingDir, "/opt/flink-operator/git-repo/current")
+	assert.Assert(t, hasVolume(podSpec.Volumes, "git-secret-volume"))
+	assert.Assert(t, gitSyncContainer.Env == nil)
+	assert.DeepEqual(t, gitSyncContainer.Args[len(gitSyncContainer.Args)-2:], []string{
+		"--ssh-key-file=/etc/git-secret/ssh-privatekey",
+		"--ssh-known-hosts-file=/etc/git-secret/known_hosts",
+	})
+	var secretMount = corev1.VolumeMount{Name: "git-secret-volume", MountPath: "/etc/git-secret", ReadOnly: true}
+	assert.Assert(t, hasVolumeMount(gitSyncContainer.VolumeMounts, secretMount))
+	assert.Assert(t, !hasVolumeMount(mainContainer.VolumeMounts, secretMount))
+}
+
+func hasVolume(volumes []corev1.Volume, name string) bool {
+	for _, volume := range volumes {
+		if volume.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasVolumeMount(volumeMounts []corev1.VolumeMount, volumeMount corev1.VolumeMount) bool {
+	for _, mount := range volumeMounts {
+		if mount == volumeMount {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCalFineGrainedResources(t *testing.T) {
+	var slot = &v1beta1.SlotResources{
+		CPUCores:       resource.MustParse("500m"),
+		TaskHeapMemory: resource.MustParse("512Mi"),
+		ManagedMemory:  resource.MustParse("1Gi"),
+	}
+	assert.DeepEqual(t, calFineGrainedResources(slot, 3), map[string]string{
+		"cluster.fine-grained-resource-management.enabled": "true",
+		"taskmanager.cpu.cores":                            "1.5",
+		"taskmanager.memory.task.heap.size":                "1536m",
+		"taskmanager.memory.managed.size":                  "3072m",
+	})
+
+	slot.TaskOffHeapMemory = resource.MustParse("100M")
+	assert.Equal(t, calFineGrainedResources(slot, 2)["taskmanager.memory.task.off-heap.size"], "191m")
+}
+
+func TestHARBAC(t *testing.T) {
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "flinkjobcluster-sample",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			FlinkProperties: map[string]string{
+				"high-availability":            "kubernetes",
+				"high-availability.storageDir": "gs://my-bucket/ha",
+				"kubernetes.cluster-id":        "flinkjobcluster-sample",
+			},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{NextRevision: "flinkjobcluster-sample-85dc8f749-1"},
+		},
+	}
+
+	var serviceAccount = newHAServiceAccount(cluster)
+	assert.Assert(t, serviceAccount != nil)
+	assert.Equal(t, serviceAccount.Name, "flinkjobcluster-sample-flink-ha")
+	assert.Equal(t, serviceAccount.Namespace, "default")
+	assert.Equal(t, ServiceAccountName(cluster), "flinkjobcluster-sample-flink-ha")
+
+	var role = newHARole(cluster)
+	assert.Assert(t, role != nil)
+	assert.Equal(t, role.Name, "flinkjobcluster-sample-flink-ha")
+	assert.DeepEqual(t, role.Rules, []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"configmaps"},
+			Verbs:     []string{"create", "delete", "get", "list", "patch", "update", "watch"},
+		},
+	})
+
+	var roleBinding = newHARoleBinding(cluster)
+	assert.Assert(t, roleBinding != nil)
+	assert.DeepEqual(t, roleBinding.RoleRef, rbacv1.RoleRef{
+		APIGroup: "rbac.authorization.k8s.io",
+		Kind:     "Role",
+		Name:     "flinkjobcluster-sample-flink-ha",
+	})
+	assert.DeepEqual(t, roleBinding.Subjects, []rbacv1.Subject{
+		{Kind: "ServiceAccount", Name: "flinkjobcluster-sample-flink-ha", Namespace: "default"},
+	})
+
+	// The given service account is used as is.
+	var userServiceAccount = "flink"
+	cluster.Spec.ServiceAccountName = &userServiceAccount
+	assert.Assert(t, newHAServiceAccount(cluster) == nil)
+	assert.Assert(t, newHARole(cluster) == nil)
+	assert.Assert(t, newHARoleBinding(cluster) == nil)
+	assert.Equal(t, ServiceAccountName(cluster), "flink")
+
+	// ZooKeeper HA services do not need the permissions.
+	cluster.Spec.ServiceAccountName = nil
+	cluster.Spec.FlinkProperties["high-availability"] = "zookeeper"
+	assert.Assert(t, newHAServiceAccount(cluster) == nil)
+	assert.Equal(t, ServiceAccountName(cluster), "")
+}
+
+func TestGetJarDownloadURL(t *testing.T) {
+	var downloadURL, err = getJarDownloadURL("gs://my-bucket/jobs/word count.jar")
+	assert.NilError(t, err)
+	assert.Equal(t, downloadURL, "https://storage.googleapis.com/my-bucket/jobs/word%20count.jar")
+
+	downloadURL, err = getJarDownloadURL("s3://my-bucket/jobs/wordcount.jar")
+	assert.NilError(t, err)
+	assert.Equal(t, downloadURL, "https://my-bucket.s3.amazonaws.com/jobs/wordcount.jar")
+
+	downloadURL, err = getJarDownloadURL("https://example.com/wordcount.jar?token=a")
+	assert.NilError(t, err)
+	assert.Equal(t, downloadURL, "https://example.com/wordcount.jar?token=a")
+
+	_, err = getJarDownloadURL("hdfs:///jobs/wordcount.jar")
+	assert.Error(t, err, "unsupported JAR file URI hdfs:///jobs/wordcount.jar")
+}
@@ -0,0 +1,36 @@
+package render
+
+import (
+	"strconv"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+)
+
+const (
+	externalDNSHostnameAnnotation = "external-dns.alpha.kubernetes.io/hostname"
+	externalDNSTTLAnnotation      = "external-dns.alpha.kubernetes.io/ttl"
+)
+
+// ExternalDNSHostname returns the hostname of spec.jobManager.externalDNS with the cluster
+// name filled in, empty if it is not set.
+func ExternalDNSHostname(cluster *v1beta1.FlinkCluster) string {
+	var externalDNS = cluster.Spec.JobManager.ExternalDNS
+	if externalDNS == nil {
+		return ""
+	}
+	return JobManagerIngressHost(externalDNS.Hostname, cluster.Name)
+}
+
+// getExternalDNSAnnotations returns the external-dns annotations of the ingress or the service
+// which publishes the JobManager, nil if spec.jobManager.externalDNS is not set.
+func getExternalDNSAnnotations(cluster *v1beta1.FlinkCluster) map[string]string {
+	var externalDNS = cluster.Spec.JobManager.ExternalDNS
+	if externalDNS == nil {
+		return nil
+	}
+	var annotations = map[string]string{externalDNSHostnameAnnotation: ExternalDNSHostname(cluster)}
+	if externalDNS.TTL != nil {
+		annotations[externalDNSTTLAnnotation] = strconv.Itoa(int(*externalDNS.TTL))
+	}
+	return annotations
+}
@@ -0,0 +1,44 @@
+package render
+
+import (
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/pkg/render/rendertest"
+	"gotest.tools/v3/assert"
+)
+
+func TestExternalDNSAnnotations(t *testing.T) {
+	var cluster = rendertest.NewFlinkCluster()
+	var desired = DesiredState(cluster, Options{})
+	_, ok := desired.JmIngress.Annotations[externalDNSHostnameAnnotation]
+	assert.Assert(t, !ok)
+
+	// The ingress publishes the hostname if it is set.
+	var ttl int32 = 60
+	cluster.Spec.JobManager.ExternalDNS = &v1beta1.ExternalDNSSpec{Hostname: "{{$clusterName}}.flink.example.com", TTL: &ttl}
+	desired = DesiredState(cluster, Options{})
+	_, ok = desired.JmService.Annotations[externalDNSHostnameAnnotation]
+	assert.Assert(t, !ok)
+	assert.Equal(t, desired.JmIngress.Annotations[externalDNSHostnameAnnotation], "fjc.flink.example.com")
+	assert.Equal(t, desired.JmIngress.Annotations[externalDNSTTLAnnotation], "60")
+	assert.Equal(t, desired.JmIngress.Annotations["kubernetes.io/ingress.class"], "nginx")
+
+	// Otherwise the service does, and the annotations of the spec take precedence.
+	cluster.Spec.JobManager.Ingress = nil
+	cluster.Spec.JobManager.AccessScope = v1beta1.AccessScopeExternal
+	cluster.Spec.JobManager.ServiceAnnotations = map[string]string{externalDNSTTLAnnotation: "300"}
+	var writable = false
+	cluster.Spec.JobManager.ReadOnlyUI = &writable
+	desired = DesiredState(cluster, Options{})
+	assert.Equal(t, desired.JmService.Annotations[externalDNSHostnameAnnotation], "fjc.flink.example.com")
+	assert.Equal(t, desired.JmService.Annotations[externalDNSTTLAnnotation], "300")
+
+	// The UI service does if it exposes the read-only UI proxy.
+	cluster.Spec.JobManager.ReadOnlyUI = nil
+	desired = DesiredState(cluster, Options{})
+	_, ok = desired.JmService.Annotations[externalDNSHostnameAnnotation]
+	assert.Assert(t, !ok)
+	assert.Equal(t, desired.JmUIService.Annotations[externalDNSHostnameAnnotation], "fjc.flink.example.com")
+	assert.Equal(t, desired.JmUIService.Annotations[externalDNSTTLAnnotation], "300")
+}
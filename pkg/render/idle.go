@@ -0,0 +1,31 @@
+package render
+
+import (
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+)
+
+// IsIdlePolicyEnabled returns true if the session cluster is scaled to zero by
+// spec.idlePolicy while it runs no jobs.
+func IsIdlePolicyEnabled(cluster *v1beta1.FlinkCluster) bool {
+	return cluster.Spec.IdlePolicy != nil && cluster.Spec.Job == nil
+}
+
+// isScaledToZero returns true if the idle session cluster is recorded as scaled to zero.
+func isScaledToZero(cluster *v1beta1.FlinkCluster) bool {
+	return IsIdlePolicyEnabled(cluster) && cluster.Status.Idle != nil && cluster.Status.Idle.ScaledToZero
+}
+
+// getIdleReplicas returns zero replicas for the TaskManagers of the cluster scaled to zero,
+// and for the JobManager if spec.idlePolicy.scaleJobManager is enabled. It returns the
+// replicas of the spec otherwise.
+func getIdleReplicas(cluster *v1beta1.FlinkCluster, component string, replicas *int32) *int32 {
+	if !isScaledToZero(cluster) {
+		return replicas
+	}
+	var scaleJobManager = cluster.Spec.IdlePolicy.ScaleJobManager
+	if component == "jobmanager" && (scaleJobManager == nil || !*scaleJobManager) {
+		return replicas
+	}
+	var zero int32
+	return &zero
+}
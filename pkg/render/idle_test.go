@@ -0,0 +1,35 @@
+package render
+
+import (
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+)
+
+func TestGetIdleReplicas(t *testing.T) {
+	var replicas int32 = 4
+	var jobManagerReplicas int32 = 1
+	var cluster = &v1beta1.FlinkCluster{
+		Spec: v1beta1.FlinkClusterSpec{
+			JobManager:  &v1beta1.JobManagerSpec{Replicas: &jobManagerReplicas},
+			TaskManager: &v1beta1.TaskManagerSpec{Replicas: &replicas},
+			IdlePolicy:  &v1beta1.IdlePolicy{IdleMinutes: 30},
+		},
+	}
+	var tmReplicas = cluster.Spec.TaskManager.Replicas
+	var jmReplicas = cluster.Spec.JobManager.Replicas
+	assert.Equal(t, getIdleReplicas(cluster, "taskmanager", tmReplicas), tmReplicas)
+
+	cluster.Status.Idle = &v1beta1.IdleStatus{IdleSince: "2023-01-01T00:00:00Z", ScaledToZero: true}
+	assert.Equal(t, *getIdleReplicas(cluster, "taskmanager", tmReplicas), int32(0))
+	assert.Equal(t, getIdleReplicas(cluster, "jobmanager", jmReplicas), jmReplicas)
+
+	var scaleJobManager = true
+	cluster.Spec.IdlePolicy.ScaleJobManager = &scaleJobManager
+	assert.Equal(t, *getIdleReplicas(cluster, "jobmanager", jmReplicas), int32(0))
+
+	// Job clusters are not scaled to zero.
+	cluster.Spec.Job = &v1beta1.JobSpec{}
+	assert.Equal(t, getIdleReplicas(cluster, "taskmanager", tmReplicas), tmReplicas)
+}
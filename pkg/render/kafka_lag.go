@@ -0,0 +1,35 @@
+package render
+
+import (
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KafkaConsumerLagMetricName is the name of the metric of the lag of the consumer group of
+// spec.monitoring.kafkaLag, which the operator serves and the autoscaler of the TaskManagers
+// scales on.
+const KafkaConsumerLagMetricName = "flink_operator_kafka_consumer_lag"
+
+// getKafkaLagMetric returns the external metric of the autoscaler of the TaskManagers for
+// spec.taskManager.horizontalPodAutoscaler.targetKafkaLagPerReplica, nil if it is not set.
+func getKafkaLagMetric(cluster *v1beta1.FlinkCluster) *autoscalingv2.MetricSpec {
+	var hpa = cluster.Spec.TaskManager.HorizontalPodAutoscaler
+	if hpa == nil || hpa.TargetKafkaLagPerReplica == nil {
+		return nil
+	}
+	return &autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ExternalMetricSourceType,
+		External: &autoscalingv2.ExternalMetricSource{
+			Metric: autoscalingv2.MetricIdentifier{
+				Name:     KafkaConsumerLagMetricName,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"cluster": cluster.Name}},
+			},
+			Target: autoscalingv2.MetricTarget{
+				Type:         autoscalingv2.AverageValueMetricType,
+				AverageValue: resource.NewQuantity(*hpa.TargetKafkaLagPerReplica, resource.DecimalSI),
+			},
+		},
+	}
+}
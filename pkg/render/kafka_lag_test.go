@@ -0,0 +1,30 @@
+package render
+
+import (
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewHorizontalPodAutoscalerKafkaLag(t *testing.T) {
+	var target = int64(5000)
+	var cluster = &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"},
+		Spec: v1beta1.FlinkClusterSpec{TaskManager: &v1beta1.TaskManagerSpec{
+			HorizontalPodAutoscaler: &v1beta1.HorizontalPodAutoscalerSpec{MaxReplicas: 8, TargetKafkaLagPerReplica: &target},
+		}},
+		Status: v1beta1.FlinkClusterStatus{Revision: v1beta1.RevisionStatus{NextRevision: "orders-85dc8f749-1"}},
+	}
+	var hpa = newHorizontalPodAutoscaler(cluster)
+	assert.Equal(t, len(hpa.Spec.Metrics), 1)
+	var external = hpa.Spec.Metrics[0].External
+	assert.Equal(t, hpa.Spec.Metrics[0].Type, autoscalingv2.ExternalMetricSourceType)
+	assert.Equal(t, external.Metric.Name, "flink_operator_kafka_consumer_lag")
+	assert.DeepEqual(t, external.Metric.Selector.MatchLabels, map[string]string{"cluster": "orders"})
+	assert.Equal(t, external.Target.Type, autoscalingv2.AverageValueMetricType)
+	assert.Assert(t, external.Target.AverageValue.Equal(resource.MustParse("5000")))
+}
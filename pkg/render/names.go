@@ -0,0 +1,139 @@
+package render
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+)
+
+// The labels and annotations of the rendered resources.
+const (
+	RevisionNameLabel = "flinkoperator.k8s.io/revision-name"
+	JobIdLabel        = "flinkoperator.k8s.io/job-id"
+	// Pod template annotation with the hash of the ConfigMaps and Secrets referenced by the spec.
+	ReferencedConfigHashAnnotation = "flinkoperator.k8s.io/referenced-config-hash"
+)
+
+// DiagnosticsPath is the mount path of the diagnostics volume of the JobManager and
+// TaskManager pods, whose files are uploaded by the diagnostics collectors.
+const DiagnosticsPath = "/opt/flink/diagnostics"
+
+const defaultClusterDomain = "cluster.local"
+
+// Gets the DNS domain of the Kubernetes cluster from spec.networking.clusterDomain or the
+// CLUSTER_DOMAIN environment variable of the operator, empty if neither is set.
+func getClusterDomain(cluster *v1beta1.FlinkCluster) string {
+	if networking := cluster.Spec.Networking; networking != nil && networking.ClusterDomain != nil {
+		return *networking.ClusterDomain
+	}
+	return os.Getenv("CLUSTER_DOMAIN")
+}
+
+// JobManagerServiceHost returns the DNS name of the JobManager service inside the Kubernetes
+// cluster.
+func JobManagerServiceHost(cluster *v1beta1.FlinkCluster) string {
+	clusterDomain := getClusterDomain(cluster)
+	if clusterDomain == "" {
+		clusterDomain = defaultClusterDomain
+	}
+
+	return fmt.Sprintf(
+		"%s.%s.svc.%s",
+		JobManagerServiceName(cluster.Name),
+		cluster.Namespace,
+		clusterDomain)
+}
+
+// JobManagerAddress returns the address of the JobManager in the Flink configuration of the
+// pods and the job submitter. It is the fully qualified name of the service if the cluster
+// domain is configured, which resolves regardless of the search domains of the pods, the
+// service name otherwise.
+func JobManagerAddress(cluster *v1beta1.FlinkCluster) string {
+	if getClusterDomain(cluster) == "" {
+		return JobManagerServiceName(cluster.Name)
+	}
+	return JobManagerServiceHost(cluster)
+}
+
+// ConfigMapName returns the name of the ConfigMap of the cluster.
+func ConfigMapName(clusterName string) string {
+	return clusterName + "-configmap"
+}
+
+// FlinkPropertiesSecretName returns the name of the Secret of the properties resolved from
+// spec.flinkPropertiesFrom.
+func FlinkPropertiesSecretName(clusterName string) string {
+	return clusterName + "-flink-properties"
+}
+
+// PodDisruptionBudgetName returns the name of the PodDisruptionBudget of the cluster.
+func PodDisruptionBudgetName(clusterName string) string {
+	return "flink-" + clusterName
+}
+
+// HorizontalPodAutoscalerName returns the name of the HorizontalPodAutoscaler of the
+// TaskManagers.
+func HorizontalPodAutoscalerName(clusterName string) string {
+	return "flink-" + clusterName
+}
+
+// JobManagerName returns the name of the JobManager StatefulSet.
+func JobManagerName(clusterName string) string {
+	return clusterName + "-jobmanager"
+}
+
+// JobManagerServiceName returns the name of the JobManager service.
+func JobManagerServiceName(clusterName string) string {
+	return clusterName + "-jobmanager"
+}
+
+// JobManagerUIServiceName returns the name of the service which exposes only the read-only
+// UI proxy of the JobManager.
+func JobManagerUIServiceName(clusterName string) string {
+	return clusterName + "-jobmanager-ui"
+}
+
+// JobManagerIngressName returns the name of the JobManager ingress.
+func JobManagerIngressName(clusterName string) string {
+	return clusterName + "-jobmanager"
+}
+
+// TaskManagerName returns the name of the TaskManager StatefulSet or Deployment.
+func TaskManagerName(clusterName string) string {
+	return clusterName + "-taskmanager"
+}
+
+// JobManagerJobName returns the name of the Job of the JobManager of application mode.
+func JobManagerJobName(clusterName string) string {
+	return JobManagerName(clusterName)
+}
+
+// SubmitterJobName returns the name of the Job of the job submitter.
+func SubmitterJobName(clusterName string) string {
+	return clusterName + "-job-submitter"
+}
+
+// HAServiceAccountName returns the name of the service account, role and role binding for
+// Kubernetes HA services.
+func HAServiceAccountName(clusterName string) string {
+	return clusterName + "-flink-ha"
+}
+
+// NextRevisionName returns the name of the next revision without its number.
+func NextRevisionName(r *v1beta1.RevisionStatus) string {
+	return r.NextRevision[:strings.LastIndex(r.NextRevision, "-")]
+}
+
+// GenJobId returns the ID of the job of the next revision of the cluster.
+func GenJobId(cluster *v1beta1.FlinkCluster) (string, error) {
+	if cluster == nil || len(cluster.Status.Revision.NextRevision) == 0 {
+		return "", fmt.Errorf("error generating job id: cluster or next revision is nil")
+	}
+
+	hash := md5.Sum([]byte(cluster.Status.Revision.NextRevision))
+	return hex.EncodeToString(hash[:]), nil
+}
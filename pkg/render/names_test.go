@@ -0,0 +1,22 @@
+package render
+
+import (
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestJobManagerAddress(t *testing.T) {
+	t.Setenv("CLUSTER_DOMAIN", "")
+	var cluster = &v1beta1.FlinkCluster{ObjectMeta: metav1.ObjectMeta{Name: "mycluster", Namespace: "default"}}
+	assert.Equal(t, JobManagerAddress(cluster), "mycluster-jobmanager")
+
+	t.Setenv("CLUSTER_DOMAIN", "my.domain")
+	assert.Equal(t, JobManagerAddress(cluster), "mycluster-jobmanager.default.svc.my.domain")
+
+	var clusterDomain = "k8s.example.com"
+	cluster.Spec.Networking = &v1beta1.NetworkingSpec{ClusterDomain: &clusterDomain}
+	assert.Equal(t, JobManagerAddress(cluster), "mycluster-jobmanager.default.svc.k8s.example.com")
+}
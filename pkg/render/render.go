@@ -0,0 +1,79 @@
+// Package render renders the Kubernetes resources of a FlinkCluster as the operator creates
+// them. The operator reconciles its clusters with it, and platforms embedding the logic of
+// the operator and validators of clusters before they are submitted use RenderDesiredState.
+// RenderDesiredState and Options are kept compatible across the releases of the operator.
+package render
+
+import (
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/util"
+	"github.com/spotify/flink-on-k8s-operator/pkg/model"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Options are the options of RenderDesiredState and DesiredState.
+type Options struct {
+	// The Flink properties resolved from spec.flinkPropertiesFrom, which the operator reads
+	// from Secrets and external secret stores. Their Secret is rendered only if they are set.
+	FlinkPropertiesFrom map[string]string
+
+	// The image of the operator, which the diagnostics collector sidecars of the clusters
+	// with spec.diagnostics run. The sidecars are not rendered if it is empty.
+	OperatorImage string
+
+	// The nginx image of the read-only web UI proxies and of the init containers rendering
+	// flink-conf.yaml, DefaultUIProxyImage if empty.
+	UIProxyImage string
+
+	// The scheduling constraints merged under those of the spec into the pods of the
+	// components, as with the --scheduling-defaults-config of the operator. None if empty.
+	SchedulingDefaults SchedulingDefaults
+
+	// The image pull secrets of the pods of the clusters which do not set
+	// spec.image.pullSecrets, as with the --default-image-pull-secrets of the operator.
+	ImagePullSecrets []corev1.LocalObjectReference
+
+	// The hash of the ConfigMaps and Secrets referenced by the spec, annotated on the pod
+	// templates so that the pods are restarted when they change. Not annotated if empty.
+	ReferencedConfigHash string
+
+	// Whether the job is stopped to be updated, in which case the Job of the submitter is
+	// rendered although the job is stopped.
+	JobUpdate bool
+
+	// The spec of the current revision of the cluster, which the TaskManager StatefulSet is
+	// rendered from while the canary update of the next revision is aborted or failed, so
+	// that the canaries return to the current revision. The next revision is rendered if nil.
+	CurrentRevisionSpec *v1beta1.FlinkClusterSpec
+
+	// Skips the validation of the cluster, e.g. of clusters which the operator already
+	// accepted.
+	SkipValidation bool
+}
+
+// RenderDesiredState returns the resources the operator creates for the cluster. The cluster
+// must have the defaults of the CRD schema, as the clusters read from the API server or
+// returned by a server-side dry run do. The defaults of the admission webhook are applied to
+// a copy of the cluster, which is validated as a new cluster unless skipped, then converted
+// like by the reconciler of a cluster which has no components yet, with its first revision
+// unless it has one. The validations which read the Kubernetes cluster or the image
+// registries, e.g. of the ResourceQuotas, are skipped. The cluster is not modified.
+func RenderDesiredState(cluster *v1beta1.FlinkCluster, options Options) (*model.DesiredClusterState, error) {
+	cluster = cluster.DeepCopy()
+	cluster.Default()
+	if !options.SkipValidation {
+		var validator = &v1beta1.Validator{}
+		if err := validator.ValidateCreate(cluster); err != nil {
+			return nil, err
+		}
+	}
+	if cluster.Status.Revision.NextRevision == "" {
+		revision, err := NewRevision(cluster, "", 1, nil)
+		if err != nil {
+			return nil, err
+		}
+		var name = util.GetRevisionWithNameNumber(revision)
+		cluster.Status.Revision = v1beta1.RevisionStatus{CurrentRevision: name, NextRevision: name}
+	}
+	return DesiredState(cluster, options), nil
+}
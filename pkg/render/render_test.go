@@ -0,0 +1,95 @@
+package render
+
+import (
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Gets a job cluster with the defaults of the CRD schema.
+func getJobCluster() *v1beta1.FlinkCluster {
+	var jmReplicas, tmReplicas int32 = 1, 3
+	var rpcPort, blobPort, queryPort, uiPort, dataPort int32 = 6123, 6124, 6125, 8081, 6121
+	var jarFile = "gs://my-bucket/myjob.jar"
+	var jobMode = v1beta1.JobModeDetached
+	var restartPolicy = v1beta1.JobRestartPolicyNever
+	var resources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("512Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("2Gi")},
+	}
+	return &v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "mycluster", Namespace: "default"},
+		Spec: v1beta1.FlinkClusterSpec{
+			FlinkVersion: "1.14",
+			Image:        v1beta1.ImageSpec{Name: "flink:1.14.2", PullPolicy: corev1.PullAlways},
+			JobManager: &v1beta1.JobManagerSpec{
+				Replicas:    &jmReplicas,
+				AccessScope: v1beta1.AccessScopeCluster,
+				Ports:       v1beta1.JobManagerPorts{RPC: &rpcPort, Blob: &blobPort, Query: &queryPort, UI: &uiPort},
+				Resources:   resources,
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				DeploymentType: v1beta1.DeploymentTypeStatefulSet,
+				Replicas:       &tmReplicas,
+				Ports:          v1beta1.TaskManagerPorts{RPC: &rpcPort, Data: &dataPort, Query: &queryPort},
+				Resources:      resources,
+			},
+			Job: &v1beta1.JobSpec{
+				JarFile:       &jarFile,
+				Mode:          &jobMode,
+				RestartPolicy: &restartPolicy,
+				CleanupPolicy: &v1beta1.CleanupPolicy{
+					AfterJobSucceeds:  v1beta1.CleanupActionDeleteCluster,
+					AfterJobFails:     v1beta1.CleanupActionKeepCluster,
+					AfterJobCancelled: v1beta1.CleanupActionDeleteCluster,
+				},
+				Resources: resources,
+			},
+		},
+	}
+}
+
+func TestRenderDesiredState(t *testing.T) {
+	var cluster = getJobCluster()
+	state, err := RenderDesiredState(cluster, Options{})
+	assert.NilError(t, err)
+	assert.Equal(t, state.ConfigMap.Name, "mycluster-configmap")
+	assert.Equal(t, state.JmStatefulSet.Name, "mycluster-jobmanager")
+	assert.Equal(t, state.TmStatefulSet.Name, "mycluster-taskmanager")
+	assert.Equal(t, state.JmService.Name, "mycluster-jobmanager")
+	assert.Equal(t, state.Job.Name, "mycluster-job-submitter")
+	assert.Assert(t, state.FlinkPropertiesSecret == nil)
+
+	// The cluster is defaulted in a copy, and its first revision is not recorded in it.
+	assert.Assert(t, state.JmStatefulSet.Spec.Template.Spec.Containers[0].LivenessProbe != nil)
+	assert.Assert(t, cluster.Spec.JobManager.LivenessProbe == nil)
+	assert.Assert(t, state.JmStatefulSet.Labels[RevisionNameLabel] != "")
+	assert.Equal(t, cluster.Status.Revision.NextRevision, "")
+}
+
+func TestRenderDesiredStateOptions(t *testing.T) {
+	var cluster = getJobCluster()
+	cluster.Spec.FlinkPropertiesFrom = []v1beta1.FlinkPropertiesSource{{SecretRef: &corev1.LocalObjectReference{Name: "flink-properties"}}}
+	state, err := RenderDesiredState(cluster, Options{FlinkPropertiesFrom: map[string]string{"taskmanager.numberOfTaskSlots": "2"}})
+	assert.NilError(t, err)
+	assert.Assert(t, state.FlinkPropertiesSecret != nil)
+
+	// The settings of the operator.
+	var pullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+	state, err = RenderDesiredState(cluster, Options{ImagePullSecrets: pullSecrets, UIProxyImage: "nginx:1.25"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, state.JmStatefulSet.Spec.Template.Spec.ImagePullSecrets, pullSecrets)
+	assert.DeepEqual(t, state.Job.Spec.Template.Spec.ImagePullSecrets, pullSecrets)
+	assert.Equal(t, state.TmStatefulSet.Spec.Template.Spec.InitContainers[0].Image, "nginx:1.25")
+
+	// Invalid clusters are rendered only when the validation is skipped.
+	cluster.Spec.Image.Name = ""
+	_, err = RenderDesiredState(cluster, Options{})
+	assert.ErrorContains(t, err, "spec.image.name is unspecified")
+	_, err = RenderDesiredState(cluster, Options{SkipValidation: true})
+	assert.NilError(t, err)
+}
@@ -0,0 +1,304 @@
+// Package rendertest provides the FlinkCluster of the tests of package render and of the
+// packages rendering clusters with it, so that their tests share a single fixture.
+package rendertest
+
+import (
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// NewFlinkCluster returns the job cluster "fjc" in the namespace "default" with the first
+// revision, which sets most of the fields of the spec. Every call returns a new cluster.
+func NewFlinkCluster() *v1beta1.FlinkCluster {
+	var (
+		controller                         = true
+		blockOwnerDeletion                 = false
+		parallelism        int32           = 2
+		jmRPCPort          int32           = 6123
+		jmBlobPort         int32           = 6124
+		jmQueryPort        int32           = 6125
+		jmUIPort           int32           = 8081
+		useTLS                             = true
+		tmDataPort         int32           = 6121
+		tmRPCPort          int32           = 6122
+		tmQueryPort        int32           = 6125
+		replicas           int32           = 42
+		tolerationSeconds  int64           = 30
+		restartPolicy                      = v1beta1.JobRestartPolicyFromSavepointOnFailure
+		className                          = "org.apache.flink.examples.java.wordcount.WordCount"
+		serviceAccount                     = "default"
+		jarFile                            = "/cache/my-job.jar"
+		hostFormat                         = "{{$clusterName}}.example.com"
+		memoryOffHeapRatio int32           = 25
+		memoryOffHeapMin                   = resource.MustParse("600M")
+		memoryProcessRatio int32           = 80
+		jobMode            v1beta1.JobMode = v1beta1.JobModeDetached
+		storageClassName                   = "default-class"
+		jmReadinessProbe                   = corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: intstr.FromInt(int(jmRPCPort)),
+				},
+			},
+			TimeoutSeconds:      10,
+			InitialDelaySeconds: 5,
+			PeriodSeconds:       5,
+			FailureThreshold:    60,
+		}
+		jmLivenessProbe = corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: intstr.FromInt(int(jmRPCPort)),
+				},
+			},
+			TimeoutSeconds:      10,
+			InitialDelaySeconds: 5,
+			PeriodSeconds:       60,
+			FailureThreshold:    5,
+		}
+		tmReadinessProbe = corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: intstr.FromInt(int(tmRPCPort)),
+				},
+			},
+			TimeoutSeconds:      10,
+			InitialDelaySeconds: 5,
+			PeriodSeconds:       5,
+			FailureThreshold:    60,
+		}
+		tmLivenessProbe = corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: intstr.FromInt(int(tmRPCPort)),
+				},
+			},
+			TimeoutSeconds:      10,
+			InitialDelaySeconds: 5,
+			PeriodSeconds:       60,
+			FailureThreshold:    5,
+		}
+		tolerations = []corev1.Toleration{
+			{
+				Key:               "toleration-key",
+				Effect:            "toleration-effect",
+				Operator:          "toleration-operator",
+				TolerationSeconds: &tolerationSeconds,
+				Value:             "toleration-value",
+			},
+			{
+				Key:               "toleration-key2",
+				Effect:            "toleration-effect2",
+				Operator:          "toleration-operator2",
+				TolerationSeconds: &tolerationSeconds,
+				Value:             "toleration-value2",
+			},
+		}
+		hostAliases = []corev1.HostAlias{
+			{
+				IP: "127.0.0.1",
+				Hostnames: []string{
+					"test-localhost-alias1",
+					"test-localhost-alias2",
+				},
+			},
+		}
+		userAndGroupId  int64 = 9999
+		securityContext       = corev1.PodSecurityContext{
+			RunAsUser:  &userAndGroupId,
+			RunAsGroup: &userAndGroupId,
+		}
+	)
+	return &v1beta1.FlinkCluster{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "FlinkCluster",
+			APIVersion: "flinkoperator.k8s.io/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fjc",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			Image:              v1beta1.ImageSpec{Name: "flink:1.8.1"},
+			ServiceAccountName: &serviceAccount,
+			Job: &v1beta1.JobSpec{
+				Args:        []string{"--input", "./README.txt"},
+				ClassName:   &className,
+				JarFile:     &jarFile,
+				Parallelism: &parallelism,
+				Resources: corev1.ResourceRequirements{
+					Requests: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("256Mi"),
+					},
+					Limits: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:    resource.MustParse("200m"),
+						corev1.ResourceMemory: resource.MustParse("512Mi"),
+					},
+				},
+				Mode:          &jobMode,
+				RestartPolicy: &restartPolicy,
+				Volumes: []corev1.Volume{
+					{
+						Name: "cache-volume",
+						VolumeSource: corev1.VolumeSource{
+							EmptyDir: &corev1.EmptyDirVolumeSource{},
+						},
+					},
+				},
+				VolumeMounts: []corev1.VolumeMount{
+					{Name: "cache-volume", MountPath: "/cache"},
+				},
+				InitContainers: []corev1.Container{
+					{
+						Name:    "gcs-downloader",
+						Image:   "google/cloud-sdk",
+						Command: []string{"gsutil"},
+						Args: []string{
+							"cp", "gs://my-bucket/my-job.jar", "/cache/my-job.jar",
+						},
+					},
+				},
+				PodAnnotations: map[string]string{
+					"example.com": "example",
+				},
+				SecurityContext: &securityContext,
+				HostAliases:     hostAliases,
+			},
+			JobManager: &v1beta1.JobManagerSpec{
+				AccessScope: v1beta1.AccessScopeVPC,
+				Ingress: &v1beta1.JobManagerIngressSpec{
+					HostFormat: &hostFormat,
+					Annotations: map[string]string{
+						"kubernetes.io/ingress.class":                "nginx",
+						"certmanager.k8s.io/cluster-issuer":          "letsencrypt-stg",
+						"nginx.ingress.kubernetes.io/rewrite-target": "/",
+					},
+					UseTLS: &useTLS,
+				},
+				Ports: v1beta1.JobManagerPorts{
+					RPC:   &jmRPCPort,
+					Blob:  &jmBlobPort,
+					Query: &jmQueryPort,
+					UI:    &jmUIPort,
+				},
+				LivenessProbe:  &jmLivenessProbe,
+				ReadinessProbe: &jmReadinessProbe,
+				Resources: corev1.ResourceRequirements{
+					Requests: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("256Mi"),
+					},
+					Limits: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:    resource.MustParse("200m"),
+						corev1.ResourceMemory: resource.MustParse("512Mi"),
+					},
+				},
+				Tolerations:        tolerations,
+				HostAliases:        hostAliases,
+				MemoryOffHeapRatio: &memoryOffHeapRatio,
+				MemoryOffHeapMin:   memoryOffHeapMin,
+				MemoryProcessRatio: &memoryProcessRatio,
+				PodAnnotations: map[string]string{
+					"example.com": "example",
+				},
+				SecurityContext: &securityContext,
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				DeploymentType: v1beta1.DeploymentTypeStatefulSet,
+				Replicas:       &replicas,
+				Ports: v1beta1.TaskManagerPorts{
+					Data:  &tmDataPort,
+					RPC:   &tmRPCPort,
+					Query: &tmQueryPort,
+				},
+				Resources: corev1.ResourceRequirements{
+					Requests: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:    resource.MustParse("200m"),
+						corev1.ResourceMemory: resource.MustParse("512Mi"),
+					},
+					Limits: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+				},
+				LivenessProbe:      &tmLivenessProbe,
+				ReadinessProbe:     &tmReadinessProbe,
+				MemoryOffHeapRatio: &memoryOffHeapRatio,
+				MemoryOffHeapMin:   memoryOffHeapMin,
+				MemoryProcessRatio: &memoryProcessRatio,
+				Sidecars:           []corev1.Container{{Name: "sidecar", Image: "alpine"}},
+				Volumes: []corev1.Volume{
+					{
+						Name: "cache-volume",
+						VolumeSource: corev1.VolumeSource{
+							EmptyDir: &corev1.EmptyDirVolumeSource{},
+						},
+					},
+				},
+				VolumeMounts: []corev1.VolumeMount{
+					{Name: "cache-volume", MountPath: "/cache"},
+				},
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "pvc-test",
+							OwnerReferences: []metav1.OwnerReference{
+								{
+									APIVersion:         "flinkoperator.k8s.io/v1beta1",
+									Kind:               "FlinkCluster",
+									Name:               "fjc",
+									Controller:         &controller,
+									BlockOwnerDeletion: &blockOwnerDeletion,
+								},
+							},
+						},
+						Spec: corev1.PersistentVolumeClaimSpec{
+							AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+							Resources: corev1.ResourceRequirements{
+								Requests: map[corev1.ResourceName]resource.Quantity{
+									corev1.ResourceStorage: resource.MustParse("100Gi"),
+								},
+							},
+							StorageClassName: &storageClassName,
+						},
+					},
+				},
+				Tolerations: tolerations,
+				HostAliases: hostAliases,
+				PodAnnotations: map[string]string{
+					"example.com": "example",
+				},
+				SecurityContext: &securityContext,
+			},
+			EnvVars: []corev1.EnvVar{{Name: "FOO", Value: "abc"}},
+			EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: "foomap",
+				}}}},
+			HadoopConfig: &v1beta1.HadoopConfig{
+				ConfigMapName: "hadoop-configmap",
+				MountPath:     "/etc/hadoop/conf",
+			},
+			GCPConfig: &v1beta1.GCPConfig{
+				ServiceAccount: &v1beta1.GCPServiceAccount{
+					SecretName: "gcp-service-account-secret",
+					KeyFile:    "gcp_service_account_key.json",
+					MountPath:  "/etc/gcp_service_account/",
+				},
+			},
+			LogConfig: map[string]string{
+				"extra-file.txt":           "hello!",
+				"log4j-console.properties": "foo",
+				"logback-console.xml":      "bar",
+				"log4j-cli.properties":     "baz",
+			},
+		},
+		Status: v1beta1.FlinkClusterStatus{
+			Revision: v1beta1.RevisionStatus{NextRevision: "fjc-85dc8f749-1"},
+		},
+	}
+}
@@ -0,0 +1,135 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"github.com/spotify/flink-on-k8s-operator/internal/controllers/history"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The kind of the owner of the ControllerRevisions of the clusters.
+var flinkClusterKind = v1beta1.GroupVersion.WithKind("FlinkCluster")
+
+// NewRevision generates FlinkClusterSpec patch and makes new child ControllerRevision
+// resource with it. The name of the revision labels the rendered resources.
+func NewRevision(cluster *v1beta1.FlinkCluster, referencedConfigHash string, revision int64, collisionCount *int32) (*appsv1.ControllerRevision, error) {
+	patch, err := newRevisionDataPatch(cluster, referencedConfigHash)
+	if err != nil {
+		return nil, err
+	}
+	cr, err := history.NewControllerRevision(cluster,
+		flinkClusterKind,
+		cluster.Labels,
+		runtime.RawExtension{Raw: patch},
+		revision,
+		collisionCount)
+	if err != nil {
+		return nil, err
+	}
+	if cr.Annotations == nil {
+		cr.Annotations = make(map[string]string)
+	}
+	for key, value := range cluster.Annotations {
+		cr.Annotations[key] = value
+	}
+	cr.SetNamespace(cluster.GetNamespace())
+	cr.GetLabels()[history.ControllerRevisionManagedByLabel] = cluster.GetName()
+	return cr, nil
+}
+
+// Creates the revision data from the spec, with the hash of the referenced ConfigMaps and
+// Secrets if spec.updateOnReferencedConfigChange is enabled so that their changes create
+// new revisions.
+func newRevisionDataPatch(cluster *v1beta1.FlinkCluster, referencedConfigHash string) ([]byte, error) {
+	// Ignore fields not related to rendering job resource.
+	var c *v1beta1.FlinkCluster
+	if cluster.Spec.Job != nil {
+		c = cluster.DeepCopy()
+		c.Spec.Job.CleanupPolicy = nil
+		c.Spec.Job.RestartPolicy = nil
+		c.Spec.Job.CancelRequested = nil
+		c.Spec.Job.SavepointGeneration = 0
+		c.Spec.Job.SubmitterTTLSecondsAfterFinished = nil
+		c.Spec.Job.SuccessPolicy = nil
+		c.Spec.Job.RestoreVerification = nil
+		c.Spec.Job.ReadinessGates = nil
+	} else if len(cluster.Spec.Jars) > 0 {
+		c = cluster.DeepCopy()
+		c.Spec.Jars = nil
+	} else {
+		c = cluster
+	}
+	// The duration of the flight recordings does not change the rendered resources, unlike
+	// the upload of the diagnostics collectors.
+	if d := cluster.Spec.Diagnostics; d != nil && d.FlightRecordingSeconds != nil {
+		if c == cluster {
+			c = cluster.DeepCopy()
+		}
+		c.Spec.Diagnostics.FlightRecordingSeconds = nil
+	}
+
+	// The deletion policy only applies when the cluster is deleted.
+	if cluster.Spec.DeletionPolicy != nil {
+		if c == cluster {
+			c = cluster.DeepCopy()
+		}
+		c.Spec.DeletionPolicy = nil
+	}
+
+	// Pausing the reconciliation does not change the rendered resources.
+	if cluster.Spec.Paused != nil {
+		if c == cluster {
+			c = cluster.DeepCopy()
+		}
+		c.Spec.Paused = nil
+	}
+
+	// The dependencies are only checked before the cluster is started.
+	if len(cluster.Spec.DependsOn) > 0 {
+		if c == cluster {
+			c = cluster.DeepCopy()
+		}
+		c.Spec.DependsOn = nil
+	}
+
+	// The idle policy scales the components without an update.
+	if cluster.Spec.IdlePolicy != nil {
+		if c == cluster {
+			c = cluster.DeepCopy()
+		}
+		c.Spec.IdlePolicy = nil
+	}
+
+	str := &bytes.Buffer{}
+	err := unstructured.UnstructuredJSONScheme.Encode(c, str)
+
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	json.Unmarshal([]byte(str.Bytes()), &raw)
+	objCopy := make(map[string]interface{})
+	spec := raw["spec"].(map[string]interface{})
+	objCopy["spec"] = spec
+	spec["$patch"] = "replace"
+	if referencedConfigHash != "" {
+		spec[ReferencedConfigHashKey] = referencedConfigHash
+	}
+
+	// backward compatibility fix
+	if c.Spec.Job != nil {
+		job := spec["job"].(map[string]interface{})
+		job["restartPolicy"] = nil
+	}
+
+	patch, err := json.Marshal(objCopy)
+	return patch, err
+}
+
+// ReferencedConfigHashKey is the key of the revision data with the hash of the referenced
+// ConfigMaps and Secrets.
+const ReferencedConfigHashKey = "referencedConfigHash"
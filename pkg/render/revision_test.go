@@ -0,0 +1,120 @@
+/*
+Copyright 2019 Google LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"testing"
+
+	v1beta1 "github.com/spotify/flink-on-k8s-operator/apis/flinkcluster/v1beta1"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestNewRevision(t *testing.T) {
+	var jmReplicas int32 = v1beta1.DefaultJobManagerReplicas
+	var tmReplicas int32 = v1beta1.DefaultTaskManagerReplicas
+	var rpcPort int32 = 8001
+	var blobPort int32 = 8002
+	var queryPort int32 = 8003
+	var uiPort int32 = 8004
+	var dataPort int32 = 8005
+	var memoryOffHeapRatio int32 = 25
+	var memoryOffHeapMin = resource.MustParse("600M")
+	var jarFile = "gs://my-bucket/myjob.jar"
+	var parallelism int32 = 2
+	var savepointDir = "/savepoint_dir"
+	restartPolicy := v1beta1.JobRestartPolicyNever
+	var flinkCluster = v1beta1.FlinkCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mycluster",
+			Namespace: "default",
+		},
+		Spec: v1beta1.FlinkClusterSpec{
+			Image: v1beta1.ImageSpec{
+				Name:       "flink:1.8.1",
+				PullPolicy: corev1.PullPolicy("Always"),
+			},
+			JobManager: &v1beta1.JobManagerSpec{
+				Replicas:    &jmReplicas,
+				AccessScope: v1beta1.AccessScopeVPC,
+				Ports: v1beta1.JobManagerPorts{
+					RPC:   &rpcPort,
+					Blob:  &blobPort,
+					Query: &queryPort,
+					UI:    &uiPort,
+				},
+				MemoryOffHeapRatio: &memoryOffHeapRatio,
+				MemoryOffHeapMin:   memoryOffHeapMin,
+			},
+			TaskManager: &v1beta1.TaskManagerSpec{
+				Replicas: &tmReplicas,
+				Ports: v1beta1.TaskManagerPorts{
+					RPC:   &rpcPort,
+					Data:  &dataPort,
+					Query: &queryPort,
+				},
+				MemoryOffHeapRatio: &memoryOffHeapRatio,
+				MemoryOffHeapMin:   memoryOffHeapMin,
+			},
+			Job: &v1beta1.JobSpec{
+				JarFile:       &jarFile,
+				Parallelism:   &parallelism,
+				SavepointsDir: &savepointDir,
+				RestartPolicy: &restartPolicy,
+			},
+		},
+	}
+
+	var patched = flinkCluster.DeepCopy()
+	// RestartPolicy is not part of the revision.
+	patched.Spec.Job.RestartPolicy = nil
+
+	var collisionCount int32 = 0
+	var controller = true
+	var blockOwnerDeletion = true
+	var raw, _ = newRevisionDataPatch(patched, "")
+	var revision, _ = NewRevision(&flinkCluster, "", 1, &collisionCount)
+	var expectedRevision = appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mycluster-7bc87c954f",
+			Namespace: "default",
+			Labels: map[string]string{
+				"flinkoperator.k8s.io/hash":       "7bc87c954f",
+				"flinkoperator.k8s.io/managed-by": "mycluster",
+			},
+			Annotations: map[string]string{},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "flinkoperator.k8s.io/v1beta1",
+				Kind:               "FlinkCluster",
+				Name:               "mycluster",
+				Controller:         &controller,
+				BlockOwnerDeletion: &blockOwnerDeletion,
+			}},
+		},
+		Revision: 1,
+		Data:     runtime.RawExtension{Raw: raw},
+	}
+	assert.Assert(t, revision != nil)
+	assert.DeepEqual(
+		t,
+		*revision,
+		expectedRevision)
+}
@@ -0,0 +1,98 @@
+package render
+
+import (
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// PodSchedulingDefaults defines the scheduling constraints merged under those of the spec
+// into the pods of a component.
+type PodSchedulingDefaults struct {
+	NodeSelector map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations  []corev1.Toleration `json:"tolerations,omitempty"`
+	Affinity     *corev1.Affinity    `json:"affinity,omitempty"`
+}
+
+// SchedulingDefaults is the config of the default scheduling constraints of the pods of all
+// the clusters, per component, e.g. to run them on a dedicated node pool.
+type SchedulingDefaults struct {
+	JobManager   *PodSchedulingDefaults `json:"jobManager,omitempty"`
+	TaskManager  *PodSchedulingDefaults `json:"taskManager,omitempty"`
+	JobSubmitter *PodSchedulingDefaults `json:"jobSubmitter,omitempty"`
+}
+
+// LoadSchedulingDefaults reads the scheduling defaults from a YAML or JSON file.
+func LoadSchedulingDefaults(path string) (*SchedulingDefaults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var defaults = new(SchedulingDefaults)
+	if err := yaml.UnmarshalStrict(data, defaults); err != nil {
+		return nil, fmt.Errorf("invalid scheduling defaults config %v: %v", path, err)
+	}
+	return defaults, nil
+}
+
+// setSchedulingDefaults merges the defaults under the scheduling constraints of the pod: the
+// node selector labels of the spec win over the defaults of the same key, its tolerations
+// over the defaults of the same key and effect, and its node affinity, pod affinity and pod
+// anti-affinity each replace the default.
+func setSchedulingDefaults(defaults *PodSchedulingDefaults, podSpec *corev1.PodSpec) {
+	if defaults == nil {
+		return
+	}
+
+	if len(defaults.NodeSelector) > 0 {
+		var nodeSelector = make(map[string]string, len(defaults.NodeSelector)+len(podSpec.NodeSelector))
+		for key, value := range defaults.NodeSelector {
+			nodeSelector[key] = value
+		}
+		for key, value := range podSpec.NodeSelector {
+			nodeSelector[key] = value
+		}
+		podSpec.NodeSelector = nodeSelector
+	}
+
+	// The tolerations are shared with the cluster spec, which must not be modified.
+	var tolerations = append([]corev1.Toleration{}, podSpec.Tolerations...)
+	for _, toleration := range defaults.Tolerations {
+		if !hasToleration(podSpec.Tolerations, toleration) {
+			tolerations = append(tolerations, toleration)
+		}
+	}
+	if len(tolerations) > 0 {
+		podSpec.Tolerations = tolerations
+	}
+
+	if defaults.Affinity != nil {
+		var affinity = podSpec.Affinity.DeepCopy()
+		if affinity == nil {
+			affinity = &corev1.Affinity{}
+		}
+		if affinity.NodeAffinity == nil {
+			affinity.NodeAffinity = defaults.Affinity.NodeAffinity.DeepCopy()
+		}
+		if affinity.PodAffinity == nil {
+			affinity.PodAffinity = defaults.Affinity.PodAffinity.DeepCopy()
+		}
+		if affinity.PodAntiAffinity == nil {
+			affinity.PodAntiAffinity = defaults.Affinity.PodAntiAffinity.DeepCopy()
+		}
+		podSpec.Affinity = affinity
+	}
+}
+
+// hasToleration returns true if the tolerations have one of the key and effect of the
+// toleration.
+func hasToleration(tolerations []corev1.Toleration, toleration corev1.Toleration) bool {
+	for _, t := range tolerations {
+		if t.Key == toleration.Key && t.Effect == toleration.Effect {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spotify/flink-on-k8s-operator/pkg/render/rendertest"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestLoadSchedulingDefaults(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "scheduling-defaults.yaml")
+	os.WriteFile(path, []byte(`
+taskManager:
+  nodeSelector:
+    pool: flink
+  tolerations:
+  - key: dedicated
+    value: flink
+    effect: NoSchedule
+`), 0644)
+	defaults, err := LoadSchedulingDefaults(path)
+	assert.NilError(t, err)
+	assert.Assert(t, defaults.JobManager == nil)
+	assert.DeepEqual(t, defaults.TaskManager.NodeSelector, map[string]string{"pool": "flink"})
+	assert.Equal(t, defaults.TaskManager.Tolerations[0].Effect, corev1.TaintEffectNoSchedule)
+
+	os.WriteFile(path, []byte("taskManagers:\n  nodeSelector:\n    pool: flink\n"), 0644)
+	_, err = LoadSchedulingDefaults(path)
+	assert.ErrorContains(t, err, "invalid scheduling defaults config")
+}
+
+func TestSetSchedulingDefaults(t *testing.T) {
+	var defaults = &PodSchedulingDefaults{
+		NodeSelector: map[string]string{"pool": "flink", "zone": "a"},
+		Tolerations: []corev1.Toleration{
+			{Key: "dedicated", Value: "flink", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "spot", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		},
+		Affinity: &corev1.Affinity{
+			NodeAffinity:    &corev1.NodeAffinity{},
+			PodAntiAffinity: &corev1.PodAntiAffinity{},
+		},
+	}
+	var specTolerations = []corev1.Toleration{{Key: "dedicated", Value: "streaming", Effect: corev1.TaintEffectNoSchedule}}
+	var podSpec = &corev1.PodSpec{
+		NodeSelector: map[string]string{"zone": "b"},
+		Tolerations:  specTolerations,
+		Affinity:     &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{TopologyKey: "zone"}}}},
+	}
+
+	setSchedulingDefaults(nil, podSpec)
+	setSchedulingDefaults(defaults, podSpec)
+	// The spec takes precedence over the defaults.
+	assert.DeepEqual(t, podSpec.NodeSelector, map[string]string{"pool": "flink", "zone": "b"})
+	assert.DeepEqual(t, podSpec.Tolerations, []corev1.Toleration{
+		{Key: "dedicated", Value: "streaming", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "spot", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	})
+	assert.Assert(t, podSpec.Affinity.NodeAffinity != nil)
+	assert.Equal(t, podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].TopologyKey, "zone")
+	assert.Equal(t, len(specTolerations), 1)
+}
+
+func TestSchedulingDefaultsOfComponents(t *testing.T) {
+	var options = Options{SchedulingDefaults: SchedulingDefaults{
+		TaskManager: &PodSchedulingDefaults{NodeSelector: map[string]string{"pool": "flink"}},
+	}}
+	var desired = DesiredState(rendertest.NewFlinkCluster(), options)
+	assert.DeepEqual(t, desired.TmStatefulSet.Spec.Template.Spec.NodeSelector, map[string]string{"pool": "flink"})
+	assert.Assert(t, desired.JmStatefulSet.Spec.Template.Spec.NodeSelector == nil)
+}
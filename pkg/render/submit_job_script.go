@@ -0,0 +1,378 @@
+/*
+Copyright 2020 Google LLC.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+// This script is part of the cluster's ConfigMap and is mounted into the
+// job (submitter) container at `/opt/flink-operator/submit-job.sh` for job
+// submission.
+var submitJobScript = `
+#! /usr/bin/env bash
+
+# This script checks the status of the JobManager and submits the Flink job when ready.
+# When job submission is finished, the result is recorded in the pod termination log in the following YAML format.
+#
+# When succeeded:
+#
+# jobID: ec74209eb4e3db8ae72db00bd7a830aa
+# message: |
+#   Successfully submitted!
+#   /opt/flink/bin/flink run --jobmanager flinkjobcluster-sample-jobmanager:8081 --class org.apache.flink.streaming.examples.wordcount.WordCount --parallelism 2 --detached ./examples/streaming/WordCount.jar --input ./README.txt
+#	Starting execution of program
+#	Printing result to stdout. Use --output to specify output path.
+#	Job has been submitted with JobID ec74209eb4e3db8ae72db00bd7a830aa
+#
+# When submission fails (no jobID):
+#
+# message: |
+#   Aborted submit because JobManager is unavailable.
+#   Checking job manager to be ready. Will check success of 2 API calls for stable job submission.
+#	curl -sS "http://t3-jobmanager:8081/jobs"
+#	curl: (7) Failed to connect to t3-jobmanager port 8081: Connection refused
+#   ...
+
+set -euo pipefail
+
+readonly TERM_LOG="/dev/termination-log"
+TERM_LOG_SIZE=0
+TERM_LOG_MAX_SIZE=4096
+
+function echo_log() {
+    local msg="$1"
+    local log_file="$2"
+    echo -e "${msg}" | tee -a "${log_file}"
+}
+
+function write_term_log() {
+    local msg=$1
+    local size=$(echo -e "${msg}" | wc -c)
+
+    if ((TERM_LOG_SIZE + size > TERM_LOG_MAX_SIZE)); then
+        return 0
+    fi
+
+    echo "${msg}" >>"${TERM_LOG}"
+    TERM_LOG_SIZE=$((TERM_LOG_SIZE + size))
+
+    return $((size))
+}
+
+# write message to termination log as YAML format.
+function write_term_log_msg() {
+    local result_msg="$1"
+    local log_file="$2"
+
+    # Write result message.
+    write_term_log "message: |"
+    write_term_log "  ${result_msg}"
+
+    # Append submit log to message.
+    # Two space indentation is required to write strings in the form of YAML literal block scalar.
+    IFS=''
+    while read -r line; do
+        # Insert indentation before printing line.
+        write_term_log "  ${line}"
+        if [ $? = 0 ]; then
+            break
+        fi
+    done <"${log_file}"
+}
+
+function check_jm_ready() {
+    # Waiting for 10 mins.
+    local -r MAX_RETRY=120
+    local -r RETRY_INTERVAL=5s
+    local -r REQUIRED_SUCCESS_NUMBER=3
+    local -r CONNECT_TIMEOUT=5
+    local success_count=0
+
+    echo_log "Checking job manager to be ready. Will check success of ${REQUIRED_SUCCESS_NUMBER} API calls for stable job submission." "job_check_log"
+    for ((i = 1; i <= MAX_RETRY; i++)); do
+        echo_log "curl -sS \"http://${FLINK_JM_ADDR}/jobs\"" "job_check_log"
+        if curl -sS --connect-timeout ${CONNECT_TIMEOUT} "http://${FLINK_JM_ADDR}/jobs" 2>&1 | tee -a job_check_log; then
+            ((success_count++))
+            echo_log "\nSuccess ${success_count}/${REQUIRED_SUCCESS_NUMBER}" "job_check_log"
+            if ((success_count < REQUIRED_SUCCESS_NUMBER)); then
+                echo_log "\nWaiting..." "job_check_log"
+                sleep "${RETRY_INTERVAL}"
+                continue
+            fi
+            echo_log "\nJob manager is ready now. Tried ${i} time(s), every ${RETRY_INTERVAL} and succeeded ${success_count} time(s)." "job_check_log"
+            return 0
+        else
+            echo_log "\nWaiting..." "job_check_log"
+        fi
+        sleep "${RETRY_INTERVAL}"
+    done
+
+    echo_log "\nReached max retry count(${MAX_RETRY}) to check job manager status." "job_check_log"
+    echo_log "Aborted to submit job." "job_check_log"
+
+    write_term_log_msg "Aborted submit because JobManager is unavailable." "job_check_log"
+
+    return 1
+}
+
+# Resolves the entry class from the manifest of the JAR file in FLINK_JOB_JAR_FILE into
+# ENTRY_CLASS, when spec.job.className is not specified. The program-class entry wins over the
+# Main-Class entry, as in Flink. Fails when the manifest has no entry class.
+# If the manifest cannot be read, e.g. the image has no unzip, the entry class is left to Flink.
+ENTRY_CLASS=""
+function resolve_entry_class() {
+    local -r jar_file="$1"
+    local manifest
+
+    if ! command -v unzip >/dev/null || ! manifest=$(unzip -p "${jar_file}" META-INF/MANIFEST.MF 2>/dev/null); then
+        echo_log "Could not read the manifest of ${jar_file}, the entry class is resolved by Flink." "submit_log"
+        return 0
+    fi
+    # Join the continuation lines of the entries, which are wrapped at 72 bytes.
+    manifest=$(echo "${manifest}" | tr -d '\r' | sed -e ':a' -e '$!N' -e 's/\n //' -e 'ta' -e 'P' -e 'D')
+    local -r program_class=$(echo "${manifest}" | sed -n 's/^program-class: *//p' | head -n 1)
+    local -r main_class=$(echo "${manifest}" | sed -n 's/^Main-Class: *//p' | head -n 1)
+
+    if [[ -n "${program_class}" && -n "${main_class}" && "${program_class}" != "${main_class}" ]]; then
+        echo_log "The program-class ${program_class} overrides the Main-Class ${main_class} in the manifest of ${jar_file}." "submit_log"
+    fi
+    ENTRY_CLASS="${program_class:-${main_class}}"
+    if [[ -z "${ENTRY_CLASS}" ]]; then
+        echo_log "Neither a program-class nor a Main-Class entry was found in the manifest of ${jar_file}." "submit_log"
+        write_term_log_msg "Failed to resolve the entry class, set spec.job.className." "submit_log"
+        return 1
+    fi
+    echo_log "Resolved the entry class ${ENTRY_CLASS} from the manifest of ${jar_file}." "submit_log"
+    return 0
+}
+
+function submit_job() {
+    local job_id=""
+
+    # Submit job and extract the job ID. The arguments from secrets are masked in the log, which
+    # ends up in the termination message of the pod.
+    local command="/opt/flink/bin/flink run $*"
+    local name
+    for name in ${FLINK_JOB_SECRET_ARGS:-}; do
+        if [[ -n "${!name:-}" ]]; then
+            command="${command//"${!name}"/******}"
+        fi
+    done
+    echo "${command}" | tee -a submit_log
+    /opt/flink/bin/flink run "$@" 2>&1 | tee -a submit_log
+    local -r job_exit_code=$?
+    local -r job_id_indicator="Job has been submitted with JobID"
+    job_id=$(grep "${job_id_indicator}" submit_log | awk -F "${job_id_indicator}" '{printf $2}' | awk '{printf $1}')
+
+    # Write result as YAML format to pod termination-log.
+    # On failure, write log only.
+    if [[ -z ${job_id} ]]; then
+		if [ $job_exit_code -eq 0 ]; then
+			write_term_log_msg "The submitter finished successfully but there is no job id. Did you forget to execute the pipeline?" "submit_log"
+			return 1
+		fi        
+        write_term_log_msg "Failed to submit." "submit_log"
+        return 1
+    fi
+
+    # write job ID if there is one
+    write_term_log "jobID: ${job_id}"
+
+    # check the job's exit code
+    if [ $job_exit_code -ne 0 ]; then
+        write_term_log_msg "Job failed with a non-zero exit code: ${job_exit_code}" "submit_log"
+        return $job_exit_code
+    fi        
+
+    # On success, write log
+    write_term_log_msg "Successfully submitted!" "submit_log"
+    return 0
+}
+
+function main() {
+    echo -e "---------- Checking job manager status ----------"
+    if ! check_jm_ready; then
+        exit 1
+    fi
+
+    echo -e "\n---------- Submitting job ----------"
+    local -a args=("$@")
+    if [[ -n "${FLINK_JOB_JAR_FILE:-}" && -f "${FLINK_JOB_JAR_FILE}" ]]; then
+        if ! resolve_entry_class "${FLINK_JOB_JAR_FILE}"; then
+            exit 1
+        fi
+        if [[ -n "${ENTRY_CLASS}" ]]; then
+            args=(--class "${ENTRY_CLASS}" "${args[@]}")
+        fi
+    fi
+    set +e
+    submit_job "${args[@]}"
+    submit_job_result=$?
+    set -e
+    exit $submit_job_result
+}
+
+main "$@"
+`
+
+// This script is run by the `artifact-cache` init container of the job
+// submitter. It downloads the JAR file into the artifact cache volume unless
+// it was already downloaded by a previous submission. The file is renamed into
+// place only when the download completes, so concurrent submitters never use
+// partial files. When ARTIFACT_SHA256 is set, the file, cached or downloaded,
+// must match the pinned SHA-256 checksum. Otherwise when ARTIFACT_CHECKSUM_URI
+// is set, e.g. for Maven artifacts, the file is only renamed into place if it
+// matches the SHA-1 checksum. The credentials are passed to curl on stdin to
+// keep them out of the process list, quoted as strings of the curl config.
+var artifactCacheScript = `
+set -euo pipefail
+
+function curl_config_string() {
+    local value="${1//\\/\\\\}"
+    printf '"%s"' "${value//\"/\\\"}"
+}
+
+function fetch() {
+    if [[ -n "${ARTIFACT_USERNAME:-}" ]]; then
+        printf 'user = %s\n' "$(curl_config_string "${ARTIFACT_USERNAME}:${ARTIFACT_PASSWORD:-}")" \
+            | curl -fsSL --retry 3 -K - "$@"
+    else
+        curl -fsSL --retry 3 "$@"
+    fi
+}
+
+function sha256_matches() {
+    [[ "$(sha256sum "$1" | awk '{print $1}')" == "${ARTIFACT_SHA256,,}" ]]
+}
+
+if [[ -f "${ARTIFACT_PATH}" ]]; then
+    if [[ -z "${ARTIFACT_SHA256:-}" ]] || sha256_matches "${ARTIFACT_PATH}"; then
+        echo "Found ${ARTIFACT_URI} in the artifact cache: ${ARTIFACT_PATH}"
+        exit 0
+    fi
+    echo "The cached ${ARTIFACT_PATH} does not match the SHA-256 checksum ${ARTIFACT_SHA256}, downloading it again"
+fi
+
+mkdir -p "$(dirname "${ARTIFACT_PATH}")"
+tmp_file="$(mktemp "${ARTIFACT_PATH}.XXXXXX")"
+trap 'rm -f "${tmp_file}"' EXIT
+
+echo "Downloading ${ARTIFACT_URI} to the artifact cache: ${ARTIFACT_PATH}"
+fetch -o "${tmp_file}" "${ARTIFACT_URI}"
+if [[ -n "${ARTIFACT_SHA256:-}" ]]; then
+    if ! sha256_matches "${tmp_file}"; then
+        echo "Checksum mismatch of ${ARTIFACT_URI}: expected ${ARTIFACT_SHA256}, got $(sha256sum "${tmp_file}" | awk '{print $1}')" >&2
+        exit 1
+    fi
+    echo "Verified the SHA-256 checksum ${ARTIFACT_SHA256}"
+elif [[ -n "${ARTIFACT_CHECKSUM_URI:-}" ]]; then
+    expected="$(fetch "${ARTIFACT_CHECKSUM_URI}" | awk '{print $1}')"
+    actual="$(sha1sum "${tmp_file}" | awk '{print $1}')"
+    if [[ "${expected}" != "${actual}" ]]; then
+        echo "Checksum mismatch of ${ARTIFACT_URI}: expected ${expected}, got ${actual}" >&2
+        exit 1
+    fi
+    echo "Verified the SHA-1 checksum ${actual}"
+fi
+mv -f "${tmp_file}" "${ARTIFACT_PATH}"
+`
+
+// This script is run by the `extra-artifacts` init container of the TaskManager
+// pods when the image of the operator is unknown, e.g. when rendered without it.
+// Its arguments are triplets of the URL of a file of
+// spec.taskManager.extraArtifacts, the path to download it to and its expected
+// SHA-256 checksum, empty if it is not verified.
+var extraArtifactsScript = `
+set -euo pipefail
+
+while [[ $# -gt 0 ]]; do
+    uri="$1" file="$2" checksum="$3"
+    shift 3
+    mkdir -p "$(dirname "${file}")"
+    echo "Downloading ${uri} to ${file}"
+    curl -fsSL --retry 3 -o "${file}" "${uri}"
+    if [[ -n "${checksum}" ]]; then
+        actual="$(sha256sum "${file}" | awk '{print $1}')"
+        if [[ "${actual}" != "${checksum,,}" ]]; then
+            echo "Checksum mismatch of ${uri}: expected ${checksum}, got ${actual}" >&2
+            exit 1
+        fi
+        echo "Verified the SHA-256 checksum ${actual}"
+    fi
+done
+`
+
+// This script is run by the `flink-plugins` init container of the JobManager and
+// TaskManager pods. The first argument is the directory to copy the plugins to
+// and the rest are the plugins of spec.flinkPlugins, each copied from the opt
+// directory of the image into a directory of its own.
+var flinkPluginsScript = `
+set -euo pipefail
+
+plugins_dir="$1"
+shift
+for plugin in "$@"; do
+    jars=("${FLINK_HOME:-/opt/flink}"/opt/flink-"${plugin}"-*.jar)
+    if [[ ! -f "${jars[0]}" ]]; then
+        echo "Plugin ${plugin} is not in the opt directory of the image" >&2
+        exit 1
+    fi
+    mkdir -p "${plugins_dir}/${plugin}"
+    echo "Enabling plugin ${plugin} with ${jars[*]}"
+    cp "${jars[@]}" "${plugins_dir}/${plugin}/"
+done
+`
+
+// This script is run by the `import-ca-bundle` init container of the pods of
+// spec.networking.caBundle. It copies the truststore of the JVM of the image
+// and imports the certificates of the bundle one by one, as keytool imports
+// only the first certificate of a PEM file. The bundle of the image is
+// concatenated with the certificates too, for the tools which read PEM files,
+// e.g. curl.
+var importCABundleScript = `
+set -euo pipefail
+
+cacerts="${JAVA_HOME}/lib/security/cacerts"
+if [[ ! -f "${cacerts}" ]]; then
+    # The truststore of the JRE of Java 8.
+    cacerts="${JAVA_HOME}/jre/lib/security/cacerts"
+fi
+cp "${cacerts}" "${TRUSTSTORE_DIR}/cacerts"
+chmod 644 "${TRUSTSTORE_DIR}/cacerts"
+
+awk -v dir="${TRUSTSTORE_DIR}" '
+    /-----BEGIN CERTIFICATE-----/ { n++ }
+    n > 0 { print > (dir "/ca-" n ".pem") }
+' "${CA_BUNDLE_FILE}"
+count=0
+for cert in "${TRUSTSTORE_DIR}"/ca-*.pem; do
+    [[ -f "${cert}" ]] || continue
+    keytool -importcert -noprompt -keystore "${TRUSTSTORE_DIR}/cacerts" -storepass "${TRUSTSTORE_PASSWORD}" \
+        -alias "flink-operator-$(basename "${cert}" .pem)" -file "${cert}"
+    count=$((count + 1))
+done
+rm -f "${TRUSTSTORE_DIR}"/ca-*.pem
+if [[ ${count} -eq 0 ]]; then
+    echo "No certificate found in ${CA_BUNDLE_FILE}" >&2
+    exit 1
+fi
+
+{
+    if [[ -f /etc/ssl/certs/ca-certificates.crt ]]; then
+        cat /etc/ssl/certs/ca-certificates.crt
+    fi
+    cat "${CA_BUNDLE_FILE}"
+} > "${TRUSTSTORE_DIR}/ca-certificates.crt"
+echo "Imported ${count} certificates from ${CA_BUNDLE_FILE}"
+`
@@ -0,0 +1,23 @@
+package render
+
+// This nginx config is part of the cluster's ConfigMap when the web UI is
+// read-only. It is mounted into the JobManager `ui-proxy` sidecar, which only
+// passes read requests to the Flink REST API. The placeholders are the proxy
+// port and the JobManager UI port.
+var uiProxyConfig = `server {
+    listen %d;
+
+    # Legacy job cancellation endpoints accept GET requests.
+    location ~ ^/jobs/[^/]+/yarn-(cancel|stop)$ {
+        return 403;
+    }
+
+    location / {
+        limit_except GET {
+            deny all;
+        }
+        proxy_pass http://127.0.0.1:%d;
+        proxy_set_header Host $host;
+    }
+}
+`